@@ -2,22 +2,36 @@ package main
 
 import (
 	"context"
+	"fkteams/internal/adapters/hookrunner"
 	modelproviders "fkteams/internal/adapters/model/providers"
+	fileaudit "fkteams/internal/adapters/storage/file/audit"
+	fileusage "fkteams/internal/adapters/storage/file/usage"
 	mcpadapter "fkteams/internal/adapters/tools/mcp"
 	clicommands "fkteams/internal/adapters/transport/cli/commands"
 	agents "fkteams/internal/app/agent/catalog"
 	"fkteams/internal/app/agent/catalog/toolmeta"
+	"fkteams/internal/app/appdata"
+	appaudit "fkteams/internal/app/audit"
 	apptools "fkteams/internal/app/tools"
+	appusage "fkteams/internal/app/usage"
+	"fkteams/internal/app/userhooks"
 	bootstrapruntimes "fkteams/internal/bootstrap/runtimes"
 	bootstraptools "fkteams/internal/bootstrap/tools"
 	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/env"
+	"fkteams/internal/runtime/hooks"
 	modelregistry "fkteams/internal/runtime/model"
+	"fkteams/internal/runtime/secrets"
 	"os"
+	// 内嵌时区数据，定时任务的 time_zone 在没有系统时区库的环境（如 Windows）中也可用。
+	_ "time/tzdata"
 
 	"github.com/pterm/pterm"
 )
 
 func main() {
+	// 密钥库口令等敏感变量在创建任何子进程之前移出进程环境
+	env.TakeSecrets()
 	mcpProvider := mcpadapter.NewProvider()
 	toolDisplays := toolmeta.NewRegistry()
 	runtimeDefaults, err := bootstrapruntimes.NewDefaults(bootstrapruntimes.Options{
@@ -32,6 +46,13 @@ func main() {
 		pterm.Error.Println(err)
 		os.Exit(1)
 	}
+	usageLedger := appusage.NewLedger(fileusage.NewStore(appdata.UsageDir()), appusage.SettingsFromConfig)
+	usageLedger.Install(runtimeDefaults.HookBus)
+	// 审计密钥在启动时从密钥库读取一次，不会创建密钥库；不可用时工具调用被拒绝
+	auditKey := appaudit.StaticKey(appaudit.LoadVaultKey(secrets.Open(appdata.SecretsFile(), secrets.Options{Keyring: secrets.SystemKeyring()})))
+	auditLog := appaudit.NewLog(fileaudit.NewStore(appdata.AuditFile(), auditKey), auditKey)
+	auditLog.Install(runtimeDefaults.HookBus)
+	userHooks := userhooks.NewManager(runtimeDefaults.HookBus, userhooks.SettingsFromConfig, hookrunner.New)
 	ctx := runtimeport.WithRuntime(context.Background(), runtimeDefaults.Runtime)
 	ctx = runtimeport.WithInterruptRuntime(ctx, runtimeDefaults.Interrupt)
 	ctx = modelregistry.WithRegistry(ctx, runtimeDefaults.ModelRegistry)
@@ -39,6 +60,10 @@ func main() {
 	ctx = apptools.WithRegistry(ctx, toolRegistry)
 	ctx = toolmeta.WithRegistry(ctx, toolDisplays)
 	ctx = agents.WithRegistry(ctx, agents.NewRegistry())
+	ctx = hooks.WithBus(ctx, runtimeDefaults.HookBus)
+	ctx = appusage.WithLedger(ctx, usageLedger)
+	ctx = appaudit.WithLog(ctx, auditLog)
+	ctx = userhooks.WithManager(ctx, userHooks)
 	if err := clicommands.Root().Run(ctx, os.Args); err != nil {
 		pterm.Error.Println(err)
 	}
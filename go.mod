@@ -53,8 +53,10 @@ require (
 	golang.org/x/crypto v0.46.0
 	golang.org/x/oauth2 v0.30.0
 	golang.org/x/sync v0.19.0
+	golang.org/x/sys v0.41.0
 	golang.org/x/term v0.39.0
 	google.golang.org/genai v1.50.0
+	mvdan.cc/sh/v3 v3.11.0
 )
 
 require (
@@ -197,7 +199,6 @@ require (
 	golang.org/x/exp v0.0.0-20250218142911-aa4b98e5adaa // indirect
 	golang.org/x/mod v0.30.0 // indirect
 	golang.org/x/net v0.47.0 // indirect
-	golang.org/x/sys v0.41.0 // indirect
 	golang.org/x/text v0.32.0 // indirect
 	golang.org/x/time v0.6.0 // indirect
 	golang.org/x/tools v0.39.0 // indirect
@@ -0,0 +1,140 @@
+// Package hookrunner 提供用户 hook 的外部执行器：本地命令和 HTTP 端点。
+package hookrunner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"fkteams/internal/app/config"
+	"fkteams/internal/app/userhooks"
+	fkenv "fkteams/internal/runtime/env"
+)
+
+const (
+	// maxOutputBytes 限制 hook 返回内容的大小，防止异常输出占满内存。
+	maxOutputBytes = 4 << 20
+	// exitCodeReject 是命令 hook 表示拒绝的退出码，stderr 作为拒绝原因。
+	exitCodeReject = 2
+	// commandWaitDelay 是上下文取消后等待子进程输出管道关闭的时间。
+	commandWaitDelay = time.Second
+)
+
+// New 按配置创建命令或 HTTP 执行器，符合 userhooks.RunnerFactory。
+func New(cfg config.HookConfig) (userhooks.Runner, error) {
+	switch {
+	case cfg.Command != "":
+		env := make([]string, 0, len(cfg.Env))
+		for key, value := range cfg.Env {
+			env = append(env, key+"="+value)
+		}
+		return &commandRunner{command: cfg.Command, env: env}, nil
+	case cfg.URL != "":
+		return &httpRunner{url: cfg.URL, headers: cfg.Headers, client: http.DefaultClient}, nil
+	default:
+		return nil, fmt.Errorf("hook %s requires command or url", cfg.Name)
+	}
+}
+
+// commandRunner 通过系统 shell 执行本地命令，请求 JSON 从 stdin 传入。
+type commandRunner struct {
+	command string
+	env     []string
+}
+
+func (r *commandRunner) Run(ctx context.Context, env []string, body []byte) ([]byte, error) {
+	shell, args := shellCommand(r.command)
+	cmd := exec.CommandContext(ctx, shell, args...)
+	cmd.Env = fkenv.ChildEnviron(append(append([]string(nil), r.env...), env...)...)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.WaitDelay = commandWaitDelay
+	stdout := &limitedWriter{limit: maxOutputBytes}
+	stderr := &limitedWriter{limit: maxOutputBytes}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		message := strings.TrimSpace(stderr.buf.String())
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == exitCodeReject {
+			return nil, &userhooks.RejectError{Message: message}
+		}
+		if message != "" {
+			return nil, fmt.Errorf("%w: %s", err, message)
+		}
+		return nil, err
+	}
+	if stdout.truncated {
+		return nil, fmt.Errorf("hook output exceeds %d bytes", maxOutputBytes)
+	}
+	return stdout.buf.Bytes(), nil
+}
+
+func shellCommand(command string) (string, []string) {
+	if runtime.GOOS == "windows" {
+		return "powershell", []string{"-NonInteractive", "-Command", command}
+	}
+	return "/bin/sh", []string{"-c", command}
+}
+
+type limitedWriter struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (w *limitedWriter) Write(data []byte) (int, error) {
+	size := len(data)
+	remaining := w.limit - w.buf.Len()
+	if size > remaining {
+		w.truncated = true
+		data = data[:max(remaining, 0)]
+	}
+	w.buf.Write(data)
+	return size, nil
+}
+
+// httpRunner 以 POST JSON 调用 HTTP 端点。
+type httpRunner struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+func (r *httpRunner) Run(ctx context.Context, _ []string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range r.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxOutputBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxOutputBytes {
+		return nil, fmt.Errorf("hook response exceeds %d bytes", maxOutputBytes)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("hook endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
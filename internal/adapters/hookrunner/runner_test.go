@@ -0,0 +1,121 @@
+package hookrunner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"fkteams/internal/app/config"
+	"fkteams/internal/app/userhooks"
+	"fkteams/internal/domain/event"
+	"fkteams/internal/domain/session"
+	"fkteams/internal/runtime/hooks"
+)
+
+func requireShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("command hook tests use /bin/sh")
+	}
+}
+
+func staticSettings(entries *[]config.HookConfig) func() []config.HookConfig {
+	return func() []config.HookConfig { return *entries }
+}
+
+func TestCommandHookRewritesMatchedToolArgsAndRejectsWithExitCode(t *testing.T) {
+	requireShell(t)
+	bus := hooks.NewBus()
+	entries := []config.HookConfig{
+		{
+			Name:    "rewrite",
+			Points:  []string{"before_tool_call"},
+			Matcher: "^execute$",
+			Command: `payload=$(cat); case "$payload" in *'"tool_name":"execute"'*) printf '{"payload":{"args":"{\"cmd\":\"%s\"}"}}' "$FKTEAMS_HOOK_POINT";; esac`,
+		},
+		{
+			Name:     "deny-delete",
+			Points:   []string{"before_tool_call"},
+			Matcher:  "^delete_file$",
+			Command:  `echo "deletion is not allowed" >&2; exit 2`,
+			Priority: 1,
+		},
+	}
+	manager := userhooks.NewManager(bus, staticSettings(&entries), New)
+	if err := manager.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	ctx := session.WithID(context.Background(), "session-1")
+
+	payload, err := bus.InvokeBeforeToolCall(ctx, hooks.BeforeToolCallPayload{ToolName: "execute", Args: `{"cmd":"ls"}`})
+	if err != nil {
+		t.Fatalf("InvokeBeforeToolCall: %v", err)
+	}
+	if payload.Args != `{"cmd":"before_tool_call"}` || payload.ToolName != "execute" {
+		t.Fatalf("payload = %#v", payload)
+	}
+
+	unmatched, err := bus.InvokeBeforeToolCall(ctx, hooks.BeforeToolCallPayload{ToolName: "read_file", Args: "{}"})
+	if err != nil || unmatched.Args != "{}" {
+		t.Fatalf("unmatched tool = %#v, %v", unmatched, err)
+	}
+
+	_, err = bus.InvokeBeforeToolCall(ctx, hooks.BeforeToolCallPayload{ToolName: "delete_file", Args: "{}"})
+	if err == nil || !strings.Contains(err.Error(), "deletion is not allowed") {
+		t.Fatalf("reject error = %v", err)
+	}
+}
+
+func TestHTTPHookReceivesPayloadAndSkipsEvent(t *testing.T) {
+	var got struct {
+		Hook      string          `json:"hook"`
+		HookPoint hooks.HookPoint `json:"hook_point"`
+		SessionID string          `json:"session_id"`
+		Payload   struct {
+			Event event.Event `json:"event"`
+		} `json:"payload"`
+	}
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if got.Payload.Event.Type == event.TypeAssistantText {
+			_, _ = w.Write([]byte(`{"action":"skip"}`))
+		}
+	}))
+	defer server.Close()
+
+	bus := hooks.NewBus()
+	entries := []config.HookConfig{{
+		Name:    "notify",
+		Points:  []string{"on_event"},
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}}
+	if err := userhooks.NewManager(bus, staticSettings(&entries), New).Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	ctx := session.WithID(context.Background(), "session-1")
+
+	_, emit, err := bus.InvokeEvent(ctx, event.Event{Type: event.TypeAssistantText, Content: "hi"})
+	if err != nil || emit {
+		t.Fatalf("delta emit = %v, err = %v", emit, err)
+	}
+	if auth != "Bearer token" || got.Hook != "notify" || got.HookPoint != hooks.HookOnEvent || got.SessionID != "session-1" {
+		t.Fatalf("request = %#v, auth = %q", got, auth)
+	}
+	if got.Payload.Event.Content != "hi" {
+		t.Fatalf("payload = %#v", got.Payload)
+	}
+
+	_, emit, err = bus.InvokeEvent(ctx, event.Event{Type: event.TypeAssistantCompleted})
+	if err != nil || !emit {
+		t.Fatalf("completed emit = %v, err = %v", emit, err)
+	}
+}
@@ -0,0 +1,246 @@
+// Package oidctest 提供进程内的 OpenID Connect 身份提供方，用于测试授权码登录流程。
+package oidctest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Server 是一个自动同意授权的身份提供方：授权端点直接用当前用户签发授权码并跳回客户端。
+type Server struct {
+	URL          string
+	ClientID     string
+	ClientSecret string
+
+	server *httptest.Server
+
+	mu     sync.Mutex
+	key    *rsa.PrivateKey
+	kid    string
+	claims map[string]any
+	grants map[string]grant
+	seq    int
+}
+
+type grant struct {
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	claims        map[string]any
+}
+
+// New 启动身份提供方，ClientSecret 为空时按公开客户端处理，只校验 PKCE。
+func New(clientID, clientSecret string) *Server {
+	s := &Server{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		grants:       make(map[string]grant),
+		claims:       map[string]any{"sub": "user-1"},
+	}
+	s.RotateKey()
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /jwks", s.jwks)
+	mux.HandleFunc("GET /authorize", s.authorize)
+	mux.HandleFunc("POST /token", s.token)
+	s.server = httptest.NewServer(mux)
+	s.URL = s.server.URL
+	return s
+}
+
+// Close 关闭身份提供方。
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// SetUser 设置之后授权时签发的用户声明，未包含 sub 时使用 user-1。
+func (s *Server) SetUser(claims map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.claims = make(map[string]any, len(claims)+1)
+	s.claims["sub"] = "user-1"
+	for name, value := range claims {
+		s.claims[name] = value
+	}
+}
+
+// RotateKey 生成新的签名密钥并替换 JWKS 中的旧密钥。
+func (s *Server) RotateKey() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: generate key: %v", err))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	s.key = key
+	s.kid = fmt.Sprintf("key-%d", s.seq)
+}
+
+// SignIDToken 用当前密钥对任意声明签名，用于构造过期、错误受众等异常令牌。
+func (s *Server) SignIDToken(claims map[string]any) string {
+	s.mu.Lock()
+	key, kid := s.key, s.kid
+	s.mu.Unlock()
+	return sign(key, kid, claims)
+}
+
+// IDTokenClaims 返回按标准字段补全的 ID Token 声明。
+func (s *Server) IDTokenClaims(nonce string, extra map[string]any) map[string]any {
+	now := time.Now()
+	claims := map[string]any{
+		"iss":   s.URL,
+		"aud":   s.ClientID,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": nonce,
+	}
+	for name, value := range extra {
+		claims[name] = value
+	}
+	return claims
+}
+
+func (s *Server) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                s.URL,
+		"authorization_endpoint":                s.URL + "/authorize",
+		"token_endpoint":                        s.URL + "/token",
+		"jwks_uri":                              s.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	key, kid := s.key, s.kid
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != s.ClientID || query.Get("response_type") != "code" {
+		http.Error(w, "invalid client or response type", http.StatusBadRequest)
+		return
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "PKCE S256 is required", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || !redirectURI.IsAbs() {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.seq++
+	code := fmt.Sprintf("code-%d", s.seq)
+	claims := make(map[string]any, len(s.claims))
+	for name, value := range s.claims {
+		claims[name] = value
+	}
+	s.grants[code] = grant{
+		redirectURI:   redirectURI.String(),
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		claims:        claims,
+	}
+	s.mu.Unlock()
+
+	values := redirectURI.Query()
+	values.Set("code", code)
+	values.Set("state", query.Get("state"))
+	redirectURI.RawQuery = values.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		tokenError(w, "invalid_request")
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != s.ClientID || subtle.ConstantTimeCompare([]byte(clientSecret), []byte(s.ClientSecret)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oidctest"`)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		tokenError(w, "unsupported_grant_type")
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	issued, ok := s.grants[code]
+	delete(s.grants, code)
+	s.mu.Unlock()
+	if !ok || issued.redirectURI != r.PostForm.Get("redirect_uri") {
+		tokenError(w, "invalid_grant")
+		return
+	}
+	challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(challenge[:]) != issued.codeChallenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	idToken := s.SignIDToken(s.IDTokenClaims(issued.nonce, issued.claims))
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": "access-" + code,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func sign(key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: encode claims: %v", err))
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: sign token: %v", err))
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	writeJSON(w, http.StatusBadRequest, map[string]string{"error": code})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
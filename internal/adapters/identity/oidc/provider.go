@@ -0,0 +1,177 @@
+// Package oidc 实现 OpenID Connect 授权码登录：发现文档、PKCE 授权码交换和 ID Token 签名校验。
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	discoveryPath     = "/.well-known/openid-configuration"
+	maxDocumentBytes  = 1 << 20
+	jwksRefreshPeriod = 30 * time.Second
+	defaultTimeout    = 15 * time.Second
+)
+
+// Config 描述向身份提供方注册的客户端。
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Provider 是通过发现文档初始化的身份提供方，JWKS 按需获取并缓存。
+type Provider struct {
+	issuer   string
+	authURL  string
+	tokenURL string
+	jwksURL  string
+	client   *http.Client
+	now      func() time.Time
+
+	mu          sync.Mutex
+	keys        []jsonWebKey
+	keysFetched time.Time
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Discover 读取 issuer 的发现文档。client 为 nil 时使用带超时的默认客户端。
+func Discover(ctx context.Context, issuer string, client *http.Client) (*Provider, error) {
+	issuer = strings.TrimSuffix(strings.TrimSpace(issuer), "/")
+	if issuer == "" {
+		return nil, errors.New("oidc issuer is required")
+	}
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	var doc discoveryDocument
+	if err := getJSON(ctx, client, issuer+discoveryPath, &doc); err != nil {
+		return nil, fmt.Errorf("fetch oidc discovery document: %w", err)
+	}
+	// 发现文档声明的 issuer 必须与配置一致，防止被引导到其他提供方签发的令牌
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("oidc issuer mismatch: configured %q, discovery document reports %q", issuer, doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("oidc discovery document is missing authorization_endpoint, token_endpoint or jwks_uri")
+	}
+	return &Provider{
+		issuer:   doc.Issuer,
+		authURL:  doc.AuthorizationEndpoint,
+		tokenURL: doc.TokenEndpoint,
+		jwksURL:  doc.JWKSURI,
+		client:   client,
+		now:      time.Now,
+	}, nil
+}
+
+// Issuer 返回发现文档中的 issuer。
+func (p *Provider) Issuer() string {
+	return p.issuer
+}
+
+func (p *Provider) oauth2Config(cfg Config) *oauth2.Config {
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "profile", "email"}
+	}
+	return &oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       scopes,
+		Endpoint:     oauth2.Endpoint{AuthURL: p.authURL, TokenURL: p.tokenURL},
+	}
+}
+
+// AuthCodeURL 返回跳转到身份提供方的授权地址，使用 S256 PKCE 和 nonce。
+func (p *Provider) AuthCodeURL(cfg Config, state, nonce, verifier string) string {
+	return p.oauth2Config(cfg).AuthCodeURL(state,
+		oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	)
+}
+
+// Exchange 用授权码和 PKCE verifier 换取原始 ID Token。
+func (p *Provider) Exchange(ctx context.Context, cfg Config, code, verifier string) (string, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+	token, err := p.oauth2Config(cfg).Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return "", fmt.Errorf("exchange authorization code: %w", err)
+	}
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return "", errors.New("token response does not contain an id_token")
+	}
+	return rawIDToken, nil
+}
+
+// NewVerifier 生成 PKCE code verifier。
+func NewVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+// signingKeys 返回缓存的 JWKS；refresh 为 true 时在冷却期外重新获取，用于处理密钥轮换。
+func (p *Provider) signingKeys(ctx context.Context, refresh bool) ([]jsonWebKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.keys != nil && (!refresh || p.now().Sub(p.keysFetched) < jwksRefreshPeriod) {
+		return p.keys, nil
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, p.client, p.jwksURL, &set); err != nil {
+		if p.keys != nil {
+			return p.keys, nil
+		}
+		return nil, fmt.Errorf("fetch oidc jwks: %w", err)
+	}
+	p.keys = set.Keys
+	p.keysFetched = p.now()
+	return p.keys, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentBytes+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxDocumentBytes {
+		return fmt.Errorf("GET %s: response exceeds %d bytes", url, maxDocumentBytes)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("decode %s: %w", url, err)
+	}
+	return nil
+}
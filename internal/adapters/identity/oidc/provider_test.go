@@ -0,0 +1,148 @@
+package oidc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"fkteams/internal/adapters/identity/oidc/oidctest"
+)
+
+func TestAuthorizationCodeFlowWithPKCE(t *testing.T) {
+	idp := oidctest.New("fkteams", "client-secret")
+	defer idp.Close()
+	idp.SetUser(map[string]any{"sub": "u-42", "preferred_username": "alice", "groups": []string{"dev", "ops"}})
+
+	ctx := context.Background()
+	provider, err := Discover(ctx, idp.URL+"/", nil)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	cfg := Config{
+		Issuer:       idp.URL,
+		ClientID:     "fkteams",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://app.test/callback",
+	}
+	verifier := NewVerifier()
+	authURL, err := url.Parse(provider.AuthCodeURL(cfg, "state-1", "nonce-1", verifier))
+	if err != nil {
+		t.Fatalf("parse auth url: %v", err)
+	}
+	query := authURL.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("nonce") != "nonce-1" || !strings.Contains(query.Get("scope"), "openid") {
+		t.Fatalf("auth url query = %v", query)
+	}
+
+	code := authorize(t, authURL.String())
+	if _, err := provider.Exchange(ctx, cfg, code, NewVerifier()); err == nil {
+		t.Fatal("expected exchange with wrong verifier to fail")
+	}
+	code = authorize(t, authURL.String())
+	rawIDToken, err := provider.Exchange(ctx, cfg, code, verifier)
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	claims, err := provider.Verify(ctx, rawIDToken, "fkteams", "nonce-1")
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if claims.String("sub") != "u-42" || claims.String("preferred_username") != "alice" {
+		t.Fatalf("claims = %#v", claims)
+	}
+	if groups := claims.Strings("groups"); len(groups) != 2 || groups[1] != "ops" {
+		t.Fatalf("groups = %#v", groups)
+	}
+	if _, err := provider.Verify(ctx, rawIDToken, "fkteams", "other-nonce"); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected nonce mismatch, got %v", err)
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	idp := oidctest.New("fkteams", "")
+	defer idp.Close()
+	ctx := context.Background()
+	provider, err := Discover(ctx, idp.URL, nil)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	valid := idp.SignIDToken(idp.IDTokenClaims("n", map[string]any{"sub": "u-1"}))
+	if _, err := provider.Verify(ctx, valid, "fkteams", "n"); err != nil {
+		t.Fatalf("verify valid token: %v", err)
+	}
+
+	parts := strings.Split(valid, ".")
+	noneHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"admin"}`)) + "." + parts[2]
+	cases := map[string]string{
+		"wrong audience": idp.SignIDToken(idp.IDTokenClaims("n", map[string]any{"sub": "u-1", "aud": "other"})),
+		"wrong issuer":   idp.SignIDToken(idp.IDTokenClaims("n", map[string]any{"sub": "u-1", "iss": "https://evil.test"})),
+		"expired":        idp.SignIDToken(idp.IDTokenClaims("n", map[string]any{"sub": "u-1", "exp": time.Now().Add(-time.Hour).Unix()})),
+		"missing sub":    idp.SignIDToken(idp.IDTokenClaims("n", nil)),
+		"multi audience": idp.SignIDToken(idp.IDTokenClaims("n", map[string]any{"sub": "u-1", "aud": []string{"fkteams", "other"}})),
+		"alg none":       noneHeader + "." + parts[1] + ".",
+		"tampered":       tampered,
+		"malformed":      "not-a-token",
+	}
+	for name, token := range cases {
+		if _, err := provider.Verify(ctx, token, "fkteams", "n"); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: expected ErrInvalidToken, got %v", name, err)
+		}
+	}
+}
+
+func TestVerifyRefreshesKeysAfterRotation(t *testing.T) {
+	idp := oidctest.New("fkteams", "")
+	defer idp.Close()
+	ctx := context.Background()
+	provider, err := Discover(ctx, idp.URL, nil)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if _, err := provider.Verify(ctx, idp.SignIDToken(idp.IDTokenClaims("n", map[string]any{"sub": "u-1"})), "fkteams", "n"); err != nil {
+		t.Fatalf("verify before rotation: %v", err)
+	}
+
+	idp.RotateKey()
+	rotated := idp.SignIDToken(idp.IDTokenClaims("n", map[string]any{"sub": "u-1"}))
+	provider.keysFetched = time.Now().Add(-time.Hour)
+	if _, err := provider.Verify(ctx, rotated, "fkteams", "n"); err != nil {
+		t.Fatalf("verify after rotation: %v", err)
+	}
+}
+
+func TestDiscoverRejectsIssuerMismatch(t *testing.T) {
+	idp := oidctest.New("fkteams", "")
+	defer idp.Close()
+	issuer := strings.Replace(idp.URL, "127.0.0.1", "localhost", 1)
+	if _, err := Discover(context.Background(), issuer, nil); err == nil || !strings.Contains(err.Error(), "issuer mismatch") {
+		t.Fatalf("expected issuer mismatch, got %v", err)
+	}
+}
+
+// authorize 访问授权地址并返回跳转中携带的授权码。
+func authorize(t *testing.T, authURL string) string {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse redirect: %v", err)
+	}
+	if location.Query().Get("state") != "state-1" {
+		t.Fatalf("redirect state = %q", location.Query().Get("state"))
+	}
+	return location.Query().Get("code")
+}
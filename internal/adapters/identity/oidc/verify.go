@@ -0,0 +1,272 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// clockSkew 是校验 exp/iat 时允许的时钟偏差。
+const clockSkew = time.Minute
+
+// ErrInvalidToken 表示 ID Token 签名或声明校验失败。
+var ErrInvalidToken = errors.New("invalid id token")
+
+// Claims 是 ID Token 的声明集合。
+type Claims map[string]any
+
+// String 返回字符串声明，不存在或类型不符时返回空。
+func (c Claims) String(name string) string {
+	value, _ := c[name].(string)
+	return value
+}
+
+// Strings 返回字符串或字符串数组声明，用于 groups、roles 等多值声明。
+func (c Claims) Strings(name string) []string {
+	switch value := c[name].(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			if s, ok := item.(string); ok && s != "" {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwsHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type signatureAlgorithm struct {
+	kty  string
+	hash crypto.Hash
+	pss  bool
+	// curve 仅用于 ECDSA，签名为定长 r||s
+	curve elliptic.Curve
+}
+
+// 只接受非对称签名算法，拒绝 none 和 HS*，避免用公开的 client_id 伪造令牌
+var signatureAlgorithms = map[string]signatureAlgorithm{
+	"RS256": {kty: "RSA", hash: crypto.SHA256},
+	"RS384": {kty: "RSA", hash: crypto.SHA384},
+	"RS512": {kty: "RSA", hash: crypto.SHA512},
+	"PS256": {kty: "RSA", hash: crypto.SHA256, pss: true},
+	"PS384": {kty: "RSA", hash: crypto.SHA384, pss: true},
+	"PS512": {kty: "RSA", hash: crypto.SHA512, pss: true},
+	"ES256": {kty: "EC", hash: crypto.SHA256, curve: elliptic.P256()},
+	"ES384": {kty: "EC", hash: crypto.SHA384, curve: elliptic.P384()},
+	"ES512": {kty: "EC", hash: crypto.SHA512, curve: elliptic.P521()},
+}
+
+// Verify 校验 ID Token 的签名、issuer、audience、有效期和 nonce，返回其声明。
+func (p *Provider) Verify(ctx context.Context, rawIDToken, clientID, nonce string) (Claims, error) {
+	parts := strings.Split(rawIDToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+	var header jwsHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: decode header: %v", ErrInvalidToken, err)
+	}
+	alg, ok := signatureAlgorithms[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported signing algorithm %q", ErrInvalidToken, header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: decode signature: %v", ErrInvalidToken, err)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	if err := p.verifySignature(ctx, header, alg, signed, signature); err != nil {
+		return nil, err
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: decode claims: %v", ErrInvalidToken, err)
+	}
+	if err := p.validateClaims(claims, clientID, nonce); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (p *Provider) verifySignature(ctx context.Context, header jwsHeader, alg signatureAlgorithm, signed, signature []byte) error {
+	keys, err := p.signingKeys(ctx, false)
+	if err != nil {
+		return err
+	}
+	key, found := matchKey(keys, header, alg)
+	if !found {
+		// 未找到 kid 时可能是身份提供方轮换了密钥，重新获取一次
+		if keys, err = p.signingKeys(ctx, true); err != nil {
+			return err
+		}
+		if key, found = matchKey(keys, header, alg); !found {
+			return fmt.Errorf("%w: no signing key matches kid %q", ErrInvalidToken, header.Kid)
+		}
+	}
+	hasher := alg.hash.New()
+	hasher.Write(signed)
+	digest := hasher.Sum(nil)
+
+	switch alg.kty {
+	case "RSA":
+		publicKey, err := key.rsaPublicKey()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+		}
+		if alg.pss {
+			err = rsa.VerifyPSS(publicKey, alg.hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			err = rsa.VerifyPKCS1v15(publicKey, alg.hash, digest, signature)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: signature verification failed", ErrInvalidToken)
+		}
+	case "EC":
+		publicKey, err := key.ecdsaPublicKey(alg.curve)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+		}
+		size := (alg.curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("%w: signature verification failed", ErrInvalidToken)
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(publicKey, digest, r, s) {
+			return fmt.Errorf("%w: signature verification failed", ErrInvalidToken)
+		}
+	}
+	return nil
+}
+
+func matchKey(keys []jsonWebKey, header jwsHeader, alg signatureAlgorithm) (jsonWebKey, bool) {
+	var candidates []jsonWebKey
+	for _, key := range keys {
+		if key.Kty != alg.kty || (key.Use != "" && key.Use != "sig") || (key.Alg != "" && key.Alg != header.Alg) {
+			continue
+		}
+		if header.Kid != "" && key.Kid == header.Kid {
+			return key, true
+		}
+		candidates = append(candidates, key)
+	}
+	// 令牌未声明 kid 时，只有唯一候选密钥才能确定签名者
+	if header.Kid == "" && len(candidates) == 1 {
+		return candidates[0], true
+	}
+	return jsonWebKey{}, false
+}
+
+func (p *Provider) validateClaims(claims Claims, clientID, nonce string) error {
+	if claims.String("iss") != p.issuer {
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.String("iss"))
+	}
+	audience := claims.Strings("aud")
+	if !slices.Contains(audience, clientID) {
+		return fmt.Errorf("%w: token audience does not include client %q", ErrInvalidToken, clientID)
+	}
+	if len(audience) > 1 && claims.String("azp") != clientID {
+		return fmt.Errorf("%w: token authorized party is not client %q", ErrInvalidToken, clientID)
+	}
+	if claims.String("sub") == "" {
+		return fmt.Errorf("%w: missing sub claim", ErrInvalidToken)
+	}
+	now := p.now()
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	}
+	if !now.Before(exp.Add(clockSkew)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidToken)
+	}
+	if iat, ok := numericDate(claims["iat"]); ok && iat.After(now.Add(clockSkew)) {
+		return fmt.Errorf("%w: token issued in the future", ErrInvalidToken)
+	}
+	if subtle.ConstantTimeCompare([]byte(claims.String("nonce")), []byte(nonce)) != 1 {
+		return fmt.Errorf("%w: nonce mismatch", ErrInvalidToken)
+	}
+	return nil
+}
+
+func numericDate(value any) (time.Time, bool) {
+	seconds, ok := value.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
+
+func decodeSegment(segment string, target any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil || len(n) == 0 {
+		return nil, fmt.Errorf("invalid RSA modulus for key %q", k.Kid)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, fmt.Errorf("invalid RSA exponent for key %q", k.Kid)
+	}
+	exponent := int(new(big.Int).SetBytes(e).Int64())
+	modulus := new(big.Int).SetBytes(n)
+	if modulus.BitLen() < 2048 {
+		return nil, fmt.Errorf("RSA key %q is shorter than 2048 bits", k.Kid)
+	}
+	return &rsa.PublicKey{N: modulus, E: exponent}, nil
+}
+
+func (k jsonWebKey) ecdsaPublicKey(curve elliptic.Curve) (*ecdsa.PublicKey, error) {
+	if k.Crv != curve.Params().Name {
+		return nil, fmt.Errorf("key %q uses curve %q, want %q", k.Kid, k.Crv, curve.Params().Name)
+	}
+	x, errX := base64.RawURLEncoding.DecodeString(k.X)
+	y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+	if errX != nil || errY != nil {
+		return nil, fmt.Errorf("invalid EC coordinates for key %q", k.Kid)
+	}
+	publicKey := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	if _, err := publicKey.ECDH(); err != nil {
+		return nil, fmt.Errorf("EC key %q is not on curve %s", k.Kid, k.Crv)
+	}
+	return publicKey, nil
+}
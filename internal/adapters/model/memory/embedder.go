@@ -0,0 +1,148 @@
+package memorymodel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"fkteams/internal/app/config"
+	memoryport "fkteams/internal/ports/memory"
+)
+
+const (
+	defaultOllamaBaseURL = "http://localhost:11434"
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	maxEmbedResponse     = 32 << 20
+	embedRequestTimeout  = 30 * time.Second
+)
+
+// NewEmbedder 按配置创建长期记忆的向量模型，provider 为 none 时返回 nil，仅使用 BM25 检索。
+func NewEmbedder(cfg config.MemoryEmbedding) (memoryport.Embedder, error) {
+	client := &http.Client{Timeout: embedRequestTimeout}
+	switch strings.ToLower(strings.TrimSpace(cfg.Provider)) {
+	case "", "hash":
+		return NewHashEmbedder(cfg.Dimensions), nil
+	case "none":
+		return nil, nil
+	case "openai":
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("memory.embedding.model is required for provider openai")
+		}
+		baseURL := strings.TrimRight(cfg.BaseURL, "/")
+		if baseURL == "" {
+			baseURL = defaultOpenAIBaseURL
+		}
+		return &openAIEmbedder{client: client, baseURL: baseURL, apiKey: cfg.APIKey, model: cfg.Model, dimensions: cfg.Dimensions}, nil
+	case "ollama":
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("memory.embedding.model is required for provider ollama")
+		}
+		baseURL := strings.TrimRight(cfg.BaseURL, "/")
+		if baseURL == "" {
+			baseURL = defaultOllamaBaseURL
+		}
+		return &ollamaEmbedder{client: client, baseURL: baseURL, model: cfg.Model}, nil
+	default:
+		return nil, fmt.Errorf("unsupported memory embedding provider %q", cfg.Provider)
+	}
+}
+
+// openAIEmbedder 调用 OpenAI 兼容的 /embeddings 接口
+type openAIEmbedder struct {
+	client     *http.Client
+	baseURL    string
+	apiKey     string
+	model      string
+	dimensions int
+}
+
+func (e *openAIEmbedder) Model() string {
+	if e.dimensions > 0 {
+		return fmt.Sprintf("openai:%s:%d", e.model, e.dimensions)
+	}
+	return "openai:" + e.model
+}
+
+func (e *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	payload := map[string]any{"model": e.model, "input": texts}
+	if e.dimensions > 0 {
+		payload["dimensions"] = e.dimensions
+	}
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := postJSON(ctx, e.client, e.baseURL+"/embeddings", e.apiKey, payload, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("embeddings response has %d items for %d inputs", len(resp.Data), len(texts))
+	}
+	sort.SliceStable(resp.Data, func(i, j int) bool { return resp.Data[i].Index < resp.Data[j].Index })
+	vectors := make([][]float32, len(resp.Data))
+	for i, item := range resp.Data {
+		vectors[i] = item.Embedding
+	}
+	return vectors, nil
+}
+
+// ollamaEmbedder 调用 Ollama 的 /api/embed 接口
+type ollamaEmbedder struct {
+	client  *http.Client
+	baseURL string
+	model   string
+}
+
+func (e *ollamaEmbedder) Model() string { return "ollama:" + e.model }
+
+func (e *ollamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := postJSON(ctx, e.client, e.baseURL+"/api/embed", "", map[string]any{"model": e.model, "input": texts}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("embed response has %d items for %d inputs", len(resp.Embeddings), len(texts))
+	}
+	return resp.Embeddings, nil
+}
+
+func postJSON(ctx context.Context, client *http.Client, url, apiKey string, payload, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxEmbedResponse))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("embedding request returned %s: %s", resp.Status, strings.TrimSpace(string(data[:min(len(data), 512)])))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode embedding response: %w", err)
+	}
+	return nil
+}
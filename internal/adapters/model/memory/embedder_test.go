@@ -0,0 +1,115 @@
+package memorymodel
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"fkteams/internal/app/config"
+)
+
+func TestNewEmbedderProviders(t *testing.T) {
+	embedder, err := NewEmbedder(config.MemoryEmbedding{})
+	if err != nil || embedder == nil || embedder.Model() != "hash:v1:512" {
+		t.Fatalf("default embedder = %v, %v", embedder, err)
+	}
+	if embedder, err := NewEmbedder(config.MemoryEmbedding{Provider: "none"}); err != nil || embedder != nil {
+		t.Fatalf("none embedder = %v, %v", embedder, err)
+	}
+	if _, err := NewEmbedder(config.MemoryEmbedding{Provider: "ollama"}); err == nil {
+		t.Fatal("ollama without model should fail")
+	}
+	if _, err := NewEmbedder(config.MemoryEmbedding{Provider: "bogus"}); err == nil {
+		t.Fatal("unknown provider should fail")
+	}
+}
+
+func TestHashEmbedderMatchesOverlappingText(t *testing.T) {
+	embedder := NewHashEmbedder(0)
+	vectors, err := embedder.Embed(context.Background(), []string{
+		"数据库迁移统一使用 goose",
+		"database migrations use goose",
+		"前端样式使用 tailwind",
+	})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	query, _ := embedder.Embed(context.Background(), []string{"数据库 迁移 工具 goose"})
+	related, unrelated := cosine(query[0], vectors[0]), cosine(query[0], vectors[2])
+	if related <= unrelated {
+		t.Fatalf("related similarity %.3f <= unrelated %.3f", related, unrelated)
+	}
+	if english := cosine(query[0], vectors[1]); english <= unrelated {
+		t.Fatalf("shared english term similarity %.3f <= unrelated %.3f", english, unrelated)
+	}
+}
+
+func TestOpenAIEmbedderOrdersByIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer sk-test" {
+			t.Errorf("unexpected request %s auth=%q", r.URL.Path, r.Header.Get("Authorization"))
+		}
+		var req struct {
+			Model      string   `json:"model"`
+			Input      []string `json:"input"`
+			Dimensions int      `json:"dimensions"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "text-embedding-3-small" || len(req.Input) != 2 || req.Dimensions != 2 {
+			t.Errorf("unexpected payload %#v", req)
+		}
+		_, _ = w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	embedder, err := NewEmbedder(config.MemoryEmbedding{Provider: "openai", Model: "text-embedding-3-small", BaseURL: server.URL + "/v1/", APIKey: "sk-test", Dimensions: 2})
+	if err != nil {
+		t.Fatalf("NewEmbedder: %v", err)
+	}
+	if embedder.Model() != "openai:text-embedding-3-small:2" {
+		t.Fatalf("Model = %q", embedder.Model())
+	}
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if vectors[0][0] != 1 || vectors[1][1] != 1 {
+		t.Fatalf("vectors = %v, want ordered by index", vectors)
+	}
+}
+
+func TestOllamaEmbedder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embed" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"embeddings":[[0.5,0.5]]}`))
+	}))
+	defer server.Close()
+
+	embedder, err := NewEmbedder(config.MemoryEmbedding{Provider: "ollama", Model: "bge-m3", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewEmbedder: %v", err)
+	}
+	vectors, err := embedder.Embed(context.Background(), []string{"a"})
+	if err != nil || len(vectors) != 1 || len(vectors[0]) != 2 {
+		t.Fatalf("Embed = %v, %v", vectors, err)
+	}
+	if _, err := embedder.Embed(context.Background(), []string{"a", "b"}); err == nil {
+		t.Fatal("mismatched response length should fail")
+	}
+}
+
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	return dot / math.Sqrt(na*nb)
+}
@@ -0,0 +1,96 @@
+package memorymodel
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode"
+
+	memoryport "fkteams/internal/ports/memory"
+)
+
+const defaultHashDimensions = 512
+
+// hashEmbedder 是无需网络的离线向量模型：把中文单字与双字、英文单词与字符三元组
+// 哈希到固定维度（feature hashing），能匹配词形变化和中英文混排里的部分改写
+type hashEmbedder struct {
+	dims int
+}
+
+// NewHashEmbedder 创建离线哈希向量模型，dims 不大于 0 时使用 512 维。
+func NewHashEmbedder(dims int) memoryport.Embedder {
+	if dims <= 0 {
+		dims = defaultHashDimensions
+	}
+	return &hashEmbedder{dims: dims}
+}
+
+func (e *hashEmbedder) Model() string { return fmt.Sprintf("hash:v1:%d", e.dims) }
+
+func (e *hashEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, e.dims)
+		for _, feature := range hashFeatures(text) {
+			h := fnv.New64a()
+			_, _ = h.Write([]byte(feature.text))
+			sum := h.Sum64()
+			// 最高位决定符号，减小哈希碰撞带来的偏差
+			sign := float32(1)
+			if sum>>63 == 1 {
+				sign = -1
+			}
+			vector[sum%uint64(e.dims)] += sign * feature.weight
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+type hashFeature struct {
+	text   string
+	weight float32
+}
+
+// hashFeatures 提取文本特征：中文取单字和相邻双字，其他文字取小写单词和字符三元组
+func hashFeatures(text string) []hashFeature {
+	var features []hashFeature
+	var han []rune
+	flushHan := func() {
+		for i, r := range han {
+			features = append(features, hashFeature{text: "h:" + string(r), weight: 0.5})
+			if i+1 < len(han) {
+				features = append(features, hashFeature{text: "h:" + string(han[i:i+2]), weight: 1})
+			}
+		}
+		han = han[:0]
+	}
+	var word []rune
+	flushWord := func() {
+		if len(word) > 0 {
+			features = append(features, hashFeature{text: "w:" + string(word), weight: 1})
+			padded := []rune("^" + string(word) + "$")
+			for i := 0; i+3 <= len(padded); i++ {
+				features = append(features, hashFeature{text: "t:" + string(padded[i:i+3]), weight: 0.3})
+			}
+		}
+		word = word[:0]
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, r)
+		default:
+			flushHan()
+			flushWord()
+		}
+	}
+	flushHan()
+	flushWord()
+	return features
+}
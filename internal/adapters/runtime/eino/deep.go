@@ -3,7 +3,7 @@ package eino
 import (
 	"context"
 	"fkteams/internal/adapters/runtime/eino/middlewares/fkfs"
-	"fkteams/internal/app/appdata"
+	"fkteams/internal/app/project"
 	runtimeport "fkteams/internal/ports/runtime"
 	"fmt"
 
@@ -33,6 +33,11 @@ func NewDeepAgent(ctx context.Context, cfg *runtimeport.DeepAgentConfig) (runtim
 	if err != nil {
 		return nil, fmt.Errorf("adapt middleware: %w", err)
 	}
+	runnerToolMiddlewares, err := adaptToolMiddlewaresForRunner(cfg.ToolMiddlewares)
+	if err != nil {
+		return nil, fmt.Errorf("adapt tool middleware: %w", err)
+	}
+	runnerToolMiddlewares = append([]compose.ToolMiddleware{toolAgentNameMiddleware(cfg.Name)}, runnerToolMiddlewares...)
 
 	deepCfg := &deep.Config{
 		Name:                         cfg.Name,
@@ -51,19 +56,21 @@ func NewDeepAgent(ctx context.Context, cfg *runtimeport.DeepAgentConfig) (runtim
 		ToolsConfig: adk.ToolsConfig{
 			EmitInternalEvents: cfg.EmitInternalEvents,
 			ToolsNodeConfig: compose.ToolsNodeConfig{
-				Tools: runnerTools,
+				Tools:               runnerTools,
+				ToolCallMiddlewares: runnerToolMiddlewares,
 			},
 		},
 	}
 	if cfg.Workspace.Enabled {
-		backend, err := fkfs.NewLocalBackend(appdata.WorkspaceDir())
+		backend, err := fkfs.NewLocalBackend(project.WorkspaceDir(ctx))
 		if err != nil {
 			return nil, fmt.Errorf("init deep workspace backend: %w", err)
 		}
 		deepCfg.Backend = backend
 	}
-	if cfg.Shell.Enabled {
-		deepCfg.Shell = fkfs.NewLocalShell(appdata.WorkspaceDir(), cfg.Shell.Timeout)
+	// 限定在成员文件区中的会话不提供未经沙箱的 shell
+	if _, confined := project.UserArea(ctx); cfg.Shell.Enabled && !confined {
+		deepCfg.Shell = fkfs.NewLocalShell(project.WorkspaceDir(ctx), cfg.Shell.Timeout)
 	}
 
 	agent, err := deep.New(ctx, deepCfg)
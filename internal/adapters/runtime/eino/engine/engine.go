@@ -14,6 +14,7 @@ import (
 	"fkteams/internal/adapters/runtime/eino/middlewares/tools/destructiveguard"
 	hooktools "fkteams/internal/adapters/runtime/eino/middlewares/tools/hooks"
 	"fkteams/internal/adapters/runtime/eino/middlewares/tools/patch"
+	"fkteams/internal/adapters/runtime/eino/middlewares/tools/permission"
 	"fkteams/internal/adapters/runtime/eino/middlewares/tools/trimresult"
 	"fkteams/internal/adapters/runtime/eino/middlewares/tools/warperror"
 	runtimeport "fkteams/internal/ports/runtime"
@@ -97,6 +98,7 @@ func (e *Engine) DefaultAgentMiddlewares(ctx context.Context) ([]runtimeport.Age
 func (e *Engine) DefaultToolMiddlewares() []runtimeport.ToolMiddleware {
 	return []runtimeport.ToolMiddleware{
 		e.newHookToolMiddleware(),
+		e.newPermissionMiddleware(),
 		e.newDestructiveGuardMiddleware(),
 	}
 }
@@ -159,6 +161,10 @@ func (e *Engine) newHookToolMiddleware() runtimeport.ToolMiddleware {
 	return hooktools.New()
 }
 
+func (e *Engine) newPermissionMiddleware() runtimeport.ToolMiddleware {
+	return permission.New()
+}
+
 func (e *Engine) MCPTools(ctx context.Context, cli *client.Client) ([]runtimeport.Tool, error) {
 	tools, err := einoMCP.GetTools(ctx, &einoMCP.Config{Cli: cli})
 	if err != nil {
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"sync"
 
+	hookport "fkteams/internal/ports/hooks"
+
 	"github.com/cloudwego/eino/adk"
 	"github.com/cloudwego/eino/schema"
 )
@@ -57,6 +59,12 @@ func (e *errorSafeAgent) wrap(ctx context.Context, innerIter *adk.AsyncIterator[
 			}
 
 			if event.Err != nil {
+				// 回合终止错误需要传到最外层运行器，不能被转换为文本交给 coordinator 继续处理。
+				var abort *hookport.AbortError
+				if errors.As(event.Err, &abort) {
+					gen.Send(event)
+					break
+				}
 				errMsg := formatAgentError(e.inner.Name(ctx), event.Err, collector.String())
 
 				gen.Send(&adk.AgentEvent{
@@ -3,11 +3,13 @@ package eino
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
 	domainevent "fkteams/internal/domain/event"
 	domainmessage "fkteams/internal/domain/message"
+	hookport "fkteams/internal/ports/hooks"
 	runtimeport "fkteams/internal/ports/runtime"
 	checkpointmemory "fkteams/internal/runtime/checkpoint/memory"
 	runtimeevents "fkteams/internal/runtime/events"
@@ -107,6 +109,38 @@ func TestRunnerEmitsErrorAndClosesLifecycleOnModelError(t *testing.T) {
 	}
 }
 
+func TestRunnerEmitsTurnFailedOnHookAbort(t *testing.T) {
+	ctx := context.Background()
+	abortErr := fmt.Errorf("hook budget failed: %w", &hookport.AbortError{Reason: "daily budget exceeded"})
+	model := testmodel.New().EnqueueGenerate(domainmessage.Message{}, abortErr)
+	agent, err := NewChatModelAgent(ctx, &runtimeport.ChatAgentConfig{
+		Name:          "lifecycle-abort",
+		Description:   "lifecycle abort",
+		Model:         model,
+		MaxIterations: 2,
+	})
+	if err != nil {
+		t.Fatalf("create agent: %v", err)
+	}
+
+	got, err := runAgentForTestResult(t, ctx, agent, false)
+	var abort *hookport.AbortError
+	if !errors.As(err, &abort) {
+		t.Fatalf("run error = %v, want abort; events=%#v", err, got)
+	}
+	failedIdx := requireEventIndex(t, got, func(event domainevent.Event) bool {
+		return event.Type == domainevent.TypeTurnFailed && event.Error == "daily budget exceeded"
+	}, "turn failed")
+	for _, event := range got {
+		if event.Type == domainevent.TypeTurnCompleted {
+			t.Fatalf("aborted turn should not complete: %#v", got)
+		}
+	}
+	if got[len(got)-1].Type != domainevent.TypeAgentCompleted || failedIdx != len(got)-2 {
+		t.Fatalf("turn_failed should precede final agent_end: %#v", got)
+	}
+}
+
 func TestStreamingUsageAttachesToAssistantCompleted(t *testing.T) {
 	reader, writer := schema.Pipe[*schema.Message](1)
 	if stopped := writer.Send(&schema.Message{
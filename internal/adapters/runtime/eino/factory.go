@@ -30,6 +30,7 @@ func NewChatModelAgent(ctx context.Context, cfg *runtimeport.ChatAgentConfig) (r
 	if err != nil {
 		return nil, fmt.Errorf("adapt tool middleware: %w", err)
 	}
+	runnerToolMiddlewares = append([]compose.ToolMiddleware{toolAgentNameMiddleware(cfg.Name)}, runnerToolMiddlewares...)
 
 	agentCfg := &adk.ChatModelAgentConfig{
 		Name:             cfg.Name,
@@ -106,6 +107,22 @@ func adaptToolMiddlewaresForRunner(middlewares []runtimeport.ToolMiddleware) ([]
 	return result, nil
 }
 
+// toolAgentNameMiddleware 将智能体名注入工具调用上下文，供后续工具中间件使用。
+func toolAgentNameMiddleware(agentName string) compose.ToolMiddleware {
+	return compose.ToolMiddleware{
+		Invokable: func(next compose.InvokableToolEndpoint) compose.InvokableToolEndpoint {
+			return func(ctx context.Context, input *compose.ToolInput) (*compose.ToolOutput, error) {
+				return next(runtimeport.WithToolAgentName(ctx, agentName), input)
+			}
+		},
+		Streamable: func(next compose.StreamableToolEndpoint) compose.StreamableToolEndpoint {
+			return func(ctx context.Context, input *compose.ToolInput) (*compose.StreamToolOutput, error) {
+				return next(runtimeport.WithToolAgentName(ctx, agentName), input)
+			}
+		},
+	}
+}
+
 func adaptUnknownToolHandlerForRunner(agentName string, handler runtimeport.UnknownToolHandler) func(context.Context, string, string) (string, error) {
 	if handler == nil {
 		return nil
@@ -28,6 +28,7 @@ func adaptMessagesForRunner(messages []domainmessage.Message) []adk.Message {
 		if len(msg.ToolCalls) > 0 {
 			m.ToolCalls = adaptToolCallsForRunner(msg.ToolCalls)
 		}
+		adaptResponseMetaForRunner(m, msg.ResponseMeta)
 		result = append(result, m)
 	}
 	return result
@@ -50,9 +51,53 @@ func adaptMessageFromRunner(msg *schema.Message) domainmessage.Message {
 		ToolName:         msg.ToolName,
 		ContentParts:     parts,
 		Name:             msg.Name,
+		ResponseMeta:     adaptResponseMetaFromRunner(msg),
 	}
 }
 
+// responseModelExtraKey 在 schema.Message.Extra 中记录实际应答的模型。
+const responseModelExtraKey = "fkteams_response_model"
+
+func adaptResponseMetaForRunner(m *schema.Message, meta *domainmessage.ResponseMeta) {
+	if meta == nil {
+		return
+	}
+	if meta.Usage != nil {
+		m.ResponseMeta = &schema.ResponseMeta{Usage: &schema.TokenUsage{
+			PromptTokens:     meta.Usage.PromptTokens,
+			CompletionTokens: meta.Usage.CompletionTokens,
+			TotalTokens:      meta.Usage.TotalTokens,
+		}}
+	}
+	if meta.Model != "" {
+		m.Extra = map[string]any{responseModelExtraKey: meta.Model}
+	}
+}
+
+func adaptResponseMetaFromRunner(msg *schema.Message) *domainmessage.ResponseMeta {
+	model := responseModel(msg)
+	var usage *domainmessage.TokenUsage
+	if msg.ResponseMeta != nil && msg.ResponseMeta.Usage != nil {
+		usage = &domainmessage.TokenUsage{
+			PromptTokens:     msg.ResponseMeta.Usage.PromptTokens,
+			CompletionTokens: msg.ResponseMeta.Usage.CompletionTokens,
+			TotalTokens:      msg.ResponseMeta.Usage.TotalTokens,
+		}
+	}
+	if model == "" && usage == nil {
+		return nil
+	}
+	return &domainmessage.ResponseMeta{Model: model, Usage: usage}
+}
+
+func responseModel(msg *schema.Message) string {
+	if msg == nil {
+		return ""
+	}
+	model, _ := msg.Extra[responseModelExtraKey].(string)
+	return model
+}
+
 func adaptRoleForRunner(role domainmessage.Role) schema.RoleType {
 	switch role {
 	case domainmessage.RoleSystem:
@@ -9,7 +9,7 @@ import (
 
 	einoruntime "fkteams/internal/adapters/runtime/eino"
 	"fkteams/internal/adapters/runtime/eino/middlewares/fkfs"
-	"fkteams/internal/app/appdata"
+	"fkteams/internal/app/project"
 	runtimeport "fkteams/internal/ports/runtime"
 	"fkteams/internal/runtime/log"
 
@@ -21,7 +21,7 @@ const allAgentsMDMaxBytes = 256 * 1024
 var defaultAgentsMDFiles = []string{"AGENTS.md", "Agents.md"}
 
 func New(ctx context.Context) (runtimeport.AgentMiddleware, error) {
-	backend, err := fkfs.NewLocalBackend(appdata.WorkspaceDir())
+	backend, err := fkfs.NewLocalBackend(project.WorkspaceDir(ctx))
 	if err != nil {
 		return nil, fmt.Errorf("create agents.md backend: %w", err)
 	}
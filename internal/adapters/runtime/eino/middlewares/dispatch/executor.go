@@ -136,7 +136,10 @@ func (m *middleware) executeOneTask(parentCtx context.Context, index int, task t
 	taskCtx, cancel := context.WithTimeout(parentCtx, m.taskTimeout)
 	defer cancel()
 
-	taskCtx = approval.WithRegistry(taskCtx, approval.NewAutoApproveRegistry())
+	// 子任务自动审批，但仍沿用父级的权限策略，deny 规则不会被绕过。
+	taskRegistry := approval.NewAutoApproveRegistry()
+	taskRegistry.SetPolicy(approval.PolicyFromContext(parentCtx))
+	taskCtx = approval.WithRegistry(taskCtx, taskRegistry)
 	taskCtx = copilot.WithAgentInitiator(taskCtx)
 
 	agent, err := m.createSubAgent(taskCtx, fmt.Sprintf("子任务-%d", index), task.Description)
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"time"
 
+	"fkteams/internal/runtime/env"
+
 	"github.com/cloudwego/eino/adk/filesystem"
 )
 
@@ -56,6 +58,7 @@ func (s *LocalShell) Execute(ctx context.Context, input *filesystem.ExecuteReque
 
 	cmd := exec.CommandContext(cmdCtx, shell, shellArgs...)
 	cmd.Dir = s.workDir
+	cmd.Env = env.ChildEnviron()
 
 	var stdoutBuf, stderrBuf bytes.Buffer
 	const maxOutput = 1024 * 1024 // 1MB
@@ -5,7 +5,7 @@ package inject
 import (
 	"context"
 	einoruntime "fkteams/internal/adapters/runtime/eino"
-	appdata "fkteams/internal/app/appdata"
+	"fkteams/internal/app/project"
 	domainmessage "fkteams/internal/domain/message"
 	runtimeport "fkteams/internal/ports/runtime"
 	"fkteams/internal/runtime/hooks"
@@ -57,7 +57,7 @@ func (m *injectChatModel) WithTools(tools []*schema.ToolInfo) (model.ToolCalling
 }
 
 func (m *injectChatModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
-	enriched := m.injectDynamicContext(input)
+	enriched := m.injectDynamicContext(ctx, input)
 	var hookErr error
 	enriched, hookErr = invokeBeforeModelRequest(ctx, enriched)
 	if hookErr != nil {
@@ -96,7 +96,7 @@ func (m *injectChatModel) generateWithProxyCallbacks(ctx context.Context,
 func (m *injectChatModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (
 	*schema.StreamReader[*schema.Message], error) {
 
-	enriched := m.injectDynamicContext(input)
+	enriched := m.injectDynamicContext(ctx, input)
 	var hookErr error
 	enriched, hookErr = invokeBeforeModelRequest(ctx, enriched)
 	if hookErr != nil {
@@ -149,8 +149,8 @@ func (m *injectChatModel) IsCallbacksEnabled() bool { return true }
 // injectDynamicContext 向消息列表末尾注入一条临时用户消息，包含动态上下文。
 // 放在末尾不破坏前缀缓存（静态 system prompt 在最前）；作为独立 UserMessage
 // 便于后续扩展（操作系统、环境变量等），新增内容只需追加到 buildDynamicContext。
-func (m *injectChatModel) injectDynamicContext(input []*schema.Message) []*schema.Message {
-	dynamicContext := buildDynamicContext()
+func (m *injectChatModel) injectDynamicContext(ctx context.Context, input []*schema.Message) []*schema.Message {
+	dynamicContext := buildDynamicContext(ctx)
 	if dynamicContext == "" {
 		return input
 	}
@@ -160,8 +160,8 @@ func (m *injectChatModel) injectDynamicContext(input []*schema.Message) []*schem
 	return enriched
 }
 
-// buildDynamicContext 构建动态上下文文本，后续可扩展更多信息。
-func buildDynamicContext() string {
+// buildDynamicContext 构建动态上下文文本，工作目录取会话所属项目，后续可扩展更多信息。
+func buildDynamicContext(ctx context.Context) string {
 	contextMsg := fmt.Sprintf(`<system-reminder>
 在回答用户问题时，你可以参考以下背景信息：
 - 当前时间：%s
@@ -172,7 +172,7 @@ func buildDynamicContext() string {
 		time.Now().Format("2006-01-02 15:04:05"),
 		runtime.GOOS,
 		runtime.GOARCH,
-		appdata.WorkspaceDir(),
+		project.WorkspaceDir(ctx),
 	)
 	return contextMsg
 }
@@ -6,6 +6,7 @@ import (
 
 	einoruntime "fkteams/internal/adapters/runtime/eino"
 	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/approval"
 	projecthooks "fkteams/internal/runtime/hooks"
 
 	"github.com/cloudwego/eino/compose"
@@ -16,6 +17,7 @@ func New() runtimeport.ToolMiddleware {
 	return einoruntime.WrapToolMiddleware("hooks", compose.ToolMiddleware{
 		Invokable: func(next compose.InvokableToolEndpoint) compose.InvokableToolEndpoint {
 			return func(ctx context.Context, input *compose.ToolInput) (*compose.ToolOutput, error) {
+				ctx, journal := approval.WithJournal(ctx)
 				if err := invokeBeforeTool(ctx, input); err != nil {
 					return nil, err
 				}
@@ -24,7 +26,7 @@ func New() runtimeport.ToolMiddleware {
 				if output != nil {
 					result = output.Result
 				}
-				if hookErr := invokeAfterTool(ctx, input, result, err); hookErr != nil && err == nil {
+				if hookErr := invokeAfterTool(ctx, input, result, err, journal); hookErr != nil && err == nil {
 					err = hookErr
 				}
 				return output, err
@@ -32,11 +34,12 @@ func New() runtimeport.ToolMiddleware {
 		},
 		Streamable: func(next compose.StreamableToolEndpoint) compose.StreamableToolEndpoint {
 			return func(ctx context.Context, input *compose.ToolInput) (*compose.StreamToolOutput, error) {
+				ctx, journal := approval.WithJournal(ctx)
 				if err := invokeBeforeTool(ctx, input); err != nil {
 					return nil, err
 				}
 				output, err := next(ctx, input)
-				if hookErr := invokeAfterTool(ctx, input, "<stream>", err); hookErr != nil && err == nil {
+				if hookErr := invokeAfterTool(ctx, input, "<stream>", err, journal); hookErr != nil && err == nil {
 					err = hookErr
 				}
 				return output, err
@@ -63,17 +66,22 @@ func invokeBeforeTool(ctx context.Context, input *compose.ToolInput) error {
 	return nil
 }
 
-func invokeAfterTool(ctx context.Context, input *compose.ToolInput, output string, toolErr error) error {
+// invokeAfterTool 执行工具后 hook。Meta 中的 approvals 是本次调用的审批检查，
+// interrupted 表示调用在等待用户输入时暂停，恢复后会重新执行。
+func invokeAfterTool(ctx context.Context, input *compose.ToolInput, output string, toolErr error, journal *approval.Journal) error {
 	if input == nil {
 		return nil
 	}
+	_, interrupted := compose.IsInterruptRerunError(toolErr)
 	return projecthooks.FromContext(ctx).InvokeAfterToolCall(ctx, projecthooks.AfterToolCallPayload{
 		ToolName: input.Name,
 		Args:     input.Arguments,
 		Result:   output,
 		Error:    toolErr,
 		Meta: map[string]any{
-			"call_id": input.CallID,
+			"call_id":     input.CallID,
+			"approvals":   journal.Decisions(),
+			"interrupted": interrupted || journal.Interrupted(),
 		},
 	})
 }
@@ -0,0 +1,48 @@
+// Package permission 在工具执行前按审批 Registry 上的权限策略放行、审批或拒绝调用。
+package permission
+
+import (
+	"context"
+
+	einoruntime "fkteams/internal/adapters/runtime/eino"
+	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/approval"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+// New 创建工具权限策略中间件。
+func New() runtimeport.ToolMiddleware {
+	return einoruntime.WrapToolMiddleware("permission", compose.ToolMiddleware{
+		Invokable: func(next compose.InvokableToolEndpoint) compose.InvokableToolEndpoint {
+			return func(ctx context.Context, input *compose.ToolInput) (*compose.ToolOutput, error) {
+				ctx, err := check(ctx, input)
+				if err != nil {
+					return nil, err
+				}
+				return next(ctx, input)
+			}
+		},
+		Streamable: func(next compose.StreamableToolEndpoint) compose.StreamableToolEndpoint {
+			return func(ctx context.Context, input *compose.ToolInput) (*compose.StreamToolOutput, error) {
+				ctx, err := check(ctx, input)
+				if err != nil {
+					return nil, err
+				}
+				return next(ctx, input)
+			}
+		},
+	})
+}
+
+func check(ctx context.Context, input *compose.ToolInput) (context.Context, error) {
+	if input == nil {
+		return ctx, nil
+	}
+	return approval.CheckToolCall(ctx, approval.ToolCall{
+		Tool:      input.Name,
+		Agent:     runtimeport.ToolAgentNameFromContext(ctx),
+		Arguments: input.Arguments,
+		CallID:    input.CallID,
+	})
+}
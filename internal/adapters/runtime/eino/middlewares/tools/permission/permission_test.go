@@ -0,0 +1,66 @@
+package permission
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	einoruntime "fkteams/internal/adapters/runtime/eino"
+	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/approval"
+
+	"github.com/cloudwego/eino/compose"
+)
+
+func policyContext(t *testing.T, data string) context.Context {
+	t.Helper()
+	policy, err := approval.ParsePolicy([]byte(data), "policy.toml")
+	if err != nil {
+		t.Fatalf("parse policy: %v", err)
+	}
+	reg := approval.NewDefaultRegistry()
+	reg.SetPolicy(policy)
+	return approval.WithRegistry(context.Background(), reg)
+}
+
+func TestPermissionDeniesMatchingAgentCall(t *testing.T) {
+	ctx := policyContext(t, "[[rules]]\nname = \"reviewer\"\neffect = \"deny\"\ntools = [\"file_write\"]\nagents = [\"reviewer\"]")
+	middleware, err := einoruntime.AdaptToolMiddlewareForRunner(New())
+	if err != nil {
+		t.Fatalf("adapt middleware: %v", err)
+	}
+	called := false
+	wrapped := middleware.Invokable(func(ctx context.Context, input *compose.ToolInput) (*compose.ToolOutput, error) {
+		called = true
+		return &compose.ToolOutput{Result: "ok"}, nil
+	})
+
+	_, err = wrapped(runtimeport.WithToolAgentName(ctx, "reviewer"), &compose.ToolInput{Name: "file_write", Arguments: `{"filepath":"a.txt"}`})
+	if !errors.Is(err, approval.ErrDenied) || called {
+		t.Fatalf("err = %v, called = %v", err, called)
+	}
+
+	if _, err = wrapped(runtimeport.WithToolAgentName(ctx, "coder"), &compose.ToolInput{Name: "file_write", Arguments: `{"filepath":"a.txt"}`}); err != nil || !called {
+		t.Fatalf("other agent err = %v, called = %v", err, called)
+	}
+}
+
+func TestPermissionAllowSkipsToolApproval(t *testing.T) {
+	ctx := policyContext(t, "[[rules]]\neffect = \"allow\"\ntools = [\"execute\"]\ncommands = [\"go test\"]")
+	middleware, err := einoruntime.AdaptToolMiddlewareForRunner(New())
+	if err != nil {
+		t.Fatalf("adapt middleware: %v", err)
+	}
+	wrapped := middleware.Invokable(func(ctx context.Context, input *compose.ToolInput) (*compose.ToolOutput, error) {
+		ctx = runtimeport.WithToolRuntimeMetadata(ctx, runtimeport.ToolRuntimeMetadata{CallID: input.CallID, Name: input.Name})
+		if err := approval.Require(ctx, approval.StoreCommand, "go test ./...", "info"); err != nil {
+			return nil, err
+		}
+		return &compose.ToolOutput{Result: "ok"}, nil
+	})
+
+	output, err := wrapped(ctx, &compose.ToolInput{Name: "execute", CallID: "call-1", Arguments: `{"command":"go test ./..."}`})
+	if err != nil || output.Result != "ok" {
+		t.Fatalf("output = %#v, err = %v", output, err)
+	}
+}
@@ -2,6 +2,7 @@ package eino
 
 import (
 	"context"
+	"encoding/json"
 	domainmessage "fkteams/internal/domain/message"
 	runtimeport "fkteams/internal/ports/runtime"
 	"fmt"
@@ -11,6 +12,10 @@ import (
 	"github.com/cloudwego/eino/schema"
 )
 
+// runnerToolInfoKey 在 ToolInfo.Extra 中保留原始工具定义，
+// 使经过运行时无关模型（如故障转移组合模型）的工具参数 schema 不丢失。
+const runnerToolInfoKey = "__runner_tool_info"
+
 func AdaptChatModelForRunner(m runtimeport.ChatModel) (model.ToolCallingChatModel, error) {
 	if m == nil {
 		return nil, fmt.Errorf("model is nil")
@@ -72,6 +77,10 @@ func (m *runtimeChatModelAdapter) Stream(ctx context.Context, input []domainmess
 func (m *runtimeChatModelAdapter) WithTools(tools []runtimeport.ToolInfo) (runtimeport.ChatModel, error) {
 	runnerTools := make([]*schema.ToolInfo, 0, len(tools))
 	for _, t := range tools {
+		if info, ok := t.Extra[runnerToolInfoKey].(*schema.ToolInfo); ok && info != nil {
+			runnerTools = append(runnerTools, info)
+			continue
+		}
 		runnerTools = append(runnerTools, &schema.ToolInfo{Name: t.Name, Desc: t.Desc, Extra: t.Extra})
 	}
 	next, err := m.inner.WithTools(runnerTools)
@@ -81,6 +90,22 @@ func (m *runtimeChatModelAdapter) WithTools(tools []runtimeport.ToolInfo) (runti
 	return WrapChatModel(next), nil
 }
 
+// toolParameters 导出工具参数的 JSON Schema，无法导出时返回 nil。
+func toolParameters(info *schema.ToolInfo) json.RawMessage {
+	if info.ParamsOneOf == nil {
+		return nil
+	}
+	s, err := info.ParamsOneOf.ToJSONSchema()
+	if err != nil || s == nil {
+		return nil
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
 type runtimeMessageStreamAdapter struct {
 	inner *schema.StreamReader[*schema.Message]
 }
@@ -139,7 +164,12 @@ func (m *nativeChatModelAdapter) WithTools(tools []*schema.ToolInfo) (model.Tool
 		if t == nil {
 			continue
 		}
-		coreTools = append(coreTools, runtimeport.ToolInfo{Name: t.Name, Desc: t.Desc, Extra: t.Extra})
+		extra := make(map[string]any, len(t.Extra)+1)
+		for key, value := range t.Extra {
+			extra[key] = value
+		}
+		extra[runnerToolInfoKey] = t
+		coreTools = append(coreTools, runtimeport.ToolInfo{Name: t.Name, Desc: t.Desc, Extra: extra, Parameters: toolParameters(t)})
 	}
 	next, err := m.inner.WithTools(coreTools)
 	if err != nil {
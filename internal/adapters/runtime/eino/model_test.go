@@ -10,6 +10,7 @@ import (
 	runtimeport "fkteams/internal/ports/runtime"
 	"fkteams/internal/testmodel"
 
+	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/schema"
 )
@@ -47,6 +48,76 @@ func TestAdaptNativeChatModelForRunner(t *testing.T) {
 	}
 }
 
+func TestNativeChatModelPreservesToolSchemaAndResponseMeta(t *testing.T) {
+	inner := &recordingRunnerModel{reply: &schema.Message{
+		Role:         schema.Assistant,
+		Content:      "ok",
+		ResponseMeta: &schema.ResponseMeta{Usage: &schema.TokenUsage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}},
+	}}
+	runnerModel, err := AdaptChatModelForRunner(&stampingModel{inner: WrapChatModel(inner)})
+	if err != nil {
+		t.Fatalf("adapt model: %v", err)
+	}
+	params := schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{"path": {Type: schema.String}})
+	bound, err := runnerModel.WithTools([]*schema.ToolInfo{{Name: "read", Desc: "read file", ParamsOneOf: params}})
+	if err != nil {
+		t.Fatalf("bind tools: %v", err)
+	}
+	if len(inner.tools) != 1 || inner.tools[0].ParamsOneOf != params {
+		t.Fatalf("tool schema lost: %#v", inner.tools)
+	}
+
+	resp, err := bound.Generate(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	if responseModel(resp) != "backup" || resp.ResponseMeta == nil || resp.ResponseMeta.Usage.TotalTokens != 5 {
+		t.Fatalf("response meta lost: %#v", resp)
+	}
+}
+
+type recordingRunnerModel struct {
+	tools []*schema.ToolInfo
+	reply *schema.Message
+}
+
+func (m *recordingRunnerModel) Generate(context.Context, []*schema.Message, ...model.Option) (*schema.Message, error) {
+	return m.reply, nil
+}
+
+func (m *recordingRunnerModel) Stream(context.Context, []*schema.Message, ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	return schema.StreamReaderFromArray([]*schema.Message{m.reply}), nil
+}
+
+func (m *recordingRunnerModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	m.tools = tools
+	return m, nil
+}
+
+// stampingModel 模拟运行时无关的组合模型：透传调用并写入应答模型。
+type stampingModel struct{ inner runtimeport.ChatModel }
+
+func (m *stampingModel) Generate(ctx context.Context, input []domainmessage.Message) (domainmessage.Message, error) {
+	msg, err := m.inner.Generate(ctx, input)
+	if msg.ResponseMeta == nil {
+		msg.ResponseMeta = &domainmessage.ResponseMeta{}
+	}
+	msg.ResponseMeta.Model = "backup"
+	return msg, err
+}
+
+func (m *stampingModel) Stream(ctx context.Context, input []domainmessage.Message) (runtimeport.MessageStream, error) {
+	return m.inner.Stream(ctx, input)
+}
+
+func (m *stampingModel) WithTools(tools []runtimeport.ToolInfo) (runtimeport.ChatModel, error) {
+	next, err := m.inner.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	return &stampingModel{inner: next}, nil
+}
+
 func TestAdaptToolUsesCoreInvoke(t *testing.T) {
 	coreTool := &invokeOnlyTool{
 		info: runtimeport.ToolInfo{Name: "invoke_tool", Desc: "invoke tool"},
@@ -5,6 +5,7 @@ import (
 	"errors"
 	domainevent "fkteams/internal/domain/event"
 	domainmessage "fkteams/internal/domain/message"
+	hookport "fkteams/internal/ports/hooks"
 	runtimeport "fkteams/internal/ports/runtime"
 	"fkteams/internal/runtime/events"
 	"fmt"
@@ -40,7 +41,7 @@ func (r *Runner) Run(ctx context.Context, input domainmessage.TurnInput, opts ru
 	if err := emitter.Emit(events.TurnStart(runID, turnID)); err != nil {
 		return nil, err
 	}
-	if !input.Message.IsEmpty() && input.Message.Role == domainmessage.RoleUser {
+	if len(opts.Resume) == 0 && !input.Message.IsEmpty() && input.Message.Role == domainmessage.RoleUser {
 		userMessage := input.Message
 		messageID := fmt.Sprintf("%s:user", turnID)
 		userEvent := events.UserMessage(runID, turnID, messageID, userMessage)
@@ -51,11 +52,26 @@ func (r *Runner) Run(ctx context.Context, input domainmessage.TurnInput, opts ru
 
 	unknownTools := newUnknownToolRecorder()
 	ctx = withUnknownToolRecorder(ctx, unknownTools)
-	iter := r.inner.Run(ctx, adaptMessagesForRunner(input.AllMessages()), adk.WithCheckPointID(opts.CheckpointID))
+	var iter *adk.AsyncIterator[*adk.AgentEvent]
+	if len(opts.Resume) > 0 {
+		resumeIter, resumeErr := r.inner.ResumeWithParams(ctx, opts.CheckpointID, &adk.ResumeParams{Targets: opts.Resume})
+		if resumeErr != nil {
+			err := fmt.Errorf("resume failed: %w", resumeErr)
+			_ = emitter.Emit(events.AgentError(runID, err))
+			return &runtimeport.RunResult{LastEvent: emitter.LastEvent()}, err
+		}
+		iter = resumeIter
+	} else {
+		iter = r.inner.Run(ctx, adaptMessagesForRunner(input.AllMessages()), adk.WithCheckPointID(opts.CheckpointID))
+	}
 	converter := newConverter(emitter, unknownTools)
 	for {
 		lastEvent, err := converter.drain(ctx, iter)
 		if err != nil {
+			var abort *hookport.AbortError
+			if errors.As(err, &abort) {
+				_ = emitter.Emit(events.TurnFailed(runID, turnID, abort.Reason))
+			}
 			_ = emitter.Emit(events.AgentError(runID, err))
 			return &runtimeport.RunResult{LastEvent: converter.lastEvent()}, err
 		}
@@ -234,6 +250,11 @@ func (c *converter) process(ctx context.Context, event *adk.AgentEvent) error {
 		if isContextCanceled(ctx, event.Err) {
 			return nil
 		}
+		// hook 要求终止回合（如超出预算）时不再继续消费事件，由 Run 发出 turn_failed。
+		var abort *hookport.AbortError
+		if errors.As(event.Err, &abort) {
+			return event.Err
+		}
 		nEvent := events.Error(event.AgentName, formatRunPath(event.RunPath), event.Err)
 		scope.apply(&nEvent, c)
 		return c.emit(nEvent)
@@ -333,6 +354,7 @@ func (c *converter) handleRegularMessage(event *adk.AgentEvent, msg *schema.Mess
 	endMeta.ToolCalls = message.ToolCalls
 	endMeta.ToolCallRefs = c.identities.refsFor(message.ToolCalls)
 	end := events.AssistantCompleted(endMeta)
+	attachMessageUsage(&end, msg)
 	scope.apply(&end, c)
 	if err := c.emit(end); err != nil {
 		return err
@@ -390,6 +412,7 @@ type streamState struct {
 	toolRefs       map[int]string
 	toolStarted    map[int]bool
 	usage          *streamUsage
+	model          string
 }
 
 type streamUsage struct {
@@ -474,6 +497,9 @@ func (c *converter) processStreamChunk(event *adk.AgentEvent, chunk *schema.Mess
 	if chunk == nil {
 		return nil
 	}
+	if model := responseModel(chunk); model != "" {
+		ss.model = model
+	}
 	if chunk.ResponseMeta != nil && chunk.ResponseMeta.Usage != nil {
 		usage := chunk.ResponseMeta.Usage
 		ss.usage = &streamUsage{
@@ -589,6 +615,7 @@ func (c *converter) emitStreamUsage(event *adk.AgentEvent, ss *streamState, scop
 		return nil
 	}
 	usageEvent := events.Usage(event.AgentName, formatRunPath(event.RunPath), ss.usage.promptTokens, ss.usage.completionTokens, ss.usage.totalTokens)
+	usageEvent.Usage.Model = ss.model
 	scope.apply(&usageEvent, c)
 	ss.usage = nil
 	return c.emit(usageEvent)
@@ -602,6 +629,7 @@ func attachStreamUsage(event *events.Event, ss *streamState) {
 	event.CompletionTokens = ss.usage.completionTokens
 	event.TotalTokens = ss.usage.totalTokens
 	event.Usage = &domainevent.UsagePayload{
+		Model:            ss.model,
 		PromptTokens:     ss.usage.promptTokens,
 		CompletionTokens: ss.usage.completionTokens,
 		TotalTokens:      ss.usage.totalTokens,
@@ -609,6 +637,23 @@ func attachStreamUsage(event *events.Event, ss *streamState) {
 	ss.usage = nil
 }
 
+// attachMessageUsage 将非流式响应携带的用量写入 assistant_completed，与流式路径保持一致。
+func attachMessageUsage(event *events.Event, msg *schema.Message) {
+	if msg.ResponseMeta == nil || msg.ResponseMeta.Usage == nil {
+		return
+	}
+	usage := msg.ResponseMeta.Usage
+	event.PromptTokens = usage.PromptTokens
+	event.CompletionTokens = usage.CompletionTokens
+	event.TotalTokens = usage.TotalTokens
+	event.Usage = &domainevent.UsagePayload{
+		Model:            responseModel(msg),
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+}
+
 func (c *converter) messageID(event *adk.AgentEvent, suffix string) string {
 	return fmt.Sprintf("msg_%s_%s_%d", event.AgentName, suffix, atomic.AddInt64(&globalMessageSeq, 1))
 }
@@ -0,0 +1,48 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"fkteams/internal/adapters/transport/channel"
+	appschedule "fkteams/internal/app/schedule"
+	domainschedule "fkteams/internal/domain/schedule"
+)
+
+// maxChannelMessageRunes 与通道回复的分片长度一致，满足 Discord 单条消息的限制。
+const maxChannelMessageRunes = 2000
+
+// ChannelSender 通过消息通道发送通知。通道服务晚于调度服务创建，管理器在之后注入。
+type ChannelSender struct {
+	mu      sync.RWMutex
+	manager *channel.Manager
+}
+
+// NewChannelSender 创建尚未绑定通道管理器的发送器。
+func NewChannelSender() *ChannelSender {
+	return &ChannelSender{}
+}
+
+// SetManager 绑定通道管理器，传入 nil 表示通道不可用。
+func (s *ChannelSender) SetManager(manager *channel.Manager) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.manager = manager
+}
+
+// Send 实现 appschedule.Sender，过长的正文按换行分片发送。
+func (s *ChannelSender) Send(ctx context.Context, delivery domainschedule.Delivery, notification appschedule.Notification) error {
+	s.mu.RLock()
+	manager := s.manager
+	s.mu.RUnlock()
+	if manager == nil {
+		return errors.New("message channels are not running")
+	}
+	for _, chunk := range channel.SplitMessage(notification.Body, maxChannelMessageRunes) {
+		if err := manager.SendText(ctx, delivery.Channel, delivery.ChatID, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package delivery
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"fkteams/internal/app/config"
+	appschedule "fkteams/internal/app/schedule"
+	domainschedule "fkteams/internal/domain/schedule"
+)
+
+const defaultSMTPPort = 587
+
+// EmailSender 通过 SMTP 发送通知邮件。
+type EmailSender struct {
+	settings func() config.SMTP
+}
+
+// NewEmailSender 创建邮件发送器，settings 在每次发送时读取。
+func NewEmailSender(settings func() config.SMTP) *EmailSender {
+	return &EmailSender{settings: settings}
+}
+
+// Send 实现 appschedule.Sender。未使用隐式 TLS 时，服务器支持则升级为 STARTTLS；
+// 配置了用户名时使用 PLAIN 认证，net/smtp 会拒绝在非本机的明文连接上发送密码。
+func (s *EmailSender) Send(ctx context.Context, delivery domainschedule.Delivery, notification appschedule.Notification) error {
+	var cfg config.SMTP
+	if s.settings != nil {
+		cfg = s.settings()
+	}
+	if cfg.Host == "" {
+		return errors.New("smtp is not configured")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid smtp from address: %w", err)
+	}
+	recipients := make([]*mail.Address, 0, len(delivery.To))
+	for _, to := range delivery.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", to, err)
+		}
+		recipients = append(recipients, addr)
+	}
+	message := buildMessage(from, recipients, notification.Subject, notification.Body)
+
+	port := cfg.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(cfg.Host, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+	if cfg.TLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if !cfg.TLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("starttls: %w", err)
+			}
+		}
+	}
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt.Address); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// buildMessage 构造纯文本 UTF-8 邮件，正文使用 base64 编码。
+func buildMessage(from *mail.Address, to []*mail.Address, subject, body string) []byte {
+	toHeader := make([]string, len(to))
+	for i, addr := range to {
+		toHeader[i] = addr.String()
+	}
+	var b strings.Builder
+	b.WriteString("From: " + from.String() + "\r\n")
+	b.WriteString("To: " + strings.Join(toHeader, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	encoded := base64.StdEncoding.EncodeToString([]byte(body))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,118 @@
+package delivery
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"fkteams/internal/app/config"
+	appschedule "fkteams/internal/app/schedule"
+	domainschedule "fkteams/internal/domain/schedule"
+)
+
+// smtpCapture 记录测试 SMTP 服务器收到的一封邮件。
+type smtpCapture struct {
+	from string
+	rcpt []string
+	data string
+}
+
+// startSMTPServer 启动只处理一个会话的最小 SMTP 服务器，不支持 STARTTLS 和认证。
+func startSMTPServer(t *testing.T) (int, <-chan smtpCapture) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	captured := make(chan smtpCapture, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+		text := textproto.NewConn(conn)
+		var got smtpCapture
+		reply := func(line string) { _ = text.PrintfLine("%s", line) }
+		reply("220 localhost ESMTP test")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch verb {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "MAIL":
+				got.from = line
+				reply("250 OK")
+			case "RCPT":
+				got.rcpt = append(got.rcpt, line)
+				reply("250 OK")
+			case "DATA":
+				reply("354 end with <CRLF>.<CRLF>")
+				data, err := text.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				got.data = string(data)
+				reply("250 OK")
+			case "QUIT":
+				reply("221 bye")
+				captured <- got
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port, captured
+}
+
+func TestEmailSenderSendsMessage(t *testing.T) {
+	port, captured := startSMTPServer(t)
+	sender := NewEmailSender(func() config.SMTP {
+		return config.SMTP{Host: "127.0.0.1", Port: port, From: "fkteams <bot@example.com>"}
+	})
+	delivery := domainschedule.Delivery{Type: domainschedule.DeliveryEmail, To: []string{"a@example.com", "B <b@example.com>"}}
+	notification := appschedule.Notification{Subject: "定时任务完成：日报", Body: "今日结果"}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := sender.Send(ctx, delivery, notification); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	got := <-captured
+	if got.from != "MAIL FROM:<bot@example.com>" {
+		t.Fatalf("MAIL = %q", got.from)
+	}
+	if len(got.rcpt) != 2 || got.rcpt[0] != "RCPT TO:<a@example.com>" || got.rcpt[1] != "RCPT TO:<b@example.com>" {
+		t.Fatalf("RCPT = %#v", got.rcpt)
+	}
+	header, body, ok := strings.Cut(got.data, "\n\n")
+	if !ok {
+		t.Fatalf("message has no body: %q", got.data)
+	}
+	if !strings.Contains(header, "Subject: =?utf-8?q?") || !strings.Contains(header, `To: <a@example.com>, "B" <b@example.com>`) {
+		t.Fatalf("header = %q", header)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(strings.TrimSpace(body), "\n", ""))
+	if err != nil || string(decoded) != "今日结果" {
+		t.Fatalf("body = %q, err = %v", decoded, err)
+	}
+}
+
+func TestEmailSenderRequiresConfiguration(t *testing.T) {
+	sender := NewEmailSender(func() config.SMTP { return config.SMTP{} })
+	err := sender.Send(context.Background(), domainschedule.Delivery{Type: domainschedule.DeliveryEmail, To: []string{"a@example.com"}}, appschedule.Notification{})
+	if err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Fatalf("Send error = %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+// Package delivery 提供定时任务结果的投递发送器：消息通道、Webhook 和邮件。
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	appschedule "fkteams/internal/app/schedule"
+	domainschedule "fkteams/internal/domain/schedule"
+)
+
+const (
+	// TimestampHeader 携带签名时使用的 Unix 时间戳（秒）。
+	TimestampHeader = "X-Fkteams-Timestamp"
+	// SignatureHeader 携带 "sha256=" 加十六进制 HMAC-SHA256 签名。
+	SignatureHeader = "X-Fkteams-Signature"
+
+	// maxResponseBytes 限制读取的 Webhook 响应大小，仅用于错误信息。
+	maxResponseBytes = 4 << 10
+)
+
+// Sign 计算 Webhook 签名，签名内容为 "<timestamp>.<body>"。
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookSender 以 POST JSON 投递通知，配置密钥时附带签名。
+type WebhookSender struct {
+	secret func() string
+	client *http.Client
+}
+
+// NewWebhookSender 创建 Webhook 发送器，secret 在每次发送时读取，返回空串表示不签名。
+func NewWebhookSender(secret func() string) *WebhookSender {
+	return &WebhookSender{secret: secret, client: http.DefaultClient}
+}
+
+// Send 实现 appschedule.Sender。
+func (s *WebhookSender) Send(ctx context.Context, delivery domainschedule.Delivery, notification appschedule.Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.secret != nil {
+		if secret := s.secret(); secret != "" {
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			req.Header.Set(TimestampHeader, timestamp)
+			req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+		}
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+	return nil
+}
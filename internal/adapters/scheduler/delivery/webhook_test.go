@@ -0,0 +1,58 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	appschedule "fkteams/internal/app/schedule"
+	domainschedule "fkteams/internal/domain/schedule"
+)
+
+func TestWebhookSenderSignsPayload(t *testing.T) {
+	var gotBody []byte
+	var gotTimestamp, gotSignature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotTimestamp = r.Header.Get(TimestampHeader)
+		gotSignature = r.Header.Get(SignatureHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := NewWebhookSender(func() string { return "s3cret" })
+	notification := appschedule.Notification{TaskID: "task-1", Task: "日报", Success: true, Result: "完成", Body: "正文"}
+	if err := sender.Send(context.Background(), domainschedule.Delivery{Type: domainschedule.DeliveryWebhook, URL: server.URL}, notification); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if gotTimestamp == "" || gotSignature != Sign("s3cret", gotTimestamp, gotBody) {
+		t.Fatalf("signature = %q, timestamp = %q", gotSignature, gotTimestamp)
+	}
+	var payload appschedule.Notification
+	if err := json.Unmarshal(gotBody, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.TaskID != "task-1" || payload.Result != "完成" || payload.Body != "正文" || !payload.Success {
+		t.Fatalf("payload = %#v", payload)
+	}
+}
+
+func TestWebhookSenderWithoutSecretAndErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(SignatureHeader) != "" {
+			t.Errorf("unexpected signature header")
+		}
+		http.Error(w, "boom", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	sender := NewWebhookSender(func() string { return "" })
+	err := sender.Send(context.Background(), domainschedule.Delivery{Type: domainschedule.DeliveryWebhook, URL: server.URL}, appschedule.Notification{})
+	if err == nil || !strings.Contains(err.Error(), "502") || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("Send error = %v", err)
+	}
+}
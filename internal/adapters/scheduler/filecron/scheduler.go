@@ -2,16 +2,19 @@ package filecron
 
 import (
 	"context"
+	cryptorand "crypto/rand"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"fkteams/internal/domain/apperror"
 	domainschedule "fkteams/internal/domain/schedule"
@@ -42,8 +45,19 @@ type Scheduler struct {
 	stopping    bool
 	cronParser  cron.Parser
 	semaphore   chan struct{}
-	cancelFuncs map[string]context.CancelFunc
-	cancelsMu   sync.Mutex
+	// executions 记录每个任务正在进行的执行，允许并发的任务可能同时有多条。
+	executions   map[string][]*execution
+	executionsMu sync.Mutex
+	// now 和 jitter 可在测试中替换，用于固定时钟和随机延迟。
+	now    func() time.Time
+	jitter func(limit time.Duration) time.Duration
+}
+
+// execution 是一次正在进行的任务执行。
+type execution struct {
+	cancel   context.CancelFunc
+	started  time.Time
+	replaced bool
 }
 
 const (
@@ -53,6 +67,9 @@ const (
 	maxTaskStoreBytes       int64 = 32 << 20
 	maxResultDirectories          = 10_000
 	taskResultTTL                 = 7 * 24 * time.Hour
+	// maxCatchUpRuns 是 run_all 策略补执行的上限，积压更多时只补执行一次。
+	maxCatchUpRuns    = 100
+	maxLastErrorBytes = 1 << 10
 )
 
 // NewScheduler 创建基于文件存储和 cron 计算的调度器。
@@ -90,12 +107,14 @@ func NewScheduler(baseDir string) (*Scheduler, error) {
 	}
 
 	scheduler := &Scheduler{
-		filePath:    filePath,
-		resultsDir:  resultsDir,
-		stopCh:      make(chan struct{}),
-		cronParser:  cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow),
-		semaphore:   make(chan struct{}, maxConcurrentTasks),
-		cancelFuncs: make(map[string]context.CancelFunc),
+		filePath:   filePath,
+		resultsDir: resultsDir,
+		stopCh:     make(chan struct{}),
+		cronParser: cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow),
+		semaphore:  make(chan struct{}, maxConcurrentTasks),
+		executions: make(map[string][]*execution),
+		now:        time.Now,
+		jitter:     randomJitter,
 	}
 	tasks, err := scheduler.loadTasks()
 	if err != nil {
@@ -105,6 +124,14 @@ func NewScheduler(baseDir string) (*Scheduler, error) {
 	return scheduler, nil
 }
 
+// randomJitter 返回 [0, limit) 内的随机延迟。
+func randomJitter(limit time.Duration) time.Duration {
+	if limit <= 0 {
+		return 0
+	}
+	return rand.N(limit)
+}
+
 // generateTaskID 生成基于 UUID v4 的任务 ID
 func generateTaskID() string {
 	return uuid.New().String()
@@ -143,12 +170,26 @@ func (s *Scheduler) ComputeNextRun(expr string, after time.Time) (time.Time, err
 	return sched.Next(after), nil
 }
 
+// nextTick 按任务时区计算 after 之后的下一次 cron 执行时间，并附加随机延迟。
+func (s *Scheduler) nextTick(task domainschedule.Task, after time.Time) (time.Time, error) {
+	loc, err := domainschedule.LoadTimeZone(task.TimeZone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	next, err := s.ComputeNextRun(task.CronExpr, after.In(loc))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return next.Add(s.jitter(task.Policy.JitterDuration())), nil
+}
+
 // AddTask 创建调度任务。
 func (s *Scheduler) AddTask(ctx context.Context, req schedulerport.AddTaskRequest) (*domainschedule.Task, error) {
 	task := domainschedule.Task{
 		ID:        generateTaskID(),
-		CreatedAt: time.Now(),
+		CreatedAt: s.now(),
 		Status:    domainschedule.StatusPending,
+		Owner:     req.Owner,
 	}
 	if err := s.applyTaskSchedule(&task, req); err != nil {
 		return nil, err
@@ -197,6 +238,8 @@ func (s *Scheduler) UpdateTask(ctx context.Context, taskID string, req scheduler
 		next := tasks.Tasks[i]
 		next.Status = domainschedule.StatusPending
 		next.LastRunAt = nil
+		next.Attempt = 0
+		next.LastError = ""
 		if err := s.applyTaskSchedule(&next, req); err != nil {
 			return nil, err
 		}
@@ -216,23 +259,71 @@ func (s *Scheduler) applyTaskSchedule(task *domainschedule.Task, req schedulerpo
 	if len([]byte(strings.TrimSpace(req.Task))) > maxTaskDescriptionBytes {
 		return apperror.New(apperror.CodeResourceLimit, "task description is too large")
 	}
-	if req.CronExpr == "" && req.ExecuteAt == "" {
-		return apperror.New(apperror.CodeInvalidArgument, "must provide cron_expr (recurring) or execute_at (one-time)")
+	if req.Trigger != nil && (req.CronExpr != "" || req.ExecuteAt != "") {
+		return apperror.New(apperror.CodeInvalidArgument, "trigger is mutually exclusive with cron_expr and execute_at")
+	}
+	if req.Trigger == nil && req.CronExpr == "" && req.ExecuteAt == "" {
+		return apperror.New(apperror.CodeInvalidArgument, "must provide cron_expr (recurring), execute_at (one-time) or trigger (event)")
 	}
 	if req.CronExpr != "" && req.ExecuteAt != "" {
 		return apperror.New(apperror.CodeInvalidArgument, "cron_expr and execute_at are mutually exclusive")
 	}
+	target := req.Target.Normalize()
+	if err := target.Validate(); err != nil {
+		return apperror.Errorf(apperror.CodeInvalidArgument, "invalid task target: %v", err)
+	}
+	deliveries, err := domainschedule.NormalizeDeliveries(req.Deliveries)
+	if err != nil {
+		return apperror.Errorf(apperror.CodeInvalidArgument, "invalid task deliveries: %v", err)
+	}
+	timeZone := strings.TrimSpace(req.TimeZone)
+	if _, err := domainschedule.LoadTimeZone(timeZone); err != nil {
+		return apperror.Wrap(apperror.CodeInvalidArgument, err.Error(), err)
+	}
+	policy := req.Policy.Normalize()
+	if err := policy.Validate(); err != nil {
+		return apperror.Errorf(apperror.CodeInvalidArgument, "invalid task policy: %v", err)
+	}
+
+	var trigger *domainschedule.Trigger
+	if req.Trigger != nil {
+		normalized := req.Trigger.Normalize()
+		if err := normalized.Validate(); err != nil {
+			return apperror.Errorf(apperror.CodeInvalidArgument, "invalid task trigger: %v", err)
+		}
+		if policy.Retry != (domainschedule.RetryPolicy{}) || policy.Misfire != "" || policy.Jitter != "" {
+			return apperror.New(apperror.CodeInvalidArgument, "retry, misfire and jitter policies apply only to time-based tasks")
+		}
+		if normalized.Type == domainschedule.TriggerWebhook && normalized.Secret == "" {
+			// 更新时未传密钥则沿用原密钥，调用方无需重新配置。
+			if task.Trigger != nil && task.Trigger.Type == domainschedule.TriggerWebhook {
+				normalized.Secret = task.Trigger.Secret
+			} else {
+				normalized.Secret = cryptorand.Text()
+			}
+		}
+		trigger = &normalized
+	}
 
 	task.Task = strings.TrimSpace(req.Task)
+	task.Target = target
+	task.Deliveries = deliveries
+	task.TimeZone = timeZone
+	task.Policy = policy
+	task.Trigger = trigger
 	task.CronExpr = ""
 	task.OneTime = false
+	now := s.now()
+	if trigger != nil {
+		task.NextRunAt = time.Time{}
+		return nil
+	}
 	if req.CronExpr != "" {
-		expr := strings.TrimSpace(req.CronExpr)
-		nextRun, err := s.ParseCronExpr(expr)
+		task.CronExpr = strings.TrimSpace(req.CronExpr)
+		nextRun, err := s.nextTick(*task, now)
 		if err != nil {
 			return apperror.Wrap(apperror.CodeInvalidArgument, "invalid cron expression", err)
 		}
-		task.CronExpr = expr
 		task.NextRunAt = nextRun
 		return nil
 	}
@@ -241,11 +332,11 @@ func (s *Scheduler) applyTaskSchedule(task *domainschedule.Task, req schedulerpo
 	if err != nil {
 		return apperror.Wrap(apperror.CodeInvalidArgument, "invalid time format, use ISO 8601", err)
 	}
-	if executeAt.Before(time.Now()) {
+	if executeAt.Before(now) {
 		return apperror.New(apperror.CodeInvalidArgument, "execute_at must be in the future")
 	}
 	task.OneTime = true
-	task.NextRunAt = executeAt
+	task.NextRunAt = executeAt.Add(s.jitter(policy.JitterDuration()))
 	return nil
 }
 
@@ -271,6 +362,31 @@ func (s *Scheduler) ListTasks(ctx context.Context, statusFilter domainschedule.S
 	return filtered, nil
 }
 
+// AssignOwner 将没有归属的任务归属到 owner，返回修改的任务数。
+func (s *Scheduler) AssignOwner(ctx context.Context, owner string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks, err := s.loadTasks()
+	if err != nil {
+		return 0, apperror.Wrap(apperror.CodeUnavailable, "scheduler storage unavailable", err)
+	}
+	assigned := 0
+	for i := range tasks.Tasks {
+		if tasks.Tasks[i].Owner == "" {
+			tasks.Tasks[i].Owner = owner
+			assigned++
+		}
+	}
+	if assigned == 0 {
+		return 0, nil
+	}
+	if err := s.saveTasks(tasks); err != nil {
+		return 0, apperror.Wrap(apperror.CodeUnavailable, "scheduler storage unavailable", err)
+	}
+	return assigned, nil
+}
+
 // CancelTask 取消待执行任务，或请求停止正在执行的任务。
 func (s *Scheduler) CancelTask(ctx context.Context, taskID string) error {
 	if !validTaskID(taskID) {
@@ -454,12 +570,14 @@ func (s *Scheduler) Stop(ctx context.Context) error {
 	}
 
 	// 取消所有正在执行的任务
-	s.cancelsMu.Lock()
-	for taskID, cancel := range s.cancelFuncs {
+	s.executionsMu.Lock()
+	for taskID, runs := range s.executions {
 		log.Printf("[scheduler] cancelling running task: %s", taskID)
-		cancel()
+		for _, run := range runs {
+			run.cancel()
+		}
 	}
-	s.cancelsMu.Unlock()
+	s.executionsMu.Unlock()
 	if runCancel != nil {
 		go func(done chan struct{}) {
 			s.wg.Wait()
@@ -504,10 +622,10 @@ func (s *Scheduler) nextCheckDuration() time.Duration {
 		return 30 * time.Second
 	}
 
-	now := time.Now()
+	now := s.now()
 	minWait := 30 * time.Second
 	for _, t := range tasks.Tasks {
-		if t.Status != domainschedule.StatusPending {
+		if t.Triggered() || !schedulable(t) {
 			continue
 		}
 		wait := t.NextRunAt.Sub(now)
@@ -545,13 +663,10 @@ func (s *Scheduler) checkAndExecute() {
 		return
 	}
 
-	now := time.Now()
+	now := s.now()
 	for i := range tasks.Tasks {
 		task := &tasks.Tasks[i]
-		if task.Status != domainschedule.StatusPending {
-			continue
-		}
-		if now.Before(task.NextRunAt) {
+		if task.Triggered() || !schedulable(*task) || now.Before(task.NextRunAt) {
 			continue
 		}
 
@@ -586,47 +701,191 @@ func (s *Scheduler) checkAndExecute() {
 			}
 		}
 
-		if currentTask == nil || currentTask.Status != domainschedule.StatusPending || time.Now().Before(currentTask.NextRunAt) {
+		if currentTask == nil || currentTask.Triggered() || !schedulable(*currentTask) || now.Before(currentTask.NextRunAt) {
 			s.mu.Unlock()
 			<-s.semaphore
 			continue
 		}
 
-		currentTask.Status = domainschedule.StatusRunning
-		currentTask.LastRunAt = &now
-		if saveErr := s.saveTasks(currentTasks); saveErr != nil {
+		scheduled := currentTask.NextRunAt
+		if !s.planRun(currentTask, now) {
+			if saveErr := s.saveTasks(currentTasks); saveErr != nil {
+				log.Printf("[scheduler] save skipped task failed: %v", saveErr)
+			}
 			s.mu.Unlock()
 			<-s.semaphore
-			log.Printf("[scheduler] save task status failed: %v", saveErr)
 			continue
 		}
-		parentCtx := s.runCtx
-		if parentCtx == nil {
-			parentCtx = context.Background()
+		// 快照保留本次计划执行时间，停机中断时据此恢复。
+		snapshot := *currentTask
+		snapshot.NextRunAt = scheduled
+		if err := s.launch(currentTasks, currentTask, snapshot, now, executor); err != nil {
+			s.mu.Unlock()
+			<-s.semaphore
+			log.Printf("[scheduler] save task status failed: %v", err)
+			continue
 		}
-		executionCtx, executionCancel := context.WithTimeout(parentCtx, 30*time.Minute)
-		s.cancelsMu.Lock()
-		s.cancelFuncs[currentTask.ID] = executionCancel
-		s.cancelsMu.Unlock()
-		s.wg.Add(1)
 		s.mu.Unlock()
+	}
+}
+
+// launch 将任务标记为执行中并在后台开始执行。调用方持有 s.mu 并已占用并发名额，
+// 返回错误时由调用方释放。
+func (s *Scheduler) launch(tasks *domainschedule.TaskList, task *domainschedule.Task, snapshot domainschedule.Task, now time.Time, executor schedulerport.TaskExecutor) error {
+	task.Status = domainschedule.StatusRunning
+	task.LastRunAt = &now
+	if err := s.saveTasks(tasks); err != nil {
+		return err
+	}
+	parentCtx := s.runCtx
+	if parentCtx == nil {
+		parentCtx = context.Background()
+	}
+	executionCtx, executionCancel := context.WithTimeout(parentCtx, task.Target.ExecutionTimeout())
+	run := &execution{cancel: executionCancel, started: now}
+	s.startExecution(task.ID, run, task.Policy.ConcurrencyOrDefault() == domainschedule.ConcurrencyReplace)
+	s.wg.Add(1)
+	go func(ctx context.Context, parent context.Context, snapshot domainschedule.Task) {
+		defer s.wg.Done()
+		defer func() { <-s.semaphore }()
+		defer run.cancel()
+		s.executeTask(ctx, parent, snapshot, run, executor)
+	}(executionCtx, parentCtx, snapshot)
+	return nil
+}
+
+// FireTrigger 以触发事件开始一次事件触发任务的执行，不等待执行结束。
+// 任务正在执行且并发策略为 forbid 时返回冲突错误，本次触发被丢弃。
+func (s *Scheduler) FireTrigger(ctx context.Context, taskID string, event domainschedule.TriggerEvent) error {
+	if !validTaskID(taskID) {
+		return apperror.New(apperror.CodeInvalidArgument, "invalid task ID")
+	}
+	select {
+	case s.semaphore <- struct{}{}:
+	default:
+		return apperror.Errorf(apperror.CodeResourceLimit, "max concurrent scheduled tasks reached (%d)", maxConcurrentTasks)
+	}
+	release := true
+	defer func() {
+		if release {
+			<-s.semaphore
+		}
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running || s.executor == nil {
+		return apperror.New(apperror.CodeUnavailable, "scheduler is not running")
+	}
+	tasks, err := s.loadTasks()
+	if err != nil {
+		return apperror.Wrap(apperror.CodeUnavailable, "scheduler storage unavailable", err)
+	}
+	for i := range tasks.Tasks {
+		task := &tasks.Tasks[i]
+		if task.ID != taskID {
+			continue
+		}
+		if !task.Triggered() {
+			return apperror.New(apperror.CodeInvalidArgument, "task is not event-triggered")
+		}
+		if !schedulable(*task) {
+			if task.Status == domainschedule.StatusRunning {
+				return apperror.New(apperror.CodeConflict, "task is already running")
+			}
+			return apperror.Errorf(apperror.CodeConflict, "task status is %s and cannot be triggered", task.Status)
+		}
+		if event.Time.IsZero() {
+			event.Time = s.now()
+		}
+		snapshot := *task
+		snapshot.Event = &event
+		if err := s.launch(tasks, task, snapshot, s.now(), s.executor); err != nil {
+			return apperror.Wrap(apperror.CodeUnavailable, "scheduler storage unavailable", err)
+		}
+		release = false
+		log.Printf("[scheduler] task triggered: %s, type=%s", taskID, event.Type)
+		return nil
+	}
+	return apperror.New(apperror.CodeNotFound, "task not found")
+}
 
-		go func(ctx context.Context, parent context.Context, cancel context.CancelFunc, tID, tContent, tCron string, tOneTime bool, tExec schedulerport.TaskExecutor) {
-			defer s.wg.Done()
-			defer func() { <-s.semaphore }()
-			defer cancel()
-			defer s.finishExecution(tID)
-			s.executeTask(ctx, parent, tID, tContent, tCron, tOneTime, tExec)
-		}(executionCtx, parentCtx, executionCancel, currentTask.ID, currentTask.Task, currentTask.CronExpr, currentTask.OneTime, executor)
+// schedulable 判断任务状态是否允许开始新的执行：待执行的任务，
+// 或并发策略为 allow、replace 且正在执行的重复任务。
+func schedulable(task domainschedule.Task) bool {
+	switch task.Status {
+	case domainschedule.StatusPending:
+		return true
+	case domainschedule.StatusRunning:
+		concurrency := task.Policy.ConcurrencyOrDefault()
+		return !task.OneTime && (concurrency == domainschedule.ConcurrencyAllow || concurrency == domainschedule.ConcurrencyReplace)
+	default:
+		return false
 	}
 }
 
-func (s *Scheduler) executeTask(ctx context.Context, parentCtx context.Context, taskID string, taskContent string, cronExpr string, oneTime bool, executor schedulerport.TaskExecutor) {
+// planRun 在任务到期时按错过执行策略决定本次是否执行，并推进重复任务的下次执行时间。
+// 超过 MisfireGrace 才开始的执行视为错过；重试中的执行不会被跳过。
+func (s *Scheduler) planRun(task *domainschedule.Task, now time.Time) bool {
+	scheduled := task.NextRunAt
+	misfire := task.Policy.MisfireOrDefault()
+	skip := now.Sub(scheduled) > domainschedule.MisfireGrace && misfire == domainschedule.MisfireSkip && task.Attempt == 0
+	if task.OneTime {
+		if skip {
+			task.Status = domainschedule.StatusFailed
+			task.LastError = fmt.Sprintf("missed scheduled run at %s", scheduled.Format(time.RFC3339))
+			log.Printf("[scheduler] one-time task missed, skipped: %s", task.ID)
+			return false
+		}
+		return true
+	}
+
+	base := now
+	if misfire == domainschedule.MisfireRunAll && s.catchUpBacklog(*task, scheduled, now) {
+		base = scheduled
+	}
+	next, err := s.nextTick(*task, base)
+	if err != nil {
+		task.Status = domainschedule.StatusFailed
+		task.LastError = err.Error()
+		log.Printf("[scheduler] cron parse failed: taskID=%s, err=%v", task.ID, err)
+		return false
+	}
+	task.NextRunAt = next
+	if skip {
+		log.Printf("[scheduler] missed run skipped: taskID=%s, scheduled=%s", task.ID, scheduled.Format(time.RFC3339))
+		return false
+	}
+	return true
+}
+
+// catchUpBacklog 判断 run_all 策略是否应逐次补执行：本次之后仍有错过的执行，
+// 且积压不超过 maxCatchUpRuns。
+func (s *Scheduler) catchUpBacklog(task domainschedule.Task, scheduled, now time.Time) bool {
+	loc, err := domainschedule.LoadTimeZone(task.TimeZone)
+	if err != nil {
+		return false
+	}
+	next := scheduled
+	for range maxCatchUpRuns {
+		tick, err := s.ComputeNextRun(task.CronExpr, next.In(loc))
+		if err != nil || tick.After(now) {
+			return next != scheduled
+		}
+		next = tick
+	}
+	log.Printf("[scheduler] catch-up backlog exceeds %d runs, running once: %s", maxCatchUpRuns, task.ID)
+	return false
+}
+
+func (s *Scheduler) executeTask(ctx context.Context, parentCtx context.Context, snapshot domainschedule.Task, run *execution, executor schedulerport.TaskExecutor) {
+	taskID := snapshot.ID
 	log.Printf("[scheduler] task started: %s", taskID)
-	_, err := executor.Execute(ctx, taskID, taskContent)
+	_, err := executor.Execute(ctx, snapshot)
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	replaced, others := s.finishExecution(taskID, run)
 
 	tasks, loadErr := s.loadTasks()
 	if loadErr != nil {
@@ -635,46 +894,47 @@ func (s *Scheduler) executeTask(ctx context.Context, parentCtx context.Context,
 	}
 
 	for i := range tasks.Tasks {
-		if tasks.Tasks[i].ID == taskID {
-			now := time.Now()
-			tasks.Tasks[i].LastRunAt = &now
-
-			if tasks.Tasks[i].Status == domainschedule.StatusCancelled {
-				log.Printf("[scheduler] task cancelled: %s", taskID)
-			} else if tasks.Tasks[i].Status != domainschedule.StatusRunning {
-				log.Printf("[scheduler] task status changed during execution: taskID=%s, status=%s", taskID, tasks.Tasks[i].Status)
-			} else if parentCtx.Err() != nil {
-				tasks.Tasks[i].Status = domainschedule.StatusPending
-				log.Printf("[scheduler] task interrupted by shutdown, returning to pending: %s", taskID)
-			} else if err != nil {
-				tasks.Tasks[i].Status = domainschedule.StatusFailed
-				log.Printf("[scheduler] task failed: %s, err=%v", taskID, err)
+		task := &tasks.Tasks[i]
+		if task.ID != taskID {
+			continue
+		}
+		now := s.now()
+		task.LastRunAt = &now
+
+		switch {
+		case task.Status == domainschedule.StatusCancelled:
+			log.Printf("[scheduler] task cancelled: %s", taskID)
+		case task.Status != domainschedule.StatusRunning:
+			log.Printf("[scheduler] task status changed during execution: taskID=%s, status=%s", taskID, task.Status)
+		case replaced:
+			log.Printf("[scheduler] task execution replaced by a newer run: %s", taskID)
+		case others > 0:
+			// 并行执行时由最后结束的执行决定状态。
+			if err != nil {
+				task.LastError = truncateError(err)
+			}
+		case parentCtx.Err() != nil:
+			task.Status = domainschedule.StatusPending
+			if snapshot.NextRunAt.Before(task.NextRunAt) {
+				task.NextRunAt = snapshot.NextRunAt
+			}
+			log.Printf("[scheduler] task interrupted by shutdown, returning to pending: %s", taskID)
+		case err != nil:
+			log.Printf("[scheduler] task failed: %s, err=%v", taskID, err)
+			s.skipOverlappedTicks(task, run, now)
+			s.scheduleRetry(task, err, now)
+		default:
+			task.Attempt = 0
+			task.LastError = ""
+			if task.OneTime {
+				task.Status = domainschedule.StatusCompleted
 			} else {
-				if oneTime {
-					tasks.Tasks[i].Status = domainschedule.StatusCompleted
-				} else {
-					nextRun, cronErr := s.ComputeNextRun(cronExpr, now)
-					if cronErr != nil {
-						tasks.Tasks[i].Status = domainschedule.StatusFailed
-						log.Printf("[scheduler] cron parse failed: taskID=%s, err=%v", taskID, cronErr)
-					} else {
-						// 避免紧贴当前时间重复触发
-						if nextRun.Sub(now) < 30*time.Second {
-							nextRun, cronErr = s.ComputeNextRun(cronExpr, nextRun)
-							if cronErr != nil {
-								tasks.Tasks[i].Status = domainschedule.StatusFailed
-								log.Printf("[scheduler] cron parse failed (skip): taskID=%s, err=%v", taskID, cronErr)
-								break
-							}
-						}
-						tasks.Tasks[i].Status = domainschedule.StatusPending
-						tasks.Tasks[i].NextRunAt = nextRun
-					}
-				}
+				task.Status = domainschedule.StatusPending
+				s.skipOverlappedTicks(task, run, now)
 			}
-			log.Printf("[scheduler] task done: %s, status=%s", taskID, tasks.Tasks[i].Status)
-			break
 		}
+		log.Printf("[scheduler] task done: %s, status=%s", taskID, task.Status)
+		break
 	}
 
 	if saveErr := s.saveTasks(tasks); saveErr != nil {
@@ -682,6 +942,60 @@ func (s *Scheduler) executeTask(ctx context.Context, parentCtx context.Context,
 	}
 }
 
+// scheduleRetry 记录失败原因，并在重试次数未用完时安排重试。
+// 重复任务的下一次常规执行先于重试到来时不再单独重试；重试用完后一次性任务
+// 标记为失败，重复任务等待下一次常规执行。
+func (s *Scheduler) scheduleRetry(task *domainschedule.Task, err error, now time.Time) {
+	task.LastError = truncateError(err)
+	task.Status = domainschedule.StatusPending
+	retry := task.Policy.Retry
+	if task.Attempt < retry.MaxAttempts {
+		attempt := task.Attempt + 1
+		retryAt := now.Add(retry.Delay(attempt))
+		if task.OneTime || retryAt.Before(task.NextRunAt) {
+			task.Attempt = attempt
+			task.NextRunAt = retryAt
+			log.Printf("[scheduler] task retry %d/%d scheduled at %s: %s", attempt, retry.MaxAttempts, retryAt.Format(time.RFC3339), task.ID)
+			return
+		}
+	}
+	task.Attempt = 0
+	if task.OneTime {
+		task.Status = domainschedule.StatusFailed
+	}
+}
+
+// skipOverlappedTicks 在 forbid 策略下跳过执行期间到期的 cron 执行；
+// 执行开始前已积压的执行（run_all 补执行）保留。
+func (s *Scheduler) skipOverlappedTicks(task *domainschedule.Task, run *execution, now time.Time) {
+	if task.OneTime || task.Policy.ConcurrencyOrDefault() != domainschedule.ConcurrencyForbid {
+		return
+	}
+	if !task.NextRunAt.After(run.started) || task.NextRunAt.After(now) {
+		return
+	}
+	next, err := s.nextTick(*task, now)
+	if err != nil {
+		log.Printf("[scheduler] cron parse failed: taskID=%s, err=%v", task.ID, err)
+		return
+	}
+	log.Printf("[scheduler] runs due during execution skipped: %s", task.ID)
+	task.NextRunAt = next
+}
+
+// truncateError 截断过长的错误信息，避免撑大任务表。
+func truncateError(err error) string {
+	message := err.Error()
+	if len(message) <= maxLastErrorBytes {
+		return message
+	}
+	cut := maxLastErrorBytes
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return message[:cut] + "..."
+}
+
 // cleanupExpiredTasks 清理超过 TTL 的已完成/失败/取消的一次性任务
 func (s *Scheduler) cleanupExpiredTasks() {
 	s.mu.Lock()
@@ -692,7 +1006,7 @@ func (s *Scheduler) cleanupExpiredTasks() {
 		return
 	}
 
-	cutoff := time.Now().Add(-taskResultTTL)
+	cutoff := s.now().Add(-taskResultTTL)
 	remaining := make([]domainschedule.Task, 0, len(tasks.Tasks))
 	removedIDs := make([]string, 0)
 
@@ -757,27 +1071,62 @@ func (s *Scheduler) cleanupOrphanTaskDirs(tasks *domainschedule.TaskList) {
 	}
 }
 
-// CancelExecution 取消正在执行的任务
+// CancelExecution 取消任务所有正在进行的执行
 func (s *Scheduler) CancelExecution(taskID string) {
-	s.cancelsMu.Lock()
-	if cancel, ok := s.cancelFuncs[taskID]; ok {
-		cancel()
+	s.executionsMu.Lock()
+	runs := s.executions[taskID]
+	for _, run := range runs {
+		run.cancel()
+	}
+	if len(runs) > 0 {
 		log.Printf("[scheduler] task execution cancelled: %s", taskID)
 	}
-	s.cancelsMu.Unlock()
+	s.executionsMu.Unlock()
 }
 
 func (s *Scheduler) isExecuting(taskID string) bool {
-	s.cancelsMu.Lock()
-	defer s.cancelsMu.Unlock()
-	_, ok := s.cancelFuncs[taskID]
-	return ok
+	s.executionsMu.Lock()
+	defer s.executionsMu.Unlock()
+	return len(s.executions[taskID]) > 0
+}
+
+// startExecution 登记一次执行；replace 为 true 时先取消该任务其余的执行。
+func (s *Scheduler) startExecution(taskID string, run *execution, replace bool) {
+	s.executionsMu.Lock()
+	defer s.executionsMu.Unlock()
+	if replace {
+		for _, active := range s.executions[taskID] {
+			if !active.replaced {
+				active.replaced = true
+				active.cancel()
+				log.Printf("[scheduler] replacing running execution: %s", taskID)
+			}
+		}
+	}
+	s.executions[taskID] = append(s.executions[taskID], run)
 }
 
-func (s *Scheduler) finishExecution(taskID string) {
-	s.cancelsMu.Lock()
-	delete(s.cancelFuncs, taskID)
-	s.cancelsMu.Unlock()
+// finishExecution 注销一次执行，返回它是否已被替换，以及仍在进行且未被替换的执行数。
+func (s *Scheduler) finishExecution(taskID string, run *execution) (bool, int) {
+	s.executionsMu.Lock()
+	defer s.executionsMu.Unlock()
+	remaining := make([]*execution, 0, len(s.executions[taskID]))
+	others := 0
+	for _, active := range s.executions[taskID] {
+		if active == run {
+			continue
+		}
+		remaining = append(remaining, active)
+		if !active.replaced {
+			others++
+		}
+	}
+	if len(remaining) == 0 {
+		delete(s.executions, taskID)
+	} else {
+		s.executions[taskID] = remaining
+	}
+	return run.replaced, others
 }
 
 // loadTaskByID 在持有锁的情况下根据 ID 查找任务
@@ -885,6 +1234,26 @@ func (s *Scheduler) validateTaskList(list *domainschedule.TaskList) error {
 		if len([]byte(task.Task)) > maxTaskDescriptionBytes {
 			return fmt.Errorf("task %q description exceeds size limit", task.ID)
 		}
+		if err := task.Target.Validate(); err != nil {
+			return fmt.Errorf("task %q has invalid target: %w", task.ID, err)
+		}
+		if _, err := domainschedule.NormalizeDeliveries(task.Deliveries); err != nil {
+			return fmt.Errorf("task %q has invalid deliveries: %w", task.ID, err)
+		}
+		if _, err := domainschedule.LoadTimeZone(task.TimeZone); err != nil {
+			return fmt.Errorf("task %q has invalid time zone: %w", task.ID, err)
+		}
+		if err := task.Policy.Validate(); err != nil {
+			return fmt.Errorf("task %q has invalid policy: %w", task.ID, err)
+		}
+		if task.Trigger != nil {
+			if err := task.Trigger.Validate(); err != nil {
+				return fmt.Errorf("task %q has invalid trigger: %w", task.ID, err)
+			}
+			if task.Trigger.Type == domainschedule.TriggerWebhook && task.Trigger.Secret == "" {
+				return fmt.Errorf("task %q webhook trigger has no secret", task.ID)
+			}
+		}
 	}
 	return nil
 }
@@ -971,6 +1340,12 @@ func (s *Scheduler) ListHistoryEntries(ctx context.Context, taskID string) ([]do
 	}
 
 	result := make([]domainschedule.HistoryEntry, 0, min(len(entries), domainschedule.MaxHistoryEntries))
+	records := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && strings.HasSuffix(entry.Name(), ".delivery.json") {
+			records[entry.Name()] = true
+		}
+	}
 	for _, entry := range entries {
 		if !entry.Type().IsRegular() || filepath.Ext(entry.Name()) != ".md" {
 			continue
@@ -991,9 +1366,30 @@ func (s *Scheduler) ListHistoryEntries(ctx context.Context, taskID string) ([]do
 	if len(result) > domainschedule.MaxHistoryEntries {
 		result = result[:domainschedule.MaxHistoryEntries]
 	}
+	for i := range result {
+		name := domainschedule.DeliveryRecordName(result[i].Filename)
+		if records[name] {
+			result[i].Deliveries = s.readDeliveryRecord(ctx, filepath.Join(historyPath, name))
+		}
+	}
 	return result, nil
 }
 
+// readDeliveryRecord 读取历史结果的投递状态；记录损坏时只记日志，不影响历史列表。
+func (s *Scheduler) readDeliveryRecord(ctx context.Context, relativePath string) []domainschedule.DeliveryResult {
+	data, err := s.readResultFile(ctx, relativePath)
+	if err != nil {
+		log.Printf("[scheduler] read delivery record %s failed: %v", relativePath, err)
+		return nil
+	}
+	var results []domainschedule.DeliveryResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		log.Printf("[scheduler] parse delivery record %s failed: %v", relativePath, err)
+		return nil
+	}
+	return results
+}
+
 // ReadHistoryFile 读取指定历史结果文件。
 func (s *Scheduler) ReadHistoryFile(ctx context.Context, taskID string, filename string) (string, error) {
 	if !validTaskID(taskID) {
@@ -87,6 +87,14 @@ func TestUpdateTask(t *testing.T) {
 	updated, err := s.UpdateTask(ctx, task.ID, schedulerport.AddTaskRequest{
 		Task:      "生成周报",
 		ExecuteAt: time.Now().Add(2 * time.Hour).Format(time.RFC3339),
+		Target: domainschedule.Target{
+			Agent:         " researcher ",
+			Model:         "cheap",
+			Tools:         []string{"search", "fetch", "search", " "},
+			MaxIterations: 10,
+			Timeout:       "5m",
+		},
+		Deliveries: []domainschedule.Delivery{{Type: " email ", To: []string{" a@example.com ", ""}, On: domainschedule.DeliverOnFailure}},
 	})
 	if err != nil {
 		t.Fatalf("UpdateTask: %v", err)
@@ -95,13 +103,24 @@ func TestUpdateTask(t *testing.T) {
 		t.Fatalf("updated task = %#v", updated)
 	}
 
-	tasks, err := s.ListTasks(ctx, "")
+	reloaded, err := NewScheduler(filepath.Dir(s.filePath))
+	if err != nil {
+		t.Fatalf("NewScheduler: %v", err)
+	}
+	tasks, err := reloaded.ListTasks(ctx, "")
 	if err != nil {
 		t.Fatalf("ListTasks: %v", err)
 	}
 	if len(tasks) != 1 || tasks[0].Task != "生成周报" {
 		t.Fatalf("tasks = %#v", tasks)
 	}
+	target := tasks[0].Target
+	if target.Agent != "researcher" || target.Model != "cheap" || strings.Join(target.Tools, ",") != "search,fetch" || target.MaxIterations != 10 || target.ExecutionTimeout() != 5*time.Minute {
+		t.Fatalf("persisted target = %#v", target)
+	}
+	if deliveries := tasks[0].Deliveries; len(deliveries) != 1 || deliveries[0].Type != domainschedule.DeliveryEmail || strings.Join(deliveries[0].To, ",") != "a@example.com" {
+		t.Fatalf("persisted deliveries = %#v", deliveries)
+	}
 }
 
 func TestAddTaskValidation(t *testing.T) {
@@ -118,7 +137,17 @@ func TestAddTaskValidation(t *testing.T) {
 		{name: "mutually exclusive", req: schedulerport.AddTaskRequest{Task: "do work", CronExpr: "* * * * *", ExecuteAt: time.Now().Add(time.Hour).Format(time.RFC3339)}, want: "mutually exclusive"},
 		{name: "invalid cron", req: schedulerport.AddTaskRequest{Task: "do work", CronExpr: "bad cron"}, want: "invalid cron expression"},
 		{name: "past time", req: schedulerport.AddTaskRequest{Task: "do work", ExecuteAt: time.Now().Add(-time.Hour).Format(time.RFC3339)}, want: "must be in the future"},
+		{name: "agent and mode", req: schedulerport.AddTaskRequest{Task: "do work", CronExpr: "* * * * *", Target: domainschedule.Target{Agent: "researcher", Mode: "team"}}, want: "agent and mode are mutually exclusive"},
+		{name: "relative work dir", req: schedulerport.AddTaskRequest{Task: "do work", CronExpr: "* * * * *", Target: domainschedule.Target{WorkDir: "repo"}}, want: "absolute path"},
+		{name: "bad timeout", req: schedulerport.AddTaskRequest{Task: "do work", CronExpr: "* * * * *", Target: domainschedule.Target{Timeout: "-1m"}}, want: "timeout must be positive"},
+		{name: "bad delivery", req: schedulerport.AddTaskRequest{Task: "do work", CronExpr: "* * * * *", Deliveries: []domainschedule.Delivery{{Type: domainschedule.DeliveryWebhook, URL: "ftp://example.com"}}}, want: "invalid task deliveries"},
+		{name: "bad delivery template", req: schedulerport.AddTaskRequest{Task: "do work", CronExpr: "* * * * *", Deliveries: []domainschedule.Delivery{{Type: domainschedule.DeliveryEmail, To: []string{"a@example.com"}, Template: "{{.Result"}}}, want: "invalid template"},
+		{name: "negative iterations", req: schedulerport.AddTaskRequest{Task: "do work", CronExpr: "* * * * *", Target: domainschedule.Target{MaxIterations: -1}}, want: "max_iterations"},
 		{name: "large task", req: schedulerport.AddTaskRequest{Task: strings.Repeat("x", maxTaskDescriptionBytes+1), ExecuteAt: time.Now().Add(time.Hour).Format(time.RFC3339)}, want: "too large"},
+		{name: "trigger and cron", req: schedulerport.AddTaskRequest{Task: "do work", CronExpr: "* * * * *", Trigger: &domainschedule.Trigger{Type: domainschedule.TriggerWebhook}}, want: "mutually exclusive"},
+		{name: "trigger escapes workspace", req: schedulerport.AddTaskRequest{Task: "do work", Trigger: &domainschedule.Trigger{Type: domainschedule.TriggerFile, Glob: "../**/*.md"}}, want: "inside the workspace"},
+		{name: "keyword trigger without keywords", req: schedulerport.AddTaskRequest{Task: "do work", Trigger: &domainschedule.Trigger{Type: domainschedule.TriggerKeyword, Keywords: []string{" "}}}, want: "at least one keyword"},
+		{name: "trigger with retry", req: schedulerport.AddTaskRequest{Task: "do work", Trigger: &domainschedule.Trigger{Type: domainschedule.TriggerWebhook}, Policy: domainschedule.Policy{Retry: domainschedule.RetryPolicy{MaxAttempts: 1}}}, want: "only to time-based tasks"},
 	}
 
 	for _, tt := range tests {
@@ -223,6 +252,9 @@ func TestResultAndHistoryReaders(t *testing.T) {
 	if err := os.WriteFile(filepath.Join(historyDir, "20260430_150405.md"), []byte("history result"), 0644); err != nil {
 		t.Fatalf("write history: %v", err)
 	}
+	if err := os.WriteFile(filepath.Join(historyDir, "20260430_150405.delivery.json"), []byte(`[{"type":"webhook","target":"https://hooks.example/a","status":"sent","time":"2026-04-30T15:04:06Z"}]`), 0644); err != nil {
+		t.Fatalf("write delivery record: %v", err)
+	}
 	if err := os.WriteFile(filepath.Join(historyDir, "ignore.txt"), []byte("ignored"), 0644); err != nil {
 		t.Fatalf("write ignored file: %v", err)
 	}
@@ -251,6 +283,9 @@ func TestResultAndHistoryReaders(t *testing.T) {
 	if len(entries) != 1 || entries[0].Filename != "20260430_150405.md" || entries[0].Time != "2026-04-30 15:04:05" {
 		t.Fatalf("entries = %#v", entries)
 	}
+	if len(entries[0].Deliveries) != 1 || entries[0].Deliveries[0].Status != domainschedule.DeliverySent {
+		t.Fatalf("entry deliveries = %#v", entries[0].Deliveries)
+	}
 
 	if _, err := s.ReadHistoryFile(context.Background(), taskID, "../20260430_150405.md"); err == nil {
 		t.Fatal("ReadHistoryFile should reject a non-base filename")
@@ -365,8 +400,8 @@ func TestTimingRecoveryExecuteAndCleanup(t *testing.T) {
 		t.Fatalf("mkdir old task dir: %v", err)
 	}
 
-	s.executeTask(context.Background(), context.Background(), "ok", "ok task", "", true, fakeTaskExecutor{})
-	s.executeTask(context.Background(), context.Background(), "fail", "fail task", "", true, fakeTaskExecutor{err: errors.New("boom")})
+	s.executeTask(context.Background(), context.Background(), domainschedule.Task{ID: "ok", Task: "ok task", OneTime: true}, &execution{}, fakeTaskExecutor{})
+	s.executeTask(context.Background(), context.Background(), domainschedule.Task{ID: "fail", Task: "fail task", OneTime: true}, &execution{}, fakeTaskExecutor{err: errors.New("boom")})
 	tasks, err := s.ListTasks(context.Background(), "")
 	if err != nil {
 		t.Fatalf("ListTasks all: %v", err)
@@ -453,7 +488,7 @@ type fakeTaskExecutor struct {
 	err error
 }
 
-func (e fakeTaskExecutor) Execute(context.Context, string, string) (string, error) {
+func (e fakeTaskExecutor) Execute(context.Context, domainschedule.Task) (string, error) {
 	return "ok", e.err
 }
 
@@ -474,7 +509,7 @@ func newBlockingTaskExecutor() *blockingTaskExecutor {
 	}
 }
 
-func (e *blockingTaskExecutor) Execute(ctx context.Context, _ string, _ string) (string, error) {
+func (e *blockingTaskExecutor) Execute(ctx context.Context, _ domainschedule.Task) (string, error) {
 	e.startOnce.Do(func() { close(e.started) })
 	<-ctx.Done()
 	e.cancelOnce.Do(func() { close(e.cancelled) })
@@ -485,3 +520,399 @@ func (e *blockingTaskExecutor) Execute(ctx context.Context, _ string, _ string)
 func (e *blockingTaskExecutor) Release() {
 	e.releaseOnce.Do(func() { close(e.release) })
 }
+
+// fakeClock 是可手动推进的测试时钟。
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// recordingTaskExecutor 记录每次执行，并按顺序返回预设错误。
+type recordingTaskExecutor struct {
+	mu    sync.Mutex
+	calls int
+	errs  []error
+}
+
+func (e *recordingTaskExecutor) Execute(context.Context, domainschedule.Task) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var err error
+	if e.calls < len(e.errs) {
+		err = e.errs[e.calls]
+	}
+	e.calls++
+	return "ok", err
+}
+
+func (e *recordingTaskExecutor) Calls() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.calls
+}
+
+func newClockedScheduler(t *testing.T, now time.Time) (*Scheduler, *fakeClock) {
+	t.Helper()
+	s := newTestScheduler(t)
+	clock := &fakeClock{now: now}
+	s.now = clock.Now
+	s.jitter = func(time.Duration) time.Duration { return 0 }
+	return s, clock
+}
+
+// runDueTasks 执行一轮到期检查，并等待本轮启动的执行结束。
+func runDueTasks(t *testing.T, s *Scheduler) {
+	t.Helper()
+	s.mu.Lock()
+	s.running = true
+	s.runCtx = context.Background()
+	s.mu.Unlock()
+	s.checkAndExecute()
+	s.wg.Wait()
+}
+
+func singleTask(t *testing.T, s *Scheduler) domainschedule.Task {
+	t.Helper()
+	tasks, err := s.ListTasks(context.Background(), "")
+	if err != nil || len(tasks) != 1 {
+		t.Fatalf("ListTasks() = %#v, %v", tasks, err)
+	}
+	return tasks[0]
+}
+
+func TestRetryPolicyBacksOffUntilExhausted(t *testing.T) {
+	start := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+	s, clock := newClockedScheduler(t, start)
+	executor := &recordingTaskExecutor{errs: []error{errors.New("boom"), errors.New("boom again"), errors.New("final")}}
+	s.SetExecutor(executor)
+	if err := s.saveTasks(&domainschedule.TaskList{Tasks: []domainschedule.Task{{
+		ID: "retry", Task: "retry task", Status: domainschedule.StatusPending, OneTime: true,
+		NextRunAt: start, CreatedAt: start,
+		Policy: domainschedule.Policy{Retry: domainschedule.RetryPolicy{MaxAttempts: 2, Backoff: "1m"}},
+	}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	runDueTasks(t, s)
+	task := singleTask(t, s)
+	if task.Status != domainschedule.StatusPending || task.Attempt != 1 || task.LastError != "boom" || !task.NextRunAt.Equal(start.Add(time.Minute)) {
+		t.Fatalf("after first failure = %#v", task)
+	}
+
+	runDueTasks(t, s)
+	if executor.Calls() != 1 {
+		t.Fatalf("retry ran before its backoff elapsed, calls = %d", executor.Calls())
+	}
+
+	clock.Advance(time.Minute)
+	runDueTasks(t, s)
+	task = singleTask(t, s)
+	if task.Attempt != 2 || !task.NextRunAt.Equal(start.Add(3*time.Minute)) {
+		t.Fatalf("second retry should double the backoff: %#v", task)
+	}
+
+	clock.Advance(2 * time.Minute)
+	runDueTasks(t, s)
+	task = singleTask(t, s)
+	if task.Status != domainschedule.StatusFailed || task.Attempt != 0 || task.LastError != "final" || executor.Calls() != 3 {
+		t.Fatalf("exhausted retries = %#v, calls = %d", task, executor.Calls())
+	}
+}
+
+func TestRecurringTaskFailureWaitsForNextTick(t *testing.T) {
+	start := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+	s, _ := newClockedScheduler(t, start)
+	s.SetExecutor(&recordingTaskExecutor{errs: []error{errors.New("boom")}})
+	if err := s.saveTasks(&domainschedule.TaskList{Tasks: []domainschedule.Task{{
+		ID: "hourly", Task: "hourly task", Status: domainschedule.StatusPending, CronExpr: "0 * * * *", TimeZone: "UTC",
+		NextRunAt: start, CreatedAt: start,
+	}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	runDueTasks(t, s)
+	task := singleTask(t, s)
+	if task.Status != domainschedule.StatusPending || task.LastError != "boom" || !task.NextRunAt.Equal(start.Add(time.Hour)) {
+		t.Fatalf("recurring task after failure = %#v", task)
+	}
+}
+
+func TestMisfirePolicies(t *testing.T) {
+	now := time.Date(2026, 6, 9, 10, 30, 0, 0, time.UTC)
+	missed := now.Add(-150 * time.Minute) // 08:00，之后还错过了 09:00 和 10:00
+	tests := []struct {
+		policy   domainschedule.MisfirePolicy
+		wantRuns int
+		wantNext time.Time
+	}{
+		{policy: domainschedule.MisfireSkip, wantRuns: 0, wantNext: now.Add(30 * time.Minute)},
+		{policy: domainschedule.MisfireRunOnce, wantRuns: 1, wantNext: now.Add(30 * time.Minute)},
+		{policy: domainschedule.MisfireRunAll, wantRuns: 3, wantNext: now.Add(30 * time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			s, _ := newClockedScheduler(t, now)
+			executor := &recordingTaskExecutor{}
+			s.SetExecutor(executor)
+			if err := s.saveTasks(&domainschedule.TaskList{Tasks: []domainschedule.Task{{
+				ID: "hourly", Task: "hourly task", Status: domainschedule.StatusPending, CronExpr: "0 * * * *", TimeZone: "UTC",
+				NextRunAt: missed, CreatedAt: missed, Policy: domainschedule.Policy{Misfire: tt.policy},
+			}}}); err != nil {
+				t.Fatal(err)
+			}
+			for range 5 {
+				runDueTasks(t, s)
+			}
+			task := singleTask(t, s)
+			if executor.Calls() != tt.wantRuns || task.Status != domainschedule.StatusPending || !task.NextRunAt.Equal(tt.wantNext) {
+				t.Fatalf("runs = %d, task = %#v", executor.Calls(), task)
+			}
+		})
+	}
+}
+
+func TestMisfireSkipFailsMissedOneTimeTask(t *testing.T) {
+	now := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+	s, _ := newClockedScheduler(t, now)
+	executor := &recordingTaskExecutor{}
+	s.SetExecutor(executor)
+	if err := s.saveTasks(&domainschedule.TaskList{Tasks: []domainschedule.Task{{
+		ID: "once", Task: "once task", Status: domainschedule.StatusPending, OneTime: true,
+		NextRunAt: now.Add(-time.Hour), CreatedAt: now.Add(-2 * time.Hour),
+		Policy: domainschedule.Policy{Misfire: domainschedule.MisfireSkip},
+	}}}); err != nil {
+		t.Fatal(err)
+	}
+	runDueTasks(t, s)
+	task := singleTask(t, s)
+	if executor.Calls() != 0 || task.Status != domainschedule.StatusFailed || !strings.Contains(task.LastError, "missed") {
+		t.Fatalf("runs = %d, task = %#v", executor.Calls(), task)
+	}
+}
+
+func TestConcurrencyPolicies(t *testing.T) {
+	start := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		policy        domainschedule.ConcurrencyPolicy
+		wantStarts    int
+		wantCancelled bool
+	}{
+		{policy: domainschedule.ConcurrencyForbid, wantStarts: 1},
+		{policy: domainschedule.ConcurrencyAllow, wantStarts: 2},
+		{policy: domainschedule.ConcurrencyReplace, wantStarts: 2, wantCancelled: true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			s, clock := newClockedScheduler(t, start)
+			executor := newCountingBlockingExecutor()
+			s.SetExecutor(executor)
+			if err := s.saveTasks(&domainschedule.TaskList{Tasks: []domainschedule.Task{{
+				ID: "minutely", Task: "minutely task", Status: domainschedule.StatusPending, CronExpr: "* * * * *", TimeZone: "UTC",
+				NextRunAt: start, CreatedAt: start, Policy: domainschedule.Policy{Concurrency: tt.policy},
+			}}}); err != nil {
+				t.Fatal(err)
+			}
+			s.mu.Lock()
+			s.running = true
+			s.runCtx = context.Background()
+			s.mu.Unlock()
+
+			s.checkAndExecute()
+			executor.waitStarts(t, 1)
+			clock.Advance(time.Minute)
+			s.checkAndExecute()
+			if tt.wantStarts > 1 {
+				executor.waitStarts(t, tt.wantStarts)
+			}
+			if got := executor.Starts(); got != tt.wantStarts {
+				t.Fatalf("starts = %d, want %d", got, tt.wantStarts)
+			}
+			if tt.wantCancelled {
+				select {
+				case <-executor.firstCancelled:
+				case <-time.After(2 * time.Second):
+					t.Fatal("replaced execution was not cancelled")
+				}
+			}
+
+			clock.Advance(30 * time.Second)
+			executor.Release()
+			s.wg.Wait()
+			task := singleTask(t, s)
+			if task.Status != domainschedule.StatusPending || s.isExecuting(task.ID) {
+				t.Fatalf("task after executions = %#v", task)
+			}
+			if tt.policy == domainschedule.ConcurrencyForbid && !task.NextRunAt.Equal(start.Add(2*time.Minute)) {
+				t.Fatalf("forbid should skip the tick due during execution, next = %s", task.NextRunAt)
+			}
+		})
+	}
+}
+
+func TestTimeZoneAndJitter(t *testing.T) {
+	now := time.Date(2026, 6, 9, 0, 30, 0, 0, time.UTC)
+	s, _ := newClockedScheduler(t, now)
+	s.jitter = func(limit time.Duration) time.Duration { return limit / 2 }
+
+	task, err := s.AddTask(context.Background(), schedulerport.AddTaskRequest{
+		Task:     "morning report",
+		CronExpr: "0 9 * * *",
+		TimeZone: "Asia/Shanghai",
+		Policy:   domainschedule.Policy{Jitter: "10m"},
+	})
+	if err != nil {
+		t.Fatalf("AddTask(): %v", err)
+	}
+	// 上海 09:00 即 UTC 01:00，再加 5 分钟随机延迟。
+	if want := time.Date(2026, 6, 9, 1, 5, 0, 0, time.UTC); !task.NextRunAt.Equal(want) {
+		t.Fatalf("NextRunAt = %s, want %s", task.NextRunAt.UTC(), want)
+	}
+
+	for _, req := range []schedulerport.AddTaskRequest{
+		{Task: "bad zone", CronExpr: "0 9 * * *", TimeZone: "Mars/Base"},
+		{Task: "bad retry", CronExpr: "0 9 * * *", Policy: domainschedule.Policy{Retry: domainschedule.RetryPolicy{MaxAttempts: 99}}},
+		{Task: "bad misfire", CronExpr: "0 9 * * *", Policy: domainschedule.Policy{Misfire: "later"}},
+		{Task: "bad jitter", CronExpr: "0 9 * * *", Policy: domainschedule.Policy{Jitter: "3h"}},
+	} {
+		if _, err := s.AddTask(context.Background(), req); err == nil {
+			t.Fatalf("AddTask(%q) should fail", req.Task)
+		}
+	}
+}
+
+// countingBlockingExecutor 阻塞每次执行直到 Release，并记录首个执行是否被取消。
+type countingBlockingExecutor struct {
+	mu             sync.Mutex
+	starts         int
+	started        chan struct{}
+	release        chan struct{}
+	releaseOnce    sync.Once
+	firstCancelled chan struct{}
+}
+
+func newCountingBlockingExecutor() *countingBlockingExecutor {
+	return &countingBlockingExecutor{
+		started:        make(chan struct{}, 10),
+		release:        make(chan struct{}),
+		firstCancelled: make(chan struct{}),
+	}
+}
+
+func (e *countingBlockingExecutor) Execute(ctx context.Context, _ domainschedule.Task) (string, error) {
+	e.mu.Lock()
+	e.starts++
+	first := e.starts == 1
+	e.mu.Unlock()
+	e.started <- struct{}{}
+	select {
+	case <-ctx.Done():
+		if first {
+			close(e.firstCancelled)
+		}
+		<-e.release
+		return "", ctx.Err()
+	case <-e.release:
+		return "ok", nil
+	}
+}
+
+func (e *countingBlockingExecutor) Starts() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.starts
+}
+
+func (e *countingBlockingExecutor) waitStarts(t *testing.T, n int) {
+	t.Helper()
+	for e.Starts() < n {
+		select {
+		case <-e.started:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("executions started = %d, want %d", e.Starts(), n)
+		}
+	}
+}
+
+func (e *countingBlockingExecutor) Release() {
+	e.releaseOnce.Do(func() { close(e.release) })
+}
+
+// snapshotTaskExecutor 记录每次执行收到的任务快照。
+type snapshotTaskExecutor struct {
+	mu        sync.Mutex
+	snapshots []domainschedule.Task
+}
+
+func (e *snapshotTaskExecutor) Execute(_ context.Context, task domainschedule.Task) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.snapshots = append(e.snapshots, task)
+	return "ok", nil
+}
+
+func TestFireTriggerRunsTaskWithEvent(t *testing.T) {
+	start := time.Date(2026, 6, 9, 10, 0, 0, 0, time.UTC)
+	s, clock := newClockedScheduler(t, start)
+	executor := &snapshotTaskExecutor{}
+	s.SetExecutor(executor)
+	ctx := context.Background()
+
+	task, err := s.AddTask(ctx, schedulerport.AddTaskRequest{Task: "处理推送", Trigger: &domainschedule.Trigger{Type: domainschedule.TriggerWebhook}})
+	if err != nil {
+		t.Fatalf("AddTask: %v", err)
+	}
+	if !task.NextRunAt.IsZero() || len(task.Trigger.Secret) < domainschedule.MinTriggerSecretBytes {
+		t.Fatalf("triggered task = %#v", task)
+	}
+	secret := task.Trigger.Secret
+	updated, err := s.UpdateTask(ctx, task.ID, schedulerport.AddTaskRequest{Task: "处理推送事件", Trigger: &domainschedule.Trigger{Type: domainschedule.TriggerWebhook}})
+	if err != nil || updated.Trigger.Secret != secret {
+		t.Fatalf("UpdateTask should keep the secret: %#v, %v", updated, err)
+	}
+
+	event := domainschedule.NewTriggerEvent(domainschedule.TriggerWebhook, "test", `{"ref":"main"}`, time.Time{})
+	if err := s.FireTrigger(ctx, task.ID, event); err == nil {
+		t.Fatal("FireTrigger should fail while the scheduler is stopped")
+	}
+	clock.Advance(time.Hour)
+	runDueTasks(t, s)
+	if len(executor.snapshots) != 0 {
+		t.Fatal("triggered task should not run on the timer")
+	}
+
+	if err := s.FireTrigger(ctx, task.ID, event); err != nil {
+		t.Fatalf("FireTrigger: %v", err)
+	}
+	s.wg.Wait()
+	if len(executor.snapshots) != 1 {
+		t.Fatalf("executions = %d", len(executor.snapshots))
+	}
+	snapshot := executor.snapshots[0]
+	if snapshot.Event == nil || !strings.Contains(snapshot.Prompt(), `{"ref":"main"}`) || !strings.HasPrefix(snapshot.Prompt(), "处理推送事件") {
+		t.Fatalf("snapshot prompt = %q", snapshot.Prompt())
+	}
+	stored := singleTask(t, s)
+	if stored.Status != domainschedule.StatusPending || stored.Event != nil || stored.LastRunAt == nil {
+		t.Fatalf("task after trigger = %#v", stored)
+	}
+
+	cron, err := s.AddTask(ctx, schedulerport.AddTaskRequest{Task: "cron", CronExpr: "* * * * *"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.FireTrigger(ctx, cron.ID, event); err == nil || !strings.Contains(err.Error(), "not event-triggered") {
+		t.Fatalf("FireTrigger on cron task error = %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+// Package account 提供单个 JSON 文件的账号目录存储。
+package account
+
+import (
+	"context"
+
+	"fkteams/internal/adapters/storage/file/jsonfile"
+	domainaccount "fkteams/internal/domain/account"
+	storageport "fkteams/internal/ports/storage"
+)
+
+var _ storageport.AccountStore = (*Store)(nil)
+
+// Store 将账号目录保存在单个 JSON 文件中。
+type Store struct {
+	file *jsonfile.File[domainaccount.Directory]
+}
+
+// NewStore 创建以 filePath 为存储文件的账号存储。
+func NewStore(filePath string) *Store {
+	return &Store{file: jsonfile.New[domainaccount.Directory](filePath, "account")}
+}
+
+func (s *Store) LoadAccounts(_ context.Context) (domainaccount.Directory, error) {
+	return s.file.Load()
+}
+
+func (s *Store) SaveAccounts(_ context.Context, directory domainaccount.Directory) error {
+	return s.file.Save(directory)
+}
@@ -0,0 +1,30 @@
+// Package apikey 提供单个 JSON 文件的 API 密钥存储。
+package apikey
+
+import (
+	"context"
+
+	"fkteams/internal/adapters/storage/file/jsonfile"
+	domainapikey "fkteams/internal/domain/apikey"
+	storageport "fkteams/internal/ports/storage"
+)
+
+var _ storageport.APIKeyStore = (*Store)(nil)
+
+// Store 将密钥保存在单个 JSON 文件中。
+type Store struct {
+	file *jsonfile.File[domainapikey.Store]
+}
+
+// NewStore 创建以 filePath 为存储文件的密钥存储。
+func NewStore(filePath string) *Store {
+	return &Store{file: jsonfile.New[domainapikey.Store](filePath, "api key")}
+}
+
+func (s *Store) LoadAPIKeys(_ context.Context) (domainapikey.Store, error) {
+	return s.file.Load()
+}
+
+func (s *Store) SaveAPIKeys(_ context.Context, store domainapikey.Store) error {
+	return s.file.Save(store)
+}
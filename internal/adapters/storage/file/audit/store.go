@@ -0,0 +1,151 @@
+// Package audit 提供单文件 JSONL 的审计哈希链存储。
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	domainaudit "fkteams/internal/domain/audit"
+	storageport "fkteams/internal/ports/storage"
+)
+
+const maxRecordBytes = 64 << 10
+
+var _ storageport.AuditStore = (*Store)(nil)
+
+// Store 将审计记录逐行追加写入 path。CLI 和 Web 服务等多个进程共用同一个文件，
+// 读取末尾记录到写入新记录之间持有文件锁。最后一条记录缓存在内存中，
+// 文件大小与缓存不一致（被其他进程追加）时重新读取文件末尾。
+type Store struct {
+	path string
+	key  storageport.AuditKeyFunc
+
+	mu   sync.Mutex
+	size int64
+	last *domainaudit.Record
+}
+
+// NewStore 创建以 path 为文件、以 key 返回的密钥计算哈希链的审计存储。
+func NewStore(path string, key storageport.AuditKeyFunc) *Store {
+	return &Store{path: path, key: key, size: -1}
+}
+
+func (s *Store) AppendAudit(_ context.Context, record domainaudit.Record) (domainaudit.Record, error) {
+	if s == nil || s.path == "" || s.key == nil {
+		return record, fmt.Errorf("audit storage is not configured")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return record, fmt.Errorf("create audit directory: %w", err)
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0600)
+	if err != nil {
+		return record, fmt.Errorf("open audit file: %w", err)
+	}
+	defer file.Close()
+	if err := lockFile(file); err != nil {
+		return record, fmt.Errorf("lock audit file: %w", err)
+	}
+	defer unlockFile(file)
+	// 首次生成密钥也在文件锁内完成，避免多个进程各自生成不同的密钥
+	key, err := s.key()
+	if err != nil {
+		return record, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return record, fmt.Errorf("stat audit file: %w", err)
+	}
+	if info.Size() != s.size {
+		last, err := readLast(file, info.Size())
+		if err != nil {
+			return record, err
+		}
+		s.last, s.size = last, info.Size()
+	}
+
+	record = record.Chain(s.last, key)
+	data, err := json.Marshal(record)
+	if err != nil {
+		return record, fmt.Errorf("encode audit record: %w", err)
+	}
+	if len(data) >= maxRecordBytes {
+		return record, fmt.Errorf("audit record exceeds %d bytes", maxRecordBytes)
+	}
+	n, err := file.Write(append(data, '\n'))
+	if err != nil {
+		s.size = -1
+		return record, fmt.Errorf("write audit record: %w", err)
+	}
+	s.last, s.size = &record, s.size+int64(n)
+	return record, nil
+}
+
+func (s *Store) ScanAudit(ctx context.Context, fn func(line int, record domainaudit.Record, err error) error) error {
+	if s == nil || s.path == "" {
+		return fmt.Errorf("audit storage is not configured")
+	}
+	file, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open audit file: %w", err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 4096), maxRecordBytes)
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var record domainaudit.Record
+		decodeErr := json.Unmarshal(scanner.Bytes(), &record)
+		if decodeErr != nil {
+			decodeErr = fmt.Errorf("decode audit record: %w", decodeErr)
+		}
+		if err := fn(line, record, decodeErr); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read audit file: %w", err)
+	}
+	return nil
+}
+
+// readLast 读取文件最后一条记录，文件为空时返回 nil。
+// 末尾不完整或无法解析时返回错误，避免新记录链接到错误的位置。
+func readLast(file *os.File, size int64) (*domainaudit.Record, error) {
+	if size == 0 {
+		return nil, nil
+	}
+	offset := max(size-maxRecordBytes-1, 0)
+	data := make([]byte, size-offset)
+	if _, err := file.ReadAt(data, offset); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("read audit file: %w", err)
+	}
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		return nil, fmt.Errorf("audit log %s ends with an incomplete record, run fkteams audit verify", file.Name())
+	}
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	} else if offset > 0 {
+		return nil, fmt.Errorf("audit log %s has an oversized last record", file.Name())
+	}
+	var record domainaudit.Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("audit log %s has a malformed last record, run fkteams audit verify: %w", file.Name(), err)
+	}
+	return &record, nil
+}
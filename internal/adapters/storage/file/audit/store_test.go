@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	domainaudit "fkteams/internal/domain/audit"
+)
+
+var testKey = []byte("test-audit-key")
+
+func staticKey() ([]byte, error) { return testKey, nil }
+
+// verifyFile 以测试密钥校验审计文件的哈希链。
+func verifyFile(t *testing.T, store *Store) domainaudit.Verification {
+	t.Helper()
+	verifier := domainaudit.NewVerifier(testKey)
+	err := store.ScanAudit(context.Background(), func(line int, record domainaudit.Record, err error) error {
+		if err != nil {
+			verifier.Fail(line, err.Error())
+			return nil
+		}
+		verifier.Add(line, record)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanAudit: %v", err)
+	}
+	return verifier.Result()
+}
+
+func TestStoreChainsRecordsAcrossInstances(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	store := NewStore(path, staticKey)
+	first, err := store.AppendAudit(ctx, domainaudit.Record{At: time.Now(), Tool: "read_file", Status: domainaudit.StatusOK})
+	if err != nil {
+		t.Fatalf("AppendAudit: %v", err)
+	}
+	second, err := NewStore(path, staticKey).AppendAudit(ctx, domainaudit.Record{At: time.Now(), Tool: "execute", Status: domainaudit.StatusError})
+	if err != nil {
+		t.Fatalf("AppendAudit second store: %v", err)
+	}
+	if first.Seq != 1 || first.PrevHash != "" || second.Seq != 2 || second.PrevHash != first.Hash {
+		t.Fatalf("chain = %#v, %#v", first, second)
+	}
+	// 另一个实例写入后，缓存的末尾记录需要重新读取
+	third, err := store.AppendAudit(ctx, domainaudit.Record{At: time.Now(), Tool: "write_file", Status: domainaudit.StatusOK})
+	if err != nil || third.Seq != 3 || third.PrevHash != second.Hash {
+		t.Fatalf("third = %#v, err = %v", third, err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("audit file mode = %v, err = %v", info.Mode(), err)
+	}
+
+	if result := verifyFile(t, store); !result.Valid || result.Records != 3 || result.Head != third.Hash {
+		t.Fatalf("verification = %#v", result)
+	}
+}
+
+// 每个 Store 各自打开文件，相当于不同进程同时追加：文件锁保证记录不会链接到同一条上一记录。
+func TestStoreConcurrentInstancesKeepChainIntact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	const writers, perWriter = 4, 25
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter)
+	for range writers {
+		store := NewStore(path, staticKey)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range perWriter {
+				if _, err := store.AppendAudit(context.Background(), domainaudit.Record{Tool: "execute", Status: domainaudit.StatusOK}); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("AppendAudit: %v", err)
+	}
+	if result := verifyFile(t, NewStore(path, staticKey)); !result.Valid || result.Records != writers*perWriter {
+		t.Fatalf("verification = %#v", result)
+	}
+}
+
+func TestStoreDoesNotAppendWithoutKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	locked := func() ([]byte, error) { return nil, errors.New("vault is locked") }
+	if _, err := NewStore(path, locked).AppendAudit(context.Background(), domainaudit.Record{Tool: "execute"}); err == nil {
+		t.Fatal("append without a key should fail")
+	}
+	if data, err := os.ReadFile(path); err != nil || len(data) != 0 {
+		t.Fatalf("audit file = %q, err = %v", data, err)
+	}
+}
+
+func TestStoreRefusesToAppendAfterTruncatedRecord(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := os.WriteFile(path, []byte(`{"seq":1,"tool":"exec`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewStore(path, staticKey).AppendAudit(ctx, domainaudit.Record{Tool: "read_file"}); err == nil {
+		t.Fatal("append after an incomplete record should fail")
+	}
+}
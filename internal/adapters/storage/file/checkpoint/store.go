@@ -0,0 +1,115 @@
+// Package checkpoint 提供基于会话目录的文件 checkpoint 存储，使中断的回合在进程重启后仍可恢复。
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	domainsession "fkteams/internal/domain/session"
+	storageport "fkteams/internal/ports/storage"
+	"fkteams/internal/runtime/atomicfile"
+)
+
+const (
+	// DirName 是会话目录下保存 checkpoint 的子目录名。
+	DirName          = "checkpoints"
+	defaultNamespace = "default"
+	fileExt          = ".ckpt"
+)
+
+var (
+	_ storageport.CheckpointStore   = (*Store)(nil)
+	_ storageport.CheckpointCleaner = (*Store)(nil)
+)
+
+// Store 将 checkpoint 写入 <root>/<checkpointID>/checkpoints/<namespace>.ckpt。
+// key 采用 runtime/checkpoint.NamespaceStore 的 "namespace:checkpointID" 格式，
+// checkpoint ID 即会话 ID，删除会话目录时 checkpoint 随之清理。
+type Store struct {
+	root string
+	mu   sync.RWMutex
+}
+
+// NewStore 创建以会话根目录为基准的文件 checkpoint 存储。
+func NewStore(root string) *Store {
+	return &Store{root: root}
+}
+
+func (s *Store) Set(_ context.Context, key string, value []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := atomicfile.WriteFile(path, value, 0600); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) Get(_ context.Context, key string) ([]byte, bool, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, false, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("read checkpoint: %w", err)
+	}
+	return data, true, nil
+}
+
+// DeleteCheckpoints 删除 checkpoint ID 下所有命名空间的 checkpoint，
+// 会话目录因此变空时一并删除。
+func (s *Store) DeleteCheckpoints(_ context.Context, checkpointID string) error {
+	if s == nil || s.root == "" {
+		return fmt.Errorf("checkpoint storage is not configured")
+	}
+	if !domainsession.ValidID(checkpointID) {
+		return fmt.Errorf("invalid checkpoint ID: %q", checkpointID)
+	}
+	dir := filepath.Join(s.root, checkpointID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.RemoveAll(filepath.Join(dir, DirName)); err != nil {
+		return fmt.Errorf("delete checkpoints: %w", err)
+	}
+	// 目录不为空（保存了会话记录）或不存在时 Remove 失败，均无需处理
+	_ = os.Remove(dir)
+	return nil
+}
+
+func (s *Store) path(key string) (string, error) {
+	if s == nil || s.root == "" {
+		return "", fmt.Errorf("checkpoint storage is not configured")
+	}
+	namespace, checkpointID := splitKey(key)
+	if !domainsession.ValidID(checkpointID) {
+		return "", fmt.Errorf("invalid checkpoint ID: %q", checkpointID)
+	}
+	return filepath.Join(s.root, checkpointID, DirName, url.PathEscape(namespace)+fileExt), nil
+}
+
+func splitKey(key string) (namespace, checkpointID string) {
+	index := strings.LastIndexByte(key, ':')
+	if index < 0 {
+		return defaultNamespace, key
+	}
+	namespace = key[:index]
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	return namespace, key[index+1:]
+}
@@ -0,0 +1,86 @@
+package checkpoint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	runtimecheckpoint "fkteams/internal/runtime/checkpoint"
+)
+
+func TestStorePersistsAcrossInstances(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+
+	first := runtimecheckpoint.NewNamespaceStore("team", NewStore(root))
+	if err := first.Set(ctx, "session-1", []byte("state")); err != nil {
+		t.Fatalf("set checkpoint: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "session-1", DirName, "team"+fileExt)); err != nil {
+		t.Fatalf("checkpoint file should live under session dir: %v", err)
+	}
+
+	second := runtimecheckpoint.NewNamespaceStore("team", NewStore(root))
+	got, ok, err := second.Get(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("get checkpoint: %v", err)
+	}
+	if !ok || string(got) != "state" {
+		t.Fatalf("checkpoint = %q, %v; want state, true", got, ok)
+	}
+}
+
+func TestStoreSeparatesNamespaces(t *testing.T) {
+	ctx := context.Background()
+	inner := NewStore(t.TempDir())
+	team := runtimecheckpoint.NewNamespaceStore("team", inner)
+	agent := runtimecheckpoint.NewNamespaceStore("agent_coder", inner)
+
+	if err := team.Set(ctx, "session-1", []byte("team")); err != nil {
+		t.Fatalf("set team checkpoint: %v", err)
+	}
+	if _, ok, err := agent.Get(ctx, "session-1"); err != nil || ok {
+		t.Fatalf("agent checkpoint = %v, %v; want missing", ok, err)
+	}
+}
+
+func TestStoreRejectsUnsafeCheckpointID(t *testing.T) {
+	store := NewStore(t.TempDir())
+	if err := store.Set(context.Background(), "team:../escape", []byte("x")); err == nil {
+		t.Fatal("expected invalid checkpoint ID error")
+	}
+}
+
+func TestStoreDeleteCheckpointsRemovesEmptySessionDir(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := NewStore(root)
+	for _, namespace := range []string{"team", "agent_coder"} {
+		if err := runtimecheckpoint.NewNamespaceStore(namespace, store).Set(ctx, "api-1", []byte("state")); err != nil {
+			t.Fatalf("set checkpoint: %v", err)
+		}
+	}
+	if err := store.Set(ctx, "team:session-1", []byte("state")); err != nil {
+		t.Fatalf("set checkpoint: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "session-1", "history.json"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.DeleteCheckpoints(ctx, "api-1"); err != nil {
+		t.Fatalf("DeleteCheckpoints: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "api-1")); !os.IsNotExist(err) {
+		t.Fatalf("empty session dir should be removed: %v", err)
+	}
+	if err := store.DeleteCheckpoints(ctx, "session-1"); err != nil {
+		t.Fatalf("DeleteCheckpoints: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "session-1", "history.json")); err != nil {
+		t.Fatalf("session files should be kept: %v", err)
+	}
+	if err := store.DeleteCheckpoints(ctx, "../escape"); err == nil {
+		t.Fatal("unsafe checkpoint ID should be rejected")
+	}
+}
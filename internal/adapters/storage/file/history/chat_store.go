@@ -43,6 +43,7 @@ func (s *ChatSessionStore) UpdateMetadata(_ context.Context, update appchat.Meta
 			meta.ID = update.SessionID
 			meta.Title = titleFromSource(update.TitleSource, update.DefaultTitle)
 			meta.Status = domainsession.Status(update.Status)
+			meta.Owner = update.Owner
 			meta.CreatedAt = now
 			meta.UpdatedAt = now
 		} else {
@@ -60,6 +61,9 @@ func (s *ChatSessionStore) UpdateMetadata(_ context.Context, update appchat.Meta
 		if update.CurrentAgent != nil {
 			meta.CurrentAgent = strings.TrimSpace(*update.CurrentAgent)
 		}
+		if update.Project != nil {
+			meta.Project = strings.TrimSpace(*update.Project)
+		}
 		return nil
 	})
 	if errors.Is(err, os.ErrNotExist) && !update.CreateIfMissing {
@@ -496,6 +496,9 @@ func (h *HistoryRecorder) RecordEvent(event Event) {
 			CompletionTokens: event.CompletionTokens,
 			TotalTokens:      event.TotalTokens,
 		}
+		if event.Usage != nil {
+			evt.Usage.Model = event.Usage.Model
+		}
 		ctx.msg.Events = append(ctx.msg.Events, evt)
 
 	case events.EventAssistantReasoning, events.EventAssistantText:
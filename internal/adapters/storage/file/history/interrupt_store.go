@@ -0,0 +1,113 @@
+package eventlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"fkteams/internal/app/chat/taskstream"
+	domainsession "fkteams/internal/domain/session"
+	"fkteams/internal/runtime/atomicfile"
+)
+
+// InterruptFileName 是会话目录下保存等待中断的文件名。
+const InterruptFileName = "interrupt.json"
+
+const maxInterruptFileBytes = 1 << 20
+
+// InterruptStore 将等待人工输入的中断保存在各自会话目录中。
+type InterruptStore struct {
+	sessionsDir string
+}
+
+func NewInterruptStore(sessionsDir string) *InterruptStore {
+	return &InterruptStore{sessionsDir: sessionsDir}
+}
+
+func (s *InterruptStore) SavePendingInterrupt(_ context.Context, pending taskstream.PendingInterrupt) error {
+	if !domainsession.ValidID(pending.SessionID) {
+		return fmt.Errorf("invalid session ID")
+	}
+	data, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal pending interrupt: %w", err)
+	}
+	sessionDir := filepath.Join(s.sessionsDir, pending.SessionID)
+	if err := os.MkdirAll(sessionDir, 0755); err != nil {
+		return fmt.Errorf("create session dir: %w", err)
+	}
+	return atomicfile.WriteFile(filepath.Join(sessionDir, InterruptFileName), data, 0600)
+}
+
+func (s *InterruptStore) LoadPendingInterrupt(_ context.Context, sessionID string) (*taskstream.PendingInterrupt, error) {
+	if !domainsession.ValidID(sessionID) {
+		return nil, fmt.Errorf("invalid session ID")
+	}
+	return loadPendingInterrupt(filepath.Join(s.sessionsDir, sessionID, InterruptFileName))
+}
+
+func (s *InterruptStore) DeletePendingInterrupt(_ context.Context, sessionID string) error {
+	if !domainsession.ValidID(sessionID) {
+		return fmt.Errorf("invalid session ID")
+	}
+	err := os.Remove(filepath.Join(s.sessionsDir, sessionID, InterruptFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *InterruptStore) ListPendingInterrupts(_ context.Context) ([]taskstream.PendingInterrupt, error) {
+	entries, err := os.ReadDir(s.sessionsDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []taskstream.PendingInterrupt{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := make([]taskstream.PendingInterrupt, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() || !domainsession.ValidID(entry.Name()) {
+			continue
+		}
+		pending, err := loadPendingInterrupt(filepath.Join(s.sessionsDir, entry.Name(), InterruptFileName))
+		if err != nil || pending == nil {
+			continue
+		}
+		pending.SessionID = entry.Name()
+		result = append(result, *pending)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+func loadPendingInterrupt(path string) (*taskstream.PendingInterrupt, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxInterruptFileBytes {
+		return nil, fmt.Errorf("pending interrupt is too large")
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var pending taskstream.PendingInterrupt
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, fmt.Errorf("decode pending interrupt: %w", err)
+	}
+	return &pending, nil
+}
@@ -0,0 +1,59 @@
+package eventlog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"fkteams/internal/app/chat/taskstream"
+)
+
+func TestInterruptStoreRoundTrip(t *testing.T) {
+	store := NewInterruptStore(t.TempDir())
+	ctx := context.Background()
+	pending := taskstream.PendingInterrupt{
+		SessionID:    "session-1",
+		Kind:         taskstream.InterruptApproval,
+		InterruptIDs: []string{"interrupt-1"},
+		Mode:         "team",
+		Message:      "run command",
+		CreatedAt:    time.Now(),
+	}
+	if err := store.SavePendingInterrupt(ctx, pending); err != nil {
+		t.Fatalf("SavePendingInterrupt returned error: %v", err)
+	}
+
+	loaded, err := store.LoadPendingInterrupt(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("LoadPendingInterrupt returned error: %v", err)
+	}
+	if loaded == nil || loaded.Kind != taskstream.InterruptApproval || loaded.Mode != "team" || len(loaded.InterruptIDs) != 1 {
+		t.Fatalf("loaded = %#v, want saved interrupt", loaded)
+	}
+	list, err := store.ListPendingInterrupts(ctx)
+	if err != nil {
+		t.Fatalf("ListPendingInterrupts returned error: %v", err)
+	}
+	if len(list) != 1 || list[0].SessionID != "session-1" {
+		t.Fatalf("list = %#v, want one interrupt", list)
+	}
+
+	if err := store.DeletePendingInterrupt(ctx, "session-1"); err != nil {
+		t.Fatalf("DeletePendingInterrupt returned error: %v", err)
+	}
+	loaded, err = store.LoadPendingInterrupt(ctx, "session-1")
+	if err != nil || loaded != nil {
+		t.Fatalf("LoadPendingInterrupt after delete = %#v, %v; want nil", loaded, err)
+	}
+	if err := store.DeletePendingInterrupt(ctx, "session-1"); err != nil {
+		t.Fatalf("second DeletePendingInterrupt returned error: %v", err)
+	}
+}
+
+func TestInterruptStoreRejectsInvalidSessionID(t *testing.T) {
+	store := NewInterruptStore(t.TempDir())
+	err := store.SavePendingInterrupt(context.Background(), taskstream.PendingInterrupt{SessionID: "../escape"})
+	if err == nil {
+		t.Fatal("SavePendingInterrupt accepted invalid session ID")
+	}
+}
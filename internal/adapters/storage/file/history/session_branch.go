@@ -0,0 +1,265 @@
+package eventlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"fkteams/internal/runtime/atomicfile"
+)
+
+const todosFileName = "todos.json"
+
+// ErrTranscriptEventNotFound 表示分叉或回退目标不在会话记录中。
+var ErrTranscriptEventNotFound = errors.New("transcript event not found")
+
+// ErrRewindTargetNotUserMessage 表示回退目标不是主记录中的用户消息。
+var ErrRewindTargetNotUserMessage = errors.New("rewind target must be a user message")
+
+// branchSource 是分叉和回退时读取的会话记录快照：主记录和各成员运行的子记录。
+type branchSource struct {
+	main []TranscriptEvent
+	runs []subagentTranscript
+}
+
+type subagentTranscript struct {
+	dir      string // subagents 下的目录名
+	metadata SubagentMetadata
+	events   []TranscriptEvent
+}
+
+// branchCut 描述保留范围：主记录保留前 mainKeep 条，子记录按时间与 cutoff 比较。
+type branchCut struct {
+	mainKeep  int
+	cutoff    time.Time
+	inclusive bool
+	target    TranscriptEvent
+}
+
+func (c branchCut) keeps(at time.Time) bool {
+	if c.inclusive {
+		return !at.After(c.cutoff)
+	}
+	return at.Before(c.cutoff)
+}
+
+func loadBranchSource(sessionDir string) (branchSource, error) {
+	main, err := loadTranscript(transcriptPath(sessionDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return branchSource{}, err
+	}
+	source := branchSource{main: main}
+	matches, err := filepath.Glob(filepath.Join(sessionDir, subagentsDirName, "*", TranscriptFileName))
+	if err != nil {
+		return branchSource{}, fmt.Errorf("find subagent transcripts: %w", err)
+	}
+	for _, filePath := range matches {
+		runDir := filepath.Dir(filePath)
+		metadata, err := loadSubagentMetadata(filepath.Join(runDir, "metadata.json"))
+		if err != nil {
+			return branchSource{}, fmt.Errorf("load subagent transcript metadata: %w", err)
+		}
+		events, err := loadTranscript(filePath)
+		if err != nil {
+			return branchSource{}, fmt.Errorf("load subagent transcript: %w", err)
+		}
+		source.runs = append(source.runs, subagentTranscript{dir: filepath.Base(runDir), metadata: metadata, events: events})
+	}
+	return source, nil
+}
+
+// cutAfter 返回保留到 eventID（含）的截断点；目标位于成员子记录时，主记录保留不晚于该事件的部分。
+func (s branchSource) cutAfter(eventID string) (branchCut, bool) {
+	for i, event := range s.main {
+		if event.ID == eventID {
+			return branchCut{mainKeep: i + 1, cutoff: event.At, inclusive: true, target: event}, true
+		}
+	}
+	for _, run := range s.runs {
+		for _, event := range run.events {
+			if event.ID != eventID {
+				continue
+			}
+			keep := 0
+			for keep < len(s.main) && !s.main[keep].At.After(event.At) {
+				keep++
+			}
+			return branchCut{mainKeep: keep, cutoff: event.At, inclusive: true, target: event}, true
+		}
+	}
+	return branchCut{}, false
+}
+
+// cutBefore 返回丢弃 eventID 指向的用户消息及其后记录的截断点。
+func (s branchSource) cutBefore(eventID string) (branchCut, error) {
+	for i, event := range s.main {
+		if event.ID != eventID {
+			continue
+		}
+		if event.Type != TranscriptUserMessage {
+			return branchCut{}, ErrRewindTargetNotUserMessage
+		}
+		return branchCut{mainKeep: i, cutoff: event.At, target: event}, nil
+	}
+	return branchCut{}, ErrTranscriptEventNotFound
+}
+
+func (s branchSource) apply(cut branchCut) branchSource {
+	kept := branchSource{main: s.main[:cut.mainKeep]}
+	for _, run := range s.runs {
+		var events []TranscriptEvent
+		for _, event := range run.events {
+			if cut.keeps(event.At) {
+				events = append(events, event)
+			}
+		}
+		if len(events) > 0 {
+			run.events = events
+			kept.runs = append(kept.runs, run)
+		}
+	}
+	return kept
+}
+
+func (s branchSource) eventCount() int {
+	count := len(s.main)
+	for _, run := range s.runs {
+		count += len(run.events)
+	}
+	return count
+}
+
+func (s branchSource) resultRefs() map[string]struct{} {
+	refs := make(map[string]struct{})
+	add := func(events []TranscriptEvent) {
+		for _, event := range events {
+			if event.ResultRef != "" {
+				refs[filepath.Base(event.ResultRef)] = struct{}{}
+			}
+		}
+	}
+	add(s.main)
+	for _, run := range s.runs {
+		add(run.events)
+	}
+	return refs
+}
+
+// write 将记录写入 sessionDir，覆盖已有的主记录和同名子记录。
+func (s branchSource) write(sessionDir string) error {
+	if err := writeTranscriptFile(transcriptPath(sessionDir), s.main); err != nil {
+		return err
+	}
+	for _, run := range s.runs {
+		runDir := filepath.Join(sessionDir, subagentsDirName, run.dir)
+		if err := writeTranscriptFile(filepath.Join(runDir, TranscriptFileName), run.events); err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(run.metadata, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encode subagent metadata: %w", err)
+		}
+		if err := atomicfile.WriteFile(filepath.Join(runDir, "metadata.json"), data, 0644); err != nil {
+			return fmt.Errorf("write subagent metadata: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeTranscriptFile(filePath string, events []TranscriptEvent) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("encode transcript: %w", err)
+		}
+	}
+	if err := atomicfile.WriteFile(filePath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("write transcript: %w", err)
+	}
+	return nil
+}
+
+// forkSessionDir 将 srcDir 中截至 eventID（含）的记录、引用的工具结果和待办状态复制到 dstDir。
+func forkSessionDir(srcDir, dstDir, eventID string) (TranscriptEvent, error) {
+	source, err := loadBranchSource(srcDir)
+	if err != nil {
+		return TranscriptEvent{}, err
+	}
+	cut, ok := source.cutAfter(eventID)
+	if !ok {
+		return TranscriptEvent{}, ErrTranscriptEventNotFound
+	}
+	kept := source.apply(cut)
+	if err := kept.write(dstDir); err != nil {
+		return TranscriptEvent{}, err
+	}
+	for name := range kept.resultRefs() {
+		rel := filepath.Join(toolResultsDirName, name)
+		if err := copySessionFile(filepath.Join(srcDir, rel), filepath.Join(dstDir, rel)); err != nil {
+			return TranscriptEvent{}, fmt.Errorf("copy tool result: %w", err)
+		}
+	}
+	if err := copySessionFile(filepath.Join(srcDir, todosFileName), filepath.Join(dstDir, todosFileName)); err != nil {
+		return TranscriptEvent{}, fmt.Errorf("copy todos: %w", err)
+	}
+	return cut.target, nil
+}
+
+// rewindSessionDir 丢弃 eventID 指向的用户消息及其后的记录，并清理不再被引用的子记录和工具结果。
+// 返回回退目标消息和丢弃的记录数。
+func rewindSessionDir(sessionDir, eventID string) (TranscriptEvent, int, error) {
+	source, err := loadBranchSource(sessionDir)
+	if err != nil {
+		return TranscriptEvent{}, 0, err
+	}
+	cut, err := source.cutBefore(eventID)
+	if err != nil {
+		return TranscriptEvent{}, 0, err
+	}
+	kept := source.apply(cut)
+	if err := kept.write(sessionDir); err != nil {
+		return TranscriptEvent{}, 0, err
+	}
+	keptRuns := make(map[string]struct{}, len(kept.runs))
+	for _, run := range kept.runs {
+		keptRuns[run.dir] = struct{}{}
+	}
+	for _, run := range source.runs {
+		if _, ok := keptRuns[run.dir]; !ok {
+			if err := os.RemoveAll(filepath.Join(sessionDir, subagentsDirName, run.dir)); err != nil {
+				return TranscriptEvent{}, 0, fmt.Errorf("remove subagent transcript: %w", err)
+			}
+		}
+	}
+	refs := kept.resultRefs()
+	entries, err := os.ReadDir(filepath.Join(sessionDir, toolResultsDirName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return TranscriptEvent{}, 0, fmt.Errorf("read tool results: %w", err)
+	}
+	for _, entry := range entries {
+		if _, ok := refs[entry.Name()]; ok || entry.IsDir() {
+			continue
+		}
+		if err := os.Remove(filepath.Join(sessionDir, toolResultsDirName, entry.Name())); err != nil {
+			return TranscriptEvent{}, 0, fmt.Errorf("remove tool result: %w", err)
+		}
+	}
+	return cut.target, source.eventCount() - kept.eventCount(), nil
+}
+
+// copySessionFile 复制会话目录中的普通文件，来源不存在时跳过。
+func copySessionFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(dst, data, 0644)
+}
@@ -0,0 +1,146 @@
+package eventlog
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fkteams/internal/domain/apperror"
+	domainsession "fkteams/internal/domain/session"
+)
+
+func writeBranchFixture(t *testing.T, root, sessionID string) {
+	t.Helper()
+	dir := filepath.Join(root, sessionID)
+	base := time.Unix(1000, 0).UTC()
+	if err := SaveMetadata(dir, &SessionMetadata{ID: sessionID, Title: "source", Status: domainsession.StatusActive, CreatedAt: base, UpdatedAt: base}); err != nil {
+		t.Fatal(err)
+	}
+	main := []TranscriptEvent{
+		{ID: "u1", At: base, Type: TranscriptUserMessage, Content: "first"},
+		{ID: "c1", At: base.Add(time.Second), Type: TranscriptToolCallEnd, CallID: "call-1", ResultRef: toolResultsDirName + "/call-1.json"},
+		{ID: "a1", At: base.Add(2 * time.Second), Type: TranscriptAssistantMessage, Content: "done"},
+		{ID: "u2", At: base.Add(3 * time.Second), Type: TranscriptUserMessage, Content: "second"},
+		{ID: "c2", At: base.Add(4 * time.Second), Type: TranscriptToolCallEnd, CallID: "call-2", ResultRef: toolResultsDirName + "/call-2.json"},
+	}
+	for _, name := range []string{"call-1.json", "call-2.json"} {
+		if err := os.MkdirAll(filepath.Join(dir, toolResultsDirName), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, toolResultsDirName, name), []byte(`{}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, todosFileName), []byte(`[]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	source := branchSource{main: main, runs: []subagentTranscript{{
+		dir:      "run-1",
+		metadata: SubagentMetadata{AgentRunID: "run-1", Agent: "coder"},
+		events:   []TranscriptEvent{{ID: "s1", At: base.Add(4 * time.Second), Type: TranscriptAssistantMessage, Content: "sub"}},
+	}}}
+	if err := source.write(dir); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSessionRepositoryForkCopiesTranscriptPrefix(t *testing.T) {
+	root := t.TempDir()
+	writeBranchFixture(t, root, "source")
+	repository := NewSessionRepository(root)
+
+	metadata := SessionMetadata{ID: "fork", Title: "fork", ForkedFrom: "source", ForkedAt: "a1"}
+	if _, err := repository.ForkSession(context.Background(), "source", "a1", metadata); err != nil {
+		t.Fatalf("ForkSession: %v", err)
+	}
+	forkDir := filepath.Join(root, "fork")
+	events, err := loadTranscript(transcriptPath(forkDir))
+	if err != nil || len(events) != 3 || events[2].ID != "a1" {
+		t.Fatalf("forked transcript = %#v, %v", events, err)
+	}
+	if _, err := os.Stat(filepath.Join(forkDir, toolResultsDirName, "call-1.json")); err != nil {
+		t.Fatalf("referenced tool result was not copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(forkDir, toolResultsDirName, "call-2.json")); !os.IsNotExist(err) {
+		t.Fatalf("unreferenced tool result copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(forkDir, todosFileName)); err != nil {
+		t.Fatalf("todos were not copied: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(forkDir, subagentsDirName, "run-1")); !os.IsNotExist(err) {
+		t.Fatalf("later subagent run copied: %v", err)
+	}
+	if loaded, err := LoadMetadata(forkDir); err != nil || loaded.ForkedFrom != "source" {
+		t.Fatalf("fork metadata = %#v, %v", loaded, err)
+	}
+
+	if _, err := repository.ForkSession(context.Background(), "source", "a1", metadata); apperror.CodeOf(err) != apperror.CodeConflict {
+		t.Fatalf("duplicate fork error = %v", err)
+	}
+	metadata.ID = "fork-2"
+	if _, err := repository.ForkSession(context.Background(), "source", "missing", metadata); apperror.CodeOf(err) != apperror.CodeNotFound {
+		t.Fatalf("unknown event error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "fork-2")); !os.IsNotExist(err) {
+		t.Fatalf("failed fork left directory behind: %v", err)
+	}
+}
+
+func TestSessionRepositoryForkAtSubagentEventKeepsEarlierMainEvents(t *testing.T) {
+	root := t.TempDir()
+	writeBranchFixture(t, root, "source")
+	repository := NewSessionRepository(root)
+
+	if _, err := repository.ForkSession(context.Background(), "source", "s1", SessionMetadata{ID: "fork"}); err != nil {
+		t.Fatalf("ForkSession: %v", err)
+	}
+	events, err := loadTranscript(transcriptPath(filepath.Join(root, "fork")))
+	if err != nil || len(events) != 5 {
+		t.Fatalf("forked transcript = %d events, %v", len(events), err)
+	}
+	sub, err := loadTranscript(filepath.Join(root, "fork", subagentsDirName, "run-1", TranscriptFileName))
+	if err != nil || len(sub) != 1 {
+		t.Fatalf("forked subagent transcript = %#v, %v", sub, err)
+	}
+}
+
+func TestSessionRepositoryRewindDropsLaterEvents(t *testing.T) {
+	root := t.TempDir()
+	writeBranchFixture(t, root, "source")
+	repository := NewSessionRepository(root)
+
+	if _, err := repository.RewindSession(context.Background(), "source", "a1"); apperror.CodeOf(err) != apperror.CodeInvalidArgument {
+		t.Fatalf("rewind to assistant message error = %v", err)
+	}
+
+	manager := NewSessionHistoryManager()
+	_, release := manager.Acquire("source", root)
+	if _, err := repository.RewindSession(context.Background(), "source", "u2"); apperror.CodeOf(err) != apperror.CodeConflict {
+		t.Fatalf("rewind while acquired error = %v", err)
+	}
+	release()
+
+	result, err := repository.RewindSession(context.Background(), "source", "u2")
+	if err != nil {
+		t.Fatalf("RewindSession: %v", err)
+	}
+	if result.Message != "second" || result.Removed != 3 {
+		t.Fatalf("rewind result = %#v", result)
+	}
+	dir := filepath.Join(root, "source")
+	events, err := loadTranscript(transcriptPath(dir))
+	if err != nil || len(events) != 3 {
+		t.Fatalf("rewound transcript = %#v, %v", events, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, toolResultsDirName, "call-2.json")); !os.IsNotExist(err) {
+		t.Fatalf("dropped tool result still exists: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, toolResultsDirName, "call-1.json")); err != nil {
+		t.Fatalf("kept tool result removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, subagentsDirName, "run-1")); !os.IsNotExist(err) {
+		t.Fatalf("dropped subagent run still exists: %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"fkteams/internal/domain/apperror"
 	domainsession "fkteams/internal/domain/session"
@@ -132,3 +133,83 @@ func (r *SessionRepository) DeleteSession(_ context.Context, sessionID string) e
 	}
 	return nil
 }
+
+func (r *SessionRepository) ForkSession(_ context.Context, sourceID, eventID string, metadata domainsession.Metadata) (domainsession.Metadata, error) {
+	if !domainsession.ValidID(sourceID) || !domainsession.ValidID(metadata.ID) {
+		return domainsession.Metadata{}, apperror.New(apperror.CodeInvalidArgument, "invalid session ID")
+	}
+	if eventID == "" {
+		return domainsession.Metadata{}, apperror.New(apperror.CodeInvalidArgument, "event ID is required")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sourceDir := filepath.Join(r.root, sourceID)
+	if _, err := LoadMetadata(sourceDir); errors.Is(err, os.ErrNotExist) {
+		return domainsession.Metadata{}, apperror.New(apperror.CodeNotFound, "session not found")
+	} else if err != nil {
+		return domainsession.Metadata{}, apperror.Wrap(apperror.CodeUnavailable, "session storage unavailable", err)
+	}
+	targetDir := filepath.Join(r.root, metadata.ID)
+	if _, err := os.Stat(targetDir); err == nil {
+		return domainsession.Metadata{}, apperror.New(apperror.CodeConflict, "session already exists")
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return domainsession.Metadata{}, apperror.Wrap(apperror.CodeUnavailable, "session storage unavailable", err)
+	}
+	if _, err := forkSessionDir(sourceDir, targetDir, eventID); err != nil {
+		_ = os.RemoveAll(targetDir)
+		if errors.Is(err, ErrTranscriptEventNotFound) {
+			return domainsession.Metadata{}, apperror.New(apperror.CodeNotFound, "transcript event not found")
+		}
+		return domainsession.Metadata{}, apperror.Wrap(apperror.CodeUnavailable, "session storage unavailable", err)
+	}
+	if err := SaveMetadata(targetDir, &metadata); err != nil {
+		_ = os.RemoveAll(targetDir)
+		return domainsession.Metadata{}, apperror.Wrap(apperror.CodeUnavailable, "session storage unavailable", err)
+	}
+	return metadata, nil
+}
+
+func (r *SessionRepository) RewindSession(_ context.Context, sessionID, eventID string) (domainsession.RewindResult, error) {
+	if !domainsession.ValidID(sessionID) {
+		return domainsession.RewindResult{}, apperror.New(apperror.CodeInvalidArgument, "invalid session ID")
+	}
+	if eventID == "" {
+		return domainsession.RewindResult{}, apperror.New(apperror.CodeInvalidArgument, "event ID is required")
+	}
+	// 回退会改写记录文件，与删除一样要求会话当前没有使用者。
+	finishRewind, ok := beginSessionDelete(r.root, sessionID)
+	if !ok {
+		return domainsession.RewindResult{}, apperror.New(apperror.CodeConflict, "session is active")
+	}
+	defer finishRewind()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sessionDir := filepath.Join(r.root, sessionID)
+	if _, err := LoadMetadata(sessionDir); errors.Is(err, os.ErrNotExist) {
+		return domainsession.RewindResult{}, apperror.New(apperror.CodeNotFound, "session not found")
+	} else if err != nil {
+		return domainsession.RewindResult{}, apperror.Wrap(apperror.CodeUnavailable, "session storage unavailable", err)
+	}
+	target, removed, err := rewindSessionDir(sessionDir, eventID)
+	switch {
+	case errors.Is(err, ErrTranscriptEventNotFound):
+		return domainsession.RewindResult{}, apperror.New(apperror.CodeNotFound, "transcript event not found")
+	case errors.Is(err, ErrRewindTargetNotUserMessage):
+		return domainsession.RewindResult{}, apperror.New(apperror.CodeInvalidArgument, err.Error())
+	case err != nil:
+		return domainsession.RewindResult{}, apperror.Wrap(apperror.CodeUnavailable, "session storage unavailable", err)
+	}
+	metadata, err := UpdateMetadata(sessionDir, false, func(metadata *domainsession.Metadata) error {
+		metadata.UpdatedAt = time.Now()
+		return nil
+	})
+	if err != nil {
+		return domainsession.RewindResult{}, apperror.Wrap(apperror.CodeUnavailable, "session storage unavailable", err)
+	}
+	return domainsession.RewindResult{
+		Metadata:     *metadata,
+		Removed:      removed,
+		Message:      target.Content,
+		ContentParts: target.ContentParts,
+	}, nil
+}
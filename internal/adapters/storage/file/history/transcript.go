@@ -310,5 +310,9 @@ func usageRecordFromEvent(event Event) *UsageRecord {
 	if promptTokens == 0 && completionTokens == 0 && totalTokens == 0 {
 		return nil
 	}
-	return &UsageRecord{PromptTokens: promptTokens, CompletionTokens: completionTokens, TotalTokens: totalTokens}
+	record := &UsageRecord{PromptTokens: promptTokens, CompletionTokens: completionTokens, TotalTokens: totalTokens}
+	if event.Usage != nil {
+		record.Model = event.Usage.Model
+	}
+	return record
 }
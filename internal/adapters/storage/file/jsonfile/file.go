@@ -0,0 +1,82 @@
+// Package jsonfile 提供以单个 JSON 文件保存整份数据的存储，供账号、API 密钥等小型目录复用。
+package jsonfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"fkteams/internal/runtime/atomicfile"
+)
+
+// MaxFileBytes 是读取文件的大小上限，防止异常文件占满内存。
+const MaxFileBytes = 4 << 20
+
+// File 将 T 整体编码保存在 path，文件权限为 0600，目录权限为 0700，写入通过临时文件原子替换。
+type File[T any] struct {
+	path string
+	// name 用于错误信息，如 "account"
+	name string
+	mu   sync.Mutex
+}
+
+// New 创建以 path 为存储文件的 JSON 文件存储。
+func New[T any](path, name string) *File[T] {
+	return &File[T]{path: path, name: name}
+}
+
+// Load 读取并解码文件，文件不存在时返回零值。
+func (f *File[T]) Load() (T, error) {
+	var value T
+	if f == nil || f.path == "" {
+		return value, f.notConfigured()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, err := os.Stat(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return value, nil
+	}
+	if err != nil {
+		return value, err
+	}
+	if info.Size() > MaxFileBytes {
+		return value, fmt.Errorf("%s file exceeds %d bytes", f.name, MaxFileBytes)
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return value, err
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("decode %s file: %w", f.name, err)
+	}
+	return value, nil
+}
+
+// Save 编码 value 并原子替换文件。
+func (f *File[T]) Save(value T) error {
+	if f == nil || f.path == "" {
+		return f.notConfigured()
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s file: %w", f.name, err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return fmt.Errorf("create %s directory: %w", f.name, err)
+	}
+	return atomicfile.WriteFile(f.path, data, 0600)
+}
+
+func (f *File[T]) notConfigured() error {
+	name := "json file"
+	if f != nil && f.name != "" {
+		name = f.name
+	}
+	return fmt.Errorf("%s storage is not configured", name)
+}
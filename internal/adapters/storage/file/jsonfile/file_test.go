@@ -0,0 +1,74 @@
+package jsonfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type catalog struct {
+	Items []string `json:"items"`
+}
+
+func TestFileRoundTripsWithPrivatePermissions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "catalog.json")
+	file := New[catalog](path, "catalog")
+
+	value, err := file.Load()
+	if err != nil || len(value.Items) != 0 {
+		t.Fatalf("Load on missing file = %#v, %v", value, err)
+	}
+
+	if err := file.Save(catalog{Items: []string{"a", "b"}}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	for _, check := range []struct {
+		path string
+		perm os.FileMode
+	}{{path, 0600}, {filepath.Dir(path), 0700}} {
+		info, err := os.Stat(check.path)
+		if err != nil {
+			t.Fatalf("stat %s: %v", check.path, err)
+		}
+		if info.Mode().Perm() != check.perm {
+			t.Fatalf("%s mode = %v, want %v", check.path, info.Mode().Perm(), check.perm)
+		}
+	}
+
+	loaded, err := New[catalog](path, "catalog").Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if strings.Join(loaded.Items, ",") != "a,b" {
+		t.Fatalf("loaded = %#v", loaded)
+	}
+}
+
+func TestFileRejectsCorruptAndOversizedFiles(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("{not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New[catalog](corrupt, "catalog").Load(); err == nil || !strings.Contains(err.Error(), "decode catalog file") {
+		t.Fatalf("corrupt file error = %v", err)
+	}
+
+	oversized := filepath.Join(dir, "oversized.json")
+	if err := os.WriteFile(oversized, make([]byte, MaxFileBytes+1), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New[catalog](oversized, "catalog").Load(); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Fatalf("oversized file error = %v", err)
+	}
+}
+
+func TestFileRequiresPath(t *testing.T) {
+	if _, err := New[catalog]("", "catalog").Load(); err == nil || err.Error() != "catalog storage is not configured" {
+		t.Fatalf("Load without path error = %v", err)
+	}
+	if err := New[catalog]("", "catalog").Save(catalog{}); err == nil {
+		t.Fatal("Save without path should fail")
+	}
+}
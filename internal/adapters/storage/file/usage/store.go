@@ -0,0 +1,144 @@
+// Package usage 提供按天分文件的 JSONL 模型用量存储。
+package usage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	domainusage "fkteams/internal/domain/usage"
+	storageport "fkteams/internal/ports/storage"
+	"fkteams/internal/runtime/log"
+)
+
+const (
+	fileExt        = ".jsonl"
+	maxRecordBytes = 64 << 10
+)
+
+var _ storageport.UsageStore = (*Store)(nil)
+
+// Store 将用量记录追加写入 <root>/<YYYY-MM-DD>.jsonl。
+type Store struct {
+	root string
+	mu   sync.Mutex
+}
+
+// NewStore 创建以 root 为目录的用量存储。
+func NewStore(root string) *Store {
+	return &Store{root: root}
+}
+
+func (s *Store) AppendUsage(_ context.Context, record domainusage.Record) error {
+	if s == nil || s.root == "" {
+		return fmt.Errorf("usage storage is not configured")
+	}
+	if record.At.IsZero() {
+		record.At = time.Now()
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encode usage record: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(s.root, 0755); err != nil {
+		return fmt.Errorf("create usage directory: %w", err)
+	}
+	file, err := os.OpenFile(filepath.Join(s.root, record.Day()+fileExt), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("open usage file: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write usage record: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close usage file: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) ListUsage(_ context.Context, from, to time.Time) ([]domainusage.Record, error) {
+	if s == nil || s.root == "" {
+		return nil, fmt.Errorf("usage storage is not configured")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := os.ReadDir(s.root)
+	if errors.Is(err, os.ErrNotExist) {
+		return []domainusage.Record{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read usage directory: %w", err)
+	}
+	days := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		day, ok := strings.CutSuffix(entry.Name(), fileExt)
+		if entry.IsDir() || !ok {
+			continue
+		}
+		if _, err := time.ParseInLocation(domainusage.DayLayout, day, time.Local); err != nil {
+			continue
+		}
+		if !from.IsZero() && day < from.Local().Format(domainusage.DayLayout) {
+			continue
+		}
+		if !to.IsZero() && day > to.Local().Format(domainusage.DayLayout) {
+			continue
+		}
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	filter := domainusage.Filter{From: from, To: to}
+	records := []domainusage.Record{}
+	for _, day := range days {
+		dayRecords, err := readDay(filepath.Join(s.root, day+fileExt))
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range dayRecords {
+			if filter.Match(record) {
+				records = append(records, record)
+			}
+		}
+	}
+	return records, nil
+}
+
+// readDay 读取单日文件；损坏的行（如进程崩溃时写了一半）被跳过。
+func readDay(path string) ([]domainusage.Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open usage file: %w", err)
+	}
+	defer file.Close()
+	var records []domainusage.Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 4096), maxRecordBytes)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var record domainusage.Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			log.Printf("[usage] skip malformed record in %s: %v", filepath.Base(path), err)
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read usage file: %w", err)
+	}
+	return records, nil
+}
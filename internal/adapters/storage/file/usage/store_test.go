@@ -0,0 +1,75 @@
+package usage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	domainusage "fkteams/internal/domain/usage"
+)
+
+func TestStoreAppendsAndListsByRange(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := NewStore(root)
+
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	day2 := day1.Add(24 * time.Hour)
+	for _, record := range []domainusage.Record{
+		{At: day1, SessionID: "s1", Model: "m1", TotalTokens: 10},
+		{At: day2, SessionID: "s1", Model: "m1", TotalTokens: 20},
+		{At: day2.Add(time.Hour), SessionID: "s2", Model: "m2", TotalTokens: 30},
+	} {
+		if err := store.AppendUsage(ctx, record); err != nil {
+			t.Fatalf("AppendUsage: %v", err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, day2.Format(domainusage.DayLayout)+fileExt)); err != nil {
+		t.Fatalf("usage should be stored per day: %v", err)
+	}
+
+	all, err := NewStore(root).ListUsage(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("ListUsage: %v", err)
+	}
+	if len(all) != 3 || all[0].TotalTokens != 10 {
+		t.Fatalf("all records = %#v", all)
+	}
+
+	ranged, err := store.ListUsage(ctx, day2, day2.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("ListUsage range: %v", err)
+	}
+	if len(ranged) != 1 || ranged[0].TotalTokens != 20 {
+		t.Fatalf("ranged records = %#v", ranged)
+	}
+}
+
+func TestStoreSkipsMalformedLinesAndMissingDir(t *testing.T) {
+	ctx := context.Background()
+	root := filepath.Join(t.TempDir(), "usage")
+	store := NewStore(root)
+	records, err := store.ListUsage(ctx, time.Time{}, time.Time{})
+	if err != nil || len(records) != 0 {
+		t.Fatalf("missing dir = %#v, %v", records, err)
+	}
+
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.Local)
+	if err := store.AppendUsage(ctx, domainusage.Record{At: at, TotalTokens: 5}); err != nil {
+		t.Fatalf("AppendUsage: %v", err)
+	}
+	path := filepath.Join(root, at.Format(domainusage.DayLayout)+fileExt)
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	_, _ = file.WriteString("{\"at\":\n")
+	_ = file.Close()
+
+	records, err = store.ListUsage(ctx, time.Time{}, time.Time{})
+	if err != nil || len(records) != 1 || records[0].TotalTokens != 5 {
+		t.Fatalf("records = %#v, %v", records, err)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"os/exec"
 	"strings"
 	"syscall"
+
+	"fkteams/internal/runtime/sandbox"
 )
 
 func setupProcessGroup(cmd *exec.Cmd) {
@@ -19,7 +21,8 @@ func setupProcessGroup(cmd *exec.Cmd) {
 }
 
 // startBackgroundProcess 以 nohup 后台方式启动命令，stdout/stderr 写入临时文件。
-func startBackgroundProcess(command, workDir string) (*backgroundProcessResult, error) {
+// 沙箱执行时后台进程留在沙箱中，启动它的 shell 退出后仍继续运行。
+func startBackgroundProcess(command, workDir string, env []string, executor sandbox.Executor) (*backgroundProcessResult, error) {
 	stdoutFile, err := os.CreateTemp(workDir, "bg_stdout_*.txt")
 	if err != nil {
 		return nil, err
@@ -42,6 +45,11 @@ func startBackgroundProcess(command, workDir string) (*backgroundProcessResult,
 
 	cmd := exec.Command(shell, shellArgs...)
 	cmd.Dir = workDir
+	cmd.Env = commandEnv(env)
+	opts := wrapOptions(workDir, env)
+	opts.Detached = true
+	release := executor.Wrap(cmd, opts)
+	defer release()
 
 	output, err := cmd.Output()
 	if err != nil {
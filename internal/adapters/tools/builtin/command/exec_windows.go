@@ -8,6 +8,8 @@ import (
 	"os/exec"
 	"strings"
 	"syscall"
+
+	"fkteams/internal/runtime/sandbox"
 )
 
 func setupProcessGroup(cmd *exec.Cmd) {
@@ -18,7 +20,7 @@ func setupProcessGroup(cmd *exec.Cmd) {
 }
 
 // startBackgroundProcess 以 Start-Process 后台方式启动命令，stdout/stderr 写入临时文件。
-func startBackgroundProcess(command, workDir string) (*backgroundProcessResult, error) {
+func startBackgroundProcess(command, workDir string, env []string, executor sandbox.Executor) (*backgroundProcessResult, error) {
 	stdoutFile, err := os.CreateTemp(workDir, "bg_stdout_*.txt")
 	if err != nil {
 		return nil, err
@@ -43,6 +45,11 @@ func startBackgroundProcess(command, workDir string) (*backgroundProcessResult,
 	)
 
 	cmd := exec.Command("powershell", "-NonInteractive", "-Command", psCommand)
+	cmd.Env = commandEnv(env)
+	opts := wrapOptions(workDir, env)
+	opts.Detached = true
+	release := executor.Wrap(cmd, opts)
+	defer release()
 	output, err := cmd.Output()
 	if err != nil {
 		os.Remove(stdoutPath)
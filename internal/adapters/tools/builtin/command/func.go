@@ -13,6 +13,9 @@ import (
 	"time"
 
 	"fkteams/internal/runtime/approval"
+	"fkteams/internal/runtime/env"
+	"fkteams/internal/runtime/pathguard"
+	"fkteams/internal/runtime/sandbox"
 )
 
 // ApprovalMode 审批模式
@@ -31,15 +34,27 @@ func WithApprovalMode(mode ApprovalMode) Option {
 	return func(t *CommandTools) { t.approvalMode = mode }
 }
 
+// WithEnv 设置命令的附加环境变量（KEY=VALUE），追加在当前进程环境之后
+func WithEnv(env []string) Option {
+	return func(t *CommandTools) { t.env = env }
+}
+
+// WithSandbox 设置命令的执行后端，默认直接执行
+func WithSandbox(executor sandbox.Executor) Option {
+	return func(t *CommandTools) { t.sandbox = executor }
+}
+
 // CommandTools 命令行工具，带安全审批功能
 type CommandTools struct {
 	workDir      string
 	approvalMode ApprovalMode
+	env          []string
+	sandbox      sandbox.Executor
 }
 
 // NewCommandTools 创建命令行工具实例
 func NewCommandTools(workDir string, opts ...Option) *CommandTools {
-	t := &CommandTools{workDir: workDir}
+	t := &CommandTools{workDir: workDir, sandbox: sandbox.Direct()}
 	for _, opt := range opts {
 		opt(t)
 	}
@@ -211,7 +226,15 @@ func (t *CommandTools) SmartExecute(ctx context.Context, req *SmartExecuteReques
 		return &SmartExecuteResponse{ErrorMessage: "command is required"}, nil
 	}
 
-	eval := evaluateSecurity(req.Command)
+	eval := evaluateSecurity(req.Command, t.workDir)
+
+	if target, ok := eval.reservedTarget(); ok {
+		return &SmartExecuteResponse{
+			Command:       req.Command,
+			SecurityLevel: securityLevelName(eval.Level),
+			ErrorMessage:  fmt.Sprintf("命令被拒绝：%s 位于只读目录 %s 内", target, pathguard.ReservedDir),
+		}, nil
+	}
 
 	if eval.Level == LevelDangerous {
 		if t.approvalMode == ApprovalModeReject {
@@ -234,6 +257,7 @@ func (t *CommandTools) SmartExecute(ctx context.Context, req *SmartExecuteReques
 				{Name: "Description", Value: eval.Description},
 				{Name: "Risks", Value: strings.Join(eval.Risks, "; ")},
 			},
+			Findings: eval.approvalFindings(),
 		}); err != nil {
 			if errors.Is(err, approval.ErrRejected) {
 				return &SmartExecuteResponse{
@@ -253,6 +277,11 @@ func (t *CommandTools) SmartExecute(ctx context.Context, req *SmartExecuteReques
 	return t.executeCommand(ctx, req, eval)
 }
 
+// wrapOptions 返回沙箱选项：传入项目环境变量，工作区保留目录只读挂载
+func wrapOptions(workDir string, env []string) sandbox.WrapOptions {
+	return sandbox.WrapOptions{ReadOnly: []string{filepath.Join(workDir, pathguard.ReservedDir)}, Env: env}
+}
+
 func (t *CommandTools) executeCommand(ctx context.Context, req *SmartExecuteRequest, eval SecurityEvaluation) (*SmartExecuteResponse, error) {
 	timeout := 60 * time.Second
 	if req.Timeout > 0 && req.Timeout <= 600 {
@@ -266,12 +295,16 @@ func (t *CommandTools) executeCommand(ctx context.Context, req *SmartExecuteRequ
 
 	cmd := exec.CommandContext(ec.cmdCtx, shell, shellArgs...)
 	cmd.Dir = t.workDir
+	cmd.Env = commandEnv(t.env)
 	setupProcessGroup(cmd)
 	cmd.Stdout = ec.stdoutLW
 	cmd.Stderr = ec.stderrLW
+	release := t.sandbox.Wrap(cmd, wrapOptions(t.workDir, t.env))
 
 	ec.startTime = time.Now()
-	if err := cmd.Start(); err != nil {
+	err := cmd.Start()
+	release()
+	if err != nil {
 		ec.cancel()
 		return &SmartExecuteResponse{
 			Command:       req.Command,
@@ -345,7 +378,7 @@ func (t *CommandTools) backgroundExecution(ec *executionContext) (*SmartExecuteR
 
 // executeBackground 立即以后台方式启动命令，stdout/stderr 写入临时文件。
 func (t *CommandTools) executeBackground(req *SmartExecuteRequest, eval SecurityEvaluation) (*SmartExecuteResponse, error) {
-	result, err := startBackgroundProcess(req.Command, t.workDir)
+	result, err := startBackgroundProcess(req.Command, t.workDir, t.env, t.sandbox)
 	if err != nil {
 		return &SmartExecuteResponse{
 			Command:       req.Command,
@@ -466,3 +499,8 @@ func saveOutputToFile(content, workDir string) (string, string, error) {
 	relPath, _ := filepath.Rel(workDir, file.Name())
 	return relPath, preview, nil
 }
+
+// commandEnv 返回子进程环境：当前进程环境加上附加变量，不含密钥库口令等敏感变量。
+func commandEnv(extra []string) []string {
+	return env.ChildEnviron(extra...)
+}
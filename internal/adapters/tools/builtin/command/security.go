@@ -1,6 +1,11 @@
 package command
 
-import "strings"
+import (
+	"runtime"
+	"strings"
+
+	"fkteams/internal/runtime/approval"
+)
 
 // SecurityLevel 安全等级
 type SecurityLevel int
@@ -11,15 +16,29 @@ const (
 	LevelDangerous               // 危险，需要审批或拒绝
 )
 
+// SecurityFinding 单条风险发现，对应命令中的一个程序调用或重定向。
+type SecurityFinding struct {
+	Level   SecurityLevel `json:"level"`
+	Program string        `json:"program,omitempty"`
+	Target  string        `json:"target,omitempty"`
+	// Scope 是 Target 相对于工作区的位置，如 workspace、outside、system、dynamic
+	Scope       string `json:"scope,omitempty"`
+	Description string `json:"description"`
+	Risk        string `json:"risk,omitempty"`
+}
+
 // SecurityEvaluation 安全评估结果
+// Level/Description 取风险最高的发现，Risks 汇总全部发现的风险说明。
 type SecurityEvaluation struct {
-	Level       SecurityLevel
-	Description string
-	Risks       []string
+	Level       SecurityLevel     `json:"level"`
+	Description string            `json:"description"`
+	Risks       []string          `json:"risks,omitempty"`
+	Programs    []string          `json:"programs,omitempty"`
+	Findings    []SecurityFinding `json:"findings,omitempty"`
 }
 
 // 危险命令黑名单
-// dangerousPatterns 精确匹配模式，按优先级排列。
+// dangerousPatterns 精确匹配模式，按优先级排列，仅用于 PowerShell 和无法解析的命令。
 // 模式末尾带 / 或空白表示仅当该位置后无更多字符时才触发（避免 rm -rf / 误匹配 rm -rf /tmp）
 var dangerousPatterns = []struct {
 	Pattern     string
@@ -92,10 +111,30 @@ var riskyPatterns = []struct {
 	{"new-psdrive", LevelModerate, "映射网络驱动器", "可能连接不可信网络资源"},
 }
 
-func evaluateSecurity(command string) SecurityEvaluation {
+// evaluateSecurity 评估命令风险。Unix 上按 bash 语法树分析，workDir 用于区分工作区内外的写入；
+// Windows 的 PowerShell 命令和无法解析的命令回退到模式匹配。
+func evaluateSecurity(command, workDir string) SecurityEvaluation {
+	if runtime.GOOS == "windows" {
+		return evaluatePatterns(command)
+	}
+	eval, err := analyzeShell(command, workDir)
+	if err != nil {
+		eval = evaluatePatterns(command)
+		if eval.Level < LevelModerate {
+			eval = SecurityEvaluation{
+				Level:       LevelModerate,
+				Description: "无法解析的命令",
+				Risks:       []string{"命令语法无法解析，无法评估实际执行的程序: " + err.Error()},
+			}
+		}
+	}
+	return eval
+}
+
+// evaluatePatterns 按模式匹配评估命令，复合命令取所有子命令中最高的安全等级。
+func evaluatePatterns(command string) SecurityEvaluation {
 	segments := splitShellCommands(command)
 	if len(segments) > 1 {
-		// 复合命令：取所有子命令中最高的安全等级
 		var maxEval SecurityEvaluation
 		for _, seg := range segments {
 			eval := evaluateSingleCommand(seg)
@@ -118,8 +157,8 @@ func evaluateSingleCommand(cmdLower string) SecurityEvaluation {
 	if cmdLower != "" && cmdLower[len(cmdLower)-1] == '&' && !strings.HasSuffix(cmdLower, "&&") {
 		return SecurityEvaluation{
 			Level:       LevelDangerous,
-			Description: "后台符号 & 会导致进程脱离管控",
-			Risks:       []string{"进程逃逸后无法被超时或取消终止，请移除末尾的 & 符号，改用 background=true 参数"},
+			Description: backgroundDescription,
+			Risks:       []string{backgroundRisk},
 		}
 	}
 
@@ -147,7 +186,7 @@ func evaluateSingleCommand(cmdLower string) SecurityEvaluation {
 		}
 	}
 
-	return SecurityEvaluation{Level: LevelSafe, Description: "常规命令"}
+	return SecurityEvaluation{Level: LevelSafe, Description: safeDescription}
 }
 
 // splitShellCommands 按 &&、;、| 拆分为独立子命令，关注引号
@@ -190,6 +229,32 @@ func splitShellCommands(command string) []string {
 	return segments
 }
 
+// reservedTarget 返回命令中修改工作区保留目录的第一个目标；这类命令直接拒绝，不进入审批。
+func (e SecurityEvaluation) reservedTarget() (string, bool) {
+	for _, f := range e.Findings {
+		if f.Scope == scopeReserved.String() {
+			return f.Target, true
+		}
+	}
+	return "", false
+}
+
+// approvalFindings 将风险发现转换为审批请求附带的结构化发现。
+func (e SecurityEvaluation) approvalFindings() []approval.Finding {
+	findings := make([]approval.Finding, 0, len(e.Findings))
+	for _, f := range e.Findings {
+		findings = append(findings, approval.Finding{
+			Level:   securityLevelName(f.Level),
+			Program: f.Program,
+			Target:  f.Target,
+			Scope:   f.Scope,
+			Reason:  f.Description,
+			Risk:    f.Risk,
+		})
+	}
+	return findings
+}
+
 func securityLevelName(level SecurityLevel) string {
 	switch level {
 	case LevelSafe:
@@ -0,0 +1,964 @@
+package command
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"fkteams/internal/runtime/pathguard"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+const (
+	safeDescription       = "常规命令"
+	backgroundDescription = "后台符号 & 会导致进程脱离管控"
+	backgroundRisk        = "进程逃逸后无法被超时或取消终止，请移除末尾的 & 符号，改用 background=true 参数"
+)
+
+// maxNestedDepth 限制 sh -c、eval、find -exec 等嵌套命令的分析深度。
+const maxNestedDepth = 4
+
+// pathScope 是命令操作的路径相对于工作区的位置。
+type pathScope int
+
+const (
+	scopeWorkspace pathScope = iota
+	scopeNull                // /dev/null 等无害设备
+	scopeTemp                // 系统临时目录
+	scopeOutside             // 工作区外的普通路径
+	scopeSystem              // 系统目录下的路径
+	scopeCritical            // 根目录、家目录和系统目录本身
+	scopeDevice              // 块设备等设备文件
+	scopeDynamic             // 由变量、命令替换或输入决定的路径
+	scopeReserved            // 工作区保留目录 .fkteams，智能体只能读取
+)
+
+var pathScopeNames = [...]string{
+	scopeWorkspace: "workspace",
+	scopeNull:      "null",
+	scopeTemp:      "temp",
+	scopeOutside:   "outside",
+	scopeSystem:    "system",
+	scopeCritical:  "critical",
+	scopeDevice:    "device",
+	scopeDynamic:   "dynamic",
+	scopeReserved:  "reserved",
+}
+
+func (s pathScope) String() string {
+	if int(s) < len(pathScopeNames) {
+		return pathScopeNames[s]
+	}
+	return "unknown"
+}
+
+var systemDirs = []string{
+	"/bin", "/boot", "/dev", "/etc", "/lib", "/lib32", "/lib64", "/opt", "/proc", "/root",
+	"/sbin", "/srv", "/sys", "/usr", "/var", "/System", "/Library", "/Applications", "/private",
+}
+
+var tempDirs = []string{"/tmp", "/var/tmp", "/private/tmp", "/var/folders", "/private/var/folders"}
+
+var harmlessDevices = []string{
+	"/dev/null", "/dev/zero", "/dev/random", "/dev/urandom", "/dev/stdin", "/dev/stdout", "/dev/stderr", "/dev/tty",
+}
+
+var shellInterpreters = []string{"sh", "bash", "zsh", "dash", "ksh", "fish", "python", "python3", "perl", "ruby", "node"}
+
+// wrapperOptionsWithValue 记录包装命令中需要额外参数值的选项，解析时一并跳过。
+var wrapperOptionsWithValue = map[string][]string{
+	"sudo":    {"-u", "-g", "-C", "-D", "-h", "-p", "-r", "-t", "-U"},
+	"doas":    {"-u", "-C"},
+	"env":     {"-u", "-C", "-S"},
+	"nice":    {"-n"},
+	"ionice":  {"-c", "-n", "-p"},
+	"stdbuf":  {"-i", "-o", "-e"},
+	"timeout": {"-s", "-k"},
+	"xargs":   {"-I", "-i", "-n", "-P", "-L", "-l", "-d", "-E", "-e", "-s", "-a"},
+}
+
+// shellWord 是解析后的单个 shell 参数；dynamic 表示包含变量、命令替换等运行时才能确定的内容。
+type shellWord struct {
+	value   string
+	dynamic bool
+}
+
+// shellAnalyzer 遍历 bash 语法树收集风险发现。
+type shellAnalyzer struct {
+	workDir  string
+	homeDir  string
+	dir      dirState
+	findings []SecurityFinding
+	programs []string
+}
+
+// dirState 是分析到当前位置时 shell 的工作目录，随 cd、pushd、popd 更新。
+// 子 shell、管道和命令替换结束后恢复进入前的状态。
+type dirState struct {
+	cwd string
+	// dynamic 表示工作目录由变量、cd - 等决定，此后的相对路径无法静态确定
+	dynamic bool
+	stack   []dirState
+}
+
+// analyzeShell 解析命令并逐个分析管道、列表、子 shell、命令替换中的程序调用和重定向。
+func analyzeShell(command, workDir string) (SecurityEvaluation, error) {
+	a := &shellAnalyzer{}
+	if workDir != "" {
+		if abs, err := filepath.Abs(workDir); err == nil {
+			a.workDir = filepath.Clean(abs)
+		}
+	}
+	a.dir.cwd = a.workDir
+	if home, err := os.UserHomeDir(); err == nil {
+		a.homeDir = filepath.Clean(home)
+	}
+	file, err := parseShell(command)
+	if err != nil {
+		return SecurityEvaluation{}, err
+	}
+	a.walk(file, 0)
+	return a.evaluation(), nil
+}
+
+func parseShell(command string) (*syntax.File, error) {
+	return syntax.NewParser(syntax.Variant(syntax.LangBash)).Parse(strings.NewReader(command), "")
+}
+
+func (a *shellAnalyzer) walk(node syntax.Node, depth int) {
+	syntax.Walk(node, func(node syntax.Node) bool {
+		switch n := node.(type) {
+		case *syntax.Stmt:
+			if n.Background {
+				a.add(LevelDangerous, "", "", backgroundDescription, backgroundRisk)
+			}
+		case *syntax.BinaryCmd:
+			if n.Op == syntax.Pipe || n.Op == syntax.PipeAll {
+				a.checkPipe(n)
+				// 管道的每一段都在子 shell 中执行，其中的 cd 不影响后续命令
+				a.isolated(func() { a.walk(n.X, depth) })
+				a.isolated(func() { a.walk(n.Y, depth) })
+				return false
+			}
+		case *syntax.Subshell:
+			a.isolated(func() { a.walkStmts(n.Stmts, depth) })
+			return false
+		case *syntax.CmdSubst:
+			a.isolated(func() { a.walkStmts(n.Stmts, depth) })
+			return false
+		case *syntax.ProcSubst:
+			a.isolated(func() { a.walkStmts(n.Stmts, depth) })
+			return false
+		case *syntax.FuncDecl:
+			a.checkFuncDecl(n)
+		case *syntax.Redirect:
+			a.checkRedirect(n)
+		case *syntax.CallExpr:
+			a.checkCall(wordsOf(n.Args), depth)
+		}
+		return true
+	})
+}
+
+func (a *shellAnalyzer) walkStmts(stmts []*syntax.Stmt, depth int) {
+	for _, stmt := range stmts {
+		a.walk(stmt, depth)
+	}
+}
+
+// isolated 在子 shell 语义下执行 fn，结束后恢复工作目录。
+func (a *shellAnalyzer) isolated(fn func()) {
+	saved := a.dir
+	saved.stack = slices.Clone(a.dir.stack)
+	fn()
+	a.dir = saved
+}
+
+// analyzeNested 分析 sh -c、eval 等以字符串形式传入的嵌套命令。
+func (a *shellAnalyzer) analyzeNested(program string, script shellWord, depth int) {
+	if script.dynamic {
+		a.add(LevelModerate, program, script.value, "执行动态生成的脚本", "脚本内容由变量或命令替换决定，无法静态分析")
+		return
+	}
+	if depth >= maxNestedDepth {
+		a.add(LevelModerate, program, "", "嵌套命令层级过深", "超出分析深度，无法评估内部命令")
+		return
+	}
+	file, err := parseShell(script.value)
+	if err != nil {
+		a.add(LevelModerate, program, script.value, "无法解析的嵌套脚本", err.Error())
+		return
+	}
+	if program == "eval" {
+		// eval 在当前 shell 中执行，其中的 cd 会影响后续命令
+		a.walk(file, depth+1)
+		return
+	}
+	a.isolated(func() { a.walk(file, depth+1) })
+}
+
+func (a *shellAnalyzer) checkCall(args []shellWord, depth int) {
+	args = a.unwrap(args, true)
+	if len(args) == 0 {
+		return
+	}
+	if args[0].dynamic {
+		a.add(LevelModerate, "", args[0].value, "动态命令名", "无法静态确定实际执行的程序")
+		return
+	}
+	name := path.Base(args[0].value)
+	a.addProgram(name)
+	rest := args[1:]
+	if strings.HasPrefix(name, "mkfs.") {
+		name = "mkfs"
+	}
+
+	switch name {
+	case "cd", "pushd", "popd":
+		a.changeDir(name, rest)
+	case "rm":
+		a.checkRemove(name, rest)
+	case "rmdir", "unlink":
+		a.checkWrites(name, operands(rest), "删除")
+	case "shred":
+		a.checkShred(name, rest)
+	case "mkfs", "mke2fs", "mkswap", "fdisk", "sfdisk", "gdisk", "parted", "wipefs":
+		a.add(LevelDangerous, name, "", "格式化或分区磁盘", "会清除磁盘上的所有数据")
+	case "dd":
+		a.checkDD(rest)
+	case "chmod", "chown", "chgrp":
+		a.checkPermission(name, rest)
+	case "mv":
+		a.checkMove(rest)
+	case "cp", "install", "ln":
+		a.checkCopy(name, rest)
+	case "rsync":
+		if targets := operands(rest); len(targets) > 1 {
+			a.checkWrites(name, targets[len(targets)-1:], "写入")
+		}
+	case "tee", "truncate":
+		a.checkWrites(name, operands(rest), "写入")
+	case "kill":
+		a.checkKill(rest)
+	case "killall", "pkill":
+		a.add(LevelModerate, name, "", "终止进程", "可能导致服务中断")
+	case "shutdown", "reboot", "halt", "poweroff":
+		a.add(LevelDangerous, name, "", "关机或重启", "会立即中断系统上的所有服务")
+	case "init", "telinit":
+		if ops := operands(rest); len(ops) > 0 && (ops[0].value == "0" || ops[0].value == "6") {
+			a.add(LevelDangerous, name, ops[0].value, "关机或重启", "会立即中断系统上的所有服务")
+		}
+	case "systemctl":
+		if ops := operands(rest); len(ops) > 0 && slices.Contains([]string{"poweroff", "reboot", "halt"}, ops[0].value) {
+			a.add(LevelDangerous, name, ops[0].value, "关机或重启", "会立即中断系统上的所有服务")
+		}
+	case "curl":
+		a.add(LevelModerate, name, "", "下载/上传数据", "可能泄露数据")
+	case "wget":
+		a.add(LevelModerate, name, "", "下载文件", "可能下载恶意内容")
+	case "pip", "pip3":
+		if ops := operands(rest); len(ops) > 0 && ops[0].value == "install" {
+			a.add(LevelModerate, name, "", "安装 Python 包", "可能引入不安全的依赖")
+		}
+	case "npm", "pnpm", "yarn":
+		if hasFlag(rest, "g", "global") || containsWords(rest, "global") {
+			a.add(LevelModerate, name, "", "全局安装包", "可能影响系统环境")
+		}
+	case "sh", "bash", "zsh", "dash", "ksh":
+		for i, arg := range rest {
+			if !arg.dynamic && arg.value == "-c" && i+1 < len(rest) {
+				a.analyzeNested(name, rest[i+1], depth)
+				break
+			}
+		}
+	case "eval":
+		a.analyzeNested(name, joinWords(rest), depth)
+	case "find":
+		a.checkFind(rest, depth)
+	}
+}
+
+// changeDir 按 cd、pushd、popd 更新工作目录；目标无法静态确定时，
+// 之后的相对路径按动态路径处理。
+func (a *shellAnalyzer) changeDir(program string, args []shellWord) {
+	ops := operands(args)
+	if program == "popd" {
+		if n := len(a.dir.stack); n > 0 && len(ops) == 0 {
+			a.dir.cwd, a.dir.dynamic = a.dir.stack[n-1].cwd, a.dir.stack[n-1].dynamic
+			a.dir.stack = a.dir.stack[:n-1]
+		} else {
+			a.dir.dynamic = true
+		}
+		return
+	}
+	if len(ops) == 0 {
+		if program == "pushd" {
+			// 无参数的 pushd 交换栈顶两个目录
+			a.dir.dynamic = true
+			return
+		}
+		ops = []shellWord{{value: "~"}}
+	}
+	if program == "pushd" {
+		a.dir.stack = append(a.dir.stack, dirState{cwd: a.dir.cwd, dynamic: a.dir.dynamic})
+	}
+	dir, ok := a.resolveDir(ops[0])
+	if !ok {
+		a.dir.dynamic = true
+		return
+	}
+	a.dir.cwd, a.dir.dynamic = dir, false
+}
+
+// resolveDir 将 cd 的目标解析为绝对路径，变量、通配符、cd - 和 pushd +N 等无法静态确定。
+func (a *shellAnalyzer) resolveDir(w shellWord) (string, bool) {
+	p := w.value
+	if w.dynamic || p == "-" || strings.HasPrefix(p, "+") || strings.ContainsAny(p, "*?[") {
+		return "", false
+	}
+	switch {
+	case p == "~" || strings.HasPrefix(p, "~/"):
+		if a.homeDir == "" {
+			return "", false
+		}
+		p = filepath.Join(a.homeDir, strings.TrimPrefix(p, "~"))
+	case strings.HasPrefix(p, "~"):
+		return "", false
+	}
+	if !filepath.IsAbs(p) {
+		if a.dir.dynamic || a.dir.cwd == "" {
+			return "", false
+		}
+		p = filepath.Join(a.dir.cwd, p)
+	}
+	return filepath.Clean(p), true
+}
+
+// unwrap 跳过 sudo、env、nohup、xargs 等包装命令，返回实际执行的程序及参数。
+// record 为 true 时记录包装命令本身带来的风险。
+func (a *shellAnalyzer) unwrap(args []shellWord, record bool) []shellWord {
+	viaInput := false
+	for len(args) > 0 && !args[0].dynamic {
+		name := path.Base(args[0].value)
+		switch name {
+		case "sudo", "doas":
+			if record {
+				a.addProgram(name)
+				a.add(LevelModerate, name, "", "以管理员权限执行", "高权限操作，可修改系统文件")
+			}
+		case "env", "nohup", "time", "nice", "ionice", "stdbuf", "exec", "command", "builtin", "setsid", "timeout", "xargs",
+			"busybox", "toybox":
+			if record {
+				a.addProgram(name)
+			}
+		default:
+			if viaInput {
+				// xargs 会把标准输入追加为参数，目标路径只能在运行时确定。
+				args = append(args, shellWord{value: "<stdin>", dynamic: true})
+			}
+			return args
+		}
+		args = skipOptions(name, args[1:])
+		switch name {
+		case "env":
+			for len(args) > 0 && !args[0].dynamic && strings.Contains(args[0].value, "=") {
+				args = args[1:]
+			}
+		case "timeout":
+			if len(args) > 0 {
+				args = args[1:]
+			}
+		case "xargs":
+			viaInput = true
+		}
+	}
+	return args
+}
+
+func skipOptions(wrapper string, args []shellWord) []shellWord {
+	withValue := wrapperOptionsWithValue[wrapper]
+	for len(args) > 0 && !args[0].dynamic && strings.HasPrefix(args[0].value, "-") {
+		opt := args[0].value
+		args = args[1:]
+		if opt == "--" {
+			break
+		}
+		if slices.Contains(withValue, opt) && len(args) > 0 {
+			args = args[1:]
+		}
+	}
+	return args
+}
+
+func (a *shellAnalyzer) checkRemove(program string, args []shellWord) {
+	recursive := hasFlag(args, "rR", "recursive")
+	desc := "递归删除"
+	if recursive && hasFlag(args, "f", "force") {
+		desc = "强制递归删除"
+	}
+	for _, target := range operands(args) {
+		switch scope := a.classifyPath(target); scope {
+		case scopeReserved:
+			a.addReserved(program, target.value, "删除")
+		case scopeCritical:
+			if recursive {
+				a.addPath(scope, LevelDangerous, program, target.value, "递归删除关键目录", "会导致系统或用户数据被完全破坏")
+			} else {
+				a.addPath(scope, LevelModerate, program, target.value, "删除关键目录", "可能破坏系统结构")
+			}
+		case scopeSystem, scopeDevice:
+			a.addPath(scope, LevelDangerous, program, target.value, "删除系统文件", "可能导致系统无法正常运行")
+		case scopeDynamic:
+			if recursive {
+				a.addPath(scope, LevelDangerous, program, target.value, "递归删除由变量或输入决定的路径", "变量为空或异常时可能删除意外的目录")
+			} else {
+				a.addPath(scope, LevelModerate, program, target.value, "删除由变量或输入决定的文件", "实际删除的文件只能在运行时确定")
+			}
+		case scopeOutside:
+			if recursive {
+				a.addPath(scope, LevelDangerous, program, target.value, "递归删除工作区外的目录", "可能删除工作区外的重要数据")
+			} else {
+				a.addPath(scope, LevelModerate, program, target.value, "删除工作区外的文件", "可能删除工作区外的数据")
+			}
+		case scopeTemp, scopeWorkspace:
+			if recursive {
+				a.addPath(scope, LevelModerate, program, target.value, desc, "可能意外删除文件")
+			}
+		}
+	}
+}
+
+func (a *shellAnalyzer) checkShred(program string, args []shellWord) {
+	for _, target := range operands(args) {
+		if scope := a.classifyPath(target); scope == scopeWorkspace || scope == scopeTemp {
+			a.addPath(scope, LevelModerate, program, target.value, "粉碎文件", "文件内容无法恢复")
+		} else if scope == scopeReserved {
+			a.addReserved(program, target.value, "粉碎")
+		} else {
+			a.addPath(scope, LevelDangerous, program, target.value, "粉碎工作区外的文件", "文件内容无法恢复")
+		}
+	}
+}
+
+// checkWrites 按目标位置评估写入或删除类操作。
+func (a *shellAnalyzer) checkWrites(program string, targets []shellWord, action string) {
+	for _, target := range targets {
+		switch scope := a.classifyPath(target); scope {
+		case scopeReserved:
+			a.addReserved(program, target.value, action)
+		case scopeCritical, scopeSystem:
+			a.addPath(scope, LevelDangerous, program, target.value, action+"系统目录", "可能破坏系统文件")
+		case scopeDevice:
+			a.addPath(scope, LevelDangerous, program, target.value, action+"设备文件", "可能直接覆盖磁盘数据")
+		case scopeOutside:
+			a.addPath(scope, LevelModerate, program, target.value, action+"工作区外的文件", "可能修改工作区外的数据")
+		case scopeDynamic:
+			a.addPath(scope, LevelModerate, program, target.value, action+"由变量决定的路径", "实际目标只能在运行时确定")
+		}
+	}
+}
+
+func (a *shellAnalyzer) checkDD(args []shellWord) {
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg.value, "=")
+		if !ok {
+			continue
+		}
+		target := shellWord{value: value, dynamic: arg.dynamic}
+		scope := a.classifyPath(target)
+		switch key {
+		case "of":
+			switch scope {
+			case scopeReserved:
+				a.addReserved("dd", value, "写入")
+			case scopeDevice:
+				a.addPath(scope, LevelDangerous, "dd", value, "dd 写入设备文件", "可能永久性擦除磁盘数据")
+			case scopeCritical, scopeSystem:
+				a.addPath(scope, LevelDangerous, "dd", value, "dd 写入系统文件", "可能覆盖重要数据")
+			case scopeOutside, scopeDynamic:
+				a.addPath(scope, LevelModerate, "dd", value, "dd 写入工作区外的文件", "可能覆盖重要数据")
+			}
+		case "if":
+			if scope == scopeDevice {
+				a.addPath(scope, LevelModerate, "dd", value, "dd 读取设备文件", "可能读取敏感设备")
+			}
+		}
+	}
+}
+
+func (a *shellAnalyzer) checkPermission(program string, args []shellWord) {
+	recursive := hasFlag(args, "R", "recursive")
+	ops := operands(args)
+	if len(ops) == 0 {
+		return
+	}
+	spec, targets := ops[0], ops[1:]
+	if program == "chmod" && !spec.dynamic && (strings.HasSuffix(spec.value, "777") || strings.Contains(spec.value, "o+w") || spec.value == "a+rwx") {
+		a.add(LevelModerate, program, spec.value, "设置全局可写权限", "安全风险")
+	}
+	for _, target := range targets {
+		switch scope := a.classifyPath(target); scope {
+		case scopeReserved:
+			a.addReserved(program, target.value, "修改权限或所有者")
+		case scopeCritical, scopeSystem, scopeDevice:
+			a.addPath(scope, LevelDangerous, program, target.value, "修改系统文件的权限或所有者", "严重的安全风险")
+		case scopeOutside, scopeDynamic:
+			a.addPath(scope, LevelModerate, program, target.value, "修改工作区外文件的权限或所有者", "可能破坏权限结构")
+		default:
+			if recursive {
+				a.addPath(scope, LevelModerate, program, target.value, "递归修改权限或所有者", "可能影响多个文件")
+			}
+		}
+	}
+}
+
+// checkCopy 检查 cp、install、ln 的写入目标：-t 指定的目录，未指定时为最后一个操作数。
+func (a *shellAnalyzer) checkCopy(program string, args []shellWord) {
+	if target, _, ok := targetDirectory(args); ok {
+		a.checkWrites(program, []shellWord{target}, "写入")
+		return
+	}
+	if targets := operands(args); len(targets) > 1 {
+		a.checkWrites(program, targets[len(targets)-1:], "写入")
+	}
+}
+
+func (a *shellAnalyzer) checkMove(args []shellWord) {
+	target, sources, ok := targetDirectory(args)
+	if !ok {
+		ops := operands(args)
+		if len(ops) < 2 {
+			return
+		}
+		target, sources = ops[len(ops)-1], ops[:len(ops)-1]
+	}
+	for _, source := range sources {
+		switch scope := a.classifyPath(source); scope {
+		case scopeReserved:
+			a.addReserved("mv", source.value, "移出")
+		case scopeCritical:
+			a.addPath(scope, LevelDangerous, "mv", source.value, "移动关键目录", "会破坏系统结构")
+		case scopeSystem, scopeDevice:
+			a.addPath(scope, LevelDangerous, "mv", source.value, "移动系统文件", "可能导致系统无法正常运行")
+		case scopeOutside, scopeDynamic:
+			a.addPath(scope, LevelModerate, "mv", source.value, "移动工作区外的文件", "可能修改工作区外的数据")
+		}
+	}
+	a.checkWrites("mv", []shellWord{target}, "写入")
+}
+
+func (a *shellAnalyzer) checkKill(args []shellWord) {
+	force := false
+	for i, arg := range args {
+		switch {
+		case arg.value == "-9" || arg.value == "-KILL" || arg.value == "-SIGKILL":
+			force = true
+		case arg.value == "-s" && i+1 < len(args) && strings.TrimPrefix(strings.ToUpper(args[i+1].value), "SIG") == "KILL":
+			force = true
+		case arg.value == "-1" && i > 0:
+			a.add(LevelDangerous, "kill", "-1", "杀死所有进程", "会导致系统崩溃")
+			return
+		}
+	}
+	if force {
+		a.add(LevelModerate, "kill", "", "强制终止进程", "可能导致数据丢失")
+	}
+}
+
+func (a *shellAnalyzer) checkFind(args []shellWord, depth int) {
+	var roots []shellWord
+	for _, arg := range args {
+		if !arg.dynamic && (strings.HasPrefix(arg.value, "-") || arg.value == "(" || arg.value == "!") {
+			break
+		}
+		roots = append(roots, arg)
+	}
+	for i := 0; i < len(args); i++ {
+		switch args[i].value {
+		case "-delete":
+			for _, root := range roots {
+				switch scope := a.classifyPath(root); scope {
+				case scopeReserved:
+					a.addReserved("find", root.value, "删除")
+				case scopeCritical, scopeSystem:
+					a.addPath(scope, LevelDangerous, "find", root.value, "find 删除系统目录中的文件", "可能导致系统无法正常运行")
+				case scopeOutside, scopeDynamic:
+					a.addPath(scope, LevelModerate, "find", root.value, "find 删除工作区外的文件", "可能删除工作区外的数据")
+				}
+			}
+			a.add(LevelModerate, "find", "", "find 删除文件", "匹配范围过大时可能删除意外文件")
+		case "-exec", "-execdir", "-ok", "-okdir":
+			var nested []shellWord
+			for i++; i < len(args); i++ {
+				if v := args[i].value; v == ";" || v == "+" {
+					break
+				}
+				if args[i].value == "{}" {
+					nested = append(nested, shellWord{value: "{}", dynamic: true})
+					continue
+				}
+				nested = append(nested, args[i])
+			}
+			if depth < maxNestedDepth {
+				a.checkCall(nested, depth+1)
+			}
+		}
+	}
+}
+
+func (a *shellAnalyzer) checkRedirect(r *syntax.Redirect) {
+	switch r.Op {
+	case syntax.RdrOut, syntax.AppOut, syntax.ClbOut, syntax.RdrAll, syntax.AppAll, syntax.RdrInOut:
+	default:
+		return
+	}
+	if r.Word == nil {
+		return
+	}
+	target := wordOf(r.Word)
+	switch scope := a.classifyPath(target); scope {
+	case scopeReserved:
+		a.addReserved("", target.value, "重定向写入")
+	case scopeDevice:
+		a.addPath(scope, LevelDangerous, "", target.value, "重定向写入设备文件", "可能直接覆盖磁盘数据")
+	case scopeCritical, scopeSystem:
+		a.addPath(scope, LevelDangerous, "", target.value, "重定向到系统目录", "可能破坏系统文件")
+	case scopeOutside:
+		a.addPath(scope, LevelModerate, "", target.value, "重定向写入工作区外的文件", "可能覆盖工作区外的数据")
+	case scopeDynamic:
+		a.addPath(scope, LevelModerate, "", target.value, "重定向到由变量决定的路径", "实际写入的文件只能在运行时确定")
+	}
+}
+
+// checkPipe 识别 curl ... | sh 这类下载后直接执行的管道。
+func (a *shellAnalyzer) checkPipe(n *syntax.BinaryCmd) {
+	right := a.stmtProgram(n.Y)
+	if !slices.Contains(shellInterpreters, right) {
+		return
+	}
+	downloads := false
+	syntax.Walk(n.X, func(node syntax.Node) bool {
+		if call, ok := node.(*syntax.CallExpr); ok {
+			args := a.unwrap(wordsOf(call.Args), false)
+			if len(args) > 0 && !args[0].dynamic {
+				switch path.Base(args[0].value) {
+				case "curl", "wget", "fetch", "nc":
+					downloads = true
+				}
+			}
+		}
+		return !downloads
+	})
+	if downloads {
+		a.add(LevelDangerous, right, "", "下载并直接执行远程脚本", "远程内容未经审查即被执行")
+	}
+}
+
+func (a *shellAnalyzer) stmtProgram(stmt *syntax.Stmt) string {
+	if stmt == nil {
+		return ""
+	}
+	switch cmd := stmt.Cmd.(type) {
+	case *syntax.CallExpr:
+		args := a.unwrap(wordsOf(cmd.Args), false)
+		if len(args) > 0 && !args[0].dynamic {
+			return path.Base(args[0].value)
+		}
+	case *syntax.BinaryCmd:
+		return a.stmtProgram(cmd.X)
+	}
+	return ""
+}
+
+// checkFuncDecl 识别调用自身的函数定义，例如 fork 炸弹 :(){ :|:& };:。
+func (a *shellAnalyzer) checkFuncDecl(fn *syntax.FuncDecl) {
+	if fn.Name == nil || fn.Body == nil {
+		return
+	}
+	recursive := false
+	syntax.Walk(fn.Body, func(node syntax.Node) bool {
+		if call, ok := node.(*syntax.CallExpr); ok && len(call.Args) > 0 && call.Args[0].Lit() == fn.Name.Value {
+			recursive = true
+		}
+		return !recursive
+	})
+	if recursive {
+		a.add(LevelDangerous, fn.Name.Value, "", "fork 炸弹", "会耗尽系统资源")
+	}
+}
+
+// classifyPath 判断路径相对工作区的位置；相对路径以当前工作目录为基准，
+// 工作目录无法确定时视为动态路径。
+func (a *shellAnalyzer) classifyPath(w shellWord) pathScope {
+	if w.dynamic {
+		return scopeDynamic
+	}
+	p := globBase(w.value)
+	switch {
+	case p == "~" || strings.HasPrefix(p, "~/"):
+		if a.homeDir == "" {
+			return scopeDynamic
+		}
+		p = filepath.Join(a.homeDir, strings.TrimPrefix(p, "~"))
+	case strings.HasPrefix(p, "~"):
+		return scopeOutside
+	}
+	if !filepath.IsAbs(p) {
+		if a.dir.dynamic {
+			return scopeDynamic
+		}
+		if a.dir.cwd == "" {
+			return scopeWorkspace
+		}
+		p = filepath.Join(a.dir.cwd, p)
+	}
+	p = filepath.ToSlash(filepath.Clean(p))
+
+	switch {
+	case p == "/" || (a.homeDir != "" && p == filepath.ToSlash(a.homeDir)):
+		return scopeCritical
+	case slices.Contains(harmlessDevices, p) || strings.HasPrefix(p, "/dev/fd/"):
+		return scopeNull
+	case strings.HasPrefix(p, "/dev/"):
+		return scopeDevice
+	case a.workDir != "" && pathguard.InReservedDir(a.workDir, filepath.FromSlash(p)):
+		return scopeReserved
+	case a.workDir != "" && isUnder(p, filepath.ToSlash(a.workDir)):
+		return scopeWorkspace
+	}
+	for _, dir := range tempDirs {
+		if isUnder(p, dir) {
+			return scopeTemp
+		}
+	}
+	for _, dir := range systemDirs {
+		if p == dir {
+			return scopeCritical
+		}
+		if isUnder(p, dir) {
+			return scopeSystem
+		}
+	}
+	return scopeOutside
+}
+
+func (a *shellAnalyzer) add(level SecurityLevel, program, target, description, risk string) {
+	a.addFinding(SecurityFinding{Level: level, Program: program, Target: target, Description: description, Risk: risk})
+}
+
+// addPath 记录针对某个路径的发现，并附带路径相对于工作区的位置。
+func (a *shellAnalyzer) addPath(scope pathScope, level SecurityLevel, program, target, description, risk string) {
+	a.addFinding(SecurityFinding{Level: level, Program: program, Target: target, Scope: scope.String(), Description: description, Risk: risk})
+}
+
+// addReserved 记录对工作区保留目录的修改，这类命令不允许执行，审批也不能放行。
+func (a *shellAnalyzer) addReserved(program, target, action string) {
+	a.addPath(scopeReserved, LevelDangerous, program, target, action+"应用数据目录 "+pathguard.ReservedDir, "该目录对智能体只读")
+}
+
+func (a *shellAnalyzer) addFinding(finding SecurityFinding) {
+	if !slices.Contains(a.findings, finding) {
+		a.findings = append(a.findings, finding)
+	}
+}
+
+func (a *shellAnalyzer) addProgram(name string) {
+	if !slices.Contains(a.programs, name) {
+		a.programs = append(a.programs, name)
+	}
+}
+
+// evaluation 汇总发现：等级和描述取最高风险的第一条，风险说明按等级从高到低去重。
+func (a *shellAnalyzer) evaluation() SecurityEvaluation {
+	eval := SecurityEvaluation{Level: LevelSafe, Description: safeDescription, Programs: a.programs, Findings: a.findings}
+	for _, f := range a.findings {
+		if f.Level > eval.Level {
+			eval.Level = f.Level
+			eval.Description = f.Description
+		}
+	}
+	for level := LevelDangerous; level > LevelSafe; level-- {
+		for _, f := range a.findings {
+			if f.Level == level && f.Risk != "" && !slices.Contains(eval.Risks, f.Risk) {
+				eval.Risks = append(eval.Risks, f.Risk)
+			}
+		}
+	}
+	return eval
+}
+
+func wordsOf(words []*syntax.Word) []shellWord {
+	result := make([]shellWord, 0, len(words))
+	for _, w := range words {
+		result = append(result, wordOf(w))
+	}
+	return result
+}
+
+// wordOf 将语法树中的单词还原为字面值，去掉引号和转义；
+// 含变量、命令替换等运行时内容时保留原文并标记为动态。
+func wordOf(w *syntax.Word) shellWord {
+	var sb strings.Builder
+	dynamic := false
+	for _, part := range w.Parts {
+		switch p := part.(type) {
+		case *syntax.Lit:
+			sb.WriteString(unescape(p.Value, ""))
+		case *syntax.SglQuoted:
+			sb.WriteString(p.Value)
+		case *syntax.DblQuoted:
+			for _, inner := range p.Parts {
+				if lit, ok := inner.(*syntax.Lit); ok {
+					sb.WriteString(unescape(lit.Value, "$`\"\\\n"))
+				} else {
+					dynamic = true
+				}
+			}
+		default:
+			dynamic = true
+		}
+	}
+	if dynamic {
+		var printed strings.Builder
+		if err := syntax.NewPrinter().Print(&printed, w); err == nil {
+			return shellWord{value: printed.String(), dynamic: true}
+		}
+		return shellWord{value: sb.String(), dynamic: true}
+	}
+	return shellWord{value: sb.String()}
+}
+
+// unescape 去掉反斜杠转义；escapable 为空表示任意字符都可被转义。
+func unescape(value, escapable string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) && (escapable == "" || strings.IndexByte(escapable, value[i+1]) >= 0) {
+			i++
+		}
+		sb.WriteByte(value[i])
+	}
+	return sb.String()
+}
+
+func joinWords(words []shellWord) shellWord {
+	values := make([]string, 0, len(words))
+	dynamic := false
+	for _, w := range words {
+		values = append(values, w.value)
+		dynamic = dynamic || w.dynamic
+	}
+	return shellWord{value: strings.Join(values, " "), dynamic: dynamic}
+}
+
+// hasFlag 判断参数中是否包含短选项（可合并，如 -rf）或长选项。
+func hasFlag(args []shellWord, shorts string, long string) bool {
+	for _, arg := range args {
+		if arg.dynamic {
+			continue
+		}
+		v := arg.value
+		switch {
+		case v == "--":
+			return false
+		case strings.HasPrefix(v, "--"):
+			if v[2:] == long {
+				return true
+			}
+		case strings.HasPrefix(v, "-") && len(v) > 1:
+			if strings.ContainsAny(v[1:], shorts) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// targetDirectory 解析 cp、mv、install、ln 的 -t DIR、-tDIR 和 --target-directory=DIR，
+// 返回目标目录和其余操作数；未指定目标目录时 ok 为 false。
+func targetDirectory(args []shellWord) (target shellWord, rest []shellWord, ok bool) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		v := arg.value
+		switch {
+		case !arg.dynamic && v == "--":
+			return target, append(rest, args[i+1:]...), ok
+		case strings.HasPrefix(v, "--"):
+			name, value, hasValue := strings.Cut(v, "=")
+			// 长选项可以缩写为任意无歧义的前缀
+			if len(name) < len("--target") || !strings.HasPrefix("--target-directory", name) {
+				continue
+			}
+			if hasValue {
+				target, ok = shellWord{value: value, dynamic: arg.dynamic}, true
+			} else if i+1 < len(args) {
+				i++
+				target, ok = args[i], true
+			}
+		case strings.HasPrefix(v, "-") && v != "-":
+			// 短选项可以合并书写，t 之后的部分或下一个参数是目标目录
+			idx := strings.IndexByte(v, 't')
+			if idx < 0 {
+				continue
+			}
+			if value := v[idx+1:]; value != "" {
+				target, ok = shellWord{value: value, dynamic: arg.dynamic}, true
+			} else if i+1 < len(args) {
+				i++
+				target, ok = args[i], true
+			}
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return target, rest, ok
+}
+
+// operands 返回非选项参数，-- 之后的参数都视为操作数。
+func operands(args []shellWord) []shellWord {
+	var result []shellWord
+	for i, arg := range args {
+		if !arg.dynamic && arg.value == "--" {
+			return append(result, args[i+1:]...)
+		}
+		if !arg.dynamic && strings.HasPrefix(arg.value, "-") && arg.value != "-" {
+			continue
+		}
+		result = append(result, arg)
+	}
+	return result
+}
+
+func containsWords(args []shellWord, values ...string) bool {
+	for _, arg := range args {
+		if !arg.dynamic && slices.Contains(values, arg.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// globBase 去掉路径中从第一个通配符开始的部分，/* 视为根目录本身。
+func globBase(p string) string {
+	idx := strings.IndexAny(p, "*?[")
+	if idx < 0 {
+		return p
+	}
+	dir := p[:idx]
+	if slash := strings.LastIndex(dir, "/"); slash >= 0 {
+		if slash == 0 {
+			return "/"
+		}
+		return dir[:slash]
+	}
+	return "."
+}
+
+func isUnder(p, dir string) bool {
+	return p == dir || strings.HasPrefix(p, strings.TrimSuffix(dir, "/")+"/")
+}
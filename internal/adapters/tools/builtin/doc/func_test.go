@@ -26,6 +26,21 @@ func TestGetDocumentInfoForTextFile(t *testing.T) {
 	}
 }
 
+func TestConfineResolvesPathsInBaseDir(t *testing.T) {
+	path := writeDocTestFile(t, "note.txt", "hello\n")
+	base := filepath.Dir(path)
+	info := confine(base, func(r *GetDocumentInfoRequest) *string { return &r.FilePath }, GetDocumentInfo)
+
+	resp, err := info(context.Background(), &GetDocumentInfoRequest{FilePath: "note.txt"})
+	if err != nil || resp.ErrorMessage != "" || resp.FilePath != path {
+		t.Fatalf("confined relative path = %#v, %v", resp, err)
+	}
+	outside := writeDocTestFile(t, "secret.txt", "secret\n")
+	if _, err := info(context.Background(), &GetDocumentInfoRequest{FilePath: outside}); err == nil {
+		t.Fatal("confined doc tool read a file outside its base dir")
+	}
+}
+
 func TestDocumentReadFunctionsReportMissingFile(t *testing.T) {
 	missing := filepath.Join(t.TempDir(), "missing.txt")
 
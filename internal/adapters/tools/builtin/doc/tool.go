@@ -1,16 +1,53 @@
 package doc
 
 import (
+	"context"
+	"fmt"
+
 	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/pathguard"
 )
 
-func GetTools() (tools []runtimeport.Tool, err error) {
+// Option 配置文档工具
+type Option func(*options)
+
+type options struct {
+	baseDir string
+}
+
+// WithBaseDir 将文档路径限定在 baseDir 内，相对路径按 baseDir 解析
+func WithBaseDir(baseDir string) Option {
+	return func(o *options) { o.baseDir = baseDir }
+}
+
+// confine 在调用前把请求中的文件路径解析到 baseDir 内，baseDir 为空时原样调用
+func confine[Req, Resp any](baseDir string, path func(*Req) *string, fn func(context.Context, *Req) (*Resp, error)) func(context.Context, *Req) (*Resp, error) {
+	if baseDir == "" {
+		return fn
+	}
+	return func(ctx context.Context, req *Req) (*Resp, error) {
+		p := path(req)
+		resolved, err := pathguard.ResolveWorkspace(baseDir, *p)
+		if err != nil {
+			return nil, fmt.Errorf("访问被拒绝: 路径 %s 不在工作目录 %s 内", *p, baseDir)
+		}
+		*p = resolved.AbsPath
+		return fn(ctx, req)
+	}
+}
+
+func GetTools(opts ...Option) (tools []runtimeport.Tool, err error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	// 1. 获取文档信息工具
 	getInfoTool, err := runtimeport.InferTool(
 		"get_document_info",
 		`获取文档的基本信息，包括文件类型、大小、页数、元数据等。支持格式：.docx, .pdf, .xlsx, .pptx, .txt, .csv, .md, .rtf。
 这是读取文档前的第一步，帮助你了解文档结构，决定如何读取。`,
-		GetDocumentInfo,
+		confine(o.baseDir, func(r *GetDocumentInfoRequest) *string { return &r.FilePath }, GetDocumentInfo),
 	)
 	if err != nil {
 		return nil, err
@@ -26,7 +63,7 @@ func GetTools() (tools []runtimeport.Tool, err error) {
 - 自动清理多余空格和空行
 - 文档过大时会提供建议
 适用于：首次阅读文档，快速了解内容概况`,
-		ReadDocumentSmart,
+		confine(o.baseDir, func(r *ReadDocumentSmartRequest) *string { return &r.FilePath }, ReadDocumentSmart),
 	)
 	if err != nil {
 		return nil, err
@@ -42,7 +79,7 @@ func GetTools() (tools []runtimeport.Tool, err error) {
 - end_page: 结束页（-1表示到末尾）
 返回每页的详细信息（页码、行数）。
 适用于：需要读取特定页面或章节`,
-		ReadDocumentByPages,
+		confine(o.baseDir, func(r *ReadDocumentByPagesRequest) *string { return &r.FilePath }, ReadDocumentByPages),
 	)
 	if err != nil {
 		return nil, err
@@ -58,7 +95,7 @@ func GetTools() (tools []runtimeport.Tool, err error) {
 - end_line: 结束行（-1表示到末尾）
 - page_index: 页面索引（-1表示第一页）
 适用于：需要读取特定行或段落`,
-		ReadDocumentByLines,
+		confine(o.baseDir, func(r *ReadDocumentByLinesRequest) *string { return &r.FilePath }, ReadDocumentByLines),
 	)
 	if err != nil {
 		return nil, err
@@ -26,11 +26,21 @@ type FileTools struct {
 	osFs afero.Fs
 	// allowedBaseDir 是允许访问的基础目录
 	allowedBaseDir string
+	// confined 为 true 时工作目录外的路径直接拒绝，不能通过审批访问
+	confined bool
+}
+
+// Option 配置文件工具
+type Option func(*FileTools)
+
+// WithConfined 禁止访问工作目录外的路径，用于限定在成员文件区中的会话
+func WithConfined() Option {
+	return func(ft *FileTools) { ft.confined = true }
 }
 
 // NewFileTools 创建一个新的文件工具实例
 // baseDir 是允许操作的基础目录（默认 ~/.fkteams/workspace）
-func NewFileTools(baseDir string) (*FileTools, error) {
+func NewFileTools(baseDir string, opts ...Option) (*FileTools, error) {
 	absPath, err := filepath.Abs(baseDir)
 	if err != nil {
 		return nil, fmt.Errorf("无法获取绝对路径: %w", err)
@@ -45,11 +55,15 @@ func NewFileTools(baseDir string) (*FileTools, error) {
 
 	securedFs := afero.NewBasePathFs(afero.NewOsFs(), absPath)
 
-	return &FileTools{
+	ft := &FileTools{
 		securedFs:      securedFs,
 		osFs:           afero.NewOsFs(),
 		allowedBaseDir: absPath,
-	}, nil
+	}
+	for _, opt := range opts {
+		opt(ft)
+	}
+	return ft, nil
 }
 
 // resolvedPath 路径解析结果
@@ -74,6 +88,9 @@ func (ft *FileTools) resolvePath(ctx context.Context, userPath string) (*resolve
 
 	// 2. 仅支持绝对路径访问外部文件
 	cleanUserPath := cleanPath(userPath)
+	if ft.confined {
+		return nil, fmt.Errorf("访问被拒绝: 路径 %s 不在工作目录 %s 内，当前账号不能访问工作目录外的文件", userPath, ft.allowedBaseDir)
+	}
 	if !filepath.IsAbs(cleanUserPath) {
 		return nil, fmt.Errorf("路径 %s 不在工作目录 %s 内，如需访问外部文件请使用绝对路径", userPath, ft.allowedBaseDir)
 	}
@@ -188,6 +205,26 @@ func countLines(content string) int {
 	return strings.Count(text, "\n") + 1
 }
 
+// resolveWritePath 解析写操作的目标路径，工作区保留目录 .fkteams 中的路径一律拒绝，不进入审批。
+func (ft *FileTools) resolveWritePath(ctx context.Context, userPath string) (*resolvedPath, error) {
+	if err := ft.checkWritable(userPath); err != nil {
+		return nil, err
+	}
+	return ft.resolvePath(ctx, userPath)
+}
+
+// checkWritable 拒绝写入工作区保留目录，其中保存成员文件区等应用数据，智能体只能读取
+func (ft *FileTools) checkWritable(userPath string) error {
+	target := cleanPath(userPath)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(ft.allowedBaseDir, target)
+	}
+	if pathguard.InReservedDir(ft.allowedBaseDir, target) {
+		return fmt.Errorf("访问被拒绝: %s 位于只读目录 %s 内", userPath, pathguard.ReservedDir)
+	}
+	return nil
+}
+
 // workspacePath 验证路径在工作目录内，返回相对路径
 func (ft *FileTools) workspacePath(userPath string) (string, error) {
 	if userPath == "" {
@@ -347,7 +384,7 @@ func (ft *FileTools) FileWrite(ctx context.Context, req *FileWriteRequest) (*Fil
 		}, nil
 	}
 
-	rp, err := ft.resolvePath(ctx, req.Filepath)
+	rp, err := ft.resolveWritePath(ctx, req.Filepath)
 	if err != nil {
 		return nil, err
 	}
@@ -396,7 +433,7 @@ func (ft *FileTools) FileAppend(ctx context.Context, req *FileAppendRequest) (*F
 		return &FileAppendResponse{ErrorMessage: "filepath 参数是必需的"}, nil
 	}
 
-	rp, err := ft.resolvePath(ctx, req.Filepath)
+	rp, err := ft.resolveWritePath(ctx, req.Filepath)
 	if err != nil {
 		return nil, err
 	}
@@ -787,7 +824,7 @@ func (ft *FileTools) FileEdit(ctx context.Context, req *FileEditRequest) (*FileE
 		return &FileEditResponse{ErrorMessage: "old_string is required"}, nil
 	}
 
-	rp, err := ft.resolvePath(ctx, req.Filepath)
+	rp, err := ft.resolveWritePath(ctx, req.Filepath)
 	if err != nil {
 		return nil, err
 	}
@@ -924,6 +961,9 @@ func (a *fsAccessor) ReadFile(path string) (string, error) {
 }
 
 func (a *fsAccessor) WriteFile(path string, content string) error {
+	if err := a.ft.checkWritable(path); err != nil {
+		return err
+	}
 	relPath, err := a.ft.workspacePath(path)
 	if err != nil {
 		return err
@@ -939,6 +979,9 @@ func (a *fsAccessor) WriteFile(path string, content string) error {
 }
 
 func (a *fsAccessor) DeleteFile(path string) error {
+	if err := a.ft.checkWritable(path); err != nil {
+		return err
+	}
 	relPath, err := a.ft.workspacePath(path)
 	if err != nil {
 		return err
@@ -1,8 +1,10 @@
 package file
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -56,3 +58,61 @@ func TestWorkspacePathRejectsNewFileUnderOutsideSymlinkDir(t *testing.T) {
 		t.Fatal("expected new file below outside symlink dir to be rejected")
 	}
 }
+
+func TestWriteToolsRejectReservedDir(t *testing.T) {
+	base := t.TempDir()
+	policy := filepath.Join(base, ".fkteams", "policy.toml")
+	if err := os.MkdirAll(filepath.Dir(policy), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(policy, []byte("# original\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ft, err := NewFileTools(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if _, err := ft.FileWrite(ctx, &FileWriteRequest{Filepath: ".fkteams/policy.toml", Content: "allow"}); err == nil {
+		t.Fatal("file_write into .fkteams was allowed")
+	}
+	if _, err := ft.FileAppend(ctx, &FileAppendRequest{Filepath: policy, Content: "allow"}); err == nil {
+		t.Fatal("file_append into .fkteams was allowed")
+	}
+	patch := "--- .fkteams/policy.toml\n+++ .fkteams/policy.toml\n@@ -1 +1 @@\n-# original\n+# patched\n"
+	if resp, _ := ft.FilePatch(ctx, &FilePatchRequest{Patch: patch}); resp.Failed != 1 {
+		t.Fatalf("file_patch into .fkteams = %#v", resp)
+	}
+	if data, _ := os.ReadFile(policy); string(data) != "# original\n" {
+		t.Fatalf("reserved file was modified: %q", data)
+	}
+	if resp, err := ft.FileRead(ctx, &FileReadRequest{Filepath: ".fkteams/policy.toml"}); err != nil || resp.ErrorMessage != "" {
+		t.Fatalf("reading .fkteams failed: %v %#v", err, resp)
+	}
+}
+
+func TestConfinedToolsRejectOutsidePaths(t *testing.T) {
+	base := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(outside, []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(base, "note.txt"), []byte("note"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ft, err := NewFileTools(base, WithConfined())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, p := range []string{outside, "../secret.txt"} {
+		if _, err := ft.FileRead(ctx, &FileReadRequest{Filepath: p}); err == nil || !strings.Contains(err.Error(), "不能访问工作目录外") {
+			t.Fatalf("confined file_read %q error = %v", p, err)
+		}
+	}
+	if resp, err := ft.FileRead(ctx, &FileReadRequest{Filepath: "note.txt"}); err != nil || resp.ErrorMessage != "" {
+		t.Fatalf("confined file_read in workspace failed: %v %#v", err, resp)
+	}
+}
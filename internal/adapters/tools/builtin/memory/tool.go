@@ -0,0 +1,321 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"fkteams/internal/app/appstate"
+	appmemory "fkteams/internal/app/memory"
+	domainmemory "fkteams/internal/domain/memory"
+	"fkteams/internal/domain/session"
+	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/approval"
+)
+
+const (
+	defaultSearchTopK = 10
+	maxSearchTopK     = 50
+)
+
+// ManagerProvider 从工具执行上下文提供记忆管理器和当前对话的作用域。
+type ManagerProvider func(context.Context) (appstate.MemoryManager, domainmemory.ScopeContext)
+
+// Tools 是长期记忆工具适配器。
+type Tools struct {
+	manager ManagerProvider
+}
+
+// NewTools 创建长期记忆工具适配器。
+func NewTools(provider ManagerProvider) *Tools {
+	if provider == nil {
+		provider = appstate.MemoryFromContext
+	}
+	return &Tools{manager: provider}
+}
+
+func (t *Tools) managerOrError(ctx context.Context) (appstate.MemoryManager, domainmemory.ScopeContext, error) {
+	if t == nil || t.manager == nil {
+		return nil, domainmemory.ScopeContext{}, fmt.Errorf("long-term memory is not enabled")
+	}
+	manager, scope := t.manager(ctx)
+	if manager == nil {
+		return nil, domainmemory.ScopeContext{}, fmt.Errorf("long-term memory is not enabled")
+	}
+	return manager, scope, nil
+}
+
+// GetTools 获取长期记忆工具集合。
+func (t *Tools) GetTools() ([]runtimeport.Tool, error) {
+	var tools []runtimeport.Tool
+
+	memorySaveTool, err := runtimeport.InferTool("memory_save",
+		"主动保存一条长期记忆，用于记录用户明确要求记住的内容、已确定的方案、踩过的坑或可复用的操作经验。"+
+			"与已有的同类型、同作用域相近记忆会自动合并，不会产生重复条目。只保存以后对话仍然有用的信息，不要保存一次性的任务细节。",
+		t.MemorySave)
+	if err != nil {
+		return nil, err
+	}
+	tools = append(tools, memorySaveTool)
+
+	memorySearchTool, err := runtimeport.InferTool("memory_search",
+		"检索当前对话可见的长期记忆，可按类型和标签过滤。query 为空时按创建时间倒序列出命中过滤条件的记忆。"+
+			"在做决定前查阅以前确定的方案和避坑记录，或在修改、删除记忆前获取记忆 ID。",
+		t.MemorySearch)
+	if err != nil {
+		return nil, err
+	}
+	tools = append(tools, memorySearchTool)
+
+	memoryUpdateTool, err := runtimeport.InferTool("memory_update",
+		"修改一条长期记忆的类型、摘要、详情或标签，未填写的字段保持不变。记忆 ID 来自 memory_search 或 memory_save 的结果。",
+		t.MemoryUpdate)
+	if err != nil {
+		return nil, err
+	}
+	tools = append(tools, memoryUpdateTool)
+
+	memoryForgetTool, err := runtimeport.InferTool("memory_forget",
+		"删除一条过时或错误的长期记忆，需要用户审批。记忆 ID 来自 memory_search 的结果。",
+		t.MemoryForget)
+	if err != nil {
+		return nil, err
+	}
+	tools = append(tools, memoryForgetTool)
+
+	return tools, nil
+}
+
+// MemoryItem 是返回给智能体的记忆条目。
+type MemoryItem struct {
+	ID        string   `json:"id"`
+	Type      string   `json:"type"`
+	Scope     string   `json:"scope"`
+	Summary   string   `json:"summary"`
+	Detail    string   `json:"detail,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// MemorySaveRequest 保存记忆请求。
+type MemorySaveRequest struct {
+	Type    string   `json:"type" jsonschema:"description=记忆类型：preference（用户偏好）、fact（个人信息）、feedback（行为反馈）、lesson（避坑记录）、decision（已确定方案）、insight（认知洞察）或 experience（操作经验）"`
+	Summary string   `json:"summary" jsonschema:"description=一句话摘要，简洁明确，最多 200 字"`
+	Detail  string   `json:"detail,omitempty" jsonschema:"description=补充细节，如原因、适用条件和具体做法"`
+	Tags    []string `json:"tags,omitempty" jsonschema:"description=便于检索的关键词标签，最多 10 个"`
+	Scope   string   `json:"scope,omitempty" jsonschema:"description=作用域：global（所有对话）、workspace（当前项目）、agent（当前智能体）或 user（当前通道用户）。留空时个人偏好和背景归属当前用户，其余为全局"`
+}
+
+// MemorySaveResponse 保存记忆响应。
+type MemorySaveResponse struct {
+	Success      bool        `json:"success"`
+	Message      string      `json:"message"`
+	ErrorMessage string      `json:"error_message,omitempty"`
+	Memory       *MemoryItem `json:"memory,omitempty"`
+}
+
+// MemorySearchRequest 检索记忆请求。
+type MemorySearchRequest struct {
+	Query string   `json:"query,omitempty" jsonschema:"description=检索内容，使用 BM25 关键词匹配；留空时只按类型和标签过滤"`
+	Types []string `json:"types,omitempty" jsonschema:"description=只返回这些类型的记忆，留空不限制"`
+	Tags  []string `json:"tags,omitempty" jsonschema:"description=只返回带有任一标签的记忆（不区分大小写），留空不限制"`
+	TopK  int      `json:"top_k,omitempty" jsonschema:"description=最多返回的条目数，默认 10，最多 50"`
+}
+
+// MemorySearchResponse 检索记忆响应。
+type MemorySearchResponse struct {
+	Success      bool         `json:"success"`
+	TotalCount   int          `json:"total_count"`
+	ErrorMessage string       `json:"error_message,omitempty"`
+	Memories     []MemoryItem `json:"memories,omitempty"`
+}
+
+// MemoryUpdateRequest 修改记忆请求。
+type MemoryUpdateRequest struct {
+	ID      string   `json:"id" jsonschema:"description=要修改的记忆 ID"`
+	Type    string   `json:"type,omitempty" jsonschema:"description=新的记忆类型，留空不修改"`
+	Summary string   `json:"summary,omitempty" jsonschema:"description=新的摘要，留空不修改"`
+	Detail  *string  `json:"detail,omitempty" jsonschema:"description=新的详情，不填不修改，填空字符串清空详情"`
+	Tags    []string `json:"tags,omitempty" jsonschema:"description=新的标签，会整体替换原有标签，不填不修改"`
+}
+
+// MemoryUpdateResponse 修改记忆响应。
+type MemoryUpdateResponse struct {
+	Success      bool        `json:"success"`
+	Message      string      `json:"message"`
+	ErrorMessage string      `json:"error_message,omitempty"`
+	Memory       *MemoryItem `json:"memory,omitempty"`
+}
+
+// MemoryForgetRequest 删除记忆请求。
+type MemoryForgetRequest struct {
+	ID     string `json:"id" jsonschema:"description=要删除的记忆 ID"`
+	Reason string `json:"reason,omitempty" jsonschema:"description=删除原因，展示给用户审批"`
+}
+
+// MemoryForgetResponse 删除记忆响应。
+type MemoryForgetResponse struct {
+	Success      bool   `json:"success"`
+	Message      string `json:"message"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// MemorySave 保存一条长期记忆。
+func (t *Tools) MemorySave(ctx context.Context, req *MemorySaveRequest) (*MemorySaveResponse, error) {
+	manager, scope, err := t.managerOrError(ctx)
+	if err != nil {
+		return &MemorySaveResponse{ErrorMessage: err.Error()}, nil
+	}
+	memType := domainmemory.MemoryType(strings.TrimSpace(req.Type))
+	entryScope, err := saveScope(domainmemory.ScopeKind(strings.TrimSpace(req.Scope)), memType, scope)
+	if err != nil {
+		return &MemorySaveResponse{ErrorMessage: err.Error()}, nil
+	}
+	sessionID, _ := session.IDFromContext(ctx)
+	entry, action, err := manager.Save(domainmemory.MemoryEntry{
+		Type:      memType,
+		Scope:     entryScope,
+		Summary:   req.Summary,
+		Detail:    req.Detail,
+		Tags:      req.Tags,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return &MemorySaveResponse{ErrorMessage: err.Error()}, nil
+	}
+	item := toItem(entry)
+	switch action {
+	case domainmemory.SaveUpdated:
+		return &MemorySaveResponse{Success: true, Message: "merged into a similar memory", Memory: &item}, nil
+	case domainmemory.SaveSkipped:
+		return &MemorySaveResponse{Success: true, Message: "an identical memory already exists", Memory: &item}, nil
+	default:
+		return &MemorySaveResponse{Success: true, Message: "memory saved", Memory: &item}, nil
+	}
+}
+
+// saveScope 解析保存时指定的作用域：留空时按记忆类型推断，明确指定但当前对话不具备的作用域视为错误。
+func saveScope(kind domainmemory.ScopeKind, memType domainmemory.MemoryType, scope domainmemory.ScopeContext) (domainmemory.Scope, error) {
+	if kind != "" && !domainmemory.AllScopeKinds[kind] {
+		return domainmemory.Scope{}, fmt.Errorf("invalid memory scope %q", kind)
+	}
+	switch kind {
+	case "":
+		return appmemory.ResolveScope(kind, memType, scope), nil
+	case domainmemory.ScopeGlobal:
+		return domainmemory.GlobalScope(), nil
+	}
+	resolved, ok := scope.ScopeFor(kind)
+	if !ok {
+		return domainmemory.Scope{}, fmt.Errorf("memory scope %s is not available in this conversation", kind)
+	}
+	return resolved, nil
+}
+
+// MemorySearch 检索当前对话可见的长期记忆。
+func (t *Tools) MemorySearch(ctx context.Context, req *MemorySearchRequest) (*MemorySearchResponse, error) {
+	manager, scope, err := t.managerOrError(ctx)
+	if err != nil {
+		return &MemorySearchResponse{ErrorMessage: err.Error()}, nil
+	}
+	filter := domainmemory.SearchFilter{Tags: req.Tags}
+	for _, value := range req.Types {
+		memType := domainmemory.MemoryType(strings.TrimSpace(value))
+		if !domainmemory.AllMemoryTypes[memType] {
+			return &MemorySearchResponse{ErrorMessage: fmt.Sprintf("invalid memory type %q", value)}, nil
+		}
+		filter.Types = append(filter.Types, memType)
+	}
+	topK := req.TopK
+	if topK <= 0 {
+		topK = defaultSearchTopK
+	}
+	topK = min(topK, maxSearchTopK)
+
+	entries := manager.Find(req.Query, topK, scope, filter)
+	items := make([]MemoryItem, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, toItem(entry))
+	}
+	return &MemorySearchResponse{Success: true, TotalCount: len(items), Memories: items}, nil
+}
+
+// MemoryUpdate 修改当前对话可见的一条长期记忆。
+func (t *Tools) MemoryUpdate(ctx context.Context, req *MemoryUpdateRequest) (*MemoryUpdateResponse, error) {
+	manager, scope, err := t.managerOrError(ctx)
+	if err != nil {
+		return &MemoryUpdateResponse{ErrorMessage: err.Error()}, nil
+	}
+	if _, ok := manager.Get(req.ID, scope); !ok {
+		return &MemoryUpdateResponse{ErrorMessage: fmt.Sprintf("memory %q not found", req.ID)}, nil
+	}
+	entry, err := manager.Update(req.ID, domainmemory.EntryPatch{
+		Type:    domainmemory.MemoryType(strings.TrimSpace(req.Type)),
+		Summary: req.Summary,
+		Detail:  req.Detail,
+		Tags:    req.Tags,
+	})
+	if err != nil {
+		return &MemoryUpdateResponse{ErrorMessage: err.Error()}, nil
+	}
+	item := toItem(entry)
+	message := "memory updated"
+	if entry.ID != req.ID {
+		message = "memory updated and merged into a similar memory"
+	}
+	return &MemoryUpdateResponse{Success: true, Message: message, Memory: &item}, nil
+}
+
+// MemoryForget 经用户审批后删除当前对话可见的一条长期记忆。
+func (t *Tools) MemoryForget(ctx context.Context, req *MemoryForgetRequest) (*MemoryForgetResponse, error) {
+	manager, scope, err := t.managerOrError(ctx)
+	if err != nil {
+		return &MemoryForgetResponse{ErrorMessage: err.Error()}, nil
+	}
+	entry, ok := manager.Get(req.ID, scope)
+	if !ok {
+		return &MemoryForgetResponse{ErrorMessage: fmt.Sprintf("memory %q not found", req.ID)}, nil
+	}
+	if err := requireForgetApproval(ctx, entry, req.Reason); err != nil {
+		if message, rejected := approval.RejectedMessage(err, "memory deletion rejected by user"); rejected {
+			return &MemoryForgetResponse{ErrorMessage: message}, nil
+		}
+		return nil, err
+	}
+	if _, err := manager.Forget(entry.ID); err != nil {
+		return &MemoryForgetResponse{ErrorMessage: err.Error()}, nil
+	}
+	return &MemoryForgetResponse{Success: true, Message: "memory forgotten"}, nil
+}
+
+func requireForgetApproval(ctx context.Context, entry domainmemory.MemoryEntry, reason string) error {
+	details := []approval.OperationDetail{
+		{Name: "Type", Value: string(entry.Type)},
+		{Name: "Scope", Value: entry.Scope.String()},
+	}
+	if entry.Detail != "" {
+		details = append(details, approval.OperationDetail{Name: "Detail", Value: entry.Detail})
+	}
+	if reason = strings.TrimSpace(reason); reason != "" {
+		details = append(details, approval.OperationDetail{Name: "Reason", Value: reason})
+	}
+	return approval.RequireOperation(ctx, approval.Operation{
+		StoreName: approval.StoreMemory,
+		Key:       entry.Scope.String() + "/" + entry.Summary,
+		Title:     "Forgetting a memory requires approval",
+		Target:    entry.Summary,
+		Details:   details,
+	})
+}
+
+func toItem(entry domainmemory.MemoryEntry) MemoryItem {
+	return MemoryItem{
+		ID:        entry.ID,
+		Type:      string(entry.Type),
+		Scope:     entry.Scope.String(),
+		Summary:   entry.Summary,
+		Detail:    entry.Detail,
+		Tags:      entry.Tags,
+		CreatedAt: entry.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
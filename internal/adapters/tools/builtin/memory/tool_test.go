@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"fkteams/internal/app/appstate"
+	appmemory "fkteams/internal/app/memory"
+	domainmemory "fkteams/internal/domain/memory"
+	"fkteams/internal/runtime/approval"
+)
+
+func TestToolsSaveSearchUpdateAndForget(t *testing.T) {
+	manager := appmemory.NewManager(t.TempDir(), nil, nil)
+	scope := domainmemory.ScopeContext{Workspace: "fkteams", Agent: "coordinator"}
+	ctx := appstate.WithMemory(context.Background(), manager, scope)
+	ctx = approval.WithRegistry(ctx, approval.NewAutoApproveRegistry())
+	tools := NewTools(nil)
+
+	saveResp, err := tools.MemorySave(ctx, &MemorySaveRequest{
+		Type:    "decision",
+		Summary: "数据库迁移统一使用 goose",
+		Detail:  "迁移脚本放在 migrations 目录",
+		Tags:    []string{"db"},
+		Scope:   "workspace",
+	})
+	if err != nil || !saveResp.Success || saveResp.Memory == nil {
+		t.Fatalf("MemorySave = %#v, %v", saveResp, err)
+	}
+	if saveResp.Memory.Scope != "workspace:fkteams" {
+		t.Fatalf("saved scope = %q, want workspace:fkteams", saveResp.Memory.Scope)
+	}
+	if resp, _ := tools.MemorySave(ctx, &MemorySaveRequest{Type: "decision", Summary: "x", Scope: "user"}); resp.Success || !strings.Contains(resp.ErrorMessage, "not available") {
+		t.Fatalf("MemorySave without user scope = %#v, want error", resp)
+	}
+
+	searchResp, err := tools.MemorySearch(ctx, &MemorySearchRequest{Query: "数据库迁移", Types: []string{"decision"}, Tags: []string{"DB"}})
+	if err != nil || !searchResp.Success || searchResp.TotalCount != 1 {
+		t.Fatalf("MemorySearch = %#v, %v", searchResp, err)
+	}
+	id := searchResp.Memories[0].ID
+	if resp, _ := tools.MemorySearch(ctx, &MemorySearchRequest{Types: []string{"bogus"}}); resp.Success {
+		t.Fatalf("MemorySearch with invalid type = %#v, want error", resp)
+	}
+	otherCtx := appstate.WithMemory(context.Background(), manager, domainmemory.ScopeContext{Workspace: "other"})
+	if resp, _ := tools.MemorySearch(otherCtx, &MemorySearchRequest{}); resp.TotalCount != 0 {
+		t.Fatalf("MemorySearch from other workspace = %#v, want no entries", resp)
+	}
+
+	updateResp, err := tools.MemoryUpdate(ctx, &MemoryUpdateRequest{ID: id, Tags: []string{"db", "migration"}})
+	if err != nil || !updateResp.Success || len(updateResp.Memory.Tags) != 2 {
+		t.Fatalf("MemoryUpdate = %#v, %v", updateResp, err)
+	}
+	if resp, _ := tools.MemoryUpdate(otherCtx, &MemoryUpdateRequest{ID: id, Summary: "x"}); resp.Success {
+		t.Fatalf("MemoryUpdate from other workspace = %#v, want not found", resp)
+	}
+
+	forgetResp, err := tools.MemoryForget(ctx, &MemoryForgetRequest{ID: id, Reason: "方案已废弃"})
+	if err != nil || !forgetResp.Success || manager.Count() != 0 {
+		t.Fatalf("MemoryForget = %#v, %v, count = %d", forgetResp, err, manager.Count())
+	}
+}
+
+func TestMemoryForgetRequiresApproval(t *testing.T) {
+	manager := appmemory.NewManager(t.TempDir(), nil, nil)
+	entry, _, err := manager.Save(domainmemory.MemoryEntry{Type: domainmemory.Lesson, Summary: "发布前先跑集成测试"})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	ctx := appstate.WithMemory(context.Background(), manager, domainmemory.ScopeContext{})
+	if _, err := NewTools(nil).MemoryForget(ctx, &MemoryForgetRequest{ID: entry.ID}); err == nil {
+		t.Fatal("MemoryForget without approval registry should interrupt")
+	}
+	if manager.Count() != 1 {
+		t.Fatalf("count = %d, memory should be kept until approved", manager.Count())
+	}
+}
+
+func TestToolsReportDisabledMemory(t *testing.T) {
+	resp, err := NewTools(nil).MemorySearch(context.Background(), &MemorySearchRequest{Query: "x"})
+	if err != nil || resp.Success || resp.ErrorMessage == "" {
+		t.Fatalf("MemorySearch without manager = %#v, %v", resp, err)
+	}
+}
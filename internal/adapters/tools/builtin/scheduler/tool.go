@@ -44,9 +44,15 @@ func (t *Tools) GetTools() ([]runtimeport.Tool, error) {
 	var tools []runtimeport.Tool
 
 	scheduleAddTool, err := runtimeport.InferTool("schedule_add",
-		"创建定时任务，任务将由后台任务官（Tasker）独立执行，你不需要也无法参与执行。"+
+		"创建定时任务，任务将在后台独立执行，你不需要也无法参与执行。默认由后台任务官（Tasker）使用默认模型执行，"+
+			"用户要求时可通过 agent 或 mode 指定执行的智能体或模式，并可指定模型、工作目录、工具白名单、最大迭代次数和超时。"+
 			"支持两种模式：1) cron 表达式（重复任务），如 '*/5 * * * *' 每5分钟、'0 9 * * *' 每天9点；2) execute_at 指定时间（一次性任务）。"+
-			"cron 表达式为标准5字段格式：分 时 日 月 周。创建成功后告知用户任务已交由后台调度器管理即可，不要承诺你会去执行。",
+			"cron 表达式为标准5字段格式：分 时 日 月 周。用户要求在事件发生时执行时，改为设置 trigger_type 创建事件触发任务，不填 cron_expr 和 execute_at："+
+			"file 在工作目录内匹配 trigger_glob 的文件变化后触发，webhook 在外部系统调用 /api/fkteams/triggers/<任务ID> 时触发（创建后把返回的密钥告知用户），"+
+			"keyword 在消息通道收到包含 trigger_keywords 的消息时触发，触发内容会附加在任务描述之后。"+
+			"用户要求把结果发送到消息通道、Webhook 或邮箱时，通过 deliveries 配置投递目标。用户要求把结果发送到消息通道、Webhook 或邮箱时，通过 deliveries 配置投递目标。"+
+			"用户要求失败重试、补执行错过的任务、控制重叠执行、指定时区或错开执行时间时，设置 max_retries、misfire、concurrency、time_zone 或 jitter。"+
+			"创建成功后告知用户任务已交由后台调度器管理即可，不要承诺你会去执行。",
 		t.ScheduleAdd)
 	if err != nil {
 		return nil, err
@@ -85,6 +91,43 @@ type ScheduleAddRequest struct {
 	Task      string `json:"task" jsonschema:"description=任务描述，应清晰完整，足以让团队独立执行"`
 	CronExpr  string `json:"cron_expr,omitempty" jsonschema:"description=标准 cron 表达式（5个字段：分 时 日 月 周），用于重复执行的定时任务。例如：*/5 * * * * 表示每5分钟，0 9 * * * 表示每天9点，0 9 * * 1-5 表示工作日9点"`
 	ExecuteAt string `json:"execute_at,omitempty" jsonschema:"description=一次性任务的执行时间，格式为 ISO 8601（如 2025-01-15T09:00:00+08:00）。与 cron_expr 二选一"`
+
+	Agent         string   `json:"agent,omitempty" jsonschema:"description=执行任务的智能体名称（内置或自定义智能体，如 researcher），与 mode 二选一，都不填时由任务官执行"`
+	Mode          string   `json:"mode,omitempty" jsonschema:"description=执行任务的工作模式：team、deep、roundtable 或 workflow:<名称>，与 agent 二选一"`
+	Model         string   `json:"model,omitempty" jsonschema:"description=覆盖使用的模型 ID（配置文件 models 中的 id），留空使用默认模型"`
+	WorkDir       string   `json:"work_dir,omitempty" jsonschema:"description=任务的工作目录，必须是绝对路径，留空使用默认工作区"`
+	Tools         []string `json:"tools,omitempty" jsonschema:"description=工具白名单（工具名，如 search、fetch、file_read），留空不限制"`
+	MaxIterations int      `json:"max_iterations,omitempty" jsonschema:"description=单个智能体的最大迭代次数，留空使用默认值"`
+	Timeout       string   `json:"timeout,omitempty" jsonschema:"description=单次执行超时，如 10m、1h，默认 30m，最长 24h"`
+
+	Deliveries []ScheduleDelivery `json:"deliveries,omitempty" jsonschema:"description=执行结束后接收结果的目标，留空时结果只保存在任务历史中"`
+
+	TimeZone        string `json:"time_zone,omitempty" jsonschema:"description=计算 cron 表达式使用的 IANA 时区，如 Asia/Shanghai，留空使用服务器本地时区"`
+	MaxRetries      int    `json:"max_retries,omitempty" jsonschema:"description=执行失败后的最大重试次数（0-10），默认不重试"`
+	RetryBackoff    string `json:"retry_backoff,omitempty" jsonschema:"description=首次重试前的等待时间，之后每次翻倍，如 30s、5m，默认 1m"`
+	RetryMaxBackoff string `json:"retry_max_backoff,omitempty" jsonschema:"description=单次重试等待时间上限，默认 1h"`
+	Misfire         string `json:"misfire,omitempty" jsonschema:"description=错过执行（如服务停止期间到期）的处理：run_once（默认）补执行一次、run_all 逐次补执行、skip 跳过"`
+	Concurrency     string `json:"concurrency,omitempty" jsonschema:"description=重复任务上一次执行未结束时的处理：forbid（默认）跳过、allow 并行执行、replace 取消旧执行并开始新执行"`
+	Jitter          string `json:"jitter,omitempty" jsonschema:"description=每次执行附加的随机延迟上限，如 5m，最长 1h"`
+
+	TriggerType     string   `json:"trigger_type,omitempty" jsonschema:"description=事件触发类型：file（文件变化）、webhook（HTTP 调用）或 keyword（通道消息关键词），与 cron_expr、execute_at 互斥"`
+	TriggerGlob     string   `json:"trigger_glob,omitempty" jsonschema:"description=file 触发器监视的文件模式，相对工作目录，支持 **，如 docs/**/*.md"`
+	TriggerDebounce string   `json:"trigger_debounce,omitempty" jsonschema:"description=file 触发器的防抖时间，文件停止变化这么久后才触发，默认 2s"`
+	TriggerKeywords []string `json:"trigger_keywords,omitempty" jsonschema:"description=keyword 触发器的关键词，消息包含任一关键词（不区分大小写）即触发"`
+	TriggerChannel  string   `json:"trigger_channel,omitempty" jsonschema:"description=keyword 触发器只监听的通道名，留空监听所有通道"`
+	TriggerChatID   string   `json:"trigger_chat_id,omitempty" jsonschema:"description=keyword 触发器只监听的会话 ID，需同时指定 trigger_channel"`
+}
+
+// ScheduleDelivery 是定时任务的结果投递目标。
+type ScheduleDelivery struct {
+	Type     string   `json:"type" jsonschema:"description=投递方式：channel（消息通道）、webhook 或 email"`
+	On       string   `json:"on,omitempty" jsonschema:"description=何时投递：always（默认）、success 仅成功时、failure 仅失败时"`
+	Channel  string   `json:"channel,omitempty" jsonschema:"description=channel 投递的通道名：discord、qq 或 weixin"`
+	ChatID   string   `json:"chat_id,omitempty" jsonschema:"description=channel 投递的会话 ID"`
+	URL      string   `json:"url,omitempty" jsonschema:"description=webhook 投递的 http(s) 地址"`
+	To       []string `json:"to,omitempty" jsonschema:"description=email 投递的收件人地址"`
+	Subject  string   `json:"subject,omitempty" jsonschema:"description=邮件主题模板（Go text/template），留空使用默认主题"`
+	Template string   `json:"template,omitempty" jsonschema:"description=正文模板（Go text/template），可用 .TaskID .Task .Success .Result .Error .Time，留空使用默认格式"`
 }
 
 // ScheduleAddResponse 创建定时任务响应。
@@ -142,17 +185,75 @@ func (t *Tools) ScheduleAdd(ctx context.Context, req *ScheduleAddRequest) (*Sche
 		Task:      req.Task,
 		CronExpr:  req.CronExpr,
 		ExecuteAt: req.ExecuteAt,
+		Target: domainschedule.Target{
+			Agent:         req.Agent,
+			Mode:          req.Mode,
+			Model:         req.Model,
+			WorkDir:       req.WorkDir,
+			Tools:         req.Tools,
+			MaxIterations: req.MaxIterations,
+			Timeout:       req.Timeout,
+		},
+		Deliveries: toDeliveries(req.Deliveries),
+		TimeZone:   req.TimeZone,
+		Policy: domainschedule.Policy{
+			Retry: domainschedule.RetryPolicy{
+				MaxAttempts: req.MaxRetries,
+				Backoff:     req.RetryBackoff,
+				MaxBackoff:  req.RetryMaxBackoff,
+			},
+			Misfire:     domainschedule.MisfirePolicy(req.Misfire),
+			Concurrency: domainschedule.ConcurrencyPolicy(req.Concurrency),
+			Jitter:      req.Jitter,
+		},
+		Trigger: toTrigger(req),
 	})
 	if err != nil {
 		return &ScheduleAddResponse{ErrorMessage: err.Error()}, nil
 	}
+	message := "task created, will be executed in the background on schedule"
+	if task.Triggered() {
+		message = "task created, will be executed in the background when triggered"
+	}
 	return &ScheduleAddResponse{
 		Success: true,
-		Message: "task created, will be executed by background tasker on schedule",
+		Message: message,
 		Task:    task,
 	}, nil
 }
 
+// toTrigger 将扁平的触发器参数转换为触发条件，未设置触发类型时返回 nil。
+func toTrigger(req *ScheduleAddRequest) *domainschedule.Trigger {
+	if req.TriggerType == "" {
+		return nil
+	}
+	return &domainschedule.Trigger{
+		Type:     domainschedule.TriggerType(req.TriggerType),
+		Glob:     req.TriggerGlob,
+		Debounce: req.TriggerDebounce,
+		Channel:  req.TriggerChannel,
+		ChatID:   req.TriggerChatID,
+		Keywords: req.TriggerKeywords,
+	}
+}
+
+func toDeliveries(items []ScheduleDelivery) []domainschedule.Delivery {
+	var deliveries []domainschedule.Delivery
+	for _, item := range items {
+		deliveries = append(deliveries, domainschedule.Delivery{
+			Type:     domainschedule.DeliveryType(item.Type),
+			On:       domainschedule.DeliveryCondition(item.On),
+			Channel:  item.Channel,
+			ChatID:   item.ChatID,
+			URL:      item.URL,
+			To:       item.To,
+			Subject:  item.Subject,
+			Template: item.Template,
+		})
+	}
+	return deliveries
+}
+
 // ScheduleList 列出定时任务。
 func (t *Tools) ScheduleList(ctx context.Context, req *ScheduleListRequest) (*ScheduleListResponse, error) {
 	service, err := t.serviceOrError(ctx)
@@ -219,9 +320,38 @@ func FormatTasksForDisplay(tasks []domainschedule.Task) string {
 		fmt.Fprintf(&sb, "  %s %d. %s\n", statusIcon, i+1, task.Task)
 		fmt.Fprintf(&sb, "     ID: %s | Status: %s\n", task.ID, task.Status)
 		if task.CronExpr != "" {
-			fmt.Fprintf(&sb, "     Cron: %s\n", task.CronExpr)
+			if task.TimeZone != "" {
+				fmt.Fprintf(&sb, "     Cron: %s (%s)\n", task.CronExpr, task.TimeZone)
+			} else {
+				fmt.Fprintf(&sb, "     Cron: %s\n", task.CronExpr)
+			}
+		}
+		if task.Trigger != nil {
+			if describe := task.Trigger.Describe(); describe != "" {
+				fmt.Fprintf(&sb, "     Trigger: %s %s\n", task.Trigger.Type, describe)
+			} else {
+				fmt.Fprintf(&sb, "     Trigger: %s\n", task.Trigger.Type)
+			}
+		}
+		if target := formatTarget(task.Target); target != "" {
+			fmt.Fprintf(&sb, "     Target: %s\n", target)
+		}
+		for _, delivery := range task.Deliveries {
+			fmt.Fprintf(&sb, "     Deliver: %s %s\n", delivery.Type, delivery.Describe())
+		}
+		if policy := formatPolicy(task.Policy); policy != "" {
+			fmt.Fprintf(&sb, "     Policy: %s\n", policy)
+		}
+		if task.LastError != "" {
+			if task.Attempt > 0 {
+				fmt.Fprintf(&sb, "     Last error (retry %d): %s\n", task.Attempt, task.LastError)
+			} else {
+				fmt.Fprintf(&sb, "     Last error: %s\n", task.LastError)
+			}
+		}
+		if !task.NextRunAt.IsZero() {
+			fmt.Fprintf(&sb, "     Next run: %s\n", task.NextRunAt.Format("2006-01-02 15:04:05"))
 		}
-		fmt.Fprintf(&sb, "     Next run: %s\n", task.NextRunAt.Format("2006-01-02 15:04:05"))
 		if task.LastRunAt != nil {
 			fmt.Fprintf(&sb, "     Last run: %s\n", task.LastRunAt.Format("2006-01-02 15:04:05"))
 		}
@@ -233,6 +363,51 @@ func FormatTasksForDisplay(tasks []domainschedule.Task) string {
 	return sb.String()
 }
 
+// formatTarget 将执行目标格式化为单行摘要，零值返回空字符串。
+func formatTarget(target domainschedule.Target) string {
+	var parts []string
+	add := func(key, value string) {
+		if value != "" {
+			parts = append(parts, key+"="+value)
+		}
+	}
+	add("agent", target.Agent)
+	add("mode", target.Mode)
+	add("model", target.Model)
+	add("work_dir", target.WorkDir)
+	add("tools", strings.Join(target.Tools, ","))
+	if target.MaxIterations > 0 {
+		add("max_iterations", fmt.Sprint(target.MaxIterations))
+	}
+	add("timeout", target.Timeout)
+	return strings.Join(parts, " ")
+}
+
+// formatPolicy 将执行策略格式化为单行摘要，零值返回空字符串。
+func formatPolicy(policy domainschedule.Policy) string {
+	var parts []string
+	if policy.Retry.MaxAttempts > 0 {
+		retry := fmt.Sprintf("retries=%d", policy.Retry.MaxAttempts)
+		if policy.Retry.Backoff != "" {
+			retry += " backoff=" + policy.Retry.Backoff
+		}
+		if policy.Retry.MaxBackoff != "" {
+			retry += " max_backoff=" + policy.Retry.MaxBackoff
+		}
+		parts = append(parts, retry)
+	}
+	if policy.Misfire != "" {
+		parts = append(parts, "misfire="+string(policy.Misfire))
+	}
+	if policy.Concurrency != "" {
+		parts = append(parts, "concurrency="+string(policy.Concurrency))
+	}
+	if policy.Jitter != "" {
+		parts = append(parts, "jitter="+policy.Jitter)
+	}
+	return strings.Join(parts, " ")
+}
+
 // FormatTaskDetailJSON 格式化单个任务为 JSON 字符串。
 func FormatTaskDetailJSON(task domainschedule.Task) string {
 	data, err := json.MarshalIndent(task, "", "  ")
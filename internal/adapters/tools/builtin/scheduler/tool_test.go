@@ -19,8 +19,17 @@ func TestToolsDelegateToScheduleService(t *testing.T) {
 	ctx := appschedule.WithService(context.Background(), service)
 
 	addResp, err := tools.ScheduleAdd(ctx, &ScheduleAddRequest{
-		Task:      "生成日报",
-		ExecuteAt: time.Now().Add(time.Hour).Format(time.RFC3339),
+		Task:          "生成日报",
+		ExecuteAt:     time.Now().Add(time.Hour).Format(time.RFC3339),
+		Agent:         "researcher",
+		Tools:         []string{"search"},
+		MaxIterations: 5,
+		Timeout:       "10m",
+		Deliveries:    []ScheduleDelivery{{Type: "webhook", URL: "https://hooks.example/a", On: "failure"}},
+		TimeZone:      "Asia/Shanghai",
+		MaxRetries:    3,
+		RetryBackoff:  "30s",
+		Misfire:       "skip",
 	})
 	if err != nil {
 		t.Fatalf("ScheduleAdd returned error: %v", err)
@@ -28,6 +37,15 @@ func TestToolsDelegateToScheduleService(t *testing.T) {
 	if !addResp.Success || addResp.Task == nil || fake.addReq.Task != "生成日报" {
 		t.Fatalf("ScheduleAdd response = %#v, addReq = %#v", addResp, fake.addReq)
 	}
+	if target := fake.addReq.Target; target.Agent != "researcher" || len(target.Tools) != 1 || target.MaxIterations != 5 || target.Timeout != "10m" {
+		t.Fatalf("ScheduleAdd target = %#v", target)
+	}
+	if deliveries := fake.addReq.Deliveries; len(deliveries) != 1 || deliveries[0].Type != domainschedule.DeliveryWebhook || deliveries[0].On != domainschedule.DeliverOnFailure {
+		t.Fatalf("ScheduleAdd deliveries = %#v", deliveries)
+	}
+	if policy := fake.addReq.Policy; fake.addReq.TimeZone != "Asia/Shanghai" || policy.Retry.MaxAttempts != 3 || policy.Retry.Backoff != "30s" || policy.Misfire != domainschedule.MisfireSkip {
+		t.Fatalf("ScheduleAdd time zone = %q, policy = %#v", fake.addReq.TimeZone, policy)
+	}
 
 	listResp, err := tools.ScheduleList(ctx, &ScheduleListRequest{StatusFilter: string(domainschedule.StatusPending)})
 	if err != nil {
@@ -54,6 +72,24 @@ func TestToolsDelegateToScheduleService(t *testing.T) {
 	}
 }
 
+func TestScheduleAddMapsTrigger(t *testing.T) {
+	fake := &fakeScheduler{}
+	ctx := appschedule.WithService(context.Background(), appschedule.NewService(fake))
+	resp, err := NewTools(nil).ScheduleAdd(ctx, &ScheduleAddRequest{
+		Task:            "回复部署请求",
+		TriggerType:     "keyword",
+		TriggerKeywords: []string{"部署"},
+		TriggerChannel:  "qq",
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("ScheduleAdd = %#v, %v", resp, err)
+	}
+	trigger := fake.addReq.Trigger
+	if trigger == nil || trigger.Type != domainschedule.TriggerKeyword || trigger.Channel != "qq" || len(trigger.Keywords) != 1 {
+		t.Fatalf("ScheduleAdd trigger = %#v", trigger)
+	}
+}
+
 func TestToolsReturnErrorMessageWhenServiceMissing(t *testing.T) {
 	tools := NewTools(nil)
 	resp, err := tools.ScheduleList(context.Background(), &ScheduleListRequest{})
@@ -89,14 +125,31 @@ func TestFormatTasksForDisplay(t *testing.T) {
 		Task:      "整理测试",
 		Status:    domainschedule.StatusPending,
 		CronExpr:  "0 9 * * *",
+		TimeZone:  "Asia/Shanghai",
 		NextRunAt: now,
+		Target:    domainschedule.Target{Agent: "researcher", Model: "cheap"},
+		Deliveries: []domainschedule.Delivery{
+			{Type: domainschedule.DeliveryChannel, Channel: "discord", ChatID: "123"},
+		},
+		Policy:    domainschedule.Policy{Retry: domainschedule.RetryPolicy{MaxAttempts: 2}, Concurrency: domainschedule.ConcurrencyAllow},
+		Attempt:   1,
+		LastError: "timeout",
 	}})
 
-	for _, want := range []string{"1 scheduled tasks", "整理测试", "task-1", "0 9 * * *"} {
+	for _, want := range []string{"1 scheduled tasks", "整理测试", "task-1", "0 9 * * * (Asia/Shanghai)", "Target: agent=researcher model=cheap", "Deliver: channel discord:123", "Policy: retries=2 concurrency=allow", "Last error (retry 1): timeout"} {
 		if !strings.Contains(got, want) {
 			t.Fatalf("display = %q, want containing %q", got, want)
 		}
 	}
+	triggered := FormatTasksForDisplay([]domainschedule.Task{{
+		ID:      "task-2",
+		Task:    "整理文档",
+		Status:  domainschedule.StatusPending,
+		Trigger: &domainschedule.Trigger{Type: domainschedule.TriggerFile, Glob: "docs/**/*.md"},
+	}})
+	if !strings.Contains(triggered, "Trigger: file docs/**/*.md") || strings.Contains(triggered, "Next run") {
+		t.Fatalf("triggered display = %q", triggered)
+	}
 	if got := FormatTasksForDisplay(nil); got != "no scheduled tasks" {
 		t.Fatalf("empty display = %q", got)
 	}
@@ -136,6 +189,14 @@ func (s *fakeScheduler) CancelTask(ctx context.Context, taskID string) error {
 	return nil
 }
 
+func (s *fakeScheduler) FireTrigger(ctx context.Context, taskID string, event domainschedule.TriggerEvent) error {
+	return errors.New("not used")
+}
+
+func (s *fakeScheduler) AssignOwner(ctx context.Context, owner string) (int, error) {
+	return 0, errors.New("not used")
+}
+
 func (s *fakeScheduler) DeleteTask(ctx context.Context, taskID string) error {
 	s.deleteID = taskID
 	return nil
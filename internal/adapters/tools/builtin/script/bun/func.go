@@ -12,7 +12,9 @@ import (
 	"strings"
 	"time"
 
+	"fkteams/internal/runtime/env"
 	"fkteams/internal/runtime/executil"
+	"fkteams/internal/runtime/sandbox"
 )
 
 const (
@@ -28,11 +30,21 @@ type BunTools struct {
 	workDir string
 	// bunPath 是 bun 命令的路径
 	bunPath string
+	// sandbox 是子进程执行后端
+	sandbox sandbox.Executor
+}
+
+// Option 配置选项
+type Option func(*BunTools)
+
+// WithSandbox 设置子进程执行后端，默认直接执行
+func WithSandbox(executor sandbox.Executor) Option {
+	return func(bt *BunTools) { bt.sandbox = executor }
 }
 
 // NewBunTools 创建一个新的 bun 工具实例
 // envDir 存放项目环境，workDir 作为脚本执行的工作目录
-func NewBunTools(envDir, workDir string) (*BunTools, error) {
+func NewBunTools(envDir, workDir string, opts ...Option) (*BunTools, error) {
 	// 转换为绝对路径
 	absEnvDir, err := filepath.Abs(envDir)
 	if err != nil {
@@ -58,17 +70,30 @@ func NewBunTools(envDir, workDir string) (*BunTools, error) {
 		return nil, fmt.Errorf("未找到 bun 命令，请先安装 bun: https://bun.sh （可以使用 fkteams init --env bun 安装）")
 	}
 
-	return &BunTools{
+	tools := &BunTools{
 		envDir:  absEnvDir,
 		workDir: absWorkDir,
 		bunPath: bunPath,
-	}, nil
+		sandbox: sandbox.Direct(),
+	}
+	for _, opt := range opts {
+		opt(tools)
+	}
+	return tools, nil
+}
+
+// sandboxed 让命令在配置的沙箱中运行，环境目录和工作目录可写，不传递敏感环境变量
+func (bt *BunTools) sandboxed(cmd *exec.Cmd) func() {
+	cmd.Env = env.ChildEnviron()
+	return bt.sandbox.Wrap(cmd, sandbox.WrapOptions{Writable: []string{bt.envDir, bt.workDir}})
 }
 
 // executeCommand 执行命令并返回输出
 func (bt *BunTools) executeCommand(ctx context.Context, name string, args ...string) (string, error) {
 	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Dir = bt.envDir
+	release := bt.sandboxed(cmd)
+	defer release()
 
 	output, truncated, err := executil.CombinedOutput(cmd, maxScriptOutputBytes)
 	outputText := executil.String(output, truncated)
@@ -501,6 +526,8 @@ func (bt *BunTools) RunScript(ctx context.Context, req *RunScriptRequest) (*RunS
 	startTime := time.Now()
 	cmd := exec.CommandContext(execCtx, bt.bunPath, args...)
 	cmd.Dir = bt.workDir
+	release := bt.sandboxed(cmd)
+	defer release()
 
 	output, truncated, err := executil.CombinedOutput(cmd, maxScriptOutputBytes)
 	outputText := executil.String(output, truncated)
@@ -7,6 +7,8 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+
+	"fkteams/internal/runtime/sandbox"
 )
 
 func newTestBunTools(t *testing.T) *BunTools {
@@ -25,6 +27,7 @@ func newTestBunTools(t *testing.T) *BunTools {
 		envDir:  envDir,
 		workDir: workDir,
 		bunPath: writeFakeCommand(t, root, "bun", fakeBunScript()),
+		sandbox: sandbox.Direct(),
 	}
 }
 
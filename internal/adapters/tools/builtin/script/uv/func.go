@@ -12,7 +12,9 @@ import (
 	"strings"
 	"time"
 
+	"fkteams/internal/runtime/env"
 	"fkteams/internal/runtime/executil"
+	"fkteams/internal/runtime/sandbox"
 )
 
 const maxScriptOutputBytes int64 = 1 << 20
@@ -27,11 +29,21 @@ type UVTools struct {
 	venvPath string
 	// uvPath 是 uv 命令的路径
 	uvPath string
+	// sandbox 是子进程执行后端
+	sandbox sandbox.Executor
+}
+
+// Option 配置选项
+type Option func(*UVTools)
+
+// WithSandbox 设置子进程执行后端，默认直接执行
+func WithSandbox(executor sandbox.Executor) Option {
+	return func(ut *UVTools) { ut.sandbox = executor }
 }
 
 // NewUVTools 创建一个新的 uv 工具实例
 // envDir 存放虚拟环境，workDir 作为脚本执行的工作目录
-func NewUVTools(envDir, workDir string) (*UVTools, error) {
+func NewUVTools(envDir, workDir string, opts ...Option) (*UVTools, error) {
 	// 转换为绝对路径
 	absEnvDir, err := filepath.Abs(envDir)
 	if err != nil {
@@ -59,18 +71,31 @@ func NewUVTools(envDir, workDir string) (*UVTools, error) {
 
 	venvPath := filepath.Join(absEnvDir, ".venv")
 
-	return &UVTools{
+	tools := &UVTools{
 		envDir:   absEnvDir,
 		workDir:  absWorkDir,
 		venvPath: venvPath,
 		uvPath:   uvPath,
-	}, nil
+		sandbox:  sandbox.Direct(),
+	}
+	for _, opt := range opts {
+		opt(tools)
+	}
+	return tools, nil
+}
+
+// sandboxed 让命令在配置的沙箱中运行，环境目录和工作目录可写，不传递敏感环境变量
+func (ut *UVTools) sandboxed(cmd *exec.Cmd) func() {
+	cmd.Env = env.ChildEnviron()
+	return ut.sandbox.Wrap(cmd, sandbox.WrapOptions{Writable: []string{ut.envDir, ut.workDir}})
 }
 
 // executeCommand 执行命令并返回输出
 func (ut *UVTools) executeCommand(ctx context.Context, name string, args ...string) (string, error) {
 	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Dir = ut.envDir
+	release := ut.sandboxed(cmd)
+	defer release()
 
 	output, truncated, err := executil.CombinedOutput(cmd, maxScriptOutputBytes)
 	outputText := executil.String(output, truncated)
@@ -497,6 +522,8 @@ func (ut *UVTools) RunScript(ctx context.Context, req *RunScriptRequest) (*RunSc
 	startTime := time.Now()
 	cmd := exec.CommandContext(execCtx, pythonPath, args...)
 	cmd.Dir = ut.workDir
+	release := ut.sandboxed(cmd)
+	defer release()
 
 	output, truncated, err := executil.CombinedOutput(cmd, maxScriptOutputBytes)
 	outputText := executil.String(output, truncated)
@@ -641,6 +668,8 @@ func (ut *UVTools) RunCode(ctx context.Context, req *RunCodeRequest) (*RunCodeRe
 	startTime := time.Now()
 	cmd := exec.CommandContext(execCtx, pythonPath, args...)
 	cmd.Dir = ut.workDir
+	release := ut.sandboxed(cmd)
+	defer release()
 
 	output, truncated, err := executil.CombinedOutput(cmd, maxScriptOutputBytes)
 	outputText := executil.String(output, truncated)
@@ -755,6 +784,8 @@ except Exception as e:
 
 	cmd := exec.CommandContext(ctx, pythonPath, args...)
 	cmd.Dir = ut.workDir
+	release := ut.sandboxed(cmd)
+	defer release()
 
 	output, truncated, err := executil.CombinedOutput(cmd, maxScriptOutputBytes)
 	result := strings.TrimSpace(executil.String(output, truncated))
@@ -862,6 +893,8 @@ except Exception as e:
 	args := []string{"-c", formatScript, req.Code}
 	cmd := exec.CommandContext(ctx, pythonPath, args...)
 	cmd.Dir = ut.workDir
+	release := ut.sandboxed(cmd)
+	defer release()
 
 	output, truncated, err := executil.CombinedOutput(cmd, maxScriptOutputBytes)
 	if err != nil {
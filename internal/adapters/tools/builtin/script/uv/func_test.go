@@ -7,6 +7,8 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+
+	"fkteams/internal/runtime/sandbox"
 )
 
 func newTestUVTools(t *testing.T) *UVTools {
@@ -26,6 +28,7 @@ func newTestUVTools(t *testing.T) *UVTools {
 		workDir:  workDir,
 		venvPath: filepath.Join(envDir, ".venv"),
 		uvPath:   writeFakeCommand(t, root, "uv", fakeUVScript()),
+		sandbox:  sandbox.Direct(),
 	}
 }
 
@@ -13,6 +13,8 @@ import (
 	appchat "fkteams/internal/app/chat"
 	appschedule "fkteams/internal/app/schedule"
 	apptools "fkteams/internal/app/tools"
+	appusage "fkteams/internal/app/usage"
+	domainmemory "fkteams/internal/domain/memory"
 	runtimeport "fkteams/internal/ports/runtime"
 	"fkteams/internal/runtime/approval"
 	"fkteams/internal/runtime/events"
@@ -419,6 +421,7 @@ func (b *Bridge) processBatch(runCtx context.Context, sessionID string, batch []
 	ctx := WithChannelName(runCtx, channelName)
 	ctx = appstate.WithState(ctx, b.state)
 	ctx = b.withRuntimeContext(ctx)
+	ctx = appusage.WithScope(ctx, appusage.Scope{Channel: channelName})
 
 	r, err := b.getRunner(ctx)
 	if err != nil {
@@ -456,7 +459,8 @@ func (b *Bridge) processBatch(runCtx context.Context, sessionID string, batch []
 	recorder, releaseRecorder := b.sessions.Acquire(sessionID, b.historyDir)
 	defer releaseRecorder()
 	recorder.SetToolDisplayResolver(toolmeta.ResolverFromContext(ctx))
-	turnInput := appchat.BuildTurnInputWithMemory(recorder, combinedInput, b.memoryManager())
+	memoryScope := b.memoryScope(channelName, chatID, batch)
+	turnInput := appchat.BuildTurnInputWithMemory(recorder, combinedInput, b.memoryManager(), memoryScope)
 
 	rc := newReplyCollector(b.manager, channelName, chatID)
 
@@ -467,6 +471,8 @@ func (b *Bridge) processBatch(runCtx context.Context, sessionID string, batch []
 		Summary:          recorder,
 		NonInteractive:   true,
 		ApprovalRegistry: approval.NewAutoApproveRegistry(),
+		Memory:           b.memoryManager(),
+		MemoryScope:      memoryScope,
 		EventSink: func(event events.Event) error {
 			recorder.RecordEvent(event)
 			return rc.handleEvent(event)
@@ -495,6 +501,7 @@ func (b *Bridge) processBatch(runCtx context.Context, sessionID string, batch []
 				History:        recorder,
 				Memory:         b.memoryManager(),
 				MemoryMessages: eventlog.ConvertMemoryMessages(recorder),
+				MemoryScope:    memoryScope,
 			})
 			appchat.LogLifecycleError("channel", sessionID, lifecycleErr)
 			if !rc.replied {
@@ -685,7 +692,7 @@ func (rc *replyCollector) send(text string) {
 	}
 	rc.replied = true
 	ctx := context.Background()
-	for _, chunk := range splitMessage(text, 2000) {
+	for _, chunk := range SplitMessage(text, 2000) {
 		if err := rc.manager.SendText(ctx, rc.channelName, rc.chatID, chunk); err != nil {
 			log.Printf("[bridge] send reply failed: channel=%s, chat=%s, err=%v", rc.channelName, rc.chatID, err)
 			break
@@ -701,8 +708,8 @@ func WithChannelName(ctx context.Context, name string) context.Context {
 	return context.WithValue(ctx, channelNameKey{}, name)
 }
 
-// splitMessage 按最大长度分割消息，优先在换行处分割以保持语义完整
-func splitMessage(text string, maxLen int) []string {
+// SplitMessage 按最大长度（字符数）分割消息，优先在换行处分割以保持语义完整
+func SplitMessage(text string, maxLen int) []string {
 	runes := []rune(text)
 	if len(runes) <= maxLen {
 		return []string{text}
@@ -731,3 +738,24 @@ func splitMessage(text string, maxLen int) []string {
 	}
 	return chunks
 }
+
+// memoryScope 返回通道对话的记忆作用域：按通道用户隔离，未指定智能体时使用运行模式。
+// 群聊中多人的消息被合并处理时，改为按会话隔离。
+func (b *Bridge) memoryScope(channelName, chatID string, batch []queuedMessage) domainmemory.ScopeContext {
+	user := batch[0].senderID
+	for _, message := range batch[1:] {
+		if message.senderID != user {
+			user = chatID
+			break
+		}
+	}
+	agent := b.agentID
+	if agent == "" {
+		agent = b.mode
+	}
+	scope := domainmemory.ScopeContext{Agent: agent}
+	if user != "" {
+		scope.User = channelName + ":" + user
+	}
+	return scope
+}
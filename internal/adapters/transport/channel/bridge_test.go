@@ -90,7 +90,7 @@ func TestBuildUserInputCombinesContentAndAttachments(t *testing.T) {
 
 func TestSplitMessagePrefersNewlineAndHardSplitsLongText(t *testing.T) {
 	text := "第一行数据\n第二行内容很长\n第三行"
-	chunks := splitMessage(text, 10)
+	chunks := SplitMessage(text, 10)
 	for _, chunk := range chunks {
 		if len([]rune(chunk)) > 10 {
 			t.Fatalf("chunk %q length = %d, want <= 10", chunk, len([]rune(chunk)))
@@ -104,7 +104,7 @@ func TestSplitMessagePrefersNewlineAndHardSplitsLongText(t *testing.T) {
 	}
 
 	hardText := "abcdefghijklmnop"
-	hardChunks := splitMessage(hardText, 5)
+	hardChunks := SplitMessage(hardText, 5)
 	if got, want := strings.Join(hardChunks, ""), hardText; got != want {
 		t.Fatalf("joined hard chunks = %q, want %q", got, want)
 	}
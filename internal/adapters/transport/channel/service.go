@@ -56,6 +56,10 @@ func SetupWithOptions(entries []config.ChannelEntry, options SetupOptions) (*Ser
 		if name, ok := ctx.Value(channelNameKey{}).(string); ok {
 			channelName = name
 		}
+		// 关键词触发器与正常对话互不影响，命中后消息仍交给 Bridge 处理
+		if options.SchedulerProvider != nil {
+			options.SchedulerProvider().HandleChannelMessage(ctx, channelName, chatID, senderID, msg.Content)
+		}
 		if bridge, ok := bridges[channelName]; ok {
 			bridge.HandleMessage(ctx, chatID, senderID, msg, isGroup)
 		}
@@ -13,6 +13,7 @@ import (
 	"fkteams/internal/app/agent/catalog"
 	"fkteams/internal/app/config"
 	"fkteams/internal/app/lifecycle"
+	"fkteams/internal/app/project"
 	bootstrapservices "fkteams/internal/bootstrap/services"
 	runtimeport "fkteams/internal/ports/runtime"
 
@@ -76,7 +77,7 @@ func agentCommand() *ucli.Command {
 			},
 			&ucli.StringFlag{
 				Name:  "approve",
-				Usage: "自动批准指定操作类别 (all/command/file/git/dispatch，逗号分隔)",
+				Usage: "自动批准指定操作类别 (all/command/file/git/dispatch/memory，逗号分隔)",
 			},
 		},
 		Action: agentAction,
@@ -89,7 +90,15 @@ func agentAction(ctx context.Context, cmd *ucli.Command) error {
 		return err
 	}
 
+	proj, err := project.Resolve(cmd.Root().String("project"))
+	if err != nil {
+		return err
+	}
+	ctx = project.WithProject(ctx, proj)
 	agentName := cmd.String("name")
+	if agentName == "" {
+		agentName = proj.Agent
+	}
 	if agentName == "" {
 		return fmt.Errorf("请通过 --name/-n 指定 Agent 名称，或使用 agent list 查看可用列表")
 	}
@@ -187,7 +196,8 @@ func agentAction(ctx context.Context, cmd *ucli.Command) error {
 		if approve == "" {
 			approve = cmd.Root().String("approve")
 		}
-		session.ApproveStores = approve
+		session.ApproveStores = projectApproveStores(approve, proj)
+		session.SetProject(proj)
 
 		format := cmd.String("format")
 		if format == "json" {
@@ -0,0 +1,209 @@
+package commands
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	fileapikey "fkteams/internal/adapters/storage/file/apikey"
+	appapikey "fkteams/internal/app/apikey"
+	"fkteams/internal/app/appdata"
+	"fkteams/internal/app/config"
+	domainapikey "fkteams/internal/domain/apikey"
+
+	"github.com/pterm/pterm"
+	ucli "github.com/urfave/cli/v3"
+)
+
+// apiKeyCommand 创建 generate apikey 子命令。不带子命令时只生成写入 config.toml 的无限制密钥。
+func apiKeyCommand() *ucli.Command {
+	return &ucli.Command{
+		Name:  "apikey",
+		Usage: "生成 OpenAI 兼容 API 密钥，或管理具名 API 密钥",
+		Action: func(ctx context.Context, cmd *ucli.Command) error {
+			b := make([]byte, 24)
+			if _, err := rand.Read(b); err != nil {
+				return fmt.Errorf("failed to generate random bytes: %w", err)
+			}
+			key := domainapikey.Prefix + hex.EncodeToString(b)
+			fmt.Println(key)
+			fmt.Println("\n请将此密钥添加到 config.toml 的 [openai_api] 配置中:")
+			fmt.Println("  api_keys = [\"" + key + "\"]")
+			fmt.Println("\n需要限制范围、有效期或频率时，使用 fkteams generate apikey create 创建具名密钥")
+			return nil
+		},
+		Commands: []*ucli.Command{
+			{
+				Name:  "create",
+				Usage: "创建具名 API 密钥，明文只显示一次",
+				Flags: []ucli.Flag{
+					&ucli.StringFlag{
+						Name:     "name",
+						Aliases:  []string{"n"},
+						Usage:    "密钥名称",
+						Required: true,
+					},
+					&ucli.StringSliceFlag{
+						Name:  "scope",
+						Usage: "允许的范围，可多次指定 (openai, chat, sessions:read, schedules)",
+						Value: []string{string(domainapikey.ScopeOpenAI)},
+					},
+					&ucli.StringSliceFlag{
+						Name:  "model",
+						Usage: "允许使用的模型 ID，可多次指定；默认不限制",
+					},
+					&ucli.StringSliceFlag{
+						Name:  "agent",
+						Usage: "允许调用的智能体或模式，可多次指定；默认不限制",
+					},
+					&ucli.IntFlag{
+						Name:  "rate-limit",
+						Usage: "每分钟请求上限，0 表示不限制",
+					},
+					&ucli.IntFlag{
+						Name:  "days",
+						Value: 90,
+						Usage: "有效天数，0 表示永不过期",
+					},
+					&ucli.StringFlag{
+						Name:  "owner",
+						Usage: "密钥代表的账号，默认为配置文件中的管理员",
+					},
+				},
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					if err := config.Init(); err != nil {
+						return err
+					}
+					days := cmd.Int("days")
+					if days < 0 {
+						return fmt.Errorf("--days 不能为负数")
+					}
+					var expiresAt *time.Time
+					if days > 0 {
+						t := time.Now().AddDate(0, 0, days)
+						expiresAt = &t
+					}
+					owner := cmd.String("owner")
+					if owner == "" {
+						owner = config.Get().Server.Auth.Username
+					}
+					scopes := make([]domainapikey.Scope, 0, len(cmd.StringSlice("scope")))
+					for _, scope := range cmd.StringSlice("scope") {
+						scopes = append(scopes, domainapikey.Scope(scope))
+					}
+					key, secret, err := cliAPIKeyService().Create(ctx, appapikey.CreateRequest{
+						Name:      cmd.String("name"),
+						Scopes:    scopes,
+						Models:    cmd.StringSlice("model"),
+						Agents:    cmd.StringSlice("agent"),
+						RateLimit: cmd.Int("rate-limit"),
+						Owner:     owner,
+						ExpiresAt: expiresAt,
+					})
+					if err != nil {
+						return err
+					}
+					fmt.Println(secret)
+					fmt.Printf("\n已创建密钥 %s (ID: %s)，明文不会再次显示，请妥善保存\n", key.Name, key.ID)
+					return nil
+				},
+			},
+			{
+				Name:    "list",
+				Aliases: []string{"ls"},
+				Usage:   "列出具名 API 密钥",
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					if err := config.Init(); err != nil {
+						return err
+					}
+					keys, err := cliAPIKeyService().List(ctx)
+					if err != nil {
+						return err
+					}
+					return renderAPIKeys(keys, time.Now())
+				},
+			},
+			{
+				Name:      "revoke",
+				Aliases:   []string{"rm"},
+				Usage:     "吊销具名 API 密钥",
+				ArgsUsage: "<id|name>",
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					id := cmd.Args().First()
+					if id == "" {
+						return fmt.Errorf("请指定密钥 ID 或名称")
+					}
+					if err := config.Init(); err != nil {
+						return err
+					}
+					key, err := cliAPIKeyService().Revoke(ctx, id)
+					if err != nil {
+						return err
+					}
+					pterm.Success.Printfln("已吊销密钥 %s (ID: %s)", key.Name, key.ID)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func cliAPIKeyService() *appapikey.Service {
+	return appapikey.NewService(fileapikey.NewStore(appdata.APIKeysFile()))
+}
+
+// renderAPIKeys 以表格输出密钥，不显示哈希
+func renderAPIKeys(keys []domainapikey.Key, now time.Time) error {
+	if len(keys) == 0 {
+		pterm.Warning.Println("暂无具名 API 密钥")
+		return nil
+	}
+	data := [][]string{{"ID", "名称", "前缀", "范围", "模型", "智能体", "限流/分钟", "归属", "过期时间", "最近使用"}}
+	for _, key := range keys {
+		scopes := make([]string, 0, len(key.Scopes))
+		for _, scope := range key.Scopes {
+			scopes = append(scopes, string(scope))
+		}
+		expires := "永不"
+		if key.ExpiresAt != nil {
+			expires = key.ExpiresAt.Local().Format("2006-01-02 15:04")
+			if key.Expired(now) {
+				expires += " (已过期)"
+			}
+		}
+		lastUsed := "-"
+		if key.LastUsedAt != nil {
+			lastUsed = key.LastUsedAt.Local().Format("2006-01-02 15:04")
+			if key.LastUsedIP != "" {
+				lastUsed += " " + key.LastUsedIP
+			}
+		}
+		rateLimit := "-"
+		if key.RateLimit > 0 {
+			rateLimit = fmt.Sprint(key.RateLimit)
+		}
+		data = append(data, []string{
+			key.ID,
+			key.Name,
+			key.Hint + "…",
+			strings.Join(scopes, ","),
+			listOrAll(key.Models),
+			listOrAll(key.Agents),
+			rateLimit,
+			defaultOrNone(key.Owner),
+			expires,
+			lastUsed,
+		})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
+func listOrAll(values []string) string {
+	if len(values) == 0 {
+		return "全部"
+	}
+	return strings.Join(values, ",")
+}
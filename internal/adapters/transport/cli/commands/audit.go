@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	fileaudit "fkteams/internal/adapters/storage/file/audit"
+	"fkteams/internal/app/appdata"
+	appaudit "fkteams/internal/app/audit"
+	appusage "fkteams/internal/app/usage"
+	domainaudit "fkteams/internal/domain/audit"
+	storageport "fkteams/internal/ports/storage"
+
+	"github.com/pterm/pterm"
+	ucli "github.com/urfave/cli/v3"
+)
+
+// auditCommand 创建 audit 子命令，查询和校验工具执行审计日志
+func auditCommand() *ucli.Command {
+	return &ucli.Command{
+		Name:  "audit",
+		Usage: "查询工具执行审计日志",
+		Flags: []ucli.Flag{
+			&ucli.StringFlag{Name: "user", Usage: "仅显示指定账号"},
+			&ucli.StringFlag{Name: "agent", Usage: "仅显示指定智能体"},
+			&ucli.StringFlag{Name: "tool", Usage: "仅显示指定工具"},
+			&ucli.StringFlag{Name: "session", Usage: "仅显示指定会话"},
+			&ucli.StringFlag{Name: "decision", Usage: "审批结果 (approved, rejected, denied)"},
+			&ucli.StringFlag{Name: "source", Usage: "审批来源 (user, auto_approve, policy)"},
+			&ucli.StringFlag{Name: "status", Usage: "结束状态 (ok, error, rejected, denied)"},
+			&ucli.StringFlag{Name: "path", Usage: "仅显示涉及该路径或其下文件的调用"},
+			&ucli.StringFlag{Name: "from", Usage: "起始日期 YYYY-MM-DD（包含）"},
+			&ucli.StringFlag{Name: "to", Usage: "结束日期 YYYY-MM-DD（包含）"},
+			&ucli.IntFlag{Name: "limit", Value: 50, Usage: "最多显示的记录数，0 表示不限"},
+		},
+		Action: func(ctx context.Context, cmd *ucli.Command) error {
+			from, to, err := appusage.ParseDayRange(cmd.String("from"), cmd.String("to"))
+			if err != nil {
+				return err
+			}
+			records, err := cliAuditLog().Query(ctx, domainaudit.Filter{
+				From:      from,
+				To:        to,
+				User:      cmd.String("user"),
+				SessionID: cmd.String("session"),
+				Agent:     cmd.String("agent"),
+				Tool:      cmd.String("tool"),
+				Decision:  cmd.String("decision"),
+				Source:    cmd.String("source"),
+				Status:    domainaudit.Status(cmd.String("status")),
+				Path:      cmd.String("path"),
+			}, int(cmd.Int("limit")))
+			if err != nil {
+				return err
+			}
+			return renderAuditRecords(records)
+		},
+		Commands: []*ucli.Command{
+			{
+				Name:  "init",
+				Usage: "生成审计密钥并保存到密钥库，密钥库不存在时一并创建",
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					created, err := appaudit.InitVaultKey(cliVault())
+					if err != nil {
+						return err
+					}
+					if !created {
+						pterm.Info.Println("审计密钥已存在，保持不变")
+						return nil
+					}
+					pterm.Success.Println("已生成审计密钥")
+					return nil
+				},
+			},
+			{
+				Name:  "verify",
+				Usage: "校验审计日志哈希链是否完整",
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					result, err := cliAuditLog().Verify(ctx)
+					if err != nil {
+						return err
+					}
+					if !result.Valid {
+						return fmt.Errorf("审计日志第 %d 行校验失败: %s（之前 %d 条记录完整）", result.Line, result.Reason, result.Records)
+					}
+					pterm.Success.Printfln("审计日志完整，共 %d 条记录", result.Records)
+					if result.Head != "" {
+						pterm.Info.Printfln("最新哈希: %s（保存此值可在之后发现日志末尾被截断）", result.Head)
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// cliAuditLog 创建命令行使用的审计日志，仅在写入或校验需要密钥时才解锁密钥库
+func cliAuditLog() *appaudit.Log {
+	var (
+		once   sync.Once
+		loaded storageport.AuditKeyFunc
+	)
+	key := func() ([]byte, error) {
+		once.Do(func() { loaded = appaudit.StaticKey(appaudit.LoadVaultKey(cliVault())) })
+		return loaded()
+	}
+	return appaudit.NewLog(fileaudit.NewStore(appdata.AuditFile(), key), key)
+}
+
+// warnAuditUnavailable 在启动会执行工具的命令时提示审计密钥不可用，此时所有工具调用都会被拒绝
+func warnAuditUnavailable(ctx context.Context) {
+	if log := appaudit.FromContext(ctx); log != nil {
+		if err := log.Ready(); err != nil {
+			pterm.Warning.Printfln("审计日志不可用，工具调用将被拒绝: %v", err)
+		}
+	}
+}
+
+// renderAuditRecords 以表格输出审计记录，最新的在前
+func renderAuditRecords(records []domainaudit.Record) error {
+	if len(records) == 0 {
+		pterm.Warning.Println("暂无审计记录")
+		return nil
+	}
+	data := [][]string{{"序号", "时间", "账号", "智能体", "工具", "审批", "状态", "路径"}}
+	for _, record := range records {
+		decision := "-"
+		if record.Decision != "" {
+			decision = record.Decision + " (" + record.DecisionSource + ")"
+		}
+		status := string(record.Status)
+		if record.ExitCode != nil {
+			status += fmt.Sprintf(" (%d)", *record.ExitCode)
+		}
+		data = append(data, []string{
+			fmt.Sprint(record.Seq),
+			record.At.Local().Format("2006-01-02 15:04:05"),
+			valueOrDash(record.User),
+			valueOrDash(record.Agent),
+			record.Tool,
+			decision,
+			status,
+			valueOrDash(strings.Join(record.Paths, ", ")),
+		})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
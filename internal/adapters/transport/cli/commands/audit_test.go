@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"fkteams/internal/app/appdata"
+	domainaudit "fkteams/internal/domain/audit"
+	"fkteams/internal/runtime/env"
+)
+
+func TestAuditListAndVerify(t *testing.T) {
+	useTempAppDir(t)
+	t.Setenv(env.VaultPassphrase, "vault-passphrase")
+	ctx := context.Background()
+
+	if _, err := cliAuditLog().Record(ctx, domainaudit.Record{Tool: "execute"}); err == nil {
+		t.Fatal("record without an audit key should fail")
+	}
+	if _, err := os.Stat(appdata.SecretsFile()); !os.IsNotExist(err) {
+		t.Fatalf("audit key lookup created the vault: %v", err)
+	}
+	for range 2 {
+		if err := auditCommand().Run(ctx, []string{"fkteams", "init"}); err != nil {
+			t.Fatalf("audit init returned error: %v", err)
+		}
+	}
+	if err := auditCommand().Run(ctx, []string{"fkteams", "verify"}); err != nil {
+		t.Fatalf("verify empty log returned error: %v", err)
+	}
+	for _, tool := range []string{"execute", "write_file"} {
+		if _, err := cliAuditLog().Record(ctx, domainaudit.Record{Tool: tool, Status: domainaudit.StatusOK}); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	if err := auditCommand().Run(ctx, []string{"fkteams", "--tool", "execute", "--from", "2026-01-01"}); err != nil {
+		t.Fatalf("audit list returned error: %v", err)
+	}
+	if err := auditCommand().Run(ctx, []string{"fkteams", "--from", "yesterday"}); err == nil {
+		t.Fatal("invalid date should be rejected")
+	}
+	if err := auditCommand().Run(ctx, []string{"fkteams", "verify"}); err != nil {
+		t.Fatalf("verify returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(appdata.AuditFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(data), `"tool":"write_file"`, `"tool":"read_file"`, 1)
+	if err := os.WriteFile(appdata.AuditFile(), []byte(tampered), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err = auditCommand().Run(ctx, []string{"fkteams", "verify"})
+	if err == nil || !strings.Contains(err.Error(), "第 2 行") {
+		t.Fatalf("verify tampered log error = %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	appagent "fkteams/internal/app/agent"
 	"fkteams/internal/app/config"
 	"fkteams/internal/app/lifecycle"
+	"fkteams/internal/app/project"
 	bootstrapservices "fkteams/internal/bootstrap/services"
 	runtimeport "fkteams/internal/ports/runtime"
 	"fkteams/internal/runtime/log"
@@ -23,8 +24,16 @@ func chatAction(ctx context.Context, cmd *ucli.Command) error {
 	if err := config.InitAndValidate(); err != nil {
 		return err
 	}
+	warnAuditUnavailable(ctx)
 
+	proj, err := project.Resolve(cmd.String("project"))
+	if err != nil {
+		return err
+	}
 	workMode := cmd.String("mode")
+	if !cmd.IsSet("mode") && proj.Mode != "" {
+		workMode = proj.Mode
+	}
 	currentMode := cliruntime.ParseWorkMode(workMode)
 	query := cmd.String("query")
 	pipeInput, isPipe, err := cliruntime.ReadPipeInput()
@@ -44,7 +53,8 @@ func chatAction(ctx context.Context, cmd *ucli.Command) error {
 	}
 	resumeSession := cmd.String("resume")
 	temporarySession := cmd.Bool("temporary")
-	approve := cmd.String("approve")
+	approve := projectApproveStores(cmd.String("approve"), proj)
+	ctx = project.WithProject(ctx, proj)
 
 	// 创建应用实例（CLI 模式排除 SIGINT，由 Session 处理）
 	app := lifecycle.New(
@@ -96,6 +106,7 @@ func chatAction(ctx context.Context, cmd *ucli.Command) error {
 			session.SetScheduleService(schedulerSvc.AppService())
 		}
 		session.ApproveStores = approve
+		session.SetProject(proj)
 		session.SetTemporary(temporarySession)
 		if resumeSession != "" {
 			session.SetResumeSessionID(resumeSession)
@@ -157,6 +168,9 @@ func createModeRunner(ctx context.Context, mode cliruntime.WorkMode) (runtimepor
 	case cliruntime.ModeGroup:
 		return appagent.CreateLoopAgentRunner(ctx)
 	default:
+		if name, ok := mode.Workflow(); ok {
+			return appagent.CreateWorkflowRunner(ctx, name)
+		}
 		return nil, nil
 	}
 }
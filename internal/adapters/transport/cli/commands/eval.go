@@ -0,0 +1,174 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	appagent "fkteams/internal/app/agent"
+	"fkteams/internal/app/config"
+	"fkteams/internal/app/eval"
+	"fkteams/internal/app/lifecycle"
+
+	"github.com/pterm/pterm"
+	ucli "github.com/urfave/cli/v3"
+)
+
+// evalCommand 创建 eval 子命令
+func evalCommand() *ucli.Command {
+	return &ucli.Command{
+		Name:  "eval",
+		Usage: "端到端评测智能体、提示词和技能",
+		Commands: []*ucli.Command{
+			{
+				Name:      "run",
+				Usage:     "运行 YAML 评测套件并输出 JUnit/JSON 报告",
+				ArgsUsage: "<suite.yaml>",
+				Flags: []ucli.Flag{
+					&ucli.StringFlag{
+						Name:  "cassette",
+						Value: string(eval.CassetteReplay),
+						Usage: "模型交互来源: replay（从磁带回放）|record（调用真实模型并录制）|live（调用真实模型，不录制）",
+					},
+					&ucli.StringSliceFlag{
+						Name:    "scenario",
+						Aliases: []string{"s"},
+						Usage:   "只运行指定场景，可多次指定",
+					},
+					&ucli.StringFlag{
+						Name:    "report",
+						Aliases: []string{"o"},
+						Usage:   "报告输出文件，默认不写文件",
+					},
+					&ucli.StringFlag{
+						Name:  "format",
+						Usage: "报告格式: junit|json，默认按报告文件扩展名判断（.xml 为 junit）",
+					},
+				},
+				Action: evalRunAction,
+			},
+		},
+	}
+}
+
+func evalRunAction(ctx context.Context, cmd *ucli.Command) error {
+	suitePath := cmd.Args().First()
+	if suitePath == "" {
+		return fmt.Errorf("请指定评测套件文件，例如: fkteams eval run evals/smoke.yaml")
+	}
+	mode, err := eval.ParseCassetteMode(cmd.String("cassette"))
+	if err != nil {
+		return err
+	}
+	format, err := evalReportFormat(cmd.String("format"), cmd.String("report"))
+	if err != nil {
+		return err
+	}
+	suite, err := eval.LoadSuite(suitePath)
+	if err != nil {
+		return err
+	}
+	if err := suite.Select(cmd.StringSlice("scenario")); err != nil {
+		return err
+	}
+	if err := config.InitAndValidate(); err != nil {
+		return err
+	}
+
+	app := lifecycle.New(
+		lifecycle.WithExitSignals(syscall.SIGTERM, syscall.SIGHUP),
+	)
+	state := app.State()
+
+	var report *eval.Report
+	app.OnReady(func(ctx context.Context) error {
+		defer app.Shutdown()
+		runCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+
+		pterm.DefaultSection.Printfln("评测套件 %s（%d 个场景，%s）", suite.Name, len(suite.Scenarios), mode)
+		report = eval.NewExecutor(appagent.Resolve, mode).OnResult(renderEvalResult).Run(runCtx, suite)
+		return nil
+	})
+	app.OnCleanup(func(ctx context.Context) error {
+		state.RunProcessCleanup()
+		return nil
+	})
+	if err := app.Run(ctx); err != nil {
+		return err
+	}
+	if report == nil {
+		return fmt.Errorf("评测未运行")
+	}
+
+	if path := cmd.String("report"); path != "" {
+		if err := writeEvalReport(path, format, report); err != nil {
+			return err
+		}
+		pterm.Info.Printfln("报告已写入 %s", path)
+	}
+	fmt.Println()
+	summary := fmt.Sprintf("共 %d 个场景：%d 通过，%d 失败，%d 出错（%s）", report.Total, report.Passed, report.Failed, report.Errors, report.Duration.Round(time.Millisecond))
+	if !report.OK() {
+		pterm.Error.Println(summary)
+		return fmt.Errorf("评测未全部通过")
+	}
+	pterm.Success.Println(summary)
+	return nil
+}
+
+// evalReportFormat 确定报告格式，未显式指定时按文件扩展名判断
+func evalReportFormat(format, path string) (string, error) {
+	switch format {
+	case "junit", "json":
+		return format, nil
+	case "":
+		if strings.EqualFold(filepath.Ext(path), ".xml") {
+			return "junit", nil
+		}
+		return "json", nil
+	default:
+		return "", fmt.Errorf("不支持的报告格式: %s（junit|json）", format)
+	}
+}
+
+func writeEvalReport(path, format string, report *eval.Report) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建报告目录失败: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建报告文件失败: %w", err)
+	}
+	var write func(io.Writer) error = report.WriteJSON
+	if format == "junit" {
+		write = report.WriteJUnit
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("写入报告失败: %w", err)
+	}
+	return file.Close()
+}
+
+// renderEvalResult 输出单个场景的结果
+func renderEvalResult(result eval.ScenarioResult) {
+	elapsed := result.Duration.Round(time.Millisecond)
+	switch {
+	case result.Error != "":
+		pterm.Error.Printfln("%s（%s）: %s", result.Name, elapsed, result.Error)
+	case result.Passed:
+		pterm.Success.Printfln("%s（%s）", result.Name, elapsed)
+	default:
+		pterm.Warning.Printfln("%s（%s）", result.Name, elapsed)
+		for _, failure := range result.Failures {
+			pterm.FgGray.Printfln("    - %s", failure)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package commands
+
+import "testing"
+
+func TestEvalReportFormat(t *testing.T) {
+	for _, tc := range []struct {
+		format, path, want string
+	}{
+		{"", "out/report.xml", "junit"},
+		{"", "out/report.json", "json"},
+		{"", "", "json"},
+		{"junit", "report.json", "junit"},
+	} {
+		got, err := evalReportFormat(tc.format, tc.path)
+		if err != nil || got != tc.want {
+			t.Fatalf("evalReportFormat(%q, %q) = %q, %v; want %q", tc.format, tc.path, got, err, tc.want)
+		}
+	}
+	if _, err := evalReportFormat("html", ""); err == nil {
+		t.Fatal("expected unsupported format error")
+	}
+}
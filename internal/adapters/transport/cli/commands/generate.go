@@ -2,8 +2,6 @@ package commands
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"fkteams/internal/app/appdata"
 	"fkteams/internal/app/config"
 	"fmt"
@@ -28,21 +26,7 @@ func generateCommand() *ucli.Command {
 					return nil
 				},
 			},
-			{
-				Name:  "apikey",
-				Usage: "生成 OpenAI 兼容 API 密钥",
-				Action: func(ctx context.Context, cmd *ucli.Command) error {
-					b := make([]byte, 24)
-					if _, err := rand.Read(b); err != nil {
-						return fmt.Errorf("failed to generate random bytes: %w", err)
-					}
-					key := "sk-fkteams-" + hex.EncodeToString(b)
-					fmt.Println(key)
-					fmt.Println("\n请将此密钥添加到 config.toml 的 [openai_api] 配置中:")
-					fmt.Println("  api_keys = [\"" + key + "\"]")
-					return nil
-				},
-			},
+			apiKeyCommand(),
 		},
 	}
 }
@@ -43,3 +43,36 @@ func TestDefaultOrNone(t *testing.T) {
 		t.Fatalf("defaultOrNone value = %q", got)
 	}
 }
+
+func TestGenerateAPIKeyCreateAndRevoke(t *testing.T) {
+	useTempAppDir(t)
+	output := captureStdout(t, func() {
+		args := []string{"fkteams", "apikey", "create", "--name", "ci", "--scope", "openai", "--scope", "chat", "--model", "gpt-4o", "--rate-limit", "30", "--days", "0"}
+		if err := generateCommand().Run(context.Background(), args); err != nil {
+			t.Fatalf("generate apikey create returned error: %v", err)
+		}
+	})
+	secret := strings.SplitN(strings.TrimSpace(output), "\n", 2)[0]
+	if !regexp.MustCompile(`^sk-fkteams-[0-9a-f]{48}$`).MatchString(secret) {
+		t.Fatalf("created key = %q", secret)
+	}
+
+	keys, err := cliAPIKeyService().List(context.Background())
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("List = %#v, %v", keys, err)
+	}
+	key := keys[0]
+	if key.Name != "ci" || len(key.Scopes) != 2 || key.RateLimit != 30 || key.ExpiresAt != nil || !key.AllowsModel("gpt-4o") || key.AllowsModel("other") {
+		t.Fatalf("stored key = %#v", key)
+	}
+	if strings.Contains(key.Hash, secret) {
+		t.Fatal("stored key must not contain the plaintext secret")
+	}
+
+	if err := generateCommand().Run(context.Background(), []string{"fkteams", "apikey", "revoke", key.ID}); err != nil {
+		t.Fatalf("generate apikey revoke returned error: %v", err)
+	}
+	if keys, err := cliAPIKeyService().List(context.Background()); err != nil || len(keys) != 0 {
+		t.Fatalf("keys after revoke = %#v, %v", keys, err)
+	}
+}
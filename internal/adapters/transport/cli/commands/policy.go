@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"fkteams/internal/app/config"
+	"fkteams/internal/app/project"
+	"fkteams/internal/app/tools"
+	"fkteams/internal/runtime/approval"
+
+	"github.com/pterm/pterm"
+	ucli "github.com/urfave/cli/v3"
+)
+
+// policyCommand 创建 policy 子命令
+func policyCommand() *ucli.Command {
+	return &ucli.Command{
+		Name:  "policy",
+		Usage: "工具权限策略",
+		Commands: []*ucli.Command{
+			{
+				Name:      "check",
+				Usage:     "离线测试工具调用命中的权限规则",
+				ArgsUsage: "<tool> [json-args]",
+				Flags: []ucli.Flag{
+					&ucli.StringFlag{
+						Name:  "agent",
+						Usage: "发起调用的智能体名",
+					},
+					&ucli.StringSliceFlag{
+						Name:  "file",
+						Usage: "指定策略文件，可多次指定；默认读取项目和全局策略",
+					},
+					&ucli.StringFlag{
+						Name:    "project",
+						Aliases: []string{"p"},
+						Usage:   "读取指定项目的策略，默认使用当前项目",
+					},
+				},
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					toolName := cmd.Args().Get(0)
+					if toolName == "" {
+						return fmt.Errorf("请指定工具名，例如: fkteams policy check execute '{\"command\":\"ls\"}'")
+					}
+					files := cmd.StringSlice("file")
+					if len(files) == 0 {
+						if err := config.Init(); err != nil {
+							return err
+						}
+						p, err := project.Resolve(cmd.String("project"))
+						if err != nil {
+							return err
+						}
+						files = tools.PolicyFiles(p.Root)
+					}
+					verdict, err := checkPolicy(files, approval.ToolCall{
+						Tool:      toolName,
+						Agent:     cmd.String("agent"),
+						Arguments: cmd.Args().Get(1),
+					})
+					if err != nil {
+						return err
+					}
+					renderPolicyVerdict(files, verdict)
+					return nil
+				},
+			},
+			{
+				Name:  "path",
+				Usage: "显示项目策略和全局策略文件的路径",
+				Flags: []ucli.Flag{
+					&ucli.StringFlag{
+						Name:    "project",
+						Aliases: []string{"p"},
+						Usage:   "显示指定项目的策略文件，默认使用当前项目",
+					},
+				},
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					if err := config.Init(); err != nil {
+						return err
+					}
+					p, err := project.Resolve(cmd.String("project"))
+					if err != nil {
+						return err
+					}
+					files := tools.PolicyFiles(p.Root)
+					fmt.Printf("项目策略: %s\n全局策略: %s\n", files[0], files[1])
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// checkPolicy 加载策略文件并评估一次工具调用
+func checkPolicy(files []string, call approval.ToolCall) (approval.Verdict, error) {
+	if call.Arguments == "" {
+		call.Arguments = "{}"
+	}
+	if !json.Valid([]byte(call.Arguments)) {
+		return approval.Verdict{}, fmt.Errorf("工具参数不是合法的 JSON: %s", call.Arguments)
+	}
+	policy, err := approval.LoadPolicyFiles(files...)
+	if err != nil {
+		return approval.Verdict{}, err
+	}
+	return policy.Evaluate(call), nil
+}
+
+// renderPolicyVerdict 输出策略评估结果
+func renderPolicyVerdict(files []string, verdict approval.Verdict) {
+	pterm.DefaultSection.Println("策略文件")
+	for _, file := range files {
+		pterm.FgGray.Printfln("  %s", file)
+	}
+	fmt.Println()
+	if verdict.Rule == nil {
+		pterm.Info.Println("未命中任何规则，按内置审批流程处理")
+		return
+	}
+	switch verdict.Effect {
+	case approval.EffectAllow:
+		pterm.Success.Printfln("allow: %s", verdict.Rule.Describe())
+	case approval.EffectAsk:
+		pterm.Warning.Printfln("ask: %s", verdict.Rule.Describe())
+	case approval.EffectDeny:
+		pterm.Error.Printfln("deny: %s", verdict.Rule.Describe())
+	}
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fkteams/internal/app/appdata"
+	"fkteams/internal/app/tools"
+	"fkteams/internal/runtime/approval"
+)
+
+func TestCheckPolicyUsesProjectBeforeGlobal(t *testing.T) {
+	useTempAppDir(t)
+	if err := os.MkdirAll(filepath.Dir(appdata.ProjectPolicyFile("")), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(appdata.ProjectPolicyFile(""), []byte("[[rules]]\nname = \"ws\"\neffect = \"allow\"\ntools = [\"execute\"]\ncommands = [\"make\"]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(appdata.PolicyFile(), []byte("[[rules]]\nname = \"global\"\neffect = \"ask\"\ntools = [\"execute\"]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	verdict, err := checkPolicy(tools.PolicyFiles(""), approval.ToolCall{Tool: "execute", Arguments: `{"command":"make build"}`})
+	if err != nil || verdict.Rule == nil || verdict.Rule.Name != "ws" {
+		t.Fatalf("verdict = %#v, err = %v", verdict, err)
+	}
+	verdict, err = checkPolicy(tools.PolicyFiles(""), approval.ToolCall{Tool: "execute", Arguments: `{"command":"rm x"}`})
+	if err != nil || verdict.Effect != approval.EffectAsk {
+		t.Fatalf("verdict = %#v, err = %v", verdict, err)
+	}
+	verdict, err = checkPolicy(tools.PolicyFiles(""), approval.ToolCall{Tool: "file_read"})
+	if err != nil || verdict.Rule != nil {
+		t.Fatalf("unmatched verdict = %#v, err = %v", verdict, err)
+	}
+
+	if _, err := checkPolicy(tools.PolicyFiles(""), approval.ToolCall{Tool: "execute", Arguments: "{bad"}); err == nil || !strings.Contains(err.Error(), "JSON") {
+		t.Fatalf("invalid args err = %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"fkteams/internal/app/config"
+	"fkteams/internal/app/project"
+
+	"github.com/pterm/pterm"
+	ucli "github.com/urfave/cli/v3"
+)
+
+// projectCommand 创建 project 子命令
+func projectCommand() *ucli.Command {
+	return &ucli.Command{
+		Name:  "project",
+		Usage: "具名工作区（项目）管理",
+		Commands: []*ucli.Command{
+			{
+				Name:      "add",
+				Usage:     "注册项目目录",
+				ArgsUsage: "<name> <root>",
+				Flags: []ucli.Flag{
+					&ucli.StringFlag{
+						Name:  "description",
+						Usage: "项目说明",
+					},
+					&ucli.StringFlag{
+						Name:  "agent",
+						Usage: "在该项目中运行时默认使用的 Agent",
+					},
+					&ucli.StringFlag{
+						Name:  "mode",
+						Usage: "在该项目中运行时默认使用的工作模式: team|deep|group|workflow:<名称>",
+					},
+					&ucli.StringSliceFlag{
+						Name:  "approve",
+						Usage: "在该项目中自动批准的操作类别，可重复指定",
+					},
+					&ucli.StringSliceFlag{
+						Name:  "env",
+						Usage: "执行命令时注入的环境变量 KEY=VALUE，可重复指定",
+					},
+					&ucli.StringFlag{
+						Name:  "sandbox",
+						Usage: "在该项目中执行命令和脚本时使用的沙箱配置名",
+					},
+				},
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					if cmd.Args().Len() != 2 {
+						return fmt.Errorf("用法: fkteams project add <name> <root>")
+					}
+					if err := config.Init(); err != nil {
+						return err
+					}
+					env, err := parseProjectEnv(cmd.StringSlice("env"))
+					if err != nil {
+						return err
+					}
+					p, err := project.Add(project.Project{
+						Name:        cmd.Args().Get(0),
+						Root:        cmd.Args().Get(1),
+						Description: cmd.String("description"),
+						Agent:       cmd.String("agent"),
+						Mode:        cmd.String("mode"),
+						AutoApprove: cmd.StringSlice("approve"),
+						Env:         env,
+						Sandbox:     cmd.String("sandbox"),
+					})
+					if err != nil {
+						return err
+					}
+					pterm.Success.Printfln("已注册项目 %s: %s", p.Name, p.Root)
+					return nil
+				},
+			},
+			{
+				Name:    "ls",
+				Aliases: []string{"list"},
+				Usage:   "列出所有项目",
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					if err := config.Init(); err != nil {
+						return err
+					}
+					return listProjects()
+				},
+			},
+			{
+				Name:      "use",
+				Usage:     "切换当前项目",
+				ArgsUsage: "<name>",
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					if cmd.Args().Len() != 1 {
+						return fmt.Errorf("用法: fkteams project use <name>")
+					}
+					if err := config.Init(); err != nil {
+						return err
+					}
+					p, err := project.Use(cmd.Args().First())
+					if err != nil {
+						return err
+					}
+					pterm.Success.Printfln("当前项目: %s (%s)", p.Name, p.Root)
+					return nil
+				},
+			},
+			{
+				Name:      "rm",
+				Aliases:   []string{"remove"},
+				Usage:     "移除项目注册（不会删除项目目录）",
+				ArgsUsage: "<name>",
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					if cmd.Args().Len() != 1 {
+						return fmt.Errorf("用法: fkteams project rm <name>")
+					}
+					if err := config.Init(); err != nil {
+						return err
+					}
+					if err := project.Remove(cmd.Args().First()); err != nil {
+						return err
+					}
+					pterm.Success.Printfln("已移除项目 %s", cmd.Args().First())
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// listProjects 以表格输出项目列表，当前项目以 * 标记
+func listProjects() error {
+	data := [][]string{{"", "名称", "目录", "模式", "Agent", "说明"}}
+	for _, p := range project.List() {
+		marker := ""
+		if p.Current {
+			marker = "*"
+		}
+		data = append(data, []string{marker, p.Name, p.Root, valueOrDash(p.Mode), valueOrDash(p.Agent), valueOrDash(p.Description)})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// parseProjectEnv 解析 KEY=VALUE 形式的环境变量参数
+func parseProjectEnv(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	env := make(map[string]string, len(values))
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("环境变量格式应为 KEY=VALUE: %q", value)
+		}
+		env[key] = val
+	}
+	return env, nil
+}
+
+// projectApproveStores 将项目的 auto_approve 合并到命令行 --approve 参数
+func projectApproveStores(approve string, p project.Project) string {
+	if len(p.AutoApprove) == 0 {
+		return approve
+	}
+	stores := strings.Join(p.AutoApprove, ",")
+	if approve == "" {
+		return stores
+	}
+	return approve + "," + stores
+}
@@ -27,6 +27,13 @@ func Root() *ucli.Command {
 			loginCommand(),
 			logoutCommand(),
 			authCommand(),
+			usageCommand(),
+			policyCommand(),
+			projectCommand(),
+			evalCommand(),
+			workflowCommand(),
+			secretCommand(),
+			auditCommand(),
 		},
 		Flags: []ucli.Flag{
 			&ucli.StringFlag{
@@ -43,16 +50,21 @@ func Root() *ucli.Command {
 				Name:    "mode",
 				Aliases: []string{"m"},
 				Value:   "team",
-				Usage:   "工作模式: team|deep|group",
+				Usage:   "工作模式: team|deep|group|workflow:<名称>",
 			},
 			&ucli.BoolFlag{
 				Name:    "temporary",
 				Aliases: []string{"temp"},
 				Usage:   "开启临时会话，不保存聊天历史且不显示恢复命令",
 			},
+			&ucli.StringFlag{
+				Name:    "project",
+				Aliases: []string{"p"},
+				Usage:   "在指定项目的工作区中运行（默认为当前项目）",
+			},
 			&ucli.StringFlag{
 				Name:  "approve",
-				Usage: "自动批准指定操作类别 (all/command/file/git/dispatch，逗号分隔)",
+				Usage: "自动批准指定操作类别 (all/command/file/git/dispatch/memory/policy，逗号分隔)",
 			},
 		},
 		Action: chatAction,
@@ -20,7 +20,7 @@ func TestRootCommandStructure(t *testing.T) {
 	for _, sub := range cmd.Commands {
 		commandNames = append(commandNames, sub.Name)
 	}
-	for _, want := range []string{"web", "serve", "session", "update", "init", "generate", "agent", "tool", "skill", "model", "login", "logout", "auth"} {
+	for _, want := range []string{"web", "serve", "session", "update", "init", "generate", "agent", "tool", "skill", "model", "login", "logout", "auth", "usage", "policy", "project", "eval", "workflow", "secret", "audit"} {
 		if !slices.Contains(commandNames, want) {
 			t.Fatalf("root commands = %#v, missing %q", commandNames, want)
 		}
@@ -30,7 +30,7 @@ func TestRootCommandStructure(t *testing.T) {
 	for _, flag := range cmd.Flags {
 		flagNames = append(flagNames, flag.Names()[0])
 	}
-	for _, want := range []string{"query", "resume", "mode", "temporary", "approve"} {
+	for _, want := range []string{"query", "resume", "mode", "temporary", "project", "approve"} {
 		if !slices.Contains(flagNames, want) {
 			t.Fatalf("root flags = %#v, missing %q", flagNames, want)
 		}
@@ -47,8 +47,11 @@ func TestSubCommandStructure(t *testing.T) {
 		{name: "generate", command: generateCommand(), children: []string{"config", "apikey"}},
 		{name: "model", command: modelCommand(), children: []string{"ls", "lr", "sw", "rm"}},
 		{name: "session", command: sessionCommand(), children: []string{"list"}},
+		{name: "secret", command: secretCommand(), children: []string{"set", "list", "rm"}},
+		{name: "audit", command: auditCommand(), children: []string{"verify"}, flags: []string{"tool", "agent", "user", "session", "status", "from", "to", "limit"}},
 		{name: "agent", command: agentCommand(), children: []string{"list"}, flags: []string{"name", "query", "temporary", "format", "approve"}},
 		{name: "tool", command: toolCommand(), children: []string{"list"}},
+		{name: "project", command: projectCommand(), children: []string{"add", "ls", "use", "rm"}},
 		{name: "auth", command: authCommand(), children: []string{"enable", "disable", "status"}},
 		{name: "login", command: loginCommand(), children: []string{"copilot", "openai", "deepseek", "claude", "gemini", "qwen", "ollama", "ark", "openrouter", "custom"}},
 		{name: "logout", command: logoutCommand(), children: []string{"copilot", "openai", "deepseek", "claude", "gemini", "qwen", "ollama", "ark", "openrouter", "custom"}},
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"fkteams/internal/adapters/transport/cli/tui"
+	"fkteams/internal/app/appdata"
+	"fkteams/internal/runtime/env"
+	"fkteams/internal/runtime/secrets"
+
+	"github.com/mattn/go-isatty"
+	"github.com/pterm/pterm"
+	ucli "github.com/urfave/cli/v3"
+)
+
+// maxSecretInputBytes 限制从标准输入读取的密钥长度，与密钥库单条上限一致
+const maxSecretInputBytes = 64 << 10
+
+// secretCommand 创建 secret 子命令，管理 config.toml 中 secret://name 引用的加密密钥库
+func secretCommand() *ucli.Command {
+	return &ucli.Command{
+		Name:  "secret",
+		Usage: "管理加密密钥库，配置中以 secret://<name> 引用",
+		Commands: []*ucli.Command{
+			{
+				Name:      "set",
+				Usage:     "写入或更新密钥；省略值时从终端输入或标准输入读取",
+				ArgsUsage: "<name> [value]",
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					name := cmd.Args().Get(0)
+					if name == "" || cmd.Args().Len() > 2 {
+						return fmt.Errorf("用法: fkteams secret set <name> [value]")
+					}
+					if err := secrets.ValidateName(name); err != nil {
+						return err
+					}
+					value := cmd.Args().Get(1)
+					if cmd.Args().Len() < 2 {
+						var err error
+						if value, err = readSecretValue(name); err != nil {
+							return err
+						}
+					}
+					if value == "" {
+						return fmt.Errorf("密钥值不能为空")
+					}
+					vault := cliVault()
+					if err := vault.Set(name, value); err != nil {
+						return err
+					}
+					pterm.Success.Printfln("已保存密钥 %s，在配置中使用 %s%s 引用", name, secrets.RefPrefix, name)
+					return nil
+				},
+			},
+			{
+				Name:    "list",
+				Aliases: []string{"ls"},
+				Usage:   "列出密钥名称，不显示明文",
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					vault := cliVault()
+					entries, err := vault.List()
+					if err != nil {
+						return err
+					}
+					if len(entries) == 0 {
+						pterm.Warning.Println("密钥库为空，使用 fkteams secret set <name> 添加密钥")
+						return nil
+					}
+					source, err := vault.Source()
+					if err != nil {
+						return err
+					}
+					pterm.Info.Printfln("密钥库: %s (主密钥来源: %s)", vault.Path(), source)
+					data := [][]string{{"名称", "引用", "更新时间"}}
+					for _, entry := range entries {
+						data = append(data, []string{
+							entry.Name,
+							secrets.RefPrefix + entry.Name,
+							entry.UpdatedAt.Local().Format("2006-01-02 15:04"),
+						})
+					}
+					return pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+				},
+			},
+			{
+				Name:      "rm",
+				Aliases:   []string{"delete"},
+				Usage:     "删除密钥",
+				ArgsUsage: "<name>",
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					name := cmd.Args().First()
+					if name == "" {
+						return fmt.Errorf("请指定密钥名称")
+					}
+					if err := cliVault().Delete(name); err != nil {
+						if errors.Is(err, secrets.ErrNotFound) {
+							return fmt.Errorf("密钥 %s 不存在", name)
+						}
+						return err
+					}
+					pterm.Success.Printfln("已删除密钥 %s，仍引用它的配置将无法加载", name)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func cliVault() *secrets.Vault {
+	return secrets.Open(appdata.SecretsFile(), secrets.Options{
+		Keyring:    secrets.SystemKeyring(),
+		Passphrase: promptVaultPassphrase,
+	})
+}
+
+// promptVaultPassphrase 在终端中读取密钥库口令，创建密钥库时要求再次输入确认
+func promptVaultPassphrase(create bool) (string, error) {
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		return "", fmt.Errorf("%w: 非交互环境请设置 %s", secrets.ErrLocked, env.VaultPassphrase)
+	}
+	if !create {
+		return tui.ReadSecret("输入密钥库口令")
+	}
+	pterm.Info.Println("系统钥匙串不可用，将使用口令加密密钥库")
+	passphrase, err := tui.ReadSecret("设置密钥库口令")
+	if err != nil {
+		return "", err
+	}
+	confirm, err := tui.ReadSecret("再次输入口令")
+	if err != nil {
+		return "", err
+	}
+	if passphrase != confirm {
+		return "", fmt.Errorf("两次输入的口令不一致")
+	}
+	return passphrase, nil
+}
+
+// readSecretValue 从终端掩码输入或标准输入读取密钥值，避免明文出现在命令行历史中
+func readSecretValue(name string) (string, error) {
+	if isatty.IsTerminal(os.Stdin.Fd()) {
+		return tui.ReadSecret("输入 " + name + " 的值")
+	}
+	data, err := io.ReadAll(io.LimitReader(os.Stdin, maxSecretInputBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("读取标准输入失败: %w", err)
+	}
+	if len(data) > maxSecretInputBytes {
+		return "", fmt.Errorf("密钥值超过 %d 字节", maxSecretInputBytes)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
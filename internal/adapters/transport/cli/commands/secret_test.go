@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"fkteams/internal/app/appdata"
+	"fkteams/internal/runtime/env"
+	"fkteams/internal/runtime/secrets"
+)
+
+func TestSecretSetListRemove(t *testing.T) {
+	useTempAppDir(t)
+	t.Setenv(env.VaultPassphrase, "vault-passphrase")
+
+	if err := secretCommand().Run(context.Background(), []string{"fkteams", "set", "openai", "sk-vault"}); err != nil {
+		t.Fatalf("secret set returned error: %v", err)
+	}
+	value, err := secrets.Open(appdata.SecretsFile(), secrets.Options{}).Get("openai")
+	if err != nil || value != "sk-vault" {
+		t.Fatalf("vault value = %q, err = %v", value, err)
+	}
+
+	if err := secretCommand().Run(context.Background(), []string{"fkteams", "list"}); err != nil {
+		t.Fatalf("secret list returned error: %v", err)
+	}
+
+	if err := secretCommand().Run(context.Background(), []string{"fkteams", "rm", "openai"}); err != nil {
+		t.Fatalf("secret rm returned error: %v", err)
+	}
+	if err := secretCommand().Run(context.Background(), []string{"fkteams", "rm", "openai"}); err == nil {
+		t.Fatal("removing a missing secret should fail")
+	}
+	if err := secretCommand().Run(context.Background(), []string{"fkteams", "set", "../bad", "x"}); err == nil {
+		t.Fatal("invalid secret name should be rejected")
+	}
+}
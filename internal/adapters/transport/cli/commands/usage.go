@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	fileusage "fkteams/internal/adapters/storage/file/usage"
+	"fkteams/internal/app/appdata"
+	"fkteams/internal/app/config"
+	appusage "fkteams/internal/app/usage"
+	domainusage "fkteams/internal/domain/usage"
+
+	"github.com/pterm/pterm"
+	ucli "github.com/urfave/cli/v3"
+)
+
+// usageCommand 创建 usage 子命令
+func usageCommand() *ucli.Command {
+	return &ucli.Command{
+		Name:  "usage",
+		Usage: "查看模型用量与费用统计",
+		Flags: []ucli.Flag{
+			&ucli.StringFlag{
+				Name:  "by",
+				Value: string(domainusage.ByModel),
+				Usage: "汇总维度 (session, task, agent, model, day, channel)",
+			},
+			&ucli.StringFlag{
+				Name:  "from",
+				Usage: "起始日期 YYYY-MM-DD（包含）",
+			},
+			&ucli.StringFlag{
+				Name:  "to",
+				Usage: "结束日期 YYYY-MM-DD（包含）",
+			},
+			&ucli.StringFlag{
+				Name:  "session",
+				Usage: "仅统计指定会话",
+			},
+		},
+		Action: func(ctx context.Context, cmd *ucli.Command) error {
+			if err := config.Init(); err != nil {
+				return err
+			}
+			from, to, err := appusage.ParseDayRange(cmd.String("from"), cmd.String("to"))
+			if err != nil {
+				return err
+			}
+			ledger := appusage.NewLedger(fileusage.NewStore(appdata.UsageDir()), appusage.SettingsFromConfig)
+			summary, err := ledger.Summary(ctx, domainusage.Filter{From: from, To: to, SessionID: cmd.String("session")}, domainusage.Dimension(cmd.String("by")))
+			if err != nil {
+				return err
+			}
+			return renderUsageSummary(summary)
+		},
+	}
+}
+
+// renderUsageSummary 以表格输出用量汇总
+func renderUsageSummary(summary domainusage.Summary) error {
+	if len(summary.Groups) == 0 {
+		pterm.Warning.Println("暂无用量记录")
+		return nil
+	}
+	data := [][]string{{string(summary.By), "请求数", "输入 tokens", "输出 tokens", "总 tokens", "费用"}}
+	row := func(key string, totals domainusage.Totals) []string {
+		return []string{
+			key,
+			fmt.Sprint(totals.Requests),
+			fmt.Sprint(totals.PromptTokens),
+			fmt.Sprint(totals.CompletionTokens),
+			fmt.Sprint(totals.TotalTokens),
+			fmt.Sprintf("%.4f", totals.Cost),
+		}
+	}
+	for _, group := range summary.Groups {
+		key := group.Key
+		if key == "" {
+			key = "-"
+		}
+		data = append(data, row(key, group.Totals))
+	}
+	data = append(data, row("合计", summary.Totals))
+	return pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
@@ -17,6 +17,7 @@ func webCommand() *ucli.Command {
 			if err := config.Init(); err != nil {
 				return err
 			}
+			warnAuditUnavailable(ctx)
 			return httpserver.RunContext(ctx)
 		},
 	}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"fkteams/internal/app/appdata"
+	"fkteams/internal/app/workflow"
+
+	"github.com/pterm/pterm"
+	ucli "github.com/urfave/cli/v3"
+)
+
+// workflowCommand 创建 workflow 子命令
+func workflowCommand() *ucli.Command {
+	return &ucli.Command{
+		Name:  "workflow",
+		Usage: "管理声明式工作流，使用 -m workflow:<名称> 运行",
+		Commands: []*ucli.Command{
+			{
+				Name:    "ls",
+				Aliases: []string{"list"},
+				Usage:   "列出工作流目录中的定义",
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					return listWorkflows()
+				},
+			},
+			{
+				Name:      "show",
+				Usage:     "校验并展示工作流的节点",
+				ArgsUsage: "<name>",
+				Action: func(ctx context.Context, cmd *ucli.Command) error {
+					if cmd.Args().Len() != 1 {
+						return fmt.Errorf("用法: fkteams workflow show <name>")
+					}
+					def, err := workflow.Load(cmd.Args().First())
+					if err != nil {
+						return err
+					}
+					return showWorkflow(def)
+				},
+			},
+		},
+	}
+}
+
+func listWorkflows() error {
+	entries, err := workflow.List()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		pterm.Info.Printfln("%s 中没有工作流定义", appdata.WorkflowsDir())
+		return nil
+	}
+	data := [][]string{{"名称", "节点", "说明"}}
+	for _, entry := range entries {
+		if entry.Err != nil {
+			data = append(data, []string{entry.Name, "-", pterm.Red(entry.Err.Error())})
+			continue
+		}
+		data = append(data, []string{entry.Name, fmt.Sprint(len(entry.Definition.Nodes)), valueOrDash(entry.Definition.Description)})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
+func showWorkflow(def *workflow.Definition) error {
+	pterm.DefaultSection.Printfln("工作流 %s", def.Name)
+	if def.Description != "" {
+		fmt.Println(def.Description)
+	}
+	data := [][]string{{"节点", "类型", "智能体", "依赖", "条件"}}
+	for _, node := range def.Nodes {
+		depends := strings.Join(node.Depends, ", ")
+		if len(node.Depends) > 1 && node.Join == workflow.JoinAny {
+			depends += "（任一）"
+		}
+		when := ""
+		if node.When != nil {
+			when = "依据 " + node.When.Node
+		}
+		data = append(data, []string{node.ID, string(node.Type), valueOrDash(node.Agent), valueOrDash(depends), valueOrDash(when)})
+	}
+	return pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
@@ -174,6 +174,9 @@ func formatSchedulerResult(content string, toolName string) string {
 				OneTime   bool   `json:"one_time"`
 				NextRunAt string `json:"next_run_at"`
 				Status    string `json:"status"`
+				Trigger   *struct {
+					Type string `json:"type"`
+				} `json:"trigger"`
 			} `json:"task"`
 		}
 		if err := json.Unmarshal([]byte(content), &result); err != nil {
@@ -188,7 +191,11 @@ func formatSchedulerResult(content string, toolName string) string {
 			if result.Task.CronExpr != "" {
 				fmt.Fprintf(&output, "  Cron: %s\n", result.Task.CronExpr)
 			}
-			fmt.Fprintf(&output, "  下次执行: %s\n", formatTime(result.Task.NextRunAt))
+			if result.Task.Trigger != nil {
+				fmt.Fprintf(&output, "  触发方式: %s\n", result.Task.Trigger.Type)
+			} else {
+				fmt.Fprintf(&output, "  下次执行: %s\n", formatTime(result.Task.NextRunAt))
+			}
 		}
 
 	case "schedule_list":
@@ -205,6 +212,9 @@ func formatSchedulerResult(content string, toolName string) string {
 				Status    string `json:"status"`
 				LastRunAt string `json:"last_run_at"`
 				Result    string `json:"result"`
+				Trigger   *struct {
+					Type string `json:"type"`
+				} `json:"trigger"`
 			} `json:"tasks"`
 		}
 		if err := json.Unmarshal([]byte(content), &result); err != nil {
@@ -233,7 +243,11 @@ func formatSchedulerResult(content string, toolName string) string {
 				if t.CronExpr != "" {
 					fmt.Fprintf(&output, "     Cron: %s\n", t.CronExpr)
 				}
-				fmt.Fprintf(&output, "     下次执行: %s\n", formatTime(t.NextRunAt))
+				if t.Trigger != nil {
+					fmt.Fprintf(&output, "     触发方式: %s\n", t.Trigger.Type)
+				} else {
+					fmt.Fprintf(&output, "     下次执行: %s\n", formatTime(t.NextRunAt))
+				}
 			}
 		}
 
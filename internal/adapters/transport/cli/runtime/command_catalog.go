@@ -13,12 +13,14 @@ var allCommands = []CommandInfo{
 	{Name: "help", Desc: "显示帮助信息", Category: "基础"},
 	{Name: "quit", Desc: "退出程序", Category: "基础"},
 	{Name: "list_agents", Desc: "列出所有可用的智能体", Category: "基础"},
-	{Name: "switch_work_mode", Desc: "切换工作模式", Usage: "[team|deep|group]", Category: "基础"},
+	{Name: "switch_work_mode", Desc: "切换工作模式", Usage: "[team|deep|group|workflow:<名称>]", Category: "基础"},
 
 	{Name: "list_chat_history", Desc: "列出所有聊天历史会话", Category: "会话"},
 	{Name: "load_chat_history", Desc: "选择并加载聊天历史会话", Usage: "[SESSION_ID]", Category: "会话"},
 	{Name: "save_chat_history", Desc: "保存聊天历史到当前会话文件", Category: "会话"},
 	{Name: "clear_chat_history", Desc: "清空当前聊天历史", Category: "会话"},
+	{Name: "fork", Desc: "从指定记录事件（默认最新）分叉出新会话并切换过去", Usage: "[EVENT_ID]", Category: "会话"},
+	{Name: "rewind", Desc: "回退到某条用户消息之前，并把该消息放回输入框以便编辑重发", Usage: "[EVENT_ID]", Category: "会话"},
 	{Name: "save_chat_history_to_html", Desc: "导出聊天历史为 HTML 文件", Category: "会话"},
 	{Name: "save_chat_history_to_markdown", Desc: "导出聊天历史为 Markdown 文件", Category: "会话"},
 
@@ -26,8 +28,9 @@ var allCommands = []CommandInfo{
 	{Name: "cancel_schedule", Desc: "选择并取消定时任务", Usage: "[TASK_ID]", Category: "定时任务"},
 	{Name: "delete_schedule", Desc: "选择并删除定时任务", Usage: "[TASK_ID]", Category: "定时任务"},
 
-	{Name: "list_memory", Desc: "列出所有长期记忆条目", Category: "长期记忆"},
+	{Name: "list_memory", Desc: "列出长期记忆条目，可按作用域过滤", Usage: "[SCOPE]", Category: "长期记忆"},
 	{Name: "delete_memory", Desc: "选择并删除记忆条目", Usage: "[SUMMARY]", Category: "长期记忆"},
+	{Name: "move_memory", Desc: "选择记忆条目并移动到指定作用域", Usage: "SCOPE [SUMMARY]", Category: "长期记忆"},
 	{Name: "clear_memory", Desc: "清空所有长期记忆", Category: "长期记忆"},
 }
 
@@ -6,6 +6,8 @@ import (
 	"os"
 	"regexp"
 	"strings"
+
+	appagent "fkteams/internal/app/agent"
 )
 
 const maxPipeInputBytes int64 = 32 << 20
@@ -75,12 +77,23 @@ func (m WorkMode) GetPromptPrefix() string {
 	case ModeGroup:
 		return "多智能体讨论模式> "
 	default:
+		if name, ok := m.Workflow(); ok {
+			return fmt.Sprintf("工作流 %s> ", name)
+		}
 		return "未知模式> "
 	}
 }
 
-// ParseWorkMode 解析工作模式
+// Workflow 返回 workflow:<名称> 模式对应的工作流名称。
+func (m WorkMode) Workflow() (string, bool) {
+	return appagent.WorkflowName(string(m))
+}
+
+// ParseWorkMode 解析工作模式，workflow:<名称> 原样保留
 func ParseWorkMode(mode string) WorkMode {
+	if _, ok := appagent.WorkflowName(mode); ok {
+		return WorkMode(mode)
+	}
 	switch mode {
 	case "team":
 		return ModeTeam
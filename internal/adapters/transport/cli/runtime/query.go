@@ -18,6 +18,7 @@ import (
 	appchat "fkteams/internal/app/chat"
 	appschedule "fkteams/internal/app/schedule"
 	"fkteams/internal/app/tools/ask"
+	appusage "fkteams/internal/app/usage"
 	domainmessage "fkteams/internal/domain/message"
 	"fkteams/internal/domain/session"
 	runtimeport "fkteams/internal/ports/runtime"
@@ -115,7 +116,7 @@ func (e *QueryExecutor) SetAutoReject(v bool) {
 	e.autoReject = v
 }
 
-// SetApproveStores 设置自动批准的操作类别（逗号分隔: all/command/file/dispatch）
+// SetApproveStores 设置自动批准的操作类别（逗号分隔: all/command/file/git/dispatch/memory）
 func (e *QueryExecutor) SetApproveStores(s string) {
 	if s == "" {
 		return
@@ -257,13 +258,13 @@ func (e *QueryExecutor) Execute(ctx context.Context, input string) error {
 	}
 	recorder := session.recorder()
 	recorder.SetToolDisplayResolver(toolmeta.ResolverFromContext(ctx))
-	turnInput := appchat.BuildTurnInputWithMemory(recorder, input, e.memory)
+	turnInput := appchat.BuildTurnInputWithMemory(recorder, input, e.memory, session.memoryScope())
 
 	// 缓存第一次输入作为会话标题（不立即创建文件，等用户保存时才写入）
 	session.setTitleFromInput(input)
 
 	// 创建可取消的 context
-	queryCtx, cancelFunc := context.WithCancel(ctx)
+	queryCtx, cancelFunc := context.WithCancel(appusage.WithScope(ctx, appusage.Scope{Channel: appusage.ChannelCLI}))
 
 	e.view.Start(input)
 	innerCallback := e.view.EventCallback(recorder)
@@ -334,6 +335,8 @@ func (e *QueryExecutor) Execute(ctx context.Context, input string) error {
 			ApprovalRegistry: approvalReg,
 			SteeringSource:   steeringSource,
 			AskHandler:       e.askRuntime,
+			Memory:           e.memory,
+			MemoryScope:      session.memoryScope(),
 			ContextHooks: []appchat.ContextHook{
 				func(ctx context.Context) context.Context {
 					return appschedule.WithService(ctx, e.scheduler)
@@ -349,7 +352,7 @@ func (e *QueryExecutor) Execute(ctx context.Context, input string) error {
 			if queryCtx.Err() != nil {
 				recorder.RecordCancelled("任务已取消")
 				store := eventlog.NewChatSessionStore(session.historyDir)
-				appchat.LogLifecycleError("cli", session.sessionID(), appchat.NewSessionLifecycle(store, store).SaveActive(context.Background(), session.sessionID(), session.sessionTitle, recorder))
+				appchat.LogLifecycleError("cli", session.sessionID(), appchat.NewSessionLifecycle(store, store).SaveActive(session.withProject(context.Background()), session.sessionID(), session.sessionTitle, recorder))
 				e.view.Interrupted()
 				return nil
 			}
@@ -358,7 +361,7 @@ func (e *QueryExecutor) Execute(ctx context.Context, input string) error {
 		}
 
 		if next, ok := e.drainSteeringMessage(); ok && queryCtx.Err() == nil {
-			currentInput = appchat.BuildTurnInputWithMemory(recorder, next.DisplayText(), e.memory)
+			currentInput = appchat.BuildTurnInputWithMemory(recorder, next.DisplayText(), e.memory, session.memoryScope())
 			continue
 		}
 		break
@@ -368,7 +371,7 @@ func (e *QueryExecutor) Execute(ctx context.Context, input string) error {
 		e.view.Flush()
 	}
 
-	appchat.ExtractMemoryAsync(e.memory, eventlog.ConvertMemoryMessages(recorder), session.sessionID())
+	appchat.ExtractMemoryAsync(e.memory, eventlog.ConvertMemoryMessages(recorder), session.sessionID(), session.memoryScope())
 
 	elapsed := time.Since(startTime).Round(time.Millisecond)
 	e.view.Done(elapsed)
@@ -470,7 +473,7 @@ func (s *Session) FlushMemoryWithManager(ctx context.Context, manager appstate.M
 	recorder := s.recorder()
 	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
 	defer cancel()
-	appchat.FlushMemory(ctx, manager, eventlog.ConvertMemoryMessages(recorder), s.sessionID())
+	appchat.FlushMemory(ctx, manager, eventlog.ConvertMemoryMessages(recorder), s.sessionID(), s.memoryScope())
 }
 
 // SaveHistory 保存当前 CLI 实例的可恢复会话历史。
@@ -0,0 +1,122 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	eventlog "fkteams/internal/adapters/storage/file/history"
+	appsession "fkteams/internal/app/session"
+)
+
+// sessionBranchService 返回操作当前历史目录的会话服务。
+func (s *Session) sessionBranchService() *appsession.Service {
+	return appsession.NewService(eventlog.NewSessionRepository(s.historyDir))
+}
+
+// persistForBranch 在分叉或回退前落盘当前会话，保证磁盘记录包含最新事件。
+func (m runtimeModel) persistForBranch(title string) (runtimeModel, bool) {
+	session := m.runtime.session
+	if session.isTemporary() {
+		m.appendBlock(runtimeBlockError, title, "临时会话不保存记录")
+		return m, false
+	}
+	if !session.SaveHistory(session.withProject(context.Background())) {
+		m.appendBlock(runtimeBlockError, title, "当前会话还没有可用的记录")
+		return m, false
+	}
+	return m, true
+}
+
+// sessionUserMessages 按从新到旧返回当前会话主记录中的用户消息。
+func (s *Session) sessionUserMessages() ([]eventlog.TranscriptEvent, error) {
+	records, err := eventlog.LoadSessionTranscriptRecords(filepath.Join(s.historyDir, s.sessionID()))
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]eventlog.TranscriptEvent, 0)
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Member == nil && records[i].Event.Type == eventlog.TranscriptUserMessage {
+			messages = append(messages, records[i].Event)
+		}
+	}
+	return messages, nil
+}
+
+// lastSessionEventID 返回当前会话主记录的最后一条事件 ID。
+func (s *Session) lastSessionEventID() (string, error) {
+	records, err := eventlog.LoadSessionTranscriptRecords(filepath.Join(s.historyDir, s.sessionID()))
+	if err != nil {
+		return "", err
+	}
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Member == nil && records[i].Event.ID != "" {
+			return records[i].Event.ID, nil
+		}
+	}
+	return "", fmt.Errorf("当前会话没有记录")
+}
+
+func (m runtimeModel) forkRuntimeSession(eventID string) runtimeModel {
+	m, ok := m.persistForBranch("分叉会话失败")
+	if !ok {
+		return m
+	}
+	session := m.runtime.session
+	if eventID == "" {
+		var err error
+		if eventID, err = session.lastSessionEventID(); err != nil {
+			m.appendBlock(runtimeBlockError, "分叉会话失败", err.Error())
+			return m
+		}
+	}
+	sourceID := session.sessionID()
+	forked, err := session.sessionBranchService().Fork(context.Background(), appsession.ForkRequest{SessionID: sourceID, EventID: eventID})
+	if err != nil {
+		m.appendBlock(runtimeBlockError, "分叉会话失败", err.Error())
+		return m
+	}
+	session.sessionTitle = forked.Title
+	m = m.loadRuntimeSession(forked.ID)
+	m.appendBlock(runtimeBlockSystem, "分叉会话", fmt.Sprintf("已从 %s 分叉出新会话 %s，原会话保持不变", sourceID, forked.ID))
+	return m
+}
+
+func (m runtimeModel) rewindRuntimeSession(eventID string) runtimeModel {
+	m, ok := m.persistForBranch("回退会话失败")
+	if !ok {
+		return m
+	}
+	session := m.runtime.session
+	sessionID := session.sessionID()
+	if !session.historyManager.Remove(sessionID) {
+		m.appendBlock(runtimeBlockError, "回退会话失败", "会话正在使用中")
+		return m
+	}
+	result, err := session.sessionBranchService().Rewind(context.Background(), appsession.RewindRequest{SessionID: sessionID, EventID: eventID})
+	if err != nil {
+		m.appendBlock(runtimeBlockError, "回退会话失败", err.Error())
+		return m
+	}
+	m = m.loadRuntimeSession(sessionID)
+	m.appendBlock(runtimeBlockSystem, "回退会话", fmt.Sprintf("已丢弃 %d 条记录，被撤回的消息已放回输入框，可编辑后重新发送", result.Removed))
+	m.input.SetValue(result.Message)
+	m.input.CursorEnd()
+	return m
+}
+
+func newRewindPicker(session *Session) (*runtimePicker, error) {
+	messages, err := session.sessionUserMessages()
+	if err != nil {
+		return nil, err
+	}
+	items := make([]runtimePickerItem, 0, len(messages))
+	for _, event := range messages {
+		items = append(items, runtimePickerItem{
+			Label: fmt.Sprintf("%s - %s", event.At.Local().Format("01-02 15:04:05"), truncateTitle(strings.Join(strings.Fields(event.Content), " "))),
+			Value: event.ID,
+		})
+	}
+	return newRuntimePicker(runtimePickerRewind, "回退到用户消息之前", items, 12), nil
+}
@@ -177,6 +177,12 @@ func (m *runtimeModel) applyMemberEvent(event events.Event) {
 		}
 	case events.EventAgentCompleted:
 		member.setStatusDone()
+	case events.EventMemberCompleted:
+		if event.Content != "" {
+			member.markDirty()
+			member.Blocks = append(member.Blocks, runtimeBlock{Kind: runtimeBlockSystem, Title: member.Name, Content: event.Content})
+		}
+		member.setStatusDone()
 	case events.EventSystemNotice:
 		if event.Content != "" {
 			member.markDirty()
@@ -7,6 +7,7 @@ import (
 
 	"fkteams/internal/adapters/transport/cli/tui"
 	appchat "fkteams/internal/app/chat"
+	domainmemory "fkteams/internal/domain/memory"
 
 	"fmt"
 	"os"
@@ -163,6 +164,18 @@ func (m runtimeModel) handleSubmit(input string) (tea.Model, tea.Cmd) {
 		case "clear_chat_history":
 			m.picker = newConfirmPicker("清空当前聊天历史", "clear_chat_history")
 			return m, nil
+		case "fork":
+			return m.forkRuntimeSession(args), nil
+		case "rewind":
+			if args != "" {
+				return m.rewindRuntimeSession(args), nil
+			}
+			m, ok := m.persistForBranch("回退会话失败")
+			if !ok {
+				return m, nil
+			}
+			picker, err := newRewindPicker(m.runtime.session)
+			return m.openRuntimePicker(picker, err, "回退会话")
 		case "save_chat_history_to_markdown":
 			return m.saveRuntimeChatHistoryMarkdown(), nil
 		case "save_chat_history_to_html":
@@ -192,7 +205,7 @@ func (m runtimeModel) handleSubmit(input string) (tea.Model, tea.Cmd) {
 			picker, err := newScheduleDeletePicker(m.runtime.session.scheduler)
 			return m.openRuntimePicker(picker, err, "删除定时任务")
 		case "list_memory":
-			m.appendBlock(runtimeBlockSystem, "长期记忆", runtimeMemoryMarkdown(m.runtime.session.memory))
+			m.appendBlock(runtimeBlockSystem, "长期记忆", runtimeMemoryMarkdown(m.runtime.session.memory, args))
 			return m, nil
 		case "delete_memory":
 			if args != "" {
@@ -200,6 +213,18 @@ func (m runtimeModel) handleSubmit(input string) (tea.Model, tea.Cmd) {
 			}
 			picker, err := newMemoryDeletePicker(m.runtime.session.memory)
 			return m.openRuntimePicker(picker, err, "删除长期记忆")
+		case "move_memory":
+			scopeArg, summary, _ := strings.Cut(args, " ")
+			scope, err := domainmemory.ParseScope(scopeArg)
+			if scopeArg == "" || err != nil {
+				m.appendBlock(runtimeBlockError, "移动长期记忆失败", "用法: /move_memory <global|workspace:名称|agent:名称|user:通道:用户> [SUMMARY]")
+				return m, nil
+			}
+			if summary = strings.TrimSpace(summary); summary != "" {
+				return m.moveRuntimeMemory(summary, scope), nil
+			}
+			picker, err := newMemoryMovePicker(m.runtime.session.memory, scope)
+			return m.openRuntimePicker(picker, err, "移动长期记忆")
 		case "clear_memory":
 			m.picker = newConfirmPicker("清空所有长期记忆", "clear_memory")
 			return m, nil
@@ -276,8 +301,12 @@ func (m runtimeModel) switchRuntimeWorkMode(arg string) (string, error) {
 		modeSwitcher := &sessionModeSwitcher{session: m.runtime.session, ctx: m.runtime.ctx, executor: m.runtime.executor}
 		return modeSwitcher.SwitchMode()
 	}
-	newMode := ParseWorkMode(strings.ToLower(strings.TrimSpace(arg)))
-	if newMode.String() != strings.ToLower(strings.TrimSpace(arg)) {
+	value := strings.TrimSpace(arg)
+	if _, ok := WorkMode(value).Workflow(); !ok {
+		value = strings.ToLower(value)
+	}
+	newMode := ParseWorkMode(value)
+	if newMode.String() != value {
 		return "", fmt.Errorf("unknown work mode: %s", arg)
 	}
 	if m.runtime.session.createModeRunner == nil {
@@ -313,7 +342,7 @@ func (m runtimeModel) saveRuntimeChatHistory() runtimeModel {
 	recorder := m.runtime.session.recorder()
 	historyFile := filepath.Join(m.runtime.session.historyDir, m.runtime.session.sessionID(), eventlog.TranscriptFileName)
 	store := eventlog.NewChatSessionStore(m.runtime.session.historyDir)
-	if err := appchat.NewSessionLifecycle(store, store).SaveActive(context.Background(), m.runtime.session.sessionID(), m.runtime.session.sessionTitle, recorder); err != nil {
+	if err := appchat.NewSessionLifecycle(store, store).SaveActive(m.runtime.session.withProject(context.Background()), m.runtime.session.sessionID(), m.runtime.session.sessionTitle, recorder); err != nil {
 		m.appendBlock(runtimeBlockError, "保存聊天历史失败", err.Error())
 		return m
 	}
@@ -384,6 +413,25 @@ func (m runtimeModel) deleteRuntimeMemory(summary string) runtimeModel {
 	return m
 }
 
+func (m runtimeModel) moveRuntimeMemory(summary string, scope domainmemory.Scope) runtimeModel {
+	manager := m.runtime.session.memory
+	if manager == nil {
+		m.appendBlock(runtimeBlockError, "移动长期记忆失败", "长期记忆未启用，请在 config.toml 中设置 [memory] enabled = true")
+		return m
+	}
+	moved, err := manager.Move(summary, scope)
+	if err != nil {
+		m.appendBlock(runtimeBlockError, "移动长期记忆失败", err.Error())
+		return m
+	}
+	if moved == 0 {
+		m.appendBlock(runtimeBlockSystem, "长期记忆", "未找到需要移动的记忆条目: "+summary)
+		return m
+	}
+	m.appendBlock(runtimeBlockSystem, "长期记忆", fmt.Sprintf("已将 %d 条记忆移动到 %s: %s", moved, scope, summary))
+	return m
+}
+
 func (m runtimeModel) clearRuntimeMemory() runtimeModel {
 	manager := m.runtime.session.memory
 	if manager == nil {
@@ -13,6 +13,8 @@ import (
 	"fkteams/internal/app/config"
 	appschedule "fkteams/internal/app/schedule"
 	"fkteams/internal/app/version"
+	domainmemory "fkteams/internal/domain/memory"
+	domainschedule "fkteams/internal/domain/schedule"
 )
 
 func runtimeHelpMarkdown() string {
@@ -93,26 +95,37 @@ func runtimeChatHistoryMarkdown(interactive bool) string {
 	return sb.String()
 }
 
-func runtimeMemoryMarkdown(manager appstate.MemoryManager) string {
-	entries, err := runtimeMemoryEntriesFrom(manager)
+func runtimeMemoryMarkdown(manager appstate.MemoryManager, scope string) string {
+	filter, err := domainmemory.ParseScopeFilter(scope)
 	if err != nil {
 		return err.Error()
 	}
+	all, err := runtimeMemoryEntriesFrom(manager)
+	if err != nil {
+		return err.Error()
+	}
+	entries := make([]domainmemory.MemoryEntry, 0, len(all))
+	for _, entry := range all {
+		if filter.Match(entry.Scope) {
+			entries = append(entries, entry)
+		}
+	}
 	if len(entries) == 0 {
 		return "暂无长期记忆条目"
 	}
 	var sb strings.Builder
-	sb.WriteString("| 类型 | 摘要 | 详情 | 命中 |\n")
-	sb.WriteString("|------|------|------|------|\n")
+	sb.WriteString("| 类型 | 范围 | 摘要 | 详情 | 命中 |\n")
+	sb.WriteString("|------|------|------|------|------|\n")
 	for _, entry := range entries {
-		fmt.Fprintf(&sb, "| %s | %s | %s | %d |\n",
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %d |\n",
 			markdownEscapeTable(string(entry.Type)),
+			markdownEscapeTable(entry.Scope.String()),
 			markdownEscapeTable(entry.Summary),
 			markdownEscapeTable(entry.Detail),
 			entry.HitCount,
 		)
 	}
-	fmt.Fprintf(&sb, "\n共 **%d** 条记忆，使用 `delete_memory` 删除条目，或 `clear_memory` 清空全部。", len(entries))
+	fmt.Fprintf(&sb, "\n共 **%d** 条记忆，使用 `move_memory` 调整作用域，`delete_memory` 删除条目，或 `clear_memory` 清空全部。", len(entries))
 	return sb.String()
 }
 
@@ -132,13 +145,21 @@ func runtimeScheduleMarkdown(service *appschedule.Service) string {
 			markdownEscapeTable(task.ID),
 			markdownEscapeTable(string(task.Status)),
 			markdownEscapeTable(truncateRuntimeText(task.Task, 80)),
-			task.NextRunAt.Format("2006-01-02 15:04"),
+			markdownEscapeTable(runtimeScheduleNextRun(task)),
 		)
 	}
 	fmt.Fprintf(&sb, "\n共 **%d** 个定时任务。", len(tasks))
 	return sb.String()
 }
 
+// runtimeScheduleNextRun 返回任务的下次执行时间，事件触发任务返回触发方式。
+func runtimeScheduleNextRun(task domainschedule.Task) string {
+	if task.Trigger != nil {
+		return "等待 " + string(task.Trigger.Type) + " 触发"
+	}
+	return task.NextRunAt.Format("2006-01-02 15:04")
+}
+
 func markdownEscapeTable(value string) string {
 	value = strings.ReplaceAll(value, "\n", " ")
 	return strings.ReplaceAll(value, "|", "\\|")
@@ -156,7 +177,7 @@ func runtimeWelcomeInfo(session *Session) tui.WelcomeInfo {
 		Version:   fmt.Sprint(version.Get()),
 		Mode:      runtimeModeName(session.CurrentMode),
 		SessionID: session.sessionID(),
-		Workspace: runtimeShortPath(GetWorkspaceDir()),
+		Workspace: runtimeShortPath(session.workspaceDir()),
 		Model:     modelName,
 	}
 }
@@ -168,6 +189,9 @@ func runtimeModeName(mode WorkMode) string {
 	case ModeGroup:
 		return "多智能体讨论模式"
 	default:
+		if name, ok := mode.Workflow(); ok {
+			return "工作流 " + name
+		}
 		return "团队模式"
 	}
 }
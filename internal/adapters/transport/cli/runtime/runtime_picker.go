@@ -26,8 +26,10 @@ const (
 	runtimePickerFile           runtimePickerKind = "file"
 	runtimePickerSession        runtimePickerKind = "session"
 	runtimePickerMemoryDelete   runtimePickerKind = "memory_delete"
+	runtimePickerMemoryMove     runtimePickerKind = "memory_move"
 	runtimePickerScheduleCancel runtimePickerKind = "schedule_cancel"
 	runtimePickerScheduleDelete runtimePickerKind = "schedule_delete"
+	runtimePickerRewind         runtimePickerKind = "rewind"
 	runtimePickerConfirm        runtimePickerKind = "confirm"
 )
 
@@ -99,13 +101,34 @@ func newMemoryDeletePicker(manager appstate.MemoryManager) (*runtimePicker, erro
 	items := make([]runtimePickerItem, 0, len(entries))
 	for _, entry := range entries {
 		items = append(items, runtimePickerItem{
-			Label: fmt.Sprintf("[%s] %s - %s", entry.Type, entry.Summary, entry.Detail),
+			Label: fmt.Sprintf("[%s] [%s] %s - %s", entry.Type, entry.Scope, entry.Summary, entry.Detail),
 			Value: entry.Summary,
 		})
 	}
 	return newRuntimePicker(runtimePickerMemoryDelete, "删除长期记忆", items, 12), nil
 }
 
+// newMemoryMovePicker 列出不在目标作用域的记忆，选中后移动到目标作用域。
+func newMemoryMovePicker(manager appstate.MemoryManager, scope domainmemory.Scope) (*runtimePicker, error) {
+	entries, err := runtimeMemoryEntriesFrom(manager)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]runtimePickerItem, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Scope.Normalize() == scope {
+			continue
+		}
+		items = append(items, runtimePickerItem{
+			Label: fmt.Sprintf("[%s] [%s] %s - %s", entry.Type, entry.Scope, entry.Summary, entry.Detail),
+			Value: entry.Summary,
+		})
+	}
+	p := newRuntimePicker(runtimePickerMemoryMove, "移动长期记忆到 "+scope.String(), items, 12)
+	p.action = scope.String()
+	return p, nil
+}
+
 func newScheduleCancelPicker(service *appschedule.Service) (*runtimePicker, error) {
 	tasks, err := runtimeScheduledTasks(service, "pending")
 	if err != nil {
@@ -114,7 +137,7 @@ func newScheduleCancelPicker(service *appschedule.Service) (*runtimePicker, erro
 	items := make([]runtimePickerItem, 0, len(tasks))
 	for _, task := range tasks {
 		items = append(items, runtimePickerItem{
-			Label: fmt.Sprintf("%s - %s (下次: %s)", task.ID, task.Task, task.NextRunAt.Format("2006-01-02 15:04")),
+			Label: fmt.Sprintf("%s - %s (下次: %s)", task.ID, task.Task, runtimeScheduleNextRun(task)),
 			Value: task.ID,
 		})
 	}
@@ -305,12 +328,23 @@ func (m runtimeModel) acceptPicker() (tea.Model, tea.Cmd) {
 	case runtimePickerMemoryDelete:
 		m.picker = nil
 		return m.deleteRuntimeMemory(selected.Value), nil
+	case runtimePickerMemoryMove:
+		scope, err := domainmemory.ParseScope(m.picker.action)
+		m.picker = nil
+		if err != nil {
+			m.appendBlock(runtimeBlockError, "移动长期记忆失败", err.Error())
+			return m, nil
+		}
+		return m.moveRuntimeMemory(selected.Value, scope), nil
 	case runtimePickerScheduleCancel:
 		m.picker = nil
 		return m.cancelRuntimeSchedule(selected.Value), nil
 	case runtimePickerScheduleDelete:
 		m.picker = nil
 		return m.deleteRuntimeSchedule(selected.Value), nil
+	case runtimePickerRewind:
+		m.picker = nil
+		return m.rewindRuntimeSession(selected.Value), nil
 	case runtimePickerConfirm:
 		action := m.picker.action
 		m.picker = nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	eventlog "fkteams/internal/adapters/storage/file/history"
 	"fkteams/internal/adapters/transport/cli/tui"
+	appmemory "fkteams/internal/app/memory"
 	"fkteams/internal/app/tools/ask"
 	domainmessage "fkteams/internal/domain/message"
 	runtimeport "fkteams/internal/ports/runtime"
@@ -842,6 +843,34 @@ func TestRuntimeUnknownSlashCommandDoesNotSubmitQuery(t *testing.T) {
 	}
 }
 
+func TestRuntimeMoveMemoryCommand(t *testing.T) {
+	session := NewSession(ModeTeam, nil, nil)
+	session.SetMemoryManager(appmemory.NewManager(t.TempDir(), nil, nil))
+	model := newRuntimeModel(&Runtime{
+		ctx:         context.Background(),
+		session:     session,
+		exitSignals: make(chan os.Signal, 1),
+	})
+
+	updated, _ := model.handleSubmit("/move_memory")
+	model = updated.(runtimeModel)
+	if last := model.blocks[len(model.blocks)-1]; last.Kind != runtimeBlockError || !strings.Contains(last.Content, "用法") {
+		t.Fatalf("move_memory without scope should append usage error, got %#v", last)
+	}
+
+	updated, _ = model.handleSubmit("/move_memory agent:coder")
+	model = updated.(runtimeModel)
+	if last := model.blocks[len(model.blocks)-1]; model.picker != nil || !strings.Contains(last.Content, "暂无可选择的条目") {
+		t.Fatalf("move_memory without entries should not open picker, got %#v", last)
+	}
+
+	updated, _ = model.handleSubmit("/move_memory workspace:fkteams 不存在的记忆")
+	model = updated.(runtimeModel)
+	if last := model.blocks[len(model.blocks)-1]; !strings.Contains(last.Content, "未找到需要移动的记忆条目") {
+		t.Fatalf("move_memory with missing summary should report not found, got %#v", last)
+	}
+}
+
 func TestRuntimeMouseWheelScrollsTranscript(t *testing.T) {
 	model := newRuntimeModel(&Runtime{
 		ctx:         context.Background(),
@@ -1543,3 +1572,33 @@ func keyMsg(name, text string, code rune) tea.KeyPressMsg {
 	}
 	return tea.KeyPressMsg(tea.Key{Text: text, Code: code})
 }
+
+func TestRuntimeRewindPutsUserMessageBackIntoInput(t *testing.T) {
+	session := NewSession(ModeTeam, nil, nil)
+	session.historyDir = t.TempDir()
+	session.activeSessionID = "cli-rewind"
+	recorder := session.recorder()
+	recorder.RecordEvent(events.UserMessage("run-1", events.TurnID("run-1", 1), "run-1:user", domainmessage.Message{Role: domainmessage.RoleUser, Content: "第一问"}))
+	recorder.RecordEvent(eventlog.Event{Type: events.EventAssistantCompleted, AgentName: "coordinator", Content: "第一答"})
+	recorder.RecordEvent(events.UserMessage("run-2", events.TurnID("run-2", 1), "run-2:user", domainmessage.Message{Role: domainmessage.RoleUser, Content: "第二问"}))
+	recorder.RecordEvent(eventlog.Event{Type: events.EventAssistantCompleted, AgentName: "coordinator", Content: "第二答"})
+	model := newRuntimeModel(&Runtime{
+		ctx:         context.Background(),
+		session:     session,
+		exitSignals: make(chan os.Signal, 1),
+	})
+
+	updated, _ := model.handleSubmit("/rewind")
+	model = updated.(runtimeModel)
+	if model.picker == nil || model.picker.kind != runtimePickerRewind || len(model.picker.items) != 2 {
+		t.Fatalf("/rewind should list user messages, got %#v", model.picker)
+	}
+	updated, _ = model.acceptPicker()
+	model = updated.(runtimeModel)
+	if got := model.input.Value(); got != "第二问" {
+		t.Fatalf("input after rewind = %q, want the rewound message", got)
+	}
+	if count := session.recorder().GetMessageCount(); count != 2 {
+		t.Fatalf("recorder messages after rewind = %d, want 2", count)
+	}
+}
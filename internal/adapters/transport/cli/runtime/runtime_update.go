@@ -318,7 +318,7 @@ func (m runtimeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.exitUntil = time.Time{}
 			switch msg.Text {
 			case "#":
-				picker, err := newFilePicker(GetWorkspaceDir())
+				picker, err := newFilePicker(m.runtime.session.workspaceDir())
 				if err != nil {
 					m.appendBlock(runtimeBlockError, "文件选择失败", err.Error())
 					return m, nil
@@ -5,7 +5,9 @@ import (
 	"fkteams/internal/adapters/storage/file/history"
 	"fkteams/internal/app/appdata"
 	"fkteams/internal/app/appstate"
+	"fkteams/internal/app/project"
 	appschedule "fkteams/internal/app/schedule"
+	domainmemory "fkteams/internal/domain/memory"
 	runtimeport "fkteams/internal/ports/runtime"
 	"fkteams/internal/runtime/events"
 	"fkteams/internal/runtime/log"
@@ -24,7 +26,7 @@ type ModeRunnerCreator func(ctx context.Context, mode WorkMode) (runtimeport.Run
 type Session struct {
 	InputHistory     []string
 	CurrentMode      WorkMode
-	ApproveStores    string // 自动批准的 store（逗号分隔: all/command/file/dispatch）
+	ApproveStores    string // 自动批准的 store（逗号分隔: all/command/file/git/dispatch/memory）
 	queryState       *QueryState
 	currentAgent     string
 	createModeRunner ModeRunnerCreator
@@ -37,6 +39,7 @@ type Session struct {
 	sessionTitle     string
 	resumeSessionID  string
 	temporary        bool
+	project          *project.Project
 }
 
 // NewSession 创建交互会话
@@ -77,6 +80,42 @@ func (s *Session) SetTemporary(v bool) {
 	s.temporary = v
 }
 
+// SetProject 设置当前 CLI 会话所属的项目。
+func (s *Session) SetProject(p project.Project) {
+	s.project = &p
+}
+
+// withProject 将会话所属项目注入 context，未设置项目时原样返回。
+func (s *Session) withProject(ctx context.Context) context.Context {
+	if s == nil || s.project == nil {
+		return ctx
+	}
+	return project.WithProject(ctx, *s.project)
+}
+
+// workspaceDir 返回会话所属项目的工作区。
+func (s *Session) workspaceDir() string {
+	if s == nil || s.project == nil {
+		return GetWorkspaceDir()
+	}
+	return s.project.Root
+}
+
+// memoryScope 返回会话的长期记忆作用域：所属项目，以及当前智能体，未指定时使用工作模式。
+func (s *Session) memoryScope() domainmemory.ScopeContext {
+	if s == nil {
+		return domainmemory.ScopeContext{}
+	}
+	scope := domainmemory.ScopeContext{Agent: s.currentAgent}
+	if scope.Agent == "" {
+		scope.Agent = string(s.CurrentMode)
+	}
+	if s.project != nil {
+		scope.Workspace = s.project.Name
+	}
+	return scope
+}
+
 func (s *Session) isTemporary() bool {
 	return s != nil && s.temporary
 }
@@ -0,0 +1,355 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"fkteams/internal/adapters/model/providers"
+	appchat "fkteams/internal/app/chat"
+	"fkteams/internal/app/config"
+	domainmessage "fkteams/internal/domain/message"
+	"fkteams/internal/runtime/events"
+	"fkteams/internal/runtime/log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	anthropicDefaultBaseURL = "https://api.anthropic.com"
+	anthropicDefaultVersion = "2023-06-01"
+)
+
+// anthropicMessagesRequest 是虚拟模型支持的 Messages API 请求子集。
+// tools / tool_choice 仅在透传时生效，智能体使用自身配置的工具。
+type anthropicMessagesRequest struct {
+	Model    string                    `json:"model"`
+	System   json.RawMessage           `json:"system"`
+	Messages []anthropicMessageRequest `json:"messages"`
+	Stream   bool                      `json:"stream"`
+}
+
+type anthropicMessageRequest struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+type anthropicContentBlock struct {
+	Type   string `json:"type"`
+	Text   string `json:"text,omitempty"`
+	Source *struct {
+		Type      string `json:"type"`
+		MediaType string `json:"media_type,omitempty"`
+		Data      string `json:"data,omitempty"`
+		URL       string `json:"url,omitempty"`
+	} `json:"source,omitempty"`
+}
+
+// AnthropicMessagesHandler 处理 Anthropic Messages API 请求。
+// 智能体虚拟模型由 chat.Service 执行，claude 提供者的模型透传到上游 /v1/messages。
+func (rt *Runtime) AnthropicMessagesHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bodyBytes, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, anthropicError("invalid_request_error", "failed to read request body"))
+			return
+		}
+
+		var req struct {
+			Model string `json:"model"`
+		}
+		if err := json.Unmarshal(bodyBytes, &req); err != nil {
+			c.JSON(http.StatusBadRequest, anthropicError("invalid_request_error", "invalid JSON"))
+			return
+		}
+		if req.Model == "" {
+			c.JSON(http.StatusBadRequest, anthropicError("invalid_request_error", "model is required"))
+			return
+		}
+		if !requestAllowsModel(c.Request.Context(), req.Model) {
+			c.JSON(http.StatusForbidden, anthropicError("permission_error", fmt.Sprintf("API key is not allowed to use model %q", req.Model)))
+			return
+		}
+		if model, ok := parseVirtualModel(req.Model); ok {
+			rt.handleVirtualMessages(c, model, bodyBytes)
+			return
+		}
+		proxyAnthropicMessages(c, req.Model, bodyBytes)
+	}
+}
+
+// proxyAnthropicMessages 将请求透传到配置中 claude 提供者的模型。
+func proxyAnthropicMessages(c *gin.Context, modelID string, bodyBytes []byte) {
+	mc := config.Get().ResolveModel(modelID)
+	if mc == nil {
+		c.JSON(http.StatusNotFound, anthropicError("not_found_error", fmt.Sprintf("model %q not found", modelID)))
+		return
+	}
+	pt := providers.Type(mc.Provider)
+	if pt == "" {
+		pt = providers.Detect(mc.BaseURL, mc.Model)
+	}
+	if pt != providers.Claude {
+		c.JSON(http.StatusBadRequest, anthropicError("invalid_request_error", fmt.Sprintf("model %q is not a claude provider model", modelID)))
+		return
+	}
+
+	baseURL := mc.BaseURL
+	if baseURL == "" {
+		baseURL = anthropicDefaultBaseURL
+	}
+	targetURL := strings.TrimRight(baseURL, "/") + "/v1/messages"
+
+	var bodyMap map[string]any
+	if err := json.Unmarshal(bodyBytes, &bodyMap); err != nil {
+		c.JSON(http.StatusBadRequest, anthropicError("invalid_request_error", "invalid JSON"))
+		return
+	}
+	bodyMap["model"] = mc.Model
+	newBody, _ := json.Marshal(bodyMap)
+
+	proxyReq, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, targetURL, bytes.NewReader(newBody))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, anthropicError("api_error", "failed to create proxy request"))
+		return
+	}
+	proxyReq.Header.Set("Content-Type", "application/json")
+	version := c.GetHeader("anthropic-version")
+	if version == "" {
+		version = anthropicDefaultVersion
+	}
+	proxyReq.Header.Set("anthropic-version", version)
+	if beta := c.GetHeader("anthropic-beta"); beta != "" {
+		proxyReq.Header.Set("anthropic-beta", beta)
+	}
+	if mc.APIKey != "" {
+		proxyReq.Header.Set("x-api-key", mc.APIKey)
+	}
+	for k, v := range mc.ParseExtraHeaders() {
+		proxyReq.Header.Set(k, v)
+	}
+
+	resp, err := newProxyHTTPClient().Do(proxyReq)
+	if err != nil {
+		log.Printf("[anthropic-proxy] upstream request failed: model=%s, url=%s, err=%v", modelID, targetURL, err)
+		c.JSON(http.StatusBadGateway, anthropicError("api_error", "upstream request failed"))
+		return
+	}
+	defer resp.Body.Close()
+
+	writeProxyResponse(c, resp, func(key string) bool {
+		return key == "Content-Type" || key == "Request-Id" || strings.HasPrefix(key, "Anthropic-Ratelimit-")
+	})
+}
+
+// handleVirtualMessages 通过 chat.Service 执行虚拟模型请求，并以 Messages API 格式返回。
+func (rt *Runtime) handleVirtualMessages(c *gin.Context, model virtualModel, body []byte) {
+	var req anthropicMessagesRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		c.JSON(http.StatusBadRequest, anthropicError("invalid_request_error", "invalid JSON"))
+		return
+	}
+	input, err := anthropicTurnInput(req.System, req.Messages)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, anthropicError("invalid_request_error", err.Error()))
+		return
+	}
+
+	messageID := "msg_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if req.Stream {
+		rt.streamVirtualMessages(c, model, input, messageID)
+		return
+	}
+
+	var (
+		text  strings.Builder
+		usage virtualModelUsage
+	)
+	err = rt.runVirtualModelTurn(c.Request.Context(), model, input, func(event events.Event) error {
+		usage.add(event)
+		if isVirtualModelAnswer(event) && event.Type == events.EventAssistantText {
+			text.WriteString(event.Content)
+		}
+		return nil
+	})
+	if err != nil {
+		var modelErr *virtualModelError
+		if errors.As(err, &modelErr) && modelErr.Status == http.StatusNotFound {
+			c.JSON(http.StatusNotFound, anthropicError("not_found_error", fmt.Sprintf("model %q not found", model.ID)))
+			return
+		}
+		log.Printf("[anthropic-agent] virtual model turn failed: model=%s, err=%v", model.ID, err)
+		c.JSON(http.StatusInternalServerError, anthropicError("api_error", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":            messageID,
+		"type":          "message",
+		"role":          "assistant",
+		"model":         model.ID,
+		"content":       []gin.H{{"type": "text", "text": text.String()}},
+		"stop_reason":   "end_turn",
+		"stop_sequence": nil,
+		"usage":         anthropicUsage(usage),
+	})
+}
+
+func (rt *Runtime) streamVirtualMessages(c *gin.Context, model virtualModel, input domainmessage.TurnInput, messageID string) {
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.WriteHeader(http.StatusOK)
+	clearSSEWriteDeadline(c.Writer)
+
+	write := func(eventType string, payload gin.H) error {
+		payload["type"] = eventType
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		if !writeSSEChunk(c.Writer, "event: "+eventType+"\ndata: "+string(data)+"\n\n") {
+			return fmt.Errorf("write SSE event: %w", errClientGone)
+		}
+		return nil
+	}
+
+	if write("message_start", gin.H{"message": gin.H{
+		"id":            messageID,
+		"type":          "message",
+		"role":          "assistant",
+		"model":         model.ID,
+		"content":       []gin.H{},
+		"stop_reason":   nil,
+		"stop_sequence": nil,
+		"usage":         gin.H{"input_tokens": 0, "output_tokens": 0},
+	}}) != nil {
+		return
+	}
+	if write("content_block_start", gin.H{"index": 0, "content_block": gin.H{"type": "text", "text": ""}}) != nil {
+		return
+	}
+	if write("ping", gin.H{}) != nil {
+		return
+	}
+
+	var usage virtualModelUsage
+	err := rt.runVirtualModelTurn(c.Request.Context(), model, input, func(event events.Event) error {
+		usage.add(event)
+		if isVirtualModelAnswer(event) && event.Type == events.EventAssistantText {
+			return write("content_block_delta", gin.H{
+				"index": 0,
+				"delta": gin.H{"type": "text_delta", "text": event.Content},
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, errClientGone) {
+			log.Printf("[anthropic-agent] virtual model turn failed: model=%s, err=%v", model.ID, err)
+			_ = write("error", anthropicError("api_error", err.Error()))
+		}
+		return
+	}
+	if write("content_block_stop", gin.H{"index": 0}) != nil {
+		return
+	}
+	if write("message_delta", gin.H{
+		"delta": gin.H{"stop_reason": "end_turn", "stop_sequence": nil},
+		"usage": anthropicUsage(usage),
+	}) != nil {
+		return
+	}
+	_ = write("message_stop", gin.H{})
+}
+
+// anthropicTurnInput 将 system 和 messages 转换为回合输入：最后一条 user 消息作为本轮输入。
+func anthropicTurnInput(system json.RawMessage, messages []anthropicMessageRequest) (domainmessage.TurnInput, error) {
+	converted := make([]domainmessage.Message, 0, len(messages)+1)
+	if systemMsg, err := anthropicMessageContent(system); err != nil {
+		return domainmessage.TurnInput{}, fmt.Errorf("system: %w", err)
+	} else if !systemMsg.IsEmpty() {
+		systemMsg.Role = domainmessage.RoleSystem
+		converted = append(converted, systemMsg)
+	}
+	for i, raw := range messages {
+		role := domainmessage.Role(raw.Role)
+		if role != domainmessage.RoleUser && role != domainmessage.RoleAssistant {
+			return domainmessage.TurnInput{}, fmt.Errorf("messages[%d]: unsupported role %q", i, raw.Role)
+		}
+		msg, err := anthropicMessageContent(raw.Content)
+		if err != nil {
+			return domainmessage.TurnInput{}, fmt.Errorf("messages[%d]: %w", i, err)
+		}
+		if msg.IsEmpty() {
+			continue
+		}
+		msg.Role = role
+		converted = append(converted, msg)
+	}
+	if len(converted) == 0 || converted[len(converted)-1].Role != domainmessage.RoleUser {
+		return domainmessage.TurnInput{}, fmt.Errorf("the last message must be a user message")
+	}
+	last := len(converted) - 1
+	return domainmessage.TurnInput{Context: converted[:last], Message: converted[last]}, nil
+}
+
+func anthropicMessageContent(raw json.RawMessage) (domainmessage.Message, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return domainmessage.Message{}, nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return domainmessage.Message{Content: text}, nil
+	}
+	var blocks []anthropicContentBlock
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return domainmessage.Message{}, fmt.Errorf("content must be a string or an array of content blocks")
+	}
+	var msg domainmessage.Message
+	for _, block := range blocks {
+		switch block.Type {
+		case "text":
+			msg.ContentParts = append(msg.ContentParts, appchat.TextPart(block.Text))
+		case "image":
+			if block.Source == nil {
+				return domainmessage.Message{}, fmt.Errorf("image block requires source")
+			}
+			switch block.Source.Type {
+			case "base64":
+				msg.ContentParts = append(msg.ContentParts, appchat.ImageBase64Part(block.Source.Data, block.Source.MediaType))
+			case "url":
+				msg.ContentParts = append(msg.ContentParts, appchat.ImageURLPart(block.Source.URL))
+			default:
+				return domainmessage.Message{}, fmt.Errorf("unsupported image source type %q", block.Source.Type)
+			}
+		case "tool_use", "tool_result", "thinking", "redacted_thinking":
+			// 客户端自身的工具调用和思考块无法由智能体复现，跳过。
+		default:
+			return domainmessage.Message{}, fmt.Errorf("unsupported content block type %q", block.Type)
+		}
+	}
+	return msg, nil
+}
+
+func anthropicUsage(usage virtualModelUsage) gin.H {
+	return gin.H{
+		"input_tokens":  usage.PromptTokens,
+		"output_tokens": usage.CompletionTokens,
+	}
+}
+
+func anthropicError(errType, message string) gin.H {
+	return gin.H{
+		"type": "error",
+		"error": gin.H{
+			"type":    errType,
+			"message": message,
+		},
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	domainmessage "fkteams/internal/domain/message"
+	"fkteams/internal/runtime/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAnthropicTurnInputConvertsSystemAndBlocks(t *testing.T) {
+	input, err := anthropicTurnInput([]byte(`[{"type":"text","text":"be brief"}]`), []anthropicMessageRequest{
+		{Role: "user", Content: []byte(`"hi"`)},
+		{Role: "assistant", Content: []byte(`[{"type":"text","text":"hello"},{"type":"tool_use","id":"t1","name":"x","input":{}}]`)},
+		{Role: "user", Content: []byte(`[{"type":"text","text":"look"},{"type":"image","source":{"type":"base64","media_type":"image/png","data":"AAAA"}}]`)},
+	})
+	if err != nil {
+		t.Fatalf("anthropicTurnInput: %v", err)
+	}
+	if len(input.Context) != 3 || input.Context[0].Role != domainmessage.RoleSystem || len(input.Context[2].ContentParts) != 1 {
+		t.Fatalf("context = %#v", input.Context)
+	}
+	parts := input.Message.ContentParts
+	if len(parts) != 2 || parts[1].Base64Data != "AAAA" || parts[1].MIMEType != "image/png" {
+		t.Fatalf("message parts = %#v", parts)
+	}
+
+	if _, err := anthropicTurnInput(nil, []anthropicMessageRequest{{Role: "assistant", Content: []byte(`"x"`)}}); err == nil {
+		t.Fatal("expected error when last message is not from user")
+	}
+}
+
+func TestAnthropicMessagesRunsVirtualModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	runner := &scriptedRunner{events: []events.Event{
+		{Type: events.EventAssistantText, Content: "member draft", MemberCallID: "call-1"},
+		{Type: events.EventAssistantText, Content: "Hello"},
+		{Type: events.EventAssistantText, Content: " world"},
+		events.Usage("leader", "root", 10, 5, 15),
+	}}
+	rt := newVirtualModelTestRuntime(t, runner)
+
+	router := gin.New()
+	router.POST("/v1/messages", rt.AnthropicMessagesHandler())
+
+	resp := performJSON(router, http.MethodPost, "/v1/messages", `{"model":"fkteams/team","max_tokens":64,"system":"be brief","messages":[{"role":"user","content":"hi"}]}`)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", resp.Code, resp.Body.String())
+	}
+	body := resp.Body.String()
+	for _, want := range []string{`"type":"message"`, `"text":"Hello world"`, `"stop_reason":"end_turn"`, `"input_tokens":10`, `"output_tokens":5`} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("body missing %s: %s", want, body)
+		}
+	}
+	if strings.Contains(body, "member draft") {
+		t.Fatalf("member output leaked: %s", body)
+	}
+	if runner.input.Message.Content != "hi" || len(runner.input.Context) != 1 {
+		t.Fatalf("runner input = %#v", runner.input)
+	}
+}
+
+func TestAnthropicMessagesStreamsVirtualModelEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	runner := &scriptedRunner{events: []events.Event{
+		{Type: events.EventAssistantText, Content: "Hi"},
+		events.Usage("leader", "root", 3, 2, 5),
+	}}
+	rt := newVirtualModelTestRuntime(t, runner)
+
+	router := gin.New()
+	router.POST("/v1/messages", rt.AnthropicMessagesHandler())
+
+	resp := performJSON(router, http.MethodPost, "/v1/messages", `{"model":"fkteams/team","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	body := resp.Body.String()
+	last := -1
+	for _, want := range []string{"event: message_start", "event: content_block_start", `"delta":{"text":"Hi","type":"text_delta"}`, "event: content_block_stop", `"output_tokens":2`, "event: message_stop"} {
+		idx := strings.Index(body, want)
+		if idx <= last {
+			t.Fatalf("stream body missing or out of order %s:\n%s", want, body)
+		}
+		last = idx
+	}
+}
+
+func TestAnthropicMessagesRejectsNonClaudeModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rt := newTestRuntime(t)
+
+	router := gin.New()
+	router.POST("/v1/messages", rt.AnthropicMessagesHandler())
+
+	resp := performJSON(router, http.MethodPost, "/v1/messages", `{"messages":[]}`)
+	if resp.Code != http.StatusBadRequest || !strings.Contains(resp.Body.String(), `"type":"error"`) {
+		t.Fatalf("status = %d: %s", resp.Code, resp.Body.String())
+	}
+}
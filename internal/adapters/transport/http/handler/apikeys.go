@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	fileapikey "fkteams/internal/adapters/storage/file/apikey"
+	appagent "fkteams/internal/app/agent"
+	appapikey "fkteams/internal/app/apikey"
+	"fkteams/internal/app/appdata"
+	"fkteams/internal/app/config"
+	domainaccount "fkteams/internal/domain/account"
+	domainapikey "fkteams/internal/domain/apikey"
+	"fkteams/internal/runtime/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultAPIKeyLifetime 是未指定有效期时新密钥的有效期。
+	defaultAPIKeyLifetime = 90 * 24 * time.Hour
+	maxAPIKeyLifetime     = 10 * 365 * 24 * time.Hour
+)
+
+var (
+	apiKeysMu   sync.Mutex
+	apiKeysPath string
+	apiKeys     *appapikey.Service
+)
+
+// apiKeyService 返回当前数据目录的密钥服务，数据目录变化时重新创建。
+func apiKeyService() *appapikey.Service {
+	path := appdata.APIKeysFile()
+	apiKeysMu.Lock()
+	defer apiKeysMu.Unlock()
+	if apiKeys == nil || apiKeysPath != path {
+		apiKeys = appapikey.NewService(fileapikey.NewStore(path))
+		apiKeysPath = path
+	}
+	return apiKeys
+}
+
+// AuthenticateAPIKey 校验受管 API 密钥并记录使用来源。
+func AuthenticateAPIKey(ctx context.Context, secret, ip string) (domainapikey.Key, error) {
+	return apiKeyService().Authenticate(ctx, secret, ip)
+}
+
+// AllowAPIKeyRequest 按密钥的每分钟请求上限限流。
+func AllowAPIKeyRequest(key domainapikey.Key) (bool, time.Duration) {
+	return apiKeyService().Allow(key)
+}
+
+// APIKeyPrincipal 返回密钥代表的身份。密钥不继承管理员权限；只有 sessions:read 范围的密钥按只读账号处理。
+// 归属账号已删除或停用时密钥随之失效。
+func APIKeyPrincipal(ctx context.Context, key domainapikey.Key) (domainaccount.Principal, bool) {
+	owner := key.Owner
+	if owner == "" {
+		owner = config.Get().Server.Auth.Username
+	}
+	principal, _, ok := resolveAccount(ctx, owner)
+	if !ok {
+		return domainaccount.Principal{}, false
+	}
+	role := domainaccount.RoleMember
+	if principal.Role == domainaccount.RoleViewer || !(key.HasScope(domainapikey.ScopeChat) || key.HasScope(domainapikey.ScopeSchedules)) {
+		role = domainaccount.RoleViewer
+	}
+	return domainaccount.Principal{Username: principal.Username, Role: role, APIKey: key.ID}, true
+}
+
+// requestAllowsModel 判断请求使用的 API 密钥是否可以调用模型，未使用受管密钥时不限制。
+// 智能体虚拟模型还需通过密钥的智能体白名单。
+func requestAllowsModel(ctx context.Context, modelID string) bool {
+	key, ok := domainapikey.FromContext(ctx)
+	if !ok {
+		return true
+	}
+	if !key.AllowsModel(modelID) {
+		return false
+	}
+	if model, ok := parseVirtualModel(modelID); ok {
+		return key.AllowsAgent(agentTarget(model.Mode, model.AgentName))
+	}
+	return true
+}
+
+// requestAllowsAgent 判断请求使用的 API 密钥是否可以调用智能体或模式，未使用受管密钥时不限制。
+func requestAllowsAgent(ctx context.Context, mode, agentName string) bool {
+	key, ok := domainapikey.FromContext(ctx)
+	return !ok || key.AllowsAgent(agentTarget(mode, agentName))
+}
+
+// agentTarget 返回白名单中用于匹配的名称：指定智能体时为智能体名，否则为协作模式。
+func agentTarget(mode, agentName string) string {
+	if agentName != "" {
+		return agentName
+	}
+	if mode == "" {
+		return appagent.ModeTeam
+	}
+	return mode
+}
+
+// APIKeyInfo 是返回给管理端的密钥信息，不包含哈希。
+type APIKeyInfo struct {
+	ID         string               `json:"id"`
+	Name       string               `json:"name"`
+	Hint       string               `json:"hint"`
+	Scopes     []domainapikey.Scope `json:"scopes"`
+	Models     []string             `json:"models,omitempty"`
+	Agents     []string             `json:"agents,omitempty"`
+	RateLimit  int                  `json:"rate_limit,omitempty"`
+	Owner      string               `json:"owner,omitempty"`
+	ExpiresAt  *time.Time           `json:"expires_at,omitempty"`
+	Expired    bool                 `json:"expired,omitempty"`
+	CreatedAt  time.Time            `json:"created_at"`
+	LastUsedAt *time.Time           `json:"last_used_at,omitempty"`
+	LastUsedIP string               `json:"last_used_ip,omitempty"`
+}
+
+func apiKeyInfo(key domainapikey.Key, now time.Time) APIKeyInfo {
+	return APIKeyInfo{
+		ID:         key.ID,
+		Name:       key.Name,
+		Hint:       key.Hint,
+		Scopes:     key.Scopes,
+		Models:     key.Models,
+		Agents:     key.Agents,
+		RateLimit:  key.RateLimit,
+		Owner:      key.Owner,
+		ExpiresAt:  key.ExpiresAt,
+		Expired:    key.Expired(now),
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		LastUsedIP: key.LastUsedIP,
+	}
+}
+
+// ListAPIKeysHandler 列出所有 API 密钥。
+func ListAPIKeysHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keys, err := apiKeyService().List(c.Request.Context())
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		now := time.Now()
+		result := make([]APIKeyInfo, 0, len(keys))
+		for _, key := range keys {
+			result = append(result, apiKeyInfo(key, now))
+		}
+		OK(c, gin.H{"api_keys": result})
+	}
+}
+
+// CreateAPIKeyHandler 签发 API 密钥，明文只在本次响应中返回。
+func CreateAPIKeyHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Name      string               `json:"name"`
+			Scopes    []domainapikey.Scope `json:"scopes"`
+			Models    []string             `json:"models"`
+			Agents    []string             `json:"agents"`
+			RateLimit int                  `json:"rate_limit"`
+			Owner     string               `json:"owner"`
+			// ExpiresIn 是有效期秒数，省略时为 90 天，负数表示永不过期
+			ExpiresIn *int64 `json:"expires_in"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			Fail(c, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if req.Owner == "" {
+			if principal, ok := RequestPrincipal(c); ok {
+				req.Owner = principal.Username
+			}
+		}
+		if authEnabled, _ := AuthEnabled(); authEnabled && req.Owner != "" {
+			if _, _, ok := resolveAccount(c.Request.Context(), req.Owner); !ok {
+				Fail(c, http.StatusBadRequest, "owner must be an enabled user")
+				return
+			}
+		}
+		if req.ExpiresIn != nil && *req.ExpiresIn > int64(maxAPIKeyLifetime/time.Second) {
+			Fail(c, http.StatusBadRequest, "expires_in exceeds 10 years, use a negative value for a key that never expires")
+			return
+		}
+		var expiresAt *time.Time
+		switch {
+		case req.ExpiresIn == nil:
+			t := time.Now().Add(defaultAPIKeyLifetime)
+			expiresAt = &t
+		case *req.ExpiresIn >= 0:
+			t := time.Now().Add(time.Duration(*req.ExpiresIn) * time.Second)
+			expiresAt = &t
+		}
+		key, secret, err := apiKeyService().Create(c.Request.Context(), appapikey.CreateRequest{
+			Name:      req.Name,
+			Scopes:    req.Scopes,
+			Models:    req.Models,
+			Agents:    req.Agents,
+			RateLimit: req.RateLimit,
+			Owner:     req.Owner,
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		log.Printf("api key created: id=%s, name=%s, scopes=%v, owner=%s", key.ID, key.Name, key.Scopes, key.Owner)
+		Created(c, gin.H{"api_key": apiKeyInfo(key, time.Now()), "key": secret})
+	}
+}
+
+// DeleteAPIKeyHandler 吊销 API 密钥，使用该密钥的请求立即失效。
+func DeleteAPIKeyHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := apiKeyService().Revoke(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		log.Printf("api key revoked: id=%s, name=%s", key.ID, key.Name)
+		OK(c, nil)
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"fkteams/internal/app/config"
+	domainapikey "fkteams/internal/domain/apikey"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestOpenAIHandlersEnforceAPIKeyAllowlists(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rt := newVirtualModelTestRuntime(t, &scriptedRunner{})
+	saveHandlerConfig(t, config.Config{Models: []config.ModelConfig{{ID: "gpt-4o"}, {ID: "claude"}}})
+
+	key := domainapikey.Key{ID: "k1", Models: []string{"gpt-4o", "fkteams/team", "fkteams/deep"}, Agents: []string{"team"}}
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(domainapikey.WithKey(c.Request.Context(), key))
+	})
+	router.GET("/v1/models", rt.OpenAIModelsHandler())
+	router.POST("/v1/chat/completions", rt.OpenAIChatCompletionsHandler())
+	router.POST("/v1/messages", rt.AnthropicMessagesHandler())
+
+	resp := performJSON(router, http.MethodGet, "/v1/models", "")
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode models: %v", err)
+	}
+	var ids []string
+	for _, model := range list.Data {
+		ids = append(ids, model.ID)
+	}
+	// fkteams/deep 在模型白名单中，但不在智能体白名单中
+	if strings.Join(ids, ",") != "gpt-4o,fkteams/team" {
+		t.Fatalf("listed models = %v", ids)
+	}
+
+	for _, model := range []string{"claude", "fkteams/deep", "fkteams/agent:coder"} {
+		body := `{"model":"` + model + `","messages":[{"role":"user","content":"hi"}]}`
+		if resp := performJSON(router, http.MethodPost, "/v1/chat/completions", body); resp.Code != http.StatusForbidden {
+			t.Errorf("chat completions with %s: status = %d, want 403", model, resp.Code)
+		}
+		if resp := performJSON(router, http.MethodPost, "/v1/messages", body); resp.Code != http.StatusForbidden {
+			t.Errorf("messages with %s: status = %d, want 403", model, resp.Code)
+		}
+	}
+	if resp := performJSON(router, http.MethodPost, "/v1/chat/completions", `{"model":"fkteams/team","messages":[{"role":"user","content":"hi"}]}`); resp.Code != http.StatusOK {
+		t.Fatalf("allowed virtual model status = %d: %s", resp.Code, resp.Body.String())
+	}
+}
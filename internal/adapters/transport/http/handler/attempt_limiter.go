@@ -90,4 +90,5 @@ func rateLimitExceeded(c *gin.Context, retryAfter time.Duration) {
 var (
 	loginAttempts       = newAttemptLimiter(8, 5*time.Minute, 10000)
 	publicShareAttempts = newAttemptLimiter(8, 5*time.Minute, 20000)
+	triggerAttempts     = newAttemptLimiter(8, 5*time.Minute, 20000)
 )
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	appusage "fkteams/internal/app/usage"
+	domainaudit "fkteams/internal/domain/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultAuditRecordLimit = 100
+
+// auditFilterFromQuery 从查询参数解析审计过滤条件。
+func auditFilterFromQuery(c *gin.Context) (domainaudit.Filter, error) {
+	from, to, err := appusage.ParseDayRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return domainaudit.Filter{}, err
+	}
+	return domainaudit.Filter{
+		From:      from,
+		To:        to,
+		User:      c.Query("user"),
+		SessionID: c.Query("session_id"),
+		Agent:     c.Query("agent"),
+		Tool:      c.Query("tool"),
+		Decision:  c.Query("decision"),
+		Source:    c.Query("source"),
+		Status:    domainaudit.Status(c.Query("status")),
+		Path:      c.Query("path"),
+	}, nil
+}
+
+// AuditRecordsHandler 按时间倒序返回工具执行审计记录。
+
+func (rt *Runtime) AuditRecordsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rt.Audit == nil {
+			Fail(c, http.StatusServiceUnavailable, "audit log not initialized")
+			return
+		}
+		filter, err := auditFilterFromQuery(c)
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		limit := defaultAuditRecordLimit
+		if raw := c.Query("limit"); raw != "" {
+			limit, err = strconv.Atoi(raw)
+			if err != nil || limit <= 0 {
+				Fail(c, http.StatusBadRequest, "limit must be a positive integer")
+				return
+			}
+		}
+		records, err := rt.Audit.Query(c.Request.Context(), filter, limit)
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		OK(c, gin.H{"records": records})
+	}
+}
+
+// AuditVerifyHandler 校验审计日志哈希链。
+
+func (rt *Runtime) AuditVerifyHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rt.Audit == nil {
+			Fail(c, http.StatusServiceUnavailable, "audit log not initialized")
+			return
+		}
+		result, err := rt.Audit.Verify(c.Request.Context())
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		OK(c, result)
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	fileaudit "fkteams/internal/adapters/storage/file/audit"
+	appaudit "fkteams/internal/app/audit"
+	domainaudit "fkteams/internal/domain/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuditHandlersQueryAndVerify(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	key := func() ([]byte, error) { return []byte("test-audit-key"), nil }
+	auditLog := appaudit.NewLog(fileaudit.NewStore(path, key), key)
+	for _, record := range []domainaudit.Record{
+		{User: "alice", Agent: "coder", Tool: "execute", Status: domainaudit.StatusOK},
+		{User: "bob", Agent: "coder", Tool: "write_file", Status: domainaudit.StatusDenied},
+		{User: "alice", Agent: "leader", Tool: "execute", Status: domainaudit.StatusRejected},
+	} {
+		if _, err := auditLog.Record(context.Background(), record); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	router := gin.New()
+	rt := NewRuntime(RuntimeOptions{Audit: auditLog})
+	router.GET("/audit", rt.AuditRecordsHandler())
+	router.GET("/audit/verify", rt.AuditVerifyHandler())
+
+	resp := performRequest(router, http.MethodGet, "/audit?tool=execute&user=alice&limit=1", nil)
+	var payload struct {
+		Records []domainaudit.Record `json:"records"`
+	}
+	decodeRawData(t, resp, &payload)
+	if len(payload.Records) != 1 || payload.Records[0].Seq != 3 {
+		t.Fatalf("records = %#v", payload.Records)
+	}
+	if resp := performRequest(router, http.MethodGet, "/audit?limit=0", nil); resp.Code != http.StatusBadRequest {
+		t.Fatalf("invalid limit status = %d", resp.Code)
+	}
+
+	var result domainaudit.Verification
+	decodeRawData(t, performRequest(router, http.MethodGet, "/audit/verify", nil), &result)
+	if !result.Valid || result.Records != 3 {
+		t.Fatalf("verification = %#v", result)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(data), `"status":"denied"`, `"status":"ok"`, 1)
+	if err := os.WriteFile(path, []byte(tampered), 0600); err != nil {
+		t.Fatal(err)
+	}
+	decodeRawData(t, performRequest(router, http.MethodGet, "/audit/verify", nil), &result)
+	if result.Valid || result.Line != 2 {
+		t.Fatalf("tampered verification = %#v", result)
+	}
+
+	unavailable := gin.New()
+	unavailable.GET("/audit", NewRuntime().AuditRecordsHandler())
+	if resp := performRequest(unavailable, http.MethodGet, "/audit", nil); resp.Code != http.StatusServiceUnavailable {
+		t.Fatalf("missing audit log status = %d", resp.Code)
+	}
+}
@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/hex"
 	"fkteams/internal/app/config"
+	domainaccount "fkteams/internal/domain/account"
 	"fkteams/internal/runtime/log"
 	"net/http"
 	"strings"
@@ -19,12 +21,36 @@ const (
 	authTokenTTL   = 7 * 24 * time.Hour
 )
 
+// getTokenSecret 返回配置文件内置管理员的令牌签名密钥
 func getTokenSecret() []byte {
 	auth := config.Get().Server.Auth
-	sum := sha256.Sum256([]byte(auth.Secret + "\x00" + auth.Username + "\x00" + auth.Password))
+	return tokenSecret(auth.Username, auth.Password)
+}
+
+// tokenSecret 将账号凭据混入签名密钥，修改密码或 SECRET 后已签发的令牌随之失效
+func tokenSecret(username, credential string) []byte {
+	sum := sha256.Sum256([]byte(config.Get().Server.Auth.Secret + "\x00" + username + "\x00" + credential))
 	return sum[:]
 }
 
+// resolveAccount 返回用户名对应的身份和令牌签名密钥。配置文件中的管理员优先于账号存储；
+// 已删除或停用的账号无法解析，其令牌随即失效。
+func resolveAccount(ctx context.Context, username string) (domainaccount.Principal, []byte, bool) {
+	auth := config.Get().Server.Auth
+	if username == auth.Username {
+		return builtinPrincipal(), getTokenSecret(), true
+	}
+	user, err := accountService().Get(ctx, username)
+	if err != nil || user.Disabled {
+		return domainaccount.Principal{}, nil, false
+	}
+	return domainaccount.Principal{Username: user.Username, Role: user.Role}, tokenSecret(user.Username, user.TokenCredential()), true
+}
+
+func builtinPrincipal() domainaccount.Principal {
+	return domainaccount.Principal{Username: config.Get().Server.Auth.Username, Role: domainaccount.RoleAdmin, Builtin: true}
+}
+
 // AuthEnabled 检查是否启用登录认证，启用时校验 SECRET 非空
 func AuthEnabled() (bool, error) {
 	auth := config.Get().Server.Auth
@@ -38,9 +64,13 @@ func AuthEnabled() (bool, error) {
 }
 
 func generateToken(username string) string {
+	_, secret, ok := resolveAccount(context.Background(), username)
+	if !ok {
+		return ""
+	}
 	expiry := time.Now().Add(authTokenTTL)
 	payload := username + "|" + expiry.Format(time.RFC3339)
-	mac := hmac.New(sha256.New, getTokenSecret())
+	mac := hmac.New(sha256.New, secret)
 	mac.Write([]byte(payload))
 	sig := hex.EncodeToString(mac.Sum(nil))
 	return hex.EncodeToString([]byte(payload)) + "." + sig
@@ -48,39 +78,46 @@ func generateToken(username string) string {
 
 // ValidateToken 校验 token 有效性
 func ValidateToken(token string) bool {
+	_, ok := AuthenticateToken(token)
+	return ok
+}
+
+// AuthenticateToken 校验 token 并返回其代表的身份
+func AuthenticateToken(token string) (domainaccount.Principal, bool) {
 	parts := splitToken(token)
 	if parts == nil {
-		return false
+		return domainaccount.Principal{}, false
 	}
 	payload, sig := parts[0], parts[1]
 
 	payloadBytes, err := hex.DecodeString(payload)
 	if err != nil {
-		return false
+		return domainaccount.Principal{}, false
+	}
+	payloadStr := string(payloadBytes)
+	idx := strings.LastIndex(payloadStr, "|")
+	if idx < 0 {
+		return domainaccount.Principal{}, false
+	}
+	principal, secret, ok := resolveAccount(context.Background(), payloadStr[:idx])
+	if !ok {
+		return domainaccount.Principal{}, false
 	}
 
-	mac := hmac.New(sha256.New, getTokenSecret())
+	mac := hmac.New(sha256.New, secret)
 	mac.Write(payloadBytes)
 	expectedSig := hex.EncodeToString(mac.Sum(nil))
 
 	if !hmac.Equal([]byte(sig), []byte(expectedSig)) {
-		return false
+		return domainaccount.Principal{}, false
 	}
 
 	// 检查过期
-	payloadStr := string(payloadBytes)
-	idx := strings.LastIndex(payloadStr, "|")
-	if idx < 0 {
-		return false
-	}
-	if payloadStr[:idx] != config.Get().Server.Auth.Username {
-		return false
-	}
 	expiry, err := time.Parse(time.RFC3339, payloadStr[idx+1:])
-	if err != nil {
-		return false
+	if err != nil || !time.Now().Before(expiry) {
+		return domainaccount.Principal{}, false
 	}
-	return time.Now().Before(expiry)
+	return principal, true
 }
 
 // RequestAuthToken 从 Bearer Header 或 HttpOnly Cookie 提取请求 Token。
@@ -170,13 +207,25 @@ func LoginHandler() gin.HandlerFunc {
 		}
 
 		if !credentialsMatch(req.Username, req.Password, expectedUser, expectedPass) {
-			log.Printf("login failed: username=%s, ip=%s", req.Username, c.ClientIP())
-			Fail(c, http.StatusUnauthorized, "用户名或密码错误")
-			return
+			// 配置文件中的管理员不匹配时再查询账号存储
+			if req.Username == expectedUser {
+				log.Printf("login failed: username=%s, ip=%s", req.Username, c.ClientIP())
+				Fail(c, http.StatusUnauthorized, "用户名或密码错误")
+				return
+			}
+			if _, err := accountService().Authenticate(c.Request.Context(), req.Username, req.Password); err != nil {
+				log.Printf("login failed: username=%s, ip=%s", req.Username, c.ClientIP())
+				Fail(c, http.StatusUnauthorized, "用户名或密码错误")
+				return
+			}
 		}
 		loginAttempts.Reset(attemptKey)
 
 		token := generateToken(req.Username)
+		if token == "" {
+			Fail(c, http.StatusUnauthorized, "用户名或密码错误")
+			return
+		}
 		setAuthCookie(c, token, int(authTokenTTL/time.Second))
 		c.Header("Cache-Control", "no-store")
 		if req.CookieOnly {
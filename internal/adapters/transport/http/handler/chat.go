@@ -9,8 +9,10 @@ import (
 	appchat "fkteams/internal/app/chat"
 	"fkteams/internal/app/chat/taskstream"
 	"fkteams/internal/app/config"
+	"fkteams/internal/app/project"
 	"fkteams/internal/app/tools/ask"
 	domainevent "fkteams/internal/domain/event"
+	domainmemory "fkteams/internal/domain/memory"
 	domainmessage "fkteams/internal/domain/message"
 	runtimeport "fkteams/internal/ports/runtime"
 	"fkteams/internal/runtime/approval"
@@ -55,17 +57,17 @@ func messageContentParts(message domainmessage.Message) []domainmessage.ContentP
 }
 
 // buildChatInput 构建输入消息（含历史），支持多模态
-func buildChatInput(recorder *eventlog.HistoryRecorder, message string, contents []ContentPart, manager appstate.MemoryManager) (input domainmessage.TurnInput, displayText string) {
+func buildChatInput(recorder *eventlog.HistoryRecorder, message string, contents []ContentPart, manager appstate.MemoryManager, scope domainmemory.ScopeContext) (input domainmessage.TurnInput, displayText string) {
 	if len(contents) > 0 {
 		parts := convertContentParts(contents)
 		displayText = appchat.ExtractTextFromParts(parts)
 		if displayText == "" {
 			displayText = message
 		}
-		input = appchat.BuildMultimodalTurnInputWithMemory(recorder, displayText, parts, manager)
+		input = appchat.BuildMultimodalTurnInputWithMemory(recorder, displayText, parts, manager, scope)
 	} else {
 		displayText = message
-		input = appchat.BuildTurnInputWithMemory(recorder, message, manager)
+		input = appchat.BuildTurnInputWithMemory(recorder, message, manager, scope)
 	}
 	return
 }
@@ -87,7 +89,7 @@ func queuedChatMessage(kind taskstream.QueueKind, message string, contents []Con
 	return queued
 }
 
-func buildQueuedChatInput(recorder *eventlog.HistoryRecorder, msg taskstream.QueuedMessage, manager appstate.MemoryManager) domainmessage.TurnInput {
+func buildQueuedChatInput(recorder *eventlog.HistoryRecorder, msg taskstream.QueuedMessage, manager appstate.MemoryManager, scope domainmemory.ScopeContext) domainmessage.TurnInput {
 	if len(msg.Parts) > 0 {
 		displayText := appchat.ExtractTextFromParts(msg.Parts)
 		if displayText == "" {
@@ -96,9 +98,9 @@ func buildQueuedChatInput(recorder *eventlog.HistoryRecorder, msg taskstream.Que
 		if displayText == "" {
 			displayText = msg.Text
 		}
-		return appchat.BuildMultimodalTurnInputWithMemory(recorder, displayText, msg.Parts, manager)
+		return appchat.BuildMultimodalTurnInputWithMemory(recorder, displayText, msg.Parts, manager, scope)
 	}
-	return appchat.BuildTurnInputWithMemory(recorder, msg.Text, manager)
+	return appchat.BuildTurnInputWithMemory(recorder, msg.Text, manager, scope)
 }
 
 func enqueueTaskMessage(stream *taskstream.Stream, sessionID string, kind taskstream.QueueKind, message string, contents []ContentPart) (taskstream.QueuedMessage, error) {
@@ -228,6 +230,20 @@ func extractInterruptMessage(interrupts []runtimeport.Interrupt) string {
 	return "需要审批"
 }
 
+// extractApprovalFindings 汇总根因中断附带的结构化风险发现。
+func extractApprovalFindings(interrupts []runtimeport.Interrupt) []events.ApprovalFinding {
+	var findings []events.ApprovalFinding
+	for _, ic := range interrupts {
+		if !ic.IsRootCause {
+			continue
+		}
+		if request, ok := ic.Info.(*approval.Request); ok {
+			findings = append(findings, request.Findings...)
+		}
+	}
+	return findings
+}
+
 func approvalDecisionText(result map[string]any) string {
 	for _, v := range result {
 		switch v {
@@ -245,12 +261,17 @@ func approvalDecisionText(result map[string]any) string {
 	return ""
 }
 
-func configuredApprovalRegistry() *approval.Registry {
+// configuredApprovalRegistry 按全局配置和 context 中项目的 auto_approve 创建审批注册表。
+func configuredApprovalRegistry(ctx context.Context) *approval.Registry {
 	cfg := config.Get()
 	if cfg == nil {
 		return approval.NewDefaultRegistry()
 	}
-	return approval.NewDefaultSelectiveRegistry(cfg.Tools.Approval.AutoApprove)
+	autoApprove := cfg.Tools.Approval.AutoApprove
+	if p, ok := project.FromContext(ctx); ok && len(p.AutoApprove) > 0 {
+		autoApprove = append(append([]string(nil), autoApprove...), p.AutoApprove...)
+	}
+	return approval.NewDefaultSelectiveRegistry(autoApprove)
 }
 
 // askResponseText 从中断结果中提取 ask_answered 的可读文本
@@ -671,6 +692,9 @@ func convertEventToMapWithResolver(event events.Event, resolver toolmeta.Resolve
 	if event.Approval != nil {
 		result["approval"] = event.Approval
 	}
+	if event.Sandbox != nil {
+		result["sandbox"] = event.Sandbox
+	}
 	return result
 }
 
@@ -744,6 +768,27 @@ type ContentPart struct {
 	Detail     string `json:"detail,omitempty"`      // type=image_url 时的精度: high/low/auto
 }
 
+// scopeFileContents 校验非管理员账号附件中的工作区路径。该账号的智能体工具以其私有文件目录为工作区，
+// 路径保持相对该目录，与文件接口的解析一致；越出私有目录的附件被丢弃。
+func scopeFileContents(ctx context.Context, contents []ContentPart) []ContentPart {
+	area, err := userFileArea(ctx)
+	if area == "" && err == nil {
+		return contents
+	}
+	scoped := make([]ContentPart, 0, len(contents))
+	for _, part := range contents {
+		if part.Type == "file_url" && !strings.Contains(part.URL, "://") {
+			workspacePath, ok := workspacePathInArea(area, part.URL)
+			if err != nil || !ok {
+				continue
+			}
+			part.URL = strings.TrimPrefix(strings.TrimPrefix(workspacePath, area), "/")
+		}
+		scoped = append(scoped, part)
+	}
+	return scoped
+}
+
 // convertContentParts 将前端传入的多模态内容转换为核心内容部分
 func convertContentParts(parts []ContentPart) []domainmessage.ContentPart {
 	result := make([]domainmessage.ContentPart, 0, len(parts))
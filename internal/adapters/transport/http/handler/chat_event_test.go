@@ -11,6 +11,7 @@ import (
 	"fkteams/internal/app/tools/ask"
 	"fkteams/internal/domain/message"
 	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/approval"
 	"fkteams/internal/runtime/events"
 )
 
@@ -228,6 +229,23 @@ func TestAskInterruptIDPrefersRootCause(t *testing.T) {
 	}
 }
 
+func TestExtractApprovalFindingsUsesRootCauseRequests(t *testing.T) {
+	finding := events.ApprovalFinding{Level: "危险", Program: "rm", Target: "/", Scope: "critical", Reason: "递归删除关键目录"}
+	interrupts := []runtimeport.Interrupt{
+		{ID: "wrapper", Info: &approval.Request{Message: "wrapped", Findings: []events.ApprovalFinding{{Reason: "ignored"}}}},
+		{ID: "root", IsRootCause: true, Info: &approval.Request{Message: "Dangerous command requires approval", Findings: []events.ApprovalFinding{finding}}},
+		{ID: "plain", IsRootCause: true, Info: "File operation requires approval"},
+	}
+
+	got := extractApprovalFindings(interrupts)
+	if len(got) != 1 || got[0] != finding {
+		t.Fatalf("unexpected findings: %#v", got)
+	}
+	if msg := extractInterruptMessage(interrupts); msg != "Dangerous command requires approval\nFile operation requires approval" {
+		t.Fatalf("unexpected approval message: %q", msg)
+	}
+}
+
 func TestExtractAskInterruptUsesAskRootCauseMetadata(t *testing.T) {
 	order := 2
 	got := extractAskInterrupt([]runtimeport.Interrupt{
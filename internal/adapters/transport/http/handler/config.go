@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	memorymodel "fkteams/internal/adapters/model/memory"
 	"fkteams/internal/app/agent/catalog"
 	"fkteams/internal/app/appstate"
@@ -62,9 +63,19 @@ func GetConfigHandler() gin.HandlerFunc {
 		if resp.Server.Auth.Secret != "" {
 			resp.Server.Auth.Secret = sensitivePassword
 		}
+		if resp.Server.Auth.OIDC.ClientSecret != "" {
+			resp.Server.Auth.OIDC.ClientSecret = sensitivePassword
+		}
 
 		resp.Agents.Items = agents.ConfigItems(cfg)
 		maskAgentSSHPasswords(resp.Agents.Items)
+		maskHookSecrets(resp.Hooks)
+		for i := range resp.Tools.MCPServers {
+			maskEnvValues(resp.Tools.MCPServers[i].Env)
+		}
+		for i := range resp.Projects.Items {
+			maskEnvValues(resp.Projects.Items[i].Env)
+		}
 
 		// 脱敏 Channels
 		if resp.Channels.QQ.AppSecret != "" {
@@ -73,6 +84,18 @@ func GetConfigHandler() gin.HandlerFunc {
 		if resp.Channels.Discord.Token != "" {
 			resp.Channels.Discord.Token = maskAPIKey(resp.Channels.Discord.Token)
 		}
+		if resp.Scheduler.WebhookSecret != "" {
+			resp.Scheduler.WebhookSecret = sensitivePassword
+		}
+		if resp.Scheduler.SMTP.Password != "" {
+			resp.Scheduler.SMTP.Password = sensitivePassword
+		}
+		if resp.Memory.Embedding.APIKey != "" {
+			resp.Memory.Embedding.APIKey = sensitivePassword
+		}
+
+		// 引用（secret://name、${env:VAR}）本身不含明文，原样返回以便前端展示和回传
+		config.ApplySecretReferences(resp)
 
 		OK(c, resp)
 	}
@@ -105,6 +128,26 @@ func (rt *Runtime) UpdateConfigHandlerWithState(state *appstate.State) gin.Handl
 			Fail(c, http.StatusBadRequest, err.Error())
 			return
 		}
+		if err := newCfg.ValidateHooks(); err != nil {
+			Fail(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := newCfg.ValidateProjects(); err != nil {
+			Fail(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := newCfg.ValidateSandbox(); err != nil {
+			Fail(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := newCfg.ValidateScheduler(); err != nil {
+			Fail(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := newCfg.ValidateMemory(); err != nil {
+			Fail(c, http.StatusBadRequest, err.Error())
+			return
+		}
 
 		// 合并敏感字段：只按稳定 ID 恢复，禁止按数组位置猜测密钥归属。
 		if err := restoreModelSecrets(&newCfg, oldCfg); err != nil {
@@ -123,14 +166,29 @@ func (rt *Runtime) UpdateConfigHandlerWithState(state *appstate.State) gin.Handl
 		if newCfg.Server.Auth.Secret == sensitivePassword {
 			newCfg.Server.Auth.Secret = oldCfg.Server.Auth.Secret
 		}
+		if newCfg.Server.Auth.OIDC.ClientSecret == sensitivePassword {
+			newCfg.Server.Auth.OIDC.ClientSecret = oldCfg.Server.Auth.OIDC.ClientSecret
+		}
 		newCfg.Agents.Items = userAgentConfigItems(newCfg.Agents.Items)
 		restoreAgentSSHPasswords(newCfg.Agents.Items, oldCfg)
+		restoreHookSecrets(newCfg.Hooks, oldCfg)
+		restoreMCPServerEnv(newCfg.Tools.MCPServers, oldCfg)
+		restoreProjectEnv(newCfg.Projects.Items, oldCfg)
 		if newCfg.Channels.QQ.AppSecret == sensitivePassword {
 			newCfg.Channels.QQ.AppSecret = oldCfg.Channels.QQ.AppSecret
 		}
 		if isMasked(newCfg.Channels.Discord.Token) {
 			newCfg.Channels.Discord.Token = oldCfg.Channels.Discord.Token
 		}
+		if newCfg.Scheduler.WebhookSecret == sensitivePassword {
+			newCfg.Scheduler.WebhookSecret = oldCfg.Scheduler.WebhookSecret
+		}
+		if newCfg.Scheduler.SMTP.Password == sensitivePassword {
+			newCfg.Scheduler.SMTP.Password = oldCfg.Scheduler.SMTP.Password
+		}
+		if newCfg.Memory.Embedding.APIKey == sensitivePassword {
+			newCfg.Memory.Embedding.APIKey = oldCfg.Memory.Embedding.APIKey
+		}
 		if err := newCfg.Server.Validate(); err != nil {
 			Fail(c, http.StatusBadRequest, err.Error())
 			return
@@ -144,6 +202,10 @@ func (rt *Runtime) UpdateConfigHandlerWithState(state *appstate.State) gin.Handl
 
 		// 保存并重载
 		if err := config.Save(&newCfg); err != nil {
+			if errors.Is(err, config.ErrSecretReference) {
+				Fail(c, http.StatusBadRequest, err.Error())
+				return
+			}
 			Fail(c, http.StatusInternalServerError, "failed to save config: "+err.Error())
 			return
 		}
@@ -160,6 +222,9 @@ func (rt *Runtime) UpdateConfigHandlerWithState(state *appstate.State) gin.Handl
 			rt.ResetChannels()
 		}
 		resetMemoryLLM(c.Request.Context(), state, rt.ModelRegistry)
+		if newCfg.Memory.Embedding != oldCfg.Memory.Embedding {
+			resetMemoryEmbedder(state)
+		}
 
 		OK(c, gin.H{"auth_changed": authChanged})
 	}
@@ -250,6 +315,82 @@ func maskAgentSSHPasswords(items []config.AgentConfig) {
 	}
 }
 
+// maskHookSecrets 脱敏 hook 的请求头和环境变量值，它们常用于携带访问令牌。
+func maskHookSecrets(items []config.HookConfig) {
+	for i := range items {
+		for key := range items[i].Headers {
+			items[i].Headers[key] = sensitivePassword
+		}
+		for key := range items[i].Env {
+			items[i].Env[key] = sensitivePassword
+		}
+	}
+}
+
+// maskEnvValues 脱敏环境变量值，MCP 服务和项目命令通过它们获取访问令牌。
+func maskEnvValues(values map[string]string) {
+	for key := range values {
+		values[key] = sensitivePassword
+	}
+}
+
+func restoreEnvValues(values, old map[string]string) {
+	for key, value := range values {
+		if value == sensitivePassword {
+			values[key] = old[key]
+		}
+	}
+}
+
+func restoreMCPServerEnv(items []config.MCPServer, oldCfg *config.Config) {
+	if oldCfg == nil {
+		return
+	}
+	oldByID := make(map[string]config.MCPServer, len(oldCfg.Tools.MCPServers))
+	for _, item := range oldCfg.Tools.MCPServers {
+		oldByID[item.ID] = item
+	}
+	for i := range items {
+		restoreEnvValues(items[i].Env, oldByID[items[i].ID].Env)
+	}
+}
+
+func restoreProjectEnv(items []config.ProjectConfig, oldCfg *config.Config) {
+	if oldCfg == nil {
+		return
+	}
+	oldByName := make(map[string]config.ProjectConfig, len(oldCfg.Projects.Items))
+	for _, item := range oldCfg.Projects.Items {
+		oldByName[item.Name] = item
+	}
+	for i := range items {
+		restoreEnvValues(items[i].Env, oldByName[items[i].Name].Env)
+	}
+}
+
+func restoreHookSecrets(items []config.HookConfig, oldCfg *config.Config) {
+	if oldCfg == nil {
+		return
+	}
+	oldByName := make(map[string]config.HookConfig, len(oldCfg.Hooks))
+	for _, item := range oldCfg.Hooks {
+		oldByName[item.Name] = item
+	}
+	for i := range items {
+		old := oldByName[items[i].Name]
+		for key, value := range items[i].Headers {
+			if value == sensitivePassword {
+				items[i].Headers[key] = old.Headers[key]
+			}
+		}
+		for key, value := range items[i].Env {
+			if value == sensitivePassword {
+				items[i].Env[key] = old.Env[key]
+			}
+		}
+	}
+}
+
 func restoreAgentSSHPasswords(items []config.AgentConfig, oldCfg *config.Config) {
 	if oldCfg == nil {
 		return
@@ -303,6 +444,21 @@ func resetMemoryLLM(ctx context.Context, state *appstate.State, registry *modelr
 	log.Println("[memory] 记忆服务模型已更新")
 }
 
+// resetMemoryEmbedder 使用当前配置重建 MemoryManager 的向量模型，模型标识变化时重建向量索引
+func resetMemoryEmbedder(state *appstate.State) {
+	manager := memoryFromState(state)
+	if manager == nil {
+		return
+	}
+	embedder, err := memorymodel.NewEmbedder(config.Get().Memory.Embedding)
+	if err != nil {
+		log.Printf("[memory] 重建向量模型失败，记忆服务继续使用旧模型: %v", err)
+		return
+	}
+	manager.ResetEmbedder(embedder)
+	log.Println("[memory] 记忆服务向量模型已更新")
+}
+
 // GetToolNamesHandler 获取可用工具名列表
 
 func (rt *Runtime) GetToolNamesHandler() gin.HandlerFunc {
@@ -4,11 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 
+	"fkteams/internal/app/appdata"
 	"fkteams/internal/app/appstate"
 	"fkteams/internal/app/config"
 	"fkteams/internal/app/memory"
+	"fkteams/internal/runtime/env"
+	"fkteams/internal/runtime/secrets"
 
 	"github.com/gin-gonic/gin"
 )
@@ -41,6 +45,19 @@ func TestGetConfigHandlerMasksSensitiveFields(t *testing.T) {
 			QQ:      config.ChannelQQ{AppSecret: "qq-secret"},
 			Discord: config.ChannelDiscord{Token: "discord-secret"},
 		},
+		Hooks: []config.HookConfig{{
+			Name:    "notify",
+			Points:  []string{"on_event"},
+			URL:     "https://hooks.example/notify",
+			Headers: map[string]string{"Authorization": "Bearer hook-secret"},
+		}},
+		Tools: config.ToolSettings{MCPServers: []config.MCPServer{{
+			ID: "github", Command: "github-mcp", Env: map[string]string{"GITHUB_TOKEN": "ghp-secret"},
+		}}},
+		Scheduler: config.Scheduler{
+			WebhookSecret: "webhook-secret",
+			SMTP:          config.SMTP{Host: "smtp.example.com", From: "bot@example.com", Password: "smtp-secret"},
+		},
 	})
 
 	router := gin.New()
@@ -74,6 +91,18 @@ func TestGetConfigHandlerMasksSensitiveFields(t *testing.T) {
 	if got.Channels.Discord.Token == "discord-secret" || !isMasked(got.Channels.Discord.Token) {
 		t.Fatalf("discord token was not masked: %#v", got.Channels.Discord)
 	}
+	if got.Scheduler.WebhookSecret != sensitivePassword || got.Scheduler.SMTP.Password != sensitivePassword {
+		t.Fatalf("scheduler secrets were not masked: %#v", got.Scheduler)
+	}
+	if len(got.Hooks) != 1 || got.Hooks[0].Headers["Authorization"] != sensitivePassword {
+		t.Fatalf("hook headers were not masked: %#v", got.Hooks)
+	}
+	if len(got.Tools.MCPServers) != 1 || got.Tools.MCPServers[0].Env["GITHUB_TOKEN"] != sensitivePassword {
+		t.Fatalf("mcp env was not masked: %#v", got.Tools.MCPServers)
+	}
+	if config.Get().Hooks[0].Headers["Authorization"] != "Bearer hook-secret" {
+		t.Fatal("masking hook headers mutated stored configuration")
+	}
 }
 
 func TestUpdateConfigHandlerRestoresSensitiveFields(t *testing.T) {
@@ -106,6 +135,19 @@ func TestUpdateConfigHandlerRestoresSensitiveFields(t *testing.T) {
 			QQ:      config.ChannelQQ{AppSecret: "old-qq"},
 			Discord: config.ChannelDiscord{Token: "old-discord"},
 		},
+		Hooks: []config.HookConfig{{
+			Name:    "audit",
+			Points:  []string{"before_tool_call"},
+			Command: "audit.sh",
+			Env:     map[string]string{"AUDIT_TOKEN": "old-audit-token"},
+		}},
+		Tools: config.ToolSettings{MCPServers: []config.MCPServer{{
+			ID: "github", Command: "github-mcp", Env: map[string]string{"GITHUB_TOKEN": "old-ghp"},
+		}}},
+		Scheduler: config.Scheduler{
+			WebhookSecret: "old-webhook",
+			SMTP:          config.SMTP{Host: "smtp.example.com", From: "bot@example.com", Password: "old-smtp"},
+		},
 	})
 
 	next := config.Config{
@@ -136,6 +178,19 @@ func TestUpdateConfigHandlerRestoresSensitiveFields(t *testing.T) {
 			QQ:      config.ChannelQQ{AppSecret: sensitivePassword},
 			Discord: config.ChannelDiscord{Token: maskAPIKey("old-discord")},
 		},
+		Hooks: []config.HookConfig{{
+			Name:    "audit",
+			Points:  []string{"before_tool_call", "after_tool_call"},
+			Command: "audit.sh",
+			Env:     map[string]string{"AUDIT_TOKEN": sensitivePassword, "AUDIT_LEVEL": "debug"},
+		}},
+		Tools: config.ToolSettings{MCPServers: []config.MCPServer{{
+			ID: "github", Command: "github-mcp", Env: map[string]string{"GITHUB_TOKEN": sensitivePassword},
+		}}},
+		Scheduler: config.Scheduler{
+			WebhookSecret: sensitivePassword,
+			SMTP:          config.SMTP{Host: "smtp.example.com", From: "bot@example.com", Password: sensitivePassword},
+		},
 	}
 	body, err := json.Marshal(next)
 	if err != nil {
@@ -175,6 +230,65 @@ func TestUpdateConfigHandlerRestoresSensitiveFields(t *testing.T) {
 	if got.Channels.QQ.AppSecret != "old-qq" || got.Channels.Discord.Token != "old-discord" {
 		t.Fatalf("channel secrets were not restored: %#v", got.Channels)
 	}
+	if got.Scheduler.WebhookSecret != "old-webhook" || got.Scheduler.SMTP.Password != "old-smtp" {
+		t.Fatalf("scheduler secrets were not restored: %#v", got.Scheduler)
+	}
+	if len(got.Hooks) != 1 || got.Hooks[0].Env["AUDIT_TOKEN"] != "old-audit-token" || got.Hooks[0].Env["AUDIT_LEVEL"] != "debug" {
+		t.Fatalf("hook env was not restored: %#v", got.Hooks)
+	}
+	if len(got.Tools.MCPServers) != 1 || got.Tools.MCPServers[0].Env["GITHUB_TOKEN"] != "old-ghp" {
+		t.Fatalf("mcp env was not restored: %#v", got.Tools.MCPServers)
+	}
+}
+
+func TestConfigHandlersKeepSecretReferences(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv(env.VaultPassphrase, "vault-passphrase")
+	t.Setenv("FKTEAMS_TEST_SMTP_PASSWORD", "smtp-from-env")
+	t.Setenv(env.AppDir, t.TempDir())
+	if err := secrets.Open(appdata.SecretsFile(), secrets.Options{}).Set("openai", "sk-from-vault"); err != nil {
+		t.Fatalf("vault set: %v", err)
+	}
+	if err := config.Save(&config.Config{
+		Models: []config.ModelConfig{{ID: "main", Name: "主力模型", UseFor: []string{config.ModelUseChat}, APIKey: "secret://openai"}},
+		Scheduler: config.Scheduler{
+			SMTP: config.SMTP{Host: "smtp.example.com", From: "bot@example.com", Password: "${env:FKTEAMS_TEST_SMTP_PASSWORD}"},
+		},
+	}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/config", GetConfigHandler())
+	router.POST("/config", NewRuntime().UpdateConfigHandlerWithState(nil))
+
+	resp := performRequest(router, http.MethodGet, "/config", nil)
+	if strings.Contains(resp.Body.String(), "sk-from-vault") || strings.Contains(resp.Body.String(), "smtp-from-env") {
+		t.Fatalf("config response leaks resolved secrets: %s", resp.Body.String())
+	}
+	var got config.Config
+	decodeRawData(t, resp, &got)
+	if got.Models[0].APIKey != "secret://openai" || !got.Models[0].HasAPIKey || got.Scheduler.SMTP.Password != "${env:FKTEAMS_TEST_SMTP_PASSWORD}" {
+		t.Fatalf("references were not returned: %#v %#v", got.Models[0], got.Scheduler.SMTP)
+	}
+
+	got.Server.Port = 4321
+	body, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	if resp := performJSON(router, http.MethodPost, "/config", string(body)); resp.Code != http.StatusOK {
+		t.Fatalf("update config status = %d: %s", resp.Code, resp.Body.String())
+	}
+	if cfg := config.Get(); cfg.Models[0].APIKey != "sk-from-vault" || cfg.Scheduler.SMTP.Password != "smtp-from-env" {
+		t.Fatalf("references were not resolved after update: %#v", cfg.Models[0])
+	}
+
+	got.Models[0].APIKey = "secret://missing"
+	body, _ = json.Marshal(got)
+	if resp := performJSON(router, http.MethodPost, "/config", string(body)); resp.Code != http.StatusBadRequest || !strings.Contains(resp.Body.String(), "models.main.api_key") {
+		t.Fatalf("unresolvable reference status = %d: %s", resp.Code, resp.Body.String())
+	}
 }
 
 func TestUpdateConfigHandlerFiltersBuiltinAgents(t *testing.T) {
@@ -448,26 +562,106 @@ func TestMemoryHandlersWithoutState(t *testing.T) {
 	}
 }
 
+func TestMemoryHandlersFilterAndMoveScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := &handlerFakeMemory{entries: []memory.MemoryEntry{
+		{ID: "1", Summary: "全局偏好"},
+		{ID: "2", Summary: "项目约定", Scope: memory.Scope{Kind: memory.ScopeWorkspace, Key: "repo-a"}},
+		{ID: "3", Summary: "另一个项目", Scope: memory.Scope{Kind: memory.ScopeWorkspace, Key: "repo-b"}},
+	}}
+	state := appstate.New()
+	state.SetMemory(manager)
+
+	router := gin.New()
+	router.GET("/memory", GetMemoryListHandlerWithState(state))
+	router.POST("/memory/move", MoveMemoryHandlerWithState(state))
+
+	for query, want := range map[string]int{"global": 1, "workspace": 2, "workspace:repo-a": 1, "user": 0} {
+		resp := performRequest(router, http.MethodGet, "/memory?scope="+query, nil)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("memory list %s status = %d: %s", query, resp.Code, resp.Body.String())
+		}
+		var entries []memory.MemoryEntry
+		decodeRawData(t, resp, &entries)
+		if len(entries) != want {
+			t.Fatalf("memory list %s = %#v, want %d entries", query, entries, want)
+		}
+	}
+	if resp := performRequest(router, http.MethodGet, "/memory?scope=team", nil); resp.Code != http.StatusBadRequest {
+		t.Fatalf("invalid scope filter status = %d, want 400", resp.Code)
+	}
+
+	resp := performJSON(router, http.MethodPost, "/memory/move", `{"summary":"全局偏好","scope":"agent:coder"}`)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("memory move status = %d: %s", resp.Code, resp.Body.String())
+	}
+	if got := manager.moved["全局偏好"]; got.String() != "agent:coder" {
+		t.Fatalf("moved scope = %s, want agent:coder", got)
+	}
+	if resp := performJSON(router, http.MethodPost, "/memory/move", `{"summary":"全局偏好","scope":"agent"}`); resp.Code != http.StatusBadRequest {
+		t.Fatalf("move without scope key status = %d, want 400", resp.Code)
+	}
+	if resp := performJSON(router, http.MethodPost, "/memory/move", `{"summary":"不存在","scope":"global"}`); resp.Code != http.StatusNotFound {
+		t.Fatalf("move missing entry status = %d, want 404", resp.Code)
+	}
+}
+
 type handlerFakeMemory struct {
 	entries     []memory.MemoryEntry
 	deleted     map[string]int
 	count       int
 	deleteCalls int
 	cleared     bool
+	moved       map[string]memory.Scope
 }
 
-func (m *handlerFakeMemory) Search(string, int) []memory.MemoryEntry                { return nil }
-func (m *handlerFakeMemory) ExtractAndStoreAsync([]memory.Message, string) bool     { return true }
-func (m *handlerFakeMemory) FlushExtract(context.Context, []memory.Message, string) {}
-func (m *handlerFakeMemory) List() []memory.MemoryEntry                             { return m.entries }
+func (m *handlerFakeMemory) Search(string, int, memory.ScopeContext) []memory.MemoryEntry { return nil }
+func (m *handlerFakeMemory) ExtractAndStoreAsync([]memory.Message, string, memory.ScopeContext) bool {
+	return true
+}
+func (m *handlerFakeMemory) FlushExtract(context.Context, []memory.Message, string, memory.ScopeContext) {
+}
+func (m *handlerFakeMemory) List() []memory.MemoryEntry { return m.entries }
+func (m *handlerFakeMemory) Move(summary string, scope memory.Scope) (int, error) {
+	moved := 0
+	for _, entry := range m.entries {
+		if entry.Summary == summary {
+			moved++
+		}
+	}
+	if moved > 0 {
+		if m.moved == nil {
+			m.moved = make(map[string]memory.Scope)
+		}
+		m.moved[summary] = scope
+	}
+	return moved, nil
+}
 func (m *handlerFakeMemory) Delete(summary string) int {
 	m.deleteCalls++
 	return m.deleted[summary]
 }
+func (m *handlerFakeMemory) Get(string, memory.ScopeContext) (memory.MemoryEntry, bool) {
+	return memory.MemoryEntry{}, false
+}
+func (m *handlerFakeMemory) Find(string, int, memory.ScopeContext, memory.SearchFilter) []memory.MemoryEntry {
+	return nil
+}
+func (m *handlerFakeMemory) Save(memory.MemoryEntry) (memory.MemoryEntry, memory.SaveAction, error) {
+	return memory.MemoryEntry{}, memory.SaveAdded, nil
+}
+func (m *handlerFakeMemory) Update(string, memory.EntryPatch) (memory.MemoryEntry, error) {
+	return memory.MemoryEntry{}, nil
+}
+func (m *handlerFakeMemory) Forget(string) (memory.MemoryEntry, error) {
+	return memory.MemoryEntry{}, nil
+}
 func (m *handlerFakeMemory) Count() int { return m.count }
 func (m *handlerFakeMemory) Clear()     { m.cleared = true }
 func (m *handlerFakeMemory) ResetLLM(memory.LLMClient) {
 }
-func (m *handlerFakeMemory) Wait(context.Context) error { return nil }
+func (m *handlerFakeMemory) ResetEmbedder(memory.Embedder) {}
+func (m *handlerFakeMemory) Wait(context.Context) error    { return nil }
 
 var _ appstate.MemoryManager = (*handlerFakeMemory)(nil)
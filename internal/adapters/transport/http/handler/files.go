@@ -13,6 +13,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 	"strconv"
@@ -22,7 +23,7 @@ import (
 	"time"
 	"unicode/utf8"
 
-	"fkteams/internal/app/appdata"
+	domainaccount "fkteams/internal/domain/account"
 	"fkteams/internal/runtime/atomicfile"
 	"fkteams/internal/runtime/log"
 	"fkteams/internal/runtime/pathguard"
@@ -67,17 +68,66 @@ var errSearchLimit = errors.New("workspace search limit exceeded")
 
 const untrustedContentSecurityPolicy = "sandbox; default-src 'none'; img-src 'self' data: blob:; media-src 'self' data: blob:; style-src 'self' 'unsafe-inline'; font-src 'self' data:; script-src 'none'; connect-src 'none'; object-src 'none'; frame-src 'none'; worker-src 'none'; base-uri 'none'; form-action 'none'; frame-ancestors 'self'"
 
-// getWorkspaceDir 获取工作目录并返回绝对路径
-func getWorkspaceDir() (string, error) {
-	baseDir := appdata.WorkspaceDir()
+// userFilesDir 是工作区内各账号私有文件目录的父目录，相对工作区根目录。
+const userFilesDir = ".fkteams/users"
+
+// getWorkspaceDir 获取请求 ?project= 指定项目的工作目录并返回绝对路径，未指定时使用当前项目。
+// 非管理员账号只能访问工作区内自己的文件目录，返回该目录。
+func getWorkspaceDir(c *gin.Context) (string, error) {
+	baseDir, err := projectWorkspaceDir(c)
+	if err != nil {
+		return "", err
+	}
 	if err := os.MkdirAll(baseDir, 0755); err != nil {
 		return "", fmt.Errorf("创建工作目录失败")
 	}
+	return userAreaDir(c.Request.Context(), baseDir)
+}
+
+// userAreaDir 返回请求账号在工作目录 baseDir 中可访问的绝对目录：管理员为 baseDir 本身，
+// 其他账号为其私有文件目录（不存在时创建）。
+func userAreaDir(ctx context.Context, baseDir string) (string, error) {
 	absBase, err := filepath.Abs(baseDir)
 	if err != nil {
 		return "", fmt.Errorf("解析工作目录失败")
 	}
-	return absBase, nil
+	area, err := userFileArea(ctx)
+	if err != nil {
+		return "", err
+	}
+	if area == "" {
+		return absBase, nil
+	}
+	if err := ensureWorkspaceDirectoryNoSymlinks(absBase, filepath.FromSlash(area)); err != nil {
+		return "", fmt.Errorf("创建用户文件目录失败")
+	}
+	return filepath.Join(absBase, filepath.FromSlash(area)), nil
+}
+
+// userFileArea 返回请求账号的私有文件目录（相对工作区，斜杠分隔）。
+// 管理员和未启用账号体系时返回空，表示可以访问整个工作区。
+func userFileArea(ctx context.Context) (string, error) {
+	principal, ok := domainaccount.PrincipalFromContext(ctx)
+	if !ok || principal.IsAdmin() {
+		return "", nil
+	}
+	name := principal.Username
+	if domainaccount.ValidateUsername(name) != nil || name == "." || name == ".." {
+		return "", fmt.Errorf("当前账号无法访问工作区文件")
+	}
+	return path.Join(userFilesDir, name), nil
+}
+
+// workspacePathInArea 将账号文件目录内的相对路径转换为工作区相对路径，路径越出该目录时返回 false。
+func workspacePathInArea(area, relativePath string) (string, bool) {
+	if area == "" {
+		return relativePath, true
+	}
+	joined := path.Join(area, filepath.ToSlash(relativePath))
+	if joined != area && !strings.HasPrefix(joined, area+"/") {
+		return "", false
+	}
+	return joined, true
 }
 
 // resolveWorkspaceEntryNoSymlinks 解析已存在的工作区条目，并拒绝路径中任意符号链接。
@@ -313,7 +363,7 @@ func saveUploadedFileAtomic(fileHeader *multipart.FileHeader, destination string
 // GetFilesHandler 获取指定目录下的文件和文件夹列表
 func GetFilesHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		baseDir, err := getWorkspaceDir()
+		baseDir, err := getWorkspaceDir(c)
 		if err != nil {
 			Fail(c, http.StatusInternalServerError, err.Error())
 			return
@@ -386,7 +436,7 @@ func GetFilesHandler() gin.HandlerFunc {
 // SearchFilesHandler 递归搜索文件名和相对路径
 func SearchFilesHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		baseDir, err := getWorkspaceDir()
+		baseDir, err := getWorkspaceDir(c)
 		if err != nil {
 			Fail(c, http.StatusInternalServerError, err.Error())
 			return
@@ -495,7 +545,7 @@ func searchWorkspaceFiles(ctx context.Context, filesystem fs.FS, query string) (
 // Query: path(文件相对路径)
 func GetFileContentHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		baseDir, err := getWorkspaceDir()
+		baseDir, err := getWorkspaceDir(c)
 		if err != nil {
 			Fail(c, http.StatusInternalServerError, err.Error())
 			return
@@ -553,7 +603,7 @@ func GetFileContentHandler() gin.HandlerFunc {
 // JSON body: {"path": "相对路径", "content": "文件内容"}
 func SaveFileContentHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		baseDir, err := getWorkspaceDir()
+		baseDir, err := getWorkspaceDir(c)
 		if err != nil {
 			Fail(c, http.StatusInternalServerError, err.Error())
 			return
@@ -636,7 +686,7 @@ func UploadFileHandler() gin.HandlerFunc {
 			}
 		}()
 
-		baseDir, err := getWorkspaceDir()
+		baseDir, err := getWorkspaceDir(c)
 		if err != nil {
 			Fail(c, http.StatusInternalServerError, err.Error())
 			return
@@ -974,7 +1024,7 @@ func (rt *Runtime) UploadChunkHandler() gin.HandlerFunc {
 			}
 		}()
 
-		baseDir, err := getWorkspaceDir()
+		baseDir, err := getWorkspaceDir(c)
 		if err != nil {
 			Fail(c, http.StatusInternalServerError, err.Error())
 			return
@@ -1215,7 +1265,7 @@ func sanitizeUploadID(id string) string {
 // Query: path(文件相对路径)
 func DownloadFileHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		baseDir, err := getWorkspaceDir()
+		baseDir, err := getWorkspaceDir(c)
 		if err != nil {
 			Fail(c, http.StatusInternalServerError, err.Error())
 			return
@@ -1285,7 +1335,7 @@ func DownloadFileHandler() gin.HandlerFunc {
 // BatchDownloadHandler 批量下载：将多个文件/文件夹打包为单个 zip。
 func BatchDownloadHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		baseDir, err := getWorkspaceDir()
+		baseDir, err := getWorkspaceDir(c)
 		if err != nil {
 			Fail(c, http.StatusInternalServerError, err.Error())
 			return
@@ -1555,7 +1605,7 @@ func writeArchiveValidationError(c *gin.Context, err error) {
 // force 为 true 时可删除非空目录
 func DeleteFileHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		baseDir, err := getWorkspaceDir()
+		baseDir, err := getWorkspaceDir(c)
 		if err != nil {
 			Fail(c, http.StatusInternalServerError, err.Error())
 			return
@@ -1662,7 +1712,7 @@ func setUntrustedContentHeaders(c *gin.Context) {
 // 相对路径通过 URL wildcard 传入，浏览器可自然解析相对引用
 func ServeFileHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		baseDir, err := getWorkspaceDir()
+		baseDir, err := getWorkspaceDir(c)
 		if err != nil {
 			Fail(c, http.StatusInternalServerError, err.Error())
 			return
@@ -13,6 +13,8 @@ import (
 	"testing/fstest"
 
 	"fkteams/internal/app/appdata"
+	"fkteams/internal/app/project"
+	domainaccount "fkteams/internal/domain/account"
 	"fkteams/internal/runtime/env"
 
 	"github.com/gin-gonic/gin"
@@ -138,6 +140,107 @@ func TestDeleteFileHandler(t *testing.T) {
 	}
 }
 
+func TestMemberFileAccessIsConfinedToOwnArea(t *testing.T) {
+	workspace := setupWorkspaceDir(t)
+	bobFile := filepath.Join(workspace, ".fkteams", "users", "bob", "notes.txt")
+	if err := os.MkdirAll(filepath.Dir(bobFile), 0755); err != nil {
+		t.Fatalf("mkdir bob area: %v", err)
+	}
+	if err := os.WriteFile(bobFile, []byte("bob"), 0644); err != nil {
+		t.Fatalf("write bob file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "team.txt"), []byte("team"), 0644); err != nil {
+		t.Fatalf("write shared file: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(testPrincipalMiddleware())
+	router.GET("/files", GetFilesHandler())
+	router.GET("/search", SearchFilesHandler())
+	router.GET("/content", GetFileContentHandler())
+	router.POST("/delete", DeleteFileHandler())
+
+	resp := performAs(router, "alice", http.MethodGet, "/files", "")
+	var files []FileInfo
+	decodeRawData(t, resp, &files)
+	if len(files) != 0 {
+		t.Fatalf("member should start with an empty file area, got %#v", files)
+	}
+	resp = performAs(router, "alice", http.MethodGet, "/search?q=txt", "")
+	decodeRawData(t, resp, &files)
+	if len(files) != 0 {
+		t.Fatalf("member search should not see other files, got %#v", files)
+	}
+	for _, target := range []string{"team.txt", "../bob/notes.txt", "../../../.fkteams/users/bob/notes.txt"} {
+		if resp := performAs(router, "alice", http.MethodGet, "/content?path="+target, ""); resp.Code == http.StatusOK {
+			t.Fatalf("member read %s: %s", target, resp.Body.String())
+		}
+	}
+	performAs(router, "alice", http.MethodPost, "/delete", `{"path":"../bob/notes.txt","force":true}`)
+	if _, err := os.Stat(bobFile); err != nil {
+		t.Fatalf("member must not delete another user's file: %v", err)
+	}
+
+	resp = performAs(router, "bob", http.MethodGet, "/content?path=notes.txt", "")
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), "bob") {
+		t.Fatalf("owner read status = %d: %s", resp.Code, resp.Body.String())
+	}
+	resp = performAs(router, "root", http.MethodGet, "/content?path=.fkteams/users/bob/notes.txt", "")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("admin read status = %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestScopeFileContentsKeepsMemberAttachmentsInArea(t *testing.T) {
+	contents := []ContentPart{
+		{Type: "text", Text: "hi"},
+		{Type: "file_url", URL: "chat-attachments/a.txt"},
+		{Type: "file_url", URL: "../bob/notes.txt"},
+		{Type: "file_url", URL: "https://example.com/a.txt"},
+	}
+	ctx := domainaccount.WithPrincipal(context.Background(), domainaccount.Principal{Username: "alice", Role: domainaccount.RoleMember})
+	got := scopeFileContents(ctx, contents)
+	if len(got) != 3 || got[1].URL != "chat-attachments/a.txt" || got[2].URL != "https://example.com/a.txt" {
+		t.Fatalf("unexpected member contents: %#v", got)
+	}
+
+	admin := domainaccount.WithPrincipal(context.Background(), domainaccount.Principal{Username: "root", Role: domainaccount.RoleAdmin})
+	if got := scopeFileContents(admin, contents); len(got) != len(contents) || got[1].URL != "chat-attachments/a.txt" {
+		t.Fatalf("admin contents should be unchanged: %#v", got)
+	}
+}
+
+func TestWithProjectScopeConfinesMemberTools(t *testing.T) {
+	workspace := setupWorkspaceDir(t)
+	proj := project.Project{Name: "demo", Root: workspace}
+
+	member := domainaccount.WithPrincipal(context.Background(), domainaccount.Principal{Username: "alice", Role: domainaccount.RoleMember})
+	ctx, err := withProjectScope(member, proj)
+	if err != nil {
+		t.Fatalf("withProjectScope() error = %v", err)
+	}
+	area := filepath.Join(workspace, ".fkteams", "users", "alice")
+	if got := project.WorkspaceDir(ctx); got != area {
+		t.Fatalf("member workspace = %q, want %q", got, area)
+	}
+	if got := project.RootDir(ctx); got != workspace {
+		t.Fatalf("member root = %q, want %q", got, workspace)
+	}
+	if info, err := os.Stat(area); err != nil || !info.IsDir() {
+		t.Fatalf("member area was not created: %v", err)
+	}
+
+	admin := domainaccount.WithPrincipal(context.Background(), domainaccount.Principal{Username: "root", Role: domainaccount.RoleAdmin})
+	ctx, err = withProjectScope(admin, proj)
+	if err != nil {
+		t.Fatalf("withProjectScope() error = %v", err)
+	}
+	if _, ok := project.UserArea(ctx); ok || project.WorkspaceDir(ctx) != workspace {
+		t.Fatalf("admin workspace = %q, want %q", project.WorkspaceDir(ctx), workspace)
+	}
+}
+
 func TestFileContentHandlers(t *testing.T) {
 	workspace := setupWorkspaceDir(t)
 	filePath := filepath.Join(workspace, "note.md")
@@ -10,6 +10,8 @@ import (
 	"fkteams/internal/adapters/storage/file/history"
 	"fkteams/internal/app/appstate"
 	appchat "fkteams/internal/app/chat"
+	appusage "fkteams/internal/app/usage"
+	domainmemory "fkteams/internal/domain/memory"
 	domainmessage "fkteams/internal/domain/message"
 	runtimeport "fkteams/internal/ports/runtime"
 	"fkteams/internal/runtime/events"
@@ -24,6 +26,7 @@ type ChatRequest struct {
 	Message   string        `json:"message"`
 	Mode      string        `json:"mode"`
 	AgentName string        `json:"agent_name"`
+	Project   string        `json:"project,omitempty"`
 	Stream    bool          `json:"stream"`
 	Contents  []ContentPart `json:"contents"`
 }
@@ -45,22 +48,36 @@ func (rt *Runtime) ChatHandlerWithState(state *appstate.State) gin.HandlerFunc {
 			Fail(c, http.StatusBadRequest, "message or contents is required")
 			return
 		}
+		req.Contents = scopeFileContents(c.Request.Context(), req.Contents)
 
 		sessionID := req.SessionID
 		if sessionID == "" {
 			sessionID = uuid.New().String()
 		}
-		mode := req.Mode
-		if mode == "" {
-			mode = "team"
+		if !rt.authorizeSession(c, sessionID) {
+			return
+		}
+		proj, err := rt.resolveSessionProject(c.Request.Context(), sessionID, req.Project)
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		mode, agentName := applyProjectDefaults(proj, req.Mode, req.AgentName)
+		if !requestAllowsAgent(c.Request.Context(), mode, agentName) {
+			Fail(c, http.StatusForbidden, fmt.Sprintf("API key is not allowed to use agent %q", agentTarget(mode, agentName)))
+			return
 		}
 
-		ctx := appstate.WithState(c.Request.Context(), state)
-		r, err := rt.resolveRunner(ctx, mode, req.AgentName)
+		ctx, err := withProjectScope(appstate.WithState(c.Request.Context(), state), proj)
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		r, err := rt.resolveRunner(ctx, mode, agentName)
 		if err != nil {
-			log.Printf("failed to resolve runner: mode=%s, agent=%s, err=%v", mode, req.AgentName, err)
+			log.Printf("failed to resolve runner: mode=%s, agent=%s, err=%v", mode, agentName, err)
 			status := http.StatusInternalServerError
-			if req.AgentName != "" {
+			if agentName != "" {
 				status = http.StatusBadRequest
 			}
 			Fail(c, status, err.Error())
@@ -70,29 +87,33 @@ func (rt *Runtime) ChatHandlerWithState(state *appstate.State) gin.HandlerFunc {
 		recorder, releaseRecorder := rt.acquireRecorder(sessionID)
 		defer releaseRecorder()
 		manager := memoryFromState(state)
-		turnInput, userDisplayText := buildChatInput(recorder, req.Message, req.Contents, manager)
+		memoryScope := chatMemoryScope(ctx, mode, agentName)
+		turnInput, userDisplayText := buildChatInput(recorder, req.Message, req.Contents, manager, memoryScope)
 
 		if req.Stream {
 			Fail(c, http.StatusBadRequest, "stream=true is not supported on /api/fkteams/chat; use /api/fkteams/stream/start")
 			return
 		}
-		rt.handleSyncChat(c, ctx, r, recorder, turnInput, sessionID, userDisplayText, manager)
+		rt.handleSyncChat(c, ctx, r, recorder, turnInput, sessionID, userDisplayText, manager, memoryScope)
 	}
 }
 
 // handleSyncChat 同步聊天响应（收集完整结果后返回）
-func (rt *Runtime) handleSyncChat(c *gin.Context, ctx context.Context, r runtimeport.Runner, recorder *eventlog.HistoryRecorder, turnInput domainmessage.TurnInput, sessionID, userDisplayText string, manager appstate.MemoryManager) {
+func (rt *Runtime) handleSyncChat(c *gin.Context, ctx context.Context, r runtimeport.Runner, recorder *eventlog.HistoryRecorder, turnInput domainmessage.TurnInput, sessionID, userDisplayText string, manager appstate.MemoryManager, memoryScope domainmemory.ScopeContext) {
 	taskCtx, taskCancel := context.WithCancel(ctx)
 	defer taskCancel()
 	taskCtx = rt.withExecutionDependencies(taskCtx)
+	taskCtx = appusage.WithScope(taskCtx, appusage.Scope{Channel: appusage.ChannelAPI})
 
 	var collectedEvents []events.Event
 
 	_, runErr := appchat.NewService().RunTurn(taskCtx, appchat.TurnRequest{
-		SessionID: sessionID,
-		Runner:    r,
-		Input:     turnInput,
-		Summary:   recorder,
+		SessionID:   sessionID,
+		Runner:      r,
+		Input:       turnInput,
+		Summary:     recorder,
+		Memory:      manager,
+		MemoryScope: memoryScope,
 		EventSink: func(event events.Event) error {
 			recorder.RecordEvent(event)
 			collectedEvents = append(collectedEvents, event)
@@ -104,7 +125,7 @@ func (rt *Runtime) handleSyncChat(c *gin.Context, ctx context.Context, r runtime
 				rt.finishErrorChat(recorder, sessionID, userDisplayText, err)
 				return
 			}
-			rt.finishChat(recorder, sessionID, userDisplayText, manager)
+			rt.finishChat(recorder, sessionID, userDisplayText, manager, memoryScope)
 		},
 	})
 	if runErr != nil {
@@ -1,8 +1,12 @@
 package handler
 
 import (
+	"context"
 	"fkteams/internal/app/appstate"
+	domainaccount "fkteams/internal/domain/account"
+	domainmemory "fkteams/internal/domain/memory"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,15 +16,26 @@ func GetMemoryListHandler() gin.HandlerFunc {
 	return GetMemoryListHandlerWithState(nil)
 }
 
-// GetMemoryListHandlerWithState 获取所有长期记忆条目。
+// GetMemoryListHandlerWithState 获取长期记忆条目，scope 查询参数按作用域过滤。
 func GetMemoryListHandlerWithState(state *appstate.State) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		filter, err := domainmemory.ParseScopeFilter(c.Query("scope"))
+		if err != nil {
+			Fail(c, http.StatusBadRequest, "参数错误: "+err.Error())
+			return
+		}
 		manager := memoryFromState(state)
 		if manager == nil {
 			OK(c, []any{})
 			return
 		}
-		OK(c, manager.List())
+		entries := make([]domainmemory.MemoryEntry, 0)
+		for _, entry := range manager.List() {
+			if filter.Match(entry.Scope) && memoryScopeVisible(c.Request.Context(), entry.Scope) {
+				entries = append(entries, entry)
+			}
+		}
+		OK(c, entries)
 	}
 }
 
@@ -45,6 +60,10 @@ func DeleteMemoryHandlerWithState(state *appstate.State) gin.HandlerFunc {
 			Fail(c, http.StatusBadRequest, "参数错误: summary 不能为空")
 			return
 		}
+		if !memorySummaryAccessible(c.Request.Context(), manager, req.Summary) {
+			Fail(c, http.StatusNotFound, "未找到匹配的记忆条目")
+			return
+		}
 
 		deleted := manager.Delete(req.Summary)
 		if deleted > 0 {
@@ -55,6 +74,50 @@ func DeleteMemoryHandlerWithState(state *appstate.State) gin.HandlerFunc {
 	}
 }
 
+// MoveMemoryHandlerWithState 将指定摘要的记忆条目移动到其他作用域。
+func MoveMemoryHandlerWithState(state *appstate.State) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := memoryFromState(state)
+		if manager == nil {
+			Fail(c, http.StatusBadRequest, "长期记忆未启用")
+			return
+		}
+
+		var req struct {
+			Summary string `json:"summary" binding:"required"`
+			Scope   string `json:"scope" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			Fail(c, http.StatusBadRequest, "参数错误: summary 和 scope 不能为空")
+			return
+		}
+		scope, err := domainmemory.ParseScope(req.Scope)
+		if err != nil {
+			Fail(c, http.StatusBadRequest, "参数错误: "+err.Error())
+			return
+		}
+		if !memoryScopeVisible(c.Request.Context(), scope) {
+			Fail(c, http.StatusForbidden, "不能移动到其他账号的记忆作用域")
+			return
+		}
+		if !memorySummaryAccessible(c.Request.Context(), manager, req.Summary) {
+			Fail(c, http.StatusNotFound, "未找到匹配的记忆条目")
+			return
+		}
+
+		moved, err := manager.Move(req.Summary, scope)
+		if err != nil {
+			Fail(c, http.StatusBadRequest, "参数错误: "+err.Error())
+			return
+		}
+		if moved > 0 {
+			OK(c, gin.H{"moved": moved, "scope": scope.String()})
+		} else {
+			Fail(c, http.StatusNotFound, "未找到匹配的记忆条目")
+		}
+	}
+}
+
 // ClearMemoryHandler 清空所有长期记忆
 func ClearMemoryHandler() gin.HandlerFunc {
 	return ClearMemoryHandlerWithState(nil)
@@ -80,3 +143,43 @@ func memoryFromState(state *appstate.State) appstate.MemoryManager {
 	}
 	return state.Memory()
 }
+
+// webUserScopeKey 返回 Web 账号的用户记忆作用域 key。
+func webUserScopeKey(username string) string {
+	return "web:" + username
+}
+
+// memoryScopeVisible 判断请求账号能否查看作用域中的记忆：共享作用域对所有账号可见，
+// Web 账号的用户作用域只对本人和管理员可见。
+func memoryScopeVisible(ctx context.Context, scope domainmemory.Scope) bool {
+	principal, ok := domainaccount.PrincipalFromContext(ctx)
+	if !ok || principal.IsAdmin() || scope.Kind != domainmemory.ScopeUser || !strings.HasPrefix(scope.Key, "web:") {
+		return true
+	}
+	return scope.Key == webUserScopeKey(principal.Username)
+}
+
+// memorySummaryAccessible 判断摘要匹配的记忆条目是否都对请求账号可见，避免误删其他账号的记忆。
+func memorySummaryAccessible(ctx context.Context, manager appstate.MemoryManager, summary string) bool {
+	summary = strings.TrimSpace(summary)
+	for _, entry := range manager.List() {
+		if entry.Summary == summary && !memoryScopeVisible(ctx, entry.Scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// chatMemoryScope 返回 Web 对话的记忆作用域：当前项目，以及指定的智能体，未指定时使用工作模式；
+// 已登录时同时带上账号的用户作用域。
+func chatMemoryScope(ctx context.Context, mode, agentName string) domainmemory.ScopeContext {
+	agent := agentName
+	if agent == "" {
+		agent = mode
+	}
+	scope := domainmemory.ScopeContext{Workspace: projectName(ctx), Agent: agent}
+	if owner := domainaccount.OwnerFromContext(ctx); owner != "" {
+		scope.User = webUserScopeKey(owner)
+	}
+	return scope
+}
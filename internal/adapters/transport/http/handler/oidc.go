@@ -0,0 +1,340 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"fkteams/internal/adapters/identity/oidc"
+	appaccount "fkteams/internal/app/account"
+	"fkteams/internal/app/config"
+	domainaccount "fkteams/internal/domain/account"
+	"fkteams/internal/domain/apperror"
+	"fkteams/internal/runtime/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oidcProviderName    = "oidc"
+	oidcCallbackPath    = "/api/fkteams/oidc/callback"
+	oidcStateCookieName = "fk_oidc_state"
+	oidcLoginTTL        = 10 * time.Minute
+	oidcProviderTTL     = time.Hour
+	maxPendingOIDCLogin = 1000
+)
+
+// pendingOIDCLogin 保存一次授权跳转的 PKCE verifier、nonce 和登录后返回的地址。
+type pendingOIDCLogin struct {
+	verifier    string
+	nonce       string
+	next        string
+	redirectURL string
+	expiresAt   time.Time
+}
+
+var oidcLogins = struct {
+	sync.Mutex
+	pending     map[string]pendingOIDCLogin
+	provider    *oidc.Provider
+	issuer      string
+	discoveryAt time.Time
+}{pending: make(map[string]pendingOIDCLogin)}
+
+// oidcProvider 返回缓存的身份提供方，issuer 变化或缓存过期时重新读取发现文档。
+func oidcProvider(ctx context.Context, issuer string) (*oidc.Provider, error) {
+	oidcLogins.Lock()
+	defer oidcLogins.Unlock()
+	if oidcLogins.provider != nil && oidcLogins.issuer == issuer && time.Since(oidcLogins.discoveryAt) < oidcProviderTTL {
+		return oidcLogins.provider, nil
+	}
+	provider, err := oidc.Discover(ctx, issuer, nil)
+	if err != nil {
+		return nil, err
+	}
+	oidcLogins.provider = provider
+	oidcLogins.issuer = issuer
+	oidcLogins.discoveryAt = time.Now()
+	return provider, nil
+}
+
+func storeOIDCLogin(state string, login pendingOIDCLogin) bool {
+	now := time.Now()
+	oidcLogins.Lock()
+	defer oidcLogins.Unlock()
+	for key, pending := range oidcLogins.pending {
+		if now.After(pending.expiresAt) {
+			delete(oidcLogins.pending, key)
+		}
+	}
+	if len(oidcLogins.pending) >= maxPendingOIDCLogin {
+		return false
+	}
+	oidcLogins.pending[state] = login
+	return true
+}
+
+// takeOIDCLogin 取出并删除 state 对应的登录，每个 state 只能使用一次。
+func takeOIDCLogin(state string) (pendingOIDCLogin, bool) {
+	oidcLogins.Lock()
+	defer oidcLogins.Unlock()
+	login, ok := oidcLogins.pending[state]
+	delete(oidcLogins.pending, state)
+	if !ok || time.Now().After(login.expiresAt) {
+		return pendingOIDCLogin{}, false
+	}
+	return login, true
+}
+
+func oidcClientConfig(cfg config.ServerOIDC, redirectURL string) oidc.Config {
+	return oidc.Config{
+		Issuer:       cfg.Issuer,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       cfg.Scopes,
+	}
+}
+
+// oidcEnabled 返回启用的单点登录配置；未启用认证或单点登录时返回 false。
+func oidcEnabled() (config.ServerOIDC, bool) {
+	authEnabled, err := AuthEnabled()
+	if err != nil || !authEnabled {
+		return config.ServerOIDC{}, false
+	}
+	cfg := config.Get().Server.Auth.OIDC
+	return cfg, cfg.Enabled
+}
+
+// LoginOptionsHandler 返回登录页可用的登录方式，该接口无需登录。
+func LoginOptionsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authEnabled, _ := AuthEnabled()
+		cfg, enabled := oidcEnabled()
+		displayName := strings.TrimSpace(cfg.DisplayName)
+		if displayName == "" {
+			displayName = "单点登录"
+		}
+		OK(c, gin.H{
+			"auth_enabled": authEnabled,
+			"password":     authEnabled,
+			"oidc": gin.H{
+				"enabled":      enabled,
+				"display_name": displayName,
+			},
+		})
+	}
+}
+
+// OIDCLoginHandler 生成 state、nonce 和 PKCE verifier，跳转到身份提供方的授权页。
+func OIDCLoginHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg, enabled := oidcEnabled()
+		if !enabled {
+			Fail(c, http.StatusNotFound, "single sign-on is disabled")
+			return
+		}
+		provider, err := oidcProvider(c.Request.Context(), cfg.Issuer)
+		if err != nil {
+			log.Warnf("oidc discovery failed: issuer=%s, err=%v", cfg.Issuer, err)
+			redirectLoginError(c, "sso_unavailable")
+			return
+		}
+		state, errState := randomHex(32)
+		nonce, errNonce := randomHex(32)
+		if errState != nil || errNonce != nil {
+			Fail(c, http.StatusInternalServerError, "failed to start single sign-on")
+			return
+		}
+		redirectURL := cfg.RedirectURL
+		if redirectURL == "" {
+			redirectURL = requestBaseURL(c.Request) + oidcCallbackPath
+		}
+		login := pendingOIDCLogin{
+			verifier:    oidc.NewVerifier(),
+			nonce:       nonce,
+			next:        safeReturnPath(c.Query("next")),
+			redirectURL: redirectURL,
+			expiresAt:   time.Now().Add(oidcLoginTTL),
+		}
+		if !storeOIDCLogin(state, login) {
+			Fail(c, http.StatusTooManyRequests, "too many pending single sign-on requests")
+			return
+		}
+		// state Cookie 将回调绑定到发起登录的浏览器，防止登录 CSRF；
+		// 回调来自身份提供方的跨站跳转，因此使用 Lax
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     oidcStateCookieName,
+			Value:    state,
+			Path:     oidcCallbackPath,
+			HttpOnly: true,
+			Secure:   requestUsesHTTPS(c.Request),
+			SameSite: http.SameSiteLaxMode,
+			MaxAge:   int(oidcLoginTTL / time.Second),
+		})
+		c.Header("Cache-Control", "no-store")
+		c.Redirect(http.StatusFound, provider.AuthCodeURL(oidcClientConfig(cfg, redirectURL), state, nonce, login.verifier))
+	}
+}
+
+// OIDCCallbackHandler 校验授权回调，交换 ID Token 并签发与密码登录相同的认证 Cookie。
+func OIDCCallbackHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+		cfg, enabled := oidcEnabled()
+		if !enabled {
+			Fail(c, http.StatusNotFound, "single sign-on is disabled")
+			return
+		}
+		http.SetCookie(c.Writer, &http.Cookie{Name: oidcStateCookieName, Path: oidcCallbackPath, MaxAge: -1, Expires: time.Unix(1, 0)})
+
+		if idpError := c.Query("error"); idpError != "" {
+			log.Printf("oidc login rejected by provider: error=%s, ip=%s", idpError, c.ClientIP())
+			redirectLoginError(c, "sso_denied")
+			return
+		}
+		state := c.Query("state")
+		cookieState, _ := c.Cookie(oidcStateCookieName)
+		if state == "" || cookieState != state {
+			log.Printf("oidc callback state mismatch: ip=%s", c.ClientIP())
+			redirectLoginError(c, "sso_failed")
+			return
+		}
+		login, ok := takeOIDCLogin(state)
+		if !ok {
+			redirectLoginError(c, "sso_expired")
+			return
+		}
+
+		principal, err := completeOIDCLogin(c.Request.Context(), cfg, login, c.Query("code"))
+		if err != nil {
+			log.Warnf("oidc login failed: ip=%s, err=%v", c.ClientIP(), err)
+			if apperror.IsCode(err, apperror.CodeForbidden) || apperror.IsCode(err, apperror.CodeConflict) {
+				redirectLoginError(c, "sso_forbidden")
+				return
+			}
+			redirectLoginError(c, "sso_failed")
+			return
+		}
+		token := generateToken(principal.Username)
+		if token == "" {
+			redirectLoginError(c, "sso_failed")
+			return
+		}
+		log.Printf("oidc login: username=%s, role=%s, ip=%s", principal.Username, principal.Role, c.ClientIP())
+		setAuthCookie(c, token, int(authTokenTTL/time.Second))
+		// 认证 Cookie 为 SameSite=Strict，跨站跳转链中的后续请求不会携带；
+		// 返回同源页面再跳转，使目标页面请求能带上新 Cookie
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusOK)
+		_ = oidcRedirectPage.Execute(c.Writer, login.next)
+	}
+}
+
+var oidcRedirectPage = template.Must(template.New("oidc-redirect").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><meta http-equiv="refresh" content="0;url={{.}}"><title>登录中</title></head>
+<body><a href="{{.}}">继续</a></body></html>
+`))
+
+// completeOIDCLogin 交换授权码、校验 ID Token，并按声明映射创建或更新账号。
+func completeOIDCLogin(ctx context.Context, cfg config.ServerOIDC, login pendingOIDCLogin, code string) (domainaccount.Principal, error) {
+	if code == "" {
+		return domainaccount.Principal{}, errors.New("authorization code is missing")
+	}
+	provider, err := oidcProvider(ctx, cfg.Issuer)
+	if err != nil {
+		return domainaccount.Principal{}, err
+	}
+	rawIDToken, err := provider.Exchange(ctx, oidcClientConfig(cfg, login.redirectURL), code, login.verifier)
+	if err != nil {
+		return domainaccount.Principal{}, err
+	}
+	claims, err := provider.Verify(ctx, rawIDToken, cfg.ClientID, login.nonce)
+	if err != nil {
+		return domainaccount.Principal{}, err
+	}
+	username, role, err := mapOIDCClaims(cfg, claims)
+	if err != nil {
+		return domainaccount.Principal{}, err
+	}
+	user, err := accountService().ProvisionExternal(ctx, appaccount.ExternalIdentity{
+		Provider: oidcProviderName,
+		Subject:  provider.Issuer() + "|" + claims.String("sub"),
+		Username: username,
+		Role:     role,
+	}, config.Get().Server.Auth.Username)
+	if err != nil {
+		return domainaccount.Principal{}, err
+	}
+	return domainaccount.Principal{Username: user.Username, Role: user.Role}, nil
+}
+
+// mapOIDCClaims 按配置从声明中取出用户名，并把用户组映射为权限最高的角色。
+func mapOIDCClaims(cfg config.ServerOIDC, claims oidc.Claims) (string, domainaccount.Role, error) {
+	username := strings.TrimSpace(claims.String(cfg.UsernameClaimName()))
+	if username == "" {
+		return "", "", apperror.Errorf(apperror.CodeForbidden, "id token has no %q claim", cfg.UsernameClaimName())
+	}
+	if err := domainaccount.ValidateUsername(username); err != nil {
+		return "", "", apperror.Wrap(apperror.CodeForbidden, "id token username is not a valid account name", err)
+	}
+	role := domainaccount.Role(cfg.DefaultRole)
+	for _, group := range claims.Strings(cfg.GroupsClaimName()) {
+		mapped, ok := cfg.GroupRoles[group]
+		if ok && roleRank(domainaccount.Role(mapped)) > roleRank(role) {
+			role = domainaccount.Role(mapped)
+		}
+	}
+	if !role.Valid() {
+		return "", "", apperror.Errorf(apperror.CodeForbidden, "user %q has no group mapped to a role", username)
+	}
+	return username, role, nil
+}
+
+func roleRank(role domainaccount.Role) int {
+	switch role {
+	case domainaccount.RoleAdmin:
+		return 3
+	case domainaccount.RoleMember:
+		return 2
+	case domainaccount.RoleViewer:
+		return 1
+	}
+	return 0
+}
+
+// redirectLoginError 返回登录页并附带错误码，由登录页展示提示。
+func redirectLoginError(c *gin.Context, code string) {
+	c.Redirect(http.StatusFound, "/login?error="+url.QueryEscape(code))
+}
+
+// safeReturnPath 只允许站内路径作为登录后的返回地址，防止开放重定向。
+func safeReturnPath(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") || strings.HasPrefix(next, "/login") {
+		return "/chat"
+	}
+	return next
+}
+
+func requestBaseURL(request *http.Request) string {
+	scheme := "http"
+	if requestUsesHTTPS(request) {
+		scheme = "https"
+	}
+	return scheme + "://" + request.Host
+}
+
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"fkteams/internal/adapters/identity/oidc/oidctest"
+	"fkteams/internal/app/config"
+	domainaccount "fkteams/internal/domain/account"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestOIDCLoginIssuesAuthCookie(t *testing.T) {
+	idp := oidctest.New("fkteams", "client-secret")
+	defer idp.Close()
+	router := newOIDCTestRouter(t, idp)
+
+	resp := performJSON(router, http.MethodGet, "/api/fkteams/login/options", "")
+	var options struct {
+		Data struct {
+			OIDC struct {
+				Enabled     bool   `json:"enabled"`
+				DisplayName string `json:"display_name"`
+			} `json:"oidc"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &options); err != nil || !options.Data.OIDC.Enabled || options.Data.OIDC.DisplayName != "Company SSO" {
+		t.Fatalf("login options = %s (%v)", resp.Body.String(), err)
+	}
+
+	idp.SetUser(map[string]any{"sub": "u-1", "preferred_username": "alice", "groups": []string{"staff", "eng"}})
+	resp = completeTestOIDCLogin(t, router, idp, "/chat/session-1")
+	if resp.Code != http.StatusOK {
+		t.Fatalf("callback status = %d, location = %q", resp.Code, resp.Header().Get("Location"))
+	}
+	if !strings.Contains(resp.Body.String(), "/chat/session-1") {
+		t.Fatalf("callback body does not return to next path: %s", resp.Body.String())
+	}
+	token := authCookieValue(resp)
+	principal, ok := AuthenticateToken(token)
+	if !ok || principal.Username != "alice" || principal.Role != domainaccount.RoleMember {
+		t.Fatalf("principal = %#v, ok = %v", principal, ok)
+	}
+	user, err := accountService().Get(t.Context(), "alice")
+	if err != nil || !user.External() {
+		t.Fatalf("provisioned user = %#v, err = %v", user, err)
+	}
+
+	// 用户组变化后重新登录，角色随映射更新
+	idp.SetUser(map[string]any{"sub": "u-1", "preferred_username": "alice", "groups": []string{"eng", "eng-admins"}})
+	resp = completeTestOIDCLogin(t, router, idp, "")
+	if principal, ok := AuthenticateToken(authCookieValue(resp)); !ok || principal.Role != domainaccount.RoleAdmin {
+		t.Fatalf("re-login principal = %#v, ok = %v", principal, ok)
+	}
+	if !strings.Contains(resp.Body.String(), "/chat") {
+		t.Fatalf("default return path missing: %s", resp.Body.String())
+	}
+}
+
+func TestOIDCLoginRejectsUnmappedAndReservedUsers(t *testing.T) {
+	idp := oidctest.New("fkteams", "client-secret")
+	defer idp.Close()
+	router := newOIDCTestRouter(t, idp)
+
+	idp.SetUser(map[string]any{"sub": "u-2", "preferred_username": "bob", "groups": []string{"sales"}})
+	if resp := completeTestOIDCLogin(t, router, idp, ""); resp.Header().Get("Location") != "/login?error=sso_forbidden" {
+		t.Fatalf("unmapped user status = %d, location = %q", resp.Code, resp.Header().Get("Location"))
+	}
+
+	idp.SetUser(map[string]any{"sub": "u-3", "preferred_username": "admin", "groups": []string{"eng-admins"}})
+	if resp := completeTestOIDCLogin(t, router, idp, ""); resp.Header().Get("Location") != "/login?error=sso_forbidden" {
+		t.Fatalf("builtin admin takeover status = %d, location = %q", resp.Code, resp.Header().Get("Location"))
+	}
+	if resp := performJSON(router, http.MethodGet, "/api/fkteams/oidc/callback?code=x&state=forged", ""); resp.Header().Get("Location") != "/login?error=sso_failed" {
+		t.Fatalf("forged state location = %q", resp.Header().Get("Location"))
+	}
+}
+
+func TestOIDCCallbackRejectsReplayedState(t *testing.T) {
+	idp := oidctest.New("fkteams", "client-secret")
+	defer idp.Close()
+	router := newOIDCTestRouter(t, idp)
+	idp.SetUser(map[string]any{"sub": "u-1", "preferred_username": "alice", "groups": []string{"eng"}})
+
+	stateCookie, callback := startTestOIDCLogin(t, router, idp, "")
+	if resp := requestWithCookie(router, callback, stateCookie); resp.Code != http.StatusOK {
+		t.Fatalf("first callback status = %d, location = %q", resp.Code, resp.Header().Get("Location"))
+	}
+	if resp := requestWithCookie(router, callback, stateCookie); resp.Header().Get("Location") != "/login?error=sso_expired" {
+		t.Fatalf("replayed callback status = %d, location = %q", resp.Code, resp.Header().Get("Location"))
+	}
+}
+
+func newOIDCTestRouter(t *testing.T, idp *oidctest.Server) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	saveHandlerConfig(t, config.Config{Server: config.Server{Auth: config.ServerAuth{
+		Enabled:  true,
+		Username: "admin",
+		Password: "secret",
+		Secret:   "token-secret",
+		OIDC: config.ServerOIDC{
+			Enabled:      true,
+			DisplayName:  "Company SSO",
+			Issuer:       idp.URL,
+			ClientID:     idp.ClientID,
+			ClientSecret: idp.ClientSecret,
+			GroupRoles:   map[string]string{"eng": "member", "eng-admins": "admin"},
+		},
+	}}})
+	router := gin.New()
+	router.GET("/api/fkteams/login/options", LoginOptionsHandler())
+	router.GET("/api/fkteams/oidc/login", OIDCLoginHandler())
+	router.GET("/api/fkteams/oidc/callback", OIDCCallbackHandler())
+	return router
+}
+
+// startTestOIDCLogin 发起登录并经由身份提供方授权，返回 state Cookie 和回调地址。
+func startTestOIDCLogin(t *testing.T, router http.Handler, idp *oidctest.Server, next string) (*http.Cookie, string) {
+	t.Helper()
+	resp := performJSON(router, http.MethodGet, "/api/fkteams/oidc/login?next="+url.QueryEscape(next), "")
+	if resp.Code != http.StatusFound || !strings.HasPrefix(resp.Header().Get("Location"), idp.URL+"/authorize") {
+		t.Fatalf("login status = %d, location = %q", resp.Code, resp.Header().Get("Location"))
+	}
+	var stateCookie *http.Cookie
+	for _, cookie := range resp.Result().Cookies() {
+		if cookie.Name == oidcStateCookieName {
+			stateCookie = cookie
+		}
+	}
+	if stateCookie == nil || stateCookie.SameSite != http.SameSiteLaxMode || !stateCookie.HttpOnly {
+		t.Fatalf("state cookie = %#v", stateCookie)
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	idpResp, err := client.Get(resp.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	defer idpResp.Body.Close()
+	callback, err := url.Parse(idpResp.Header.Get("Location"))
+	if err != nil || idpResp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d, location = %q", idpResp.StatusCode, idpResp.Header.Get("Location"))
+	}
+	if callback.Path != oidcCallbackPath {
+		t.Fatalf("callback path = %q", callback.Path)
+	}
+	return stateCookie, callback.RequestURI()
+}
+
+func completeTestOIDCLogin(t *testing.T, router http.Handler, idp *oidctest.Server, next string) *httptest.ResponseRecorder {
+	t.Helper()
+	stateCookie, callback := startTestOIDCLogin(t, router, idp, next)
+	return requestWithCookie(router, callback, stateCookie)
+}
+
+func requestWithCookie(router http.Handler, target string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.AddCookie(cookie)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func authCookieValue(resp *httptest.ResponseRecorder) string {
+	for _, cookie := range resp.Result().Cookies() {
+		if cookie.Name == authCookieName {
+			return cookie.Value
+		}
+	}
+	return ""
+}
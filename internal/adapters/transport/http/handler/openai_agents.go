@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	appchat "fkteams/internal/app/chat"
+	domainmessage "fkteams/internal/domain/message"
+	"fkteams/internal/runtime/events"
+	"fkteams/internal/runtime/log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// openAIChatRequest 是虚拟模型支持的 chat/completions 请求子集。
+// tools / tool_choice 等字段被忽略：智能体使用自身配置的工具。
+type openAIChatRequest struct {
+	Model         string              `json:"model"`
+	Messages      []openAIChatMessage `json:"messages"`
+	Stream        bool                `json:"stream"`
+	StreamOptions *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options"`
+}
+
+type openAIChatMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+	Name    string          `json:"name,omitempty"`
+}
+
+type openAIContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	ImageURL *struct {
+		URL    string `json:"url"`
+		Detail string `json:"detail,omitempty"`
+	} `json:"image_url,omitempty"`
+}
+
+// handleVirtualChatCompletions 通过 chat.Service 执行虚拟模型请求，并以 OpenAI 格式返回。
+func (rt *Runtime) handleVirtualChatCompletions(c *gin.Context, model virtualModel, body []byte) {
+	var req openAIChatRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		c.JSON(http.StatusBadRequest, openAIError("invalid_request_error", "invalid JSON"))
+		return
+	}
+	input, err := openAITurnInput(req.Messages)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, openAIError("invalid_request_error", err.Error()))
+		return
+	}
+
+	completionID := "chatcmpl-" + uuid.NewString()
+	created := time.Now().Unix()
+	if req.Stream {
+		includeUsage := req.StreamOptions != nil && req.StreamOptions.IncludeUsage
+		rt.streamVirtualChatCompletions(c, model, input, completionID, created, includeUsage)
+		return
+	}
+
+	var (
+		content   strings.Builder
+		reasoning strings.Builder
+		usage     virtualModelUsage
+		progress  []map[string]any
+	)
+	err = rt.runVirtualModelTurn(c.Request.Context(), model, input, func(event events.Event) error {
+		usage.add(event)
+		if isVirtualModelAnswer(event) {
+			if event.Type == events.EventAssistantReasoning {
+				reasoning.WriteString(event.Content)
+			} else {
+				content.WriteString(event.Content)
+			}
+		}
+		if item := virtualModelProgress(event); item != nil {
+			progress = append(progress, item)
+		}
+		return nil
+	})
+	if err != nil {
+		writeVirtualModelError(c, model, err)
+		return
+	}
+
+	message := gin.H{"role": "assistant", "content": content.String()}
+	if reasoning.Len() > 0 {
+		message["reasoning_content"] = reasoning.String()
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"id":      completionID,
+		"object":  "chat.completion",
+		"created": created,
+		"model":   model.ID,
+		"choices": []gin.H{{
+			"index":         0,
+			"message":       message,
+			"finish_reason": "stop",
+		}},
+		"usage":   usage,
+		"fkteams": gin.H{"progress": progress},
+	})
+}
+
+func (rt *Runtime) streamVirtualChatCompletions(c *gin.Context, model virtualModel, input domainmessage.TurnInput, completionID string, created int64, includeUsage bool) {
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.WriteHeader(http.StatusOK)
+	clearSSEWriteDeadline(c.Writer)
+
+	chunk := func(delta gin.H, finishReason any) gin.H {
+		return gin.H{
+			"id":      completionID,
+			"object":  "chat.completion.chunk",
+			"created": created,
+			"model":   model.ID,
+			"choices": []gin.H{{
+				"index":         0,
+				"delta":         delta,
+				"finish_reason": finishReason,
+			}},
+		}
+	}
+	write := func(payload any) error {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		if !writeSSEChunk(c.Writer, "data: "+string(data)+"\n\n") {
+			return fmt.Errorf("write SSE chunk: %w", errClientGone)
+		}
+		return nil
+	}
+
+	if write(chunk(gin.H{"role": "assistant", "content": ""}, nil)) != nil {
+		return
+	}
+	var usage virtualModelUsage
+	err := rt.runVirtualModelTurn(c.Request.Context(), model, input, func(event events.Event) error {
+		usage.add(event)
+		if isVirtualModelAnswer(event) {
+			key := "content"
+			if event.Type == events.EventAssistantReasoning {
+				key = "reasoning_content"
+			}
+			return write(chunk(gin.H{key: event.Content}, nil))
+		}
+		if progress := virtualModelProgress(event); progress != nil {
+			payload := chunk(gin.H{}, nil)
+			payload["fkteams"] = progress
+			return write(payload)
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, errClientGone) {
+			log.Printf("[openai-agent] virtual model turn failed: model=%s, err=%v", model.ID, err)
+			_ = write(openAIError("server_error", err.Error()))
+		}
+		return
+	}
+	if write(chunk(gin.H{}, "stop")) != nil {
+		return
+	}
+	if includeUsage {
+		if write(gin.H{
+			"id":      completionID,
+			"object":  "chat.completion.chunk",
+			"created": created,
+			"model":   model.ID,
+			"choices": []gin.H{},
+			"usage":   usage,
+		}) != nil {
+			return
+		}
+	}
+	writeSSEChunk(c.Writer, "data: [DONE]\n\n")
+}
+
+var errClientGone = errors.New("client disconnected")
+
+func writeVirtualModelError(c *gin.Context, model virtualModel, err error) {
+	var modelErr *virtualModelError
+	if errors.As(err, &modelErr) && modelErr.Status == http.StatusNotFound {
+		c.JSON(http.StatusNotFound, openAIError("model_not_found", fmt.Sprintf("model %q not found", model.ID)))
+		return
+	}
+	log.Printf("[openai-agent] virtual model turn failed: model=%s, err=%v", model.ID, err)
+	c.JSON(http.StatusInternalServerError, openAIError("server_error", err.Error()))
+}
+
+// openAITurnInput 将 OpenAI messages 转换为回合输入：最后一条 user 消息作为本轮输入，之前的消息作为上下文。
+func openAITurnInput(messages []openAIChatMessage) (domainmessage.TurnInput, error) {
+	converted := make([]domainmessage.Message, 0, len(messages))
+	for i, raw := range messages {
+		role := domainmessage.Role(raw.Role)
+		switch role {
+		case "developer":
+			role = domainmessage.RoleSystem
+		case domainmessage.RoleSystem, domainmessage.RoleUser, domainmessage.RoleAssistant:
+		default:
+			// 工具消息属于客户端自身的工具调用，智能体无法复现，跳过。
+			continue
+		}
+		msg, err := openAIMessageContent(raw.Content)
+		if err != nil {
+			return domainmessage.TurnInput{}, fmt.Errorf("messages[%d]: %w", i, err)
+		}
+		msg.Role = role
+		msg.Name = raw.Name
+		converted = append(converted, msg)
+	}
+	if len(converted) == 0 || converted[len(converted)-1].Role != domainmessage.RoleUser {
+		return domainmessage.TurnInput{}, fmt.Errorf("the last message must be a user message")
+	}
+	last := len(converted) - 1
+	return domainmessage.TurnInput{Context: converted[:last], Message: converted[last]}, nil
+}
+
+func openAIMessageContent(raw json.RawMessage) (domainmessage.Message, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return domainmessage.Message{}, nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return domainmessage.Message{Content: text}, nil
+	}
+	var parts []openAIContentPart
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return domainmessage.Message{}, fmt.Errorf("content must be a string or an array of content parts")
+	}
+	var msg domainmessage.Message
+	for _, part := range parts {
+		switch part.Type {
+		case "text":
+			msg.ContentParts = append(msg.ContentParts, appchat.TextPart(part.Text))
+		case "image_url":
+			if part.ImageURL == nil || part.ImageURL.URL == "" {
+				return domainmessage.Message{}, fmt.Errorf("image_url part requires url")
+			}
+			msg.ContentParts = append(msg.ContentParts, imageContentPart(part.ImageURL.URL, part.ImageURL.Detail))
+		default:
+			return domainmessage.Message{}, fmt.Errorf("unsupported content part type %q", part.Type)
+		}
+	}
+	return msg, nil
+}
+
+// imageContentPart 将 URL 或 data URI 图片转换为内容片段。
+func imageContentPart(url, detail string) domainmessage.ContentPart {
+	if rest, ok := strings.CutPrefix(url, "data:"); ok {
+		if mimeType, data, ok := strings.Cut(rest, ";base64,"); ok {
+			return appchat.ImageBase64Part(data, mimeType)
+		}
+	}
+	if detail == "" {
+		return appchat.ImageURLPart(url)
+	}
+	return appchat.ImageURLPart(url, detail)
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	appagent "fkteams/internal/app/agent"
+	domainmessage "fkteams/internal/domain/message"
+	runtimeport "fkteams/internal/ports/runtime"
+	storageport "fkteams/internal/ports/storage"
+	"fkteams/internal/runtime/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+type scriptedRunner struct {
+	input  domainmessage.TurnInput
+	events []events.Event
+}
+
+func (r *scriptedRunner) Run(_ context.Context, input domainmessage.TurnInput, opts runtimeport.RunOptions) (*runtimeport.RunResult, error) {
+	r.input = input
+	for _, event := range r.events {
+		if err := opts.Sink(event); err != nil {
+			return nil, err
+		}
+	}
+	return &runtimeport.RunResult{}, nil
+}
+
+func newVirtualModelTestRuntime(t *testing.T, runner runtimeport.Runner) *Runtime {
+	t.Helper()
+	rt := newTestRuntime(t)
+	if _, err := rt.RunnerCache.GetOrCreate(appagent.ModeTeam, func() (runtimeport.Runner, error) {
+		return runner, nil
+	}); err != nil {
+		t.Fatalf("seed runner cache: %v", err)
+	}
+	return rt
+}
+
+func TestParseVirtualModel(t *testing.T) {
+	tests := []struct {
+		id        string
+		ok        bool
+		mode      string
+		agentName string
+	}{
+		{id: "fkteams/team", ok: true, mode: "team"},
+		{id: "fkteams/deep", ok: true, mode: "deep"},
+		{id: "fkteams/agent:coder", ok: true, mode: "team", agentName: "coder"},
+		{id: "fkteams/agent:", ok: false},
+		{id: "fkteams/unknown", ok: false},
+		{id: "deepseek-chat", ok: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseVirtualModel(tt.id)
+		if ok != tt.ok || got.Mode != tt.mode || got.AgentName != tt.agentName {
+			t.Fatalf("parseVirtualModel(%q) = %#v, %v", tt.id, got, ok)
+		}
+	}
+}
+
+func TestOpenAITurnInputUsesLastUserMessage(t *testing.T) {
+	input, err := openAITurnInput([]openAIChatMessage{
+		{Role: "system", Content: []byte(`"be brief"`)},
+		{Role: "user", Content: []byte(`"hi"`)},
+		{Role: "assistant", Content: []byte(`"hello"`)},
+		{Role: "user", Content: []byte(`[{"type":"text","text":"look"},{"type":"image_url","image_url":{"url":"data:image/png;base64,AAAA"}}]`)},
+	})
+	if err != nil {
+		t.Fatalf("openAITurnInput: %v", err)
+	}
+	if len(input.Context) != 3 || input.Context[0].Role != domainmessage.RoleSystem {
+		t.Fatalf("context = %#v", input.Context)
+	}
+	parts := input.Message.ContentParts
+	if len(parts) != 2 || parts[1].Base64Data != "AAAA" || parts[1].MIMEType != "image/png" {
+		t.Fatalf("message parts = %#v", parts)
+	}
+
+	if _, err := openAITurnInput([]openAIChatMessage{{Role: "assistant", Content: []byte(`"x"`)}}); err == nil {
+		t.Fatal("expected error when last message is not from user")
+	}
+}
+
+func TestOpenAIChatCompletionsRunsVirtualModel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	runner := &scriptedRunner{events: []events.Event{
+		{Type: events.EventToolCallStarted, ToolName: "agent_coder"},
+		{Type: events.EventAssistantText, Content: "member draft", MemberCallID: "call-1"},
+		{Type: events.EventAssistantText, Content: "Hello"},
+		{Type: events.EventAssistantText, Content: " world"},
+		events.Usage("leader", "root", 10, 5, 15),
+	}}
+	rt := newVirtualModelTestRuntime(t, runner)
+
+	router := gin.New()
+	router.POST("/v1/chat/completions", rt.OpenAIChatCompletionsHandler())
+
+	resp := performJSON(router, http.MethodPost, "/v1/chat/completions", `{"model":"fkteams/team","messages":[{"role":"user","content":"hi"}]}`)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", resp.Code, resp.Body.String())
+	}
+	body := resp.Body.String()
+	if !strings.Contains(body, `"content":"Hello world"`) || strings.Contains(body, "member draft") {
+		t.Fatalf("unexpected completion body: %s", body)
+	}
+	if !strings.Contains(body, `"total_tokens":15`) || !strings.Contains(body, `"tool_name":"agent_coder"`) {
+		t.Fatalf("missing usage or progress: %s", body)
+	}
+	if runner.input.Message.Content != "hi" {
+		t.Fatalf("runner input = %#v", runner.input)
+	}
+}
+
+func TestOpenAIChatCompletionsStreamsVirtualModelChunks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	runner := &scriptedRunner{events: []events.Event{
+		{Type: events.EventAssistantText, Content: "Hi"},
+		events.Usage("leader", "root", 3, 2, 5),
+	}}
+	rt := newVirtualModelTestRuntime(t, runner)
+
+	router := gin.New()
+	router.POST("/v1/chat/completions", rt.OpenAIChatCompletionsHandler())
+
+	resp := performJSON(router, http.MethodPost, "/v1/chat/completions", `{"model":"fkteams/team","stream":true,"stream_options":{"include_usage":true},"messages":[{"role":"user","content":"hi"}]}`)
+	body := resp.Body.String()
+	for _, want := range []string{`"object":"chat.completion.chunk"`, `"delta":{"content":"Hi"}`, `"finish_reason":"stop"`, `"total_tokens":5`, "data: [DONE]"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("stream body missing %s:\n%s", want, body)
+		}
+	}
+}
+
+// checkpointingRunner 模拟回合中断时写入 checkpoint。
+type checkpointingRunner struct {
+	store func() storageport.CheckpointStore
+}
+
+func (r *checkpointingRunner) Run(ctx context.Context, _ domainmessage.TurnInput, opts runtimeport.RunOptions) (*runtimeport.RunResult, error) {
+	if err := r.store().Set(ctx, "team:"+opts.CheckpointID, []byte("state")); err != nil {
+		return nil, err
+	}
+	if err := opts.Sink(events.Event{Type: events.EventAssistantText, Content: "done"}); err != nil {
+		return nil, err
+	}
+	return &runtimeport.RunResult{}, nil
+}
+
+func TestVirtualModelTurnDiscardsCheckpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	runner := &checkpointingRunner{}
+	rt := newVirtualModelTestRuntime(t, runner)
+	runner.store = func() storageport.CheckpointStore { return rt.Checkpoints }
+
+	router := gin.New()
+	router.POST("/v1/chat/completions", rt.OpenAIChatCompletionsHandler())
+	resp := performJSON(router, http.MethodPost, "/v1/chat/completions", `{"model":"fkteams/team","messages":[{"role":"user","content":"hi"}]}`)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", resp.Code, resp.Body.String())
+	}
+	entries, err := os.ReadDir(rt.HistoryDir)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("one-off API session left %d entries in %s", len(entries), rt.HistoryDir)
+	}
+}
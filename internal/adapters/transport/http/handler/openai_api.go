@@ -18,8 +18,8 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// OpenAIModelsHandler 返回所有已配置的模型列表（OpenAI 兼容格式）
-func OpenAIModelsHandler() gin.HandlerFunc {
+// OpenAIModelsHandler 返回所有已配置的模型和智能体虚拟模型列表（OpenAI 兼容格式）
+func (rt *Runtime) OpenAIModelsHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		cfg := config.Get()
 		now := time.Now().Unix()
@@ -31,8 +31,23 @@ func OpenAIModelsHandler() gin.HandlerFunc {
 			OwnedBy string `json:"owned_by"`
 		}
 
-		models := make([]modelObject, 0, len(cfg.Models))
+		virtualModels := rt.virtualModels()
+		models := make([]modelObject, 0, len(cfg.Models)+len(virtualModels))
 		for _, m := range cfg.Models {
+			if !requestAllowsModel(c.Request.Context(), m.ID) {
+				continue
+			}
+			models = append(models, modelObject{
+				ID:      m.ID,
+				Object:  "model",
+				Created: now,
+				OwnedBy: "fkteams",
+			})
+		}
+		for _, m := range virtualModels {
+			if !requestAllowsModel(c.Request.Context(), m.ID) {
+				continue
+			}
 			models = append(models, modelObject{
 				ID:      m.ID,
 				Object:  "model",
@@ -48,8 +63,9 @@ func OpenAIModelsHandler() gin.HandlerFunc {
 	}
 }
 
-// OpenAIChatCompletionsHandler 代理 chat/completions 请求到配置的模型后端
-func OpenAIChatCompletionsHandler() gin.HandlerFunc {
+// OpenAIChatCompletionsHandler 处理 chat/completions 请求。
+// 智能体虚拟模型由 chat.Service 执行，其余请求代理到配置的模型后端。
+func (rt *Runtime) OpenAIChatCompletionsHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		bodyBytes, err := io.ReadAll(c.Request.Body)
 		if err != nil {
@@ -69,6 +85,14 @@ func OpenAIChatCompletionsHandler() gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, openAIError("invalid_request_error", "model is required"))
 			return
 		}
+		if !requestAllowsModel(c.Request.Context(), req.Model) {
+			c.JSON(http.StatusForbidden, openAIError("permission_error", fmt.Sprintf("API key is not allowed to use model %q", req.Model)))
+			return
+		}
+		if model, ok := parseVirtualModel(req.Model); ok {
+			rt.handleVirtualChatCompletions(c, model, bodyBytes)
+			return
+		}
 
 		// 查找模型配置
 		cfg := config.Get()
@@ -138,41 +162,49 @@ func OpenAIChatCompletionsHandler() gin.HandlerFunc {
 		defer resp.Body.Close()
 
 		// 只透传安全的响应头，防止上游注入 Set-Cookie / Location 等
-		safeHeaders := map[string]bool{
-			"Content-Type":                   true,
-			"X-Request-Id":                   true,
-			"X-Ratelimit-Limit-Requests":     true,
-			"X-Ratelimit-Limit-Tokens":       true,
-			"X-Ratelimit-Remaining-Requests": true,
-			"X-Ratelimit-Remaining-Tokens":   true,
-			"X-Ratelimit-Reset-Requests":     true,
-			"X-Ratelimit-Reset-Tokens":       true,
-		}
-		for k, vs := range resp.Header {
-			if safeHeaders[k] {
-				for _, v := range vs {
-					c.Writer.Header().Add(k, v)
-				}
+		writeProxyResponse(c, resp, func(key string) bool {
+			return openAISafeHeaders[key]
+		})
+	}
+}
+
+var openAISafeHeaders = map[string]bool{
+	"Content-Type":                   true,
+	"X-Request-Id":                   true,
+	"X-Ratelimit-Limit-Requests":     true,
+	"X-Ratelimit-Limit-Tokens":       true,
+	"X-Ratelimit-Remaining-Requests": true,
+	"X-Ratelimit-Remaining-Tokens":   true,
+	"X-Ratelimit-Reset-Requests":     true,
+	"X-Ratelimit-Reset-Tokens":       true,
+}
+
+// writeProxyResponse 透传上游状态码、允许的响应头和（流式）响应体。
+func writeProxyResponse(c *gin.Context, resp *http.Response, allowHeader func(string) bool) {
+	for k, vs := range resp.Header {
+		if allowHeader(k) {
+			for _, v := range vs {
+				c.Writer.Header().Add(k, v)
 			}
 		}
-		c.Writer.WriteHeader(resp.StatusCode)
-
-		// 流式转发响应体
-		if f, ok := c.Writer.(http.Flusher); ok {
-			buf := make([]byte, 4096)
-			for {
-				n, readErr := resp.Body.Read(buf)
-				if n > 0 {
-					c.Writer.Write(buf[:n])
-					f.Flush()
-				}
-				if readErr != nil {
-					break
-				}
+	}
+	c.Writer.WriteHeader(resp.StatusCode)
+
+	// 流式转发响应体
+	if f, ok := c.Writer.(http.Flusher); ok {
+		buf := make([]byte, 4096)
+		for {
+			n, readErr := resp.Body.Read(buf)
+			if n > 0 {
+				c.Writer.Write(buf[:n])
+				f.Flush()
+			}
+			if readErr != nil {
+				break
 			}
-		} else {
-			io.Copy(c.Writer, resp.Body)
 		}
+	} else {
+		io.Copy(c.Writer, resp.Body)
 	}
 }
 
@@ -8,6 +8,8 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fkteams/internal/app/appdata"
+	"fkteams/internal/app/project"
+	domainaccount "fkteams/internal/domain/account"
 	"fkteams/internal/runtime/atomicfile"
 	"fkteams/internal/runtime/log"
 	"fmt"
@@ -52,6 +54,8 @@ type shareFileEntry struct {
 	FilePaths     []string `json:"file_paths"`
 	ResourcePaths []string `json:"resource_paths"`
 	PasswordHash  string   `json:"password_hash,omitempty"`
+	Project       string   `json:"project,omitempty"`
+	Owner         string   `json:"owner,omitempty"`
 	ExpiresAt     int64    `json:"expires_at"` // Unix 时间戳，0 表示永不过期
 	CreatedAt     int64    `json:"created_at"`
 }
@@ -105,6 +109,8 @@ func (s *PreviewLinkStore) Load() error {
 			expiresAt,
 			time.Unix(e.CreatedAt, 0),
 		)
+		entry.Project = e.Project
+		entry.Owner = e.Owner
 		if err := validatePreviewLinkEntry(id, entry); err != nil {
 			return err
 		}
@@ -163,6 +169,8 @@ func (s *PreviewLinkStore) saveLockedTo(filePath string) error {
 			FilePaths:     e.FilePaths,
 			ResourcePaths: e.ResourcePaths,
 			PasswordHash:  e.PasswordHash,
+			Project:       e.Project,
+			Owner:         e.Owner,
 			ExpiresAt:     expiresAtUnix(e.ExpiresAt),
 			CreatedAt:     e.CreatedAt.Unix(),
 		}
@@ -217,6 +225,29 @@ func (s *PreviewLinkStore) Delete(id string) (bool, error) {
 	return true, nil
 }
 
+// AssignOwner 将没有归属的预览链接归属到 owner，返回修改的链接数。
+func (s *PreviewLinkStore) AssignOwner(owner string) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	var assigned []*previewLinkEntry
+	for _, entry := range s.m {
+		if entry.Owner == "" {
+			entry.Owner = owner
+			assigned = append(assigned, entry)
+		}
+	}
+	if len(assigned) == 0 {
+		return 0, nil
+	}
+	if err := s.saveLockedTo(s.filePath); err != nil {
+		for _, entry := range assigned {
+			entry.Owner = ""
+		}
+		return 0, err
+	}
+	return len(assigned), nil
+}
+
 func (s *PreviewLinkStore) DeleteMany(ids []string) error {
 	s.Lock()
 	defer s.Unlock()
@@ -244,6 +275,8 @@ type previewLinkEntry struct {
 	FilePaths     []string // 用户显式分享的工作区相对路径列表
 	ResourcePaths []string // 创建链接时授权的普通文件清单
 	PasswordHash  string   // bcrypt 哈希 (空字符串表示无密码)
+	Project       string   // 文件所属项目，空字符串表示默认工作区
+	Owner         string   // 创建链接的账号，未启用认证时为空
 	ExpiresAt     time.Time
 	CreatedAt     time.Time
 	resourceSet   map[string]struct{}
@@ -283,6 +316,18 @@ func validatePreviewLinkEntry(id string, entry *previewLinkEntry) error {
 	return nil
 }
 
+// workspaceDir 返回链接所属项目的工作区；项目已被删除时返回 false，避免回退到其他工作区。
+func (e *previewLinkEntry) workspaceDir() (string, bool) {
+	if e.Project == "" {
+		return appdata.WorkspaceDir(), true
+	}
+	p, err := project.Resolve(e.Project)
+	if err != nil {
+		return "", false
+	}
+	return p.Root, true
+}
+
 func (e *previewLinkEntry) indexResources() {
 	if e == nil {
 		return
@@ -414,7 +459,12 @@ func collectPreviewPaths(baseDir string, requested []string) ([]string, []string
 // CreatePreviewLinkHandler 创建当前 HTTP runtime 的文件预览链接。
 func (rt *Runtime) CreatePreviewLinkHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		baseDir := appdata.WorkspaceDir()
+		proj, err := project.Resolve(c.Query("project"))
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		baseDir := proj.Root
 
 		var req struct {
 			FilePath  string   `json:"file_path"`
@@ -436,6 +486,21 @@ func (rt *Runtime) CreatePreviewLinkHandler() gin.HandlerFunc {
 			Fail(c, http.StatusBadRequest, "缺少文件路径")
 			return
 		}
+		area, err := userFileArea(c.Request.Context())
+		if err != nil {
+			Fail(c, http.StatusForbidden, err.Error())
+			return
+		}
+		scopedPaths := make([]string, 0, len(paths))
+		for _, p := range paths {
+			scoped, ok := workspacePathInArea(area, p)
+			if !ok {
+				Fail(c, http.StatusForbidden, "无权访问该文件")
+				return
+			}
+			scopedPaths = append(scopedPaths, scoped)
+		}
+		paths = scopedPaths
 
 		cleanPaths, resourcePaths, err := collectPreviewPaths(baseDir, paths)
 		if err != nil {
@@ -469,6 +534,10 @@ func (rt *Runtime) CreatePreviewLinkHandler() gin.HandlerFunc {
 			expiresAt = now.Add(time.Duration(expiresIn) * time.Second)
 		}
 		entry := newPreviewLinkEntry(cleanPaths, resourcePaths, "", expiresAt, now)
+		if !proj.Builtin {
+			entry.Project = proj.Name
+		}
+		entry.Owner = domainaccount.OwnerFromContext(c.Request.Context())
 
 		if req.Password != "" {
 			h := hashPassword(req.Password)
@@ -506,7 +575,6 @@ func (rt *Runtime) CreatePreviewLinkHandler() gin.HandlerFunc {
 // PreviewFileHandler 通过当前 HTTP runtime 的预览链接访问文件。
 func (rt *Runtime) PreviewFileHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		baseDir := appdata.WorkspaceDir()
 
 		linkID := c.Param("linkId")
 		if linkID == "" {
@@ -531,6 +599,11 @@ func (rt *Runtime) PreviewFileHandler() gin.HandlerFunc {
 			Fail(c, http.StatusGone, "链接已过期")
 			return
 		}
+		baseDir, ok := entry.workspaceDir()
+		if !ok {
+			Fail(c, http.StatusNotFound, "shared file is unavailable")
+			return
+		}
 
 		if !authorizePreviewPassword(c, linkID, entry.PasswordHash, c.GetHeader("X-Preview-Password")) {
 			return
@@ -599,6 +672,13 @@ func (rt *Runtime) DeletePreviewLinkHandler() gin.HandlerFunc {
 		}
 
 		store := rt.PreviewLinks
+		store.RLock()
+		entry := store.m[linkID]
+		store.RUnlock()
+		if entry != nil && !domainaccount.CanAccess(c.Request.Context(), entry.Owner) {
+			Fail(c, http.StatusNotFound, "链接不存在")
+			return
+		}
 		exists, err := store.Delete(linkID)
 		if !exists {
 			Fail(c, http.StatusNotFound, "链接不存在")
@@ -651,7 +731,6 @@ func (rt *Runtime) PreviewAuthHandler() gin.HandlerFunc {
 // PreviewInfoHandler 获取当前 HTTP runtime 的预览链接文件信息。
 func (rt *Runtime) PreviewInfoHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		baseDir := appdata.WorkspaceDir()
 
 		linkID := c.Param("linkId")
 		if linkID == "" {
@@ -676,6 +755,11 @@ func (rt *Runtime) PreviewInfoHandler() gin.HandlerFunc {
 			Fail(c, http.StatusGone, "链接已过期")
 			return
 		}
+		baseDir, ok := entry.workspaceDir()
+		if !ok {
+			Fail(c, http.StatusNotFound, "shared file is unavailable")
+			return
+		}
 		_, primaryInfo, err := resolveWorkspaceEntryNoSymlinks(baseDir, entry.FilePaths[0])
 		if err != nil {
 			Fail(c, http.StatusNotFound, "shared file is unavailable")
@@ -753,6 +837,9 @@ func (rt *Runtime) ListPreviewLinksHandler() gin.HandlerFunc {
 				expired = append(expired, id)
 				continue
 			}
+			if !domainaccount.CanAccess(c.Request.Context(), entry.Owner) {
+				continue
+			}
 			filePath := entry.FilePaths[0]
 			if len(entry.FilePaths) > 1 {
 				filePath = fmt.Sprintf("%d 个文件", len(entry.FilePaths))
@@ -951,7 +1038,6 @@ func isPreviewable(contentType string) bool {
 // PreviewRenderHandler 渲染当前 HTTP runtime 的预览文件。
 func (rt *Runtime) PreviewRenderHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		baseDir := appdata.WorkspaceDir()
 
 		linkID := c.Param("linkId")
 		if linkID == "" {
@@ -976,6 +1062,11 @@ func (rt *Runtime) PreviewRenderHandler() gin.HandlerFunc {
 			Fail(c, http.StatusGone, "链接已过期")
 			return
 		}
+		baseDir, ok := entry.workspaceDir()
+		if !ok {
+			Fail(c, http.StatusNotFound, "shared file is unavailable")
+			return
+		}
 
 		if !authorizePreviewPassword(c, linkID, entry.PasswordHash, c.GetHeader("X-Preview-Password")) {
 			return
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"fkteams/internal/app/project"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListProjectsHandler 列出默认项目和所有已注册项目。
+func ListProjectsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		OK(c, gin.H{"projects": project.List()})
+	}
+}
+
+// CreateProjectHandler 注册新项目。
+func CreateProjectHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req project.Project
+		if err := c.ShouldBindJSON(&req); err != nil {
+			Fail(c, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		p, err := project.Add(req)
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		Created(c, p)
+	}
+}
+
+// DeleteProjectHandler 删除已注册项目，不会删除项目目录。
+func DeleteProjectHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := project.Remove(c.Param("name")); err != nil {
+			FailError(c, err)
+			return
+		}
+		OK(c, gin.H{"message": "project deleted"})
+	}
+}
+
+// UseProjectHandler 切换未指定项目时使用的当前项目。
+func UseProjectHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Name string `json:"name" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			Fail(c, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		p, err := project.Use(req.Name)
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		OK(c, p)
+	}
+}
+
+// resolveSessionProject 解析一次运行所属的项目：请求显式指定的项目优先，
+// 其次是会话已绑定的项目，最后是当前项目。
+func (rt *Runtime) resolveSessionProject(ctx context.Context, sessionID, requested string) (project.Project, error) {
+	name := strings.TrimSpace(requested)
+	if name == "" && rt.SessionService != nil {
+		if meta, err := rt.SessionService.Get(ctx, sessionID); err == nil {
+			name = meta.Project
+		}
+	}
+	return project.Resolve(name)
+}
+
+// applyProjectDefaults 在请求既未指定模式也未指定智能体时使用项目默认值。
+func applyProjectDefaults(p project.Project, mode, agentName string) (string, string) {
+	if mode == "" && agentName == "" {
+		mode, agentName = p.Mode, p.Agent
+	}
+	if mode == "" {
+		mode = "team"
+	}
+	return mode, agentName
+}
+
+// projectWorkspaceDir 返回请求 ?project= 指定项目的工作区，未指定时使用当前项目。
+func projectWorkspaceDir(c *gin.Context) (string, error) {
+	p, err := project.Resolve(c.Query("project"))
+	if err != nil {
+		return "", err
+	}
+	return p.Root, nil
+}
+
+// withProjectScope 将项目绑定到 context。非管理员账号的智能体工具同时被限定在其私有文件目录中，
+// 与文件接口可访问的范围一致。
+func withProjectScope(ctx context.Context, p project.Project) (context.Context, error) {
+	ctx = project.WithProject(ctx, p)
+	area, err := userFileArea(ctx)
+	if err != nil || area == "" {
+		return ctx, err
+	}
+	root := project.RootDir(ctx)
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, fmt.Errorf("创建工作目录失败")
+	}
+	dir, err := userAreaDir(ctx, root)
+	if err != nil {
+		return nil, err
+	}
+	return project.WithUserArea(ctx, dir), nil
+}
+
+// projectName 返回 context 中项目的名称，未绑定项目时为空。
+func projectName(ctx context.Context) string {
+	if p, ok := project.FromContext(ctx); ok {
+		return p.Name
+	}
+	return ""
+}
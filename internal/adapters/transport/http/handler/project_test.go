@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fkteams/internal/app/config"
+	"fkteams/internal/app/project"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestProjectHandlersAndProjectScopedFiles(t *testing.T) {
+	setupWorkspaceDir(t)
+	if err := config.Save(&config.Config{}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	t.Cleanup(func() { _ = config.Save(&config.Config{}) })
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main"), 0644); err != nil {
+		t.Fatalf("write project file: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/projects", ListProjectsHandler())
+	router.POST("/projects", CreateProjectHandler())
+	router.DELETE("/projects/:name", DeleteProjectHandler())
+	router.GET("/files", GetFilesHandler())
+
+	payload, _ := json.Marshal(map[string]string{"name": "api", "root": root, "mode": "deep"})
+	body := string(payload)
+	if resp := performRequest(router, http.MethodPost, "/projects", strings.NewReader(body)); resp.Code != http.StatusCreated {
+		t.Fatalf("create project status = %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := performRequest(router, http.MethodPost, "/projects", strings.NewReader(body)); resp.Code != http.StatusConflict {
+		t.Fatalf("duplicate project status = %d: %s", resp.Code, resp.Body.String())
+	}
+
+	var listed struct {
+		Projects []project.Project `json:"projects"`
+	}
+	decodeRawData(t, performRequest(router, http.MethodGet, "/projects", nil), &listed)
+	if len(listed.Projects) != 2 || !listed.Projects[0].Builtin || listed.Projects[1].Name != "api" {
+		t.Fatalf("projects = %#v", listed.Projects)
+	}
+
+	resp := performRequest(router, http.MethodGet, "/files?project=api", nil)
+	var files []FileInfo
+	decodeRawData(t, resp, &files)
+	if len(files) != 1 || files[0].Name != "main.go" {
+		t.Fatalf("project files = %#v", files)
+	}
+	if resp := performRequest(router, http.MethodGet, "/files?project=missing", nil); resp.Code == http.StatusOK {
+		t.Fatalf("unknown project files status = %d", resp.Code)
+	}
+
+	if resp := performRequest(router, http.MethodDelete, "/projects/api", nil); resp.Code != http.StatusOK {
+		t.Fatalf("delete project status = %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestApplyProjectDefaults(t *testing.T) {
+	p := project.Project{Name: "api", Mode: "deep", Agent: "coder"}
+	if mode, agent := applyProjectDefaults(p, "", ""); mode != "deep" || agent != "coder" {
+		t.Fatalf("defaults = %q/%q", mode, agent)
+	}
+	if mode, agent := applyProjectDefaults(p, "", "writer"); mode != "team" || agent != "writer" {
+		t.Fatalf("explicit agent = %q/%q", mode, agent)
+	}
+	if mode, agent := applyProjectDefaults(project.Project{}, "", ""); mode != "team" || agent != "" {
+		t.Fatalf("empty project = %q/%q", mode, agent)
+	}
+}
@@ -9,19 +9,27 @@ import (
 	"time"
 
 	modelproviders "fkteams/internal/adapters/model/providers"
+	filecheckpoint "fkteams/internal/adapters/storage/file/checkpoint"
 	eventlog "fkteams/internal/adapters/storage/file/history"
 	appagent "fkteams/internal/app/agent"
 	agents "fkteams/internal/app/agent/catalog"
 	"fkteams/internal/app/agent/catalog/toolmeta"
 	"fkteams/internal/app/appdata"
 	"fkteams/internal/app/appstate"
+	appaudit "fkteams/internal/app/audit"
 	appchat "fkteams/internal/app/chat"
 	"fkteams/internal/app/chat/taskstream"
 	appschedule "fkteams/internal/app/schedule"
 	appsession "fkteams/internal/app/session"
 	appskill "fkteams/internal/app/skill"
 	apptools "fkteams/internal/app/tools"
+	appusage "fkteams/internal/app/usage"
+	"fkteams/internal/app/userhooks"
+	domainmemory "fkteams/internal/domain/memory"
 	runtimeport "fkteams/internal/ports/runtime"
+	storageport "fkteams/internal/ports/storage"
+	"fkteams/internal/runtime/checkpoint"
+	"fkteams/internal/runtime/hooks"
 	modelregistry "fkteams/internal/runtime/model"
 )
 
@@ -46,6 +54,12 @@ type Runtime struct {
 	Providers      *modelproviders.Registry
 	Runtime        runtimeport.Runtime
 	Interrupt      runtimeport.InterruptRuntime
+	Checkpoints    storageport.CheckpointStore
+	Interrupts     taskstream.InterruptStore
+	HookBus        *hooks.Bus
+	Usage          *appusage.Ledger
+	Audit          *appaudit.Log
+	UserHooks      *userhooks.Manager
 	ResetChannels  func()
 
 	sessionOperationsMu sync.Mutex
@@ -79,6 +93,12 @@ type RuntimeOptions struct {
 	Providers      *modelproviders.Registry
 	Runtime        runtimeport.Runtime
 	Interrupt      runtimeport.InterruptRuntime
+	Checkpoints    storageport.CheckpointStore
+	Interrupts     taskstream.InterruptStore
+	HookBus        *hooks.Bus
+	Usage          *appusage.Ledger
+	Audit          *appaudit.Log
+	UserHooks      *userhooks.Manager
 	ResetChannels  func()
 }
 
@@ -112,6 +132,12 @@ func NewRuntime(options ...RuntimeOptions) *Runtime {
 		Providers:      opt.Providers,
 		Runtime:        opt.Runtime,
 		Interrupt:      opt.Interrupt,
+		Checkpoints:    opt.Checkpoints,
+		Interrupts:     opt.Interrupts,
+		HookBus:        opt.HookBus,
+		Usage:          opt.Usage,
+		Audit:          opt.Audit,
+		UserHooks:      opt.UserHooks,
 		ResetChannels:  opt.ResetChannels,
 		shutdownDone:   make(chan struct{}),
 	}
@@ -121,6 +147,12 @@ func NewRuntime(options ...RuntimeOptions) *Runtime {
 	if rt.HistoryDir == "" {
 		rt.HistoryDir = appdata.SessionsDir()
 	}
+	if rt.Checkpoints == nil {
+		rt.Checkpoints = filecheckpoint.NewStore(rt.HistoryDir)
+	}
+	if rt.Interrupts == nil {
+		rt.Interrupts = eventlog.NewInterruptStore(rt.HistoryDir)
+	}
 	if rt.RunnerCache == nil {
 		rt.RunnerCache = appagent.NewCache()
 	}
@@ -159,6 +191,7 @@ func (rt *Runtime) Start(ctx context.Context) error {
 	if err := rt.InitializationError(); err != nil {
 		return err
 	}
+	rt.migrateLegacyOwnership(ctx)
 	rt.Streams.StartCleanup(ctx, time.Minute)
 	return nil
 }
@@ -241,6 +274,13 @@ func (rt *Runtime) Shutdown(ctx context.Context) error {
 	}
 }
 
+// isClosing 报告 runtime 是否已开始关闭；关闭期间被取消的中断需保留以便重启后恢复。
+func (rt *Runtime) isClosing() bool {
+	rt.tasksMu.Lock()
+	defer rt.tasksMu.Unlock()
+	return rt.closing
+}
+
 // Close 无超时关闭 runtime，供尚未开始接收请求的启动失败路径使用。
 func (rt *Runtime) Close() {
 	_ = rt.Shutdown(context.Background())
@@ -315,11 +355,15 @@ func (rt *Runtime) resolveRunner(ctx context.Context, mode, agentName string) (r
 func (rt *Runtime) withExecutionDependencies(ctx context.Context) context.Context {
 	ctx = runtimeport.WithRuntime(ctx, rt.Runtime)
 	ctx = runtimeport.WithInterruptRuntime(ctx, rt.Interrupt)
+	ctx = checkpoint.WithStore(ctx, rt.Checkpoints)
 	ctx = modelregistry.WithRegistry(ctx, rt.ModelRegistry)
 	ctx = modelproviders.WithRegistry(ctx, rt.Providers)
 	ctx = apptools.WithRegistry(ctx, rt.ToolRegistry)
 	ctx = toolmeta.WithRegistry(ctx, rt.ToolDisplays)
 	ctx = agents.WithRegistry(ctx, rt.AgentRegistry)
+	ctx = hooks.WithBus(ctx, rt.HookBus)
+	ctx = appusage.WithLedger(ctx, rt.Usage)
+	ctx = userhooks.WithManager(ctx, rt.UserHooks)
 	return appschedule.WithService(ctx, rt.Scheduler)
 }
 
@@ -333,15 +377,16 @@ func (rt *Runtime) saveTurnHistory(recorder *eventlog.HistoryRecorder, sessionID
 	appchat.LogLifecycleError("http", sessionID, err)
 }
 
-func (rt *Runtime) updateSessionExecutionMetadata(sessionID, userInput, mode, currentAgent string) {
+func (rt *Runtime) updateSessionExecutionMetadata(ctx context.Context, sessionID, userInput, mode, currentAgent string) {
 	err := rt.chatLifecycle().MarkProcessingWithTarget(context.Background(), sessionID, userInput, appchat.ExecutionTarget{
 		Mode:         mode,
 		CurrentAgent: currentAgent,
+		Project:      projectName(ctx),
 	})
 	appchat.LogLifecycleError("http", sessionID, err)
 }
 
-func (rt *Runtime) finishChat(recorder *eventlog.HistoryRecorder, sessionID, userInput string, manager appstate.MemoryManager) {
+func (rt *Runtime) finishChat(recorder *eventlog.HistoryRecorder, sessionID, userInput string, manager appstate.MemoryManager, memoryScope domainmemory.ScopeContext) {
 	err := rt.chatLifecycle().Finish(context.Background(), appchat.FinishRequest{
 		SessionID:       sessionID,
 		TitleSource:     userInput,
@@ -350,6 +395,7 @@ func (rt *Runtime) finishChat(recorder *eventlog.HistoryRecorder, sessionID, use
 		FinalizeHistory: true,
 		Memory:          manager,
 		MemoryMessages:  eventlog.ConvertMemoryMessages(recorder),
+		MemoryScope:     memoryScope,
 	})
 	appchat.LogLifecycleError("http", sessionID, err)
 }
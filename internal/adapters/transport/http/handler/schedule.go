@@ -1,25 +1,40 @@
 package handler
 
 import (
+	"errors"
+	appschedule "fkteams/internal/app/schedule"
+	"fkteams/internal/domain/apperror"
 	domainschedule "fkteams/internal/domain/schedule"
 	schedulerport "fkteams/internal/ports/scheduler"
 	"fkteams/internal/runtime/log"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type scheduleTaskRequest struct {
-	Task      string `json:"task"`
-	CronExpr  string `json:"cron_expr"`
-	ExecuteAt string `json:"execute_at"`
+	Task       string                    `json:"task"`
+	CronExpr   string                    `json:"cron_expr"`
+	ExecuteAt  string                    `json:"execute_at"`
+	Target     domainschedule.Target     `json:"target"`
+	Deliveries []domainschedule.Delivery `json:"deliveries"`
+	TimeZone   string                    `json:"time_zone"`
+	Policy     domainschedule.Policy     `json:"policy"`
+	Trigger    *domainschedule.Trigger   `json:"trigger"`
 }
 
 func (r scheduleTaskRequest) toAddTaskRequest() schedulerport.AddTaskRequest {
 	return schedulerport.AddTaskRequest{
-		Task:      r.Task,
-		CronExpr:  r.CronExpr,
-		ExecuteAt: r.ExecuteAt,
+		Task:       r.Task,
+		CronExpr:   r.CronExpr,
+		ExecuteAt:  r.ExecuteAt,
+		Target:     r.Target,
+		Deliveries: r.Deliveries,
+		TimeZone:   r.TimeZone,
+		Policy:     r.Policy,
+		Trigger:    r.Trigger,
 	}
 }
 
@@ -40,7 +55,7 @@ func (rt *Runtime) CreateScheduleTaskHandler() gin.HandlerFunc {
 			return
 		}
 
-		task, err := service.AddTask(c, req.toAddTaskRequest())
+		task, err := service.AddTask(c.Request.Context(), req.toAddTaskRequest())
 		if err != nil {
 			FailError(c, err)
 			return
@@ -69,7 +84,7 @@ func (rt *Runtime) UpdateScheduleTaskHandler() gin.HandlerFunc {
 			return
 		}
 
-		task, err := service.UpdateTask(c, taskID, req.toAddTaskRequest())
+		task, err := service.UpdateTask(c.Request.Context(), taskID, req.toAddTaskRequest())
 		if err != nil {
 			FailError(c, err)
 			return
@@ -93,7 +108,7 @@ func (rt *Runtime) DeleteScheduleTaskHandler() gin.HandlerFunc {
 			return
 		}
 
-		if err := service.DeleteTask(c, taskID); err != nil {
+		if err := service.DeleteTask(c.Request.Context(), taskID); err != nil {
 			FailError(c, err)
 			return
 		}
@@ -110,7 +125,7 @@ func (rt *Runtime) GetScheduleTasksHandler() gin.HandlerFunc {
 		}
 
 		statusFilter := c.Query("status")
-		tasks, err := service.ListTasks(c, domainschedule.Status(statusFilter))
+		tasks, err := service.ListTasks(c.Request.Context(), domainschedule.Status(statusFilter))
 		if err != nil {
 			log.Printf("failed to get schedule tasks: %v", err)
 			FailError(c, err)
@@ -140,7 +155,7 @@ func (rt *Runtime) CancelScheduleTaskHandler() gin.HandlerFunc {
 			return
 		}
 
-		if err := service.CancelTask(c, taskID); err != nil {
+		if err := service.CancelTask(c.Request.Context(), taskID); err != nil {
 			log.Printf("failed to cancel schedule task: id=%s, err=%v", taskID, err)
 			FailError(c, err)
 			return
@@ -166,7 +181,7 @@ func (rt *Runtime) GetTaskResultHandler() gin.HandlerFunc {
 			return
 		}
 
-		result, err := service.ReadTaskResult(c, taskID)
+		result, err := service.ReadTaskResult(c.Request.Context(), taskID)
 		if err != nil {
 			FailError(c, err)
 			return
@@ -192,7 +207,7 @@ func (rt *Runtime) GetTaskHistoryHandler() gin.HandlerFunc {
 			return
 		}
 
-		entries, err := service.ListHistoryEntries(c, taskID)
+		entries, err := service.ListHistoryEntries(c.Request.Context(), taskID)
 		if err != nil {
 			FailError(c, err)
 			return
@@ -222,7 +237,7 @@ func (rt *Runtime) GetTaskHistoryFileHandler() gin.HandlerFunc {
 			return
 		}
 
-		content, err := service.ReadHistoryFile(c, taskID, filename)
+		content, err := service.ReadHistoryFile(c.Request.Context(), taskID, filename)
 		if err != nil {
 			FailError(c, err)
 			return
@@ -231,3 +246,47 @@ func (rt *Runtime) GetTaskHistoryFileHandler() gin.HandlerFunc {
 		OK(c, gin.H{"task_id": taskID, "filename": filename, "content": content})
 	}
 }
+
+// FireTriggerHandler 处理 webhook 触发器的调用，请求体作为触发内容注入任务提示词。
+// 该接口不走登录认证，调用方需在 X-Fkteams-Trigger-Secret 中携带密钥，
+// 或在 X-Hub-Signature-256 中携带请求体的 HMAC-SHA256 签名。
+func (rt *Runtime) FireTriggerHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		taskID := c.Param("id")
+		service := rt.Scheduler
+		if service == nil {
+			Fail(c, http.StatusServiceUnavailable, "scheduler not initialized")
+			return
+		}
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				Fail(c, http.StatusRequestEntityTooLarge, "request body is too large")
+				return
+			}
+			Fail(c, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+		attemptKey := "trigger:" + taskID + ":" + c.ClientIP()
+		if allowed, retryAfter := triggerAttempts.Allow(attemptKey, time.Now()); !allowed {
+			rateLimitExceeded(c, retryAfter)
+			return
+		}
+
+		err = service.FireWebhook(c, taskID, appschedule.WebhookCall{
+			Secret:    c.GetHeader("X-Fkteams-Trigger-Secret"),
+			Signature: c.GetHeader("X-Hub-Signature-256"),
+			Body:      body,
+			Source:    "webhook from " + c.ClientIP(),
+		})
+		if apperror.CodeOf(err) != apperror.CodeUnauthorized {
+			triggerAttempts.Reset(attemptKey)
+		}
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		OK(c, gin.H{"task_id": taskID, "message": "task triggered"})
+	}
+}
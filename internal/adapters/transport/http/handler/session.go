@@ -1,13 +1,17 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"fkteams/internal/adapters/storage/file/history"
+	"fkteams/internal/app/project"
 	appsession "fkteams/internal/app/session"
+	domainaccount "fkteams/internal/domain/account"
 	domainsession "fkteams/internal/domain/session"
 	"fkteams/internal/runtime/log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -20,6 +24,8 @@ type SessionInfo struct {
 	Status       string    `json:"status"`
 	Mode         string    `json:"mode,omitempty"`
 	CurrentAgent string    `json:"current_agent,omitempty"`
+	Project      string    `json:"project,omitempty"`
+	Owner        string    `json:"owner,omitempty"`
 	Favorite     bool      `json:"favorite,omitempty"`
 	ActiveTask   bool      `json:"active_task"` // 是否有内存中的活跃流式任务可订阅
 	Size         int64     `json:"size"`
@@ -31,7 +37,37 @@ func validateSessionID(sessionID string) bool {
 	return domainsession.ValidID(sessionID)
 }
 
-// ListSessionsHandler 列出所有历史会话
+// authorizeSession 校验请求账号可以访问会话，无权访问时按会话不存在返回 404。
+func (rt *Runtime) authorizeSession(c *gin.Context, sessionID string) bool {
+	if err := rt.SessionService.Authorize(c.Request.Context(), sessionID); err != nil {
+		FailError(c, err)
+		return false
+	}
+	return true
+}
+
+// SessionAccess 为带 :sessionID 路径参数的路由校验会话归属。
+func (rt *Runtime) SessionAccess() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID := c.Param("sessionID")
+		if validateSessionID(sessionID) && !rt.authorizeSession(c, sessionID) {
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// detachedContext 返回独立于请求生命周期的 context，只保留请求身份，供后台任务使用。
+func detachedContext(c *gin.Context) context.Context {
+	ctx := context.Background()
+	if principal, ok := RequestPrincipal(c); ok {
+		ctx = domainaccount.WithPrincipal(ctx, principal)
+	}
+	return ctx
+}
+
+// ListSessionsHandler 列出所有历史会话，?project= 只返回绑定到该项目的会话
 
 func (rt *Runtime) ListSessionsHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -41,9 +77,13 @@ func (rt *Runtime) ListSessionsHandler() gin.HandlerFunc {
 			FailError(c, err)
 			return
 		}
+		projectFilter := strings.TrimSpace(c.Query("project"))
 		files := make([]SessionInfo, 0, len(records))
 		for _, record := range records {
 			meta := record.Metadata
+			if projectFilter != "" && sessionProject(meta.Project) != projectFilter {
+				continue
+			}
 			status := string(meta.Status)
 			activeTask := rt.sessionHasProcessingStream(meta.ID)
 			if activeTask {
@@ -55,6 +95,8 @@ func (rt *Runtime) ListSessionsHandler() gin.HandlerFunc {
 				Status:       status,
 				Mode:         meta.Mode,
 				CurrentAgent: meta.CurrentAgent,
+				Project:      meta.Project,
+				Owner:        meta.Owner,
 				Favorite:     meta.Favorite,
 				ActiveTask:   activeTask,
 				Size:         record.Size,
@@ -66,6 +108,14 @@ func (rt *Runtime) ListSessionsHandler() gin.HandlerFunc {
 	}
 }
 
+// sessionProject 返回会话所属项目名，未绑定项目的会话归入默认项目。
+func sessionProject(name string) string {
+	if name == "" {
+		return project.DefaultName
+	}
+	return name
+}
+
 func (rt *Runtime) sessionHasProcessingStream(sessionID string) bool {
 	stream := rt.Streams.Get(sessionID)
 	return stream != nil && stream.Status() == "processing"
@@ -78,6 +128,7 @@ func (rt *Runtime) CreateSessionHandler() gin.HandlerFunc {
 		var req struct {
 			SessionID string `json:"session_id"`
 			Title     string `json:"title"`
+			Project   string `json:"project"`
 		}
 		if err := c.ShouldBindJSON(&req); err != nil {
 			Fail(c, http.StatusBadRequest, "invalid request body")
@@ -87,6 +138,7 @@ func (rt *Runtime) CreateSessionHandler() gin.HandlerFunc {
 		metadata, created, err := rt.SessionService.Create(c.Request.Context(), appsession.CreateRequest{
 			SessionID: req.SessionID,
 			Title:     req.Title,
+			Project:   req.Project,
 		})
 		if err != nil {
 			FailError(c, err)
@@ -96,11 +148,12 @@ func (rt *Runtime) CreateSessionHandler() gin.HandlerFunc {
 			OK(c, gin.H{
 				"session_id":    metadata.ID,
 				"current_agent": metadata.CurrentAgent,
+				"project":       metadata.Project,
 				"message":       "session already exists",
 			})
 			return
 		}
-		Created(c, gin.H{"session_id": metadata.ID, "message": "session created"})
+		Created(c, gin.H{"session_id": metadata.ID, "project": metadata.Project, "message": "session created"})
 	}
 }
 
@@ -137,10 +190,14 @@ func (rt *Runtime) GetSessionHandler() gin.HandlerFunc {
 
 		currentAgent := ""
 		mode := ""
+		boundProject := ""
+		forkedFrom := ""
 		favorite := false
 		if metaErr == nil {
 			mode = meta.Mode
 			currentAgent = meta.CurrentAgent
+			boundProject = meta.Project
+			forkedFrom = meta.ForkedFrom
 			favorite = meta.Favorite
 		}
 
@@ -148,6 +205,8 @@ func (rt *Runtime) GetSessionHandler() gin.HandlerFunc {
 			"session_id":    sessionID,
 			"mode":          mode,
 			"current_agent": currentAgent,
+			"project":       boundProject,
+			"forked_from":   forkedFrom,
 			"favorite":      favorite,
 			"events":        rt.transcriptRecordsToChatEvents(sessionID, transcript),
 			"queue":         queue,
@@ -293,6 +352,7 @@ func (rt *Runtime) UpdateSessionHandler() gin.HandlerFunc {
 			Favorite     *bool   `json:"favorite"`
 			Mode         *string `json:"mode"`
 			CurrentAgent *string `json:"current_agent"`
+			Project      *string `json:"project"`
 		}
 		if err := c.ShouldBindJSON(&req); err != nil {
 			Fail(c, http.StatusBadRequest, "invalid request body")
@@ -304,6 +364,7 @@ func (rt *Runtime) UpdateSessionHandler() gin.HandlerFunc {
 			Favorite:     req.Favorite,
 			Mode:         req.Mode,
 			CurrentAgent: req.CurrentAgent,
+			Project:      req.Project,
 		})
 		if err != nil {
 			FailError(c, err)
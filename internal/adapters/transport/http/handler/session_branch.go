@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	appsession "fkteams/internal/app/session"
+	"fkteams/internal/domain/apperror"
+	domainsession "fkteams/internal/domain/session"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ForkSessionHandler 从指定记录事件分叉出新会话
+func (rt *Runtime) ForkSessionHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID := c.Param("sessionID")
+		if !validateSessionID(sessionID) {
+			Fail(c, http.StatusBadRequest, "invalid session ID")
+			return
+		}
+		var req struct {
+			EventID   string `json:"event_id" binding:"required"`
+			SessionID string `json:"session_id"`
+			Title     string `json:"title"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			Fail(c, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if req.SessionID != "" && !validateSessionID(req.SessionID) {
+			Fail(c, http.StatusBadRequest, "invalid session ID")
+			return
+		}
+		metadata, err := rt.SessionService.Fork(c.Request.Context(), appsession.ForkRequest{
+			SessionID:    sessionID,
+			EventID:      req.EventID,
+			NewSessionID: req.SessionID,
+			Title:        req.Title,
+		})
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		Created(c, metadata)
+	}
+}
+
+// RewindSessionHandler 将会话回退到指定用户消息之前，返回被撤回的消息供编辑后重新发送
+func (rt *Runtime) RewindSessionHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID := c.Param("sessionID")
+		if !validateSessionID(sessionID) {
+			Fail(c, http.StatusBadRequest, "invalid session ID")
+			return
+		}
+		var req struct {
+			EventID string `json:"event_id" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			Fail(c, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		unlockSession := rt.lockSessionOperation(sessionID)
+		defer unlockSession()
+
+		result, err := rt.rewindSessionLocked(c.Request.Context(), sessionID, req.EventID)
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		OK(c, result)
+	}
+}
+
+// rewindSessionLocked 在持有会话操作锁时回退会话，并丢弃依赖旧记录的缓存、中断和已结束的任务流。
+func (rt *Runtime) rewindSessionLocked(ctx context.Context, sessionID, eventID string) (domainsession.RewindResult, error) {
+	if stream := rt.Streams.Get(sessionID); stream != nil && stream.Status() == "processing" {
+		return domainsession.RewindResult{}, apperror.New(apperror.CodeConflict, "session is active")
+	}
+	if !rt.Sessions.Remove(sessionID) {
+		return domainsession.RewindResult{}, apperror.New(apperror.CodeConflict, "session is active")
+	}
+	result, err := rt.SessionService.Rewind(ctx, appsession.RewindRequest{SessionID: sessionID, EventID: eventID})
+	if err != nil {
+		return domainsession.RewindResult{}, err
+	}
+	rt.deletePendingInterrupt(sessionID)
+	rt.Streams.CancelAndRemove(sessionID)
+	return result, nil
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	eventlog "fkteams/internal/adapters/storage/file/history"
+	"fkteams/internal/domain/message"
+	domainsession "fkteams/internal/domain/session"
+	runtimeevents "fkteams/internal/runtime/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestForkAndRewindSessionHandlers(t *testing.T) {
+	rt := newTestRuntime(t)
+	gin.SetMode(gin.TestMode)
+
+	sessionID := "branch-session"
+	sessionDir := rt.sessionDirPath(sessionID)
+	if err := eventlog.SaveMetadata(sessionDir, &eventlog.SessionMetadata{
+		ID:        sessionID,
+		Title:     "原会话",
+		Status:    "idle",
+		Mode:      "deep",
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("save metadata: %v", err)
+	}
+	recorder := eventlog.NewHistoryRecorder()
+	recorder.SetSessionDir(sessionDir)
+	recorder.RecordEvent(runtimeevents.UserMessage("run-1", runtimeevents.TurnID("run-1", 1), "run-1:user", message.Message{Role: message.RoleUser, Content: "第一问"}))
+	recorder.RecordEvent(eventlog.Event{Type: runtimeevents.EventAssistantCompleted, AgentName: "coordinator", Content: "第一答"})
+	recorder.RecordEvent(runtimeevents.UserMessage("run-2", runtimeevents.TurnID("run-2", 1), "run-2:user", message.Message{Role: message.RoleUser, Content: "第二问"}))
+	recorder.RecordEvent(eventlog.Event{Type: runtimeevents.EventAssistantCompleted, AgentName: "coordinator", Content: "第二答"})
+	if err := recorder.SaveToFile(filepath.Join(sessionDir, eventlog.TranscriptFileName)); err != nil {
+		t.Fatalf("save history: %v", err)
+	}
+	records, err := eventlog.LoadSessionTranscriptRecords(sessionDir)
+	if err != nil || len(records) != 4 {
+		t.Fatalf("load transcript: %d records, %v", len(records), err)
+	}
+
+	router := gin.New()
+	router.POST("/sessions/:sessionID/fork", rt.ForkSessionHandler())
+	router.POST("/sessions/:sessionID/rewind", rt.RewindSessionHandler())
+
+	resp := performJSON(router, http.MethodPost, "/sessions/"+sessionID+"/fork", `{"event_id":"`+records[1].Event.ID+`","session_id":"forked"}`)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("fork status = %d: %s", resp.Code, resp.Body.String())
+	}
+	var forked domainsession.Metadata
+	decodeRawData(t, resp, &forked)
+	if forked.ID != "forked" || forked.ForkedFrom != sessionID || forked.Mode != "deep" {
+		t.Fatalf("forked metadata = %#v", forked)
+	}
+	forkedRecords, err := eventlog.LoadSessionTranscriptRecords(rt.sessionDirPath("forked"))
+	if err != nil || len(forkedRecords) != 2 {
+		t.Fatalf("forked transcript: %d records, %v", len(forkedRecords), err)
+	}
+
+	resp = performJSON(router, http.MethodPost, "/sessions/"+sessionID+"/rewind", `{"event_id":"`+records[1].Event.ID+`"}`)
+	if resp.Code != http.StatusBadRequest {
+		t.Fatalf("rewind to assistant message status = %d: %s", resp.Code, resp.Body.String())
+	}
+	resp = performJSON(router, http.MethodPost, "/sessions/"+sessionID+"/rewind", `{"event_id":"`+records[2].Event.ID+`"}`)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("rewind status = %d: %s", resp.Code, resp.Body.String())
+	}
+	var rewound domainsession.RewindResult
+	decodeRawData(t, resp, &rewound)
+	if rewound.Message != "第二问" || rewound.Removed != 2 {
+		t.Fatalf("rewind result = %#v", rewound)
+	}
+	remaining, err := eventlog.LoadSessionTranscriptRecords(sessionDir)
+	if err != nil || len(remaining) != 2 {
+		t.Fatalf("rewound transcript: %d records, %v", len(remaining), err)
+	}
+}
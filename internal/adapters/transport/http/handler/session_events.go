@@ -30,6 +30,9 @@ func (rt *Runtime) transcriptRecordsToChatEvents(sessionID string, transcript []
 			payload := rt.convertEventToMap(event)
 			payload["session_id"] = sessionID
 			payload["transcript_index"] = index
+			if item.ID != "" {
+				payload["transcript_event_id"] = item.ID
+			}
 			if item.ResultRef != "" {
 				payload["result_ref"] = item.ResultRef
 			}
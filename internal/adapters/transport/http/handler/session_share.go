@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fkteams/internal/adapters/storage/file/history"
 	"fkteams/internal/app/appdata"
+	domainaccount "fkteams/internal/domain/account"
 	"fkteams/internal/runtime/atomicfile"
 	"fkteams/internal/runtime/log"
 	"fmt"
@@ -42,6 +43,7 @@ type sessionShareEntry struct {
 	ExpiresAt        time.Time `json:"expires_at,omitempty"`
 	CreatedAt        time.Time `json:"created_at"`
 	LastAccessedAt   time.Time `json:"last_accessed_at,omitempty"`
+	Owner            string    `json:"owner,omitempty"`
 }
 
 type sessionShareFileEntry struct {
@@ -53,6 +55,7 @@ type sessionShareFileEntry struct {
 	ExpiresAt        int64  `json:"expires_at"`
 	CreatedAt        int64  `json:"created_at"`
 	LastAccessedAt   int64  `json:"last_accessed_at,omitempty"`
+	Owner            string `json:"owner,omitempty"`
 }
 
 // SessionShareStore 保存单个 HTTP runtime 的会话分享状态。
@@ -118,6 +121,7 @@ func (s *SessionShareStore) Load() error {
 			ExpiresAt:        expiresAt,
 			CreatedAt:        time.Unix(e.CreatedAt, 0),
 			LastAccessedAt:   lastAccessedAt,
+			Owner:            e.Owner,
 		}
 		if err := validateSessionShareEntry(id, entry); err != nil {
 			return err
@@ -182,6 +186,7 @@ func (s *SessionShareStore) saveLockedTo(filePath string) error {
 			ExpiresAt:        expiresAtUnix(e.ExpiresAt),
 			CreatedAt:        e.CreatedAt.Unix(),
 			LastAccessedAt:   expiresAtUnix(e.LastAccessedAt),
+			Owner:            e.Owner,
 		}
 	}
 	data, err := json.MarshalIndent(entries, "", "  ")
@@ -237,6 +242,29 @@ func (s *SessionShareStore) Delete(id string) (bool, error) {
 	return true, nil
 }
 
+// AssignOwner 将没有归属的会话分享归属到 owner，返回修改的分享数。
+func (s *SessionShareStore) AssignOwner(owner string) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	var assigned []*sessionShareEntry
+	for _, entry := range s.m {
+		if entry.Owner == "" {
+			entry.Owner = owner
+			assigned = append(assigned, entry)
+		}
+	}
+	if len(assigned) == 0 {
+		return 0, nil
+	}
+	if err := s.saveLockedTo(s.filePath); err != nil {
+		for _, entry := range assigned {
+			entry.Owner = ""
+		}
+		return 0, err
+	}
+	return len(assigned), nil
+}
+
 func (s *SessionShareStore) DeleteMany(ids []string) error {
 	s.Lock()
 	defer s.Unlock()
@@ -390,6 +418,9 @@ func (rt *Runtime) CreateSessionShareHandler() gin.HandlerFunc {
 			Fail(c, http.StatusBadRequest, "invalid session ID")
 			return
 		}
+		if !rt.authorizeSession(c, req.SessionID) {
+			return
+		}
 
 		sessionDir := rt.sessionDirPath(req.SessionID)
 		meta, metaErr := eventlog.LoadMetadata(sessionDir)
@@ -439,6 +470,7 @@ func (rt *Runtime) CreateSessionShareHandler() gin.HandlerFunc {
 			MessageCount:     len(messages),
 			ExpiresAt:        expiresAt,
 			CreatedAt:        now,
+			Owner:            domainaccount.OwnerFromContext(c.Request.Context()),
 		}
 		if req.Password != "" {
 			entry.PasswordHash = hashPassword(req.Password)
@@ -474,6 +506,9 @@ func (rt *Runtime) ListSessionSharesHandler() gin.HandlerFunc {
 				expired = append(expired, id)
 				continue
 			}
+			if !domainaccount.CanAccess(c.Request.Context(), entry.Owner) {
+				continue
+			}
 			shares = append(shares, sessionShareResponse(id, entry))
 		}
 		store.RUnlock()
@@ -502,6 +537,13 @@ func (rt *Runtime) DeleteSessionShareHandler() gin.HandlerFunc {
 		}
 
 		store := rt.SessionShares
+		store.RLock()
+		entry := store.m[shareID]
+		store.RUnlock()
+		if entry != nil && !domainaccount.CanAccess(c.Request.Context(), entry.Owner) {
+			Fail(c, http.StatusNotFound, "share not found")
+			return
+		}
 		exists, err := store.Delete(shareID)
 		if !exists {
 			Fail(c, http.StatusNotFound, "share not found")
@@ -16,6 +16,7 @@ import (
 	appchat "fkteams/internal/app/chat"
 	"fkteams/internal/app/chat/taskstream"
 	"fkteams/internal/app/tools/ask"
+	appusage "fkteams/internal/app/usage"
 	domainmessage "fkteams/internal/domain/message"
 	runtimeport "fkteams/internal/ports/runtime"
 	"fkteams/internal/runtime/events"
@@ -30,6 +31,8 @@ type StreamStartRequest struct {
 	Message   string        `json:"message"`
 	Mode      string        `json:"mode"`
 	AgentName string        `json:"agent_name"`
+	Project   string        `json:"project,omitempty"`
+	RewindTo  string        `json:"rewind_to,omitempty"` // 先回退到该用户消息之前再发送，用于编辑后重新发送
 	Contents  []ContentPart `json:"contents"`
 }
 
@@ -50,6 +53,7 @@ func (rt *Runtime) StreamStartHandlerWithState(state *appstate.State) gin.Handle
 			Fail(c, http.StatusBadRequest, "message or contents is required")
 			return
 		}
+		req.Contents = scopeFileContents(c.Request.Context(), req.Contents)
 
 		sessionID := req.SessionID
 		if sessionID == "" {
@@ -59,9 +63,18 @@ func (rt *Runtime) StreamStartHandlerWithState(state *appstate.State) gin.Handle
 			Fail(c, http.StatusBadRequest, "invalid session ID")
 			return
 		}
+		if !rt.authorizeSession(c, sessionID) {
+			return
+		}
 		unlockSession := rt.lockSessionOperation(sessionID)
 		defer unlockSession()
 
+		if req.RewindTo != "" {
+			if _, err := rt.rewindSessionLocked(c.Request.Context(), sessionID, req.RewindTo); err != nil {
+				FailError(c, err)
+				return
+			}
+		}
 		if existing := rt.Streams.Get(sessionID); existing != nil && existing.Status() == "processing" {
 			if queued, queueErr := rt.enqueueTaskMessage(existing, sessionID, taskstream.QueueFollowUp, req.Message, req.Contents); queueErr == nil {
 				writeStreamQueuedResponse(c, sessionID, existing, queued)
@@ -72,17 +85,27 @@ func (rt *Runtime) StreamStartHandlerWithState(state *appstate.State) gin.Handle
 			}
 		}
 
-		mode := req.Mode
-		if mode == "" {
-			mode = "team"
+		proj, err := rt.resolveSessionProject(c.Request.Context(), sessionID, req.Project)
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		mode, agentName := applyProjectDefaults(proj, req.Mode, req.AgentName)
+		if !requestAllowsAgent(c.Request.Context(), mode, agentName) {
+			Fail(c, http.StatusForbidden, fmt.Sprintf("API key is not allowed to use agent %q", agentTarget(mode, agentName)))
+			return
 		}
 
-		ctx := appstate.WithState(context.Background(), state)
-		r, err := rt.resolveRunner(ctx, mode, req.AgentName)
+		ctx, err := withProjectScope(appstate.WithState(detachedContext(c), state), proj)
 		if err != nil {
-			log.Printf("failed to resolve runner: mode=%s, agent=%s, err=%v", mode, req.AgentName, err)
+			FailError(c, err)
+			return
+		}
+		r, err := rt.resolveRunner(ctx, mode, agentName)
+		if err != nil {
+			log.Printf("failed to resolve runner: mode=%s, agent=%s, err=%v", mode, agentName, err)
 			status := http.StatusInternalServerError
-			if req.AgentName != "" {
+			if agentName != "" {
 				status = http.StatusBadRequest
 			}
 			Fail(c, status, err.Error())
@@ -96,7 +119,7 @@ func (rt *Runtime) StreamStartHandlerWithState(state *appstate.State) gin.Handle
 			Cancel:     taskCancel,
 			CleanupTTL: 5 * time.Minute,
 			Mode:       mode,
-			AgentName:  req.AgentName,
+			AgentName:  agentName,
 		})
 		if !created {
 			taskCancel()
@@ -111,9 +134,9 @@ func (rt *Runtime) StreamStartHandlerWithState(state *appstate.State) gin.Handle
 		rt.restorePersistentQueue(sessionID, stream)
 		recorder, releaseRecorder := rt.acquireRecorderLocked(sessionID)
 		manager := memoryFromState(state)
-		turnInput, userDisplayText := buildChatInput(recorder, req.Message, req.Contents, manager)
+		turnInput, userDisplayText := buildChatInput(recorder, req.Message, req.Contents, manager, chatMemoryScope(ctx, mode, agentName))
 
-		rt.updateSessionExecutionMetadata(sessionID, userDisplayText, mode, req.AgentName)
+		rt.updateSessionExecutionMetadata(ctx, sessionID, userDisplayText, mode, agentName)
 		initialRunID := newTurnRunID(sessionID)
 		initialTurnID := turnIDForRun(initialRunID)
 		stream.SetTurn(initialRunID, initialTurnID)
@@ -122,7 +145,7 @@ func (rt *Runtime) StreamStartHandlerWithState(state *appstate.State) gin.Handle
 		// 后台执行任务
 		if !rt.Go(func() {
 			defer releaseRecorder()
-			rt.runStreamTask(taskCtx, stream, sessionID, r, recorder, turnInput, userDisplayText, manager, initialRunID)
+			rt.runStreamTask(taskCtx, stream, sessionID, r, recorder, turnInput, userDisplayText, manager, initialRunID, nil)
 		}) {
 			taskCancel()
 			releaseRecorder()
@@ -167,6 +190,7 @@ func (rt *Runtime) StreamSteerHandler() gin.HandlerFunc {
 			Fail(c, http.StatusBadRequest, "message or contents is required")
 			return
 		}
+		req.Contents = scopeFileContents(c.Request.Context(), req.Contents)
 		if req.SessionID == "" {
 			Fail(c, http.StatusBadRequest, "session_id is required")
 			return
@@ -175,6 +199,9 @@ func (rt *Runtime) StreamSteerHandler() gin.HandlerFunc {
 			Fail(c, http.StatusBadRequest, "invalid session ID")
 			return
 		}
+		if !rt.authorizeSession(c, req.SessionID) {
+			return
+		}
 
 		stream := rt.Streams.Get(req.SessionID)
 		if stream == nil || stream.Status() != "processing" {
@@ -232,6 +259,7 @@ func (rt *Runtime) StreamQueueUpdateHandler() gin.HandlerFunc {
 			Fail(c, http.StatusBadRequest, "message or contents is required")
 			return
 		}
+		req.Contents = scopeFileContents(c.Request.Context(), req.Contents)
 		queued := queuedChatMessage(taskstream.QueueFollowUp, req.Message, req.Contents)
 		stream, ok := rt.editQueue(c, sessionID)
 		if !ok {
@@ -377,12 +405,13 @@ func (rt *Runtime) streamForQueueRequest(c *gin.Context, sessionID string) *task
 	return stream
 }
 
-// runStreamTask 后台执行流式任务
-func (rt *Runtime) runStreamTask(ctx context.Context, stream *taskstream.Stream, sessionID string, r runtimeport.Runner, recorder *eventlog.HistoryRecorder, turnInput domainmessage.TurnInput, userDisplayText string, manager appstate.MemoryManager, initialRunID string) {
+// runStreamTask 后台执行流式任务。resume 非空时首轮从会话 checkpoint 恢复中断。
+func (rt *Runtime) runStreamTask(ctx context.Context, stream *taskstream.Stream, sessionID string, r runtimeport.Runner, recorder *eventlog.HistoryRecorder, turnInput domainmessage.TurnInput, userDisplayText string, manager appstate.MemoryManager, initialRunID string, resume runtimeport.InterruptDecisions) {
 	ctx = rt.withExecutionDependencies(ctx)
+	ctx = appusage.WithScope(ctx, appusage.Scope{Channel: appusage.ChannelWeb})
 	defer stream.Done()
 
-	interruptHandler := buildStreamInterruptHandler(stream, recorder, sessionID)
+	interruptHandler := rt.buildStreamInterruptHandler(stream, recorder, sessionID)
 	currentRunID := initialRunID
 	if currentRunID == "" {
 		currentRunID = newTurnRunID(sessionID)
@@ -401,10 +430,13 @@ func (rt *Runtime) runStreamTask(ctx context.Context, stream *taskstream.Stream,
 			Input:            currentInput,
 			Summary:          recorder,
 			InterruptHandler: runtimeport.InterruptHandler(interruptHandler),
+			Resume:           resume,
 			NonInteractive:   true,
-			ApprovalRegistry: configuredApprovalRegistry(),
+			ApprovalRegistry: configuredApprovalRegistry(ctx),
 			AskHandler:       buildMemberAskRuntimeHandler(stream, recorder, sessionID),
 			SteeringSource:   steeringSource,
+			Memory:           manager,
+			MemoryScope:      chatMemoryScope(ctx, stream.Mode(), stream.AgentName()),
 			EventSink: func(event events.Event) error {
 				if stream.Status() == "cancelled" {
 					return context.Canceled
@@ -414,9 +446,13 @@ func (rt *Runtime) runStreamTask(ctx context.Context, stream *taskstream.Stream,
 				return nil
 			},
 		})
+		resume = nil
 		if runErr != nil {
 			if isConnectionClosed(ctx, runErr) {
 				log.Printf("stream task cancelled: session=%s", sessionID)
+				if !rt.isClosing() {
+					rt.deletePendingInterrupt(sessionID)
+				}
 				if stream.Status() != "cancelled" {
 					stream.SetStatus("cancelled")
 					stream.Publish(cancelledEventPayload(sessionID, currentRunID, "任务已取消"))
@@ -425,6 +461,7 @@ func (rt *Runtime) runStreamTask(ctx context.Context, stream *taskstream.Stream,
 				return
 			}
 			log.Printf("stream task error: session=%s, err=%v", sessionID, runErr)
+			rt.deletePendingInterrupt(sessionID)
 			stream.SetStatus("error")
 			stream.Publish(errorEventPayload(sessionID, runErr.Error()))
 			rt.finishErrorChat(recorder, sessionID, currentDisplayText, runErr)
@@ -437,9 +474,9 @@ func (rt *Runtime) runStreamTask(ctx context.Context, stream *taskstream.Stream,
 			publishQueueUpdated(stream, sessionID)
 			rt.persistQueueSnapshot(sessionID, stream)
 			currentDisplayText = queued.DisplayText
-			currentInput = buildQueuedChatInput(recorder, queued, manager)
+			currentInput = buildQueuedChatInput(recorder, queued, manager, chatMemoryScope(ctx, stream.Mode(), stream.AgentName()))
 			currentRunID = queuedTurnRunID(sessionID, queued)
-			rt.updateSessionExecutionMetadata(sessionID, currentDisplayText, stream.Mode(), stream.AgentName())
+			rt.updateSessionExecutionMetadata(ctx, sessionID, currentDisplayText, stream.Mode(), stream.AgentName())
 			publishQueuedExecutionStart(stream, sessionID, queued, currentRunID)
 			continue
 		}
@@ -451,7 +488,7 @@ func (rt *Runtime) runStreamTask(ctx context.Context, stream *taskstream.Stream,
 			return
 		}
 		stream.Publish(processingEndEventPayload(sessionID, currentRunID, "处理完成"))
-		rt.finishChat(recorder, sessionID, currentDisplayText, manager)
+		rt.finishChat(recorder, sessionID, currentDisplayText, manager, chatMemoryScope(ctx, stream.Mode(), stream.AgentName()))
 		return
 	}
 }
@@ -792,10 +829,15 @@ func (rt *Runtime) StreamApprovalHandler() gin.HandlerFunc {
 			Fail(c, http.StatusBadRequest, "invalid session ID")
 			return
 		}
+		if !rt.authorizeSession(c, req.SessionID) {
+			return
+		}
 
 		stream := rt.Streams.Get(req.SessionID)
 		if stream == nil || stream.Status() != "processing" {
-			Fail(c, http.StatusNotFound, "no running task for this session")
+			rt.resumePendingInterrupt(c, req.SessionID, taskstream.InterruptApproval, "", func(pending taskstream.PendingInterrupt) runtimeport.InterruptDecisions {
+				return pending.Decisions(req.Decision)
+			})
 			return
 		}
 
@@ -825,10 +867,7 @@ func (rt *Runtime) StreamAskResponseHandler() gin.HandlerFunc {
 			Fail(c, http.StatusBadRequest, "invalid session ID")
 			return
 		}
-
-		stream := rt.Streams.Get(req.SessionID)
-		if stream == nil || stream.Status() != "processing" {
-			Fail(c, http.StatusNotFound, "no running task for this session")
+		if !rt.authorizeSession(c, req.SessionID) {
 			return
 		}
 
@@ -837,6 +876,13 @@ func (rt *Runtime) StreamAskResponseHandler() gin.HandlerFunc {
 			Selected: req.Selected,
 			FreeText: req.FreeText,
 		}
+		stream := rt.Streams.Get(req.SessionID)
+		if stream == nil || stream.Status() != "processing" {
+			rt.resumePendingInterrupt(c, req.SessionID, taskstream.InterruptAsk, req.AskID, func(pending taskstream.PendingInterrupt) runtimeport.InterruptDecisions {
+				return pending.Decisions(resp)
+			})
+			return
+		}
 		if err := stream.SubmitAskResponse(req.AskID, resp); err == nil {
 			OK(c, gin.H{"message": "response submitted"})
 		} else {
@@ -886,8 +932,9 @@ func (rt *Runtime) StreamEventsHandler() gin.HandlerFunc {
 
 // ==================== 内部辅助 ====================
 
-// buildStreamInterruptHandler 构建流式任务的 HITL 中断处理器
-func buildStreamInterruptHandler(stream *taskstream.Stream, recorder *eventlog.HistoryRecorder, sessionID string) runtimeport.InterruptHandler {
+// buildStreamInterruptHandler 构建流式任务的 HITL 中断处理器。
+// 等待期间中断会持久化到会话目录，进程重启后仍可通过审批或 ask 回答恢复。
+func (rt *Runtime) buildStreamInterruptHandler(stream *taskstream.Stream, recorder *eventlog.HistoryRecorder, sessionID string) runtimeport.InterruptHandler {
 	channelHandler := appchat.ChannelInterruptHandler(stream.InterruptCh())
 	return func(ctx context.Context, interrupts []runtimeport.Interrupt) (runtimeport.InterruptDecisions, error) {
 		// 检查是否为 ask_questions 中断
@@ -902,9 +949,17 @@ func buildStreamInterruptHandler(stream *taskstream.Stream, recorder *eventlog.H
 			askEvent = events.NormalizeEvent(askEvent)
 			recorder.RecordEvent(askEvent)
 			stream.Publish(standardEventPayload(sessionID, askEvent, nil))
+			pending := rt.newPendingInterrupt(stream, taskstream.InterruptAsk, []string{askID})
+			if info != nil {
+				pending.Question = info.Question
+				pending.Options = info.Options
+				pending.MultiSelect = info.MultiSelect
+			}
+			rt.savePendingInterrupt(pending)
 
 			result, err := appchat.ChannelTargetInterruptHandler(stream.InterruptCh(), askID)(ctx, interrupts)
 			if err == nil {
+				rt.deletePendingInterrupt(sessionID)
 				answerEvent := askAnsweredEvent(memberEvent, askID, askResponseFromResult(askID, result), askResponseText(result))
 				answerEvent = events.NormalizeEvent(answerEvent)
 				recorder.RecordEvent(answerEvent)
@@ -925,16 +980,21 @@ func buildStreamInterruptHandler(stream *taskstream.Stream, recorder *eventlog.H
 			TurnID:  turnID,
 			Content: msg,
 			Approval: &events.ApprovalPayload{
-				Message: msg,
+				Message:  msg,
+				Findings: extractApprovalFindings(interrupts),
 			},
 		})
 		recorder.RecordEvent(approvalEvent)
 		payload := standardEventPayload(sessionID, approvalEvent, nil)
 		payload["message"] = msg
 		stream.Publish(payload)
+		pending := rt.newPendingInterrupt(stream, taskstream.InterruptApproval, rootInterruptIDs(interrupts))
+		pending.Message = msg
+		rt.savePendingInterrupt(pending)
 
 		result, err := channelHandler(ctx, interrupts)
 		if err == nil {
+			rt.deletePendingInterrupt(sessionID)
 			if text := approvalDecisionText(result); text != "" {
 				recorder.RecordEvent(events.NormalizeEvent(events.Event{
 					Type:    events.EventApprovalAnswered,
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"time"
+
+	eventlog "fkteams/internal/adapters/storage/file/history"
+	"fkteams/internal/app/chat/taskstream"
+	domainmessage "fkteams/internal/domain/message"
+	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/events"
+	"fkteams/internal/runtime/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamInterruptsHandler 列出等待人工输入的中断，包括进程重启前遗留、可直接恢复的中断。
+func (rt *Runtime) StreamInterruptsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rt.Interrupts == nil {
+			OK(c, gin.H{"interrupts": []gin.H{}})
+			return
+		}
+		pendings, err := rt.Interrupts.ListPendingInterrupts(c.Request.Context())
+		if err != nil {
+			Fail(c, http.StatusInternalServerError, "failed to list pending interrupts")
+			return
+		}
+		result := make([]gin.H, 0, len(pendings))
+		for _, pending := range pendings {
+			if rt.SessionService.Authorize(c.Request.Context(), pending.SessionID) != nil {
+				continue
+			}
+			stream := rt.Streams.Get(pending.SessionID)
+			result = append(result, gin.H{
+				"interrupt": pending,
+				"active":    stream != nil && stream.Status() == "processing",
+			})
+		}
+		OK(c, gin.H{"interrupts": result})
+	}
+}
+
+// resumePendingInterrupt 在会话没有运行中任务时，从持久化的中断和 checkpoint 恢复执行。
+func (rt *Runtime) resumePendingInterrupt(c *gin.Context, sessionID string, kind taskstream.InterruptKind, interruptID string, decide func(taskstream.PendingInterrupt) runtimeport.InterruptDecisions) {
+	if rt.Interrupts == nil {
+		Fail(c, http.StatusNotFound, "no running task for this session")
+		return
+	}
+	unlockSession := rt.lockSessionOperation(sessionID)
+	defer unlockSession()
+
+	pending, err := rt.Interrupts.LoadPendingInterrupt(c.Request.Context(), sessionID)
+	if err != nil {
+		log.Printf("failed to load pending interrupt: session=%s, err=%v", sessionID, err)
+		Fail(c, http.StatusInternalServerError, "failed to load pending interrupt")
+		return
+	}
+	if pending == nil || pending.Kind != kind {
+		Fail(c, http.StatusNotFound, "no running task for this session")
+		return
+	}
+	if interruptID != "" && !slices.Contains(pending.InterruptIDs, interruptID) {
+		Fail(c, http.StatusConflict, "pending request id mismatch")
+		return
+	}
+	decisions := decide(*pending)
+	if len(decisions) == 0 {
+		Fail(c, http.StatusConflict, "pending interrupt has no resumable target")
+		return
+	}
+
+	mode := pending.Mode
+	if mode == "" {
+		mode = "team"
+	}
+	proj, err := rt.resolveSessionProject(c.Request.Context(), sessionID, "")
+	if err != nil {
+		FailError(c, err)
+		return
+	}
+	baseCtx, err := withProjectScope(detachedContext(c), proj)
+	if err != nil {
+		FailError(c, err)
+		return
+	}
+	r, err := rt.resolveRunner(baseCtx, mode, pending.AgentName)
+	if err != nil {
+		log.Printf("failed to resolve runner for resume: session=%s, mode=%s, agent=%s, err=%v", sessionID, mode, pending.AgentName, err)
+		Fail(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	taskCtx, taskCancel := context.WithCancel(baseCtx)
+	stream, created := rt.Streams.RegisterIfIdle(taskstream.StreamConfig{
+		SessionID:  sessionID,
+		Cancel:     taskCancel,
+		CleanupTTL: 5 * time.Minute,
+		Mode:       mode,
+		AgentName:  pending.AgentName,
+	})
+	if !created {
+		taskCancel()
+		Fail(c, http.StatusConflict, "task is already running for this session")
+		return
+	}
+	rt.restorePersistentQueue(sessionID, stream)
+	recorder, releaseRecorder := rt.acquireRecorderLocked(sessionID)
+
+	runID := pending.RunID
+	if runID == "" {
+		runID = newTurnRunID(sessionID)
+	}
+	turnID := turnIDForRun(runID)
+	stream.SetTurn(runID, turnID)
+	recordResumedDecision(recorder, *pending, runID, turnID, decisions)
+	rt.deletePendingInterrupt(sessionID)
+	rt.updateSessionExecutionMetadata(taskCtx, sessionID, "", mode, pending.AgentName)
+	stream.Publish(standardMessageEventPayload(sessionID, runID, turnID, "正在恢复中断的任务..."))
+
+	if !rt.Go(func() {
+		defer releaseRecorder()
+		rt.runStreamTask(taskCtx, stream, sessionID, r, recorder, domainmessage.TurnInput{}, "", nil, runID, decisions)
+	}) {
+		taskCancel()
+		releaseRecorder()
+		stream.Done()
+		Fail(c, http.StatusServiceUnavailable, "HTTP runtime is shutting down")
+		return
+	}
+	unlockSession()
+
+	OK(c, gin.H{
+		"session_id": sessionID,
+		"status":     "processing",
+		"message":    "task resumed",
+	})
+}
+
+// recordResumedDecision 补记恢复时提交的人工输入，与在线审批 / 回答的历史记录保持一致。
+func recordResumedDecision(recorder *eventlog.HistoryRecorder, pending taskstream.PendingInterrupt, runID, turnID string, decisions runtimeport.InterruptDecisions) {
+	switch pending.Kind {
+	case taskstream.InterruptAsk:
+		askID := ""
+		if len(pending.InterruptIDs) > 0 {
+			askID = pending.InterruptIDs[0]
+		}
+		event := askAnsweredEvent(events.Event{RunID: runID, TurnID: turnID}, askID, askResponseFromResult(askID, decisions), askResponseText(decisions))
+		recorder.RecordEvent(events.NormalizeEvent(event))
+	case taskstream.InterruptApproval:
+		if text := approvalDecisionText(decisions); text != "" {
+			recorder.RecordEvent(events.NormalizeEvent(events.Event{
+				Type:    events.EventApprovalAnswered,
+				RunID:   runID,
+				TurnID:  turnID,
+				Content: text,
+				Approval: &events.ApprovalPayload{
+					Decision: text,
+				},
+			}))
+		}
+	}
+}
+
+func (rt *Runtime) newPendingInterrupt(stream *taskstream.Stream, kind taskstream.InterruptKind, ids []string) taskstream.PendingInterrupt {
+	runID, turnID := stream.CurrentTurn()
+	return taskstream.PendingInterrupt{
+		SessionID:    stream.SessionID(),
+		Kind:         kind,
+		InterruptIDs: ids,
+		RunID:        runID,
+		TurnID:       turnID,
+		Mode:         stream.Mode(),
+		AgentName:    stream.AgentName(),
+		CreatedAt:    time.Now(),
+	}
+}
+
+func (rt *Runtime) savePendingInterrupt(pending taskstream.PendingInterrupt) {
+	if rt.Interrupts == nil || len(pending.InterruptIDs) == 0 {
+		return
+	}
+	if err := rt.Interrupts.SavePendingInterrupt(context.Background(), pending); err != nil {
+		log.Printf("failed to persist pending interrupt: session=%s, err=%v", pending.SessionID, err)
+	}
+}
+
+func (rt *Runtime) deletePendingInterrupt(sessionID string) {
+	if rt.Interrupts == nil {
+		return
+	}
+	if err := rt.Interrupts.DeletePendingInterrupt(context.Background(), sessionID); err != nil {
+		log.Printf("failed to delete pending interrupt: session=%s, err=%v", sessionID, err)
+	}
+}
+
+func rootInterruptIDs(interrupts []runtimeport.Interrupt) []string {
+	ids := make([]string, 0, len(interrupts))
+	for _, ic := range interrupts {
+		if ic.IsRootCause && ic.ID != "" {
+			ids = append(ids, ic.ID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"fkteams/internal/app/chat/taskstream"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStreamInterruptsListsPersistedInterrupts(t *testing.T) {
+	rt := newTestRuntime(t)
+	gin.SetMode(gin.TestMode)
+
+	if err := rt.Interrupts.SavePendingInterrupt(context.Background(), taskstream.PendingInterrupt{
+		SessionID:    "session-1",
+		Kind:         taskstream.InterruptApproval,
+		InterruptIDs: []string{"interrupt-1"},
+		Mode:         "team",
+		Message:      "run command",
+		CreatedAt:    time.Now(),
+	}); err != nil {
+		t.Fatalf("save pending interrupt: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/stream/interrupts", rt.StreamInterruptsHandler())
+
+	resp := performRequest(router, http.MethodGet, "/stream/interrupts", nil)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("interrupts status = %d: %s", resp.Code, resp.Body.String())
+	}
+	var got struct {
+		Interrupts []struct {
+			Interrupt taskstream.PendingInterrupt `json:"interrupt"`
+			Active    bool                        `json:"active"`
+		} `json:"interrupts"`
+	}
+	decodeRawData(t, resp, &got)
+	if len(got.Interrupts) != 1 || got.Interrupts[0].Interrupt.SessionID != "session-1" || got.Interrupts[0].Active {
+		t.Fatalf("unexpected interrupts: %#v", got.Interrupts)
+	}
+}
+
+func TestStreamApprovalWithoutTaskOrPendingInterruptReturnsNotFound(t *testing.T) {
+	rt := newTestRuntime(t)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/stream/approval", rt.StreamApprovalHandler())
+
+	resp := performRequest(router, http.MethodPost, "/stream/approval", strings.NewReader(`{"session_id":"session-1","decision":1}`))
+	if resp.Code != http.StatusNotFound {
+		t.Fatalf("approval status = %d, want 404: %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestStreamAskResponseRejectsMismatchedPendingInterrupt(t *testing.T) {
+	rt := newTestRuntime(t)
+	gin.SetMode(gin.TestMode)
+
+	if err := rt.Interrupts.SavePendingInterrupt(context.Background(), taskstream.PendingInterrupt{
+		SessionID:    "session-1",
+		Kind:         taskstream.InterruptAsk,
+		InterruptIDs: []string{"ask-1"},
+		CreatedAt:    time.Now(),
+	}); err != nil {
+		t.Fatalf("save pending interrupt: %v", err)
+	}
+
+	router := gin.New()
+	router.POST("/stream/ask-response", rt.StreamAskResponseHandler())
+
+	resp := performRequest(router, http.MethodPost, "/stream/ask-response", strings.NewReader(`{"session_id":"session-1","ask_id":"ask-2","free_text":"yes"}`))
+	if resp.Code != http.StatusConflict {
+		t.Fatalf("ask-response status = %d, want 409: %s", resp.Code, resp.Body.String())
+	}
+}
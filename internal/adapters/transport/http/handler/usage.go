@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	appusage "fkteams/internal/app/usage"
+	domainusage "fkteams/internal/domain/usage"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultUsageRecordLimit = 100
+
+// usageFilterFromQuery 从查询参数解析用量过滤条件。
+func usageFilterFromQuery(c *gin.Context) (domainusage.Filter, error) {
+	from, to, err := appusage.ParseDayRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		return domainusage.Filter{}, err
+	}
+	return domainusage.Filter{
+		From:      from,
+		To:        to,
+		SessionID: c.Query("session_id"),
+		TaskID:    c.Query("task_id"),
+		Channel:   c.Query("channel"),
+		Agent:     c.Query("agent"),
+		Model:     c.Query("model"),
+	}, nil
+}
+
+// UsageSummaryHandler 按维度汇总模型用量与费用。
+
+func (rt *Runtime) UsageSummaryHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rt.Usage == nil {
+			Fail(c, http.StatusServiceUnavailable, "usage ledger not initialized")
+			return
+		}
+		filter, err := usageFilterFromQuery(c)
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		by := domainusage.Dimension(c.DefaultQuery("by", string(domainusage.ByModel)))
+		summary, err := rt.Usage.Summary(c.Request.Context(), filter, by)
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		OK(c, summary)
+	}
+}
+
+// UsageRecordsHandler 按时间倒序返回用量明细。
+
+func (rt *Runtime) UsageRecordsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rt.Usage == nil {
+			Fail(c, http.StatusServiceUnavailable, "usage ledger not initialized")
+			return
+		}
+		filter, err := usageFilterFromQuery(c)
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		limit := defaultUsageRecordLimit
+		if raw := c.Query("limit"); raw != "" {
+			limit, err = strconv.Atoi(raw)
+			if err != nil || limit <= 0 {
+				Fail(c, http.StatusBadRequest, "limit must be a positive integer")
+				return
+			}
+		}
+		records, err := rt.Usage.Records(c.Request.Context(), filter, limit)
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		OK(c, gin.H{"records": records})
+	}
+}
+
+// UsageBudgetsHandler 返回当日及指定会话的预算使用情况。
+
+func (rt *Runtime) UsageBudgetsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rt.Usage == nil {
+			Fail(c, http.StatusServiceUnavailable, "usage ledger not initialized")
+			return
+		}
+		statuses, err := rt.Usage.Status(c.Request.Context(), c.Query("session_id"))
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		OK(c, gin.H{"budgets": statuses})
+	}
+}
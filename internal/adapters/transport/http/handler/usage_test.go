@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	fileusage "fkteams/internal/adapters/storage/file/usage"
+	appusage "fkteams/internal/app/usage"
+	domainusage "fkteams/internal/domain/usage"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUsageHandlersSummarizeAndListRecords(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ledger := appusage.NewLedger(fileusage.NewStore(t.TempDir()), nil)
+	now := time.Now()
+	for _, record := range []domainusage.Record{
+		{At: now, SessionID: "s1", Agent: "leader", Model: "gpt", TotalTokens: 30},
+		{At: now, SessionID: "s2", Agent: "coder", Model: "gpt", TotalTokens: 10},
+		{At: now.AddDate(0, 0, -3), SessionID: "s1", Agent: "leader", Model: "old", TotalTokens: 5},
+	} {
+		if _, err := ledger.Record(context.Background(), record); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	router := gin.New()
+	rt := NewRuntime(RuntimeOptions{Usage: ledger})
+	router.GET("/usage", rt.UsageSummaryHandler())
+	router.GET("/usage/records", rt.UsageRecordsHandler())
+
+	today := now.Format(domainusage.DayLayout)
+	resp := performRequest(router, http.MethodGet, "/usage?by=agent&from="+today+"&to="+today, nil)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("summary status = %d: %s", resp.Code, resp.Body.String())
+	}
+	var summary domainusage.Summary
+	decodeRawData(t, resp, &summary)
+	if summary.Totals.TotalTokens != 40 || len(summary.Groups) != 2 || summary.Groups[0].Key != "leader" {
+		t.Fatalf("summary = %#v", summary)
+	}
+
+	resp = performRequest(router, http.MethodGet, "/usage/records?session_id=s1&limit=1", nil)
+	var payload struct {
+		Records []domainusage.Record `json:"records"`
+	}
+	decodeRawData(t, resp, &payload)
+	if len(payload.Records) != 1 || payload.Records[0].Model != "gpt" {
+		t.Fatalf("records = %#v", payload.Records)
+	}
+
+	if resp := performRequest(router, http.MethodGet, "/usage?by=color", nil); resp.Code != http.StatusBadRequest {
+		t.Fatalf("invalid dimension status = %d", resp.Code)
+	}
+	if resp := performRequest(router, http.MethodGet, "/usage?from=yesterday", nil); resp.Code != http.StatusBadRequest {
+		t.Fatalf("invalid date status = %d", resp.Code)
+	}
+}
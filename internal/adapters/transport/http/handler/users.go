@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	fileaccount "fkteams/internal/adapters/storage/file/account"
+	appaccount "fkteams/internal/app/account"
+	"fkteams/internal/app/appdata"
+	"fkteams/internal/app/config"
+	domainaccount "fkteams/internal/domain/account"
+	"fkteams/internal/runtime/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	accountsMu   sync.Mutex
+	accountsPath string
+	accounts     *appaccount.Service
+)
+
+// accountService 返回当前数据目录的账号服务，数据目录变化时重新创建。
+func accountService() *appaccount.Service {
+	path := appdata.UsersFile()
+	accountsMu.Lock()
+	defer accountsMu.Unlock()
+	if accounts == nil || accountsPath != path {
+		accounts = appaccount.NewService(fileaccount.NewStore(path))
+		accountsPath = path
+	}
+	return accounts
+}
+
+// RequestPrincipal 返回认证中间件注入的请求身份，未启用认证时返回 false。
+func RequestPrincipal(c *gin.Context) (domainaccount.Principal, bool) {
+	return domainaccount.PrincipalFromContext(c.Request.Context())
+}
+
+// UserInfo 是返回给前端的账号信息，不包含密码哈希。
+type UserInfo struct {
+	Username  string             `json:"username"`
+	Role      domainaccount.Role `json:"role"`
+	Disabled  bool               `json:"disabled,omitempty"`
+	Builtin   bool               `json:"builtin,omitempty"`
+	Provider  string             `json:"provider,omitempty"`
+	CreatedAt *time.Time         `json:"created_at,omitempty"`
+	UpdatedAt *time.Time         `json:"updated_at,omitempty"`
+}
+
+func userInfo(user domainaccount.User) UserInfo {
+	return UserInfo{
+		Username:  user.Username,
+		Role:      user.Role,
+		Disabled:  user.Disabled,
+		Provider:  user.Provider,
+		CreatedAt: &user.CreatedAt,
+		UpdatedAt: &user.UpdatedAt,
+	}
+}
+
+// MeHandler 返回当前登录的账号，前端据此隐藏无权限的操作。
+func MeHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := RequestPrincipal(c)
+		if !ok {
+			OK(c, gin.H{"auth_enabled": false, "role": domainaccount.RoleAdmin})
+			return
+		}
+		OK(c, gin.H{
+			"auth_enabled": true,
+			"username":     principal.Username,
+			"role":         principal.Role,
+			"builtin":      principal.Builtin,
+		})
+	}
+}
+
+// ListUsersHandler 列出所有账号，配置文件中的内置管理员排在最前。
+func ListUsersHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		users, err := accountService().List(c.Request.Context())
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		result := make([]UserInfo, 0, len(users)+1)
+		if auth := config.Get().Server.Auth; auth.Enabled && auth.Username != "" {
+			result = append(result, UserInfo{Username: auth.Username, Role: domainaccount.RoleAdmin, Builtin: true})
+		}
+		for _, user := range users {
+			result = append(result, userInfo(user))
+		}
+		OK(c, gin.H{"users": result})
+	}
+}
+
+// CreateUserHandler 新建账号。
+func CreateUserHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req struct {
+			Username string             `json:"username"`
+			Password string             `json:"password"`
+			Role     domainaccount.Role `json:"role"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			Fail(c, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if req.Role == "" {
+			req.Role = domainaccount.RoleMember
+		}
+		user, err := accountService().Create(c.Request.Context(), appaccount.CreateRequest{
+			Username: req.Username,
+			Password: req.Password,
+			Role:     req.Role,
+		}, config.Get().Server.Auth.Username)
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		log.Printf("user created: username=%s, role=%s", user.Username, user.Role)
+		Created(c, userInfo(user))
+	}
+}
+
+// UpdateUserHandler 修改账号的密码、角色或停用状态；内置管理员只能通过配置文件修改。
+func UpdateUserHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.Param("username")
+		if username == config.Get().Server.Auth.Username {
+			Fail(c, http.StatusForbidden, "the built-in administrator is managed in config.toml")
+			return
+		}
+		var req struct {
+			Password *string             `json:"password"`
+			Role     *domainaccount.Role `json:"role"`
+			Disabled *bool               `json:"disabled"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			Fail(c, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		user, err := accountService().Update(c.Request.Context(), username, appaccount.UpdateRequest{
+			Password: req.Password,
+			Role:     req.Role,
+			Disabled: req.Disabled,
+		})
+		if err != nil {
+			FailError(c, err)
+			return
+		}
+		log.Printf("user updated: username=%s, role=%s, disabled=%t", user.Username, user.Role, user.Disabled)
+		OK(c, userInfo(user))
+	}
+}
+
+// DeleteUserHandler 删除账号，账号名下的数据保留给管理员处理。
+func DeleteUserHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.Param("username")
+		if username == config.Get().Server.Auth.Username {
+			Fail(c, http.StatusForbidden, "the built-in administrator is managed in config.toml")
+			return
+		}
+		if err := accountService().Delete(c.Request.Context(), username); err != nil {
+			FailError(c, err)
+			return
+		}
+		log.Printf("user deleted: username=%s", username)
+		OK(c, nil)
+	}
+}
+
+// migrateLegacyOwnership 在启用认证后首次启动时，将单用户时期没有归属的会话、定时任务和分享链接
+// 归属到配置文件中的管理员。迁移失败时保留未归属状态（只有管理员可见），下次启动重试。
+func (rt *Runtime) migrateLegacyOwnership(ctx context.Context) {
+	authEnabled, err := AuthEnabled()
+	if err != nil || !authEnabled {
+		return
+	}
+	service := accountService()
+	migrated, err := service.LegacyOwner(ctx)
+	if err != nil {
+		log.Warnf("check account migration failed: %v", err)
+		return
+	}
+	if migrated != "" {
+		return
+	}
+	owner := config.Get().Server.Auth.Username
+
+	sessions, err := rt.SessionService.AssignOwner(ctx, owner)
+	if err != nil {
+		log.Warnf("assign legacy sessions to %s failed: %v", owner, err)
+		return
+	}
+	var tasks int
+	if rt.Scheduler != nil {
+		if tasks, err = rt.Scheduler.AssignOwner(ctx, owner); err != nil {
+			log.Warnf("assign legacy scheduled tasks to %s failed: %v", owner, err)
+			return
+		}
+	}
+	links, err := rt.PreviewLinks.AssignOwner(owner)
+	if err != nil {
+		log.Warnf("assign legacy preview links to %s failed: %v", owner, err)
+		return
+	}
+	shares, err := rt.SessionShares.AssignOwner(owner)
+	if err != nil {
+		log.Warnf("assign legacy session shares to %s failed: %v", owner, err)
+		return
+	}
+	if err := service.MarkMigrated(ctx, owner); err != nil {
+		log.Warnf("record account migration failed: %v", err)
+		return
+	}
+	log.Printf("assigned legacy data to %s: sessions=%d, schedules=%d, preview_links=%d, session_shares=%d", owner, sessions, tasks, links, shares)
+}
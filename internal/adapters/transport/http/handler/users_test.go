@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	eventlog "fkteams/internal/adapters/storage/file/history"
+	appaccount "fkteams/internal/app/account"
+	"fkteams/internal/app/config"
+	appsession "fkteams/internal/app/session"
+	domainaccount "fkteams/internal/domain/account"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLoginHandlerIssuesTokensForStoredUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	saveHandlerConfig(t, config.Config{Server: config.Server{Auth: config.ServerAuth{
+		Enabled:  true,
+		Username: "admin",
+		Password: "secret",
+		Secret:   "token-secret",
+	}}})
+	ctx := context.Background()
+	if _, err := accountService().Create(ctx, appaccount.CreateRequest{
+		Username: "mia", Password: "member-pass", Role: domainaccount.RoleMember,
+	}); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	router := gin.New()
+	router.POST("/login", LoginHandler())
+
+	if resp := performJSON(router, http.MethodPost, "/login", `{"username":"mia","password":"wrong-pass"}`); resp.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password status = %d, want 401", resp.Code)
+	}
+	token := loginToken(t, router, "mia", "member-pass")
+	principal, ok := AuthenticateToken(token)
+	if !ok {
+		t.Fatal("expected stored user token to be valid")
+	}
+	if principal.Username != "mia" || principal.Role != domainaccount.RoleMember || principal.Builtin {
+		t.Fatalf("principal = %#v", principal)
+	}
+	adminPrincipal, ok := AuthenticateToken(loginToken(t, router, "admin", "secret"))
+	if !ok || !adminPrincipal.Builtin || !adminPrincipal.IsAdmin() {
+		t.Fatalf("admin principal = %#v, ok = %v", adminPrincipal, ok)
+	}
+
+	role := domainaccount.RoleViewer
+	if _, err := accountService().Update(ctx, "mia", appaccount.UpdateRequest{Role: &role}); err != nil {
+		t.Fatalf("update role: %v", err)
+	}
+	if principal, ok := AuthenticateToken(token); !ok || principal.Role != domainaccount.RoleViewer {
+		t.Fatalf("expected role change to apply to existing token, got %#v, ok = %v", principal, ok)
+	}
+
+	password := "changed-pass"
+	if _, err := accountService().Update(ctx, "mia", appaccount.UpdateRequest{Password: &password}); err != nil {
+		t.Fatalf("update password: %v", err)
+	}
+	if _, ok := AuthenticateToken(token); ok {
+		t.Fatal("expected password change to invalidate existing token")
+	}
+
+	token = loginToken(t, router, "mia", "changed-pass")
+	disabled := true
+	if _, err := accountService().Update(ctx, "mia", appaccount.UpdateRequest{Disabled: &disabled}); err != nil {
+		t.Fatalf("disable user: %v", err)
+	}
+	if _, ok := AuthenticateToken(token); ok {
+		t.Fatal("expected disabled user token to be rejected")
+	}
+	if resp := performJSON(router, http.MethodPost, "/login", `{"username":"mia","password":"changed-pass"}`); resp.Code != http.StatusUnauthorized {
+		t.Fatalf("disabled user login status = %d, want 401", resp.Code)
+	}
+}
+
+func TestUserAdminHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	saveHandlerConfig(t, config.Config{Server: config.Server{Auth: config.ServerAuth{
+		Enabled:  true,
+		Username: "admin",
+		Password: "secret",
+		Secret:   "token-secret",
+	}}})
+
+	router := gin.New()
+	router.GET("/users", ListUsersHandler())
+	router.POST("/users", CreateUserHandler())
+	router.PATCH("/users/:username", UpdateUserHandler())
+	router.DELETE("/users/:username", DeleteUserHandler())
+
+	resp := performJSON(router, http.MethodPost, "/users", `{"username":"mia","password":"member-pass"}`)
+	if resp.Code != http.StatusCreated {
+		t.Fatalf("create user status = %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := performJSON(router, http.MethodPost, "/users", `{"username":"admin","password":"another-pass"}`); resp.Code != http.StatusConflict {
+		t.Fatalf("create reserved user status = %d, want 409", resp.Code)
+	}
+	if resp := performJSON(router, http.MethodPost, "/users", `{"username":"short","password":"123"}`); resp.Code != http.StatusBadRequest {
+		t.Fatalf("create weak password status = %d, want 400", resp.Code)
+	}
+	if resp := performJSON(router, http.MethodPost, "/users", `{"username":"bad","password":"member-pass","role":"owner"}`); resp.Code != http.StatusBadRequest {
+		t.Fatalf("create invalid role status = %d, want 400", resp.Code)
+	}
+
+	resp = performJSON(router, http.MethodGet, "/users", "")
+	var list struct {
+		Data struct {
+			Users []UserInfo `json:"users"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode users: %v", err)
+	}
+	if len(list.Data.Users) != 2 || !list.Data.Users[0].Builtin || list.Data.Users[1].Username != "mia" || list.Data.Users[1].Role != domainaccount.RoleMember {
+		t.Fatalf("users = %#v", list.Data.Users)
+	}
+	if bytes.Contains(resp.Body.Bytes(), []byte("password_hash")) {
+		t.Fatalf("users response leaked password hash: %s", resp.Body.String())
+	}
+
+	if resp := performJSON(router, http.MethodPatch, "/users/mia", `{"role":"viewer"}`); resp.Code != http.StatusOK {
+		t.Fatalf("update user status = %d: %s", resp.Code, resp.Body.String())
+	}
+	if user, err := accountService().Get(context.Background(), "mia"); err != nil || user.Role != domainaccount.RoleViewer {
+		t.Fatalf("updated user = %#v, err = %v", user, err)
+	}
+	if resp := performJSON(router, http.MethodPatch, "/users/admin", `{"role":"viewer"}`); resp.Code != http.StatusForbidden {
+		t.Fatalf("update builtin admin status = %d, want 403", resp.Code)
+	}
+	if resp := performJSON(router, http.MethodDelete, "/users/admin", ""); resp.Code != http.StatusForbidden {
+		t.Fatalf("delete builtin admin status = %d, want 403", resp.Code)
+	}
+	if resp := performJSON(router, http.MethodDelete, "/users/mia", ""); resp.Code != http.StatusOK {
+		t.Fatalf("delete user status = %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := performJSON(router, http.MethodDelete, "/users/mia", ""); resp.Code != http.StatusNotFound {
+		t.Fatalf("delete missing user status = %d, want 404", resp.Code)
+	}
+}
+
+func TestSessionHandlersEnforceOwnership(t *testing.T) {
+	rt := newTestRuntime(t)
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(testPrincipalMiddleware())
+	router.GET("/sessions", rt.ListSessionsHandler())
+	router.POST("/sessions", rt.CreateSessionHandler())
+	router.GET("/sessions/:sessionID", rt.SessionAccess(), rt.GetSessionHandler())
+	router.PATCH("/sessions/:sessionID", rt.UpdateSessionHandler())
+	router.DELETE("/sessions/:sessionID", rt.DeleteSessionHandler())
+
+	if resp := performAs(router, "alice", http.MethodPost, "/sessions", `{"session_id":"session-alice","title":"alice"}`); resp.Code != http.StatusCreated {
+		t.Fatalf("alice create status = %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := performAs(router, "bob", http.MethodPost, "/sessions", `{"session_id":"session-bob","title":"bob"}`); resp.Code != http.StatusCreated {
+		t.Fatalf("bob create status = %d: %s", resp.Code, resp.Body.String())
+	}
+	meta, err := eventlog.LoadMetadata(rt.sessionDirPath("session-alice"))
+	if err != nil {
+		t.Fatalf("load metadata: %v", err)
+	}
+	if meta.Owner != "alice" {
+		t.Fatalf("session owner = %q, want alice", meta.Owner)
+	}
+
+	if got := listedSessionIDs(t, performAs(router, "alice", http.MethodGet, "/sessions", "")); len(got) != 1 || got[0] != "session-alice" {
+		t.Fatalf("alice sessions = %v", got)
+	}
+	if got := listedSessionIDs(t, performAs(router, "root", http.MethodGet, "/sessions", "")); len(got) != 2 {
+		t.Fatalf("admin sessions = %v", got)
+	}
+	if resp := performAs(router, "bob", http.MethodGet, "/sessions/session-alice", ""); resp.Code != http.StatusNotFound {
+		t.Fatalf("bob get alice session status = %d, want 404", resp.Code)
+	}
+	if resp := performAs(router, "bob", http.MethodPatch, "/sessions/session-alice", `{"title":"stolen"}`); resp.Code != http.StatusNotFound {
+		t.Fatalf("bob update alice session status = %d, want 404", resp.Code)
+	}
+	if resp := performAs(router, "bob", http.MethodDelete, "/sessions/session-alice", ""); resp.Code != http.StatusNotFound {
+		t.Fatalf("bob delete alice session status = %d, want 404", resp.Code)
+	}
+	if resp := performAs(router, "bob", http.MethodPost, "/sessions", `{"session_id":"session-alice","title":"taken"}`); resp.Code != http.StatusConflict {
+		t.Fatalf("bob create over alice session status = %d, want 409", resp.Code)
+	}
+	if resp := performAs(router, "root", http.MethodGet, "/sessions/session-alice", ""); resp.Code != http.StatusOK {
+		t.Fatalf("admin get alice session status = %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestMigrateLegacyOwnershipAssignsUnownedData(t *testing.T) {
+	saveHandlerConfig(t, config.Config{Server: config.Server{Auth: config.ServerAuth{
+		Enabled:  true,
+		Username: "admin",
+		Password: "secret",
+		Secret:   "token-secret",
+	}}})
+	rt := newTestRuntime(t)
+	ctx := context.Background()
+	if _, _, err := rt.SessionService.Create(ctx, appsession.CreateRequest{SessionID: "legacy-session", Title: "legacy"}); err != nil {
+		t.Fatalf("create legacy session: %v", err)
+	}
+
+	rt.migrateLegacyOwnership(ctx)
+
+	meta, err := eventlog.LoadMetadata(rt.sessionDirPath("legacy-session"))
+	if err != nil {
+		t.Fatalf("load metadata: %v", err)
+	}
+	if meta.Owner != "admin" {
+		t.Fatalf("legacy session owner = %q, want admin", meta.Owner)
+	}
+	if owner, err := accountService().LegacyOwner(ctx); err != nil || owner != "admin" {
+		t.Fatalf("legacy owner = %q, err = %v", owner, err)
+	}
+
+	// 迁移只执行一次，之后新建的无归属数据保持原样
+	if _, _, err := rt.SessionService.Create(ctx, appsession.CreateRequest{SessionID: "later-session", Title: "later"}); err != nil {
+		t.Fatalf("create later session: %v", err)
+	}
+	rt.migrateLegacyOwnership(ctx)
+	meta, err = eventlog.LoadMetadata(rt.sessionDirPath("later-session"))
+	if err != nil {
+		t.Fatalf("load later metadata: %v", err)
+	}
+	if meta.Owner != "" {
+		t.Fatalf("later session owner = %q, want empty", meta.Owner)
+	}
+}
+
+// testPrincipalMiddleware 按 X-Test-User 请求头注入身份，root 为管理员，其余为成员。
+func testPrincipalMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.GetHeader("X-Test-User")
+		if username == "" {
+			c.Next()
+			return
+		}
+		role := domainaccount.RoleMember
+		if username == "root" {
+			role = domainaccount.RoleAdmin
+		}
+		principal := domainaccount.Principal{Username: username, Role: role}
+		c.Request = c.Request.WithContext(domainaccount.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+func performAs(router http.Handler, username, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-User", username)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	return resp
+}
+
+func listedSessionIDs(t *testing.T, resp *httptest.ResponseRecorder) []string {
+	t.Helper()
+	if resp.Code != http.StatusOK {
+		t.Fatalf("list sessions status = %d: %s", resp.Code, resp.Body.String())
+	}
+	var got struct {
+		Data struct {
+			Sessions []SessionInfo `json:"sessions"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode sessions: %v", err)
+	}
+	ids := make([]string, 0, len(got.Data.Sessions))
+	for _, session := range got.Data.Sessions {
+		ids = append(ids, session.SessionID)
+	}
+	return ids
+}
+
+func loginToken(t *testing.T, router http.Handler, username, password string) string {
+	t.Helper()
+	resp := performJSON(router, http.MethodPost, "/login", `{"username":"`+username+`","password":"`+password+`"}`)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("login %s status = %d: %s", username, resp.Code, resp.Body.String())
+	}
+	var got struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil || got.Data.Token == "" {
+		t.Fatalf("decode login token: %v (%s)", err, resp.Body.String())
+	}
+	return got.Data.Token
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	appagent "fkteams/internal/app/agent"
+	appchat "fkteams/internal/app/chat"
+	appusage "fkteams/internal/app/usage"
+	domainmessage "fkteams/internal/domain/message"
+	storageport "fkteams/internal/ports/storage"
+	"fkteams/internal/runtime/events"
+	"fkteams/internal/runtime/log"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// VirtualModelPrefix 是智能体虚拟模型 ID 的前缀，例如 fkteams/team、fkteams/agent:coder。
+	VirtualModelPrefix = "fkteams/"
+	virtualAgentPrefix = "agent:"
+)
+
+// virtualModel 描述一个由智能体（而非上游模型）应答的虚拟模型。
+type virtualModel struct {
+	ID        string
+	Mode      string
+	AgentName string
+}
+
+// parseVirtualModel 解析虚拟模型 ID；非 fkteams/ 前缀的 ID 返回 false，由调用方按普通模型处理。
+func parseVirtualModel(id string) (virtualModel, bool) {
+	name, ok := strings.CutPrefix(strings.TrimSpace(id), VirtualModelPrefix)
+	if !ok || name == "" {
+		return virtualModel{}, false
+	}
+	if agentName, ok := strings.CutPrefix(name, virtualAgentPrefix); ok {
+		if agentName == "" {
+			return virtualModel{}, false
+		}
+		return virtualModel{ID: id, Mode: appagent.ModeTeam, AgentName: agentName}, true
+	}
+	switch name {
+	case appagent.ModeTeam, appagent.ModeDeep, appagent.ModeRoundtable:
+		return virtualModel{ID: id, Mode: name}, true
+	}
+	return virtualModel{}, false
+}
+
+// virtualModels 列出当前 runtime 可用的全部虚拟模型。
+func (rt *Runtime) virtualModels() []virtualModel {
+	models := []virtualModel{
+		{ID: VirtualModelPrefix + appagent.ModeTeam, Mode: appagent.ModeTeam},
+		{ID: VirtualModelPrefix + appagent.ModeDeep, Mode: appagent.ModeDeep},
+		{ID: VirtualModelPrefix + appagent.ModeRoundtable, Mode: appagent.ModeRoundtable},
+	}
+	if rt.AgentRegistry == nil {
+		return models
+	}
+	for _, agent := range rt.AgentRegistry.List() {
+		models = append(models, virtualModel{
+			ID:        VirtualModelPrefix + virtualAgentPrefix + agent.Name,
+			Mode:      appagent.ModeTeam,
+			AgentName: agent.Name,
+		})
+	}
+	return models
+}
+
+// virtualModelError 携带面向 API 客户端的 HTTP 状态码。
+type virtualModelError struct {
+	Status int
+	Err    error
+}
+
+func (e *virtualModelError) Error() string { return e.Err.Error() }
+
+func (e *virtualModelError) Unwrap() error { return e.Err }
+
+// runVirtualModelTurn 以一次性会话执行虚拟模型回合，事件逐个交给 sink。
+// 对话历史由客户端随请求携带，因此不写入会话记录；回合结束后清理该会话的 checkpoint。
+func (rt *Runtime) runVirtualModelTurn(ctx context.Context, model virtualModel, input domainmessage.TurnInput, sink func(events.Event) error) error {
+	r, err := rt.resolveRunner(ctx, model.Mode, model.AgentName)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if model.AgentName != "" {
+			status = http.StatusNotFound
+		}
+		return &virtualModelError{Status: status, Err: fmt.Errorf("resolve model %q: %w", model.ID, err)}
+	}
+	ctx = rt.withExecutionDependencies(ctx)
+	ctx = appusage.WithScope(ctx, appusage.Scope{Channel: appusage.ChannelAPI})
+	sessionID := "api-" + uuid.NewString()
+	defer rt.discardCheckpoints(sessionID)
+	_, err = appchat.NewService().RunTurn(ctx, appchat.TurnRequest{
+		SessionID:        sessionID,
+		RunID:            newTurnRunID(sessionID),
+		Runner:           r,
+		Input:            input,
+		NonInteractive:   true,
+		ApprovalRegistry: configuredApprovalRegistry(ctx),
+		EventSink:        sink,
+	})
+	return err
+}
+
+// discardCheckpoints 删除一次性会话遗留的 checkpoint，这类会话无法恢复也不会出现在会话列表中。
+func (rt *Runtime) discardCheckpoints(sessionID string) {
+	cleaner, ok := rt.Checkpoints.(storageport.CheckpointCleaner)
+	if !ok {
+		return
+	}
+	if err := cleaner.DeleteCheckpoints(context.Background(), sessionID); err != nil {
+		log.Printf("[virtual-model] discard checkpoints failed: session=%s, err=%v", sessionID, err)
+	}
+}
+
+// virtualModelUsage 累计一次运行中所有 usage_reported 事件的 token 用量。
+type virtualModelUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (u *virtualModelUsage) add(event events.Event) {
+	if event.Type != events.EventUsageReported {
+		return
+	}
+	prompt, completion, total := event.PromptTokens, event.CompletionTokens, event.TotalTokens
+	if event.Usage != nil {
+		prompt, completion, total = event.Usage.PromptTokens, event.Usage.CompletionTokens, event.Usage.TotalTokens
+	}
+	if total == 0 {
+		total = prompt + completion
+	}
+	u.PromptTokens += prompt
+	u.CompletionTokens += completion
+	u.TotalTokens += total
+}
+
+// virtualModelProgress 将成员和工具事件转换为协议扩展字段，普通客户端可忽略。
+func virtualModelProgress(event events.Event) map[string]any {
+	switch event.Type {
+	case events.EventMemberStarted, events.EventMemberCompleted,
+		events.EventToolCallStarted, events.EventToolCallCompleted, events.EventToolCallFailed,
+		events.EventApprovalRequested, events.EventAskRequested, events.EventError:
+	default:
+		return nil
+	}
+	progress := map[string]any{"type": string(event.Type)}
+	if event.AgentName != "" {
+		progress["agent_name"] = event.AgentName
+	}
+	if event.MemberName != "" {
+		progress["member_name"] = event.MemberName
+	}
+	if event.ToolName != "" {
+		progress["tool_name"] = event.ToolName
+	}
+	if event.ToolCallID != "" {
+		progress["tool_call_id"] = event.ToolCallID
+	}
+	if event.Error != "" {
+		progress["error"] = event.Error
+	}
+	return progress
+}
+
+// isVirtualModelAnswer 判断事件是否属于最终回答文本：成员内部输出不计入，只作为进度扩展。
+func isVirtualModelAnswer(event events.Event) bool {
+	return event.MemberCallID == "" && event.Content != "" &&
+		(event.Type == events.EventAssistantText || event.Type == events.EventAssistantReasoning)
+}
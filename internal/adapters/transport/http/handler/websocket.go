@@ -14,6 +14,8 @@ import (
 	appchat "fkteams/internal/app/chat"
 	"fkteams/internal/app/chat/taskstream"
 	"fkteams/internal/app/tools/ask"
+	appusage "fkteams/internal/app/usage"
+	domainaccount "fkteams/internal/domain/account"
 	runtimeport "fkteams/internal/ports/runtime"
 	"fkteams/internal/runtime/events"
 
@@ -40,6 +42,8 @@ type WSMessage struct {
 	Message     string        `json:"message,omitempty"`
 	Mode        string        `json:"mode,omitempty"`
 	AgentName   string        `json:"agent_name,omitempty"`
+	Project     string        `json:"project,omitempty"`
+	RewindTo    string        `json:"rewind_to,omitempty"` // 先回退到该用户消息之前再发送
 	Decision    int           `json:"decision,omitempty"`
 	Contents    []ContentPart `json:"contents,omitempty"`
 	AskID       string        `json:"ask_id,omitempty"`
@@ -112,13 +116,27 @@ func (rt *Runtime) WebSocketHandlerWithState(state *appstate.State) gin.HandlerF
 				continue
 			}
 
-			// 每条消息二次校验 Token，防止长连接绕过认证热更新或过期限制。
+			// 每条消息二次校验 Token，防止长连接绕过认证热更新、过期限制或账号停用。
 			authEnabled, authErr := AuthEnabled()
-			if authErr != nil || authEnabled && !ValidateToken(wsToken) {
+			if authErr != nil {
 				_ = writeJSON(errorEventPayload("", "登录已过期，请重新登录"))
 				conn.Close()
 				break
 			}
+			msgCtx := context.Background()
+			if authEnabled {
+				principal, ok := AuthenticateToken(wsToken)
+				if !ok {
+					_ = writeJSON(errorEventPayload("", "登录已过期，请重新登录"))
+					conn.Close()
+					break
+				}
+				msgCtx = domainaccount.WithPrincipal(msgCtx, principal)
+			}
+			if errMsg := rt.authorizeWebSocketMessage(msgCtx, wsMsg); errMsg != "" {
+				_ = writeJSON(errorEventPayload(wsMsg.SessionID, errMsg))
+				continue
+			}
 
 			switch wsMsg.Type {
 			case "chat", "follow_up":
@@ -126,12 +144,12 @@ func (rt *Runtime) WebSocketHandlerWithState(state *appstate.State) gin.HandlerF
 					_ = writeJSON(errorEventPayload("", "session_id is required"))
 					continue
 				}
-				if !rt.Go(func() { rt.handleChatMessage(sm, wsMsg, writeJSON, state) }) {
+				if !rt.Go(func() { rt.handleChatMessage(msgCtx, sm, wsMsg, writeJSON, state) }) {
 					_ = writeJSON(errorEventPayload(wsMsg.SessionID, "HTTP runtime is shutting down"))
 				}
 
 			case "steer", "steering":
-				rt.handleSteeringMessage(wsMsg, writeJSON)
+				rt.handleSteeringMessage(msgCtx, wsMsg, writeJSON)
 
 			case "resume":
 				sid := wsMsg.SessionID
@@ -207,6 +225,25 @@ func (rt *Runtime) WebSocketHandlerWithState(state *appstate.State) gin.HandlerF
 	}
 }
 
+// authorizeWebSocketMessage 校验消息涉及的会话归属当前账号，只读账号只能恢复订阅。
+// 返回空字符串表示放行。
+func (rt *Runtime) authorizeWebSocketMessage(ctx context.Context, wsMsg WSMessage) string {
+	principal, ok := domainaccount.PrincipalFromContext(ctx)
+	if !ok || wsMsg.Type == "ping" {
+		return ""
+	}
+	if wsMsg.Type != "resume" && !principal.Role.CanWrite() {
+		return "当前账号只有查看权限"
+	}
+	if wsMsg.SessionID == "" || !validateSessionID(wsMsg.SessionID) {
+		return ""
+	}
+	if err := rt.SessionService.Authorize(ctx, wsMsg.SessionID); err != nil {
+		return "session not found"
+	}
+	return ""
+}
+
 // --- WebSocket HITL 中断处理器 ---
 
 // buildInterruptHandler 构建 WebSocket 聊天的 HITL 中断处理器
@@ -249,7 +286,8 @@ func buildInterruptHandler(recorder *eventlog.HistoryRecorder, sessionID string,
 			TurnID:  turnID,
 			Content: msg,
 			Approval: &events.ApprovalPayload{
-				Message: msg,
+				Message:  msg,
+				Findings: extractApprovalFindings(interrupts),
 			},
 		})
 		recorder.RecordEvent(approvalEvent)
@@ -292,23 +330,33 @@ func wsEventCallbackBuffered(sessionID string, stream *taskstream.Stream, resolv
 // --- WebSocket 聊天处理 ---
 
 // handleChatMessage 处理 WebSocket 聊天消息
-func (rt *Runtime) handleChatMessage(sm *sessionManager, wsMsg WSMessage, writeJSON func(any) error, state *appstate.State) {
+// ctx 只携带请求身份，任务生命周期独立于连接。
+func (rt *Runtime) handleChatMessage(ctx context.Context, sm *sessionManager, wsMsg WSMessage, writeJSON func(any) error, state *appstate.State) {
 	sessionID := wsMsg.SessionID
-	mode := wsMsg.Mode
-	if mode == "" {
-		mode = "team"
-	}
 	if wsMsg.Message == "" && len(wsMsg.Contents) == 0 {
 		_ = writeJSON(errorEventPayload(sessionID, "message or contents is required"))
 		return
 	}
+	wsMsg.Contents = scopeFileContents(ctx, wsMsg.Contents)
 	if !validateSessionID(sessionID) {
 		_ = writeJSON(errorEventPayload(sessionID, "invalid session ID"))
 		return
 	}
+	proj, err := rt.resolveSessionProject(ctx, sessionID, wsMsg.Project)
+	if err != nil {
+		_ = writeJSON(errorEventPayload(sessionID, err.Error()))
+		return
+	}
+	mode, agentName := applyProjectDefaults(proj, wsMsg.Mode, wsMsg.AgentName)
 	unlockSession := rt.lockSessionOperation(sessionID)
 	defer unlockSession()
 
+	if wsMsg.RewindTo != "" {
+		if _, err := rt.rewindSessionLocked(ctx, sessionID, wsMsg.RewindTo); err != nil {
+			_ = writeJSON(errorEventPayload(sessionID, err.Error()))
+			return
+		}
+	}
 	if existing := rt.Streams.Get(sessionID); existing != nil && existing.Status() == "processing" {
 		if _, queueErr := rt.enqueueTaskMessage(existing, sessionID, taskstream.QueueFollowUp, wsMsg.Message, wsMsg.Contents); queueErr == nil {
 			return
@@ -319,9 +367,15 @@ func (rt *Runtime) handleChatMessage(sm *sessionManager, wsMsg WSMessage, writeJ
 	}
 
 	// 任务 context 独立于连接——断连不会自动取消任务
-	taskCtx, taskCancel := context.WithCancel(appstate.WithState(context.Background(), state))
+	taskCtx, taskCancel := context.WithCancel(appstate.WithState(ctx, state))
 	defer taskCancel()
 	taskCtx = rt.withExecutionDependencies(taskCtx)
+	taskCtx = appusage.WithScope(taskCtx, appusage.Scope{Channel: appusage.ChannelWeb})
+	taskCtx, err = withProjectScope(taskCtx, proj)
+	if err != nil {
+		_ = writeJSON(errorEventPayload(sessionID, err.Error()))
+		return
+	}
 
 	// 注册到统一 TaskStream（支持断线重连 + Push/Pull 消费）
 	stream, created := rt.Streams.RegisterIfIdle(taskstream.StreamConfig{
@@ -329,7 +383,7 @@ func (rt *Runtime) handleChatMessage(sm *sessionManager, wsMsg WSMessage, writeJ
 		Cancel:     taskCancel,
 		CleanupTTL: 5 * time.Minute,
 		Mode:       mode,
-		AgentName:  wsMsg.AgentName,
+		AgentName:  agentName,
 	})
 	if !created {
 		if _, queueErr := rt.enqueueTaskMessage(stream, sessionID, taskstream.QueueFollowUp, wsMsg.Message, wsMsg.Contents); queueErr != nil {
@@ -352,7 +406,7 @@ func (rt *Runtime) handleChatMessage(sm *sessionManager, wsMsg WSMessage, writeJ
 	defer sm.removeTask(sessionID, taskID)
 
 	// 获取 runner
-	r, err := rt.resolveRunner(taskCtx, mode, wsMsg.AgentName)
+	r, err := rt.resolveRunner(taskCtx, mode, agentName)
 	if err != nil {
 		log.Printf("failed to resolve runner: session=%s, err=%v", sessionID, err)
 		stream.SetStatus("error")
@@ -364,7 +418,8 @@ func (rt *Runtime) handleChatMessage(sm *sessionManager, wsMsg WSMessage, writeJ
 	recorder, releaseRecorder := rt.acquireRecorder(sessionID)
 	defer releaseRecorder()
 	manager := memoryFromState(state)
-	turnInput, userDisplayText := buildChatInput(recorder, wsMsg.Message, wsMsg.Contents, manager)
+	memoryScope := chatMemoryScope(taskCtx, mode, agentName)
+	turnInput, userDisplayText := buildChatInput(recorder, wsMsg.Message, wsMsg.Contents, manager, memoryScope)
 	currentRunID := newTurnRunID(sessionID)
 	currentTurnID := turnIDForRun(currentRunID)
 	stream.SetTurn(currentRunID, currentTurnID)
@@ -379,7 +434,7 @@ func (rt *Runtime) handleChatMessage(sm *sessionManager, wsMsg WSMessage, writeJ
 	})
 	currentInput := turnInput
 	currentDisplayText := userDisplayText
-	rt.updateSessionExecutionMetadata(sessionID, currentDisplayText, mode, wsMsg.AgentName)
+	rt.updateSessionExecutionMetadata(taskCtx, sessionID, currentDisplayText, mode, agentName)
 	stream.Publish(standardMessageEventPayload(sessionID, currentRunID, currentTurnID, "开始处理您的请求..."))
 
 	chatService := appchat.NewService()
@@ -392,9 +447,11 @@ func (rt *Runtime) handleChatMessage(sm *sessionManager, wsMsg WSMessage, writeJ
 			Summary:          recorder,
 			InterruptHandler: runtimeport.InterruptHandler(interruptHandler),
 			NonInteractive:   true,
-			ApprovalRegistry: configuredApprovalRegistry(),
+			ApprovalRegistry: configuredApprovalRegistry(taskCtx),
 			AskHandler:       buildMemberAskRuntimeHandler(stream, recorder, sessionID),
 			SteeringSource:   steeringSource,
+			Memory:           manager,
+			MemoryScope:      memoryScope,
 			EventSink: func(event events.Event) error {
 				if stream.Status() == "cancelled" {
 					return nil
@@ -426,9 +483,9 @@ func (rt *Runtime) handleChatMessage(sm *sessionManager, wsMsg WSMessage, writeJ
 			publishQueueUpdated(stream, sessionID)
 			rt.persistQueueSnapshot(sessionID, stream)
 			currentDisplayText = queued.DisplayText
-			currentInput = buildQueuedChatInput(recorder, queued, manager)
+			currentInput = buildQueuedChatInput(recorder, queued, manager, memoryScope)
 			currentRunID = queuedTurnRunID(sessionID, queued)
-			rt.updateSessionExecutionMetadata(sessionID, currentDisplayText, mode, wsMsg.AgentName)
+			rt.updateSessionExecutionMetadata(taskCtx, sessionID, currentDisplayText, mode, agentName)
 			publishQueuedExecutionStart(stream, sessionID, queued, currentRunID)
 			continue
 		}
@@ -440,12 +497,12 @@ func (rt *Runtime) handleChatMessage(sm *sessionManager, wsMsg WSMessage, writeJ
 			return
 		}
 		stream.Publish(processingEndEventPayload(sessionID, currentRunID, "处理完成"))
-		rt.finishChat(recorder, sessionID, currentDisplayText, manager)
+		rt.finishChat(recorder, sessionID, currentDisplayText, manager, memoryScope)
 		return
 	}
 }
 
-func (rt *Runtime) handleSteeringMessage(wsMsg WSMessage, writeJSON func(any) error) {
+func (rt *Runtime) handleSteeringMessage(ctx context.Context, wsMsg WSMessage, writeJSON func(any) error) {
 	sessionID := wsMsg.SessionID
 	if sessionID == "" {
 		_ = writeJSON(errorEventPayload("", "session_id is required"))
@@ -455,6 +512,7 @@ func (rt *Runtime) handleSteeringMessage(wsMsg WSMessage, writeJSON func(any) er
 		_ = writeJSON(errorEventPayload(sessionID, "message or contents is required"))
 		return
 	}
+	wsMsg.Contents = scopeFileContents(ctx, wsMsg.Contents)
 	stream := rt.Streams.Get(sessionID)
 	if stream == nil || stream.Status() != "processing" {
 		_ = writeJSON(errorEventPayload(sessionID, "no running task to steer"))
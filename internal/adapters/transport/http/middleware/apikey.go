@@ -2,53 +2,153 @@ package middleware
 
 import (
 	"crypto/hmac"
+	"fkteams/internal/adapters/transport/http/handler"
 	"fkteams/internal/app/config"
+	domainaccount "fkteams/internal/domain/account"
+	domainapikey "fkteams/internal/domain/apikey"
+	"fkteams/internal/domain/apperror"
 	"fkteams/internal/runtime/events"
+	"fkteams/internal/runtime/log"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// APIKeyAuth 校验 OpenAI 兼容 API 的访问密钥
+// APIKeyAuth 校验 OpenAI / Anthropic 兼容 API 的访问密钥。
+// 支持 Authorization: Bearer <key>（OpenAI）和 x-api-key: <key>（Anthropic）两种传递方式。
+// 配置文件 api_keys 中的密钥不受限制；受管密钥需要 openai 范围，并按密钥限流。
 func APIKeyAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		keys := config.Get().OpenAIAPI.APIKeys
-		if len(keys) == 0 {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		rawKey, ok := requestAPIKey(c)
+		if !ok {
+			abortInvalidAPIKey(c, "invalid API key")
+			return
+		}
+
+		key := []byte(rawKey)
+		// 使用常量时间比较防止时序攻击
+		for _, k := range config.Get().OpenAIAPI.APIKeys {
+			if hmac.Equal(key, []byte(k)) {
+				c.Next()
+				return
+			}
+		}
+
+		if !strings.HasPrefix(rawKey, domainapikey.Prefix) {
+			abortInvalidAPIKey(c, "invalid API key")
+			return
+		}
+		managed, err := handler.AuthenticateAPIKey(c.Request.Context(), rawKey, c.ClientIP())
+		if err != nil {
+			if !apperror.IsCode(err, apperror.CodeUnauthorized) {
+				log.Printf("api key authentication failed: %v", err)
+			}
+			abortInvalidAPIKey(c, "invalid or expired API key")
+			return
+		}
+		if !managed.HasScope(domainapikey.ScopeOpenAI) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
 				"error": gin.H{
-					"message": "API key not configured, please set [[openai_api]].api_keys in config.toml",
-					"type":    events.NotifyInvalidAPIKey,
+					"message": "API key does not have the openai scope",
+					"type":    "permission_error",
 				},
 			})
 			return
 		}
-
-		authHeader := c.GetHeader("Authorization")
-		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		if allowed, retryAfter := handler.AllowAPIKeyRequest(managed); !allowed {
+			setRetryAfter(c, retryAfter)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
 				"error": gin.H{
-					"message": "invalid API key",
-					"type":    events.NotifyInvalidAPIKey,
+					"message": "API key rate limit exceeded",
+					"type":    "rate_limit_error",
 				},
 			})
 			return
 		}
+		c.Request = c.Request.WithContext(domainapikey.WithKey(c.Request.Context(), managed))
+		c.Next()
+	}
+}
 
-		key := []byte(authHeader[7:])
-		// 使用常量时间比较防止时序攻击
-		for _, k := range keys {
-			if hmac.Equal(key, []byte(k)) {
-				c.Next()
-				return
-			}
+func abortInvalidAPIKey(c *gin.Context, message string) {
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"error": gin.H{
+			"message": message,
+			"type":    events.NotifyInvalidAPIKey,
+		},
+	})
+}
+
+func setRetryAfter(c *gin.Context, retryAfter time.Duration) {
+	seconds := int(retryAfter.Round(time.Second) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	c.Header("Retry-After", fmt.Sprintf("%d", seconds))
+}
+
+func requestAPIKey(c *gin.Context) (string, bool) {
+	if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		return authHeader[7:], true
+	}
+	if key := c.GetHeader("x-api-key"); key != "" {
+		return key, true
+	}
+	return "", false
+}
+
+// apiKeyAuth 使用受管 API 密钥认证 /api/fkteams 请求，只放行密钥范围覆盖的接口。
+// 认证通过时返回密钥代表的身份，否则已写入错误响应。
+func apiKeyAuth(c *gin.Context, rawKey string) (domainaccount.Principal, bool) {
+	path := c.Request.URL.Path
+	key, err := handler.AuthenticateAPIKey(c.Request.Context(), rawKey, c.ClientIP())
+	if err != nil {
+		if !apperror.IsCode(err, apperror.CodeUnauthorized) {
+			log.Printf("api key authentication failed: %v", err)
 		}
+		log.Printf("api key auth failed: ip=%s, path=%s", c.ClientIP(), path)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"code": 1, "message": "API Key 无效或已过期"})
+		return domainaccount.Principal{}, false
+	}
+	scope, ok := apiKeyRouteScope(c.Request.Method, path)
+	if !ok || (scope != "" && !key.HasScope(scope)) {
+		log.Printf("api key scope rejected: key=%s, method=%s, path=%s", key.ID, c.Request.Method, path)
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"code": 1, "message": "API Key 无权访问该接口"})
+		return domainaccount.Principal{}, false
+	}
+	principal, ok := handler.APIKeyPrincipal(c.Request.Context(), key)
+	if !ok {
+		log.Printf("api key owner unavailable: key=%s, owner=%s", key.ID, key.Owner)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"code": 1, "message": "API Key 所属账号不可用"})
+		return domainaccount.Principal{}, false
+	}
+	if allowed, retryAfter := handler.AllowAPIKeyRequest(key); !allowed {
+		setRetryAfter(c, retryAfter)
+		c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"code": 1, "error_code": string(apperror.CodeResourceLimit), "message": "API Key 请求过于频繁"})
+		return domainaccount.Principal{}, false
+	}
+	c.Request = c.Request.WithContext(domainapikey.WithKey(c.Request.Context(), key))
+	return principal, true
+}
 
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-			"error": gin.H{
-				"message": "invalid API key",
-				"type":    events.NotifyInvalidAPIKey,
-			},
-		})
+// apiKeyRouteScope 返回访问接口需要的密钥范围。空范围表示任何有效密钥都可以访问，
+// 第二个返回值为 false 表示接口不对 API 密钥开放，例如管理接口和 WebSocket。
+func apiKeyRouteScope(method, path string) (domainapikey.Scope, bool) {
+	read := method == http.MethodGet || method == http.MethodHead
+	switch {
+	case read && (path == "/api/fkteams/version" || path == "/api/fkteams/me" || path == "/api/fkteams/agents"):
+		return "", true
+	case method == http.MethodPost && path == "/api/fkteams/chat":
+		return domainapikey.ScopeChat, true
+	case strings.HasPrefix(path, "/api/fkteams/stream/"):
+		return domainapikey.ScopeChat, true
+	case read && (path == "/api/fkteams/sessions" || strings.HasPrefix(path, "/api/fkteams/sessions/")):
+		return domainapikey.ScopeSessionsRead, true
+	case path == "/api/fkteams/schedules" || strings.HasPrefix(path, "/api/fkteams/schedules/"):
+		return domainapikey.ScopeSchedules, true
 	}
+	return "", false
 }
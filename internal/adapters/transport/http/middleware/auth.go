@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"fkteams/internal/adapters/transport/http/handler"
+	domainaccount "fkteams/internal/domain/account"
+	domainapikey "fkteams/internal/domain/apikey"
 	"fkteams/internal/runtime/log"
 	"net/http"
 	"net/url"
@@ -33,6 +35,11 @@ func Auth() gin.HandlerFunc {
 			c.Next()
 			return
 		}
+		// 单点登录的跳转和回调在登录前发生，由 state、PKCE 和 ID Token 签名保护
+		if path == "/api/fkteams/login/options" || path == "/api/fkteams/oidc/login" || path == "/api/fkteams/oidc/callback" {
+			c.Next()
+			return
+		}
 
 		// 静态资源不需要验证（CSS/JS/字体等）
 		if strings.HasPrefix(path, "/assets/") {
@@ -67,25 +74,86 @@ func Auth() gin.HandlerFunc {
 			c.Next()
 			return
 		}
+		// 定时任务 webhook 触发器使用独立的触发器密钥认证
+		if c.Request.Method == "POST" && strings.HasPrefix(path, "/api/fkteams/triggers/") {
+			c.Next()
+			return
+		}
+
+		// 受管 API 密钥只能访问其范围覆盖的接口，身份为密钥的归属账号
+		var principal domainaccount.Principal
+		var ok bool
+		if rawKey, hasKey := requestAPIKey(c); hasKey && strings.HasPrefix(rawKey, domainapikey.Prefix) {
+			principal, ok = apiKeyAuth(c, rawKey)
+		} else {
+			principal, ok = tokenAuth(c)
+		}
+		if !ok {
+			return
+		}
 
-		token := handler.RequestAuthToken(c)
-		if token == "" || !handler.ValidateToken(token) {
-			log.Printf("auth failed: ip=%s, path=%s", c.ClientIP(), path)
-			// API 请求返回 401
-			if strings.HasPrefix(path, "/api/") || path == "/ws" {
-				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-					"code":    1,
-					"message": "未登录或登录已过期",
-				})
-				return
-			}
-			// 页面请求跳转到登录页，并保留原始地址
-			loginURL := "/login?next=" + url.QueryEscape(c.Request.URL.RequestURI())
-			c.Redirect(http.StatusFound, loginURL)
-			c.Abort()
+		// 只读账号不能发起修改类请求
+		if !principal.Role.CanWrite() && !readOnlyRequest(c.Request.Method, path) {
+			log.Printf("read-only user rejected: username=%s, method=%s, path=%s", principal.Username, c.Request.Method, path)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"code":    1,
+				"message": "当前账号只有查看权限",
+			})
 			return
 		}
 
+		c.Request = c.Request.WithContext(domainaccount.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// tokenAuth 使用登录令牌认证请求，失败时已写入 401 响应或跳转到登录页。
+func tokenAuth(c *gin.Context) (domainaccount.Principal, bool) {
+	path := c.Request.URL.Path
+	token := handler.RequestAuthToken(c)
+	principal, ok := handler.AuthenticateToken(token)
+	if token != "" && ok {
+		return principal, true
+	}
+	log.Printf("auth failed: ip=%s, path=%s", c.ClientIP(), path)
+	// API 请求返回 401
+	if strings.HasPrefix(path, "/api/") || path == "/ws" {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"code":    1,
+			"message": "未登录或登录已过期",
+		})
+		return domainaccount.Principal{}, false
+	}
+	// 页面请求跳转到登录页，并保留原始地址
+	loginURL := "/login?next=" + url.QueryEscape(c.Request.URL.RequestURI())
+	c.Redirect(http.StatusFound, loginURL)
+	c.Abort()
+	return domainaccount.Principal{}, false
+}
+
+// readOnlyRequest 判断请求是否不会修改数据。批量下载和模型列表查询使用 POST 传参，但不产生修改。
+func readOnlyRequest(method, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		return path == "/api/fkteams/files/download/batch" || path == "/api/fkteams/providers/models"
+	}
+	return false
+}
+
+// RequireAdmin 只允许管理员访问，用于账号管理、服务配置和进程控制等接口。
+// 未启用认证时请求中没有身份，不做限制。
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := handler.RequestPrincipal(c)
+		if ok && !principal.IsAdmin() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"code":    1,
+				"message": "需要管理员权限",
+			})
+			return
+		}
 		c.Next()
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"fkteams/internal/adapters/transport/http/handler"
 	"fkteams/internal/app/config"
 	"fkteams/internal/runtime/env"
 
@@ -112,14 +113,17 @@ func TestAPIKeyAuth(t *testing.T) {
 	router.GET("/v1/models", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
 
 	tests := []struct {
-		name   string
-		auth   string
-		status int
+		name    string
+		auth    string
+		xAPIKey string
+		status  int
 	}{
 		{name: "missing", status: http.StatusUnauthorized},
 		{name: "wrong scheme", auth: "Token sk-valid", status: http.StatusUnauthorized},
 		{name: "wrong key", auth: "Bearer sk-wrong", status: http.StatusUnauthorized},
 		{name: "valid key", auth: "Bearer sk-valid", status: http.StatusOK},
+		{name: "wrong x-api-key", xAPIKey: "sk-wrong", status: http.StatusUnauthorized},
+		{name: "valid x-api-key", xAPIKey: "sk-valid", status: http.StatusOK},
 	}
 
 	for _, tt := range tests {
@@ -128,6 +132,9 @@ func TestAPIKeyAuth(t *testing.T) {
 			if tt.auth != "" {
 				req.Header.Set("Authorization", tt.auth)
 			}
+			if tt.xAPIKey != "" {
+				req.Header.Set("x-api-key", tt.xAPIKey)
+			}
 			w := httptest.NewRecorder()
 			router.ServeHTTP(w, req)
 			if w.Code != tt.status {
@@ -160,6 +167,7 @@ func TestAuthRejectsAPIAndRedirectsPageToLogin(t *testing.T) {
 	router.GET("/api/fkteams/version", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
 	router.POST("/api/fkteams/logout", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
 	router.POST("/api/fkteams/preview/:linkId/auth", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+	router.POST("/api/fkteams/triggers/:id", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
 
 	pageTarget := "/chat/session-1?panel=details"
 	pageReq := httptest.NewRequest(http.MethodGet, pageTarget, nil)
@@ -193,6 +201,13 @@ func TestAuthRejectsAPIAndRedirectsPageToLogin(t *testing.T) {
 	if previewAuthResp.Code != http.StatusOK {
 		t.Fatalf("preview auth status = %d, want %d", previewAuthResp.Code, http.StatusOK)
 	}
+
+	triggerReq := httptest.NewRequest(http.MethodPost, "/api/fkteams/triggers/task-1", nil)
+	triggerResp := httptest.NewRecorder()
+	router.ServeHTTP(triggerResp, triggerReq)
+	if triggerResp.Code != http.StatusOK {
+		t.Fatalf("trigger status = %d, want %d", triggerResp.Code, http.StatusOK)
+	}
 }
 
 func TestAuthReadsHotReloadedConfig(t *testing.T) {
@@ -239,3 +254,189 @@ func testRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	return gin.New()
 }
+
+func TestAuthEnforcesAccountRoles(t *testing.T) {
+	t.Setenv(env.AppDir, t.TempDir())
+	if err := config.Save(&config.Config{Server: config.Server{Auth: config.ServerAuth{
+		Enabled:  true,
+		Username: "admin",
+		Password: "secret",
+		Secret:   "token-secret",
+	}}}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "ok") }
+	router := testRouter()
+	router.Use(Auth())
+	router.POST("/api/fkteams/login", handler.LoginHandler())
+	router.POST("/api/fkteams/users", RequireAdmin(), handler.CreateUserHandler())
+	router.GET("/api/fkteams/sessions", ok)
+	router.POST("/api/fkteams/sessions", ok)
+	router.POST("/api/fkteams/files/download/batch", ok)
+	router.POST("/api/fkteams/shutdown", RequireAdmin(), ok)
+
+	request := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	login := func(username, password string) string {
+		t.Helper()
+		resp := request(http.MethodPost, "/api/fkteams/login", "", `{"username":"`+username+`","password":"`+password+`"}`)
+		var got struct {
+			Data struct {
+				Token string `json:"token"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(resp.Body.Bytes(), &got); err != nil || got.Data.Token == "" {
+			t.Fatalf("login %s failed: %d %s", username, resp.Code, resp.Body.String())
+		}
+		return got.Data.Token
+	}
+
+	admin := login("admin", "secret")
+	for _, body := range []string{
+		`{"username":"vera","password":"viewer-pass","role":"viewer"}`,
+		`{"username":"mia","password":"member-pass","role":"member"}`,
+	} {
+		if resp := request(http.MethodPost, "/api/fkteams/users", admin, body); resp.Code != http.StatusCreated {
+			t.Fatalf("create user status = %d: %s", resp.Code, resp.Body.String())
+		}
+	}
+	viewer := login("vera", "viewer-pass")
+	member := login("mia", "member-pass")
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"viewer reads", http.MethodGet, "/api/fkteams/sessions", viewer, http.StatusOK},
+		{"viewer downloads", http.MethodPost, "/api/fkteams/files/download/batch", viewer, http.StatusOK},
+		{"viewer writes", http.MethodPost, "/api/fkteams/sessions", viewer, http.StatusForbidden},
+		{"member writes", http.MethodPost, "/api/fkteams/sessions", member, http.StatusOK},
+		{"member admin route", http.MethodPost, "/api/fkteams/shutdown", member, http.StatusForbidden},
+		{"member manages users", http.MethodPost, "/api/fkteams/users", member, http.StatusForbidden},
+		{"admin route", http.MethodPost, "/api/fkteams/shutdown", admin, http.StatusOK},
+	}
+	for _, tc := range cases {
+		if resp := request(tc.method, tc.path, tc.token, `{}`); resp.Code != tc.want {
+			t.Fatalf("%s: status = %d, want %d", tc.name, resp.Code, tc.want)
+		}
+	}
+}
+
+func TestAPIKeysAreScopedAcrossRouteGroups(t *testing.T) {
+	t.Setenv(env.AppDir, t.TempDir())
+	if err := config.Save(&config.Config{Server: config.Server{Auth: config.ServerAuth{
+		Enabled:  true,
+		Username: "admin",
+		Password: "secret",
+		Secret:   "token-secret",
+	}}}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+
+	whoami := func(c *gin.Context) {
+		principal, _ := handler.RequestPrincipal(c)
+		c.JSON(http.StatusOK, principal)
+	}
+	router := testRouter()
+	router.Use(Auth())
+	router.POST("/api/fkteams/login", handler.LoginHandler())
+	router.POST("/api/fkteams/api-keys", RequireAdmin(), handler.CreateAPIKeyHandler())
+	router.DELETE("/api/fkteams/api-keys/:id", RequireAdmin(), handler.DeleteAPIKeyHandler())
+	router.GET("/api/fkteams/sessions", whoami)
+	router.POST("/api/fkteams/chat", whoami)
+	router.GET("/api/fkteams/config", whoami)
+	router.GET("/v1/models", APIKeyAuth(), whoami)
+
+	request := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+	resp := request(http.MethodPost, "/api/fkteams/login", "", `{"username":"admin","password":"secret"}`)
+	var login struct {
+		Data struct {
+			Token string `json:"token"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &login); err != nil || login.Data.Token == "" {
+		t.Fatalf("login failed: %d %s", resp.Code, resp.Body.String())
+	}
+	createKey := func(body string) (string, string) {
+		t.Helper()
+		resp := request(http.MethodPost, "/api/fkteams/api-keys", login.Data.Token, body)
+		var created struct {
+			Data struct {
+				Key    string `json:"key"`
+				APIKey struct {
+					ID string `json:"id"`
+				} `json:"api_key"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(resp.Body.Bytes(), &created); err != nil || resp.Code != http.StatusCreated || created.Data.Key == "" {
+			t.Fatalf("create api key: %d %s", resp.Code, resp.Body.String())
+		}
+		return created.Data.Key, created.Data.APIKey.ID
+	}
+	reader, readerID := createKey(`{"name":"reader","scopes":["sessions:read"],"rate_limit":3}`)
+	proxy, _ := createKey(`{"name":"proxy","scopes":["openai"]}`)
+
+	resp = request(http.MethodGet, "/api/fkteams/sessions", reader, "")
+	if resp.Code != http.StatusOK || !strings.Contains(resp.Body.String(), `"username":"admin"`) || !strings.Contains(resp.Body.String(), `"role":"viewer"`) {
+		t.Fatalf("reader sessions: %d %s", resp.Code, resp.Body.String())
+	}
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"reader chats", http.MethodPost, "/api/fkteams/chat", reader, http.StatusForbidden},
+		{"reader reads config", http.MethodGet, "/api/fkteams/config", reader, http.StatusForbidden},
+		{"reader proxies", http.MethodGet, "/v1/models", reader, http.StatusForbidden},
+		{"reader creates keys", http.MethodPost, "/api/fkteams/api-keys", reader, http.StatusForbidden},
+		{"proxy proxies", http.MethodGet, "/v1/models", proxy, http.StatusOK},
+		{"proxy reads sessions", http.MethodGet, "/api/fkteams/sessions", proxy, http.StatusForbidden},
+		{"unknown key", http.MethodGet, "/api/fkteams/sessions", proxy + "0", http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		if resp := request(tc.method, tc.path, tc.token, `{}`); resp.Code != tc.want {
+			t.Fatalf("%s: status = %d, want %d: %s", tc.name, resp.Code, tc.want, resp.Body.String())
+		}
+	}
+
+	// 被拒绝的请求不计入限流，reader 还剩两次
+	for i := 0; i < 2; i++ {
+		if resp := request(http.MethodGet, "/api/fkteams/sessions", reader, ""); resp.Code != http.StatusOK {
+			t.Fatalf("reader request %d: status = %d", i+2, resp.Code)
+		}
+	}
+	resp = request(http.MethodGet, "/api/fkteams/sessions", reader, "")
+	if resp.Code != http.StatusTooManyRequests || resp.Header().Get("Retry-After") == "" {
+		t.Fatalf("rate limited status = %d, Retry-After = %q", resp.Code, resp.Header().Get("Retry-After"))
+	}
+
+	if resp := request(http.MethodDelete, "/api/fkteams/api-keys/"+readerID, login.Data.Token, ""); resp.Code != http.StatusOK {
+		t.Fatalf("revoke: %d %s", resp.Code, resp.Body.String())
+	}
+	if resp := request(http.MethodGet, "/api/fkteams/sessions", reader, ""); resp.Code != http.StatusUnauthorized {
+		t.Fatalf("revoked key status = %d, want 401", resp.Code)
+	}
+}
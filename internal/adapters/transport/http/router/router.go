@@ -47,6 +47,8 @@ func newEngine(_ bool) *gin.Engine {
 }
 
 func registerAPIRoutesWithRuntime(r *gin.Engine, _ bool, state *appstate.State, runtime *handler.Runtime) {
+	adminOnly := middleware.RequireAdmin()
+	sessionAccess := runtime.SessionAccess()
 	controlBody := middleware.MaxBodySize(controlBodyLimit)
 	smallJSONBody := middleware.MaxBodySize(smallJSONLimit)
 	standardJSONBody := middleware.MaxBodySize(standardJSONLimit)
@@ -63,15 +65,37 @@ func registerAPIRoutesWithRuntime(r *gin.Engine, _ bool, state *appstate.State,
 	// OpenAI 兼容 API（独立的 API Key 认证）
 	v1 := r.Group("/v1", middleware.APIKeyAuth())
 	{
-		v1.GET("/models", handler.OpenAIModelsHandler())
-		v1.POST("/chat/completions", chatBody, handler.OpenAIChatCompletionsHandler())
+		v1.GET("/models", runtime.OpenAIModelsHandler())
+		v1.POST("/chat/completions", chatBody, runtime.OpenAIChatCompletionsHandler())
+		v1.POST("/messages", chatBody, runtime.AnthropicMessagesHandler())
 	}
 
 	apiV1 := r.Group("/api/fkteams")
 	{
 		apiV1.POST("/login", controlBody, handler.LoginHandler())
 		apiV1.POST("/logout", controlBody, handler.LogoutHandler())
+		apiV1.GET("/login/options", handler.LoginOptionsHandler())
+		apiV1.GET("/oidc/login", handler.OIDCLoginHandler())
+		apiV1.GET("/oidc/callback", handler.OIDCCallbackHandler())
 		apiV1.GET("/version", handler.VersionHandler())
+		apiV1.GET("/me", handler.MeHandler())
+
+		// 账号管理 API（仅管理员）
+		users := apiV1.Group("/users", adminOnly)
+		{
+			users.GET("", handler.ListUsersHandler())
+			users.POST("", controlBody, handler.CreateUserHandler())
+			users.PATCH("/:username", controlBody, handler.UpdateUserHandler())
+			users.DELETE("/:username", controlBody, handler.DeleteUserHandler())
+		}
+
+		// API 密钥管理（仅管理员）
+		apiKeys := apiV1.Group("/api-keys", adminOnly)
+		{
+			apiKeys.GET("", handler.ListAPIKeysHandler())
+			apiKeys.POST("", controlBody, handler.CreateAPIKeyHandler())
+			apiKeys.DELETE("/:id", controlBody, handler.DeleteAPIKeyHandler())
+		}
 
 		// 智能体 API
 		apiV1.GET("/agents", runtime.GetAgentsHandler())
@@ -95,18 +119,19 @@ func registerAPIRoutesWithRuntime(r *gin.Engine, _ bool, state *appstate.State,
 		{
 			stream.POST("/start", chatBody, runtime.StreamStartHandlerWithState(state))
 			stream.POST("/steer", chatBody, runtime.StreamSteerHandler())
-			stream.GET("/queue/:sessionID", runtime.StreamQueueHandler())
-			stream.PATCH("/queue/:sessionID/:queueID", chatBody, runtime.StreamQueueUpdateHandler())
-			stream.DELETE("/queue/:sessionID/:queueID", controlBody, runtime.StreamQueueDeleteHandler())
-			stream.POST("/queue/:sessionID/:queueID/kind", controlBody, runtime.StreamQueueKindHandler())
-			stream.POST("/queue/:sessionID/:queueID/move", controlBody, runtime.StreamQueueMoveHandler())
-			stream.POST("/stop/:sessionID", controlBody, runtime.StreamStopHandler())
-			stream.GET("/subscribe/:sessionID", runtime.StreamSubscribeHandler())
-			stream.GET("/snapshot/:sessionID", runtime.StreamSnapshotHandler())
-			stream.GET("/status/:sessionID", runtime.StreamStatusHandler())
-			stream.GET("/events/:sessionID", runtime.StreamEventsHandler())
+			stream.GET("/queue/:sessionID", sessionAccess, runtime.StreamQueueHandler())
+			stream.PATCH("/queue/:sessionID/:queueID", sessionAccess, chatBody, runtime.StreamQueueUpdateHandler())
+			stream.DELETE("/queue/:sessionID/:queueID", sessionAccess, controlBody, runtime.StreamQueueDeleteHandler())
+			stream.POST("/queue/:sessionID/:queueID/kind", sessionAccess, controlBody, runtime.StreamQueueKindHandler())
+			stream.POST("/queue/:sessionID/:queueID/move", sessionAccess, controlBody, runtime.StreamQueueMoveHandler())
+			stream.POST("/stop/:sessionID", sessionAccess, controlBody, runtime.StreamStopHandler())
+			stream.GET("/subscribe/:sessionID", sessionAccess, runtime.StreamSubscribeHandler())
+			stream.GET("/snapshot/:sessionID", sessionAccess, runtime.StreamSnapshotHandler())
+			stream.GET("/status/:sessionID", sessionAccess, runtime.StreamStatusHandler())
+			stream.GET("/events/:sessionID", sessionAccess, runtime.StreamEventsHandler())
 			stream.POST("/approval", smallJSONBody, runtime.StreamApprovalHandler())
 			stream.POST("/ask-response", standardJSONBody, runtime.StreamAskResponseHandler())
+			stream.GET("/interrupts", runtime.StreamInterruptsHandler())
 		}
 
 		// 文件管理 API
@@ -156,14 +181,25 @@ func registerAPIRoutesWithRuntime(r *gin.Engine, _ bool, state *appstate.State,
 		{
 			sessions.GET("", runtime.ListSessionsHandler())
 			sessions.POST("", smallJSONBody, runtime.CreateSessionHandler())
-			sessions.GET("/:sessionID", runtime.GetSessionHandler())
-			sessions.PATCH("/:sessionID", smallJSONBody, runtime.UpdateSessionHandler())
-			sessions.DELETE("/:sessionID", controlBody, runtime.DeleteSessionHandler())
+			sessions.GET("/:sessionID", sessionAccess, runtime.GetSessionHandler())
+			sessions.PATCH("/:sessionID", sessionAccess, smallJSONBody, runtime.UpdateSessionHandler())
+			sessions.DELETE("/:sessionID", sessionAccess, controlBody, runtime.DeleteSessionHandler())
+			sessions.POST("/:sessionID/fork", sessionAccess, smallJSONBody, runtime.ForkSessionHandler())
+			sessions.POST("/:sessionID/rewind", sessionAccess, controlBody, runtime.RewindSessionHandler())
 			sessions.POST("/rename", smallJSONBody, runtime.RenameSessionHandler())
 			sessions.POST("/favorite", controlBody, runtime.FavoriteSessionHandler())
 			sessions.POST("/agent", smallJSONBody, runtime.UpdateSessionAgentHandler())
 		}
 
+		// 项目（具名工作区）管理 API
+		projects := apiV1.Group("/projects")
+		{
+			projects.GET("", handler.ListProjectsHandler())
+			projects.POST("", adminOnly, smallJSONBody, handler.CreateProjectHandler())
+			projects.POST("/current", adminOnly, controlBody, handler.UseProjectHandler())
+			projects.DELETE("/:name", adminOnly, controlBody, handler.DeleteProjectHandler())
+		}
+
 		// 定时任务管理 API
 		schedules := apiV1.Group("/schedules")
 		{
@@ -177,19 +213,37 @@ func registerAPIRoutesWithRuntime(r *gin.Engine, _ bool, state *appstate.State,
 			schedules.GET("/:id/history/:filename", runtime.GetTaskHistoryFileHandler())
 		}
 
+		// 定时任务 webhook 触发器，使用触发器密钥认证
+		apiV1.POST("/triggers/:id", standardJSONBody, runtime.FireTriggerHandler())
+
+		// 用量统计 API
+		usage := apiV1.Group("/usage")
+		{
+			usage.GET("", runtime.UsageSummaryHandler())
+			usage.GET("/records", runtime.UsageRecordsHandler())
+			usage.GET("/budgets", runtime.UsageBudgetsHandler())
+		}
+
+		// 工具执行审计 API（仅管理员）
+		audit := apiV1.Group("/audit", adminOnly)
+		{
+			audit.GET("", runtime.AuditRecordsHandler())
+			audit.GET("/verify", runtime.AuditVerifyHandler())
+		}
+
 		// 技能管理 API
 		skills := apiV1.Group("/skills")
 		{
 			skills.GET("", handler.GetInstalledSkillsHandler())
-			skills.POST("", standardJSONBody, handler.CreateSkillHandler())
+			skills.POST("", adminOnly, standardJSONBody, handler.CreateSkillHandler())
 			skills.GET("/search", runtime.SearchSkillsHandler())
-			skills.POST("/install", smallJSONBody, runtime.InstallSkillHandler())
-			skills.DELETE("/:slug", controlBody, handler.RemoveSkillHandler())
+			skills.POST("/install", adminOnly, smallJSONBody, runtime.InstallSkillHandler())
+			skills.DELETE("/:slug", adminOnly, controlBody, handler.RemoveSkillHandler())
 			skills.GET("/:slug/files", handler.GetSkillFilesHandler())
-			skills.POST("/:slug/files", standardJSONBody, handler.CreateSkillFileHandler())
+			skills.POST("/:slug/files", adminOnly, standardJSONBody, handler.CreateSkillFileHandler())
 			skills.GET("/:slug/file", handler.GetSkillFileContentHandler())
-			skills.PUT("/:slug/file", standardJSONBody, handler.SaveSkillFileContentHandler())
-			skills.DELETE("/:slug/file", controlBody, handler.DeleteSkillFileHandler())
+			skills.PUT("/:slug/file", adminOnly, standardJSONBody, handler.SaveSkillFileContentHandler())
+			skills.DELETE("/:slug/file", adminOnly, controlBody, handler.DeleteSkillFileHandler())
 		}
 
 		// 长期记忆管理 API
@@ -197,14 +251,15 @@ func registerAPIRoutesWithRuntime(r *gin.Engine, _ bool, state *appstate.State,
 		{
 			memory.GET("", handler.GetMemoryListHandlerWithState(state))
 			memory.DELETE("", smallJSONBody, handler.DeleteMemoryHandlerWithState(state))
-			memory.POST("/clear", controlBody, handler.ClearMemoryHandlerWithState(state))
+			memory.POST("/move", smallJSONBody, handler.MoveMemoryHandlerWithState(state))
+			memory.POST("/clear", adminOnly, controlBody, handler.ClearMemoryHandlerWithState(state))
 		}
 
 		// 配置管理 API
 		configGroup := apiV1.Group("/config")
 		{
 			configGroup.GET("", handler.GetConfigHandler())
-			configGroup.PUT("", standardJSONBody, runtime.UpdateConfigHandlerWithState(state))
+			configGroup.PUT("", adminOnly, standardJSONBody, runtime.UpdateConfigHandlerWithState(state))
 			configGroup.GET("/tools", runtime.GetToolNamesHandler())
 			configGroup.GET("/tool-catalog", runtime.GetToolCatalogHandler())
 			configGroup.GET("/template-vars", handler.GetTemplateVarsHandler())
@@ -215,8 +270,8 @@ func registerAPIRoutesWithRuntime(r *gin.Engine, _ bool, state *appstate.State,
 		apiV1.POST("/providers/models", smallJSONBody, runtime.GetProviderModelsHandler())
 
 		// 系统管理 API
-		apiV1.POST("/shutdown", controlBody, handler.ShutdownHandler())
-		apiV1.POST("/restart", controlBody, handler.RestartHandler())
+		apiV1.POST("/shutdown", adminOnly, controlBody, handler.ShutdownHandler())
+		apiV1.POST("/restart", adminOnly, controlBody, handler.RestartHandler())
 	}
 }
 
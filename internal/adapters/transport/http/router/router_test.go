@@ -48,6 +48,11 @@ func TestRegisterAPIRoutesIncludesCoreEndpoints(t *testing.T) {
 		"PUT /api/fkteams/schedules/:id",
 		"DELETE /api/fkteams/schedules/:id",
 		"GET /api/fkteams/schedules/:id/history/:filename",
+		"POST /api/fkteams/triggers/:id",
+		"GET /api/fkteams/usage",
+		"GET /api/fkteams/usage/records",
+		"GET /api/fkteams/audit",
+		"GET /api/fkteams/audit/verify",
 		"POST /api/fkteams/skills",
 		"POST /api/fkteams/skills/:slug/files",
 		"GET /api/fkteams/skills/:slug/file",
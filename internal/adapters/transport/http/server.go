@@ -17,15 +17,19 @@ import (
 	agents "fkteams/internal/app/agent/catalog"
 	"fkteams/internal/app/agent/catalog/toolmeta"
 	"fkteams/internal/app/appstate"
+	appaudit "fkteams/internal/app/audit"
 	"fkteams/internal/app/config"
 	"fkteams/internal/app/lifecycle"
 	appschedule "fkteams/internal/app/schedule"
 	apptools "fkteams/internal/app/tools"
+	appusage "fkteams/internal/app/usage"
+	"fkteams/internal/app/userhooks"
 	"fkteams/internal/app/version"
 	bootstrapchannels "fkteams/internal/bootstrap/channels"
 	bootstrapservices "fkteams/internal/bootstrap/services"
 	bootstrapskills "fkteams/internal/bootstrap/skills"
 	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/hooks"
 	"fkteams/internal/runtime/log"
 	modelregistry "fkteams/internal/runtime/model"
 
@@ -90,6 +94,10 @@ func (s *httpService) Start(ctx context.Context) error {
 		ModelRegistry:  modelRegistry,
 		Providers:      providerRegistry,
 		SkillProviders: bootstrapskills.NewDefaultProviderRegistry(),
+		HookBus:        hooks.FromContext(ctx),
+		Usage:          appusage.FromContext(ctx),
+		Audit:          appaudit.FromContext(ctx),
+		UserHooks:      userhooks.FromContext(ctx),
 		ResetChannels:  s.resetChannels,
 	})
 	if s.scheduler != nil {
@@ -254,6 +262,9 @@ func run(ctx context.Context, mode serverMode, opts *ServeOptions) error {
 	} else if svc != nil {
 		httpSvc.resetChannels = svc.ResetRunners
 		app.RegisterService(svc)
+		if schedulerSvc != nil {
+			schedulerSvc.SetChannelManager(svc.Manager())
+		}
 	}
 
 	app.OnReady(func(ctx context.Context) error {
@@ -0,0 +1,316 @@
+// Package account 提供 Web/API 服务的账号管理和密码认证用例。
+package account
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	domainaccount "fkteams/internal/domain/account"
+	"fkteams/internal/domain/apperror"
+	storageport "fkteams/internal/ports/storage"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	minPasswordLength = 8
+	// bcrypt 只使用前 72 字节，更长的密码会被静默截断
+	maxPasswordBytes = 72
+	maxAccounts      = 1000
+)
+
+// Service 管理账号目录，所有修改立即持久化。
+type Service struct {
+	store storageport.AccountStore
+	now   func() time.Time
+	mu    sync.Mutex
+}
+
+// CreateRequest 描述新建账号。
+type CreateRequest struct {
+	Username string
+	Password string
+	Role     domainaccount.Role
+}
+
+// UpdateRequest 描述账号修改，nil 字段保持不变。
+type UpdateRequest struct {
+	Password *string
+	Role     *domainaccount.Role
+	Disabled *bool
+}
+
+// NewService 创建账号服务。
+func NewService(store storageport.AccountStore) *Service {
+	return &Service{store: store, now: time.Now}
+}
+
+func (s *Service) load(ctx context.Context) (domainaccount.Directory, error) {
+	if s == nil || s.store == nil {
+		return domainaccount.Directory{}, apperror.New(apperror.CodeUnavailable, "account service is not initialized")
+	}
+	directory, err := s.store.LoadAccounts(ctx)
+	if err != nil {
+		return directory, apperror.Wrap(apperror.CodeUnavailable, "account storage unavailable", err)
+	}
+	return directory, nil
+}
+
+func (s *Service) save(ctx context.Context, directory domainaccount.Directory) error {
+	if err := s.store.SaveAccounts(ctx, directory); err != nil {
+		return apperror.Wrap(apperror.CodeUnavailable, "account storage unavailable", err)
+	}
+	return nil
+}
+
+// List 返回按用户名排序的账号。
+func (s *Service) List(ctx context.Context) ([]domainaccount.User, error) {
+	directory, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users := slices.Clone(directory.Users)
+	slices.SortFunc(users, func(a, b domainaccount.User) int { return strings.Compare(a.Username, b.Username) })
+	return users, nil
+}
+
+// Get 返回指定账号。
+func (s *Service) Get(ctx context.Context, username string) (domainaccount.User, error) {
+	directory, err := s.load(ctx)
+	if err != nil {
+		return domainaccount.User{}, err
+	}
+	idx := indexOf(directory.Users, username)
+	if idx < 0 {
+		return domainaccount.User{}, apperror.Errorf(apperror.CodeNotFound, "user %q not found", username)
+	}
+	return directory.Users[idx], nil
+}
+
+// Authenticate 校验用户名和密码，账号不存在、已停用或密码错误时统一返回未认证错误。
+func (s *Service) Authenticate(ctx context.Context, username, password string) (domainaccount.User, error) {
+	user, err := s.Get(ctx, username)
+	if err != nil && !apperror.IsCode(err, apperror.CodeNotFound) {
+		return domainaccount.User{}, err
+	}
+	if err != nil || user.Disabled || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return domainaccount.User{}, apperror.New(apperror.CodeUnauthorized, "invalid username or password")
+	}
+	return user, nil
+}
+
+// Create 新建账号。reserved 是不能使用的用户名，例如配置文件中的内置管理员。
+func (s *Service) Create(ctx context.Context, req CreateRequest, reserved ...string) (domainaccount.User, error) {
+	username := strings.TrimSpace(req.Username)
+	if err := domainaccount.ValidateUsername(username); err != nil {
+		return domainaccount.User{}, apperror.Wrap(apperror.CodeInvalidArgument, err.Error(), err)
+	}
+	if !req.Role.Valid() {
+		return domainaccount.User{}, apperror.Errorf(apperror.CodeInvalidArgument, "invalid role %q", req.Role)
+	}
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return domainaccount.User{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	directory, err := s.load(ctx)
+	if err != nil {
+		return domainaccount.User{}, err
+	}
+	if indexOf(directory.Users, username) >= 0 || slices.Contains(reserved, username) {
+		return domainaccount.User{}, apperror.Errorf(apperror.CodeConflict, "user %q already exists", username)
+	}
+	if len(directory.Users) >= maxAccounts {
+		return domainaccount.User{}, apperror.New(apperror.CodeResourceLimit, "account limit reached")
+	}
+	now := s.now()
+	user := domainaccount.User{Username: username, PasswordHash: hash, Role: req.Role, CreatedAt: now, UpdatedAt: now}
+	directory.Users = append(directory.Users, user)
+	if err := s.save(ctx, directory); err != nil {
+		return domainaccount.User{}, err
+	}
+	return user, nil
+}
+
+// ExternalIdentity 描述单点登录校验通过的用户。
+type ExternalIdentity struct {
+	Provider string
+	Subject  string
+	Username string
+	Role     domainaccount.Role
+}
+
+// ProvisionExternal 为单点登录用户创建账号，已存在时按身份提供方的映射更新角色。
+// 同名的本地账号或其他身份提供方用户不会被接管；已停用的账号无法登录。
+func (s *Service) ProvisionExternal(ctx context.Context, identity ExternalIdentity, reserved ...string) (domainaccount.User, error) {
+	username := strings.TrimSpace(identity.Username)
+	if err := domainaccount.ValidateUsername(username); err != nil {
+		return domainaccount.User{}, apperror.Wrap(apperror.CodeInvalidArgument, err.Error(), err)
+	}
+	if identity.Provider == "" || identity.Subject == "" {
+		return domainaccount.User{}, apperror.New(apperror.CodeInvalidArgument, "external identity requires provider and subject")
+	}
+	if !identity.Role.Valid() {
+		return domainaccount.User{}, apperror.Errorf(apperror.CodeInvalidArgument, "invalid role %q", identity.Role)
+	}
+	if slices.Contains(reserved, username) {
+		return domainaccount.User{}, apperror.Errorf(apperror.CodeConflict, "user %q is reserved", username)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	directory, err := s.load(ctx)
+	if err != nil {
+		return domainaccount.User{}, err
+	}
+	now := s.now()
+	idx := indexOf(directory.Users, username)
+	if idx < 0 {
+		if len(directory.Users) >= maxAccounts {
+			return domainaccount.User{}, apperror.New(apperror.CodeResourceLimit, "account limit reached")
+		}
+		user := domainaccount.User{
+			Username:  username,
+			Role:      identity.Role,
+			Provider:  identity.Provider,
+			Subject:   identity.Subject,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		directory.Users = append(directory.Users, user)
+		if err := s.save(ctx, directory); err != nil {
+			return domainaccount.User{}, err
+		}
+		return user, nil
+	}
+
+	user := directory.Users[idx]
+	if user.Provider != identity.Provider || user.Subject != identity.Subject {
+		return domainaccount.User{}, apperror.Errorf(apperror.CodeConflict, "user %q belongs to another identity", username)
+	}
+	if user.Disabled {
+		return domainaccount.User{}, apperror.Errorf(apperror.CodeForbidden, "user %q is disabled", username)
+	}
+	if user.Role == identity.Role {
+		return user, nil
+	}
+	user.Role = identity.Role
+	user.UpdatedAt = now
+	directory.Users[idx] = user
+	if err := s.save(ctx, directory); err != nil {
+		return domainaccount.User{}, err
+	}
+	return user, nil
+}
+
+// Update 修改账号的密码、角色或停用状态。修改密码会使该账号已签发的登录令牌失效。
+func (s *Service) Update(ctx context.Context, username string, req UpdateRequest) (domainaccount.User, error) {
+	if req.Password == nil && req.Role == nil && req.Disabled == nil {
+		return domainaccount.User{}, apperror.New(apperror.CodeInvalidArgument, "at least one user field is required")
+	}
+	if req.Role != nil && !req.Role.Valid() {
+		return domainaccount.User{}, apperror.Errorf(apperror.CodeInvalidArgument, "invalid role %q", *req.Role)
+	}
+	var hash string
+	if req.Password != nil {
+		var err error
+		if hash, err = hashPassword(*req.Password); err != nil {
+			return domainaccount.User{}, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	directory, err := s.load(ctx)
+	if err != nil {
+		return domainaccount.User{}, err
+	}
+	idx := indexOf(directory.Users, username)
+	if idx < 0 {
+		return domainaccount.User{}, apperror.Errorf(apperror.CodeNotFound, "user %q not found", username)
+	}
+	user := directory.Users[idx]
+	if hash != "" {
+		if user.External() {
+			return domainaccount.User{}, apperror.Errorf(apperror.CodeInvalidArgument, "user %q signs in with single sign-on and has no password", username)
+		}
+		user.PasswordHash = hash
+	}
+	if req.Role != nil {
+		user.Role = *req.Role
+	}
+	if req.Disabled != nil {
+		user.Disabled = *req.Disabled
+	}
+	user.UpdatedAt = s.now()
+	directory.Users[idx] = user
+	if err := s.save(ctx, directory); err != nil {
+		return domainaccount.User{}, err
+	}
+	return user, nil
+}
+
+// Delete 删除账号，该账号的数据保留，之后只有管理员可以访问。
+func (s *Service) Delete(ctx context.Context, username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	directory, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	idx := indexOf(directory.Users, username)
+	if idx < 0 {
+		return apperror.Errorf(apperror.CodeNotFound, "user %q not found", username)
+	}
+	directory.Users = slices.Delete(directory.Users, idx, idx+1)
+	return s.save(ctx, directory)
+}
+
+// LegacyOwner 返回已完成单用户数据迁移的账号，未迁移时为空。
+func (s *Service) LegacyOwner(ctx context.Context) (string, error) {
+	directory, err := s.load(ctx)
+	if err != nil {
+		return "", err
+	}
+	return directory.LegacyOwner, nil
+}
+
+// MarkMigrated 记录单用户数据已归属到 owner，之后不再重复迁移。
+func (s *Service) MarkMigrated(ctx context.Context, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	directory, err := s.load(ctx)
+	if err != nil {
+		return err
+	}
+	directory.LegacyOwner = owner
+	return s.save(ctx, directory)
+}
+
+func indexOf(users []domainaccount.User, username string) int {
+	for i := range users {
+		if users[i].Username == username {
+			return i
+		}
+	}
+	return -1
+}
+
+func hashPassword(password string) (string, error) {
+	if len(password) < minPasswordLength {
+		return "", apperror.Errorf(apperror.CodeInvalidArgument, "password must be at least %d characters", minPasswordLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return "", apperror.Errorf(apperror.CodeInvalidArgument, "password must be at most %d bytes", maxPasswordBytes)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", apperror.Wrap(apperror.CodeInternal, "hash password", err)
+	}
+	return string(hash), nil
+}
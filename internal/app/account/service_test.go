@@ -0,0 +1,155 @@
+package account
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	domainaccount "fkteams/internal/domain/account"
+	"fkteams/internal/domain/apperror"
+)
+
+type memoryStore struct {
+	mu        sync.Mutex
+	directory domainaccount.Directory
+}
+
+func (s *memoryStore) LoadAccounts(_ context.Context) (domainaccount.Directory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	directory := s.directory
+	directory.Users = append([]domainaccount.User(nil), s.directory.Users...)
+	return directory, nil
+}
+
+func (s *memoryStore) SaveAccounts(_ context.Context, directory domainaccount.Directory) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.directory = directory
+	return nil
+}
+
+func TestServiceCreateAuthenticateAndUpdate(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(&memoryStore{})
+
+	user, err := service.Create(ctx, CreateRequest{Username: " alice ", Password: "correct horse", Role: domainaccount.RoleMember})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if user.Username != "alice" || user.PasswordHash == "" || user.PasswordHash == "correct horse" {
+		t.Fatalf("created user = %#v, want trimmed name and hashed password", user)
+	}
+	if _, err := service.Authenticate(ctx, "alice", "correct horse"); err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if _, err := service.Authenticate(ctx, "alice", "wrong password"); !apperror.IsCode(err, apperror.CodeUnauthorized) {
+		t.Fatalf("wrong password err = %v, want unauthorized", err)
+	}
+	if _, err := service.Authenticate(ctx, "nobody", "correct horse"); !apperror.IsCode(err, apperror.CodeUnauthorized) {
+		t.Fatalf("unknown user err = %v, want unauthorized", err)
+	}
+
+	disabled := true
+	role := domainaccount.RoleViewer
+	updated, err := service.Update(ctx, "alice", UpdateRequest{Role: &role, Disabled: &disabled})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Role != domainaccount.RoleViewer || !updated.Disabled || updated.PasswordHash != user.PasswordHash {
+		t.Fatalf("updated user = %#v", updated)
+	}
+	if _, err := service.Authenticate(ctx, "alice", "correct horse"); !apperror.IsCode(err, apperror.CodeUnauthorized) {
+		t.Fatalf("disabled user err = %v, want unauthorized", err)
+	}
+
+	password := "another secret"
+	updated, err = service.Update(ctx, "alice", UpdateRequest{Password: &password})
+	if err != nil || updated.PasswordHash == user.PasswordHash {
+		t.Fatalf("password update = %#v, %v", updated, err)
+	}
+}
+
+func TestServiceCreateValidatesInput(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(&memoryStore{})
+
+	cases := []struct {
+		name string
+		req  CreateRequest
+		code apperror.Code
+	}{
+		{name: "bad username", req: CreateRequest{Username: "a/b", Password: "long enough", Role: domainaccount.RoleMember}, code: apperror.CodeInvalidArgument},
+		{name: "short password", req: CreateRequest{Username: "bob", Password: "short", Role: domainaccount.RoleMember}, code: apperror.CodeInvalidArgument},
+		{name: "bad role", req: CreateRequest{Username: "bob", Password: "long enough", Role: "owner"}, code: apperror.CodeInvalidArgument},
+		{name: "reserved", req: CreateRequest{Username: "admin", Password: "long enough", Role: domainaccount.RoleMember}, code: apperror.CodeConflict},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := service.Create(ctx, tc.req, "admin"); !apperror.IsCode(err, tc.code) {
+				t.Fatalf("Create err = %v, want %s", err, tc.code)
+			}
+		})
+	}
+
+	if _, err := service.Create(ctx, CreateRequest{Username: "bob", Password: "long enough", Role: domainaccount.RoleViewer}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := service.Create(ctx, CreateRequest{Username: "bob", Password: "long enough", Role: domainaccount.RoleViewer}); !apperror.IsCode(err, apperror.CodeConflict) {
+		t.Fatalf("duplicate Create err = %v, want conflict", err)
+	}
+	if err := service.Delete(ctx, "bob"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := service.Delete(ctx, "bob"); !apperror.IsCode(err, apperror.CodeNotFound) {
+		t.Fatalf("second Delete err = %v, want not found", err)
+	}
+}
+
+func TestServiceProvisionExternal(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(&memoryStore{})
+	identity := ExternalIdentity{Provider: "oidc", Subject: "sub-1", Username: "carol", Role: domainaccount.RoleViewer}
+
+	user, err := service.ProvisionExternal(ctx, identity, "admin")
+	if err != nil {
+		t.Fatalf("ProvisionExternal create: %v", err)
+	}
+	if !user.External() || user.PasswordHash != "" || user.Role != domainaccount.RoleViewer {
+		t.Fatalf("provisioned user = %#v", user)
+	}
+	if _, err := service.Authenticate(ctx, "carol", ""); !apperror.IsCode(err, apperror.CodeUnauthorized) {
+		t.Fatalf("expected SSO user password login to fail, got %v", err)
+	}
+
+	identity.Role = domainaccount.RoleMember
+	user, err = service.ProvisionExternal(ctx, identity)
+	if err != nil || user.Role != domainaccount.RoleMember {
+		t.Fatalf("ProvisionExternal role update = %#v, %v", user, err)
+	}
+
+	if _, err := service.ProvisionExternal(ctx, ExternalIdentity{Provider: "oidc", Subject: "sub-2", Username: "carol", Role: domainaccount.RoleMember}); !apperror.IsCode(err, apperror.CodeConflict) {
+		t.Fatalf("expected other subject conflict, got %v", err)
+	}
+	if _, err := service.Create(ctx, CreateRequest{Username: "dave", Password: "local-password", Role: domainaccount.RoleMember}); err != nil {
+		t.Fatalf("Create local user: %v", err)
+	}
+	if _, err := service.ProvisionExternal(ctx, ExternalIdentity{Provider: "oidc", Subject: "sub-3", Username: "dave", Role: domainaccount.RoleAdmin}); !apperror.IsCode(err, apperror.CodeConflict) {
+		t.Fatalf("expected local account takeover to fail, got %v", err)
+	}
+	if _, err := service.ProvisionExternal(ctx, ExternalIdentity{Provider: "oidc", Subject: "sub-4", Username: "admin", Role: domainaccount.RoleAdmin}, "admin"); !apperror.IsCode(err, apperror.CodeConflict) {
+		t.Fatalf("expected reserved username conflict, got %v", err)
+	}
+
+	password := "new-password"
+	if _, err := service.Update(ctx, "carol", UpdateRequest{Password: &password}); !apperror.IsCode(err, apperror.CodeInvalidArgument) {
+		t.Fatalf("expected password update on SSO user to fail, got %v", err)
+	}
+	disabled := true
+	if _, err := service.Update(ctx, "carol", UpdateRequest{Disabled: &disabled}); err != nil {
+		t.Fatalf("disable SSO user: %v", err)
+	}
+	if _, err := service.ProvisionExternal(ctx, identity); !apperror.IsCode(err, apperror.CodeForbidden) {
+		t.Fatalf("expected disabled SSO user to be rejected, got %v", err)
+	}
+}
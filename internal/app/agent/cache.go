@@ -3,8 +3,11 @@ package agent
 import (
 	"context"
 	"fkteams/internal/app/agent/catalog"
+	"fkteams/internal/app/project"
 	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/checkpoint"
 	"fmt"
+	"strings"
 	"sync"
 )
 
@@ -12,8 +15,19 @@ const (
 	ModeTeam       = "team"
 	ModeRoundtable = "roundtable"
 	ModeDeep       = "deep"
+	// ModeWorkflowPrefix 是工作流模式的前缀，完整模式为 workflow:<名称>。
+	ModeWorkflowPrefix = "workflow:"
 )
 
+// WorkflowName 解析 workflow:<名称> 形式的模式，返回工作流名称。
+func WorkflowName(mode string) (string, bool) {
+	name, ok := strings.CutPrefix(mode, ModeWorkflowPrefix)
+	if !ok || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
 // Cache 负责按模式或智能体名称复用 Runner。
 type Cache struct {
 	mu    sync.RWMutex
@@ -85,8 +99,23 @@ func Resolve(ctx context.Context, mode, agentName string) (runtimeport.Runner, e
 }
 
 func resolveFactory(ctx context.Context, mode, agentName string, fallbackToTeam bool) (string, func() (runtimeport.Runner, error), error) {
+	key, create, err := resolveCreator(ctx, mode, agentName, fallbackToTeam)
+	if err != nil {
+		return "", nil, err
+	}
+	// 工具绑定在项目工作区上，不同项目的 Runner 不能复用。
+	if p, ok := project.FromContext(ctx); ok && p.Name != "" {
+		key += "@" + p.Name
+	}
+	return key, func() (runtimeport.Runner, error) {
+		// 不同 Runner 共享同一 checkpoint ID（会话 ID），按缓存键隔离避免互相覆盖。
+		return create(checkpoint.WithNamespace(ctx, key))
+	}, nil
+}
+
+func resolveCreator(ctx context.Context, mode, agentName string, fallbackToTeam bool) (string, func(context.Context) (runtimeport.Runner, error), error) {
 	if agentName != "" {
-		return agentCacheKey(agentName), func() (runtimeport.Runner, error) {
+		return agentCacheKey(agentName), func(ctx context.Context) (runtimeport.Runner, error) {
 			return createAgentRunnerByName(ctx, agentName)
 		}, nil
 	}
@@ -94,25 +123,22 @@ func resolveFactory(ctx context.Context, mode, agentName string, fallbackToTeam
 	if mode == "" {
 		mode = ModeTeam
 	}
+	if name, ok := WorkflowName(mode); ok {
+		return workflowCacheKey(name), func(ctx context.Context) (runtimeport.Runner, error) {
+			return CreateWorkflowRunner(ctx, name)
+		}, nil
+	}
 
 	switch mode {
 	case ModeRoundtable:
-		return mode, func() (runtimeport.Runner, error) {
-			return CreateLoopAgentRunner(ctx)
-		}, nil
+		return mode, CreateLoopAgentRunner, nil
 	case ModeDeep:
-		return mode, func() (runtimeport.Runner, error) {
-			return CreateDeepAgentsRunner(ctx)
-		}, nil
+		return mode, CreateDeepAgentsRunner, nil
 	case ModeTeam:
-		return mode, func() (runtimeport.Runner, error) {
-			return CreateTeamRunner(ctx)
-		}, nil
+		return mode, CreateTeamRunner, nil
 	default:
 		if fallbackToTeam {
-			return ModeTeam, func() (runtimeport.Runner, error) {
-				return CreateTeamRunner(ctx)
-			}, nil
+			return ModeTeam, CreateTeamRunner, nil
 		}
 		info, err := agents.AgentByName(ctx, mode)
 		if err != nil {
@@ -121,7 +147,7 @@ func resolveFactory(ctx context.Context, mode, agentName string, fallbackToTeam
 		if info == nil {
 			return "", nil, fmt.Errorf("unknown mode or agent: %s", mode)
 		}
-		return agentCacheKey(mode), func() (runtimeport.Runner, error) {
+		return agentCacheKey(mode), func(ctx context.Context) (runtimeport.Runner, error) {
 			agent, err := info.Creator(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("create agent %s: %w", mode, err)
@@ -149,3 +175,7 @@ func createAgentRunnerByName(ctx context.Context, agentName string) (runtimeport
 func agentCacheKey(agentName string) string {
 	return "agent_" + agentName
 }
+
+func workflowCacheKey(name string) string {
+	return "workflow_" + name
+}
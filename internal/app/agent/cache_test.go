@@ -188,3 +188,19 @@ func TestResolveFactoryExplicitAgentNameUsesAgentCacheKey(t *testing.T) {
 		t.Fatal("expected factory")
 	}
 }
+
+func TestResolveFactoryWorkflowModeUsesWorkflowCacheKey(t *testing.T) {
+	key, factory, err := resolveFactory(context.Background(), "workflow:report", "", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key != "workflow_report" {
+		t.Fatalf("unexpected workflow key: %q", key)
+	}
+	if factory == nil {
+		t.Fatal("expected factory")
+	}
+	if _, ok := WorkflowName("workflow:"); ok {
+		t.Fatal("empty workflow name should not parse")
+	}
+}
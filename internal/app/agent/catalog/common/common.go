@@ -20,14 +20,21 @@ const (
 	MaxRetries = retry.MaxRetries
 )
 
-// WorkspaceDir 返回工作目录
+// WorkspaceDir 返回默认工作目录；构建智能体时会按会话所属项目替换
 func WorkspaceDir() string {
 	return config.Get().WorkspaceDir()
 }
 
-// NewChatModel 使用配置文件的默认对话模型创建聊天模型
+// NewChatModel 使用配置文件的默认对话模型创建聊天模型，运行覆盖指定了模型时改用该模型
 func NewChatModel(ctx context.Context) (runtimeport.ChatModel, error) {
 	cfg := config.Get()
+	if id := RunOverridesFromContext(ctx).ModelID; id != "" {
+		modelCfg := cfg.ResolveModel(id)
+		if modelCfg == nil {
+			return nil, fmt.Errorf("model %q not found", id)
+		}
+		return NewChatModelWithModelConfig(ctx, modelCfg)
+	}
 	modelCfg := cfg.ResolveDefaultModel(config.ModelUseChat)
 	if modelCfg != nil && (modelCfg.APIKey != "" || modelCfg.Provider != "") {
 		return NewChatModelWithModelConfig(ctx, modelCfg)
@@ -35,15 +42,29 @@ func NewChatModel(ctx context.Context) (runtimeport.ChatModel, error) {
 	return nil, fmt.Errorf("未配置默认模型，请运行 fkteams generate config 生成配置文件")
 }
 
-// NewChatModelWithModelConfig 使用 ModelConfig 创建聊天模型
+// NewChatModelWithModelConfig 使用 ModelConfig 创建聊天模型，配置了 fallback 时返回故障转移组合模型
 func NewChatModelWithModelConfig(ctx context.Context, mc *config.ModelConfig) (runtimeport.ChatModel, error) {
-	return NewChatModelWithConfig(ctx, &modelregistry.Config{
+	cfg := modelRuntimeConfig(mc)
+	for _, id := range mc.Fallback {
+		fallback := config.Get().ResolveModel(id)
+		if fallback == nil {
+			return nil, fmt.Errorf("fallback model %q not found for model %s", id, mc.ID)
+		}
+		cfg.Fallback = append(cfg.Fallback, modelRuntimeConfig(fallback))
+	}
+	return NewChatModelWithConfig(ctx, cfg)
+}
+
+func modelRuntimeConfig(mc *config.ModelConfig) *modelregistry.Config {
+	return &modelregistry.Config{
+		ID:           mc.ID,
 		Provider:     modelregistry.Type(mc.Provider),
 		APIKey:       mc.APIKey,
 		BaseURL:      mc.BaseURL,
 		Model:        mc.Model,
 		ExtraHeaders: mc.ParseExtraHeaders(),
-	})
+		Cooldown:     mc.CooldownDuration(),
+	}
 }
 
 // NewChatModelWithConfig 使用指定配置创建聊天模型
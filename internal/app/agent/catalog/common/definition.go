@@ -3,11 +3,13 @@ package common
 import (
 	"context"
 	"fmt"
+	"maps"
 	"runtime"
 	"strconv"
 	"strings"
 
 	"fkteams/internal/app/appstate"
+	"fkteams/internal/app/project"
 	"fkteams/internal/app/tools"
 	runtimeport "fkteams/internal/ports/runtime"
 	"fkteams/internal/runtime/env"
@@ -84,7 +86,7 @@ func (r Resolver) Resolve(ctx context.Context, def Definition) (*ResolvedAgent,
 	}
 
 	coreModel := def.Model
-	if coreModel == nil {
+	if coreModel == nil || RunOverridesFromContext(ctx).ModelID != "" {
 		var err error
 		coreModel, err = NewChatModel(ctx)
 		if err != nil {
@@ -98,6 +100,12 @@ func (r Resolver) Resolve(ctx context.Context, def Definition) (*ResolvedAgent,
 		return nil, fmt.Errorf("decorate chat model: %w", err)
 	}
 
+	// 会话绑定了项目时，提示词中的工作目录替换为项目根目录。
+	if _, ok := def.TemplateVars["workspace_dir"]; ok {
+		vars := maps.Clone(def.TemplateVars)
+		vars["workspace_dir"] = project.WorkspaceDir(ctx)
+		def.TemplateVars = vars
+	}
 	instruction := renderInstruction(def.Instruction, def.TemplateVars)
 	cleaner := cleanerFromContext(ctx)
 
@@ -125,21 +133,26 @@ func (r Resolver) Resolve(ctx context.Context, def Definition) (*ResolvedAgent,
 }
 
 func (r Resolver) resolveTools(ctx context.Context, def Definition, cleaner *resources.Cleaner) ([]runtimeport.Tool, error) {
-	toolList := append([]runtimeport.Tool(nil), def.Tools...)
+	var capabilityTools []runtimeport.Tool
 	if profileIncludesWorkspace(def.Profile) {
 		builtinTools, err := tools.GetBuiltinCapabilityToolsWithCleaner(ctx, cleaner)
 		if err != nil {
 			return nil, err
 		}
-		toolList = append(toolList, builtinTools...)
+		capabilityTools = append(capabilityTools, builtinTools...)
 	}
 
 	namedTools, err := resolveNamedToolGroups(ctx, def.ToolNames, cleaner)
 	if err != nil {
 		return nil, err
 	}
-	toolList = append(toolList, namedTools...)
-	return toolList, nil
+	capabilityTools, err = FilterAllowedTools(ctx, append(capabilityTools, namedTools...))
+	if err != nil {
+		return nil, err
+	}
+	// 显式传入的工具（如团队成员）不受工具白名单约束。
+	toolList := append([]runtimeport.Tool(nil), def.Tools...)
+	return append(toolList, capabilityTools...), nil
 }
 
 func (r Resolver) resolveMiddlewares(ctx context.Context, def Definition, model runtimeport.ChatModel, pipelineRuntime runtimeport.PipelineRuntime, hasPipelineRuntime bool, cleaner *resources.Cleaner) ([]runtimeport.AgentMiddleware, error) {
@@ -228,7 +241,7 @@ func (a Assembler) Build(ctx context.Context, resolved *ResolvedAgent) (runtimep
 		ToolMiddlewares:    resolved.ToolMiddleware,
 		UnknownToolHandler: unknownToolsHandler,
 		ModelRetryConfig:   retry.NewModelRetryConfig(),
-		MaxIterations:      MaxIterations(),
+		MaxIterations:      MaxIterationsFor(ctx, 0),
 		EmitInternalEvents: true,
 		Middlewares:        resolved.Middlewares,
 	})
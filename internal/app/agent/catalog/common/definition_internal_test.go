@@ -105,6 +105,48 @@ func TestBareProfileBuildsStandaloneAgent(t *testing.T) {
 	}
 }
 
+func TestRunOverridesFilterCapabilityToolsAndIterations(t *testing.T) {
+	runtime := &minimalEngine{}
+	ctx := runtimeport.WithRuntime(context.Background(), runtime)
+	registry := apptools.NewToolGroupRegistry()
+	ctx = apptools.WithRegistry(ctx, registry)
+	const toolGroupName = "override_tools_test_group"
+	if err := registry.Register(apptools.ToolGroupRegistration{
+		Info: apptools.ToolGroupInfo{Name: toolGroupName, DisplayName: "Override Test", Description: "Override test tools", Category: "Test", Builtin: true},
+		Factory: func(apptools.ToolResolveContext) ([]runtimeport.Tool, error) {
+			return []runtimeport.Tool{registryTestTool{}}, nil
+		},
+	}); err != nil {
+		t.Fatalf("register test tool group: %v", err)
+	}
+	member, err := runtimeport.InferTool("member_tool", "member", func(context.Context, *struct{}) (string, error) {
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatalf("create tool: %v", err)
+	}
+	ctx = WithRunOverrides(ctx, RunOverrides{Tools: []string{"search"}, MaxIterations: 7})
+
+	if _, err := BuildAgent(ctx, Definition{
+		Name:      "override_test",
+		Profile:   ProfileBare,
+		Model:     testmodel.New(),
+		Tools:     []runtimeport.Tool{member},
+		ToolNames: []string{toolGroupName},
+	}); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if len(runtime.lastConfig.Tools) != 1 {
+		t.Fatalf("tools = %d, want only the explicit member tool", len(runtime.lastConfig.Tools))
+	}
+	if runtime.lastConfig.MaxIterations != 7 {
+		t.Fatalf("max iterations = %d, want 7", runtime.lastConfig.MaxIterations)
+	}
+	if got := MaxIterationsFor(context.Background(), 3); got != 3 {
+		t.Fatalf("configured max iterations = %d, want 3", got)
+	}
+}
+
 type minimalEngine struct {
 	lastConfig *runtimeport.ChatAgentConfig
 }
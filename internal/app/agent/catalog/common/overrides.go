@@ -0,0 +1,68 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	runtimeport "fkteams/internal/ports/runtime"
+)
+
+// RunOverrides 是单次运行对智能体构建参数的覆盖，零值字段表示沿用配置。
+type RunOverrides struct {
+	// ModelID 覆盖所有智能体使用的模型，包括配置了 model_id 的智能体。
+	ModelID string
+	// Tools 是工具白名单，只约束内置能力工具和按名称加载的工具组，不影响团队成员等显式传入的工具。
+	Tools []string
+	// MaxIterations 覆盖单个智能体的最大迭代次数。
+	MaxIterations int
+}
+
+type runOverridesKey struct{}
+
+// WithRunOverrides 将运行覆盖注入 context，供构建智能体时读取。
+func WithRunOverrides(ctx context.Context, overrides RunOverrides) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, runOverridesKey{}, overrides)
+}
+
+// RunOverridesFromContext 返回 context 中的运行覆盖，未设置时返回零值。
+func RunOverridesFromContext(ctx context.Context) RunOverrides {
+	if ctx == nil {
+		return RunOverrides{}
+	}
+	overrides, _ := ctx.Value(runOverridesKey{}).(RunOverrides)
+	return overrides
+}
+
+// MaxIterationsFor 返回本次运行的最大迭代次数：运行覆盖优先，其次为 configured，最后为全局默认值。
+func MaxIterationsFor(ctx context.Context, configured int) int {
+	if n := RunOverridesFromContext(ctx).MaxIterations; n > 0 {
+		return n
+	}
+	if configured > 0 {
+		return configured
+	}
+	return MaxIterations()
+}
+
+// FilterAllowedTools 按运行覆盖中的工具白名单过滤工具，未设置白名单时原样返回。
+func FilterAllowedTools(ctx context.Context, toolList []runtimeport.Tool) ([]runtimeport.Tool, error) {
+	allowed := RunOverridesFromContext(ctx).Tools
+	if len(allowed) == 0 {
+		return toolList, nil
+	}
+	filtered := make([]runtimeport.Tool, 0, len(toolList))
+	for _, tool := range toolList {
+		info, err := tool.Info(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get tool info: %w", err)
+		}
+		if slices.Contains(allowed, info.Name) {
+			filtered = append(filtered, tool)
+		}
+	}
+	return filtered, nil
+}
@@ -102,6 +102,15 @@ var coordinatorPrompt = `
 
 ---
 
+## 长期记忆
+
+- 对话结束后系统会自动提取记忆，不必把每个结论都手动保存。
+- 用户明确要求记住某件事，或确定了以后还会用到的方案、踩到值得记录的坑时，使用 memory_save 保存。
+- 做涉及既往约定的决定前，先用 memory_search 查阅已确定方案和避坑记录。
+- 发现记忆过时或错误时用 memory_update 修正；只有用户要求或记忆明显错误时才使用 memory_forget。
+
+---
+
 ## 输出风格
 
 - 以结果和行动为先，少解释过程。
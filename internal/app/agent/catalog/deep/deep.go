@@ -31,6 +31,10 @@ func NewAgent(ctx context.Context, subAgents []runtimeport.Agent) (runtimeport.A
 		}
 		toolList = append(toolList, baseTools...)
 	}
+	toolList, err = common.FilterAllowedTools(ctx, toolList)
+	if err != nil {
+		return nil, err
+	}
 	if err := toolpolicy.ClassifyTools(toolList); err != nil {
 		return nil, fmt.Errorf("classify tools: %w", err)
 	}
@@ -92,8 +96,9 @@ func NewAgent(ctx context.Context, subAgents []runtimeport.Agent) (runtimeport.A
 		ModelRetryConfig: retry.NewModelRetryConfig(),
 		SubAgents:        subAgents,
 		Tools:            toolList,
-		MaxIterations:    deepMaxIterations(deepCfg.MaxIterations),
+		MaxIterations:    common.MaxIterationsFor(ctx, deepCfg.MaxIterations),
 		Middlewares:      middlewares,
+		ToolMiddlewares:  pipelineRuntime.DefaultToolMiddlewares(),
 		Planning: runtimeport.DeepPlanningConfig{
 			Enabled: deepCfg.Planning.Enabled,
 		},
@@ -119,13 +124,6 @@ func NewAgent(ctx context.Context, subAgents []runtimeport.Agent) (runtimeport.A
 	})
 }
 
-func deepMaxIterations(configured int) int {
-	if configured > 0 {
-		return configured
-	}
-	return retry.MaxIterations()
-}
-
 func uniqueToolNames(values []string) []string {
 	seen := make(map[string]struct{}, len(values))
 	result := make([]string, 0, len(values))
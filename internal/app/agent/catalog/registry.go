@@ -13,7 +13,6 @@ import (
 	"fkteams/internal/app/config"
 	apptools "fkteams/internal/app/tools"
 	runtimeport "fkteams/internal/ports/runtime"
-	modelregistry "fkteams/internal/runtime/model"
 	"fmt"
 	"sync"
 )
@@ -394,6 +393,9 @@ func mergeAgentConfig(base, override config.AgentConfig) config.AgentConfig {
 		ssh := *override.SSH
 		base.SSH = &ssh
 	}
+	if override.Sandbox != "" {
+		base.Sandbox = override.Sandbox
+	}
 	return base
 }
 
@@ -448,17 +450,15 @@ func buildConfiguredDefinition(ctx context.Context, cfg *config.Config, def comm
 			},
 		})
 	}
+	if agentCfg.Sandbox != "" {
+		ctx = apptools.WithResolveContextPatch(ctx, apptools.ToolResolveContext{Sandbox: agentCfg.Sandbox})
+	}
 	if agentCfg.ModelID != "" {
 		modelCfg := cfg.ResolveModel(agentCfg.ModelID)
 		if modelCfg == nil {
 			return nil, fmt.Errorf("model_id %q not found for agent %s", agentCfg.ModelID, agentCfg.ID)
 		}
-		chatModel, err := common.NewChatModelWithConfig(ctx, &modelregistry.Config{
-			Provider: modelregistry.Type(modelCfg.Provider),
-			APIKey:   modelCfg.APIKey,
-			BaseURL:  modelCfg.BaseURL,
-			Model:    modelCfg.Model,
-		})
+		chatModel, err := common.NewChatModelWithModelConfig(ctx, modelCfg)
 		if err != nil {
 			return nil, fmt.Errorf("create chat model: %w", err)
 		}
@@ -516,6 +516,9 @@ func customAgentInfo(cfg *config.Config, agentCfg config.AgentConfig) AgentInfo
 					},
 				})
 			}
+			if agentCfg.Sandbox != "" {
+				ctx = apptools.WithResolveContextPatch(ctx, apptools.ToolResolveContext{Sandbox: agentCfg.Sandbox})
+			}
 			return custom.NewAgent(ctx, custom.Config{
 				Name:        agentID,
 				Description: agentCfg.Description,
@@ -4,11 +4,14 @@ package agent
 import (
 	"context"
 	"fkteams/internal/app/agent/catalog"
+	"fkteams/internal/app/agent/catalog/common"
 	"fkteams/internal/app/agent/catalog/deep"
 	"fkteams/internal/app/agent/catalog/discussant"
 	"fkteams/internal/app/agent/catalog/tasker"
 	"fkteams/internal/app/agent/catalog/toolmeta"
 	"fkteams/internal/app/config"
+	"fkteams/internal/app/workflow"
+	domainschedule "fkteams/internal/domain/schedule"
 	runtimeport "fkteams/internal/ports/runtime"
 	"fkteams/internal/runtime/checkpoint"
 	"fmt"
@@ -73,16 +76,21 @@ func agentDisplayNamesByName(ctx context.Context) map[string]string {
 	return result
 }
 
-// newRunner 用共享配置创建 Runner
+// newRunner 用共享配置创建 Runner。
+// 上下文注入了 checkpoint 存储时使用该存储，否则回退到进程内存储。
 func newRunner(ctx context.Context, agent runtimeport.Agent) (runtimeport.Runner, error) {
 	runtime, err := runtimeport.RequireRunnerRuntime(ctx)
 	if err != nil {
 		return nil, err
 	}
+	store, ok := checkpoint.StoreFromContext(ctx)
+	if !ok {
+		store = checkpoint.NewMemoryStore()
+	}
 	return runtime.NewRunner(ctx, runtimeport.RunnerConfig{
 		Agent:           agent,
 		EnableStreaming: true,
-		CheckpointStore: checkpoint.NewMemoryStore(),
+		CheckpointStore: store,
 	})
 }
 
@@ -95,6 +103,20 @@ func CreateBackgroundTaskRunner(ctx context.Context) (runtimeport.Runner, error)
 	return newRunner(ctx, agent)
 }
 
+// CreateScheduledTaskRunner 按定时任务的执行目标创建一次性 Runner，未指定智能体和模式时使用任务官。
+// 模型、工具白名单和最大迭代次数通过运行覆盖作用于目标中的所有智能体。
+func CreateScheduledTaskRunner(ctx context.Context, target domainschedule.Target) (runtimeport.Runner, error) {
+	ctx = common.WithRunOverrides(ctx, common.RunOverrides{
+		ModelID:       target.Model,
+		Tools:         target.Tools,
+		MaxIterations: target.MaxIterations,
+	})
+	if target.Agent == "" && target.Mode == "" {
+		return CreateBackgroundTaskRunner(ctx)
+	}
+	return Resolve(ctx, target.Mode, target.Agent)
+}
+
 // CreateAgentRunner 创建普通 ReACT 模式的 Runner
 func CreateAgentRunner(ctx context.Context, agent runtimeport.Agent) (runtimeport.Runner, error) {
 	return newRunner(ctx, agent)
@@ -163,6 +185,27 @@ func CreateLoopAgentRunner(ctx context.Context) (runtimeport.Runner, error) {
 	return newRunner(ctx, loopAgent)
 }
 
+// CreateWorkflowRunner 按名称加载工作流定义，为每个智能体节点创建独立 Runner 后组装工作流 Runner。
+func CreateWorkflowRunner(ctx context.Context, name string) (runtimeport.Runner, error) {
+	def, err := workflow.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	runners := make(map[string]runtimeport.Runner, len(def.Nodes))
+	for _, node := range def.Nodes {
+		if node.Type != workflow.NodeAgent {
+			continue
+		}
+		// 同一智能体可出现在多个节点，按节点隔离 checkpoint。
+		r, err := createAgentRunnerByName(checkpoint.WithNamespace(ctx, "node_"+node.ID), node.Agent)
+		if err != nil {
+			return nil, fmt.Errorf("workflow %s: node %s: %w", def.Name, node.ID, err)
+		}
+		runners[node.ID] = r
+	}
+	return workflow.NewRunner(def, runners)
+}
+
 // PrintLoopAgentsInfo 打印多智能体讨论模式的智能体信息
 func PrintLoopAgentsInfo(ctx context.Context) error {
 	teamConfig := config.Get()
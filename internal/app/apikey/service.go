@@ -0,0 +1,307 @@
+// Package apikey 提供具名 API 密钥的签发、认证、限流和使用记录用例。
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	domainapikey "fkteams/internal/domain/apikey"
+	"fkteams/internal/domain/apperror"
+	storageport "fkteams/internal/ports/storage"
+)
+
+const (
+	maxKeys       = 1000
+	maxNameLength = 64
+	maxListItems  = 100
+	hintLength    = len(domainapikey.Prefix) + 4
+	// lastUsedInterval 限制使用记录的落盘频率，避免每次请求都写文件
+	lastUsedInterval = time.Minute
+	rateWindow       = time.Minute
+)
+
+// Service 管理 API 密钥。每次调用都重新读取存储，命令行对密钥的修改无需重启服务即可生效。
+type Service struct {
+	store storageport.APIKeyStore
+	now   func() time.Time
+
+	mu      sync.Mutex
+	windows map[string]rateWindowState
+}
+
+type rateWindowState struct {
+	count     int
+	startedAt time.Time
+}
+
+// CreateRequest 描述新建密钥。
+type CreateRequest struct {
+	Name      string
+	Scopes    []domainapikey.Scope
+	Models    []string
+	Agents    []string
+	RateLimit int
+	Owner     string
+	// ExpiresAt 为 nil 时永不过期
+	ExpiresAt *time.Time
+}
+
+// NewService 创建密钥服务。
+func NewService(store storageport.APIKeyStore) *Service {
+	return &Service{store: store, now: time.Now, windows: make(map[string]rateWindowState)}
+}
+
+func (s *Service) load(ctx context.Context) (domainapikey.Store, error) {
+	if s == nil || s.store == nil {
+		return domainapikey.Store{}, apperror.New(apperror.CodeUnavailable, "api key service is not initialized")
+	}
+	store, err := s.store.LoadAPIKeys(ctx)
+	if err != nil {
+		return store, apperror.Wrap(apperror.CodeUnavailable, "api key storage unavailable", err)
+	}
+	return store, nil
+}
+
+func (s *Service) save(ctx context.Context, store domainapikey.Store) error {
+	if err := s.store.SaveAPIKeys(ctx, store); err != nil {
+		return apperror.Wrap(apperror.CodeUnavailable, "api key storage unavailable", err)
+	}
+	return nil
+}
+
+// List 返回按创建时间排序的密钥。
+func (s *Service) List(ctx context.Context) ([]domainapikey.Key, error) {
+	store, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys := slices.Clone(store.Keys)
+	slices.SortStableFunc(keys, func(a, b domainapikey.Key) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return keys, nil
+}
+
+// Create 签发新密钥，明文只在返回值中出现一次，存储中只保留哈希。
+func (s *Service) Create(ctx context.Context, req CreateRequest) (domainapikey.Key, string, error) {
+	key, err := s.normalize(req)
+	if err != nil {
+		return domainapikey.Key{}, "", err
+	}
+	secret, err := generateSecret()
+	if err != nil {
+		return domainapikey.Key{}, "", apperror.Wrap(apperror.CodeInternal, "generate api key", err)
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return domainapikey.Key{}, "", apperror.Wrap(apperror.CodeInternal, "generate api key id", err)
+	}
+	key.ID = id
+	key.Hint = secret[:hintLength]
+	key.Hash = hashSecret(secret)
+	key.CreatedAt = s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	store, err := s.load(ctx)
+	if err != nil {
+		return domainapikey.Key{}, "", err
+	}
+	if len(store.Keys) >= maxKeys {
+		return domainapikey.Key{}, "", apperror.New(apperror.CodeResourceLimit, "api key limit reached")
+	}
+	if slices.ContainsFunc(store.Keys, func(k domainapikey.Key) bool { return k.Name == key.Name }) {
+		return domainapikey.Key{}, "", apperror.Errorf(apperror.CodeConflict, "api key %q already exists", key.Name)
+	}
+	store.Keys = append(store.Keys, key)
+	if err := s.save(ctx, store); err != nil {
+		return domainapikey.Key{}, "", err
+	}
+	return key, secret, nil
+}
+
+func (s *Service) normalize(req CreateRequest) (domainapikey.Key, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+		return domainapikey.Key{}, apperror.Errorf(apperror.CodeInvalidArgument, "api key name must be 1-%d characters", maxNameLength)
+	}
+	if len(req.Scopes) == 0 {
+		return domainapikey.Key{}, apperror.New(apperror.CodeInvalidArgument, "at least one scope is required")
+	}
+	scopes := make([]domainapikey.Scope, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scope = domainapikey.Scope(strings.TrimSpace(string(scope)))
+		if !scope.Valid() {
+			return domainapikey.Key{}, apperror.Errorf(apperror.CodeInvalidArgument, "invalid scope %q", scope)
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if req.RateLimit < 0 {
+		return domainapikey.Key{}, apperror.New(apperror.CodeInvalidArgument, "rate limit must not be negative")
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(s.now()) {
+		return domainapikey.Key{}, apperror.New(apperror.CodeInvalidArgument, "expiry must be in the future")
+	}
+	models, err := cleanList("model", req.Models)
+	if err != nil {
+		return domainapikey.Key{}, err
+	}
+	agents, err := cleanList("agent", req.Agents)
+	if err != nil {
+		return domainapikey.Key{}, err
+	}
+	return domainapikey.Key{
+		Name:      name,
+		Scopes:    scopes,
+		Models:    models,
+		Agents:    agents,
+		RateLimit: req.RateLimit,
+		Owner:     strings.TrimSpace(req.Owner),
+		ExpiresAt: req.ExpiresAt,
+	}, nil
+}
+
+// Revoke 删除密钥，id 可以是密钥 ID 或名称。
+func (s *Service) Revoke(ctx context.Context, id string) (domainapikey.Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	store, err := s.load(ctx)
+	if err != nil {
+		return domainapikey.Key{}, err
+	}
+	idx := slices.IndexFunc(store.Keys, func(k domainapikey.Key) bool { return k.ID == id })
+	if idx < 0 {
+		idx = slices.IndexFunc(store.Keys, func(k domainapikey.Key) bool { return k.Name == id })
+	}
+	if idx < 0 {
+		return domainapikey.Key{}, apperror.Errorf(apperror.CodeNotFound, "api key %q not found", id)
+	}
+	key := store.Keys[idx]
+	store.Keys = slices.Delete(store.Keys, idx, idx+1)
+	if err := s.save(ctx, store); err != nil {
+		return domainapikey.Key{}, err
+	}
+	delete(s.windows, key.ID)
+	return key, nil
+}
+
+// Authenticate 校验明文密钥并记录使用时间和来源地址。未知或已过期的密钥统一返回未认证错误。
+func (s *Service) Authenticate(ctx context.Context, secret, ip string) (domainapikey.Key, error) {
+	if !strings.HasPrefix(secret, domainapikey.Prefix) {
+		return domainapikey.Key{}, apperror.New(apperror.CodeUnauthorized, "invalid api key")
+	}
+	hash := hashSecret(secret)
+	store, err := s.load(ctx)
+	if err != nil {
+		return domainapikey.Key{}, err
+	}
+	idx := -1
+	for i, key := range store.Keys {
+		if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hash)) == 1 {
+			idx = i
+		}
+	}
+	now := s.now()
+	if idx < 0 || store.Keys[idx].Expired(now) {
+		return domainapikey.Key{}, apperror.New(apperror.CodeUnauthorized, "invalid api key")
+	}
+	key := store.Keys[idx]
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedInterval || key.LastUsedIP != ip {
+		s.touch(ctx, key.ID, now, ip)
+		key.LastUsedAt = &now
+		key.LastUsedIP = ip
+	}
+	return key, nil
+}
+
+// touch 持久化使用记录。写入失败不影响本次认证。
+func (s *Service) touch(ctx context.Context, id string, now time.Time, ip string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	store, err := s.load(ctx)
+	if err != nil {
+		return
+	}
+	idx := slices.IndexFunc(store.Keys, func(k domainapikey.Key) bool { return k.ID == id })
+	if idx < 0 {
+		return
+	}
+	store.Keys[idx].LastUsedAt = &now
+	store.Keys[idx].LastUsedIP = ip
+	_ = s.save(ctx, store)
+}
+
+// Allow 按密钥的每分钟请求上限限流，超出时返回需要等待的时间。
+func (s *Service) Allow(key domainapikey.Key) (bool, time.Duration) {
+	if key.RateLimit <= 0 {
+		return true, 0
+	}
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.windows[key.ID]
+	if !ok || now.Sub(state.startedAt) >= rateWindow {
+		if len(s.windows) >= maxKeys {
+			s.pruneWindowsLocked(now)
+		}
+		s.windows[key.ID] = rateWindowState{count: 1, startedAt: now}
+		return true, 0
+	}
+	if state.count >= key.RateLimit {
+		return false, state.startedAt.Add(rateWindow).Sub(now)
+	}
+	state.count++
+	s.windows[key.ID] = state
+	return true, 0
+}
+
+func (s *Service) pruneWindowsLocked(now time.Time) {
+	for id, state := range s.windows {
+		if now.Sub(state.startedAt) >= rateWindow {
+			delete(s.windows, id)
+		}
+	}
+}
+
+func cleanList(kind string, values []string) ([]string, error) {
+	if len(values) > maxListItems {
+		return nil, apperror.Errorf(apperror.CodeInvalidArgument, "too many %ss, at most %d", kind, maxListItems)
+	}
+	var cleaned []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && !slices.Contains(cleaned, value) {
+			cleaned = append(cleaned, value)
+		}
+	}
+	return cleaned, nil
+}
+
+func generateSecret() (string, error) {
+	suffix, err := randomHex(24)
+	if err != nil {
+		return "", err
+	}
+	return domainapikey.Prefix + suffix, nil
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,139 @@
+package apikey
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	domainapikey "fkteams/internal/domain/apikey"
+	"fkteams/internal/domain/apperror"
+)
+
+type memoryStore struct {
+	mu    sync.Mutex
+	store domainapikey.Store
+	saves int
+}
+
+func (s *memoryStore) LoadAPIKeys(_ context.Context) (domainapikey.Store, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	store := s.store
+	store.Keys = append([]domainapikey.Key(nil), s.store.Keys...)
+	return store, nil
+}
+
+func (s *memoryStore) SaveAPIKeys(_ context.Context, store domainapikey.Store) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = store
+	s.saves++
+	return nil
+}
+
+func TestServiceCreateAuthenticateAndRevoke(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	service := NewService(store)
+	now := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	expires := now.Add(time.Hour)
+	key, secret, err := service.Create(ctx, CreateRequest{
+		Name:      " ci ",
+		Scopes:    []domainapikey.Scope{domainapikey.ScopeOpenAI, domainapikey.ScopeOpenAI},
+		Models:    []string{"gpt-4o", " "},
+		ExpiresAt: &expires,
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !strings.HasPrefix(secret, domainapikey.Prefix) || len(secret) != len(domainapikey.Prefix)+48 {
+		t.Fatalf("secret = %q", secret)
+	}
+	if key.Name != "ci" || len(key.Scopes) != 1 || len(key.Models) != 1 || !strings.HasPrefix(secret, key.Hint) {
+		t.Fatalf("created key = %#v", key)
+	}
+	if strings.Contains(store.store.Keys[0].Hash, secret) || store.store.Keys[0].Hash == "" {
+		t.Fatalf("stored hash = %q, want hash of secret", store.store.Keys[0].Hash)
+	}
+
+	authed, err := service.Authenticate(ctx, secret, "10.0.0.1")
+	if err != nil || authed.ID != key.ID {
+		t.Fatalf("Authenticate = %#v, %v", authed, err)
+	}
+	if last := store.store.Keys[0].LastUsedAt; last == nil || !last.Equal(now) || store.store.Keys[0].LastUsedIP != "10.0.0.1" {
+		t.Fatalf("last used = %v %q", last, store.store.Keys[0].LastUsedIP)
+	}
+	saves := store.saves
+	if _, err := service.Authenticate(ctx, secret, "10.0.0.1"); err != nil || store.saves != saves {
+		t.Fatalf("repeated use within a minute wrote storage again: saves %d -> %d, err %v", saves, store.saves, err)
+	}
+	if _, err := service.Authenticate(ctx, secret+"0", "10.0.0.1"); !apperror.IsCode(err, apperror.CodeUnauthorized) {
+		t.Fatalf("unknown key err = %v, want unauthorized", err)
+	}
+
+	now = expires
+	if _, err := service.Authenticate(ctx, secret, "10.0.0.1"); !apperror.IsCode(err, apperror.CodeUnauthorized) {
+		t.Fatalf("expired key err = %v, want unauthorized", err)
+	}
+
+	if _, err := service.Revoke(ctx, "ci"); err != nil {
+		t.Fatalf("Revoke by name: %v", err)
+	}
+	if _, err := service.Revoke(ctx, key.ID); !apperror.IsCode(err, apperror.CodeNotFound) {
+		t.Fatalf("second revoke err = %v, want not found", err)
+	}
+}
+
+func TestServiceCreateValidatesInput(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(&memoryStore{})
+	past := time.Now().Add(-time.Minute)
+	openai := []domainapikey.Scope{domainapikey.ScopeOpenAI}
+
+	cases := map[string]CreateRequest{
+		"empty name":     {Scopes: openai},
+		"no scopes":      {Name: "a"},
+		"unknown scope":  {Name: "a", Scopes: []domainapikey.Scope{"admin"}},
+		"negative limit": {Name: "a", Scopes: openai, RateLimit: -1},
+		"past expiry":    {Name: "a", Scopes: openai, ExpiresAt: &past},
+	}
+	for name, req := range cases {
+		if _, _, err := service.Create(ctx, req); !apperror.IsCode(err, apperror.CodeInvalidArgument) {
+			t.Errorf("%s: err = %v, want invalid argument", name, err)
+		}
+	}
+	if _, _, err := service.Create(ctx, CreateRequest{Name: "a", Scopes: openai}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, _, err := service.Create(ctx, CreateRequest{Name: "a", Scopes: openai}); !apperror.IsCode(err, apperror.CodeConflict) {
+		t.Fatalf("duplicate name err = %v, want conflict", err)
+	}
+}
+
+func TestServiceAllowLimitsRequestsPerMinute(t *testing.T) {
+	service := NewService(&memoryStore{})
+	now := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	key := domainapikey.Key{ID: "k1", RateLimit: 2}
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := service.Allow(key); !ok {
+			t.Fatalf("request %d rejected", i+1)
+		}
+	}
+	now = now.Add(20 * time.Second)
+	if ok, retry := service.Allow(key); ok || retry != 40*time.Second {
+		t.Fatalf("third request = %v, retry %v; want rejected with 40s", ok, retry)
+	}
+	now = now.Add(40 * time.Second)
+	if ok, _ := service.Allow(key); !ok {
+		t.Fatal("request after window rejected")
+	}
+	if ok, _ := service.Allow(domainapikey.Key{ID: "unlimited"}); !ok {
+		t.Fatal("unlimited key rejected")
+	}
+}
@@ -1,6 +1,8 @@
 package appdata
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"path/filepath"
 
@@ -34,6 +36,16 @@ func SchedulerDir() string {
 	return filepath.Join(Dir(), "scheduler")
 }
 
+// UsageDir 返回模型用量账本目录。
+func UsageDir() string {
+	return filepath.Join(Dir(), "usage")
+}
+
+// AuditFile 返回工具执行审计日志文件路径。
+func AuditFile() string {
+	return filepath.Join(Dir(), "audit", "audit.jsonl")
+}
+
 // ShareDir 返回文件分享链接持久化目录。
 func ShareDir() string {
 	return filepath.Join(Dir(), "share")
@@ -53,3 +65,42 @@ func SkillsDir() string {
 func ConfigFile() string {
 	return filepath.Join(Dir(), "config", "config.toml")
 }
+
+// PolicyFile 返回全局工具权限策略文件路径。
+func PolicyFile() string {
+	return filepath.Join(Dir(), "config", "policy.toml")
+}
+
+// ProjectPolicyFile 返回项目工具权限策略文件路径，按项目根目录的绝对路径区分，root 为空时对应默认工作区。
+// 策略保存在应用数据目录而不是项目目录中，智能体无法通过改写工作区文件放宽审批。
+func ProjectPolicyFile(root string) string {
+	if root == "" {
+		root = WorkspaceDir()
+	}
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	sum := sha256.Sum256([]byte(filepath.Clean(root)))
+	name := filepath.Base(root) + "-" + hex.EncodeToString(sum[:6]) + ".toml"
+	return filepath.Join(Dir(), "config", "policies", name)
+}
+
+// WorkflowsDir 返回工作流定义目录。
+func WorkflowsDir() string {
+	return filepath.Join(Dir(), "config", "workflows")
+}
+
+// UsersFile 返回 Web/API 服务账号文件路径。
+func UsersFile() string {
+	return filepath.Join(Dir(), "config", "users.json")
+}
+
+// APIKeysFile 返回受管 API 密钥文件路径。
+func APIKeysFile() string {
+	return filepath.Join(Dir(), "config", "apikeys.json")
+}
+
+// SecretsFile 返回加密密钥库文件路径。
+func SecretsFile() string {
+	return filepath.Join(Dir(), "config", "secrets.json")
+}
@@ -23,4 +23,18 @@ func TestDirectoryHelpersUseAppDir(t *testing.T) {
 	if got := ConfigFile(); got != filepath.Join(appDir, "config", "config.toml") {
 		t.Fatalf("ConfigFile = %q", got)
 	}
+	if got := PolicyFile(); got != filepath.Join(appDir, "config", "policy.toml") {
+		t.Fatalf("PolicyFile = %q", got)
+	}
+	policies := filepath.Join(appDir, "config", "policies")
+	project := ProjectPolicyFile("/src/api")
+	if filepath.Dir(project) != policies || !strings.HasPrefix(filepath.Base(project), "api-") {
+		t.Fatalf("ProjectPolicyFile = %q", project)
+	}
+	if ProjectPolicyFile("/src/api/") != project || ProjectPolicyFile("/other/api") == project {
+		t.Fatal("ProjectPolicyFile should be keyed by the cleaned project path")
+	}
+	if ProjectPolicyFile("") != ProjectPolicyFile(WorkspaceDir()) {
+		t.Fatal("empty root should use the default workspace policy")
+	}
 }
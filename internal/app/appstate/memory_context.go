@@ -0,0 +1,37 @@
+package appstate
+
+import (
+	"context"
+
+	domainmemory "fkteams/internal/domain/memory"
+)
+
+type memoryContextKey struct{}
+
+type memorySession struct {
+	manager MemoryManager
+	scope   domainmemory.ScopeContext
+}
+
+// WithMemory 把本轮对话使用的记忆管理器和作用域写入 context，供记忆工具读取。
+func WithMemory(ctx context.Context, manager MemoryManager, scope domainmemory.ScopeContext) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if manager == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, memoryContextKey{}, memorySession{manager: manager, scope: scope})
+}
+
+// MemoryFromContext 读取本轮对话的记忆管理器和作用域。
+// 未显式写入时回退到应用状态中的管理器，此时作用域为空，只能访问全局记忆。
+func MemoryFromContext(ctx context.Context) (MemoryManager, domainmemory.ScopeContext) {
+	if ctx == nil {
+		return nil, domainmemory.ScopeContext{}
+	}
+	if session, ok := ctx.Value(memoryContextKey{}).(memorySession); ok {
+		return session.manager, session.scope
+	}
+	return FromContext(ctx).Memory(), domainmemory.ScopeContext{}
+}
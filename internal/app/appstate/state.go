@@ -15,32 +15,44 @@ type contextKey struct{}
 type MemoryManager interface {
 	MemorySearcher
 	MemoryCatalog
+	MemoryEditor
 	MemoryExtractor
 	MemoryLifecycle
 }
 
 // MemorySearcher 提供模型上下文注入需要的记忆检索能力。
 type MemorySearcher interface {
-	Search(query string, topK int) []domainmemory.MemoryEntry
+	Search(query string, topK int, scope domainmemory.ScopeContext) []domainmemory.MemoryEntry
 }
 
 // MemoryCatalog 提供管理端需要的记忆维护能力。
 type MemoryCatalog interface {
 	List() []domainmemory.MemoryEntry
 	Delete(summary string) int
+	Move(summary string, scope domainmemory.Scope) (int, error)
 	Count() int
 	Clear()
 }
 
+// MemoryEditor 提供智能体主动读写记忆的能力。
+type MemoryEditor interface {
+	Get(id string, scope domainmemory.ScopeContext) (domainmemory.MemoryEntry, bool)
+	Find(query string, topK int, scope domainmemory.ScopeContext, filter domainmemory.SearchFilter) []domainmemory.MemoryEntry
+	Save(entry domainmemory.MemoryEntry) (domainmemory.MemoryEntry, domainmemory.SaveAction, error)
+	Update(id string, patch domainmemory.EntryPatch) (domainmemory.MemoryEntry, error)
+	Forget(id string) (domainmemory.MemoryEntry, error)
+}
+
 // MemoryExtractor 提供对话结束后的记忆提取能力。
 type MemoryExtractor interface {
-	ExtractAndStoreAsync(messages []domainmemory.Message, sessionID string) bool
-	FlushExtract(ctx context.Context, messages []domainmemory.Message, sessionID string)
+	ExtractAndStoreAsync(messages []domainmemory.Message, sessionID string, scope domainmemory.ScopeContext) bool
+	FlushExtract(ctx context.Context, messages []domainmemory.Message, sessionID string, scope domainmemory.ScopeContext)
 }
 
 // MemoryLifecycle 提供记忆服务生命周期能力。
 type MemoryLifecycle interface {
 	ResetLLM(llm memoryport.LLMClient)
+	ResetEmbedder(embedder memoryport.Embedder)
 	Wait(ctx context.Context) error
 }
 
@@ -42,12 +42,32 @@ func TestStateRunProcessCleanupExecutesAndClearsCallbacks(t *testing.T) {
 
 type fakeMemoryManager struct{}
 
-func (m *fakeMemoryManager) Search(string, int) []memory.MemoryEntry                { return nil }
-func (m *fakeMemoryManager) ExtractAndStoreAsync([]memory.Message, string) bool     { return true }
-func (m *fakeMemoryManager) FlushExtract(context.Context, []memory.Message, string) {}
-func (m *fakeMemoryManager) List() []memory.MemoryEntry                             { return nil }
-func (m *fakeMemoryManager) Delete(string) int                                      { return 0 }
-func (m *fakeMemoryManager) Count() int                                             { return 0 }
-func (m *fakeMemoryManager) Clear()                                                 {}
-func (m *fakeMemoryManager) ResetLLM(memory.LLMClient)                              {}
-func (m *fakeMemoryManager) Wait(context.Context) error                             { return nil }
+func (m *fakeMemoryManager) Search(string, int, memory.ScopeContext) []memory.MemoryEntry { return nil }
+func (m *fakeMemoryManager) ExtractAndStoreAsync([]memory.Message, string, memory.ScopeContext) bool {
+	return true
+}
+func (m *fakeMemoryManager) FlushExtract(context.Context, []memory.Message, string, memory.ScopeContext) {
+}
+func (m *fakeMemoryManager) List() []memory.MemoryEntry             { return nil }
+func (m *fakeMemoryManager) Delete(string) int                      { return 0 }
+func (m *fakeMemoryManager) Move(string, memory.Scope) (int, error) { return 0, nil }
+func (m *fakeMemoryManager) Get(string, memory.ScopeContext) (memory.MemoryEntry, bool) {
+	return memory.MemoryEntry{}, false
+}
+func (m *fakeMemoryManager) Find(string, int, memory.ScopeContext, memory.SearchFilter) []memory.MemoryEntry {
+	return nil
+}
+func (m *fakeMemoryManager) Save(memory.MemoryEntry) (memory.MemoryEntry, memory.SaveAction, error) {
+	return memory.MemoryEntry{}, memory.SaveAdded, nil
+}
+func (m *fakeMemoryManager) Update(string, memory.EntryPatch) (memory.MemoryEntry, error) {
+	return memory.MemoryEntry{}, nil
+}
+func (m *fakeMemoryManager) Forget(string) (memory.MemoryEntry, error) {
+	return memory.MemoryEntry{}, nil
+}
+func (m *fakeMemoryManager) Count() int                    { return 0 }
+func (m *fakeMemoryManager) Clear()                        {}
+func (m *fakeMemoryManager) ResetLLM(memory.LLMClient)     {}
+func (m *fakeMemoryManager) ResetEmbedder(memory.Embedder) {}
+func (m *fakeMemoryManager) Wait(context.Context) error    { return nil }
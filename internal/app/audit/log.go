@@ -0,0 +1,318 @@
+// Package audit 提供工具执行审计日志：记录谁、哪个智能体以什么参数调用了哪个工具，
+// 审批结果及其来源、结束状态和受影响路径，并校验哈希链是否被篡改。
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	appusage "fkteams/internal/app/usage"
+	domainaccount "fkteams/internal/domain/account"
+	"fkteams/internal/domain/apperror"
+	domainaudit "fkteams/internal/domain/audit"
+	"fkteams/internal/domain/session"
+	runtimeport "fkteams/internal/ports/runtime"
+	storageport "fkteams/internal/ports/storage"
+	"fkteams/internal/runtime/approval"
+	"fkteams/internal/runtime/hooks"
+	"fkteams/internal/runtime/secrets"
+)
+
+const (
+	recordHookName     = "audit.record"
+	requireKeyHookName = "audit.require_key"
+	// maxArgumentBytes 和 maxErrorBytes 限制单条记录中保存的原文长度，完整参数以哈希保留
+	maxArgumentBytes = 4 << 10
+	maxErrorBytes    = 1 << 10
+)
+
+// keySecretName 是密钥库中审计 HMAC 密钥的条目名。
+const keySecretName = "fkteams/audit-hmac-key"
+
+const keySize = 32
+
+// Log 是工具执行审计日志。
+type Log struct {
+	store storageport.AuditStore
+	key   storageport.AuditKeyFunc
+	now   func() time.Time
+}
+
+type logContextKey struct{}
+
+// NewLog 创建审计日志，key 返回存储计算哈希链所用的密钥，校验时使用同一密钥。
+func NewLog(store storageport.AuditStore, key storageport.AuditKeyFunc) *Log {
+	return &Log{store: store, key: key, now: time.Now}
+}
+
+// LoadVaultKey 从密钥库读取审计密钥。只读取，不会创建密钥库或生成密钥；
+// 密钥尚未生成时提示运行 fkteams audit init。
+func LoadVaultKey(vault *secrets.Vault) ([]byte, error) {
+	encoded, err := vault.Get(keySecretName)
+	if errors.Is(err, secrets.ErrNotFound) {
+		return nil, fmt.Errorf("audit key is not initialized, run `fkteams audit init`: %w", err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load audit key from secret vault: %w", err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(decoded) != keySize {
+		return nil, fmt.Errorf("secret %s is not a valid audit key", keySecretName)
+	}
+	return decoded, nil
+}
+
+// InitVaultKey 生成随机审计密钥写入密钥库，密钥库不存在时一并创建；已有密钥时保持不变。
+// 返回是否新生成了密钥。
+func InitVaultKey(vault *secrets.Vault) (bool, error) {
+	if _, err := LoadVaultKey(vault); err == nil {
+		return false, nil
+	} else if !errors.Is(err, secrets.ErrNotFound) {
+		return false, err
+	}
+	generated := make([]byte, keySize)
+	if _, err := rand.Read(generated); err != nil {
+		return false, err
+	}
+	if err := vault.Set(keySecretName, base64.StdEncoding.EncodeToString(generated)); err != nil {
+		return false, fmt.Errorf("save audit key to secret vault: %w", err)
+	}
+	return true, nil
+}
+
+// StaticKey 返回启动时解析好的审计密钥；解析失败时每次都返回同一错误。
+func StaticKey(key []byte, err error) storageport.AuditKeyFunc {
+	return func() ([]byte, error) {
+		if err != nil {
+			return nil, err
+		}
+		return key, nil
+	}
+}
+
+// WithLog 将审计日志注入上下文。
+func WithLog(ctx context.Context, log *Log) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if log == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, logContextKey{}, log)
+}
+
+// FromContext 从上下文读取审计日志。
+func FromContext(ctx context.Context) *Log {
+	if ctx == nil {
+		return nil
+	}
+	log, _ := ctx.Value(logContextKey{}).(*Log)
+	return log
+}
+
+func (l *Log) requireStore() (storageport.AuditStore, error) {
+	if l == nil || l.store == nil || l.key == nil {
+		return nil, apperror.New(apperror.CodeUnavailable, "audit log is not initialized")
+	}
+	return l.store, nil
+}
+
+// Ready 检查审计日志能否写入，审计密钥不可用时返回原因。
+func (l *Log) Ready() error {
+	if _, err := l.requireStore(); err != nil {
+		return err
+	}
+	if _, err := l.key(); err != nil {
+		return apperror.Wrap(apperror.CodeUnavailable, "audit key is unavailable", err)
+	}
+	return nil
+}
+
+// Install 在 hook 总线上注册审计，返回注销函数。审计密钥不可用时 before_tool_call 拒绝执行工具，
+// 记录写入失败时 after_tool_call 使工具调用失败，不会在没有审计记录的情况下继续执行。
+func (l *Log) Install(bus *hooks.Bus) func() {
+	if l == nil || bus == nil {
+		return func() {}
+	}
+	unregisterCheck := bus.RegisterFunc(requireKeyHookName, []hooks.HookPoint{hooks.HookBeforeToolCall}, l.requireKey, hooks.Options{ErrorPolicy: hooks.ErrorFail})
+	unregisterRecord := bus.RegisterFunc(recordHookName, []hooks.HookPoint{hooks.HookAfterToolCall}, l.handleToolCall, hooks.Options{ErrorPolicy: hooks.ErrorFail})
+	return func() {
+		unregisterRecord()
+		unregisterCheck()
+	}
+}
+
+// requireKey 在工具执行前确认审计密钥可用。
+func (l *Log) requireKey(context.Context, hooks.Invocation) (hooks.Result, error) {
+	return hooks.Result{}, l.Ready()
+}
+
+// handleToolCall 记录一次工具调用。等待审批而中断的调用会在恢复后重新执行，届时再记录。
+func (l *Log) handleToolCall(ctx context.Context, inv hooks.Invocation) (hooks.Result, error) {
+	payload, ok := inv.Payload.(hooks.AfterToolCallPayload)
+	if !ok {
+		return hooks.Result{}, nil
+	}
+	if interrupted, _ := payload.Meta["interrupted"].(bool); interrupted {
+		return hooks.Result{}, nil
+	}
+	record := domainaudit.Record{
+		SessionID: inv.SessionID,
+		Agent:     runtimeport.ToolAgentNameFromContext(ctx),
+		Tool:      payload.ToolName,
+		Paths:     approval.ArgumentPaths(payload.Args),
+	}
+	if record.SessionID == "" {
+		record.SessionID, _ = session.IDFromContext(ctx)
+	}
+	if principal, ok := domainaccount.PrincipalFromContext(ctx); ok {
+		record.User = principal.Username
+	}
+	scope := appusage.ScopeFromContext(ctx)
+	record.Channel, record.TaskID = scope.Channel, scope.TaskID
+	record.CallID, _ = payload.Meta["call_id"].(string)
+	decisions, _ := payload.Meta["approvals"].([]approval.Decision)
+	for _, d := range decisions {
+		record.Approvals = append(record.Approvals, domainaudit.Approval{
+			Store:   d.Store,
+			Outcome: string(d.Outcome),
+			Source:  string(d.Source),
+			Scope:   string(d.Scope),
+			Rule:    d.Rule,
+		})
+	}
+	setArguments(&record, payload.Args)
+	setOutcome(&record, payload.Result, payload.Error)
+	_, err := l.Record(ctx, record)
+	return hooks.Result{}, err
+}
+
+// Record 汇总审批结果后追加一条审计记录。
+func (l *Log) Record(ctx context.Context, record domainaudit.Record) (domainaudit.Record, error) {
+	store, err := l.requireStore()
+	if err != nil {
+		return record, err
+	}
+	if record.At.IsZero() {
+		record.At = l.now()
+	}
+	record.Summarize()
+	return store.AppendAudit(ctx, record)
+}
+
+// Query 按时间倒序返回满足条件的记录，limit <= 0 表示不限。无法解析的行被跳过，由 Verify 报告。
+func (l *Log) Query(ctx context.Context, filter domainaudit.Filter, limit int) ([]domainaudit.Record, error) {
+	store, err := l.requireStore()
+	if err != nil {
+		return nil, err
+	}
+	var records []domainaudit.Record
+	err = store.ScanAudit(ctx, func(_ int, record domainaudit.Record, err error) error {
+		if err == nil && filter.Match(record) {
+			records = append(records, record)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domainaudit.Record, 0, len(records))
+	for i := len(records) - 1; i >= 0; i-- {
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		result = append(result, records[i])
+	}
+	return result, nil
+}
+
+// Verify 从第一条记录开始以审计密钥校验哈希链，报告第一处损坏。
+func (l *Log) Verify(ctx context.Context) (domainaudit.Verification, error) {
+	store, err := l.requireStore()
+	if err != nil {
+		return domainaudit.Verification{}, err
+	}
+	key, err := l.key()
+	if err != nil {
+		return domainaudit.Verification{}, apperror.Wrap(apperror.CodeUnavailable, "audit key is unavailable", err)
+	}
+	verifier := domainaudit.NewVerifier(key)
+	err = store.ScanAudit(ctx, func(line int, record domainaudit.Record, err error) error {
+		if err != nil {
+			verifier.Fail(line, err.Error())
+			return nil
+		}
+		verifier.Add(line, record)
+		return nil
+	})
+	if err != nil {
+		return domainaudit.Verification{}, err
+	}
+	return verifier.Result(), nil
+}
+
+func setArguments(record *domainaudit.Record, args string) {
+	sum := sha256.Sum256([]byte(args))
+	record.ArgumentsSHA256 = hex.EncodeToString(sum[:])
+	record.Arguments, record.ArgumentsTruncated = truncate(args, maxArgumentBytes)
+}
+
+// setOutcome 根据工具错误和结果判断结束状态。被用户拒绝的操作通常以结果文本返回而不是错误，
+// 因此也参考审批结果；命令类工具的结果 JSON 提供 success、exit_code 和 error_message。
+func setOutcome(record *domainaudit.Record, result string, toolErr error) {
+	switch {
+	case errors.Is(toolErr, approval.ErrDenied):
+		record.Status = domainaudit.StatusDenied
+	case errors.Is(toolErr, approval.ErrRejected):
+		record.Status = domainaudit.StatusRejected
+	case toolErr != nil:
+		record.Status = domainaudit.StatusError
+	default:
+		record.Status = domainaudit.StatusOK
+	}
+	if toolErr != nil {
+		record.Error, _ = truncate(toolErr.Error(), maxErrorBytes)
+	}
+
+	var parsed struct {
+		Success      *bool  `json:"success"`
+		ExitCode     *int   `json:"exit_code"`
+		ErrorMessage string `json:"error_message"`
+	}
+	if json.Unmarshal([]byte(result), &parsed) == nil {
+		record.ExitCode = parsed.ExitCode
+		if record.Status == domainaudit.StatusOK && parsed.Success != nil && !*parsed.Success {
+			record.Status = domainaudit.StatusError
+		}
+		if record.Error == "" && parsed.ErrorMessage != "" {
+			record.Error, _ = truncate(parsed.ErrorMessage, maxErrorBytes)
+		}
+	}
+	if record.Status == domainaudit.StatusOK || record.Status == domainaudit.StatusError {
+		for _, check := range record.Approvals {
+			if check.Outcome == string(approval.OutcomeRejected) {
+				record.Status = domainaudit.StatusRejected
+				break
+			}
+		}
+	}
+}
+
+func truncate(s string, limit int) (string, bool) {
+	if len(s) <= limit {
+		return s, false
+	}
+	// 截断点落在多字节字符中间时退回到该字符开头
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit], true
+}
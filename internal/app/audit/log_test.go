@@ -0,0 +1,218 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	appusage "fkteams/internal/app/usage"
+	domainaccount "fkteams/internal/domain/account"
+	domainaudit "fkteams/internal/domain/audit"
+	domainsession "fkteams/internal/domain/session"
+	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/approval"
+	"fkteams/internal/runtime/env"
+	"fkteams/internal/runtime/hooks"
+	"fkteams/internal/runtime/secrets"
+)
+
+var testKey = []byte("test-audit-key")
+
+func staticKey() ([]byte, error) { return testKey, nil }
+
+type memoryStore struct {
+	mu      sync.Mutex
+	records []domainaudit.Record
+}
+
+func (s *memoryStore) AppendAudit(_ context.Context, record domainaudit.Record) (domainaudit.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var prev *domainaudit.Record
+	if n := len(s.records); n > 0 {
+		prev = &s.records[n-1]
+	}
+	record = record.Chain(prev, testKey)
+	s.records = append(s.records, record)
+	return record, nil
+}
+
+func (s *memoryStore) ScanAudit(_ context.Context, fn func(int, domainaudit.Record, error) error) error {
+	s.mu.Lock()
+	records := append([]domainaudit.Record(nil), s.records...)
+	s.mu.Unlock()
+	for i, record := range records {
+		if err := fn(i+1, record, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestInstallRecordsToolCalls(t *testing.T) {
+	store := &memoryStore{}
+	log := NewLog(store, staticKey)
+	bus := hooks.NewBus()
+	defer log.Install(bus)()
+
+	ctx := domainsession.WithID(context.Background(), "session-1")
+	ctx = domainaccount.WithPrincipal(ctx, domainaccount.Principal{Username: "alice"})
+	ctx = appusage.WithScope(ctx, appusage.Scope{Channel: appusage.ChannelWeb})
+	ctx = runtimeport.WithToolAgentName(ctx, "coder")
+
+	calls := []hooks.AfterToolCallPayload{
+		{
+			ToolName: "execute",
+			Args:     `{"command":"rm -rf build","path":"/work/app"}`,
+			Result:   `{"success":false,"exit_code":1,"error_message":"permission denied"}`,
+			Meta: map[string]any{"call_id": "c1", "approvals": []approval.Decision{
+				{Store: "policy", Outcome: approval.OutcomeApproved, Source: approval.SourcePolicy, Rule: "allow execute"},
+				{Store: "command", Outcome: approval.OutcomeApproved, Source: approval.SourceUser, Scope: approval.ScopeOnce},
+			}},
+		},
+		{
+			ToolName: "write_file",
+			Args:     `{"path":"/etc/hosts"}`,
+			Error:    fmt.Errorf("%w: deny write_file", approval.ErrDenied),
+			Meta: map[string]any{"approvals": []approval.Decision{
+				{Store: "policy", Outcome: approval.OutcomeDenied, Source: approval.SourcePolicy, Rule: "deny write_file"},
+			}},
+		},
+		{ToolName: "execute", Meta: map[string]any{"interrupted": true}},
+	}
+	for _, call := range calls {
+		if err := bus.InvokeAfterToolCall(ctx, call); err != nil {
+			t.Fatalf("InvokeAfterToolCall: %v", err)
+		}
+	}
+	if len(store.records) != 2 {
+		t.Fatalf("interrupted calls should not be recorded, got %d records", len(store.records))
+	}
+
+	command := store.records[0]
+	if command.User != "alice" || command.Agent != "coder" || command.SessionID != "session-1" || command.Channel != appusage.ChannelWeb ||
+		command.CallID != "c1" || command.Status != domainaudit.StatusError || command.ExitCode == nil || *command.ExitCode != 1 ||
+		command.Error != "permission denied" || command.Decision != "approved" || command.DecisionSource != "user" {
+		t.Fatalf("command record = %#v", command)
+	}
+	if len(command.Paths) != 1 || command.Paths[0] != "/work/app" || command.ArgumentsSHA256 == "" {
+		t.Fatalf("command paths = %v, sha = %q", command.Paths, command.ArgumentsSHA256)
+	}
+	denied := store.records[1]
+	if denied.Status != domainaudit.StatusDenied || denied.Decision != "denied" || denied.DecisionSource != "policy" {
+		t.Fatalf("denied record = %#v", denied)
+	}
+
+	records, err := log.Query(ctx, domainaudit.Filter{Source: "user", Path: "/work"}, 0)
+	if err != nil || len(records) != 1 || records[0].Seq != 1 {
+		t.Fatalf("Query = %#v, err = %v", records, err)
+	}
+	records, err = log.Query(ctx, domainaudit.Filter{User: "alice"}, 1)
+	if err != nil || len(records) != 1 || records[0].Seq != 2 {
+		t.Fatalf("Query newest first = %#v, err = %v", records, err)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	log := NewLog(store, staticKey)
+	for _, tool := range []string{"read_file", "execute", "write_file"} {
+		if _, err := log.Record(ctx, domainaudit.Record{Tool: tool, Status: domainaudit.StatusOK}); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	result, err := log.Verify(ctx)
+	if err != nil || !result.Valid || result.Records != 3 || result.Head != store.records[2].Hash {
+		t.Fatalf("Verify = %#v, err = %v", result, err)
+	}
+
+	store.records[1].Status = domainaudit.StatusDenied
+	if result, _ := log.Verify(ctx); result.Valid || result.Line != 2 || result.Records != 1 {
+		t.Fatalf("modified record verification = %#v", result)
+	}
+
+	// 删除记录后重新计算哈希也无法衔接后续记录
+	store.records[1].Status = domainaudit.StatusOK
+	store.records = append(store.records[:1], store.records[2:]...)
+	if result, _ := log.Verify(ctx); result.Valid || result.Line != 2 {
+		t.Fatalf("deleted record verification = %#v", result)
+	}
+
+	// 不持有密钥时，修改后重新计算整条哈希链也无法通过校验
+	var prev *domainaudit.Record
+	for i := range store.records {
+		store.records[i] = store.records[i].Chain(prev, []byte("forged-key"))
+		prev = &store.records[i]
+	}
+	if result, _ := log.Verify(ctx); result.Valid || result.Line != 1 {
+		t.Fatalf("rechained verification = %#v", result)
+	}
+}
+
+func TestVaultKeyIsCreatedOnlyByInit(t *testing.T) {
+	t.Setenv(env.VaultPassphrase, "correct horse")
+	path := filepath.Join(t.TempDir(), "secrets.json")
+
+	vault := secrets.Open(path, secrets.Options{})
+	if _, err := LoadVaultKey(vault); !errors.Is(err, secrets.ErrNotFound) {
+		t.Fatalf("missing key error = %v", err)
+	}
+	if vault.Exists() {
+		t.Fatal("loading the audit key created the vault")
+	}
+
+	if created, err := InitVaultKey(vault); err != nil || !created {
+		t.Fatalf("InitVaultKey = %v, %v", created, err)
+	}
+	first, err := LoadVaultKey(secrets.Open(path, secrets.Options{}))
+	if err != nil || len(first) != keySize {
+		t.Fatalf("LoadVaultKey = %x, err = %v", first, err)
+	}
+	if created, err := InitVaultKey(secrets.Open(path, secrets.Options{})); err != nil || created {
+		t.Fatalf("second InitVaultKey = %v, %v", created, err)
+	}
+	second, err := LoadVaultKey(secrets.Open(path, secrets.Options{}))
+	if err != nil || !bytes.Equal(first, second) {
+		t.Fatalf("second process key = %x, err = %v; want %x", second, err, first)
+	}
+
+	t.Setenv(env.VaultPassphrase, "wrong")
+	if _, err := LoadVaultKey(secrets.Open(path, secrets.Options{})); !errors.Is(err, secrets.ErrWrongKey) {
+		t.Fatalf("locked vault error = %v", err)
+	}
+}
+
+func TestInstallRefusesToolsWithoutKey(t *testing.T) {
+	store := &memoryStore{}
+	log := NewLog(store, StaticKey(nil, secrets.ErrLocked))
+	bus := hooks.NewBus()
+	defer log.Install(bus)()
+
+	ctx := context.Background()
+	if _, err := bus.InvokeBeforeToolCall(ctx, hooks.BeforeToolCallPayload{ToolName: "execute"}); !errors.Is(err, secrets.ErrLocked) {
+		t.Fatalf("before_tool_call error = %v", err)
+	}
+	bus = hooks.NewBus()
+	defer NewLog(&failingStore{}, staticKey).Install(bus)()
+	if err := bus.InvokeAfterToolCall(ctx, hooks.AfterToolCallPayload{ToolName: "execute"}); err == nil {
+		t.Fatal("after_tool_call ignored a failed audit write")
+	}
+}
+
+type failingStore struct{ memoryStore }
+
+func (*failingStore) AppendAudit(context.Context, domainaudit.Record) (domainaudit.Record, error) {
+	return domainaudit.Record{}, errors.New("disk full")
+}
+
+func TestTruncateKeepsValidUTF8(t *testing.T) {
+	got, truncated := truncate("审计日志", 4)
+	if !truncated || got != "审" {
+		t.Fatalf("truncate = %q, %v", got, truncated)
+	}
+}
@@ -19,21 +19,21 @@ type HistoryProjector interface {
 }
 
 type MemorySearcher interface {
-	Search(query string, topK int) []domainmemory.MemoryEntry
+	Search(query string, topK int, scope domainmemory.ScopeContext) []domainmemory.MemoryEntry
 }
 
 // BuildTurnInput 构建一轮输入（长期记忆 + 对话历史 + 用户输入）
 func BuildTurnInput(recorder HistoryProjector, userInput string) domainmessage.TurnInput {
-	return BuildTurnInputWithMemory(recorder, userInput, nil)
+	return BuildTurnInputWithMemory(recorder, userInput, nil, domainmemory.ScopeContext{})
 }
 
-// BuildTurnInputWithMemory 构建一轮输入并按需注入长期记忆。
-func BuildTurnInputWithMemory(recorder HistoryProjector, userInput string, manager MemorySearcher) domainmessage.TurnInput {
+// BuildTurnInputWithMemory 构建一轮输入并按需注入长期记忆，scope 决定可注入的记忆作用域。
+func BuildTurnInputWithMemory(recorder HistoryProjector, userInput string, manager MemorySearcher, scope domainmemory.ScopeContext) domainmessage.TurnInput {
 	var contextMessages []domainmessage.Message
 
 	// 注入长期记忆
 	if manager != nil {
-		memories := manager.Search(userInput, 5)
+		memories := manager.Search(userInput, 5, scope)
 		if memCtx := appmemory.BuildMemoryContext(memories); memCtx != "" {
 			contextMessages = append(contextMessages, domainmessage.Message{Role: domainmessage.RoleSystem, Content: memCtx})
 		}
@@ -54,16 +54,16 @@ func BuildTurnInputWithMemory(recorder HistoryProjector, userInput string, manag
 
 // BuildMultimodalTurnInput 构建一轮多模态输入（长期记忆 + 对话历史 + 多模态内容）
 func BuildMultimodalTurnInput(recorder HistoryProjector, textContent string, parts []domainmessage.ContentPart) domainmessage.TurnInput {
-	return BuildMultimodalTurnInputWithMemory(recorder, textContent, parts, nil)
+	return BuildMultimodalTurnInputWithMemory(recorder, textContent, parts, nil, domainmemory.ScopeContext{})
 }
 
 // BuildMultimodalTurnInputWithMemory 构建多模态输入并按需注入长期记忆。
-func BuildMultimodalTurnInputWithMemory(recorder HistoryProjector, textContent string, parts []domainmessage.ContentPart, manager MemorySearcher) domainmessage.TurnInput {
+func BuildMultimodalTurnInputWithMemory(recorder HistoryProjector, textContent string, parts []domainmessage.ContentPart, manager MemorySearcher, scope domainmemory.ScopeContext) domainmessage.TurnInput {
 	var contextMessages []domainmessage.Message
 
 	// 注入长期记忆（使用文本部分进行搜索）
 	if manager != nil {
-		memories := manager.Search(textContent, 5)
+		memories := manager.Search(textContent, 5, scope)
 		if memCtx := appmemory.BuildMemoryContext(memories); memCtx != "" {
 			contextMessages = append(contextMessages, domainmessage.Message{Role: domainmessage.RoleSystem, Content: memCtx})
 		}
@@ -52,11 +52,15 @@ func TestBuildTurnInputWithMemoryInjectsMemoryContext(t *testing.T) {
 		}},
 	}
 
-	input := BuildTurnInputWithMemory(recorder, "hello", manager)
+	scope := memory.ScopeContext{Workspace: "fkteams", Agent: "coder"}
+	input := BuildTurnInputWithMemory(recorder, "hello", manager, scope)
 
 	if manager.query != "hello" || manager.topK != 5 {
 		t.Fatalf("search query = %q/%d, want hello/5", manager.query, manager.topK)
 	}
+	if manager.scope != scope {
+		t.Fatalf("search scope = %#v, want %#v", manager.scope, scope)
+	}
 	if len(input.Context) == 0 || input.Context[0].Role != domainmessage.RoleSystem {
 		t.Fatalf("context = %#v, want memory system message", input.Context)
 	}
@@ -83,12 +87,14 @@ func TestBuildMultimodalTurnInputReturnsDisplayText(t *testing.T) {
 type testMemoryManager struct {
 	query   string
 	topK    int
+	scope   memory.ScopeContext
 	entries []memory.MemoryEntry
 }
 
-func (m *testMemoryManager) Search(query string, topK int) []memory.MemoryEntry {
+func (m *testMemoryManager) Search(query string, topK int, scope memory.ScopeContext) []memory.MemoryEntry {
 	m.query = query
 	m.topK = topK
+	m.scope = scope
 	return m.entries
 }
 
@@ -3,6 +3,8 @@ package chat
 import (
 	"context"
 
+	"fkteams/internal/app/project"
+	domainaccount "fkteams/internal/domain/account"
 	"fkteams/internal/domain/event"
 	domainmemory "fkteams/internal/domain/memory"
 	"fkteams/internal/runtime/log"
@@ -38,12 +40,16 @@ type MetadataUpdate struct {
 	UpdateDefaultTitle bool
 	Mode               *string
 	CurrentAgent       *string
+	Project            *string
+	// Owner 是新建会话时记录的归属账号，已有会话不会改变归属
+	Owner string
 }
 
-// ExecutionTarget 描述当前会话实际使用的运行模式和目标智能体。
+// ExecutionTarget 描述当前会话实际使用的运行模式、目标智能体和所属项目。
 type ExecutionTarget struct {
 	Mode         string
 	CurrentAgent string
+	Project      string
 }
 
 // MetadataStore 保存会话 metadata。
@@ -53,8 +59,8 @@ type MetadataStore interface {
 
 // MemoryExtractor 是对话结束后长期记忆提取需要的最小能力。
 type MemoryExtractor interface {
-	ExtractAndStoreAsync(messages []domainmemory.Message, sessionID string) bool
-	FlushExtract(ctx context.Context, messages []domainmemory.Message, sessionID string)
+	ExtractAndStoreAsync(messages []domainmemory.Message, sessionID string, scope domainmemory.ScopeContext) bool
+	FlushExtract(ctx context.Context, messages []domainmemory.Message, sessionID string, scope domainmemory.ScopeContext)
 }
 
 type FinishRequest struct {
@@ -67,6 +73,7 @@ type FinishRequest struct {
 	Error           error
 	Memory          MemoryExtractor
 	MemoryMessages  []domainmemory.Message
+	MemoryScope     domainmemory.ScopeContext
 }
 
 // SessionLifecycle 统一编排一次会话运行后的历史、metadata 和记忆收尾。
@@ -101,6 +108,7 @@ func (l *SessionLifecycle) MarkProcessingWithTarget(ctx context.Context, session
 		UpdateDefaultTitle: true,
 		Mode:               &target.Mode,
 		CurrentAgent:       &target.CurrentAgent,
+		Project:            optionalString(target.Project),
 	})
 }
 
@@ -140,23 +148,28 @@ func (l *SessionLifecycle) Finish(ctx context.Context, req FinishRequest) error
 		return err
 	}
 	if req.Status == SessionStatusCompleted {
-		ExtractMemoryAsync(req.Memory, req.MemoryMessages, req.SessionID)
+		ExtractMemoryAsync(req.Memory, req.MemoryMessages, req.SessionID, req.MemoryScope)
 	}
 	return nil
 }
 
+// SaveActive 保存活跃会话的历史和元数据；ctx 中带有项目时同时绑定会话所属项目。
 func (l *SessionLifecycle) SaveActive(ctx context.Context, sessionID, titleSource string, history SessionHistory) error {
 	if err := l.saveHistory(ctx, sessionID, history); err != nil {
 		return err
 	}
-	return l.updateMetadata(ctx, MetadataUpdate{
+	update := MetadataUpdate{
 		SessionID:          sessionID,
 		TitleSource:        titleSource,
 		Status:             SessionStatusActive,
 		DefaultTitle:       "未命名会话",
 		CreateIfMissing:    true,
 		UpdateDefaultTitle: true,
-	})
+	}
+	if p, ok := project.FromContext(ctx); ok {
+		update.Project = optionalString(p.Name)
+	}
+	return l.updateMetadata(ctx, update)
 }
 
 func (l *SessionLifecycle) saveHistory(ctx context.Context, sessionID string, history SessionHistory) error {
@@ -173,25 +186,28 @@ func (l *SessionLifecycle) updateMetadata(ctx context.Context, update MetadataUp
 	if update.DefaultTitle == "" {
 		update.DefaultTitle = "未命名会话"
 	}
+	if update.Owner == "" {
+		update.Owner = domainaccount.OwnerFromContext(ctx)
+	}
 	return l.metadata.UpdateMetadata(ctx, update)
 }
 
-func ExtractMemoryAsync(manager MemoryExtractor, messages []domainmemory.Message, sessionID string) {
+func ExtractMemoryAsync(manager MemoryExtractor, messages []domainmemory.Message, sessionID string, scope domainmemory.ScopeContext) {
 	if manager == nil || len(messages) == 0 {
 		return
 	}
 	copied := append([]domainmemory.Message(nil), messages...)
-	manager.ExtractAndStoreAsync(copied, sessionID)
+	manager.ExtractAndStoreAsync(copied, sessionID, scope)
 }
 
-func FlushMemory(ctx context.Context, manager MemoryExtractor, messages []domainmemory.Message, sessionID string) {
+func FlushMemory(ctx context.Context, manager MemoryExtractor, messages []domainmemory.Message, sessionID string, scope domainmemory.ScopeContext) {
 	if manager == nil || len(messages) == 0 {
 		return
 	}
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	manager.FlushExtract(ctx, messages, sessionID)
+	manager.FlushExtract(ctx, messages, sessionID, scope)
 }
 
 func LogLifecycleError(scope, sessionID string, err error) {
@@ -199,3 +215,11 @@ func LogLifecycleError(scope, sessionID string, err error) {
 		log.Printf("[%s] session lifecycle failed: session=%s, err=%v", scope, sessionID, err)
 	}
 }
+
+// optionalString 将空字符串视为未设置，避免覆盖已有字段。
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
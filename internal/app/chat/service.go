@@ -4,12 +4,18 @@ import (
 	"context"
 	"fmt"
 
+	"fkteams/internal/app/appstate"
+	"fkteams/internal/app/project"
+	"fkteams/internal/app/tools"
 	"fkteams/internal/app/tools/ask"
+	"fkteams/internal/app/userhooks"
 	"fkteams/internal/domain/event"
+	domainmemory "fkteams/internal/domain/memory"
 	"fkteams/internal/domain/message"
 	runtimeport "fkteams/internal/ports/runtime"
 	"fkteams/internal/runtime/approval"
 	"fkteams/internal/runtime/hooks"
+	"fkteams/internal/runtime/log"
 	"fkteams/internal/runtime/turn"
 )
 
@@ -28,13 +34,17 @@ type TurnRequest struct {
 	EventSink        EventHandler
 	Summary          turn.SummarySink
 	InterruptHandler runtimeport.InterruptHandler
+	Resume           runtimeport.InterruptDecisions
 	NonInteractive   bool
 	ApprovalRegistry *approval.Registry
 	SteeringSource   runtimeport.SteeringSource
 	AskHandler       ask.RuntimeHandler
-	HookBus          *hooks.Bus
-	ContextHooks     []ContextHook
-	OnFinish         func(ctx context.Context, result *runtimeport.RunResult, err error)
+	// Memory 和 MemoryScope 供记忆工具在本轮对话中读写长期记忆。
+	Memory       appstate.MemoryManager
+	MemoryScope  domainmemory.ScopeContext
+	HookBus      *hooks.Bus
+	ContextHooks []ContextHook
+	OnFinish     func(ctx context.Context, result *runtimeport.RunResult, err error)
 }
 
 // Service 是所有入口共享的聊天用例服务。
@@ -54,12 +64,24 @@ func (s *Service) RunTurn(ctx context.Context, req TurnRequest) (*runtimeport.Ru
 		return nil, fmt.Errorf("chat turn session ID is empty")
 	}
 
-	contextHooks := append([]ContextHook(nil), req.ContextHooks...)
-	if req.ApprovalRegistry != nil {
-		contextHooks = append(contextHooks, func(ctx context.Context) context.Context {
-			return approval.WithRegistry(ctx, req.ApprovalRegistry)
-		})
+	// 权限策略随文件热更新：每个回合开始前按会话所属项目重新读取，策略无效时拒绝执行而不是放行。
+	policy, err := tools.LoadPolicy(project.RootDir(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("load tool policy: %w", err)
+	}
+	registry := req.ApprovalRegistry
+	if registry == nil {
+		registry = approval.RegistryFromContext(ctx)
 	}
+	if registry == nil {
+		registry = approval.NewRegistry()
+	}
+	registry.SetPolicy(policy)
+
+	contextHooks := append([]ContextHook(nil), req.ContextHooks...)
+	contextHooks = append(contextHooks, func(ctx context.Context) context.Context {
+		return approval.WithRegistry(ctx, registry)
+	})
 	if req.SteeringSource != nil {
 		contextHooks = append(contextHooks, func(ctx context.Context) context.Context {
 			return runtimeport.WithSteeringSource(ctx, req.SteeringSource)
@@ -70,6 +92,20 @@ func (s *Service) RunTurn(ctx context.Context, req TurnRequest) (*runtimeport.Ru
 			return ask.WithRuntimeHandler(ctx, req.AskHandler)
 		})
 	}
+	if req.Memory != nil {
+		contextHooks = append(contextHooks, func(ctx context.Context) context.Context {
+			return appstate.WithMemory(ctx, req.Memory, req.MemoryScope)
+		})
+	}
+
+	hookBus := req.HookBus
+	if hookBus == nil {
+		hookBus = hooks.FromContext(ctx)
+	}
+	// 用户 hook 随配置热更新：每个回合开始前按当前配置同步注册。
+	if err := userhooks.FromContext(ctx).Reload(); err != nil {
+		log.Printf("[hooks] reload user hooks failed: %v", err)
+	}
 
 	turnHooks := make([]turn.ContextHook, 0, len(contextHooks))
 	for _, hook := range contextHooks {
@@ -83,12 +119,13 @@ func (s *Service) RunTurn(ctx context.Context, req TurnRequest) (*runtimeport.Ru
 		SessionID:      req.SessionID,
 		RunID:          req.RunID,
 		Input:          req.Input,
-		EventSink:      req.EventSink,
+		EventSink:      tools.SandboxEventSink(ctx, req.EventSink),
 		Summary:        req.Summary,
 		OnInterrupt:    turn.InterruptHandler(req.InterruptHandler),
+		Resume:         req.Resume,
 		NonInteractive: req.NonInteractive,
 		ContextHooks:   turnHooks,
-		HookBus:        req.HookBus,
+		HookBus:        hookBus,
 		OnFinish:       req.OnFinish,
 	})
 }
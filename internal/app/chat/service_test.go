@@ -45,6 +45,24 @@ func TestRunTurnDelegatesToRunnerAndPublishesEvents(t *testing.T) {
 	}
 }
 
+func TestRunTurnPassesResumeDecisionsToRunner(t *testing.T) {
+	runner := &fakeRunner{}
+	_, err := NewService().RunTurn(context.Background(), TurnRequest{
+		SessionID: "session-1",
+		Runner:    runner,
+		Resume:    runtimeport.InterruptDecisions{"interrupt-1": approval.ApproveOnce},
+	})
+	if err != nil {
+		t.Fatalf("run turn: %v", err)
+	}
+	if runner.opts.CheckpointID != "session-1" {
+		t.Fatalf("checkpoint ID = %q, want session-1", runner.opts.CheckpointID)
+	}
+	if got := runner.opts.Resume["interrupt-1"]; got != approval.ApproveOnce {
+		t.Fatalf("resume decision = %#v, want approve once", got)
+	}
+}
+
 func TestRunTurnRejectsMissingDependencies(t *testing.T) {
 	service := NewService()
 	if _, err := service.RunTurn(context.Background(), TurnRequest{SessionID: "s"}); err == nil {
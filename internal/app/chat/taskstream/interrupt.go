@@ -0,0 +1,45 @@
+package taskstream
+
+import (
+	"context"
+	"time"
+
+	runtimeport "fkteams/internal/ports/runtime"
+)
+
+// PendingInterrupt 描述一个等待人工输入的中断。
+// 它与会话 checkpoint 一起持久化，进程重启后可通过审批或 ask 回答恢复执行。
+type PendingInterrupt struct {
+	SessionID    string        `json:"session_id"`
+	Kind         InterruptKind `json:"kind"`
+	InterruptIDs []string      `json:"interrupt_ids,omitempty"`
+	RunID        string        `json:"run_id,omitempty"`
+	TurnID       string        `json:"turn_id,omitempty"`
+	Mode         string        `json:"mode,omitempty"`
+	AgentName    string        `json:"agent_name,omitempty"`
+	Message      string        `json:"message,omitempty"`
+	Question     string        `json:"question,omitempty"`
+	Options      []string      `json:"options,omitempty"`
+	MultiSelect  bool          `json:"multi_select,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+// Decisions 将一次人工输入转换为所有中断目标的恢复决策。
+func (p PendingInterrupt) Decisions(value any) runtimeport.InterruptDecisions {
+	decisions := make(runtimeport.InterruptDecisions, len(p.InterruptIDs))
+	for _, id := range p.InterruptIDs {
+		if id != "" {
+			decisions[id] = value
+		}
+	}
+	return decisions
+}
+
+// InterruptStore 持久化等待中的中断，供重启后列出和恢复。
+type InterruptStore interface {
+	SavePendingInterrupt(ctx context.Context, pending PendingInterrupt) error
+	// LoadPendingInterrupt 读取指定会话的等待中断；不存在时返回 nil。
+	LoadPendingInterrupt(ctx context.Context, sessionID string) (*PendingInterrupt, error)
+	DeletePendingInterrupt(ctx context.Context, sessionID string) error
+	ListPendingInterrupts(ctx context.Context) ([]PendingInterrupt, error)
+}
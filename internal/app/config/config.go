@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"net/mail"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"fkteams/internal/app/appdata"
 	"fkteams/internal/runtime/atomicfile"
@@ -30,16 +34,25 @@ const (
 
 // ModelConfig 可复用的模型配置，通过 ID 稳定引用，Name 仅用于展示。
 type ModelConfig struct {
-	ID           string   `toml:"id" json:"id"`
-	Name         string   `toml:"name" json:"name"`
-	UseFor       []string `toml:"use_for,omitempty" json:"use_for,omitempty"`
-	Provider     string   `toml:"provider,omitempty" json:"provider"`
-	BaseURL      string   `toml:"base_url" json:"base_url"`
-	APIKey       string   `toml:"api_key" json:"api_key"`
-	Model        string   `toml:"model" json:"model"`
-	ExtraHeaders string   `toml:"extra_headers,omitempty" json:"extra_headers"` // 格式: Key1:Value1,Key2:Value2
-	HasAPIKey    bool     `toml:"-" json:"has_api_key,omitempty"`               // 是否已配置 APIKey（前端展示用）
-	OriginalID   string   `toml:"-" json:"original_id,omitempty"`               // 前端加载时的原始 ID，用于 APIKey 还原匹配
+	ID           string      `toml:"id" json:"id"`
+	Name         string      `toml:"name" json:"name"`
+	UseFor       []string    `toml:"use_for,omitempty" json:"use_for,omitempty"`
+	Provider     string      `toml:"provider,omitempty" json:"provider"`
+	BaseURL      string      `toml:"base_url" json:"base_url"`
+	APIKey       string      `toml:"api_key" json:"api_key"`
+	Model        string      `toml:"model" json:"model"`
+	ExtraHeaders string      `toml:"extra_headers,omitempty" json:"extra_headers"` // 格式: Key1:Value1,Key2:Value2
+	Fallback     []string    `toml:"fallback,omitempty" json:"fallback,omitempty"` // 故障转移的模型 ID，按顺序尝试
+	Cooldown     string      `toml:"cooldown,omitempty" json:"cooldown,omitempty"` // 熔断冷却时间，如 "60s"；为空不熔断
+	Price        *ModelPrice `toml:"price,omitempty" json:"price,omitempty"`       // 用量计费单价；为空不计费
+	HasAPIKey    bool        `toml:"-" json:"has_api_key,omitempty"`               // 是否已配置 APIKey（前端展示用）
+	OriginalID   string      `toml:"-" json:"original_id,omitempty"`               // 前端加载时的原始 ID，用于 APIKey 还原匹配
+}
+
+// ModelPrice 模型单价，单位为每百万 token 的费用，币种由使用者自行约定。
+type ModelPrice struct {
+	Input  float64 `toml:"input" json:"input"`
+	Output float64 `toml:"output" json:"output"`
 }
 
 // ParseExtraHeaders 解析额外请求头字符串为 map
@@ -61,17 +74,112 @@ func (m *ModelConfig) ParseExtraHeaders() map[string]string {
 
 // Memory 长期记忆配置
 type Memory struct {
-	Enabled bool `toml:"enabled" json:"enabled"`
+	Enabled   bool            `toml:"enabled" json:"enabled"`
+	Embedding MemoryEmbedding `toml:"embedding" json:"embedding"`
+}
+
+// MemoryEmbedding 长期记忆语义检索使用的向量模型配置
+type MemoryEmbedding struct {
+	// Provider 可选 hash（默认，离线哈希向量）、openai（兼容 /embeddings 接口）、ollama、none（仅 BM25）
+	Provider   string `toml:"provider,omitempty" json:"provider"`
+	Model      string `toml:"model,omitempty" json:"model"`
+	BaseURL    string `toml:"base_url,omitempty" json:"base_url"`
+	APIKey     string `toml:"api_key,omitempty" json:"api_key"`
+	Dimensions int    `toml:"dimensions,omitempty" json:"dimensions"`
+	// MinSimilarity 语义检索的最低余弦相似度，0 使用默认值
+	MinSimilarity float64 `toml:"min_similarity,omitempty" json:"min_similarity"`
 }
 
 // ==================== 服务器 ====================
 
 // ServerAuth Web 认证配置
 type ServerAuth struct {
-	Enabled  bool   `toml:"enabled" json:"enabled"`
-	Username string `toml:"username" json:"username"`
-	Password string `toml:"password" json:"password"`
-	Secret   string `toml:"secret" json:"secret"`
+	Enabled  bool       `toml:"enabled" json:"enabled"`
+	Username string     `toml:"username" json:"username"`
+	Password string     `toml:"password" json:"password"`
+	Secret   string     `toml:"secret" json:"secret"`
+	OIDC     ServerOIDC `toml:"oidc" json:"oidc"`
+}
+
+// ServerOIDC OpenID Connect 单点登录配置，与密码登录并存
+type ServerOIDC struct {
+	Enabled bool `toml:"enabled" json:"enabled"`
+	// DisplayName 登录页按钮显示的名称
+	DisplayName  string `toml:"display_name,omitempty" json:"display_name"`
+	Issuer       string `toml:"issuer" json:"issuer"`
+	ClientID     string `toml:"client_id" json:"client_id"`
+	ClientSecret string `toml:"client_secret,omitempty" json:"client_secret"`
+	// RedirectURL 留空时按请求地址推导为 <scheme>://<host>/api/fkteams/oidc/callback
+	RedirectURL string   `toml:"redirect_url,omitempty" json:"redirect_url"`
+	Scopes      []string `toml:"scopes,omitempty" json:"scopes"`
+	// UsernameClaim 作为用户名的声明，默认 preferred_username
+	UsernameClaim string `toml:"username_claim,omitempty" json:"username_claim"`
+	// GroupsClaim 作为用户组的声明，默认 groups
+	GroupsClaim string `toml:"groups_claim,omitempty" json:"groups_claim"`
+	// GroupRoles 用户组到角色（admin/member/viewer）的映射，匹配多个时取权限最高的角色
+	GroupRoles map[string]string `toml:"group_roles,omitempty" json:"group_roles"`
+	// DefaultRole 没有匹配用户组时的角色，留空时拒绝登录
+	DefaultRole string `toml:"default_role,omitempty" json:"default_role"`
+}
+
+// UsernameClaimName 返回作为用户名的声明名
+func (o ServerOIDC) UsernameClaimName() string {
+	if o.UsernameClaim == "" {
+		return "preferred_username"
+	}
+	return o.UsernameClaim
+}
+
+// GroupsClaimName 返回作为用户组的声明名
+func (o ServerOIDC) GroupsClaimName() string {
+	if o.GroupsClaim == "" {
+		return "groups"
+	}
+	return o.GroupsClaim
+}
+
+func (o ServerOIDC) Validate() error {
+	if !o.Enabled {
+		return nil
+	}
+	issuer, err := url.Parse(strings.TrimSpace(o.Issuer))
+	if err != nil || issuer.Host == "" {
+		return fmt.Errorf("server.auth.oidc.issuer must be an absolute URL")
+	}
+	// 明文 HTTP 只允许本机身份提供方，用于开发和测试
+	if issuer.Scheme != "https" && !(issuer.Scheme == "http" && isLoopbackHost(issuer.Hostname())) {
+		return fmt.Errorf("server.auth.oidc.issuer must use https")
+	}
+	if strings.TrimSpace(o.ClientID) == "" {
+		return fmt.Errorf("server.auth.oidc.client_id is required when OIDC is enabled")
+	}
+	if o.RedirectURL != "" {
+		redirect, err := url.Parse(o.RedirectURL)
+		if err != nil || !redirect.IsAbs() {
+			return fmt.Errorf("server.auth.oidc.redirect_url must be an absolute URL")
+		}
+	}
+	for group, role := range o.GroupRoles {
+		if !validAccountRole(role) {
+			return fmt.Errorf("server.auth.oidc.group_roles[%q] has invalid role %q", group, role)
+		}
+	}
+	if o.DefaultRole != "" && !validAccountRole(o.DefaultRole) {
+		return fmt.Errorf("server.auth.oidc.default_role has invalid role %q", o.DefaultRole)
+	}
+	return nil
+}
+
+func validAccountRole(role string) bool {
+	return role == "admin" || role == "member" || role == "viewer"
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
 }
 
 func (a ServerAuth) Validate() error {
@@ -87,7 +195,7 @@ func (a ServerAuth) Validate() error {
 	if a.Secret == "" {
 		return fmt.Errorf("server.auth.secret is required when authentication is enabled")
 	}
-	return nil
+	return a.OIDC.Validate()
 }
 
 // Server 服务端配置
@@ -135,6 +243,7 @@ type AgentConfig struct {
 	ModelID     string    `toml:"model_id,omitempty" json:"model_id,omitempty"`
 	Tools       []string  `toml:"tools,omitempty" json:"tools"`
 	SSH         *AgentSSH `toml:"ssh,omitempty" json:"ssh,omitempty"`
+	Sandbox     string    `toml:"sandbox,omitempty" json:"sandbox,omitempty"` // 命令类工具使用的沙箱配置名
 	Enabled     bool      `toml:"enabled" json:"enabled"`
 	Builtin     bool      `toml:"-" json:"builtin,omitempty"`
 	TeamMember  bool      `toml:"-" json:"team_member,omitempty"`
@@ -380,6 +489,324 @@ type ToolApprovalSettings struct {
 	AutoApprove []string `toml:"auto_approve" json:"auto_approve"`
 }
 
+// ==================== 用量与预算 ====================
+
+// Usage 用量统计与预算配置
+type Usage struct {
+	Budgets UsageBudgets `toml:"budgets" json:"budgets"`
+}
+
+// UsageBudgets 按会话、定时任务和自然日划分的预算。
+type UsageBudgets struct {
+	Session UsageBudget `toml:"session" json:"session"`
+	Task    UsageBudget `toml:"task" json:"task"`
+	Day     UsageBudget `toml:"day" json:"day"`
+}
+
+// UsageBudget 单个预算范围的软/硬上限，0 表示不限制。
+// 超过软上限时提示一次，超过硬上限时拒绝后续模型请求并终止回合。
+type UsageBudget struct {
+	SoftTokens int64   `toml:"soft_tokens,omitempty" json:"soft_tokens,omitempty"`
+	HardTokens int64   `toml:"hard_tokens,omitempty" json:"hard_tokens,omitempty"`
+	SoftCost   float64 `toml:"soft_cost,omitempty" json:"soft_cost,omitempty"`
+	HardCost   float64 `toml:"hard_cost,omitempty" json:"hard_cost,omitempty"`
+}
+
+// ==================== 用户 Hook ====================
+
+// 用户 hook 可挂载的扩展点，与运行时 HookBus 的扩展点一致。
+var hookPoints = map[string]struct{}{
+	"before_run":           {},
+	"after_run":            {},
+	"on_event":             {},
+	"before_tool_call":     {},
+	"after_tool_call":      {},
+	"before_model_request": {},
+	"after_model_response": {},
+}
+
+// HookConfig 用户配置的 hook：本地命令或 HTTP 端点，二选一。
+type HookConfig struct {
+	Name        string            `toml:"name" json:"name"`
+	Points      []string          `toml:"points" json:"points"`
+	Matcher     string            `toml:"matcher,omitempty" json:"matcher,omitempty"` // 工具名正则，仅对工具相关调用生效
+	Command     string            `toml:"command,omitempty" json:"command,omitempty"` // 通过系统 shell 执行，载荷从 stdin 传入
+	Env         map[string]string `toml:"env,omitempty" json:"env,omitempty"`
+	URL         string            `toml:"url,omitempty" json:"url,omitempty"` // 以 POST JSON 调用
+	Headers     map[string]string `toml:"headers,omitempty" json:"headers,omitempty"`
+	Timeout     string            `toml:"timeout,omitempty" json:"timeout,omitempty"`           // 如 "5s"，为空使用默认值
+	ErrorPolicy string            `toml:"error_policy,omitempty" json:"error_policy,omitempty"` // ignore、warn、fail，为空按扩展点默认
+	Priority    int               `toml:"priority,omitempty" json:"priority,omitempty"`
+	Disabled    bool              `toml:"disabled,omitempty" json:"disabled,omitempty"`
+}
+
+// TimeoutDuration 返回 hook 超时时间，未配置或格式错误时返回 0。
+func (h *HookConfig) TimeoutDuration() time.Duration {
+	if h.Timeout == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(h.Timeout)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// ValidateHooks 校验用户 hook 配置。
+func (c *Config) ValidateHooks() error {
+	if c == nil {
+		return nil
+	}
+	names := make(map[string]struct{}, len(c.Hooks))
+	for _, h := range c.Hooks {
+		if h.Name == "" {
+			return fmt.Errorf("hook name is required")
+		}
+		if _, ok := names[h.Name]; ok {
+			return fmt.Errorf("duplicate hook name: %s", h.Name)
+		}
+		names[h.Name] = struct{}{}
+		if len(h.Points) == 0 {
+			return fmt.Errorf("hook %s points are required", h.Name)
+		}
+		for _, point := range h.Points {
+			if _, ok := hookPoints[point]; !ok {
+				return fmt.Errorf("hook %s point %q is not supported", h.Name, point)
+			}
+		}
+		if (h.Command == "") == (h.URL == "") {
+			return fmt.Errorf("hook %s requires exactly one of command or url", h.Name)
+		}
+		if h.URL != "" && !strings.HasPrefix(h.URL, "http://") && !strings.HasPrefix(h.URL, "https://") {
+			return fmt.Errorf("hook %s url must be http or https", h.Name)
+		}
+		if h.Matcher != "" {
+			if _, err := regexp.Compile(h.Matcher); err != nil {
+				return fmt.Errorf("hook %s matcher is invalid: %w", h.Name, err)
+			}
+		}
+		if h.Timeout != "" {
+			if d, err := time.ParseDuration(h.Timeout); err != nil || d < 0 {
+				return fmt.Errorf("hook %s timeout %q is invalid", h.Name, h.Timeout)
+			}
+		}
+		switch h.ErrorPolicy {
+		case "", "ignore", "warn", "fail":
+		default:
+			return fmt.Errorf("hook %s error_policy %q is invalid", h.Name, h.ErrorPolicy)
+		}
+	}
+	return nil
+}
+
+// ==================== 项目 ====================
+
+// DefaultProjectName 是内置默认项目的名称，对应 ~/.fkteams/workspace。
+const DefaultProjectName = "default"
+
+var projectNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// Projects 具名工作区（项目）注册表。
+type Projects struct {
+	Current string          `toml:"current,omitempty" json:"current"` // 未指定项目时使用的项目，为空使用默认项目
+	Items   []ProjectConfig `toml:"items,omitempty" json:"items"`
+}
+
+// ProjectConfig 描述一个项目：工作区根目录以及在该项目中运行时的默认值。
+type ProjectConfig struct {
+	Name        string            `toml:"name" json:"name"`
+	Root        string            `toml:"root" json:"root"`
+	Description string            `toml:"description,omitempty" json:"description,omitempty"`
+	Agent       string            `toml:"agent,omitempty" json:"agent,omitempty"`               // 默认智能体
+	Mode        string            `toml:"mode,omitempty" json:"mode,omitempty"`                 // 默认工作模式
+	AutoApprove []string          `toml:"auto_approve,omitempty" json:"auto_approve,omitempty"` // 在全局 auto_approve 之外额外自动批准的类别
+	Env         map[string]string `toml:"env,omitempty" json:"env,omitempty"`                   // 命令类工具的附加环境变量
+	Sandbox     string            `toml:"sandbox,omitempty" json:"sandbox,omitempty"`           // 命令类工具使用的沙箱配置名，智能体配置优先
+}
+
+// ResolveProject 按名称查找项目配置，未找到返回 nil。
+func (c *Config) ResolveProject(name string) *ProjectConfig {
+	for i := range c.Projects.Items {
+		if c.Projects.Items[i].Name == name {
+			return &c.Projects.Items[i]
+		}
+	}
+	return nil
+}
+
+// ValidateProjects 校验项目注册表。
+func (c *Config) ValidateProjects() error {
+	if c == nil {
+		return nil
+	}
+	names := make(map[string]struct{}, len(c.Projects.Items))
+	for _, p := range c.Projects.Items {
+		if !projectNamePattern.MatchString(p.Name) {
+			return fmt.Errorf("project name %q is invalid", p.Name)
+		}
+		if p.Name == DefaultProjectName {
+			return fmt.Errorf("project name %q is reserved", p.Name)
+		}
+		if _, ok := names[p.Name]; ok {
+			return fmt.Errorf("duplicate project name: %s", p.Name)
+		}
+		names[p.Name] = struct{}{}
+		if !filepath.IsAbs(p.Root) {
+			return fmt.Errorf("project %s root must be an absolute path", p.Name)
+		}
+	}
+	if current := c.Projects.Current; current != "" && current != DefaultProjectName {
+		if _, ok := names[current]; !ok {
+			return fmt.Errorf("projects.current %q not found", current)
+		}
+	}
+	return nil
+}
+
+// ==================== 执行沙箱 ====================
+
+// SandboxDirect 是内置沙箱配置名，表示直接在宿主机执行，可用于在智能体或项目中关闭全局默认沙箱。
+const SandboxDirect = "direct"
+
+// Sandbox 命令类工具（execute、uv、bun）的执行沙箱配置。
+type Sandbox struct {
+	Default  string           `toml:"default,omitempty" json:"default"` // 智能体和项目都未指定时使用的配置名，为空时直接执行
+	Profiles []SandboxProfile `toml:"profiles,omitempty" json:"profiles"`
+}
+
+// SandboxProfile 描述一个沙箱配置。
+type SandboxProfile struct {
+	Name         string   `toml:"name" json:"name"`
+	Backend      string   `toml:"backend" json:"backend"`                                 // linux 或 direct
+	Network      bool     `toml:"network,omitempty" json:"network,omitempty"`             // 允许访问网络
+	Writable     []string `toml:"writable,omitempty" json:"writable,omitempty"`           // 工作区之外额外可写的目录
+	ReadOnly     []string `toml:"read_only,omitempty" json:"read_only,omitempty"`         // 家目录和应用数据目录中需要只读访问的目录，如工具链
+	PassEnv      []string `toml:"pass_env,omitempty" json:"pass_env,omitempty"`           // 白名单之外传入沙箱的环境变量名
+	CPUSeconds   int      `toml:"cpu_seconds,omitempty" json:"cpu_seconds,omitempty"`     // CPU 时间上限
+	MemoryMB     int      `toml:"memory_mb,omitempty" json:"memory_mb,omitempty"`         // 虚拟内存上限
+	MaxProcesses int      `toml:"max_processes,omitempty" json:"max_processes,omitempty"` // 进程数上限
+	Required     bool     `toml:"required,omitempty" json:"required,omitempty"`           // 后端不可用时拒绝执行而不是回退为直接执行
+}
+
+// ResolveSandboxProfile 按名称查找沙箱配置；名称为空时使用默认配置。
+// 未配置默认沙箱或名称为 direct 时返回 nil，表示直接执行。
+func (c *Config) ResolveSandboxProfile(name string) *SandboxProfile {
+	if name == "" {
+		name = c.Sandbox.Default
+	}
+	if name == "" || name == SandboxDirect {
+		return nil
+	}
+	for i := range c.Sandbox.Profiles {
+		if c.Sandbox.Profiles[i].Name == name {
+			return &c.Sandbox.Profiles[i]
+		}
+	}
+	return nil
+}
+
+// ValidateSandbox 校验沙箱配置，以及智能体和项目对沙箱配置名的引用。
+func (c *Config) ValidateSandbox() error {
+	if c == nil {
+		return nil
+	}
+	names := map[string]struct{}{SandboxDirect: {}}
+	for _, p := range c.Sandbox.Profiles {
+		if !projectNamePattern.MatchString(p.Name) {
+			return fmt.Errorf("sandbox profile name %q is invalid", p.Name)
+		}
+		if _, ok := names[p.Name]; ok {
+			return fmt.Errorf("duplicate sandbox profile name: %s", p.Name)
+		}
+		names[p.Name] = struct{}{}
+		switch p.Backend {
+		case "linux", "direct":
+		default:
+			return fmt.Errorf("sandbox profile %s backend %q is invalid", p.Name, p.Backend)
+		}
+		if p.CPUSeconds < 0 || p.MemoryMB < 0 || p.MaxProcesses < 0 {
+			return fmt.Errorf("sandbox profile %s limits must not be negative", p.Name)
+		}
+		for _, dir := range p.Writable {
+			if !filepath.IsAbs(dir) && dir != "~" && !strings.HasPrefix(dir, "~/") {
+				return fmt.Errorf("sandbox profile %s writable path %q must be absolute", p.Name, dir)
+			}
+		}
+		for _, dir := range p.ReadOnly {
+			if !filepath.IsAbs(dir) && dir != "~" && !strings.HasPrefix(dir, "~/") {
+				return fmt.Errorf("sandbox profile %s read_only path %q must be absolute", p.Name, dir)
+			}
+		}
+		for _, name := range p.PassEnv {
+			if name == "" || strings.ContainsAny(name, "= ") {
+				return fmt.Errorf("sandbox profile %s pass_env name %q is invalid", p.Name, name)
+			}
+		}
+	}
+	check := func(owner, name string) error {
+		if name == "" {
+			return nil
+		}
+		if _, ok := names[name]; !ok {
+			return fmt.Errorf("%s sandbox profile %q not found", owner, name)
+		}
+		return nil
+	}
+	if err := check("sandbox.default", c.Sandbox.Default); err != nil {
+		return err
+	}
+	for _, agent := range c.Agents.Items {
+		if err := check("agent "+agent.ID, agent.Sandbox); err != nil {
+			return err
+		}
+	}
+	for _, p := range c.Projects.Items {
+		if err := check("project "+p.Name, p.Sandbox); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ==================== 定时任务投递 ====================
+
+// Scheduler 定时任务结果投递配置。
+type Scheduler struct {
+	WebhookSecret string `toml:"webhook_secret,omitempty" json:"webhook_secret"` // Webhook 投递的 HMAC-SHA256 签名密钥，为空时不签名
+	SMTP          SMTP   `toml:"smtp" json:"smtp"`
+}
+
+// SMTP 邮件投递使用的发信服务器。
+type SMTP struct {
+	Host     string `toml:"host,omitempty" json:"host"`
+	Port     int    `toml:"port,omitempty" json:"port"` // 默认 587
+	Username string `toml:"username,omitempty" json:"username"`
+	Password string `toml:"password,omitempty" json:"password"`
+	From     string `toml:"from,omitempty" json:"from"`
+	TLS      bool   `toml:"tls,omitempty" json:"tls"` // 使用隐式 TLS（通常为 465 端口），否则在服务器支持时使用 STARTTLS
+}
+
+// ValidateScheduler 校验定时任务投递配置。
+func (c *Config) ValidateScheduler() error {
+	if c == nil {
+		return nil
+	}
+	smtp := c.Scheduler.SMTP
+	if smtp.Port < 0 || smtp.Port > 65535 {
+		return fmt.Errorf("scheduler.smtp.port %d is invalid", smtp.Port)
+	}
+	if smtp.Host == "" {
+		return nil
+	}
+	if smtp.From == "" {
+		return fmt.Errorf("scheduler.smtp.from is required when host is set")
+	}
+	if _, err := mail.ParseAddress(smtp.From); err != nil {
+		return fmt.Errorf("scheduler.smtp.from %q is invalid", smtp.From)
+	}
+	return nil
+}
+
 // ==================== OpenAI 兼容 API ====================
 
 // OpenAIAPI OpenAI 兼容 API 配置
@@ -400,6 +827,11 @@ type Config struct {
 	Roundtable Roundtable    `toml:"roundtable" json:"roundtable"`
 	Deep       Deep          `toml:"deep" json:"deep"`
 	Tools      ToolSettings  `toml:"tools" json:"tools"`
+	Usage      Usage         `toml:"usage" json:"usage"`
+	Hooks      []HookConfig  `toml:"hooks,omitempty" json:"hooks"`
+	Projects   Projects      `toml:"projects" json:"projects"`
+	Sandbox    Sandbox       `toml:"sandbox" json:"sandbox"`
+	Scheduler  Scheduler     `toml:"scheduler" json:"scheduler"`
 }
 
 // ResolveModel 通过稳定 ID 查找模型配置，空 ID 返回默认对话模型。
@@ -454,12 +886,42 @@ func (c *Config) ValidateModels() error {
 			uses[use] = m.ID
 		}
 	}
+	for _, m := range c.Models {
+		for _, id := range m.Fallback {
+			if id == m.ID {
+				return fmt.Errorf("model %s cannot fall back to itself", m.ID)
+			}
+			if _, ok := modelIDs[id]; !ok {
+				return fmt.Errorf("model %s fallback %q not found", m.ID, id)
+			}
+		}
+		if m.Cooldown != "" {
+			if d, err := time.ParseDuration(m.Cooldown); err != nil || d < 0 {
+				return fmt.Errorf("model %s cooldown %q is invalid", m.ID, m.Cooldown)
+			}
+		}
+		if m.Price != nil && (m.Price.Input < 0 || m.Price.Output < 0) {
+			return fmt.Errorf("model %s price must not be negative", m.ID)
+		}
+	}
 	if _, ok := uses[ModelUseChat]; !ok {
 		return fmt.Errorf("model use_for %q is required", ModelUseChat)
 	}
 	return nil
 }
 
+// CooldownDuration 返回熔断冷却时间，未配置或格式错误时返回 0。
+func (m *ModelConfig) CooldownDuration() time.Duration {
+	if m.Cooldown == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(m.Cooldown)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
 func (c *Config) ValidateRoundtable() error {
 	if c == nil {
 		return nil
@@ -480,6 +942,30 @@ func (c *Config) ValidateDeep() error {
 	return nil
 }
 
+// ValidateMemory 校验长期记忆的向量模型配置
+func (c *Config) ValidateMemory() error {
+	if c == nil {
+		return nil
+	}
+	embedding := c.Memory.Embedding
+	switch strings.ToLower(strings.TrimSpace(embedding.Provider)) {
+	case "", "hash", "none":
+	case "openai", "ollama":
+		if strings.TrimSpace(embedding.Model) == "" {
+			return fmt.Errorf("memory.embedding.model is required for provider %q", embedding.Provider)
+		}
+	default:
+		return fmt.Errorf("memory.embedding.provider %q is not supported", embedding.Provider)
+	}
+	if embedding.Dimensions < 0 {
+		return fmt.Errorf("memory.embedding.dimensions must be >= 0")
+	}
+	if embedding.MinSimilarity < 0 || embedding.MinSimilarity > 1 {
+		return fmt.Errorf("memory.embedding.min_similarity must be between 0 and 1")
+	}
+	return nil
+}
+
 // WorkspaceDir 返回工作区目录（固定为 ~/.fkteams/workspace）
 func (c *Config) WorkspaceDir() string {
 	return filepath.Join(appdata.Dir(), "workspace")
@@ -488,7 +974,9 @@ func (c *Config) WorkspaceDir() string {
 // ==================== 全局单例 ====================
 
 var (
-	globalConfig  atomic.Pointer[Config]
+	globalConfig atomic.Pointer[Config]
+	// rawConfig 是配置文件中的原始内容，敏感字段可能是未解析的引用
+	rawConfig     atomic.Pointer[Config]
 	configOnce    sync.Once
 	configInitErr error
 	configMu      sync.Mutex // 保护写操作
@@ -503,11 +991,12 @@ func Init() error {
 	configMu.Lock()
 	defer configMu.Unlock()
 	configOnce.Do(func() {
-		cfg, err := load()
+		raw, cfg, err := load()
 		if err != nil {
 			configInitErr = err
 			return
 		}
+		rawConfig.Store(raw)
 		globalConfig.Store(cfg)
 	})
 	return configInitErr
@@ -530,16 +1019,18 @@ func Snapshot() *Config {
 func Reload() error {
 	configMu.Lock()
 	defer configMu.Unlock()
-	cfg, err := load()
+	raw, cfg, err := load()
 	if err != nil {
 		return err
 	}
+	rawConfig.Store(raw)
 	globalConfig.Store(cfg)
 	configInitErr = nil
 	return nil
 }
 
 // Save 原子保存配置，并发布与调用方隔离的不可变快照。
+// 值未改变的敏感字段保留配置文件中的引用；引用无法解析时不保存。
 func Save(cfg *Config) error {
 	if cfg == nil {
 		return fmt.Errorf("config is nil")
@@ -548,8 +1039,13 @@ func Save(cfg *Config) error {
 	defer configMu.Unlock()
 
 	filePath := configFilePath()
-	snapshot := cloneConfig(cfg)
-	data, err := toml.Marshal(snapshot)
+	raw := cloneConfig(cfg)
+	preserveSecretReferences(raw, rawConfig.Load(), globalConfig.Load())
+	snapshot, err := resolveSecrets(raw)
+	if err != nil {
+		return err
+	}
+	data, err := toml.Marshal(raw)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
@@ -557,6 +1053,7 @@ func Save(cfg *Config) error {
 		return fmt.Errorf("failed to save config: %w", err)
 	}
 
+	rawConfig.Store(raw)
 	globalConfig.Store(snapshot)
 	configInitErr = nil
 	return nil
@@ -570,6 +1067,11 @@ func cloneConfig(cfg *Config) *Config {
 	cloned.Models = append([]ModelConfig(nil), cfg.Models...)
 	for i := range cloned.Models {
 		cloned.Models[i].UseFor = append([]string(nil), cfg.Models[i].UseFor...)
+		cloned.Models[i].Fallback = append([]string(nil), cfg.Models[i].Fallback...)
+		if cfg.Models[i].Price != nil {
+			price := *cfg.Models[i].Price
+			cloned.Models[i].Price = &price
+		}
 	}
 	cloned.Server.AllowOrigins = append([]string(nil), cfg.Server.AllowOrigins...)
 	cloned.Server.TrustedProxies = append([]string(nil), cfg.Server.TrustedProxies...)
@@ -595,9 +1097,37 @@ func cloneConfig(cfg *Config) *Config {
 		}
 	}
 	cloned.Tools.Approval.AutoApprove = append([]string(nil), cfg.Tools.Approval.AutoApprove...)
+	cloned.Hooks = append([]HookConfig(nil), cfg.Hooks...)
+	for i := range cloned.Hooks {
+		cloned.Hooks[i].Points = append([]string(nil), cfg.Hooks[i].Points...)
+		cloned.Hooks[i].Env = cloneStringMap(cfg.Hooks[i].Env)
+		cloned.Hooks[i].Headers = cloneStringMap(cfg.Hooks[i].Headers)
+	}
+	cloned.Projects.Items = append([]ProjectConfig(nil), cfg.Projects.Items...)
+	for i := range cloned.Projects.Items {
+		cloned.Projects.Items[i].AutoApprove = append([]string(nil), cfg.Projects.Items[i].AutoApprove...)
+		cloned.Projects.Items[i].Env = cloneStringMap(cfg.Projects.Items[i].Env)
+	}
+	cloned.Sandbox.Profiles = append([]SandboxProfile(nil), cfg.Sandbox.Profiles...)
+	for i := range cloned.Sandbox.Profiles {
+		cloned.Sandbox.Profiles[i].Writable = append([]string(nil), cfg.Sandbox.Profiles[i].Writable...)
+		cloned.Sandbox.Profiles[i].ReadOnly = append([]string(nil), cfg.Sandbox.Profiles[i].ReadOnly...)
+		cloned.Sandbox.Profiles[i].PassEnv = append([]string(nil), cfg.Sandbox.Profiles[i].PassEnv...)
+	}
 	return &cloned
 }
 
+func cloneStringMap(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	cloned := make(map[string]string, len(values))
+	for key, value := range values {
+		cloned[key] = value
+	}
+	return cloned
+}
+
 // EnsureDefaultModel 检查是否配置了默认模型，未配置时返回引导信息
 func ensureDefaultModel() error {
 	cfg := Get()
@@ -625,16 +1155,20 @@ func InitAndValidate() error {
 	return ensureDefaultModel()
 }
 
-// load 从文件加载配置
-func load() (*Config, error) {
+// load 从文件加载配置，返回原始配置和解析了密钥引用的配置
+func load() (*Config, *Config, error) {
 	var config Config
 	if err := Unmarshal(filepath.Join(appdata.Dir(), "config", "config.toml"), &config); err != nil {
 		if os.IsNotExist(err) {
-			return defaultConfig(), nil
+			return defaultConfig(), defaultConfig(), nil
 		}
-		return nil, err
+		return nil, nil, err
+	}
+	resolved, err := resolveSecrets(&config)
+	if err != nil {
+		return nil, nil, err
 	}
-	return &config, nil
+	return &config, resolved, nil
 }
 
 func defaultConfig() *Config {
@@ -723,7 +1257,7 @@ func GenerateExample() error {
 					Name:        "协调者",
 					Description: "核心工程智能体，直接完成常规工程任务，并按需指派专业成员。",
 					Prompt:      "",
-					Tools:       []string{"todo", "file", "command", "scheduler", "ask"},
+					Tools:       []string{"todo", "file", "command", "scheduler", "memory", "ask"},
 					Enabled:     true,
 				},
 				{
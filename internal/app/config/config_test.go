@@ -6,6 +6,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 func resetConfigForTest(t *testing.T) string {
@@ -14,6 +15,7 @@ func resetConfigForTest(t *testing.T) string {
 	appDir := t.TempDir()
 	t.Setenv("FEIKONG_APP_DIR", appDir)
 	globalConfig.Store((*Config)(nil))
+	rawConfig.Store((*Config)(nil))
 	configOnce = sync.Once{}
 	configInitErr = nil
 	return appDir
@@ -51,6 +53,21 @@ func TestServerAuthValidate(t *testing.T) {
 		{name: "blank username", auth: ServerAuth{Enabled: true, Username: "  ", Password: "password", Secret: "secret"}, wantErr: true},
 		{name: "missing password", auth: ServerAuth{Enabled: true, Username: "admin", Secret: "secret"}, wantErr: true},
 		{name: "missing secret", auth: ServerAuth{Enabled: true, Username: "admin", Password: "password"}, wantErr: true},
+		{name: "valid oidc", auth: ServerAuth{Enabled: true, Username: "admin", Password: "password", Secret: "secret", OIDC: ServerOIDC{
+			Enabled: true, Issuer: "https://id.example.com", ClientID: "fkteams", GroupRoles: map[string]string{"eng": "member"}, DefaultRole: "viewer",
+		}}},
+		{name: "oidc loopback http issuer", auth: ServerAuth{Enabled: true, Username: "admin", Password: "password", Secret: "secret", OIDC: ServerOIDC{
+			Enabled: true, Issuer: "http://127.0.0.1:5556", ClientID: "fkteams",
+		}}},
+		{name: "oidc plain http issuer", auth: ServerAuth{Enabled: true, Username: "admin", Password: "password", Secret: "secret", OIDC: ServerOIDC{
+			Enabled: true, Issuer: "http://id.example.com", ClientID: "fkteams",
+		}}, wantErr: true},
+		{name: "oidc missing client id", auth: ServerAuth{Enabled: true, Username: "admin", Password: "password", Secret: "secret", OIDC: ServerOIDC{
+			Enabled: true, Issuer: "https://id.example.com",
+		}}, wantErr: true},
+		{name: "oidc invalid role", auth: ServerAuth{Enabled: true, Username: "admin", Password: "password", Secret: "secret", OIDC: ServerOIDC{
+			Enabled: true, Issuer: "https://id.example.com", ClientID: "fkteams", GroupRoles: map[string]string{"eng": "owner"},
+		}}, wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -146,6 +163,163 @@ func TestConfigResolveModelAndWorkspaceDir(t *testing.T) {
 	}
 }
 
+func TestValidateModelsFallback(t *testing.T) {
+	cfg := &Config{Models: []ModelConfig{
+		{ID: "main", UseFor: []string{ModelUseChat}, Fallback: []string{"backup"}, Cooldown: "30s"},
+		{ID: "backup"},
+	}}
+	if err := cfg.ValidateModels(); err != nil {
+		t.Fatalf("ValidateModels: %v", err)
+	}
+	if got := cfg.Models[0].CooldownDuration(); got != 30*time.Second {
+		t.Fatalf("CooldownDuration = %v", got)
+	}
+
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.Models[0].Fallback = []string{"missing"} },
+		func(c *Config) { c.Models[0].Fallback = []string{"main"} },
+		func(c *Config) { c.Models[0].Cooldown = "soon" },
+		func(c *Config) { c.Models[0].Price = &ModelPrice{Input: -1} },
+	} {
+		bad := cloneConfig(cfg)
+		mutate(bad)
+		if err := bad.ValidateModels(); err == nil {
+			t.Fatalf("ValidateModels accepted %#v", bad.Models[0])
+		}
+	}
+}
+
+func TestValidateHooks(t *testing.T) {
+	cfg := &Config{Hooks: []HookConfig{
+		{Name: "audit", Points: []string{"before_tool_call"}, Matcher: "^execute$", Command: "cat", Timeout: "5s", ErrorPolicy: "warn"},
+		{Name: "notify", Points: []string{"on_event"}, URL: "https://example.com/hook"},
+	}}
+	if err := cfg.ValidateHooks(); err != nil {
+		t.Fatalf("ValidateHooks: %v", err)
+	}
+	if got := cfg.Hooks[0].TimeoutDuration(); got != 5*time.Second {
+		t.Fatalf("TimeoutDuration = %v", got)
+	}
+
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.Hooks[1].Name = "audit" },
+		func(c *Config) { c.Hooks[0].Points = []string{"before_everything"} },
+		func(c *Config) { c.Hooks[0].URL = "https://example.com" },
+		func(c *Config) { c.Hooks[1].URL = "ftp://example.com" },
+		func(c *Config) { c.Hooks[0].Matcher = "(" },
+		func(c *Config) { c.Hooks[0].Timeout = "soon" },
+		func(c *Config) { c.Hooks[0].ErrorPolicy = "panic" },
+	} {
+		bad := cloneConfig(cfg)
+		mutate(bad)
+		if err := bad.ValidateHooks(); err == nil {
+			t.Fatalf("ValidateHooks accepted %#v", bad.Hooks)
+		}
+	}
+}
+
+func TestValidateProjects(t *testing.T) {
+	root := t.TempDir()
+	cfg := &Config{Projects: Projects{Current: "api", Items: []ProjectConfig{
+		{Name: "api", Root: root, Mode: "deep"},
+		{Name: "web.v2", Root: root},
+	}}}
+	if err := cfg.ValidateProjects(); err != nil {
+		t.Fatalf("ValidateProjects: %v", err)
+	}
+	if p := cfg.ResolveProject("api"); p == nil || p.Mode != "deep" {
+		t.Fatalf("ResolveProject = %#v", p)
+	}
+
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.Projects.Items[1].Name = "api" },
+		func(c *Config) { c.Projects.Items[0].Name = DefaultProjectName },
+		func(c *Config) { c.Projects.Items[0].Name = "has space" },
+		func(c *Config) { c.Projects.Items[0].Root = "relative/dir" },
+		func(c *Config) { c.Projects.Current = "missing" },
+	} {
+		bad := cloneConfig(cfg)
+		mutate(bad)
+		if err := bad.ValidateProjects(); err == nil {
+			t.Fatalf("ValidateProjects accepted %#v", bad.Projects)
+		}
+	}
+}
+
+func TestValidateSandbox(t *testing.T) {
+	cfg := &Config{
+		Sandbox: Sandbox{Default: "strict", Profiles: []SandboxProfile{
+			{Name: "strict", Backend: "linux", Writable: []string{"~/.cache"}, CPUSeconds: 60},
+			{Name: "open", Backend: "direct"},
+		}},
+		Agents:   Agents{Items: []AgentConfig{{ID: "coder", Sandbox: "open"}}},
+		Projects: Projects{Items: []ProjectConfig{{Name: "api", Root: "/srv/api", Sandbox: SandboxDirect}}},
+	}
+	if err := cfg.ValidateSandbox(); err != nil {
+		t.Fatalf("ValidateSandbox: %v", err)
+	}
+	if p := cfg.ResolveSandboxProfile(""); p == nil || p.Name != "strict" {
+		t.Fatalf("default profile = %#v", p)
+	}
+	if p := cfg.ResolveSandboxProfile(SandboxDirect); p != nil {
+		t.Fatalf("direct profile = %#v", p)
+	}
+
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.Sandbox.Profiles[1].Name = "strict" },
+		func(c *Config) { c.Sandbox.Profiles[0].Name = SandboxDirect },
+		func(c *Config) { c.Sandbox.Profiles[0].Backend = "docker" },
+		func(c *Config) { c.Sandbox.Profiles[0].MemoryMB = -1 },
+		func(c *Config) { c.Sandbox.Profiles[0].Writable = []string{"relative"} },
+		func(c *Config) { c.Sandbox.Default = "missing" },
+		func(c *Config) { c.Agents.Items[0].Sandbox = "missing" },
+		func(c *Config) { c.Projects.Items[0].Sandbox = "missing" },
+	} {
+		bad := cloneConfig(cfg)
+		mutate(bad)
+		if err := bad.ValidateSandbox(); err == nil {
+			t.Fatalf("ValidateSandbox accepted %#v", bad.Sandbox)
+		}
+	}
+}
+
+func TestValidateScheduler(t *testing.T) {
+	cfg := &Config{Scheduler: Scheduler{SMTP: SMTP{Host: "smtp.example.com", Port: 465, From: "fkteams <bot@example.com>", TLS: true}}}
+	if err := cfg.ValidateScheduler(); err != nil {
+		t.Fatalf("ValidateScheduler: %v", err)
+	}
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.Scheduler.SMTP.Port = 70000 },
+		func(c *Config) { c.Scheduler.SMTP.From = "" },
+		func(c *Config) { c.Scheduler.SMTP.From = "not an address" },
+	} {
+		bad := cloneConfig(cfg)
+		mutate(bad)
+		if err := bad.ValidateScheduler(); err == nil {
+			t.Fatalf("ValidateScheduler accepted %#v", bad.Scheduler)
+		}
+	}
+}
+
+func TestValidateMemory(t *testing.T) {
+	cfg := &Config{Memory: Memory{Embedding: MemoryEmbedding{Provider: "ollama", Model: "bge-m3", MinSimilarity: 0.4}}}
+	if err := cfg.ValidateMemory(); err != nil {
+		t.Fatalf("ValidateMemory: %v", err)
+	}
+	for _, mutate := range []func(*Config){
+		func(c *Config) { c.Memory.Embedding.Provider = "word2vec" },
+		func(c *Config) { c.Memory.Embedding.Model = "" },
+		func(c *Config) { c.Memory.Embedding.Dimensions = -1 },
+		func(c *Config) { c.Memory.Embedding.MinSimilarity = 1.5 },
+	} {
+		bad := cloneConfig(cfg)
+		mutate(bad)
+		if err := bad.ValidateMemory(); err == nil {
+			t.Fatalf("ValidateMemory accepted %#v", bad.Memory)
+		}
+	}
+}
+
 func TestDefaultConfigAndGet(t *testing.T) {
 	resetConfigForTest(t)
 
@@ -184,6 +358,7 @@ func TestSaveReloadAndInit(t *testing.T) {
 	}
 
 	globalConfig.Store((*Config)(nil))
+	rawConfig.Store((*Config)(nil))
 	if err := Reload(); err != nil {
 		t.Fatalf("Reload returned error: %v", err)
 	}
@@ -192,6 +367,7 @@ func TestSaveReloadAndInit(t *testing.T) {
 	}
 
 	globalConfig.Store((*Config)(nil))
+	rawConfig.Store((*Config)(nil))
 	configOnce = sync.Once{}
 	configInitErr = nil
 	if err := Init(); err != nil {
@@ -261,7 +437,7 @@ func TestInitAndValidate(t *testing.T) {
 
 func TestLoadAndUnmarshal(t *testing.T) {
 	appDir := resetConfigForTest(t)
-	cfg, err := load()
+	_, cfg, err := load()
 	if err != nil {
 		t.Fatalf("load missing config returned error: %v", err)
 	}
@@ -276,7 +452,7 @@ func TestLoadAndUnmarshal(t *testing.T) {
 	if err := os.WriteFile(configPath, []byte("invalid = ["), 0644); err != nil {
 		t.Fatalf("write invalid config: %v", err)
 	}
-	if _, err := load(); err == nil {
+	if _, _, err := load(); err == nil {
 		t.Fatal("load invalid config should return error")
 	}
 
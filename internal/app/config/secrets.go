@@ -0,0 +1,179 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+
+	"fkteams/internal/app/appdata"
+	"fkteams/internal/runtime/secrets"
+)
+
+// ErrSecretReference 表示配置中的密钥引用无法解析，例如条目不存在或环境变量未设置。
+var ErrSecretReference = errors.New("secret reference cannot be resolved")
+
+// secretField 是可以使用 secret://name 或 ${env:VAR} 引用的敏感字段。
+// path 通过模型、智能体等条目的 ID 或键定位字段，不依赖数组位置；
+// 没有名称的列表项设置 byValue，以解析后的值的摘要区分，增删或调整顺序不会让引用对应到其他条目。
+type secretField struct {
+	path    string
+	byValue bool
+	get     func() string
+	set     func(string)
+}
+
+// id 返回字段的稳定 ID，value 是字段解析后的值。
+func (f secretField) id(value string) string {
+	if !f.byValue {
+		return f.path
+	}
+	sum := sha256.Sum256([]byte(value))
+	return f.path + "." + hex.EncodeToString(sum[:8])
+}
+
+func stringField(path string, value *string) secretField {
+	return secretField{path: path, get: func() string { return *value }, set: func(v string) { *value = v }}
+}
+
+func mapFields(prefix string, values map[string]string) []secretField {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fields := make([]secretField, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, secretField{
+			path: prefix + "." + key,
+			get:  func() string { return values[key] },
+			set:  func(v string) { values[key] = v },
+		})
+	}
+	return fields
+}
+
+// secretFields 列出 cfg 中所有敏感字段，返回的 setter 直接修改 cfg。
+func secretFields(cfg *Config) []secretField {
+	fields := []secretField{
+		stringField("memory.embedding.api_key", &cfg.Memory.Embedding.APIKey),
+		stringField("server.auth.password", &cfg.Server.Auth.Password),
+		stringField("server.auth.secret", &cfg.Server.Auth.Secret),
+		stringField("server.auth.oidc.client_secret", &cfg.Server.Auth.OIDC.ClientSecret),
+		stringField("channels.qq.app_secret", &cfg.Channels.QQ.AppSecret),
+		stringField("channels.discord.token", &cfg.Channels.Discord.Token),
+		stringField("scheduler.webhook_secret", &cfg.Scheduler.WebhookSecret),
+		stringField("scheduler.smtp.password", &cfg.Scheduler.SMTP.Password),
+	}
+	for i := range cfg.Models {
+		fields = append(fields, stringField("models."+cfg.Models[i].ID+".api_key", &cfg.Models[i].APIKey))
+	}
+	for i := range cfg.OpenAIAPI.APIKeys {
+		field := stringField("openai_api.api_keys", &cfg.OpenAIAPI.APIKeys[i])
+		field.byValue = true
+		fields = append(fields, field)
+	}
+	for i := range cfg.Agents.Items {
+		if ssh := cfg.Agents.Items[i].SSH; ssh != nil {
+			fields = append(fields, stringField("agents."+cfg.Agents.Items[i].ID+".ssh.password", &ssh.Password))
+		}
+	}
+	for _, server := range cfg.Tools.MCPServers {
+		fields = append(fields, mapFields("tools.mcp_servers."+server.ID+".env", server.Env)...)
+	}
+	for _, project := range cfg.Projects.Items {
+		fields = append(fields, mapFields("projects."+project.Name+".env", project.Env)...)
+	}
+	for _, hook := range cfg.Hooks {
+		fields = append(fields, mapFields("hooks."+hook.Name+".env", hook.Env)...)
+		fields = append(fields, mapFields("hooks."+hook.Name+".headers", hook.Headers)...)
+	}
+	return fields
+}
+
+// newSecretResolver 返回当前数据目录密钥库的引用解析器，测试中可替换。
+var newSecretResolver = func() *secrets.Resolver {
+	vault := secrets.Open(appdata.SecretsFile(), secrets.Options{Keyring: secrets.SystemKeyring()})
+	return &secrets.Resolver{Vault: vault}
+}
+
+// resolveSecrets 返回解析了全部引用的配置副本，raw 保持不变。
+func resolveSecrets(raw *Config) (*Config, error) {
+	resolved := cloneConfig(raw)
+	var resolver *secrets.Resolver
+	for _, field := range secretFields(resolved) {
+		value := field.get()
+		if !secrets.IsReference(value) {
+			continue
+		}
+		if resolver == nil {
+			resolver = newSecretResolver()
+		}
+		plain, err := resolver.Resolve(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrSecretReference, field.path, err)
+		}
+		field.set(plain)
+	}
+	return resolved, nil
+}
+
+// preserveSecretReferences 把 cfg 中仍等于解析结果的字段还原为配置文件中的引用，
+// 调用方基于 Get 或 Snapshot 修改配置后保存时，引用不会被明文覆盖。
+func preserveSecretReferences(cfg, raw, resolved *Config) {
+	if raw == nil || resolved == nil {
+		return
+	}
+	refs := secretReferences(raw, resolved)
+	if len(refs) == 0 {
+		return
+	}
+	values := make(map[string]string)
+	for _, field := range secretFields(resolved) {
+		values[field.id(field.get())] = field.get()
+	}
+	for _, field := range secretFields(cfg) {
+		id := field.id(field.get())
+		if ref, ok := refs[id]; ok && field.get() == values[id] {
+			field.set(ref)
+		}
+	}
+}
+
+// secretReferences 返回 raw 中使用引用的字段，键为字段 ID。
+// resolved 是 raw 解析后的配置，两者的字段一一对应，列表项的 ID 取自解析后的值。
+func secretReferences(raw, resolved *Config) map[string]string {
+	refs := make(map[string]string)
+	resolvedFields := secretFields(resolved)
+	for i, field := range secretFields(raw) {
+		if value := field.get(); secrets.IsReference(value) && i < len(resolvedFields) {
+			refs[field.id(resolvedFields[i].get())] = value
+		}
+	}
+	return refs
+}
+
+// ApplySecretReferences 将 cfg 中在配置文件里使用引用的字段替换为引用表达式本身。
+// 引用不是敏感信息，配置接口据此在脱敏的同时展示字段的来源。
+// cfg 是当前配置的脱敏副本，列表项按位置对应当前配置中解析后的值。
+func ApplySecretReferences(cfg *Config) {
+	raw, resolved := rawConfig.Load(), globalConfig.Load()
+	if cfg == nil || raw == nil || resolved == nil {
+		return
+	}
+	refs := secretReferences(raw, resolved)
+	if len(refs) == 0 {
+		return
+	}
+	current := secretFields(resolved)
+	for i, field := range secretFields(cfg) {
+		value := field.get()
+		if i < len(current) && current[i].path == field.path {
+			value = current[i].get()
+		}
+		if ref, ok := refs[field.id(value)]; ok {
+			field.set(ref)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fkteams/internal/app/appdata"
+	"fkteams/internal/runtime/env"
+	"fkteams/internal/runtime/secrets"
+)
+
+func TestSecretReferencesResolveAndSurviveSave(t *testing.T) {
+	appDir := resetConfigForTest(t)
+	t.Setenv(env.VaultPassphrase, "vault-passphrase")
+	t.Setenv("FKTEAMS_TEST_MCP_TOKEN", "mcp-token")
+	vault := secrets.Open(appdata.SecretsFile(), secrets.Options{})
+	if err := vault.Set("openai", "sk-from-vault"); err != nil {
+		t.Fatalf("vault set: %v", err)
+	}
+
+	if err := Save(&Config{
+		Models: []ModelConfig{{ID: "main", UseFor: []string{ModelUseChat}, Provider: "openai", APIKey: "secret://openai"}},
+		Tools: ToolSettings{MCPServers: []MCPServer{{
+			ID: "gh", Env: map[string]string{"TOKEN": "Bearer ${env:FKTEAMS_TEST_MCP_TOKEN}", "PLAIN": "value"},
+		}}},
+	}); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	if got := Get().Models[0].APIKey; got != "sk-from-vault" {
+		t.Fatalf("resolved api key = %q", got)
+	}
+	if got := Get().Tools.MCPServers[0].Env["TOKEN"]; got != "Bearer mcp-token" {
+		t.Fatalf("resolved mcp env = %q", got)
+	}
+
+	// 基于已解析快照修改其他字段后保存，未改变的敏感字段仍写回引用
+	cfg := Snapshot()
+	cfg.Server.Port = 4321
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save snapshot returned error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(appDir, "config", "config.toml"))
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if text := string(data); strings.Contains(text, "sk-from-vault") || strings.Contains(text, "mcp-token") ||
+		!strings.Contains(text, "secret://openai") || !strings.Contains(text, "${env:FKTEAMS_TEST_MCP_TOKEN}") {
+		t.Fatalf("config file leaks secrets or lost references:\n%s", text)
+	}
+
+	display := Snapshot()
+	ApplySecretReferences(display)
+	if display.Models[0].APIKey != "secret://openai" || display.Tools.MCPServers[0].Env["PLAIN"] != "value" {
+		t.Fatalf("display config = %#v", display.Models[0])
+	}
+
+	// 显式修改的值替换引用
+	cfg = Snapshot()
+	cfg.Models[0].APIKey = "sk-plain"
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save plain returned error: %v", err)
+	}
+	if err := Reload(); err != nil {
+		t.Fatalf("Reload returned error: %v", err)
+	}
+	if got := Get().Models[0].APIKey; got != "sk-plain" {
+		t.Fatalf("reloaded api key = %q", got)
+	}
+}
+
+func TestSaveRejectsUnresolvableReference(t *testing.T) {
+	appDir := resetConfigForTest(t)
+	t.Setenv(env.VaultPassphrase, "vault-passphrase")
+	err := Save(&Config{Server: Server{Auth: ServerAuth{Password: "secret://missing"}}})
+	if err == nil || !strings.Contains(err.Error(), "server.auth.password") {
+		t.Fatalf("Save error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(appDir, "config", "config.toml")); !os.IsNotExist(err) {
+		t.Fatalf("config should not be written, stat err = %v", err)
+	}
+}
+
+func TestAPIKeyReferencesFollowEntriesWhenListChanges(t *testing.T) {
+	appDir := resetConfigForTest(t)
+	t.Setenv(env.VaultPassphrase, "vault-passphrase")
+	vault := secrets.Open(appdata.SecretsFile(), secrets.Options{})
+	for name, value := range map[string]string{"key-a": "sk-a", "key-b": "sk-b"} {
+		if err := vault.Set(name, value); err != nil {
+			t.Fatalf("vault set: %v", err)
+		}
+	}
+	if err := Save(&Config{OpenAIAPI: OpenAIAPI{APIKeys: []string{"secret://key-a", "secret://key-b"}}}); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	// 删除第一个密钥后，剩下的条目仍写回自己的引用，不会以明文保存或对应到其他引用
+	cfg := Snapshot()
+	cfg.OpenAIAPI.APIKeys = []string{cfg.OpenAIAPI.APIKeys[1], "sk-new"}
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(appDir, "config", "config.toml"))
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if text := string(data); strings.Contains(text, "sk-b") || strings.Contains(text, "secret://key-a") ||
+		!strings.Contains(text, "secret://key-b") || !strings.Contains(text, "sk-new") {
+		t.Fatalf("config file after removal:\n%s", text)
+	}
+	if got := Get().OpenAIAPI.APIKeys; len(got) != 2 || got[0] != "sk-b" || got[1] != "sk-new" {
+		t.Fatalf("resolved api keys = %q", got)
+	}
+
+	display := Snapshot()
+	display.OpenAIAPI.APIKeys = []string{"sk-b***", "sk-n***"}
+	ApplySecretReferences(display)
+	if got := display.OpenAIAPI.APIKeys; got[0] != "secret://key-b" || got[1] != "sk-n***" {
+		t.Fatalf("display api keys = %q", got)
+	}
+}
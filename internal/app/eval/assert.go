@@ -0,0 +1,118 @@
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// check 对一次运行结果执行场景断言，返回全部失败原因。
+func (e Expect) check(workspace string, calls []ToolCall, text string) []string {
+	var failures []string
+	next := 0
+	for _, want := range e.ToolCalls {
+		found := false
+		for next < len(calls) {
+			call := calls[next]
+			next++
+			if want.matches(call) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			failures = append(failures, fmt.Sprintf("expected tool call %s was not made (in order); actual calls: %s", want.describe(), describeCalls(calls)))
+			break
+		}
+	}
+	for _, name := range e.NoTools {
+		if slices.ContainsFunc(calls, func(call ToolCall) bool { return call.Name == name }) {
+			failures = append(failures, fmt.Sprintf("tool %s must not be called", name))
+		}
+	}
+
+	for _, want := range e.Text.Contains {
+		if !strings.Contains(text, want) {
+			failures = append(failures, fmt.Sprintf("final text does not contain %q", want))
+		}
+	}
+	for _, unwanted := range e.Text.NotContains {
+		if strings.Contains(text, unwanted) {
+			failures = append(failures, fmt.Sprintf("final text contains %q", unwanted))
+		}
+	}
+	if e.Text.Matches != "" && !regexp.MustCompile(e.Text.Matches).MatchString(text) {
+		failures = append(failures, fmt.Sprintf("final text does not match /%s/", e.Text.Matches))
+	}
+
+	for _, file := range e.Files {
+		failures = append(failures, file.check(workspace)...)
+	}
+	return failures
+}
+
+func (w ToolCallExpect) matches(call ToolCall) bool {
+	if call.Name != w.Name || (w.Agent != "" && call.Agent != w.Agent) {
+		return false
+	}
+	for _, fragment := range w.ArgsContain {
+		if !strings.Contains(call.Arguments, fragment) {
+			return false
+		}
+	}
+	return true
+}
+
+func (w ToolCallExpect) describe() string {
+	desc := w.Name
+	if w.Agent != "" {
+		desc = w.Agent + "/" + desc
+	}
+	if len(w.ArgsContain) > 0 {
+		desc += fmt.Sprintf(" with args containing %q", w.ArgsContain)
+	}
+	return desc
+}
+
+func describeCalls(calls []ToolCall) string {
+	if len(calls) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(calls))
+	for _, call := range calls {
+		names = append(names, call.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (f FileExpect) check(workspace string) []string {
+	data, err := os.ReadFile(filepath.Join(workspace, filepath.FromSlash(f.Path)))
+	if f.Absent {
+		if err == nil {
+			return []string{fmt.Sprintf("file %s should not exist", f.Path)}
+		}
+		return nil
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return []string{fmt.Sprintf("file %s does not exist", f.Path)}
+	}
+	if err != nil {
+		return []string{fmt.Sprintf("read file %s: %v", f.Path, err)}
+	}
+	var failures []string
+	content := string(data)
+	for _, want := range f.Contains {
+		if !strings.Contains(content, want) {
+			failures = append(failures, fmt.Sprintf("file %s does not contain %q", f.Path, want))
+		}
+	}
+	if f.Equals != nil && content != *f.Equals {
+		failures = append(failures, fmt.Sprintf("file %s content differs from expected", f.Path))
+	}
+	return failures
+}
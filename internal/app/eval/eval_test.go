@@ -0,0 +1,200 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fkteams/internal/app/project"
+	"fkteams/internal/domain/event"
+	"fkteams/internal/domain/message"
+	runtimeport "fkteams/internal/ports/runtime"
+	modelregistry "fkteams/internal/runtime/model"
+	"fkteams/internal/testmodel"
+)
+
+const suiteYAML = `
+mode: team
+scenarios:
+  - name: write-note
+    input: 把 README 的标题写进 note.txt
+    files:
+      README.md: "# Hello"
+    expect:
+      tool_calls:
+        - name: file_read
+          args_contain: [README.md]
+        - name: file_write
+      no_tools: [execute]
+      text:
+        contains: [完成]
+        matches: "note\\.txt"
+      files:
+        - path: note.txt
+          equals: Hello
+        - path: scratch.txt
+          absent: true
+`
+
+func writeSuite(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "smoke.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadSuiteAppliesDefaultsAndValidates(t *testing.T) {
+	path := writeSuite(t, suiteYAML)
+	suite, err := LoadSuite(path)
+	if err != nil {
+		t.Fatalf("LoadSuite: %v", err)
+	}
+	if suite.Name != "smoke" || len(suite.Scenarios) != 1 {
+		t.Fatalf("suite = %#v", suite)
+	}
+	if got, want := suite.CassettePath(suite.Scenarios[0]), filepath.Join(filepath.Dir(path), "cassettes", "write-note.json"); got != want {
+		t.Fatalf("cassette path = %s, want %s", got, want)
+	}
+	if err := suite.Select([]string{"missing"}); err == nil {
+		t.Fatal("expected unknown scenario error")
+	}
+
+	for name, content := range map[string]string{
+		"unknown field": "scenarios:\n  - name: a\n    input: hi\n    expct: {}\n",
+		"duplicate":     "scenarios:\n  - {name: a, input: hi}\n  - {name: a, input: hi}\n",
+		"empty input":   "scenarios:\n  - {name: a}\n",
+		"escaping file": "scenarios:\n  - name: a\n    input: hi\n    files: {\"../x\": y}\n",
+		"bad regexp":    "scenarios:\n  - name: a\n    input: hi\n    expect: {text: {matches: \"(\"}}\n",
+		"bad timeout":   "timeout: soon\nscenarios:\n  - {name: a, input: hi}\n",
+	} {
+		if _, err := LoadSuite(writeSuite(t, content)); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+// scriptedRunner 通过上下文中的模型注册表创建模型，按模型回答模拟一次带工具调用的运行。
+type scriptedRunner struct{}
+
+func (scriptedRunner) Run(ctx context.Context, _ message.TurnInput, opts runtimeport.RunOptions) (*runtimeport.RunResult, error) {
+	registry, err := modelregistry.RequireRegistry(ctx)
+	if err != nil {
+		return nil, err
+	}
+	model, err := registry.NewChatModel(ctx, &modelregistry.Config{ID: "main", Provider: modelregistry.OpenAI})
+	if err != nil {
+		return nil, err
+	}
+	reply, err := model.Generate(ctx, []message.Message{testmodel.UserMessage("go")})
+	if err != nil {
+		return nil, err
+	}
+	workspace := project.WorkspaceDir(ctx)
+	title, err := os.ReadFile(filepath.Join(workspace, "README.md"))
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "note.txt"), bytes.TrimPrefix(title, []byte("# ")), 0644); err != nil {
+		return nil, err
+	}
+	for _, e := range []event.Event{
+		{Type: event.TypeToolCallStarted, ToolName: "file_read", ToolArgs: `{"path":"README.md"}`},
+		{Type: event.TypeToolCallStarted, ToolName: "file_write", ToolArgs: `{"path":"note.txt"}`},
+		{Type: event.TypeAssistantStarted},
+		{Type: event.TypeAssistantText, Content: reply.Content, DeltaKind: event.DeltaOutput},
+		{Type: event.TypeAssistantCompleted},
+	} {
+		if err := opts.Sink(e); err != nil {
+			return nil, err
+		}
+	}
+	return &runtimeport.RunResult{}, nil
+}
+
+func TestExecutorRecordsThenReplaysScenario(t *testing.T) {
+	suite, err := LoadSuite(writeSuite(t, suiteYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolve := func(context.Context, string, string) (runtimeport.Runner, error) { return scriptedRunner{}, nil }
+
+	live := modelregistry.NewRegistry()
+	live.Register(modelregistry.OpenAI, func(context.Context, *modelregistry.Config) (runtimeport.ChatModel, error) {
+		return testmodel.New(testmodel.AssistantMessage("已完成，标题写入 note.txt")), nil
+	})
+	report := NewExecutor(resolve, CassetteRecord).Run(modelregistry.WithRegistry(context.Background(), live), suite)
+	if !report.OK() {
+		t.Fatalf("record report = %#v", report.Scenarios)
+	}
+	if _, err := os.Stat(suite.CassettePath(suite.Scenarios[0])); err != nil {
+		t.Fatalf("cassette not written: %v", err)
+	}
+
+	// 回放时真实模型不可用，结果必须完全来自磁带。
+	offline := modelregistry.NewRegistry()
+	offline.Register(modelregistry.OpenAI, func(context.Context, *modelregistry.Config) (runtimeport.ChatModel, error) {
+		return nil, fmt.Errorf("network disabled")
+	})
+	report = NewExecutor(resolve, CassetteReplay).Run(modelregistry.WithRegistry(context.Background(), offline), suite)
+	if !report.OK() {
+		t.Fatalf("replay report = %#v", report.Scenarios)
+	}
+	result := report.Scenarios[0]
+	if len(result.ToolCalls) != 2 || result.FinalText != "已完成，标题写入 note.txt" {
+		t.Fatalf("replay result = %#v", result)
+	}
+}
+
+func TestExecutorReportsAssertionFailuresAndMissingCassette(t *testing.T) {
+	suite, err := LoadSuite(writeSuite(t, strings.Replace(suiteYAML, "equals: Hello", "equals: Bye", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolve := func(context.Context, string, string) (runtimeport.Runner, error) { return scriptedRunner{}, nil }
+
+	report := NewExecutor(resolve, CassetteReplay).Run(context.Background(), suite)
+	if report.Errors != 1 || !strings.Contains(report.Scenarios[0].Error, "--record") {
+		t.Fatalf("missing cassette report = %#v", report.Scenarios)
+	}
+
+	registry := modelregistry.NewRegistry()
+	registry.Register(modelregistry.OpenAI, func(context.Context, *modelregistry.Config) (runtimeport.ChatModel, error) {
+		return testmodel.New(testmodel.AssistantMessage("写好了")), nil
+	})
+	report = NewExecutor(resolve, CassetteLive).Run(modelregistry.WithRegistry(context.Background(), registry), suite)
+	if report.Failed != 1 {
+		t.Fatalf("live report = %#v", report)
+	}
+	failures := strings.Join(report.Scenarios[0].Failures, "\n")
+	for _, want := range []string{`does not contain "完成"`, "does not match", "note.txt content differs"} {
+		if !strings.Contains(failures, want) {
+			t.Fatalf("failures missing %q:\n%s", want, failures)
+		}
+	}
+
+	var junit bytes.Buffer
+	if err := report.WriteJUnit(&junit); err != nil {
+		t.Fatal(err)
+	}
+	var doc junitTestSuites
+	if err := xml.Unmarshal(junit.Bytes(), &doc); err != nil {
+		t.Fatalf("parse junit: %v\n%s", err, junit.String())
+	}
+	if doc.Failures != 1 || doc.Suites[0].Cases[0].Failure == nil {
+		t.Fatalf("junit = %s", junit.String())
+	}
+	var jsonOut bytes.Buffer
+	if err := report.WriteJSON(&jsonOut); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(jsonOut.String(), `"duration_ms"`) || !strings.Contains(jsonOut.String(), `"failed": 1`) {
+		t.Fatalf("json report = %s", jsonOut.String())
+	}
+}
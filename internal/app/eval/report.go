@@ -0,0 +1,169 @@
+package eval
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ToolCall 是场景运行中实际发生的一次工具调用。
+type ToolCall struct {
+	Agent     string `json:"agent,omitempty"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments,omitempty"`
+}
+
+// ScenarioResult 是单个场景的运行结果；Error 表示运行本身失败，Failures 表示断言未通过。
+type ScenarioResult struct {
+	Name      string        `json:"name"`
+	Passed    bool          `json:"passed"`
+	Duration  time.Duration `json:"-"`
+	Cassette  string        `json:"cassette,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Failures  []string      `json:"failures,omitempty"`
+	ToolCalls []ToolCall    `json:"tool_calls,omitempty"`
+	FinalText string        `json:"final_text,omitempty"`
+}
+
+// Report 汇总一次套件运行。
+type Report struct {
+	Suite        string           `json:"suite"`
+	CassetteMode CassetteMode     `json:"cassette_mode"`
+	StartedAt    time.Time        `json:"started_at"`
+	Duration     time.Duration    `json:"-"`
+	Total        int              `json:"total"`
+	Passed       int              `json:"passed"`
+	Failed       int              `json:"failed"`
+	Errors       int              `json:"errors"`
+	Scenarios    []ScenarioResult `json:"scenarios"`
+}
+
+func (r *Report) add(result ScenarioResult) {
+	r.Scenarios = append(r.Scenarios, result)
+	r.Total++
+	switch {
+	case result.Error != "":
+		r.Errors++
+	case result.Passed:
+		r.Passed++
+	default:
+		r.Failed++
+	}
+}
+
+// OK 报告所有场景都通过时返回 true。
+func (r *Report) OK() bool {
+	return r.Total > 0 && r.Passed == r.Total
+}
+
+// MarshalJSON 将时长输出为毫秒，便于 CI 读取。
+func (r ScenarioResult) MarshalJSON() ([]byte, error) {
+	type alias ScenarioResult
+	return json.Marshal(struct {
+		alias
+		Duration int64 `json:"duration_ms"`
+	}{alias(r), r.Duration.Milliseconds()})
+}
+
+// MarshalJSON 将时长输出为毫秒，便于 CI 读取。
+func (r Report) MarshalJSON() ([]byte, error) {
+	type alias Report
+	return json.Marshal(struct {
+		alias
+		Duration int64 `json:"duration_ms"`
+	}{alias(r), r.Duration.Milliseconds()})
+}
+
+// WriteJSON 以 JSON 格式输出报告。
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit 以 JUnit XML 格式输出报告，每个场景对应一个 testcase。
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:      r.Suite,
+		Tests:     r.Total,
+		Failures:  r.Failed,
+		Errors:    r.Errors,
+		Time:      seconds(r.Duration),
+		Timestamp: r.StartedAt.Format(time.RFC3339),
+	}
+	for _, result := range r.Scenarios {
+		tc := junitTestCase{
+			Name:      result.Name,
+			ClassName: r.Suite,
+			Time:      seconds(result.Duration),
+			SystemOut: result.FinalText,
+		}
+		switch {
+		case result.Error != "":
+			tc.Error = &junitMessage{Message: result.Error, Type: "error", Text: result.Error}
+		case len(result.Failures) > 0:
+			tc.Failure = &junitMessage{Message: result.Failures[0], Type: "assertion", Text: strings.Join(result.Failures, "\n")}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	doc := junitTestSuites{
+		Name:     r.Suite,
+		Tests:    r.Total,
+		Failures: r.Failed,
+		Errors:   r.Errors,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,218 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	appchat "fkteams/internal/app/chat"
+	"fkteams/internal/app/project"
+	"fkteams/internal/domain/event"
+	"fkteams/internal/domain/message"
+	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/cassette"
+	modelregistry "fkteams/internal/runtime/model"
+)
+
+// CassetteMode 决定场景运行时模型交互的来源。
+type CassetteMode string
+
+const (
+	// CassetteReplay 从磁带回放模型交互，不访问真实模型。
+	CassetteReplay CassetteMode = "replay"
+	// CassetteRecord 调用真实模型并将交互写入磁带。
+	CassetteRecord CassetteMode = "record"
+	// CassetteLive 直接调用真实模型，不读写磁带。
+	CassetteLive CassetteMode = "live"
+)
+
+// ParseCassetteMode 解析磁带模式，空字符串视为回放。
+func ParseCassetteMode(value string) (CassetteMode, error) {
+	switch mode := CassetteMode(value); mode {
+	case "":
+		return CassetteReplay, nil
+	case CassetteReplay, CassetteRecord, CassetteLive:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown cassette mode %q (replay|record|live)", value)
+	}
+}
+
+// RunnerResolver 按工作模式或智能体名创建一次性 Runner。
+type RunnerResolver func(ctx context.Context, mode, agentName string) (runtimeport.Runner, error)
+
+// Executor 逐个运行套件中的场景，每个场景使用独立的临时工作区。
+type Executor struct {
+	resolve  RunnerResolver
+	mode     CassetteMode
+	chat     *appchat.Service
+	onResult func(ScenarioResult)
+}
+
+// NewExecutor 创建评测执行器。
+func NewExecutor(resolve RunnerResolver, mode CassetteMode) *Executor {
+	return &Executor{resolve: resolve, mode: mode, chat: appchat.NewService()}
+}
+
+// OnResult 设置每个场景完成后的回调，用于输出进度。
+func (e *Executor) OnResult(fn func(ScenarioResult)) *Executor {
+	e.onResult = fn
+	return e
+}
+
+// Run 运行套件中的全部场景；单个场景失败不会中断后续场景。
+func (e *Executor) Run(ctx context.Context, suite *Suite) *Report {
+	report := &Report{Suite: suite.Name, CassetteMode: e.mode, StartedAt: time.Now()}
+	for _, sc := range suite.Scenarios {
+		if ctx.Err() != nil {
+			break
+		}
+		result := e.runScenario(ctx, suite, sc)
+		report.add(result)
+		if e.onResult != nil {
+			e.onResult(result)
+		}
+	}
+	report.Duration = time.Since(report.StartedAt)
+	return report
+}
+
+func (e *Executor) runScenario(ctx context.Context, suite *Suite, sc Scenario) ScenarioResult {
+	start := time.Now()
+	result := ScenarioResult{Name: sc.Name}
+	if e.mode != CassetteLive {
+		result.Cassette = suite.CassettePath(sc)
+	}
+	finish := func(err error) ScenarioResult {
+		if err != nil {
+			result.Error = err.Error()
+		}
+		result.Passed = result.Error == "" && len(result.Failures) == 0
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	workspace, err := os.MkdirTemp("", "fkteams-eval-")
+	if err != nil {
+		return finish(fmt.Errorf("create workspace: %w", err))
+	}
+	defer os.RemoveAll(workspace)
+	if err := writeFiles(workspace, sc.Files); err != nil {
+		return finish(err)
+	}
+	ctx = project.WithProject(ctx, project.Project{Name: "eval", Root: workspace, Description: suite.Name})
+
+	var recorder *cassette.Recorder
+	var player *cassette.Player
+	switch e.mode {
+	case CassetteReplay:
+		c, err := cassette.Load(result.Cassette)
+		if errors.Is(err, fs.ErrNotExist) {
+			return finish(fmt.Errorf("cassette %s not found; run with --record to create it", result.Cassette))
+		}
+		if err != nil {
+			return finish(err)
+		}
+		player = cassette.NewPlayer(c)
+		ctx = modelregistry.WithInterceptor(ctx, player.Interceptor())
+	case CassetteRecord:
+		recorder = cassette.NewRecorder()
+		ctx = modelregistry.WithInterceptor(ctx, recorder.Interceptor())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, suite.timeoutFor(sc))
+	defer cancel()
+
+	mode, agentName := suite.modeFor(sc)
+	if e.resolve == nil {
+		return finish(fmt.Errorf("create runner: resolver is nil"))
+	}
+	runner, err := e.resolve(ctx, mode, agentName)
+	if err != nil {
+		return finish(fmt.Errorf("create runner: %w", err))
+	}
+
+	collector := &collector{}
+	_, runErr := e.chat.RunTurn(ctx, appchat.TurnRequest{
+		SessionID:      "fkteams_eval_" + sc.Name,
+		Runner:         runner,
+		Input:          message.TurnInput{Message: message.Message{Role: message.RoleUser, Content: sc.Input}},
+		EventSink:      collector.handle,
+		NonInteractive: true,
+	})
+	result.ToolCalls, result.FinalText = collector.result()
+
+	// 运行失败时也保存已录制的交互，便于排查是哪一步出错。
+	if recorder != nil {
+		if err := recorder.Cassette().Save(result.Cassette); err != nil {
+			runErr = errors.Join(runErr, fmt.Errorf("save cassette: %w", err))
+		}
+	}
+	if runErr != nil {
+		return finish(runErr)
+	}
+	if player != nil {
+		if remaining := player.Remaining(); remaining > 0 {
+			result.Failures = append(result.Failures, fmt.Sprintf("%d recorded model interaction(s) were not replayed; re-record the cassette", remaining))
+		}
+	}
+	result.Failures = append(result.Failures, sc.Expect.check(workspace, result.ToolCalls, result.FinalText)...)
+	return finish(nil)
+}
+
+func writeFiles(workspace string, files map[string]string) error {
+	for path, content := range files {
+		full := filepath.Join(workspace, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			return fmt.Errorf("prepare workspace file %s: %w", path, err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			return fmt.Errorf("prepare workspace file %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// collector 从运行事件中收集工具调用和最后一条助手回答；并行成员可能并发上报事件。
+type collector struct {
+	mu        sync.Mutex
+	calls     []ToolCall
+	current   strings.Builder
+	finalText string
+}
+
+func (c *collector) handle(e event.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch e.Type {
+	case event.TypeToolCallStarted:
+		c.calls = append(c.calls, ToolCall{Agent: e.AgentName, Name: e.ToolName, Arguments: e.ToolArgs})
+	case event.TypeAssistantStarted:
+		c.current.Reset()
+	case event.TypeAssistantText:
+		if e.DeltaKind == "" || e.DeltaKind == event.DeltaOutput {
+			c.current.WriteString(e.Content)
+		}
+	case event.TypeAssistantCompleted:
+		text := c.current.String()
+		if text == "" {
+			text = e.Content
+		}
+		if strings.TrimSpace(text) != "" {
+			c.finalText = text
+		}
+	}
+	return nil
+}
+
+func (c *collector) result() ([]ToolCall, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ToolCall(nil), c.calls...), c.finalText
+}
@@ -0,0 +1,201 @@
+// Package eval 按 YAML 场景端到端运行智能体，并对工具调用、最终回答和工作区文件做断言。
+package eval
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	domainsession "fkteams/internal/domain/session"
+
+	"github.com/goccy/go-yaml"
+)
+
+// DefaultTimeout 是场景未配置超时时的单场景超时时间。
+const DefaultTimeout = 5 * time.Minute
+
+// Suite 是一组评测场景，场景未指定的模式、智能体和超时继承自套件。
+type Suite struct {
+	Name      string     `yaml:"name"`
+	Mode      string     `yaml:"mode"`
+	Agent     string     `yaml:"agent"`
+	Timeout   string     `yaml:"timeout"`
+	Cassettes string     `yaml:"cassettes"` // 磁带目录，相对套件文件所在目录，默认 cassettes
+	Scenarios []Scenario `yaml:"scenarios"`
+
+	dir string
+}
+
+// Scenario 是一次单轮对话评测。
+type Scenario struct {
+	Name    string            `yaml:"name"`
+	Input   string            `yaml:"input"`
+	Mode    string            `yaml:"mode"`
+	Agent   string            `yaml:"agent"`
+	Timeout string            `yaml:"timeout"`
+	Files   map[string]string `yaml:"files"` // 运行前写入临时工作区的文件，键为相对路径
+	Expect  Expect            `yaml:"expect"`
+}
+
+// Expect 描述场景运行结束后的断言。
+type Expect struct {
+	// ToolCalls 必须按顺序出现在实际工具调用中，中间允许夹杂其他调用。
+	ToolCalls []ToolCallExpect `yaml:"tool_calls"`
+	// NoTools 列出不允许被调用的工具。
+	NoTools []string     `yaml:"no_tools"`
+	Text    TextExpect   `yaml:"text"`
+	Files   []FileExpect `yaml:"files"`
+}
+
+// ToolCallExpect 匹配一次工具调用，智能体和参数片段为空时不参与匹配。
+type ToolCallExpect struct {
+	Name        string   `yaml:"name"`
+	Agent       string   `yaml:"agent"`
+	ArgsContain []string `yaml:"args_contain"`
+}
+
+// TextExpect 对最终回答做断言。
+type TextExpect struct {
+	Contains    []string `yaml:"contains"`
+	NotContains []string `yaml:"not_contains"`
+	Matches     string   `yaml:"matches"`
+}
+
+// FileExpect 对运行后工作区中的文件做断言。
+type FileExpect struct {
+	Path     string   `yaml:"path"`
+	Absent   bool     `yaml:"absent"`
+	Contains []string `yaml:"contains"`
+	Equals   *string  `yaml:"equals"`
+}
+
+// LoadSuite 读取并校验评测套件文件。
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var suite Suite
+	if err := yaml.UnmarshalWithOptions(data, &suite, yaml.DisallowUnknownField()); err != nil {
+		return nil, fmt.Errorf("parse eval suite %s: %s", path, yaml.FormatError(err, false, true))
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	suite.dir = filepath.Dir(absPath)
+	if suite.Name == "" {
+		suite.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if suite.Cassettes == "" {
+		suite.Cassettes = "cassettes"
+	}
+	if err := suite.Validate(); err != nil {
+		return nil, fmt.Errorf("eval suite %s: %w", path, err)
+	}
+	return &suite, nil
+}
+
+// Validate 检查场景名、超时、正则和文件路径。
+func (s *Suite) Validate() error {
+	if len(s.Scenarios) == 0 {
+		return fmt.Errorf("no scenarios defined")
+	}
+	if _, err := parseTimeout(s.Timeout); err != nil {
+		return fmt.Errorf("timeout: %w", err)
+	}
+	seen := make(map[string]struct{}, len(s.Scenarios))
+	for i, sc := range s.Scenarios {
+		if !domainsession.ValidID(sc.Name) {
+			return fmt.Errorf("scenario #%d: invalid name %q", i+1, sc.Name)
+		}
+		if _, ok := seen[sc.Name]; ok {
+			return fmt.Errorf("duplicate scenario %q", sc.Name)
+		}
+		seen[sc.Name] = struct{}{}
+		if strings.TrimSpace(sc.Input) == "" {
+			return fmt.Errorf("scenario %s: input is empty", sc.Name)
+		}
+		if _, err := parseTimeout(sc.Timeout); err != nil {
+			return fmt.Errorf("scenario %s: timeout: %w", sc.Name, err)
+		}
+		for path := range sc.Files {
+			if !filepath.IsLocal(path) {
+				return fmt.Errorf("scenario %s: file %q must be a relative path inside the workspace", sc.Name, path)
+			}
+		}
+		for _, call := range sc.Expect.ToolCalls {
+			if call.Name == "" {
+				return fmt.Errorf("scenario %s: expected tool call without name", sc.Name)
+			}
+		}
+		if sc.Expect.Text.Matches != "" {
+			if _, err := regexp.Compile(sc.Expect.Text.Matches); err != nil {
+				return fmt.Errorf("scenario %s: text.matches: %w", sc.Name, err)
+			}
+		}
+		for _, file := range sc.Expect.Files {
+			if !filepath.IsLocal(file.Path) {
+				return fmt.Errorf("scenario %s: expected file %q must be a relative path inside the workspace", sc.Name, file.Path)
+			}
+		}
+	}
+	return nil
+}
+
+// Select 只保留指定名称的场景，names 为空时保留全部。
+func (s *Suite) Select(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(s.Scenarios, func(sc Scenario) bool { return sc.Name == name }) {
+			return fmt.Errorf("scenario not found: %s", name)
+		}
+	}
+	s.Scenarios = slices.DeleteFunc(s.Scenarios, func(sc Scenario) bool { return !slices.Contains(names, sc.Name) })
+	return nil
+}
+
+// CassettePath 返回场景对应的磁带文件路径。
+func (s *Suite) CassettePath(sc Scenario) string {
+	dir := s.Cassettes
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(s.dir, dir)
+	}
+	return filepath.Join(dir, sc.Name+".json")
+}
+
+func (s *Suite) modeFor(sc Scenario) (mode, agent string) {
+	if sc.Mode != "" || sc.Agent != "" {
+		return sc.Mode, sc.Agent
+	}
+	return s.Mode, s.Agent
+}
+
+func (s *Suite) timeoutFor(sc Scenario) time.Duration {
+	for _, value := range []string{sc.Timeout, s.Timeout} {
+		if timeout, _ := parseTimeout(value); timeout > 0 {
+			return timeout
+		}
+	}
+	return DefaultTimeout
+}
+
+func parseTimeout(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return timeout, nil
+}
@@ -73,6 +73,8 @@ func ExecutePendingRestart() error {
 		return err
 	}
 	cmd := exec.Command(executable, os.Args[1:]...)
+	// 启动时已从进程环境移除的密钥库口令需要显式传给新进程
+	cmd.Env = append(os.Environ(), env.SecretEnviron()...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
@@ -0,0 +1,216 @@
+package memory
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"fkteams/internal/domain/apperror"
+	"fkteams/internal/runtime/log"
+)
+
+const (
+	maxSummaryRunes = 200
+	maxDetailRunes  = 2_000
+	maxEntryTags    = 10
+)
+
+// Get 返回当前对话可见的指定 ID 记忆条目
+func (m *Manager) Get(id string, scope ScopeContext) (MemoryEntry, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	idx := m.indexOf(id)
+	if idx < 0 || !visibleIn(m.entries[idx], scope) {
+		return MemoryEntry{}, false
+	}
+	return m.entries[idx], true
+}
+
+// Find 按查询和过滤条件检索当前对话可见的记忆，供智能体主动查阅
+// query 为空时不做相关性排序，按创建时间倒序列出命中过滤条件的记忆
+func (m *Manager) Find(query string, topK int, scope ScopeContext, filter SearchFilter) []MemoryEntry {
+	if strings.TrimSpace(query) != "" {
+		return m.search(query, topK, scope, filter)
+	}
+	m.mu.RLock()
+	var entries []MemoryEntry
+	for _, e := range m.entries {
+		if visibleIn(e, scope) && filter.Match(e) {
+			entries = append(entries, e)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].CreatedAt.After(entries[j].CreatedAt) })
+	if topK > 0 && len(entries) > topK {
+		entries = entries[:topK]
+	}
+	return entries
+}
+
+// Save 主动保存一条记忆，与自动提取共用去重和容量淘汰逻辑
+// 合并到相近条目时保留原条目的 ID 和命中统计，返回实际保存的条目
+func (m *Manager) Save(entry MemoryEntry) (MemoryEntry, SaveAction, error) {
+	entry, err := normalizeEntry(entry)
+	if err != nil {
+		return MemoryEntry{}, "", err
+	}
+	now := time.Now()
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = now
+	}
+	if entry.ID == "" {
+		prefix := entry.SessionID
+		if prefix == "" {
+			prefix = "manual"
+		}
+		entry.ID = fmt.Sprintf("%s_%d", prefix, now.UnixNano())
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var action SaveAction
+	dup, idx := m.checkDuplicate(entry)
+	switch dup {
+	case actionSkip:
+		return m.entries[idx], SaveSkipped, nil
+	case actionUpdate:
+		existing := m.entries[idx]
+		entry.ID = existing.ID
+		entry.HitCount = existing.HitCount
+		entry.LastHitAt = existing.LastHitAt
+		m.entries[idx] = entry
+		action = SaveUpdated
+	default:
+		m.entries = append(m.entries, entry)
+		action = SaveAdded
+	}
+
+	m.evictIfNeeded()
+	m.rebuildIndex()
+	if err := m.save(); err != nil {
+		return entry, action, fmt.Errorf("save memory: %w", err)
+	}
+	log.Infof("[memory] saved entry %s (%s, total: %d)", entry.ID, action, len(m.entries))
+	return entry, action, nil
+}
+
+// Update 修改指定 ID 的记忆条目并刷新创建时间
+// 修改后与同类型、同作用域的相近条目合并，返回修改或合并后的条目
+func (m *Manager) Update(id string, patch EntryPatch) (MemoryEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pos := m.indexOf(id)
+	if pos < 0 {
+		return MemoryEntry{}, apperror.Errorf(apperror.CodeNotFound, "memory %q not found", id)
+	}
+	entry := m.entries[pos]
+	if patch.Type != "" {
+		entry.Type = patch.Type
+	}
+	if patch.Summary != "" {
+		entry.Summary = patch.Summary
+	}
+	if patch.Detail != nil {
+		entry.Detail = *patch.Detail
+	}
+	if patch.Tags != nil {
+		entry.Tags = patch.Tags
+	}
+	entry, err := normalizeEntry(entry)
+	if err != nil {
+		return MemoryEntry{}, err
+	}
+	entry.CreatedAt = time.Now()
+
+	m.entries = slices.Delete(m.entries, pos, pos+1)
+	dup, idx := m.checkDuplicate(entry)
+	if dup == actionAdd {
+		m.entries = slices.Insert(m.entries, pos, entry)
+		idx = pos
+	} else {
+		m.mergeLocked(idx, entry)
+	}
+	updated := m.entries[idx]
+
+	m.rebuildIndex()
+	if err := m.save(); err != nil {
+		return updated, fmt.Errorf("save memory: %w", err)
+	}
+	return updated, nil
+}
+
+// Forget 删除指定 ID 的记忆条目，返回被删除的条目
+func (m *Manager) Forget(id string) (MemoryEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pos := m.indexOf(id)
+	if pos < 0 {
+		return MemoryEntry{}, apperror.Errorf(apperror.CodeNotFound, "memory %q not found", id)
+	}
+	removed := m.entries[pos]
+	m.entries = slices.Delete(m.entries, pos, pos+1)
+	m.rebuildIndex()
+	if err := m.save(); err != nil {
+		return removed, fmt.Errorf("save memory: %w", err)
+	}
+	return removed, nil
+}
+
+func (m *Manager) indexOf(id string) int {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return -1
+	}
+	for i := range m.entries {
+		if m.entries[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// normalizeEntry 校验主动写入的条目，并把文本整理为单行以保持 Markdown 存储格式
+func normalizeEntry(entry MemoryEntry) (MemoryEntry, error) {
+	if !AllMemoryTypes[entry.Type] {
+		return entry, apperror.Errorf(apperror.CodeInvalidArgument, "invalid memory type %q", entry.Type)
+	}
+	entry.Scope = entry.Scope.Normalize()
+	if err := entry.Scope.Validate(); err != nil {
+		return entry, apperror.Wrap(apperror.CodeInvalidArgument, err.Error(), err)
+	}
+	entry.Summary = singleLine(entry.Summary)
+	entry.Detail = singleLine(entry.Detail)
+	if entry.Summary == "" {
+		return entry, apperror.New(apperror.CodeInvalidArgument, "memory summary is required")
+	}
+	if utf8.RuneCountInString(entry.Summary) > maxSummaryRunes {
+		return entry, apperror.Errorf(apperror.CodeInvalidArgument, "memory summary exceeds %d characters", maxSummaryRunes)
+	}
+	if utf8.RuneCountInString(entry.Detail) > maxDetailRunes {
+		return entry, apperror.Errorf(apperror.CodeInvalidArgument, "memory detail exceeds %d characters", maxDetailRunes)
+	}
+	tags := make([]string, 0, len(entry.Tags))
+	for _, tag := range entry.Tags {
+		// 标签以 ", " 分隔存储，标签内的逗号替换为空格
+		tag = strings.TrimSpace(strings.ReplaceAll(singleLine(tag), ",", " "))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > maxEntryTags {
+		return entry, apperror.Errorf(apperror.CodeInvalidArgument, "memory takes at most %d tags", maxEntryTags)
+	}
+	entry.Tags = tags
+	return entry, nil
+}
+
+func singleLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 	"unicode/utf8"
+
+	domainmemory "fkteams/internal/domain/memory"
 )
 
 // formatConversation 将消息列表转为纯文本，只保留 user 和 assistant 消息，
@@ -127,6 +129,17 @@ const extractPrompt = `你是一个记忆提取专家。从对话中识别值得
 </type>
 </types>
 
+## 作用域
+
+每条记忆还要判断适用范围（scope），范围越窄越不容易干扰无关的对话：
+
+- global：与具体项目、智能体和用户无关的通用偏好、原则和经验
+- workspace：只对当前项目有意义的信息，如项目约定、技术选型、在项目里踩过的坑
+- agent：只对当前智能体的职责有意义的做法和经验
+- user：当前通道用户的个人偏好和背景，不应影响其他用户
+
+本次对话可用的作用域：%s。无法确定时使用 global。
+
 ## 不要提取
 
 - 一次性任务指令（"帮我写脚本"、"把 X 改成 Y"）
@@ -139,7 +152,7 @@ const extractPrompt = `你是一个记忆提取专家。从对话中识别值得
 ## 输出格式
 
 JSON 数组（无 markdown 包裹，无多余文字）：
-[{"type":"preference","summary":"≤20字摘要","detail":"≤100字补充","tags":["关键词1","关键词2","关键词3"]}]
+[{"type":"preference","scope":"global","summary":"≤20字摘要","detail":"≤100字补充","tags":["关键词1","关键词2","关键词3"]}]
 
 无值得提取内容时返回 []
 
@@ -150,19 +163,20 @@ JSON 数组（无 markdown 包裹，无多余文字）：
 // extractedEntry LLM 返回的提取结果
 type extractedEntry struct {
 	Type    MemoryType `json:"type"`
+	Scope   ScopeKind  `json:"scope"`
 	Summary string     `json:"summary"`
 	Detail  string     `json:"detail"`
 	Tags    []string   `json:"tags"`
 }
 
-// Extract 从对话历史中提取记忆条目
-func Extract(ctx context.Context, messages []Message, sessionID string, llmClient LLMClient) ([]MemoryEntry, error) {
+// Extract 从对话历史中提取记忆条目，并按 scope 将 LLM 判断的作用域落到具体的项目、智能体或用户
+func Extract(ctx context.Context, messages []Message, sessionID string, scope ScopeContext, llmClient LLMClient) ([]MemoryEntry, error) {
 	conversation := formatConversation(messages)
 	if conversation == "" {
 		return nil, nil
 	}
 
-	prompt := fmt.Sprintf(extractPrompt, conversation)
+	prompt := fmt.Sprintf(extractPrompt, availableScopes(scope), conversation)
 	result, err := llmClient.Complete(ctx, prompt)
 	if err != nil {
 		return nil, fmt.Errorf("llm complete failed: %w", err)
@@ -192,6 +206,7 @@ func Extract(ctx context.Context, messages []Message, sessionID string, llmClien
 		entries = append(entries, MemoryEntry{
 			ID:        fmt.Sprintf("%s_%d", sessionID, now.UnixNano()),
 			Type:      e.Type,
+			Scope:     ResolveScope(e.Scope, e.Type, scope),
 			Summary:   e.Summary,
 			Detail:    e.Detail,
 			Tags:      e.Tags,
@@ -203,3 +218,31 @@ func Extract(ctx context.Context, messages []Message, sessionID string, llmClien
 	}
 	return entries, nil
 }
+
+// availableScopes 列出本次对话可用的作用域，供提取提示词使用
+func availableScopes(scope ScopeContext) string {
+	kinds := []string{string(ScopeGlobal)}
+	for _, kind := range []ScopeKind{ScopeWorkspace, ScopeAgent, ScopeUser} {
+		if current, ok := scope.ScopeFor(kind); ok {
+			kinds = append(kinds, fmt.Sprintf("%s（%s）", kind, current.Key))
+		}
+	}
+	return strings.Join(kinds, "、")
+}
+
+// ResolveScope 将提取或主动保存时指定的作用域类型解析为具体作用域，对应维度缺失时回退到全局。
+// 通道对话中的个人偏好和背景默认归属当前用户，避免通道用户之间以及与本机用户之间互相串扰。
+func ResolveScope(kind ScopeKind, memType MemoryType, scope ScopeContext) Scope {
+	if (kind == "" || kind == ScopeGlobal) && isPersonalType(memType) {
+		kind = ScopeUser
+	}
+	if resolved, ok := scope.ScopeFor(kind); ok {
+		return resolved
+	}
+	return domainmemory.GlobalScope()
+}
+
+// isPersonalType 判断记忆类型是否描述用户个人
+func isPersonalType(memType MemoryType) bool {
+	return memType == Preference || memType == Fact
+}
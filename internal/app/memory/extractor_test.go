@@ -13,7 +13,7 @@ func TestExtractParsesFencedJSONAndFiltersInvalidTypes(t *testing.T) {
 		{"type":"invalid","summary":"忽略","detail":"非法类型","tags":["bad"]}
 	]` + "\n```"}
 
-	entries, err := Extract(context.Background(), longConversationMessages(), "session-1", llm)
+	entries, err := Extract(context.Background(), longConversationMessages(), "session-1", ScopeContext{}, llm)
 	if err != nil {
 		t.Fatalf("Extract error: %v", err)
 	}
@@ -38,7 +38,7 @@ func TestExtractParsesFencedJSONAndFiltersInvalidTypes(t *testing.T) {
 func TestExtractSkipsShortConversationWithoutLLMCall(t *testing.T) {
 	llm := &fakeLLMClient{response: "not json"}
 
-	entries, err := Extract(context.Background(), []Message{{Role: "user", Content: "太短"}}, "session-1", llm)
+	entries, err := Extract(context.Background(), []Message{{Role: "user", Content: "太短"}}, "session-1", ScopeContext{}, llm)
 	if err != nil {
 		t.Fatalf("Extract error: %v", err)
 	}
@@ -51,10 +51,10 @@ func TestExtractSkipsShortConversationWithoutLLMCall(t *testing.T) {
 }
 
 func TestExtractReturnsLLMAndParseErrors(t *testing.T) {
-	if _, err := Extract(context.Background(), longConversationMessages(), "session-1", &fakeLLMClient{err: errors.New("down")}); err == nil || !strings.Contains(err.Error(), "llm complete failed") {
+	if _, err := Extract(context.Background(), longConversationMessages(), "session-1", ScopeContext{}, &fakeLLMClient{err: errors.New("down")}); err == nil || !strings.Contains(err.Error(), "llm complete failed") {
 		t.Fatalf("llm error = %v", err)
 	}
-	if _, err := Extract(context.Background(), longConversationMessages(), "session-1", &fakeLLMClient{response: "bad json"}); err == nil || !strings.Contains(err.Error(), "failed to parse llm response") {
+	if _, err := Extract(context.Background(), longConversationMessages(), "session-1", ScopeContext{}, &fakeLLMClient{response: "bad json"}); err == nil || !strings.Contains(err.Error(), "failed to parse llm response") {
 		t.Fatalf("parse error = %v", err)
 	}
 }
@@ -96,3 +96,29 @@ func (f *fakeLLMClient) Complete(ctx context.Context, prompt string) (string, er
 	f.prompt = prompt
 	return f.response, f.err
 }
+
+func TestExtractResolvesScopesAgainstContext(t *testing.T) {
+	llm := &fakeLLMClient{response: `[
+		{"type":"decision","scope":"workspace","summary":"项目统一用 sqlite","detail":"本地存储","tags":["存储"]},
+		{"type":"experience","scope":"agent","summary":"先跑单测再提交","detail":"避免回归","tags":["测试"]},
+		{"type":"preference","scope":"global","summary":"偏好简洁","detail":"回复直接","tags":["风格"]},
+		{"type":"lesson","scope":"user","summary":"注意时区","detail":"定时任务按本地时区","tags":["时区"]}
+	]`}
+
+	entries, err := Extract(context.Background(), longConversationMessages(), "session-1", ScopeContext{Workspace: "fkteams", User: "qq:10001"}, llm)
+	if err != nil {
+		t.Fatalf("Extract error: %v", err)
+	}
+	if !strings.Contains(llm.prompt, "workspace（fkteams）") || strings.Contains(llm.prompt, "agent（") {
+		t.Fatalf("prompt should list available scopes, got %q", llm.prompt)
+	}
+	want := []string{"workspace:fkteams", "global", "user:qq:10001", "user:qq:10001"}
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %#v, want %d entries", entries, len(want))
+	}
+	for i, entry := range entries {
+		if got := entry.Scope.String(); got != want[i] {
+			t.Fatalf("entry %q scope = %s, want %s", entry.Summary, got, want[i])
+		}
+	}
+}
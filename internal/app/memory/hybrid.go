@@ -0,0 +1,125 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"fkteams/internal/runtime/log"
+)
+
+const (
+	defaultMinSimilarity = 0.35
+
+	// rrfK 是倒数排名融合的平滑常数，取值越大排名靠后的结果权重衰减越慢
+	rrfK = 60
+
+	embedTimeout   = 15 * time.Second
+	embedBatchSize = 64
+)
+
+// rankedEntry 是单路检索排序后的结果
+type rankedEntry struct {
+	entry MemoryEntry
+	score float64
+}
+
+// fuseRankings 用倒数排名融合（RRF）合并多路检索结果，每路按各自得分降序排列
+func fuseRankings(rankings ...[]rankedEntry) []rankedEntry {
+	fused := make(map[string]*rankedEntry)
+	var order []string
+	for _, ranking := range rankings {
+		for rank, item := range ranking {
+			current, ok := fused[item.entry.ID]
+			if !ok {
+				current = &rankedEntry{entry: item.entry}
+				fused[item.entry.ID] = current
+				order = append(order, item.entry.ID)
+			}
+			current.score += 1 / float64(rrfK+rank+1)
+		}
+	}
+	results := make([]rankedEntry, 0, len(order))
+	for _, id := range order {
+		results = append(results, *fused[id])
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].score > results[j].score })
+	return results
+}
+
+// semanticRank 计算查询与候选条目的余弦相似度，返回相似度达到阈值的条目
+// 候选条目缺少向量时先补齐；向量模型不可用时返回错误，由调用方退回纯 BM25 检索
+func (m *Manager) semanticRank(embedder Embedder, store *vectorStore, query string, pool []MemoryEntry, keys map[string]string) ([]rankedEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), embedTimeout)
+	defer cancel()
+
+	model := embedder.Model()
+	poolKeys := make([]string, len(pool))
+	texts := make(map[string]string, len(pool))
+	for i, entry := range pool {
+		poolKeys[i] = keys[entry.ID]
+		texts[poolKeys[i]] = embeddingText(entry)
+	}
+	missing := store.missing(poolKeys)
+	for start := 0; start < len(missing); start += embedBatchSize {
+		batch := missing[start:min(start+embedBatchSize, len(missing))]
+		inputs := make([]string, len(batch))
+		for i, key := range batch {
+			inputs[i] = texts[key]
+		}
+		vectors, err := embedder.Embed(ctx, inputs)
+		if err != nil {
+			return nil, fmt.Errorf("embed memories: %w", err)
+		}
+		if err := store.put(model, batch, vectors); err != nil {
+			return nil, err
+		}
+	}
+	if len(missing) > 0 {
+		live := make(map[string]bool, len(keys))
+		for _, key := range keys {
+			live[key] = true
+		}
+		if err := store.flush(live); err != nil {
+			log.Warnf("[memory] warn: save vector index failed: %v", err)
+		}
+	}
+
+	queryVectors, err := embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	if len(queryVectors) != 1 || len(queryVectors[0]) == 0 {
+		return nil, fmt.Errorf("embedder returned no query vector")
+	}
+	queryVector := normalizeVector(queryVectors[0])
+
+	var ranked []rankedEntry
+	for i, entry := range pool {
+		vector, ok := store.get(poolKeys[i])
+		if !ok {
+			continue
+		}
+		if similarity := cosine(queryVector, vector); similarity >= m.minSimilarity {
+			ranked = append(ranked, rankedEntry{entry: entry, score: similarity})
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	return ranked, nil
+}
+
+// ResetEmbedder 替换向量模型（配置变更后调用），模型标识变化时重建向量索引
+func (m *Manager) ResetEmbedder(embedder Embedder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.embedder = embedder
+	if embedder == nil {
+		return
+	}
+	if m.vectors == nil {
+		m.vectors = newVectorStore(m.storeDir, embedder.Model())
+		return
+	}
+	m.vectors.reset(embedder.Model())
+}
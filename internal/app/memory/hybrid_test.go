@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeEmbedder 把文本映射到按关键词划分的概念维度，用于模拟能识别同义改写的向量模型
+type fakeEmbedder struct {
+	model    string
+	concepts [][]string
+	calls    atomic.Int32
+	err      error
+}
+
+func (e *fakeEmbedder) Model() string { return e.model }
+
+func (e *fakeEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.calls.Add(1)
+	if e.err != nil {
+		return nil, e.err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, len(e.concepts)+1)
+		vector[len(e.concepts)] = 0.1
+		for dim, words := range e.concepts {
+			for _, word := range words {
+				if strings.Contains(strings.ToLower(text), word) {
+					vector[dim] = 1
+				}
+			}
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+func newFakeEmbedder(model string) *fakeEmbedder {
+	return &fakeEmbedder{model: model, concepts: [][]string{
+		{"数据库", "database", "db"},
+		{"迁移", "migration", "migrate"},
+		{"前端", "frontend", "css"},
+	}}
+}
+
+func TestFuseRankingsUsesReciprocalRank(t *testing.T) {
+	a, b, c := MemoryEntry{ID: "a"}, MemoryEntry{ID: "b"}, MemoryEntry{ID: "c"}
+	fused := fuseRankings(
+		[]rankedEntry{{entry: a, score: 9}, {entry: b, score: 5}},
+		[]rankedEntry{{entry: b, score: 0.9}, {entry: c, score: 0.8}},
+	)
+	var ids []string
+	for _, item := range fused {
+		ids = append(ids, item.entry.ID)
+	}
+	if strings.Join(ids, ",") != "b,a,c" {
+		t.Fatalf("fused ids = %v, want entry found by both rankings first", ids)
+	}
+}
+
+func TestManagerHybridSearchFindsParaphrases(t *testing.T) {
+	dir := t.TempDir()
+	embedder := newFakeEmbedder("fake:v1")
+	manager := NewManager(dir, nil, &Config{Embedder: embedder})
+	for _, entry := range []MemoryEntry{
+		{Type: Decision, Summary: "database migrations run through goose"},
+		{Type: Decision, Summary: "前端样式统一使用 tailwind"},
+	} {
+		if _, _, err := manager.Save(entry); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	entries := manager.Search("数据库迁移", 10, ScopeContext{})
+	if len(entries) != 1 || !strings.Contains(entries[0].Summary, "goose") {
+		t.Fatalf("hybrid search = %#v, want english paraphrase only", entries)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "memory", vectorIndexFile)); err != nil {
+		t.Fatalf("vector index not written: %v", err)
+	}
+
+	calls := embedder.calls.Load()
+	reloaded := NewManager(dir, nil, &Config{Embedder: embedder})
+	reloaded.Search("数据库迁移", 10, ScopeContext{})
+	if got := embedder.calls.Load() - calls; got != 1 {
+		t.Fatalf("embed calls after reload = %d, want query only", got)
+	}
+
+	switched := newFakeEmbedder("fake:v2")
+	reloaded = NewManager(dir, nil, &Config{Embedder: switched})
+	if reloaded.vectors.missing([]string{vectorKey(entries[0])}) == nil {
+		t.Fatal("vectors from another model should be discarded")
+	}
+	reloaded.Search("数据库迁移", 10, ScopeContext{})
+	data, err := readVectorIndex(filepath.Join(dir, "memory", vectorIndexFile))
+	if err != nil || data.Model != "fake:v2" || len(data.Vectors) != 2 {
+		t.Fatalf("rebuilt index = %#v, %v", data, err)
+	}
+
+	reloaded.Clear()
+	data, err = readVectorIndex(filepath.Join(dir, "memory", vectorIndexFile))
+	if err != nil || len(data.Vectors) != 0 {
+		t.Fatalf("index after clear = %#v, %v", data, err)
+	}
+}
+
+func TestManagerHybridSearchFallsBackToBM25(t *testing.T) {
+	embedder := newFakeEmbedder("fake:v1")
+	embedder.err = errors.New("connection refused")
+	manager := NewManager(t.TempDir(), nil, &Config{Embedder: embedder})
+	if _, _, err := manager.Save(MemoryEntry{Type: Lesson, Summary: "数据库迁移要先备份"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if entries := manager.Search("数据库迁移", 10, ScopeContext{}); len(entries) != 1 {
+		t.Fatalf("fallback search = %#v, want BM25 result", entries)
+	}
+}
+
+func TestManagerResetEmbedderRebuildsOnModelChange(t *testing.T) {
+	manager := NewManager(t.TempDir(), nil, nil)
+	if _, _, err := manager.Save(MemoryEntry{Type: Lesson, Summary: "database migration needs a backup"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if entries := manager.Search("数据库", 10, ScopeContext{}); len(entries) != 0 {
+		t.Fatalf("BM25-only search = %#v, want no match across languages", entries)
+	}
+
+	first := newFakeEmbedder("fake:v1")
+	manager.ResetEmbedder(first)
+	if entries := manager.Search("数据库", 10, ScopeContext{}); len(entries) != 1 {
+		t.Fatalf("search after ResetEmbedder = %#v, want semantic match", entries)
+	}
+	manager.ResetEmbedder(first)
+	calls := first.calls.Load()
+	manager.Search("数据库", 10, ScopeContext{})
+	if got := first.calls.Load() - calls; got != 1 {
+		t.Fatalf("embed calls with same model = %d, want vectors kept", got)
+	}
+
+	second := newFakeEmbedder("fake:v2")
+	manager.ResetEmbedder(second)
+	manager.Search("数据库", 10, ScopeContext{})
+	if second.calls.Load() != 2 {
+		t.Fatalf("embed calls after model change = %d, want entries re-embedded", second.calls.Load())
+	}
+}
@@ -25,6 +25,14 @@ const (
 	maxTrackedSessions       = 4_096
 )
 
+// defaultScopeWeights 检索时各作用域的得分权重，越具体的作用域越优先
+var defaultScopeWeights = map[ScopeKind]float64{
+	ScopeGlobal:    1.0,
+	ScopeAgent:     1.2,
+	ScopeWorkspace: 1.3,
+	ScopeUser:      1.5,
+}
+
 // Manager 记忆管理器
 type Manager struct {
 	mu       sync.RWMutex
@@ -32,10 +40,16 @@ type Manager struct {
 	entries  []MemoryEntry
 	bm25     *BM25
 	llm      LLMClient
+	// embedder 为空时只使用 BM25 检索
+	embedder   Embedder
+	vectors    *vectorStore
+	vectorKeys map[string]string
 
-	maxEntries   int
-	minScore     float64
-	evictionDays int
+	maxEntries    int
+	minScore      float64
+	minSimilarity float64
+	evictionDays  int
+	scopeWeights  map[ScopeKind]float64
 
 	taskMu           sync.Mutex
 	resetMu          sync.Mutex
@@ -57,6 +71,12 @@ type Config struct {
 	MaxEntries   int
 	MinScore     float64
 	EvictionDays int
+	// ScopeWeights 覆盖各作用域的检索得分权重，未设置的作用域使用默认权重
+	ScopeWeights map[ScopeKind]float64
+	// Embedder 启用语义检索，与 BM25 结果按倒数排名融合；为空时只使用 BM25
+	Embedder Embedder
+	// MinSimilarity 语义检索的最低余弦相似度
+	MinSimilarity float64
 }
 
 // NewManager 创建记忆管理器
@@ -64,6 +84,12 @@ func NewManager(workspaceDir string, llmClient LLMClient, cfg *Config) *Manager
 	maxEntries := defaultMaxEntries
 	minScore := defaultMinScore
 	evictionDays := defaultEvictionDays
+	minSimilarity := defaultMinSimilarity
+	var embedder Embedder
+	scopeWeights := make(map[ScopeKind]float64, len(defaultScopeWeights))
+	for kind, weight := range defaultScopeWeights {
+		scopeWeights[kind] = weight
+	}
 	if cfg != nil {
 		if cfg.MaxEntries > 0 {
 			maxEntries = cfg.MaxEntries
@@ -74,14 +100,26 @@ func NewManager(workspaceDir string, llmClient LLMClient, cfg *Config) *Manager
 		if cfg.EvictionDays > 0 {
 			evictionDays = cfg.EvictionDays
 		}
+		if cfg.MinSimilarity > 0 {
+			minSimilarity = cfg.MinSimilarity
+		}
+		embedder = cfg.Embedder
+		for kind, weight := range cfg.ScopeWeights {
+			if AllScopeKinds[kind] && weight > 0 {
+				scopeWeights[kind] = weight
+			}
+		}
 	}
 	m := &Manager{
 		storeDir:         filepath.Join(workspaceDir, "memory"),
 		bm25:             &BM25{},
 		llm:              llmClient,
+		embedder:         embedder,
 		maxEntries:       maxEntries,
 		minScore:         minScore,
+		minSimilarity:    minSimilarity,
 		evictionDays:     evictionDays,
+		scopeWeights:     scopeWeights,
 		extractedOffsets: make(map[string]int),
 		lastExtractTime:  make(map[string]time.Time),
 		sessionAccess:    make(map[string]time.Time),
@@ -89,14 +127,17 @@ func NewManager(workspaceDir string, llmClient LLMClient, cfg *Config) *Manager
 		extracting:       make(map[string]struct{}),
 		stopDone:         make(chan struct{}),
 	}
+	if embedder != nil {
+		m.vectors = newVectorStore(m.storeDir, embedder.Model())
+	}
 	m.load()
 	m.rebuildIndex()
 	return m
 }
 
-// ExtractAndStore 提取记忆并存储
+// ExtractAndStore 提取记忆并存储，scope 描述对话所处的项目、智能体和通道用户
 // 内部根据新增消息数量、内容长度和冷却时间智能判断是否触发 LLM 提取
-func (m *Manager) ExtractAndStore(ctx context.Context, messages []Message, sessionID string) {
+func (m *Manager) ExtractAndStore(ctx context.Context, messages []Message, sessionID string, scope ScopeContext) {
 	m.mu.RLock()
 	offset := m.extractedOffsets[sessionID]
 	lastTime := m.lastExtractTime[sessionID]
@@ -113,7 +154,7 @@ func (m *Manager) ExtractAndStore(ctx context.Context, messages []Message, sessi
 		return
 	}
 
-	entries, err := Extract(ctx, newMessages, sessionID, llm)
+	entries, err := Extract(ctx, newMessages, sessionID, scope, llm)
 	if err != nil {
 		log.Warnf("[memory] warn: extract failed: %v", err)
 		return
@@ -162,7 +203,7 @@ func (m *Manager) ExtractAndStore(ctx context.Context, messages []Message, sessi
 }
 
 // ExtractAndStoreAsync 原子登记并异步执行一次记忆提取。
-func (m *Manager) ExtractAndStoreAsync(messages []Message, sessionID string) bool {
+func (m *Manager) ExtractAndStoreAsync(messages []Message, sessionID string, scope ScopeContext) bool {
 	if !m.beginExtraction(sessionID) {
 		return false
 	}
@@ -171,24 +212,63 @@ func (m *Manager) ExtractAndStoreAsync(messages []Message, sessionID string) boo
 		defer m.finishExtraction(sessionID)
 		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 		defer cancel()
-		m.ExtractAndStore(ctx, copied, sessionID)
+		m.ExtractAndStore(ctx, copied, sessionID, scope)
 	}()
 	return true
 }
 
 // Search 检索记忆，使用 BM25 bigram 分词进行语义匹配
-func (m *Manager) Search(query string, topK int) []MemoryEntry {
-	m.mu.RLock()
-	results := m.bm25.Search(query, m.entries, topK)
+// 只返回全局记忆和 scope 命中的作用域记忆，得分按作用域权重加权后排序；
+// 通道用户对话中不注入全局的个人偏好和背景，避免本机用户的个人信息串入通道机器人
+func (m *Manager) Search(query string, topK int, scope ScopeContext) []MemoryEntry {
+	return m.search(query, topK, scope, SearchFilter{})
+}
 
-	var entries []MemoryEntry
+func (m *Manager) search(query string, topK int, scope ScopeContext, filter SearchFilter) []MemoryEntry {
+	m.mu.RLock()
+	results := m.bm25.Search(query, m.entries, 0)
+	var lexical []rankedEntry
 	for _, r := range results {
-		if r.Score >= m.minScore {
-			entries = append(entries, *r.Entry)
+		if r.Score >= m.minScore && visibleIn(*r.Entry, scope) && filter.Match(*r.Entry) {
+			lexical = append(lexical, rankedEntry{entry: *r.Entry, score: r.Score})
+		}
+	}
+	embedder, store := m.embedder, m.vectors
+	var pool []MemoryEntry
+	var keys map[string]string
+	if embedder != nil && store != nil && len(m.entries) > 0 {
+		for _, e := range m.entries {
+			if visibleIn(e, scope) && filter.Match(e) {
+				pool = append(pool, e)
+			}
 		}
+		keys = m.vectorKeys
 	}
 	m.mu.RUnlock()
 
+	// BM25 结果已按得分降序排列；启用语义检索时与余弦相似度排序按倒数排名融合
+	candidates := lexical
+	if len(pool) > 0 {
+		semantic, err := m.semanticRank(embedder, store, query, pool, keys)
+		if err != nil {
+			log.Warnf("[memory] warn: semantic search failed, using BM25 only: %v", err)
+		} else {
+			candidates = fuseRankings(lexical, semantic)
+		}
+	}
+	for i := range candidates {
+		candidates[i].score *= m.scopeWeights[candidates[i].entry.Scope.Normalize().Kind]
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+	if topK > 0 && len(candidates) > topK {
+		candidates = candidates[:topK]
+	}
+	var entries []MemoryEntry
+	for _, c := range candidates {
+		entries = append(entries, c.entry)
+	}
+
 	// 命中统计只操作受限的内存条目，同步更新可避免每次查询创建协程。
 	if len(entries) > 0 {
 		hitIDs := make([]string, len(entries))
@@ -201,6 +281,15 @@ func (m *Manager) Search(query string, topK int) []MemoryEntry {
 	return entries
 }
 
+// visibleIn 判断记忆在当前对话中是否可见：全局记忆和命中的作用域记忆可见，
+// 但通道用户对话中看不到全局的个人偏好和背景
+func visibleIn(entry MemoryEntry, scope ScopeContext) bool {
+	if !scope.Matches(entry.Scope) {
+		return false
+	}
+	return !(entry.Scope.IsGlobal() && scope.User != "" && isPersonalType(entry.Type))
+}
+
 // Wait 等待所有异步提取任务完成（用于优雅退出）
 func (m *Manager) Wait(ctx context.Context) error {
 	if ctx == nil {
@@ -250,7 +339,7 @@ func (m *Manager) ResetLLM(llm LLMClient) {
 }
 
 // FlushExtract 强制提取指定会话的剩余消息（退出前调用，跳过触发条件检查）
-func (m *Manager) FlushExtract(ctx context.Context, messages []Message, sessionID string) {
+func (m *Manager) FlushExtract(ctx context.Context, messages []Message, sessionID string, scope ScopeContext) {
 	m.mu.RLock()
 	offset := m.extractedOffsets[sessionID]
 	llm := m.llm
@@ -270,7 +359,7 @@ func (m *Manager) FlushExtract(ctx context.Context, messages []Message, sessionI
 		return
 	}
 
-	entries, err := Extract(ctx, newMessages, sessionID, llm)
+	entries, err := Extract(ctx, newMessages, sessionID, scope, llm)
 	if err != nil {
 		log.Warnf("[memory] warn: flush extract failed: %v", err)
 		return
@@ -388,6 +477,60 @@ func (m *Manager) Delete(summary string) int {
 	return deleted
 }
 
+// Move 将指定摘要的记忆移动到目标作用域，返回移动数量
+// 目标作用域中已有相近条目时合并为一条，保留较新的内容并累加命中次数
+func (m *Manager) Move(summary string, scope Scope) (int, error) {
+	scope = scope.Normalize()
+	if err := scope.Validate(); err != nil {
+		return 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	summary = strings.TrimSpace(summary)
+	var moving []MemoryEntry
+	kept := make([]MemoryEntry, 0, len(m.entries))
+	for _, e := range m.entries {
+		if e.Summary == summary && e.Scope.Normalize() != scope {
+			moving = append(moving, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	if len(moving) == 0 {
+		return 0, nil
+	}
+	m.entries = kept
+	for _, entry := range moving {
+		entry.Scope = scope
+		action, idx := m.checkDuplicate(entry)
+		if action == actionAdd {
+			m.entries = append(m.entries, entry)
+			continue
+		}
+		m.mergeLocked(idx, entry)
+	}
+	m.rebuildIndex()
+	if err := m.save(); err != nil {
+		log.Warnf("[memory] warn: save after move failed: %v", err)
+	}
+	return len(moving), nil
+}
+
+// mergeLocked 将条目合并到已有条目：保留较新的内容，累加命中次数并保留最近命中时间
+func (m *Manager) mergeLocked(idx int, entry MemoryEntry) {
+	existing := m.entries[idx]
+	if entry.CreatedAt.After(existing.CreatedAt) {
+		existing.Summary, existing.Detail, existing.Tags = entry.Summary, entry.Detail, entry.Tags
+		existing.CreatedAt = entry.CreatedAt
+	}
+	existing.HitCount += entry.HitCount
+	if entry.LastHitAt != nil && (existing.LastHitAt == nil || entry.LastHitAt.After(*existing.LastHitAt)) {
+		existing.LastHitAt = entry.LastHitAt
+	}
+	m.entries[idx] = existing
+}
+
 // Clear 清空所有记忆
 func (m *Manager) Clear() {
 	m.mu.Lock()
@@ -467,10 +610,11 @@ const (
 	actionSkip                          // 完全重复，跳过
 )
 
-// checkDuplicate 判断新条目与已有条目的关系
+// checkDuplicate 判断新条目与已有条目的关系，只与同类型、同作用域的条目比较
 func (m *Manager) checkDuplicate(entry MemoryEntry) (duplicateAction, int) {
+	entryScope := entry.Scope.Normalize()
 	for i, existing := range m.entries {
-		if existing.Type != entry.Type {
+		if existing.Type != entry.Type || existing.Scope.Normalize() != entryScope {
 			continue
 		}
 
@@ -619,10 +763,14 @@ func (m *Manager) evictIfNeeded() {
 	}
 }
 
-// rebuildIndex 重建 BM25 索引
+// rebuildIndex 重建 BM25 索引和条目的向量索引键，向量本身在检索时按需补齐
 func (m *Manager) rebuildIndex() {
 	m.bm25 = &BM25{}
 	m.bm25.Build(m.entries)
+	m.vectorKeys = make(map[string]string, len(m.entries))
+	for _, e := range m.entries {
+		m.vectorKeys[e.ID] = vectorKey(e)
+	}
 }
 
 func (m *Manager) load() {
@@ -632,5 +780,18 @@ func (m *Manager) load() {
 // save 保存到 Markdown 文件
 func (m *Manager) save() error {
 	m.dirty = false
-	return saveAllMarkdown(m.storeDir, m.entries)
+	if err := saveAllMarkdown(m.storeDir, m.entries); err != nil {
+		return err
+	}
+	// 删除或修改的条目不再保留旧向量，避免已遗忘的内容残留在向量索引中
+	if m.vectors != nil {
+		live := make(map[string]bool, len(m.vectorKeys))
+		for _, key := range m.vectorKeys {
+			live[key] = true
+		}
+		if err := m.vectors.flush(live); err != nil {
+			log.Warnf("[memory] warn: save vector index failed: %v", err)
+		}
+	}
+	return nil
 }
@@ -17,7 +17,7 @@ func TestManagerFlushExtractPersistsAndLoadsMarkdown(t *testing.T) {
 	llm := &fakeLLMClient{response: `[{"type":"preference","summary":"偏好中文","detail":"回复使用中文且简洁","tags":["中文","简洁"]}]`}
 	manager := NewManager(workspace, llm, nil)
 
-	manager.FlushExtract(context.Background(), longConversationMessages(), "session-1")
+	manager.FlushExtract(context.Background(), longConversationMessages(), "session-1", ScopeContext{})
 
 	if manager.Count() != 1 {
 		t.Fatalf("count = %d, want 1", manager.Count())
@@ -43,7 +43,7 @@ func TestManagerFlushExtractSkipsShortContent(t *testing.T) {
 	llm := &fakeLLMClient{response: `[]`}
 	manager := NewManager(t.TempDir(), llm, nil)
 
-	manager.FlushExtract(context.Background(), []Message{{Role: "user", Content: "短内容"}}, "session-1")
+	manager.FlushExtract(context.Background(), []Message{{Role: "user", Content: "短内容"}}, "session-1", ScopeContext{})
 
 	if llm.calls != 0 {
 		t.Fatalf("llm calls = %d, want 0", llm.calls)
@@ -120,7 +120,7 @@ func TestManagerWaitTracksAsyncWorkAndRejectsNewTasks(t *testing.T) {
 		{Role: "user", Content: strings.Repeat("继续补充", 80)},
 	}
 
-	if !manager.ExtractAndStoreAsync(messages, "session-1") {
+	if !manager.ExtractAndStoreAsync(messages, "session-1", ScopeContext{}) {
 		t.Fatal("async extraction should be accepted before shutdown")
 	}
 	<-llm.started
@@ -138,7 +138,7 @@ func TestManagerWaitTracksAsyncWorkAndRejectsNewTasks(t *testing.T) {
 	close(llm.release)
 	<-waited
 
-	if manager.ExtractAndStoreAsync(messages, "session-2") {
+	if manager.ExtractAndStoreAsync(messages, "session-2", ScopeContext{}) {
 		t.Fatal("async extraction should be rejected after shutdown starts")
 	}
 	if calls := llm.calls.Load(); calls != 1 {
@@ -153,7 +153,7 @@ func TestManagerWaitTracksHitStatsUpdate(t *testing.T) {
 	}}
 	manager.rebuildIndex()
 
-	if entries := manager.Search("偏好中文", 1); len(entries) != 1 {
+	if entries := manager.Search("偏好中文", 1, ScopeContext{}); len(entries) != 1 {
 		t.Fatalf("search entries = %#v, want one", entries)
 	}
 	if err := manager.Wait(context.Background()); err != nil {
@@ -175,7 +175,7 @@ func TestManagerWaitHonorsContextDeadline(t *testing.T) {
 		{Role: "assistant", Content: "收到"},
 		{Role: "user", Content: strings.Repeat("继续补充", 80)},
 	}
-	if !manager.ExtractAndStoreAsync(messages, "session-1") {
+	if !manager.ExtractAndStoreAsync(messages, "session-1", ScopeContext{}) {
 		t.Fatal("async extraction should be accepted")
 	}
 	<-llm.started
@@ -198,11 +198,11 @@ func TestManagerRejectsDuplicateSessionExtraction(t *testing.T) {
 	}
 	manager := NewManager(t.TempDir(), llm, nil)
 	messages := asyncExtractionMessages()
-	if !manager.ExtractAndStoreAsync(messages, "session-1") {
+	if !manager.ExtractAndStoreAsync(messages, "session-1", ScopeContext{}) {
 		t.Fatal("first extraction should be accepted")
 	}
 	<-llm.started
-	if manager.ExtractAndStoreAsync(messages, "session-1") {
+	if manager.ExtractAndStoreAsync(messages, "session-1", ScopeContext{}) {
 		t.Fatal("duplicate extraction for the same session should be rejected")
 	}
 	close(llm.release)
@@ -222,11 +222,11 @@ func TestManagerLimitsConcurrentExtractions(t *testing.T) {
 	manager := NewManager(t.TempDir(), llm, nil)
 	messages := asyncExtractionMessages()
 	for i := 0; i < maxConcurrentExtractions; i++ {
-		if !manager.ExtractAndStoreAsync(messages, fmt.Sprintf("session-%d", i)) {
+		if !manager.ExtractAndStoreAsync(messages, fmt.Sprintf("session-%d", i), ScopeContext{}) {
 			t.Fatalf("extraction %d should be accepted", i)
 		}
 	}
-	if manager.ExtractAndStoreAsync(messages, "session-overflow") {
+	if manager.ExtractAndStoreAsync(messages, "session-overflow", ScopeContext{}) {
 		t.Fatal("extraction above the global limit should be rejected")
 	}
 	for i := 0; i < maxConcurrentExtractions; i++ {
@@ -285,6 +285,177 @@ func TestManagerDuplicateDetection(t *testing.T) {
 	}
 }
 
+func TestManagerSearchMergesScopesWithWeights(t *testing.T) {
+	manager := NewManager(t.TempDir(), nil, nil)
+	manager.entries = []MemoryEntry{
+		{ID: "1", Type: Lesson, Summary: "数据库迁移要先备份", Detail: "迁移前备份数据库", Scope: Scope{Kind: ScopeGlobal}},
+		{ID: "2", Type: Lesson, Summary: "数据库迁移要先备份", Detail: "迁移前备份数据库", Scope: Scope{Kind: ScopeWorkspace, Key: "repo-a"}},
+		{ID: "3", Type: Lesson, Summary: "数据库迁移要先备份", Detail: "迁移前备份数据库", Scope: Scope{Kind: ScopeWorkspace, Key: "repo-b"}},
+		{ID: "4", Type: Preference, Summary: "数据库迁移偏好", Detail: "迁移脚本用 SQL", Scope: Scope{Kind: ScopeGlobal}},
+	}
+	manager.rebuildIndex()
+
+	entries := manager.Search("数据库迁移", 10, ScopeContext{Workspace: "repo-a"})
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	if strings.Join(ids, ",") != "2,1,4" && strings.Join(ids, ",") != "2,4,1" {
+		t.Fatalf("workspace search ids = %v, want repo-a entry first and repo-b excluded", ids)
+	}
+	if entries[0].ID != "2" {
+		t.Fatalf("top entry = %s, want workspace-scoped entry", entries[0].ID)
+	}
+
+	entries = manager.Search("数据库迁移", 10, ScopeContext{User: "qq:1"})
+	for _, entry := range entries {
+		if entry.ID == "4" || entry.Scope.Kind == ScopeWorkspace {
+			t.Fatalf("channel user search returned %#v, want no global preference or workspace entries", entry)
+		}
+	}
+}
+
+func TestManagerDuplicateDetectionIsScoped(t *testing.T) {
+	manager := NewManager(t.TempDir(), nil, nil)
+	manager.entries = []MemoryEntry{{Type: Lesson, Summary: "先备份再迁移", Scope: Scope{Kind: ScopeWorkspace, Key: "repo-a"}}}
+
+	if action, _ := manager.checkDuplicate(MemoryEntry{Type: Lesson, Summary: "先备份再迁移", Scope: Scope{Kind: ScopeWorkspace, Key: "repo-a"}}); action != actionSkip {
+		t.Fatalf("same scope action = %v, want skip", action)
+	}
+	if action, _ := manager.checkDuplicate(MemoryEntry{Type: Lesson, Summary: "先备份再迁移", Scope: Scope{Kind: ScopeWorkspace, Key: "repo-b"}}); action != actionAdd {
+		t.Fatalf("other workspace action = %v, want add", action)
+	}
+	if action, _ := manager.checkDuplicate(MemoryEntry{Type: Lesson, Summary: "先备份再迁移"}); action != actionAdd {
+		t.Fatalf("global action = %v, want add", action)
+	}
+}
+
+func TestManagerMoveChangesScopeAndMerges(t *testing.T) {
+	workspace := t.TempDir()
+	manager := NewManager(workspace, nil, nil)
+	manager.entries = []MemoryEntry{
+		{ID: "1", Type: Lesson, Summary: "先备份再迁移", Detail: "旧", HitCount: 2, CreatedAt: time.Now().Add(-time.Hour)},
+		{ID: "2", Type: Lesson, Summary: "先备份再迁移", Detail: "新", HitCount: 1, CreatedAt: time.Now(), Scope: Scope{Kind: ScopeWorkspace, Key: "repo-b"}},
+	}
+	manager.rebuildIndex()
+
+	if _, err := manager.Move("先备份再迁移", Scope{Kind: ScopeWorkspace}); err == nil {
+		t.Fatal("move to workspace without key should fail")
+	}
+	moved, err := manager.Move("先备份再迁移", Scope{Kind: ScopeWorkspace, Key: "repo-a"})
+	if err != nil || moved != 2 {
+		t.Fatalf("move = %d, %v, want 2 entries", moved, err)
+	}
+	entries := manager.List()
+	if len(entries) != 1 || entries[0].Scope.String() != "workspace:repo-a" || entries[0].HitCount != 3 || entries[0].Detail != "新" {
+		t.Fatalf("entries after move = %#v, want one merged repo-a entry", entries)
+	}
+
+	reloaded := NewManager(workspace, nil, nil).List()
+	if len(reloaded) != 1 || reloaded[0].Scope.String() != "workspace:repo-a" {
+		t.Fatalf("reloaded entries = %#v, want persisted scope", reloaded)
+	}
+	if moved, err := manager.Move("不存在", Scope{Kind: ScopeGlobal}); err != nil || moved != 0 {
+		t.Fatalf("move missing = %d, %v", moved, err)
+	}
+}
+
+func TestManagerSaveDeduplicatesAndPersists(t *testing.T) {
+	workspace := t.TempDir()
+	manager := NewManager(workspace, nil, nil)
+	repoA := Scope{Kind: ScopeWorkspace, Key: "repo-a"}
+
+	saved, action, err := manager.Save(MemoryEntry{Type: Decision, Scope: repoA, Summary: "接口统一使用 gRPC", Detail: "多行\n详情", Tags: []string{"api", "api", "a,b"}})
+	if err != nil || action != SaveAdded || saved.ID == "" {
+		t.Fatalf("save = %#v, %s, %v", saved, action, err)
+	}
+	if saved.Detail != "多行 详情" || strings.Join(saved.Tags, "|") != "api|a b" {
+		t.Fatalf("saved entry was not normalized: %#v", saved)
+	}
+	if _, action, _ := manager.Save(MemoryEntry{Type: Decision, Scope: repoA, Summary: "接口统一使用 gRPC"}); action != SaveSkipped {
+		t.Fatalf("identical save action = %s, want skipped", action)
+	}
+	merged, action, err := manager.Save(MemoryEntry{Type: Decision, Scope: repoA, Summary: "接口统一使用 gRPC 协议", Detail: "新"})
+	if err != nil || action != SaveUpdated || merged.ID != saved.ID {
+		t.Fatalf("similar save = %#v, %s, %v, want merged into %s", merged, action, err, saved.ID)
+	}
+	if _, _, err := manager.Save(MemoryEntry{Type: "unknown", Summary: "x"}); err == nil {
+		t.Fatal("save with invalid type should fail")
+	}
+	if _, _, err := manager.Save(MemoryEntry{Type: Decision, Summary: "  "}); err == nil {
+		t.Fatal("save without summary should fail")
+	}
+
+	reloaded := NewManager(workspace, nil, nil).List()
+	if len(reloaded) != 1 || reloaded[0].Summary != "接口统一使用 gRPC 协议" || reloaded[0].Scope != repoA {
+		t.Fatalf("reloaded entries = %#v", reloaded)
+	}
+}
+
+func TestManagerFindFiltersByTypeAndTag(t *testing.T) {
+	manager := NewManager(t.TempDir(), nil, nil)
+	now := time.Now()
+	manager.entries = []MemoryEntry{
+		{ID: "1", Type: Lesson, Summary: "数据库迁移要先备份", Tags: []string{"DB"}, CreatedAt: now.Add(-time.Hour)},
+		{ID: "2", Type: Decision, Summary: "数据库迁移使用 goose", Tags: []string{"db", "tool"}, CreatedAt: now},
+		{ID: "3", Type: Decision, Summary: "数据库迁移在 repo-b 手动执行", Tags: []string{"db"}, Scope: Scope{Kind: ScopeWorkspace, Key: "repo-b"}, CreatedAt: now},
+	}
+	manager.rebuildIndex()
+	scope := ScopeContext{Workspace: "repo-a"}
+
+	entries := manager.Find("数据库迁移", 10, scope, SearchFilter{Types: []MemoryType{Decision}})
+	if len(entries) != 1 || entries[0].ID != "2" {
+		t.Fatalf("type filtered entries = %#v, want only visible decision", entries)
+	}
+	entries = manager.Find("", 10, scope, SearchFilter{Tags: []string{"db"}})
+	if len(entries) != 2 || entries[0].ID != "2" || entries[1].ID != "1" {
+		t.Fatalf("tag listed entries = %#v, want newest first", entries)
+	}
+	if _, ok := manager.Get("3", scope); ok {
+		t.Fatal("entry of another workspace should not be visible")
+	}
+	if entry, ok := manager.Get("3", ScopeContext{Workspace: "repo-b"}); !ok || entry.ID != "3" {
+		t.Fatalf("get = %#v, %v", entry, ok)
+	}
+}
+
+func TestManagerUpdateAndForget(t *testing.T) {
+	workspace := t.TempDir()
+	manager := NewManager(workspace, nil, nil)
+	manager.entries = []MemoryEntry{
+		{ID: "1", Type: Lesson, Summary: "先备份再迁移", Detail: "旧", HitCount: 2, CreatedAt: time.Now().Add(-time.Hour)},
+		{ID: "2", Type: Lesson, Summary: "发布前跑集成测试", Tags: []string{"release"}, HitCount: 1, CreatedAt: time.Now().Add(-time.Hour)},
+	}
+	manager.rebuildIndex()
+
+	detail := ""
+	updated, err := manager.Update("2", EntryPatch{Type: Decision, Detail: &detail, Tags: []string{"ci"}})
+	if err != nil || updated.ID != "2" || updated.Type != Decision || updated.Detail != "" || updated.Tags[0] != "ci" {
+		t.Fatalf("update = %#v, %v", updated, err)
+	}
+	merged, err := manager.Update("2", EntryPatch{Type: Lesson, Summary: "先备份再迁移数据库", Detail: &detail})
+	if err != nil || merged.ID != "1" || merged.HitCount != 3 || merged.Summary != "先备份再迁移数据库" {
+		t.Fatalf("merging update = %#v, %v, want merged into entry 1", merged, err)
+	}
+	if manager.Count() != 1 {
+		t.Fatalf("count after merge = %d, want 1", manager.Count())
+	}
+	if _, err := manager.Update("missing", EntryPatch{Summary: "x"}); err == nil {
+		t.Fatal("update of missing entry should fail")
+	}
+
+	removed, err := manager.Forget("1")
+	if err != nil || removed.Summary != "先备份再迁移数据库" || manager.Count() != 0 {
+		t.Fatalf("forget = %#v, %v, count = %d", removed, err, manager.Count())
+	}
+	if _, err := manager.Forget("1"); err == nil {
+		t.Fatal("forget of missing entry should fail")
+	}
+	if reloaded := NewManager(workspace, nil, nil).Count(); reloaded != 0 {
+		t.Fatalf("reloaded count = %d, want 0", reloaded)
+	}
+}
+
 type blockingLLMClient struct {
 	started chan struct{}
 	release chan struct{}
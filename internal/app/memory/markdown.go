@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	domainmemory "fkteams/internal/domain/memory"
 	"fkteams/internal/runtime/atomicfile"
 	"fkteams/internal/runtime/log"
 )
@@ -76,6 +77,11 @@ func writeMarkdownFile(path, title string, entries []MemoryEntry) error {
 		sb.WriteString("## ")
 		sb.WriteString(e.Summary)
 		sb.WriteString("\n\n")
+		if !e.Scope.IsGlobal() {
+			sb.WriteString("- 范围: ")
+			sb.WriteString(e.Scope.String())
+			sb.WriteString("\n")
+		}
 		sb.WriteString("- 详情: ")
 		sb.WriteString(e.Detail)
 		sb.WriteString("\n")
@@ -158,6 +164,7 @@ func loadMarkdownFile(path string, memType MemoryType, maxEntries int) ([]Memory
 			current = &MemoryEntry{
 				Summary: strings.TrimSpace(strings.TrimPrefix(line, "## ")),
 				Type:    memType,
+				Scope:   domainmemory.GlobalScope(),
 			}
 			continue
 		}
@@ -166,7 +173,14 @@ func loadMarkdownFile(path string, memType MemoryType, maxEntries int) ([]Memory
 			continue
 		}
 
-		if strings.HasPrefix(line, "- 详情: ") {
+		if strings.HasPrefix(line, "- 范围: ") {
+			// 无法识别的范围按全局处理，避免条目丢失。
+			scope, err := domainmemory.ParseScope(strings.TrimPrefix(line, "- 范围: "))
+			if err != nil {
+				log.Warnf("[memory] warn: %s: %v", filepath.Base(path), err)
+			}
+			current.Scope = scope.Normalize()
+		} else if strings.HasPrefix(line, "- 详情: ") {
 			current.Detail = strings.TrimPrefix(line, "- 详情: ")
 		} else if strings.HasPrefix(line, "- 标签: ") {
 			tagsStr := strings.TrimPrefix(line, "- 标签: ")
@@ -229,7 +243,11 @@ func writeIndexFile(dir string, entries []MemoryEntry) error {
 		}
 		fmt.Fprintf(&sb, "## %s (%d 条)\n\n", tf.Title, len(items))
 		for _, e := range items {
-			fmt.Fprintf(&sb, "- **%s**：%s\n", e.Summary, e.Detail)
+			if e.Scope.IsGlobal() {
+				fmt.Fprintf(&sb, "- **%s**：%s\n", e.Summary, e.Detail)
+			} else {
+				fmt.Fprintf(&sb, "- **%s** `%s`：%s\n", e.Summary, e.Scope, e.Detail)
+			}
 		}
 		sb.WriteString("\n")
 	}
@@ -14,7 +14,7 @@ func TestSaveAndLoadAllMarkdownRoundTrip(t *testing.T) {
 	created := time.Date(2026, 1, 1, 2, 3, 4, 0, time.Local)
 	entries := []MemoryEntry{
 		{Type: Preference, Summary: "偏好中文", Detail: "回答使用中文", Tags: []string{"中文", "风格"}, CreatedAt: created, HitCount: 3, LastHitAt: &lastHit},
-		{Type: Lesson, Summary: "先跑测试", Detail: "提交前完整验证", Tags: []string{"测试"}, CreatedAt: created, HitCount: 1},
+		{Type: Lesson, Summary: "先跑测试", Detail: "提交前完整验证", Tags: []string{"测试"}, CreatedAt: created, HitCount: 1, Scope: Scope{Kind: ScopeUser, Key: "qq:10001"}},
 	}
 
 	if err := saveAllMarkdown(dir, entries); err != nil {
@@ -39,6 +39,9 @@ func TestSaveAndLoadAllMarkdownRoundTrip(t *testing.T) {
 	if loaded[1].Summary != "先跑测试" || loaded[1].Type != Lesson {
 		t.Fatalf("loaded lesson = %#v", loaded[1])
 	}
+	if !loaded[0].Scope.IsGlobal() || loaded[1].Scope.String() != "user:qq:10001" {
+		t.Fatalf("loaded scopes = %s, %s", loaded[0].Scope, loaded[1].Scope)
+	}
 }
 
 func TestSaveAllMarkdownRemovesEmptyTypeFilesAndIndex(t *testing.T) {
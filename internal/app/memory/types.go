@@ -19,10 +19,32 @@ const (
 
 var AllMemoryTypes = domainmemory.AllMemoryTypes
 
+const (
+	ScopeGlobal    = domainmemory.ScopeGlobal
+	ScopeWorkspace = domainmemory.ScopeWorkspace
+	ScopeAgent     = domainmemory.ScopeAgent
+	ScopeUser      = domainmemory.ScopeUser
+)
+
+var AllScopeKinds = domainmemory.AllScopeKinds
+
+const (
+	SaveAdded   = domainmemory.SaveAdded
+	SaveUpdated = domainmemory.SaveUpdated
+	SaveSkipped = domainmemory.SaveSkipped
+)
+
 type TypeMeta = domainmemory.TypeMeta
 type MemoryEntry = domainmemory.MemoryEntry
 type Message = domainmemory.Message
+type Scope = domainmemory.Scope
+type ScopeKind = domainmemory.ScopeKind
+type ScopeContext = domainmemory.ScopeContext
+type SaveAction = domainmemory.SaveAction
+type SearchFilter = domainmemory.SearchFilter
+type EntryPatch = domainmemory.EntryPatch
 type LLMClient = memoryport.LLMClient
+type Embedder = memoryport.Embedder
 
 // typeOrder 类型展示顺序，injector 和 markdown 共用。
 var typeOrder = domainmemory.TypeOrder()
@@ -0,0 +1,251 @@
+package memory
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"fkteams/internal/runtime/atomicfile"
+	"fkteams/internal/runtime/log"
+)
+
+const (
+	vectorIndexFile     = "vectors.json"
+	maxVectorIndexBytes = 64 << 20
+)
+
+// vectorIndexData 是向量索引的磁盘格式，向量以小端 float32 的 base64 编码保存
+type vectorIndexData struct {
+	Model      string            `json:"model"`
+	Dimensions int               `json:"dimensions"`
+	Vectors    map[string]string `json:"vectors"`
+}
+
+// vectorStore 按条目内容哈希缓存向量，内容不变的条目不会重复计算；
+// 模型标识或向量维度变化时清空索引，由后续检索逐步重建
+type vectorStore struct {
+	mu      sync.Mutex
+	path    string
+	model   string
+	dims    int
+	vectors map[string][]float32
+	dirty   bool
+}
+
+// newVectorStore 从记忆目录加载向量索引，模型不一致时丢弃旧索引
+func newVectorStore(dir, model string) *vectorStore {
+	s := &vectorStore{
+		path:    filepath.Join(dir, vectorIndexFile),
+		model:   model,
+		vectors: make(map[string][]float32),
+	}
+	data, err := readVectorIndex(s.path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Warnf("[memory] warn: load vector index failed, rebuilding: %v", err)
+		}
+		return s
+	}
+	if data.Model != model {
+		log.Printf("[memory] embedding model changed (%s -> %s), rebuilding vector index", data.Model, model)
+		s.dirty = true
+		return s
+	}
+	for key, encoded := range data.Vectors {
+		vector, err := decodeVector(encoded)
+		if err != nil || len(vector) != data.Dimensions {
+			continue
+		}
+		s.vectors[key] = vector
+	}
+	s.dims = data.Dimensions
+	return s
+}
+
+func readVectorIndex(path string) (vectorIndexData, error) {
+	var data vectorIndexData
+	info, err := os.Stat(path)
+	if err != nil {
+		return data, err
+	}
+	if info.Size() > maxVectorIndexBytes {
+		return data, fmt.Errorf("vector index exceeds %d bytes", maxVectorIndexBytes)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return data, err
+	}
+	err = json.Unmarshal(raw, &data)
+	return data, err
+}
+
+// reset 切换到新的模型标识，标识不变时保留已有向量
+func (s *vectorStore) reset(model string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.model == model {
+		return
+	}
+	log.Printf("[memory] embedding model changed (%s -> %s), rebuilding vector index", s.model, model)
+	s.model = model
+	s.dims = 0
+	s.vectors = make(map[string][]float32)
+	s.dirty = true
+}
+
+// missing 返回尚未计算向量的内容哈希，按输入顺序去重
+func (s *vectorStore) missing(keys []string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make(map[string]bool, len(keys))
+	var result []string
+	for _, key := range keys {
+		if _, ok := s.vectors[key]; ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, key)
+	}
+	return result
+}
+
+// put 写入一批向量；model 与当前索引不一致时说明期间切换了模型，直接丢弃
+func (s *vectorStore) put(model string, keys []string, vectors [][]float32) error {
+	if len(keys) != len(vectors) {
+		return fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(keys))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if model != s.model {
+		return nil
+	}
+	for i, vector := range vectors {
+		if len(vector) == 0 {
+			return fmt.Errorf("embedder returned an empty vector")
+		}
+		if s.dims != 0 && len(vector) != s.dims {
+			log.Printf("[memory] embedding dimensions changed (%d -> %d), rebuilding vector index", s.dims, len(vector))
+			s.vectors = make(map[string][]float32)
+		}
+		s.dims = len(vector)
+		s.vectors[keys[i]] = normalizeVector(vector)
+		s.dirty = true
+	}
+	return nil
+}
+
+func (s *vectorStore) get(key string) ([]float32, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	vector, ok := s.vectors[key]
+	return vector, ok
+}
+
+// flush 移除已不存在的条目的向量，并在索引变化时写回磁盘
+func (s *vectorStore) flush(live map[string]bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.vectors {
+		if !live[key] {
+			delete(s.vectors, key)
+			s.dirty = true
+		}
+	}
+	if !s.dirty {
+		return nil
+	}
+	data := vectorIndexData{Model: s.model, Dimensions: s.dims, Vectors: make(map[string]string, len(s.vectors))}
+	for key, vector := range s.vectors {
+		data.Vectors[key] = encodeVector(vector)
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	if err := atomicfile.WriteFile(s.path, raw, 0644); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// vectorKey 返回条目内容的哈希，内容、类型或作用域变化后需要重新计算向量
+func vectorKey(entry MemoryEntry) string {
+	sum := sha256.Sum256([]byte(string(entry.Type) + "\x00" + entry.Scope.String() + "\x00" + embeddingText(entry)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// embeddingText 返回用于计算条目向量的文本
+func embeddingText(entry MemoryEntry) string {
+	parts := []string{entry.Summary}
+	if entry.Detail != "" {
+		parts = append(parts, entry.Detail)
+	}
+	if len(entry.Tags) > 0 {
+		parts = append(parts, strings.Join(entry.Tags, " "))
+	}
+	return strings.Join(parts, "\n")
+}
+
+func normalizeVector(vector []float32) []float32 {
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return vector
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	normalized := make([]float32, len(vector))
+	for i, v := range vector {
+		normalized[i] = v * scale
+	}
+	return normalized
+}
+
+// cosine 计算两个已归一化向量的余弦相似度，维度不一致时返回 0
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+func encodeVector(vector []float32) string {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+func decodeVector(encoded string) ([]float32, error) {
+	buf, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("invalid vector length %d", len(buf))
+	}
+	vector := make([]float32, len(buf)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return vector, nil
+}
@@ -0,0 +1,269 @@
+// Package project 管理具名工作区（项目），并在请求上下文中传递当前项目。
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"fkteams/internal/app/appdata"
+	"fkteams/internal/app/config"
+	"fkteams/internal/domain/apperror"
+)
+
+// DefaultName 是内置默认项目的名称，工作区为 ~/.fkteams/workspace。
+const DefaultName = config.DefaultProjectName
+
+// Project 是解析后的项目：工作区根目录和在该项目中运行时的默认值。
+type Project struct {
+	Name        string            `json:"name"`
+	Root        string            `json:"root"`
+	Description string            `json:"description,omitempty"`
+	Agent       string            `json:"agent,omitempty"`
+	Mode        string            `json:"mode,omitempty"`
+	AutoApprove []string          `json:"auto_approve,omitempty"`
+	Env         map[string]string `json:"env,omitempty"`
+	Sandbox     string            `json:"sandbox,omitempty"`
+	Builtin     bool              `json:"builtin,omitempty"`
+	Current     bool              `json:"current,omitempty"`
+}
+
+// Default 返回内置默认项目。
+func Default() Project {
+	return Project{Name: DefaultName, Root: appdata.WorkspaceDir(), Description: "默认工作区", Builtin: true}
+}
+
+func fromConfig(item config.ProjectConfig) Project {
+	return Project{
+		Name:        item.Name,
+		Root:        item.Root,
+		Description: item.Description,
+		Agent:       item.Agent,
+		Mode:        item.Mode,
+		AutoApprove: append([]string(nil), item.AutoApprove...),
+		Env:         cloneEnv(item.Env),
+		Sandbox:     item.Sandbox,
+	}
+}
+
+// List 返回默认项目和所有已注册项目，并标记当前项目。
+func List() []Project {
+	cfg := config.Get()
+	current := currentName(cfg)
+	projects := make([]Project, 0, len(cfg.Projects.Items)+1)
+	projects = append(projects, Default())
+	for _, item := range cfg.Projects.Items {
+		projects = append(projects, fromConfig(item))
+	}
+	for i := range projects {
+		projects[i].Current = projects[i].Name == current
+	}
+	return projects
+}
+
+// Resolve 按名称解析项目；名称为空时使用当前项目。
+func Resolve(name string) (Project, error) {
+	cfg := config.Get()
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = currentName(cfg)
+	}
+	if name == DefaultName {
+		return Default(), nil
+	}
+	item := cfg.ResolveProject(name)
+	if item == nil {
+		return Project{}, apperror.New(apperror.CodeNotFound, fmt.Sprintf("project %q not found", name))
+	}
+	return fromConfig(*item), nil
+}
+
+// Add 注册项目并保存配置；root 会转换为绝对路径且必须是已存在的目录。
+func Add(p Project) (Project, error) {
+	root, err := normalizeRoot(p.Root)
+	if err != nil {
+		return Project{}, err
+	}
+	cfg := config.Snapshot()
+	if p.Name == DefaultName || cfg.ResolveProject(p.Name) != nil {
+		return Project{}, apperror.New(apperror.CodeConflict, fmt.Sprintf("project %q already exists", p.Name))
+	}
+	item := config.ProjectConfig{
+		Name:        p.Name,
+		Root:        root,
+		Description: p.Description,
+		Agent:       p.Agent,
+		Mode:        p.Mode,
+		AutoApprove: p.AutoApprove,
+		Env:         p.Env,
+		Sandbox:     p.Sandbox,
+	}
+	cfg.Projects.Items = append(cfg.Projects.Items, item)
+	if err := save(cfg); err != nil {
+		return Project{}, err
+	}
+	return fromConfig(item), nil
+}
+
+// Remove 删除已注册的项目；删除当前项目时回退到默认项目。
+func Remove(name string) error {
+	cfg := config.Snapshot()
+	idx := slices.IndexFunc(cfg.Projects.Items, func(item config.ProjectConfig) bool { return item.Name == name })
+	if idx < 0 {
+		return apperror.New(apperror.CodeNotFound, fmt.Sprintf("project %q not found", name))
+	}
+	cfg.Projects.Items = slices.Delete(cfg.Projects.Items, idx, idx+1)
+	if cfg.Projects.Current == name {
+		cfg.Projects.Current = ""
+	}
+	return save(cfg)
+}
+
+// Use 将项目设为未指定项目时的当前项目。
+func Use(name string) (Project, error) {
+	p, err := Resolve(name)
+	if err != nil {
+		return Project{}, err
+	}
+	cfg := config.Snapshot()
+	cfg.Projects.Current = p.Name
+	if p.Builtin {
+		cfg.Projects.Current = ""
+	}
+	if err := save(cfg); err != nil {
+		return Project{}, err
+	}
+	p.Current = true
+	return p, nil
+}
+
+func save(cfg *config.Config) error {
+	if err := cfg.ValidateProjects(); err != nil {
+		return apperror.Wrap(apperror.CodeInvalidArgument, err.Error(), err)
+	}
+	if err := cfg.ValidateSandbox(); err != nil {
+		return apperror.Wrap(apperror.CodeInvalidArgument, err.Error(), err)
+	}
+	return config.Save(cfg)
+}
+
+func currentName(cfg *config.Config) string {
+	if cfg.Projects.Current == "" || cfg.ResolveProject(cfg.Projects.Current) == nil {
+		return DefaultName
+	}
+	return cfg.Projects.Current
+}
+
+func normalizeRoot(root string) (string, error) {
+	root = strings.TrimSpace(root)
+	if root == "" {
+		return "", apperror.New(apperror.CodeInvalidArgument, "project root is required")
+	}
+	if root == "~" || strings.HasPrefix(root, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", apperror.Wrap(apperror.CodeInvalidArgument, "resolve home directory", err)
+		}
+		root = filepath.Join(home, strings.TrimPrefix(root, "~"))
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return "", apperror.Wrap(apperror.CodeInvalidArgument, "resolve project root", err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", apperror.Wrap(apperror.CodeInvalidArgument, fmt.Sprintf("project root %s is not accessible", abs), err)
+	}
+	if !info.IsDir() {
+		return "", apperror.New(apperror.CodeInvalidArgument, fmt.Sprintf("project root %s is not a directory", abs))
+	}
+	return abs, nil
+}
+
+// EnvList 以 KEY=VALUE 形式返回项目环境变量，按键排序保证稳定。
+func (p Project) EnvList() []string {
+	if len(p.Env) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(p.Env))
+	for key := range p.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	env := make([]string, 0, len(keys))
+	for _, key := range keys {
+		env = append(env, key+"="+p.Env[key])
+	}
+	return env
+}
+
+func cloneEnv(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	cloned := make(map[string]string, len(values))
+	for key, value := range values {
+		cloned[key] = value
+	}
+	return cloned
+}
+
+type contextKey struct{}
+
+// WithProject 将当前项目注入 context。
+func WithProject(ctx context.Context, p Project) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext 返回 context 中的项目。
+func FromContext(ctx context.Context) (Project, bool) {
+	if ctx == nil {
+		return Project{}, false
+	}
+	p, ok := ctx.Value(contextKey{}).(Project)
+	return p, ok
+}
+
+type userAreaKey struct{}
+
+// WithUserArea 将智能体工具限定在项目中的成员文件区 dir：文件和命令类工具以该目录为工作区，
+// 不能访问其外的路径。项目根目录不变，仍用于匹配项目策略。
+func WithUserArea(ctx context.Context, dir string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, userAreaKey{}, dir)
+}
+
+// UserArea 返回 context 中的成员文件区，未限定时返回 false。
+func UserArea(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	dir, ok := ctx.Value(userAreaKey{}).(string)
+	return dir, ok && dir != ""
+}
+
+// WorkspaceDir 返回智能体工具的工作区：限定了成员文件区时为该目录，
+// 否则为 context 中项目的根目录，未绑定项目时返回默认工作区。
+func WorkspaceDir(ctx context.Context) string {
+	if dir, ok := UserArea(ctx); ok {
+		return dir
+	}
+	return RootDir(ctx)
+}
+
+// RootDir 返回 context 中项目的根目录，不受成员文件区影响；未绑定项目时返回默认工作区。
+func RootDir(ctx context.Context) string {
+	if p, ok := FromContext(ctx); ok && p.Root != "" {
+		return p.Root
+	}
+	return appdata.WorkspaceDir()
+}
@@ -0,0 +1,108 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"fkteams/internal/app/appdata"
+	"fkteams/internal/app/config"
+	"fkteams/internal/domain/apperror"
+	"fkteams/internal/runtime/env"
+)
+
+func setupProjects(t *testing.T) {
+	t.Helper()
+	t.Setenv(env.AppDir, t.TempDir())
+	if err := config.Save(&config.Config{}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+}
+
+func TestAddUseRemove(t *testing.T) {
+	setupProjects(t)
+	root := t.TempDir()
+
+	added, err := Add(Project{Name: "api", Root: root, Mode: "deep", AutoApprove: []string{"git"}, Env: map[string]string{"B": "2", "A": "1"}})
+	if err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if added.Root != root {
+		t.Fatalf("root = %q, want %q", added.Root, root)
+	}
+	if _, err := Add(Project{Name: "api", Root: root}); !apperror.IsCode(err, apperror.CodeConflict) {
+		t.Fatalf("duplicate Add error = %v", err)
+	}
+
+	current, err := Resolve("")
+	if err != nil || !current.Builtin {
+		t.Fatalf("Resolve(\"\") = %#v, %v; want default project", current, err)
+	}
+	if _, err := Use("api"); err != nil {
+		t.Fatalf("Use: %v", err)
+	}
+	current, err = Resolve("")
+	if err != nil || current.Name != "api" || current.Mode != "deep" {
+		t.Fatalf("Resolve(\"\") after Use = %#v, %v", current, err)
+	}
+	if got := current.EnvList(); !slices.Equal(got, []string{"A=1", "B=2"}) {
+		t.Fatalf("EnvList = %#v", got)
+	}
+
+	names := make([]string, 0)
+	for _, p := range List() {
+		if p.Current {
+			names = append(names, p.Name)
+		}
+	}
+	if !slices.Equal(names, []string{"api"}) {
+		t.Fatalf("current projects = %#v", names)
+	}
+
+	if err := Remove("api"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if config.Get().Projects.Current != "" {
+		t.Fatalf("current project was not reset: %q", config.Get().Projects.Current)
+	}
+	if _, err := Resolve("api"); !apperror.IsCode(err, apperror.CodeNotFound) {
+		t.Fatalf("Resolve removed project error = %v", err)
+	}
+}
+
+func TestAddRejectsInvalidProjects(t *testing.T) {
+	setupProjects(t)
+	file := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []Project{
+		{Name: "missing", Root: filepath.Join(t.TempDir(), "missing")},
+		{Name: "file", Root: file},
+		{Name: "bad name", Root: t.TempDir()},
+		{Name: DefaultName, Root: t.TempDir()},
+		{Name: "empty"},
+	} {
+		if _, err := Add(p); err == nil {
+			t.Fatalf("Add(%#v) succeeded", p)
+		}
+	}
+	if len(config.Get().Projects.Items) != 0 {
+		t.Fatalf("invalid projects were saved: %#v", config.Get().Projects.Items)
+	}
+}
+
+func TestWorkspaceDirFromContext(t *testing.T) {
+	setupProjects(t)
+	if got := WorkspaceDir(context.Background()); got != appdata.WorkspaceDir() {
+		t.Fatalf("WorkspaceDir without project = %q", got)
+	}
+	root := t.TempDir()
+	ctx := WithProject(context.Background(), Project{Name: "api", Root: root})
+	if got := WorkspaceDir(ctx); got != root {
+		t.Fatalf("WorkspaceDir = %q, want %q", got, root)
+	}
+}
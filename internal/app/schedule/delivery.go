@@ -0,0 +1,178 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	domainschedule "fkteams/internal/domain/schedule"
+	"fkteams/internal/runtime/atomicfile"
+	"fkteams/internal/runtime/log"
+)
+
+const (
+	// deliveryTimeout 是单个投递目标的发送时长上限。
+	deliveryTimeout = 30 * time.Second
+	// maxNotificationBytes 限制投递正文和其中的执行结果大小，完整结果仍可在历史记录中查看。
+	maxNotificationBytes = 64 << 10
+	maxSubjectBytes      = 256
+)
+
+const defaultDeliverySubject = `[fkteams] 定时任务{{if .Success}}完成{{else}}失败{{end}}：{{.Task}}`
+
+const defaultDeliveryTemplate = `定时任务{{if .Success}}执行完成{{else}}执行失败{{end}}
+任务：{{.Task}}
+任务 ID：{{.TaskID}}
+时间：{{.Time.Format "2006-01-02 15:04:05"}}
+
+{{if .Success}}{{.Result}}{{else}}错误：{{.Error}}{{end}}`
+
+var errNotificationTooLarge = errors.New("notification exceeds size limit")
+
+// Notification 是投递到外部目标的一次执行结果，也是主题和正文模板的数据。
+type Notification struct {
+	TaskID  string    `json:"task_id"`
+	Task    string    `json:"task"`
+	Success bool      `json:"success"`
+	Result  string    `json:"result,omitempty"`
+	Error   string    `json:"error,omitempty"`
+	Time    time.Time `json:"time"`
+	// Event 是触发本次执行的事件，按时间调度的执行为空。
+	Event *domainschedule.TriggerEvent `json:"event,omitempty"`
+	// Subject 和 Body 是模板渲染结果，渲染模板时为空。
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Sender 将通知发送到一种类型的投递目标，由适配层实现。
+type Sender interface {
+	Send(ctx context.Context, delivery domainschedule.Delivery, notification Notification) error
+}
+
+// WithSenders 设置各投递类型的发送器，未设置的类型投递时记为失败。
+func (e *BackgroundExecutor) WithSenders(senders map[domainschedule.DeliveryType]Sender) *BackgroundExecutor {
+	e.senders = senders
+	return e
+}
+
+// deliver 将执行结果投递到任务配置的目标，并在历史结果旁记录投递状态。
+// 投递失败不影响任务本身的执行结果。
+func (e *BackgroundExecutor) deliver(ctx context.Context, scheduled domainschedule.Task, historyName string, output string, runErr error) {
+	if len(scheduled.Deliveries) == 0 {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	notification := Notification{
+		TaskID:  scheduled.ID,
+		Task:    scheduled.Task,
+		Success: runErr == nil,
+		Time:    time.Now(),
+		Event:   scheduled.Event,
+	}
+	if runErr == nil {
+		notification.Result = truncateResult(output, maxNotificationBytes)
+	} else {
+		notification.Error = truncateResult(runErr.Error(), maxNotificationBytes)
+	}
+
+	var results []domainschedule.DeliveryResult
+	for _, delivery := range scheduled.Deliveries {
+		if !delivery.Matches(notification.Success) {
+			continue
+		}
+		result := domainschedule.DeliveryResult{
+			Type:   delivery.Type,
+			Target: delivery.Describe(),
+			Status: domainschedule.DeliverySent,
+		}
+		if err := e.send(ctx, delivery, notification); err != nil {
+			log.Printf("[scheduler] deliver task result failed: taskID=%s, type=%s, target=%s, err=%v", scheduled.ID, delivery.Type, result.Target, err)
+			result.Status = domainschedule.DeliveryFailed
+			result.Error = err.Error()
+		}
+		result.Time = time.Now()
+		results = append(results, result)
+	}
+	if len(results) == 0 || historyName == "" {
+		return
+	}
+	if err := e.writeDeliveryRecord(scheduled.ID, historyName, results); err != nil {
+		log.Printf("[scheduler] write delivery record failed: taskID=%s, err=%v", scheduled.ID, err)
+	}
+}
+
+func (e *BackgroundExecutor) send(ctx context.Context, delivery domainschedule.Delivery, notification Notification) error {
+	sender := e.senders[delivery.Type]
+	if sender == nil {
+		return fmt.Errorf("%s delivery is not available", delivery.Type)
+	}
+	subject, err := renderNotification("subject", delivery.Subject, defaultDeliverySubject, notification, maxSubjectBytes)
+	if err != nil {
+		return err
+	}
+	// 主题用于邮件头，折叠为单行并按字符边界截断。
+	subject = strings.ToValidUTF8(strings.Join(strings.Fields(subject), " "), "")
+	for len(subject) > maxSubjectBytes || !utf8.ValidString(subject) {
+		subject = subject[:len(subject)-1]
+	}
+	body, err := renderNotification("template", delivery.Template, defaultDeliveryTemplate, notification, maxNotificationBytes)
+	if err != nil {
+		return err
+	}
+	notification.Subject = subject
+	notification.Body = truncateResult(body, maxNotificationBytes)
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+	return sender.Send(ctx, delivery, notification)
+}
+
+// renderNotification 渲染主题或正文模板，输出超过 limit 时停止渲染，由调用方截断。
+func renderNotification(name, text, fallback string, data Notification, limit int) (string, error) {
+	if text == "" {
+		text = fallback
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse %s: %w", name, err)
+	}
+	out := &notificationBuffer{limit: limit}
+	if err := tmpl.Execute(out, data); err != nil && !errors.Is(err, errNotificationTooLarge) {
+		return "", fmt.Errorf("render %s: %w", name, err)
+	}
+	return out.String(), nil
+}
+
+// notificationBuffer 最多缓存 limit+1 字节，超出后中止模板执行。
+type notificationBuffer struct {
+	strings.Builder
+	limit int
+}
+
+func (b *notificationBuffer) Write(data []byte) (int, error) {
+	remaining := b.limit + 1 - b.Len()
+	if len(data) > remaining {
+		b.Builder.Write(data[:remaining])
+		return remaining, errNotificationTooLarge
+	}
+	return b.Builder.Write(data)
+}
+
+func (e *BackgroundExecutor) writeDeliveryRecord(taskID, historyName string, results []domainschedule.DeliveryResult) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	root, err := os.OpenRoot(e.resultsDir)
+	if err != nil {
+		return fmt.Errorf("open scheduler results directory: %w", err)
+	}
+	defer root.Close()
+	return atomicfile.WriteFileInRoot(root, filepath.Join(taskID, "history", domainschedule.DeliveryRecordName(historyName)), data, 0644)
+}
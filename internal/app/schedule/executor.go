@@ -12,6 +12,9 @@ import (
 	"unicode/utf8"
 
 	appchat "fkteams/internal/app/chat"
+	"fkteams/internal/app/project"
+	appusage "fkteams/internal/app/usage"
+	domainaccount "fkteams/internal/domain/account"
 	"fkteams/internal/domain/message"
 	domainschedule "fkteams/internal/domain/schedule"
 	domainsession "fkteams/internal/domain/session"
@@ -20,8 +23,8 @@ import (
 	"fkteams/internal/runtime/pathguard"
 )
 
-// RunnerCreator 为每次后台任务创建独立运行器。
-type RunnerCreator func(ctx context.Context) (runtimeport.Runner, error)
+// RunnerCreator 按任务的执行目标为每次后台任务创建独立运行器。
+type RunnerCreator func(ctx context.Context, target domainschedule.Target) (runtimeport.Runner, error)
 
 // BackgroundExecutor 将调度任务转换为一次后台聊天运行。
 type BackgroundExecutor struct {
@@ -29,6 +32,7 @@ type BackgroundExecutor struct {
 	resultsDir   string
 	chat         *appchat.Service
 	contextHook  func(context.Context) context.Context
+	senders      map[domainschedule.DeliveryType]Sender
 }
 
 // NewBackgroundExecutor 创建后台任务执行器。
@@ -68,21 +72,26 @@ func (e *BackgroundExecutor) taskResultPath(taskID string) string {
 	return filepath.Join(e.taskDir(taskID), "result.md")
 }
 
-// Execute 执行调度任务并写入当前结果和历史快照。
-func (e *BackgroundExecutor) Execute(ctx context.Context, taskID string, task string) (string, error) {
+// Execute 按任务的执行目标运行调度任务，并写入当前结果和历史快照。
+func (e *BackgroundExecutor) Execute(ctx context.Context, scheduled domainschedule.Task) (string, error) {
+	taskID, task := scheduled.ID, scheduled.Prompt()
 	if !domainsession.ValidID(taskID) || len(taskID) > 160 {
 		return "", fmt.Errorf("invalid task ID")
 	}
 	if e.contextHook != nil {
 		ctx = e.contextHook(ctx)
 	}
+	if scheduled.Owner != "" {
+		// 任务以创建者的成员身份运行，智能体只能管理该账号的数据
+		ctx = domainaccount.WithPrincipal(ctx, domainaccount.Principal{Username: scheduled.Owner, Role: domainaccount.RoleMember})
+	}
 	if e.createRunner == nil {
 		return "", fmt.Errorf("create runner: runner creator is nil")
 	}
 
-	r, err := e.createRunner(ctx)
+	ctx, r, err := e.prepareRunner(ctx, scheduled)
 	if err != nil {
-		return "", fmt.Errorf("create runner: %w", err)
+		return "", e.finishWithError(ctx, scheduled, err)
 	}
 
 	callback, getResult := newMarkdownCollector()
@@ -90,6 +99,11 @@ func (e *BackgroundExecutor) Execute(ctx context.Context, taskID string, task st
 		Message: message.Message{Role: message.RoleUser, Content: task},
 	}
 
+	ctx = appusage.WithScope(ctx, appusage.Scope{
+		Channel:   appusage.ChannelScheduler,
+		TaskID:    taskID,
+		TaskRunID: taskID + "_" + time.Now().Format("20060102_150405.000000000"),
+	})
 	_, err = e.chat.RunTurn(ctx, appchat.TurnRequest{
 		SessionID: "fkteams_scheduler_" + taskID,
 		Runner:    r,
@@ -97,21 +111,56 @@ func (e *BackgroundExecutor) Execute(ctx context.Context, taskID string, task st
 		EventSink: callback,
 	})
 	if err != nil {
-		errMsg := fmt.Sprintf("execution error: %v", err)
-		if writeErr := e.writeResult(taskID, task, errMsg); writeErr != nil {
-			return "", errors.Join(err, writeErr)
-		}
-		return "", err
+		return "", e.finishWithError(ctx, scheduled, err)
 	}
 
 	output := getResult()
-	if err := e.writeResult(taskID, task, output); err != nil {
+	historyName, err := e.writeResult(taskID, task, output)
+	if err != nil {
 		return "", err
 	}
+	e.deliver(ctx, scheduled, historyName, output, nil)
 	return output, nil
 }
 
-func (e *BackgroundExecutor) writeResult(taskID string, task string, result string) error {
+// finishWithError 记录执行错误并投递失败通知，返回原始错误和写入错误。
+func (e *BackgroundExecutor) finishWithError(ctx context.Context, scheduled domainschedule.Task, runErr error) error {
+	historyName, writeErr := e.writeResult(scheduled.ID, scheduled.Prompt(), fmt.Sprintf("execution error: %v", runErr))
+	e.deliver(ctx, scheduled, historyName, "", runErr)
+	if writeErr != nil {
+		return errors.Join(runErr, writeErr)
+	}
+	return runErr
+}
+
+// prepareRunner 将任务的工作目录绑定为项目后创建 Runner。
+func (e *BackgroundExecutor) prepareRunner(ctx context.Context, scheduled domainschedule.Task) (context.Context, runtimeport.Runner, error) {
+	if dir := scheduled.Target.WorkDir; dir != "" {
+		if err := checkWorkDir(dir); err != nil {
+			return ctx, nil, err
+		}
+		ctx = project.WithProject(ctx, project.Project{Name: "schedule", Root: dir, Description: "定时任务 " + scheduled.ID})
+	}
+	r, err := e.createRunner(ctx, scheduled.Target)
+	if err != nil {
+		return ctx, nil, fmt.Errorf("create runner: %w", err)
+	}
+	return ctx, r, nil
+}
+
+func checkWorkDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("work_dir unavailable: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("work_dir %s is not a directory", dir)
+	}
+	return nil
+}
+
+// writeResult 写入当前结果和历史快照，返回历史文件名。
+func (e *BackgroundExecutor) writeResult(taskID string, task string, result string) (string, error) {
 	now := time.Now()
 	ts := now.Format("20060102_150405")
 
@@ -122,31 +171,31 @@ func (e *BackgroundExecutor) writeResult(taskID string, task string, result stri
 	)
 	available := domainschedule.MaxResultFileBytes - len(header) - 1
 	if available < len(truncatedOutputMarker) {
-		return fmt.Errorf("task result metadata exceeds size limit")
+		return "", fmt.Errorf("task result metadata exceeds size limit")
 	}
 	result = truncateResult(result, available)
 	content := header + result + "\n"
 
 	root, err := os.OpenRoot(e.resultsDir)
 	if err != nil {
-		return fmt.Errorf("open scheduler results directory: %w", err)
+		return "", fmt.Errorf("open scheduler results directory: %w", err)
 	}
 	defer root.Close()
 	historyPath := filepath.Join(taskID, "history")
 	if err := pathguard.EnsureRootDirectory(root, historyPath, 0755); err != nil {
-		return fmt.Errorf("create task result directories: %w", err)
+		return "", fmt.Errorf("create task result directories: %w", err)
 	}
 	if err := atomicfile.WriteFileInRoot(root, filepath.Join(taskID, "result.md"), []byte(content), 0644); err != nil {
-		return fmt.Errorf("write task result: %w", err)
+		return "", fmt.Errorf("write task result: %w", err)
 	}
 
 	if err := atomicfile.WriteFileInRoot(root, filepath.Join(historyPath, ts+".md"), []byte(content), 0644); err != nil {
-		return fmt.Errorf("write task history: %w", err)
+		return "", fmt.Errorf("write task history: %w", err)
 	}
 	if err := pruneTaskHistory(root, historyPath); err != nil {
-		return fmt.Errorf("prune task history: %w", err)
+		return "", fmt.Errorf("prune task history: %w", err)
 	}
-	return nil
+	return ts + ".md", nil
 }
 
 func truncateResult(result string, limit int) string {
@@ -198,6 +247,9 @@ func pruneTaskHistory(root *os.Root, historyPath string) error {
 		if err := root.Remove(filepath.Join(historyPath, name)); err != nil {
 			return err
 		}
+		if err := root.Remove(filepath.Join(historyPath, domainschedule.DeliveryRecordName(name))); err != nil && !os.IsNotExist(err) {
+			return err
+		}
 	}
 	return nil
 }
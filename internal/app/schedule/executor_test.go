@@ -2,6 +2,7 @@ package schedule
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"strings"
 	"testing"
 
+	"fkteams/internal/app/project"
 	"fkteams/internal/domain/event"
 	"fkteams/internal/domain/message"
 	domainschedule "fkteams/internal/domain/schedule"
@@ -17,17 +19,26 @@ import (
 
 func TestBackgroundExecutorExecuteWritesResult(t *testing.T) {
 	resultsDir := t.TempDir()
-	executor, err := NewBackgroundExecutor(func(context.Context) (runtimeport.Runner, error) {
+	workDir := t.TempDir()
+	var gotTarget domainschedule.Target
+	var gotWorkspace string
+	executor, err := NewBackgroundExecutor(func(ctx context.Context, target domainschedule.Target) (runtimeport.Runner, error) {
+		gotTarget = target
+		gotWorkspace = project.WorkspaceDir(ctx)
 		return fakeRunner{content: "执行完成"}, nil
 	}, resultsDir)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	output, err := executor.Execute(context.Background(), "task-1", "生成报告")
+	target := domainschedule.Target{Agent: "researcher", Model: "cheap", WorkDir: workDir}
+	output, err := executor.Execute(context.Background(), domainschedule.Task{ID: "task-1", Task: "生成报告", Target: target})
 	if err != nil {
 		t.Fatalf("Execute: %v", err)
 	}
+	if gotTarget.Agent != "researcher" || gotTarget.Model != "cheap" || gotWorkspace != workDir {
+		t.Fatalf("target = %#v, workspace = %s", gotTarget, gotWorkspace)
+	}
 	if !strings.Contains(output, "执行完成") {
 		t.Fatalf("output = %q", output)
 	}
@@ -55,29 +66,34 @@ func TestBackgroundExecutorExecuteErrorPaths(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if _, err := executor.Execute(context.Background(), "task-nil", "task"); err == nil {
+	if _, err := executor.Execute(context.Background(), domainschedule.Task{ID: "task-nil", Task: "task"}); err == nil {
 		t.Fatal("expected nil runner creator error")
 	}
 
 	createErr := errors.New("create failed")
-	executor, err = NewBackgroundExecutor(func(context.Context) (runtimeport.Runner, error) {
+	executor, err = NewBackgroundExecutor(func(context.Context, domainschedule.Target) (runtimeport.Runner, error) {
 		return nil, createErr
 	}, t.TempDir())
 	if err != nil {
 		t.Fatal(err)
 	}
-	if _, err := executor.Execute(context.Background(), "task-create", "task"); !errors.Is(err, createErr) {
+	if _, err := executor.Execute(context.Background(), domainschedule.Task{ID: "task-create", Task: "task"}); !errors.Is(err, createErr) {
 		t.Fatalf("create runner error = %v", err)
 	}
 
+	missingDir := domainschedule.Task{ID: "task-dir", Task: "task", Target: domainschedule.Target{WorkDir: filepath.Join(t.TempDir(), "missing")}}
+	if _, err := executor.Execute(context.Background(), missingDir); err == nil || !strings.Contains(err.Error(), "work_dir") {
+		t.Fatalf("missing work_dir error = %v", err)
+	}
+
 	runErr := errors.New("run failed")
-	executor, err = NewBackgroundExecutor(func(context.Context) (runtimeport.Runner, error) {
+	executor, err = NewBackgroundExecutor(func(context.Context, domainschedule.Target) (runtimeport.Runner, error) {
 		return fakeRunner{err: runErr}, nil
 	}, t.TempDir())
 	if err != nil {
 		t.Fatal(err)
 	}
-	if _, err := executor.Execute(context.Background(), "task-run", "task"); !errors.Is(err, runErr) {
+	if _, err := executor.Execute(context.Background(), domainschedule.Task{ID: "task-run", Task: "task"}); !errors.Is(err, runErr) {
 		t.Fatalf("run error = %v", err)
 	}
 	content, err := os.ReadFile(executor.taskResultPath("task-run"))
@@ -89,6 +105,81 @@ func TestBackgroundExecutorExecuteErrorPaths(t *testing.T) {
 	}
 }
 
+func TestBackgroundExecutorDeliversResults(t *testing.T) {
+	runErr := errors.New("run failed")
+	var runnerErr error
+	executor, err := NewBackgroundExecutor(func(context.Context, domainschedule.Target) (runtimeport.Runner, error) {
+		return fakeRunner{content: "日报内容", err: runnerErr}, nil
+	}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhook := &recordingSender{}
+	executor.WithSenders(map[domainschedule.DeliveryType]Sender{
+		domainschedule.DeliveryWebhook: webhook,
+		domainschedule.DeliveryEmail:   &recordingSender{err: errors.New("smtp down")},
+	})
+	scheduled := domainschedule.Task{ID: "task-deliver", Task: "生成日报", Deliveries: []domainschedule.Delivery{
+		{Type: domainschedule.DeliveryWebhook, URL: "https://hooks.example/a?token=x", Template: "{{.TaskID}}:{{if .Success}}{{.Result}}{{else}}{{.Error}}{{end}}"},
+		{Type: domainschedule.DeliveryEmail, To: []string{"a@example.com"}, On: domainschedule.DeliverOnSuccess},
+		{Type: domainschedule.DeliveryChannel, Channel: "discord", ChatID: "1", On: domainschedule.DeliverOnFailure},
+	}}
+	if _, err := executor.Execute(context.Background(), scheduled); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(webhook.got) != 1 || webhook.got[0].Body != "task-deliver:日报内容" || !strings.Contains(webhook.got[0].Subject, "生成日报") {
+		t.Fatalf("webhook notifications = %#v", webhook.got)
+	}
+	records := readDeliveryRecords(t, executor, "task-deliver")
+	if len(records) != 2 || records[0].Status != domainschedule.DeliverySent || records[0].Target != "https://hooks.example/a" ||
+		records[1].Status != domainschedule.DeliveryFailed || records[1].Error != "smtp down" {
+		t.Fatalf("delivery records = %#v", records)
+	}
+
+	runnerErr = runErr
+	if _, err := executor.Execute(context.Background(), scheduled); !errors.Is(err, runErr) {
+		t.Fatalf("Execute error = %v", err)
+	}
+	if len(webhook.got) != 2 || webhook.got[1].Success || !strings.Contains(webhook.got[1].Body, "run failed") {
+		t.Fatalf("failure notification = %#v", webhook.got)
+	}
+}
+
+type recordingSender struct {
+	err error
+	got []Notification
+}
+
+func (s *recordingSender) Send(_ context.Context, _ domainschedule.Delivery, notification Notification) error {
+	s.got = append(s.got, notification)
+	return s.err
+}
+
+// readDeliveryRecords 读取最新一条历史结果的投递记录。
+func readDeliveryRecords(t *testing.T, executor *BackgroundExecutor, taskID string) []domainschedule.DeliveryResult {
+	t.Helper()
+	historyDir := filepath.Join(executor.taskDir(taskID), "history")
+	entries, err := os.ReadDir(historyDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var latest string
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) == ".md" {
+			latest = entry.Name()
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(historyDir, domainschedule.DeliveryRecordName(latest)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []domainschedule.DeliveryResult
+	if err := json.Unmarshal(data, &records); err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
 func TestNewBackgroundExecutorReportsInitializationFailure(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "results")
 	if err := os.WriteFile(path, []byte("not a directory"), 0644); err != nil {
@@ -114,7 +205,7 @@ func TestBackgroundExecutorPrunesHistory(t *testing.T) {
 			t.Fatal(err)
 		}
 	}
-	if err := executor.writeResult("task-prune", "task", "result"); err != nil {
+	if _, err := executor.writeResult("task-prune", "task", "result"); err != nil {
 		t.Fatal(err)
 	}
 	entries, err := os.ReadDir(historyDir)
@@ -136,7 +227,7 @@ func TestBackgroundExecutorRejectsSymlinkResultDirectory(t *testing.T) {
 	if err := os.Symlink(outside, executor.taskDir("task-linked")); err != nil {
 		t.Skipf("symlinks are unavailable: %v", err)
 	}
-	if err := executor.writeResult("task-linked", "task", "secret"); err == nil {
+	if _, err := executor.writeResult("task-linked", "task", "secret"); err == nil {
 		t.Fatal("writeResult() should reject a symlink task directory")
 	}
 	entries, err := os.ReadDir(outside)
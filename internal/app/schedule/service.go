@@ -2,7 +2,10 @@ package schedule
 
 import (
 	"context"
+	"strings"
 
+	"fkteams/internal/app/config"
+	domainaccount "fkteams/internal/domain/account"
 	"fkteams/internal/domain/apperror"
 	domainschedule "fkteams/internal/domain/schedule"
 	schedulerport "fkteams/internal/ports/scheduler"
@@ -60,9 +63,51 @@ func (s *Service) AddTask(ctx context.Context, req schedulerport.AddTaskRequest)
 	if err != nil {
 		return nil, err
 	}
+	if err := validateTargetModel(req.Target); err != nil {
+		return nil, err
+	}
+	req.Owner = domainaccount.OwnerFromContext(ctx)
 	return scheduler.AddTask(ctx, req)
 }
 
+// authorize 校验 context 中的账号可以管理任务，无权访问的任务按不存在处理。
+func authorize(ctx context.Context, scheduler schedulerport.TaskService, taskID string) error {
+	if _, ok := domainaccount.PrincipalFromContext(ctx); !ok {
+		return nil
+	}
+	tasks, err := scheduler.ListTasks(ctx, "")
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if task.ID == taskID {
+			if domainaccount.CanAccess(ctx, task.Owner) {
+				return nil
+			}
+			break
+		}
+	}
+	return apperror.New(apperror.CodeNotFound, "task not found")
+}
+
+// AssignOwner 将没有归属的任务归属到 owner，用于启用多账号时迁移单用户数据。
+func (s *Service) AssignOwner(ctx context.Context, owner string) (int, error) {
+	scheduler, err := s.requireScheduler()
+	if err != nil {
+		return 0, err
+	}
+	return scheduler.AssignOwner(ctx, owner)
+}
+
+// validateTargetModel 确认执行目标指定的模型已在配置中声明，避免任务到期后才失败。
+func validateTargetModel(target domainschedule.Target) error {
+	id := strings.TrimSpace(target.Model)
+	if id != "" && config.Get().ResolveModel(id) == nil {
+		return apperror.Errorf(apperror.CodeInvalidArgument, "model %q not found", id)
+	}
+	return nil
+}
+
 // UpdateTask 更新非运行中的调度任务。
 func (s *Service) UpdateTask(ctx context.Context, taskID string, req schedulerport.AddTaskRequest) (*domainschedule.Task, error) {
 	scheduler, err := s.requireScheduler()
@@ -72,6 +117,12 @@ func (s *Service) UpdateTask(ctx context.Context, taskID string, req schedulerpo
 	if taskID == "" {
 		return nil, apperror.New(apperror.CodeInvalidArgument, "task ID is required")
 	}
+	if err := validateTargetModel(req.Target); err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, scheduler, taskID); err != nil {
+		return nil, err
+	}
 	return scheduler.UpdateTask(ctx, taskID, req)
 }
 
@@ -84,7 +135,20 @@ func (s *Service) ListTasks(ctx context.Context, statusFilter domainschedule.Sta
 	if statusFilter != "" && !domainschedule.ValidStatus(statusFilter) {
 		return nil, apperror.Errorf(apperror.CodeInvalidArgument, "invalid task status: %s", statusFilter)
 	}
-	return scheduler.ListTasks(ctx, statusFilter)
+	tasks, err := scheduler.ListTasks(ctx, statusFilter)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := domainaccount.PrincipalFromContext(ctx); !ok {
+		return tasks, nil
+	}
+	visible := make([]domainschedule.Task, 0, len(tasks))
+	for _, task := range tasks {
+		if domainaccount.CanAccess(ctx, task.Owner) {
+			visible = append(visible, task)
+		}
+	}
+	return visible, nil
 }
 
 // CancelTask 取消待执行任务，或请求停止正在执行的任务。
@@ -96,6 +160,9 @@ func (s *Service) CancelTask(ctx context.Context, taskID string) error {
 	if taskID == "" {
 		return apperror.New(apperror.CodeInvalidArgument, "task ID is required")
 	}
+	if err := authorize(ctx, scheduler, taskID); err != nil {
+		return err
+	}
 	return scheduler.CancelTask(ctx, taskID)
 }
 
@@ -108,6 +175,9 @@ func (s *Service) DeleteTask(ctx context.Context, taskID string) error {
 	if taskID == "" {
 		return apperror.New(apperror.CodeInvalidArgument, "task ID is required")
 	}
+	if err := authorize(ctx, scheduler, taskID); err != nil {
+		return err
+	}
 	return scheduler.DeleteTask(ctx, taskID)
 }
 
@@ -120,6 +190,9 @@ func (s *Service) ReadTaskResult(ctx context.Context, taskID string) (string, er
 	if taskID == "" {
 		return "", apperror.New(apperror.CodeInvalidArgument, "task ID is required")
 	}
+	if err := authorize(ctx, scheduler, taskID); err != nil {
+		return "", err
+	}
 	return scheduler.ReadTaskResult(ctx, taskID)
 }
 
@@ -132,6 +205,9 @@ func (s *Service) ListHistoryEntries(ctx context.Context, taskID string) ([]doma
 	if taskID == "" {
 		return nil, apperror.New(apperror.CodeInvalidArgument, "task ID is required")
 	}
+	if err := authorize(ctx, scheduler, taskID); err != nil {
+		return nil, err
+	}
 	return scheduler.ListHistoryEntries(ctx, taskID)
 }
 
@@ -144,5 +220,8 @@ func (s *Service) ReadHistoryFile(ctx context.Context, taskID string, filename s
 	if taskID == "" || filename == "" {
 		return "", apperror.New(apperror.CodeInvalidArgument, "task ID and filename are required")
 	}
+	if err := authorize(ctx, scheduler, taskID); err != nil {
+		return "", err
+	}
 	return scheduler.ReadHistoryFile(ctx, taskID, filename)
 }
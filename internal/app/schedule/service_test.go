@@ -3,6 +3,7 @@ package schedule
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -46,6 +47,20 @@ func TestServiceDelegatesToSchedulerPort(t *testing.T) {
 	}
 }
 
+func TestServiceRejectsUnknownTargetModel(t *testing.T) {
+	fake := &fakeScheduler{}
+	_, err := NewService(fake).AddTask(context.Background(), schedulerport.AddTaskRequest{
+		Task:   "生成日报",
+		Target: domainschedule.Target{Model: "missing-model"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "missing-model") {
+		t.Fatalf("AddTask error = %v", err)
+	}
+	if fake.addReq.Task != "" {
+		t.Fatal("task with unknown model should not reach the scheduler")
+	}
+}
+
 func TestServiceRequiresScheduler(t *testing.T) {
 	if _, err := (*Service)(nil).ListTasks(context.Background(), ""); err == nil {
 		t.Fatal("expected nil service error")
@@ -64,6 +79,14 @@ type fakeScheduler struct {
 	updateID   string
 	listStatus domainschedule.Status
 	cancelID   string
+	// tasks 非空时作为 ListTasks 的结果。
+	tasks []domainschedule.Task
+	fired []firedTrigger
+}
+
+type firedTrigger struct {
+	taskID string
+	event  domainschedule.TriggerEvent
 }
 
 func (s *fakeScheduler) SetExecutor(schedulerport.TaskExecutor) {}
@@ -83,6 +106,9 @@ func (s *fakeScheduler) UpdateTask(ctx context.Context, taskID string, req sched
 
 func (s *fakeScheduler) ListTasks(ctx context.Context, statusFilter domainschedule.Status) ([]domainschedule.Task, error) {
 	s.listStatus = statusFilter
+	if s.tasks != nil {
+		return s.tasks, nil
+	}
 	return []domainschedule.Task{{ID: "task-1", Task: "生成日报", Status: domainschedule.StatusPending}}, nil
 }
 
@@ -91,6 +117,22 @@ func (s *fakeScheduler) CancelTask(ctx context.Context, taskID string) error {
 	return nil
 }
 
+func (s *fakeScheduler) FireTrigger(ctx context.Context, taskID string, event domainschedule.TriggerEvent) error {
+	s.fired = append(s.fired, firedTrigger{taskID: taskID, event: event})
+	return nil
+}
+
+func (s *fakeScheduler) AssignOwner(ctx context.Context, owner string) (int, error) {
+	assigned := 0
+	for i := range s.tasks {
+		if s.tasks[i].Owner == "" {
+			s.tasks[i].Owner = owner
+			assigned++
+		}
+	}
+	return assigned, nil
+}
+
 func (s *fakeScheduler) DeleteTask(ctx context.Context, taskID string) error {
 	return nil
 }
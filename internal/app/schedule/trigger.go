@@ -0,0 +1,111 @@
+package schedule
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"fkteams/internal/domain/apperror"
+	domainschedule "fkteams/internal/domain/schedule"
+	"fkteams/internal/runtime/log"
+)
+
+// webhookSignaturePrefix 是 webhook 触发请求签名的前缀，格式与 GitHub 的 X-Hub-Signature-256 相同。
+const webhookSignaturePrefix = "sha256="
+
+// WebhookCall 是一次 webhook 触发请求，Secret 和 Signature 提供其一即可。
+type WebhookCall struct {
+	// Secret 是请求直接携带的触发器密钥。
+	Secret string
+	// Signature 是 "sha256=<hex>" 格式的请求体 HMAC-SHA256 签名。
+	Signature string
+	Body      []byte
+	// Source 描述请求来源，写入触发事件。
+	Source string
+}
+
+// FireWebhook 校验 webhook 触发器的密钥或签名后，以请求体作为触发内容开始一次执行。
+func (s *Service) FireWebhook(ctx context.Context, taskID string, call WebhookCall) error {
+	scheduler, err := s.requireScheduler()
+	if err != nil {
+		return err
+	}
+	if taskID == "" {
+		return apperror.New(apperror.CodeInvalidArgument, "task ID is required")
+	}
+	tasks, err := scheduler.ListTasks(ctx, "")
+	if err != nil {
+		return err
+	}
+	var trigger *domainschedule.Trigger
+	for _, task := range tasks {
+		if task.ID == taskID && task.Trigger != nil && task.Trigger.Type == domainschedule.TriggerWebhook {
+			trigger = task.Trigger
+			break
+		}
+	}
+	if trigger == nil {
+		return apperror.New(apperror.CodeNotFound, "webhook trigger not found")
+	}
+	if !verifyWebhookCall(trigger.Secret, call) {
+		return apperror.New(apperror.CodeUnauthorized, "invalid trigger secret or signature")
+	}
+	event := domainschedule.NewTriggerEvent(domainschedule.TriggerWebhook, call.Source, string(call.Body), time.Now())
+	return scheduler.FireTrigger(ctx, taskID, event)
+}
+
+// verifyWebhookCall 以常量时间比较密钥或请求体签名。
+func verifyWebhookCall(secret string, call WebhookCall) bool {
+	if secret == "" {
+		return false
+	}
+	if call.Secret != "" {
+		return subtle.ConstantTimeCompare([]byte(call.Secret), []byte(secret)) == 1
+	}
+	signature, ok := strings.CutPrefix(strings.TrimSpace(call.Signature), webhookSignaturePrefix)
+	if !ok {
+		return false
+	}
+	provided, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(call.Body)
+	return hmac.Equal(provided, mac.Sum(nil))
+}
+
+// HandleChannelMessage 用通道收到的消息触发命中关键词的任务，返回触发的任务数。
+// 触发失败只记录日志，不影响消息的正常处理。
+func (s *Service) HandleChannelMessage(ctx context.Context, channel, chatID, senderID, text string) int {
+	if s == nil || s.scheduler == nil || strings.TrimSpace(text) == "" {
+		return 0
+	}
+	tasks, err := s.scheduler.ListTasks(ctx, "")
+	if err != nil {
+		log.Printf("[scheduler] list tasks for keyword triggers failed: %v", err)
+		return 0
+	}
+	fired := 0
+	for _, task := range tasks {
+		if task.Trigger == nil || !task.Trigger.MatchesMessage(channel, chatID, text) {
+			continue
+		}
+		if task.Status != domainschedule.StatusPending && task.Status != domainschedule.StatusRunning {
+			continue
+		}
+		source := fmt.Sprintf("%s:%s 发送者 %s", channel, chatID, senderID)
+		event := domainschedule.NewTriggerEvent(domainschedule.TriggerKeyword, source, text, time.Now())
+		if err := s.scheduler.FireTrigger(ctx, task.ID, event); err != nil {
+			log.Printf("[scheduler] keyword trigger failed: taskID=%s, err=%v", task.ID, err)
+			continue
+		}
+		fired++
+	}
+	return fired
+}
@@ -0,0 +1,156 @@
+package schedule
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fkteams/internal/domain/apperror"
+	domainschedule "fkteams/internal/domain/schedule"
+)
+
+func TestFireWebhookVerifiesSecretAndSignature(t *testing.T) {
+	const secret = "0123456789abcdef0123"
+	fake := &fakeScheduler{tasks: []domainschedule.Task{{
+		ID:      "hook-1",
+		Status:  domainschedule.StatusPending,
+		Trigger: &domainschedule.Trigger{Type: domainschedule.TriggerWebhook, Secret: secret},
+	}}}
+	service := NewService(fake)
+	body := []byte(`{"ref":"main"}`)
+
+	if err := service.FireWebhook(context.Background(), "hook-1", WebhookCall{Secret: secret, Body: body, Source: "test"}); err != nil {
+		t.Fatalf("FireWebhook with secret: %v", err)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if err := service.FireWebhook(context.Background(), "hook-1", WebhookCall{Signature: signature, Body: body}); err != nil {
+		t.Fatalf("FireWebhook with signature: %v", err)
+	}
+	if len(fake.fired) != 2 || fake.fired[0].event.Payload != string(body) || fake.fired[0].event.Type != domainschedule.TriggerWebhook {
+		t.Fatalf("fired = %#v", fake.fired)
+	}
+
+	for _, call := range []WebhookCall{{Secret: "wrong"}, {Signature: signature, Body: []byte("tampered")}, {}} {
+		if err := service.FireWebhook(context.Background(), "hook-1", call); apperror.CodeOf(err) != apperror.CodeUnauthorized {
+			t.Fatalf("FireWebhook(%#v) error = %v", call, err)
+		}
+	}
+	if err := service.FireWebhook(context.Background(), "missing", WebhookCall{Secret: secret}); apperror.CodeOf(err) != apperror.CodeNotFound {
+		t.Fatalf("FireWebhook missing error = %v", err)
+	}
+	if len(fake.fired) != 2 {
+		t.Fatalf("rejected calls should not fire, fired = %d", len(fake.fired))
+	}
+}
+
+func TestHandleChannelMessageFiresMatchingKeywordTriggers(t *testing.T) {
+	fake := &fakeScheduler{tasks: []domainschedule.Task{
+		{ID: "any", Status: domainschedule.StatusPending, Trigger: &domainschedule.Trigger{Type: domainschedule.TriggerKeyword, Keywords: []string{"Deploy"}}},
+		{ID: "other-chat", Status: domainschedule.StatusPending, Trigger: &domainschedule.Trigger{Type: domainschedule.TriggerKeyword, Keywords: []string{"deploy"}, Channel: "qq", ChatID: "g2"}},
+		{ID: "cancelled", Status: domainschedule.StatusCancelled, Trigger: &domainschedule.Trigger{Type: domainschedule.TriggerKeyword, Keywords: []string{"deploy"}}},
+		{ID: "cron", Status: domainschedule.StatusPending, CronExpr: "0 9 * * *"},
+	}}
+	service := NewService(fake)
+
+	if fired := service.HandleChannelMessage(context.Background(), "qq", "g1", "u1", "please DEPLOY now"); fired != 1 {
+		t.Fatalf("fired = %d", fired)
+	}
+	if got := fake.fired[0]; got.taskID != "any" || got.event.Payload != "please DEPLOY now" || !strings.Contains(got.event.Source, "qq:g1") {
+		t.Fatalf("fired = %#v", got)
+	}
+	if fired := service.HandleChannelMessage(context.Background(), "qq", "g1", "u1", "hello"); fired != 0 {
+		t.Fatalf("unmatched message fired %d tasks", fired)
+	}
+	if fired := (*Service)(nil).HandleChannelMessage(context.Background(), "qq", "g1", "u1", "deploy"); fired != 0 {
+		t.Fatalf("nil service fired %d tasks", fired)
+	}
+}
+
+func TestFileWatcherFiresAfterDebounce(t *testing.T) {
+	root := t.TempDir()
+	writeWatchedFile(t, filepath.Join(root, "docs", "a.md"), "a")
+	fake := &fakeScheduler{tasks: []domainschedule.Task{{
+		ID:      "watch-1",
+		Status:  domainschedule.StatusPending,
+		Target:  domainschedule.Target{WorkDir: root},
+		Trigger: &domainschedule.Trigger{Type: domainschedule.TriggerFile, Glob: "docs/**/*.md", Debounce: "10s"},
+	}}}
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	watcher := NewFileWatcher(NewService(fake), time.Second)
+	watcher.now = func() time.Time { return now }
+
+	watcher.poll(context.Background())
+	if len(fake.fired) != 0 {
+		t.Fatal("baseline scan should not fire")
+	}
+
+	writeWatchedFile(t, filepath.Join(root, "docs", "sub", "b.md"), "b")
+	writeWatchedFile(t, filepath.Join(root, "docs", "ignored.txt"), "x")
+	if err := os.Remove(filepath.Join(root, "docs", "a.md")); err != nil {
+		t.Fatal(err)
+	}
+	watcher.poll(context.Background())
+	now = now.Add(5 * time.Second)
+	watcher.poll(context.Background())
+	if len(fake.fired) != 0 {
+		t.Fatal("changes inside the debounce window should not fire")
+	}
+
+	now = now.Add(10 * time.Second)
+	watcher.poll(context.Background())
+	if len(fake.fired) != 1 {
+		t.Fatalf("fired = %d, want 1", len(fake.fired))
+	}
+	if payload := fake.fired[0].event.Payload; payload != "deleted: docs/a.md\ncreated: docs/sub/b.md" {
+		t.Fatalf("payload = %q", payload)
+	}
+
+	now = now.Add(time.Minute)
+	watcher.poll(context.Background())
+	if len(fake.fired) != 1 {
+		t.Fatal("unchanged files should not fire again")
+	}
+}
+
+func TestFileWatcherIgnoresChangesWhileRunning(t *testing.T) {
+	root := t.TempDir()
+	fake := &fakeScheduler{tasks: []domainschedule.Task{{
+		ID:      "watch-1",
+		Status:  domainschedule.StatusPending,
+		Target:  domainschedule.Target{WorkDir: root},
+		Trigger: &domainschedule.Trigger{Type: domainschedule.TriggerFile, Glob: "*.txt", Debounce: "1s"},
+	}}}
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	watcher := NewFileWatcher(NewService(fake), time.Second)
+	watcher.now = func() time.Time { return now }
+	watcher.poll(context.Background())
+
+	fake.tasks[0].Status = domainschedule.StatusRunning
+	writeWatchedFile(t, filepath.Join(root, "out.txt"), "written by the task")
+	watcher.poll(context.Background())
+
+	fake.tasks[0].Status = domainschedule.StatusPending
+	now = now.Add(time.Minute)
+	watcher.poll(context.Background())
+	if len(fake.fired) != 0 {
+		t.Fatalf("changes made while running should not fire, fired = %#v", fake.fired)
+	}
+}
+
+func writeWatchedFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,280 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fkteams/internal/app/appdata"
+	domainschedule "fkteams/internal/domain/schedule"
+	"fkteams/internal/runtime/log"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+const (
+	// DefaultFileWatchInterval 是文件触发器的轮询间隔。
+	DefaultFileWatchInterval = 2 * time.Second
+	// maxWatchedFiles 是单个文件触发器最多跟踪的文件数，超出时本轮扫描失败。
+	maxWatchedFiles = 10_000
+	// maxReportedChanges 是注入触发内容的变化文件数上限。
+	maxReportedChanges = 200
+)
+
+var errTooManyWatchedFiles = fmt.Errorf("glob matches more than %d files", maxWatchedFiles)
+
+// 文件变化类型，写入触发内容。
+const (
+	fileCreated  = "created"
+	fileModified = "modified"
+	fileDeleted  = "deleted"
+)
+
+// FileWatcher 轮询文件触发器监视的文件，文件变化后在防抖时间内不再变化时触发任务。
+// 首次扫描只建立基线；任务执行期间的变化只更新基线不触发，避免任务修改被监视的文件导致循环触发。
+type FileWatcher struct {
+	service   *Service
+	interval  time.Duration
+	workspace func() string
+	now       func() time.Time
+
+	lifecycleMu sync.Mutex
+	stopCh      chan struct{}
+	done        chan struct{}
+	states      map[string]*watchState
+}
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// watchState 是单个文件触发器的扫描状态。
+type watchState struct {
+	// key 由根目录和模式组成，触发条件变化后重新建立基线。
+	key        string
+	files      map[string]fileStamp
+	changes    map[string]string
+	lastChange time.Time
+	lastErr    string
+}
+
+// NewFileWatcher 创建文件触发器轮询器，interval 不大于 0 时使用默认间隔。
+func NewFileWatcher(service *Service, interval time.Duration) *FileWatcher {
+	if interval <= 0 {
+		interval = DefaultFileWatchInterval
+	}
+	return &FileWatcher{
+		service:   service,
+		interval:  interval,
+		workspace: appdata.WorkspaceDir,
+		now:       time.Now,
+		states:    make(map[string]*watchState),
+	}
+}
+
+// Start 在后台开始轮询，重复调用无效。
+func (w *FileWatcher) Start() {
+	w.lifecycleMu.Lock()
+	defer w.lifecycleMu.Unlock()
+	if w.stopCh != nil {
+		return
+	}
+	w.stopCh = make(chan struct{})
+	w.done = make(chan struct{})
+	go w.run(w.stopCh, w.done)
+}
+
+// Stop 停止轮询并等待当前一轮扫描结束。
+func (w *FileWatcher) Stop() {
+	w.lifecycleMu.Lock()
+	stopCh, done := w.stopCh, w.done
+	w.stopCh, w.done = nil, nil
+	w.lifecycleMu.Unlock()
+	if stopCh == nil {
+		return
+	}
+	close(stopCh)
+	<-done
+}
+
+func (w *FileWatcher) run(stopCh <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			w.poll(context.Background())
+		}
+	}
+}
+
+// poll 扫描一轮所有待触发的文件触发器。
+func (w *FileWatcher) poll(ctx context.Context) {
+	scheduler, err := w.service.requireScheduler()
+	if err != nil {
+		return
+	}
+	tasks, err := scheduler.ListTasks(ctx, "")
+	if err != nil {
+		log.Printf("[scheduler] list tasks for file triggers failed: %v", err)
+		return
+	}
+	active := make(map[string]bool)
+	for _, task := range tasks {
+		if task.Trigger == nil || task.Trigger.Type != domainschedule.TriggerFile {
+			continue
+		}
+		if task.Status != domainschedule.StatusPending && task.Status != domainschedule.StatusRunning {
+			continue
+		}
+		active[task.ID] = true
+		w.pollTask(ctx, task)
+	}
+	for taskID := range w.states {
+		if !active[taskID] {
+			delete(w.states, taskID)
+		}
+	}
+}
+
+func (w *FileWatcher) pollTask(ctx context.Context, task domainschedule.Task) {
+	root := task.Target.WorkDir
+	if root == "" {
+		root = w.workspace()
+	}
+	key := root + "\x00" + task.Trigger.Glob
+	state := w.states[task.ID]
+	if state == nil || state.key != key {
+		state = &watchState{key: key, changes: make(map[string]string)}
+		w.states[task.ID] = state
+	}
+
+	files, err := scanWatchedFiles(root, task.Trigger.Glob)
+	if err != nil {
+		// 同一错误只记录一次，避免每轮轮询刷屏。
+		if message := err.Error(); message != state.lastErr {
+			log.Printf("[scheduler] scan file trigger failed: taskID=%s, err=%v", task.ID, err)
+			state.lastErr = message
+		}
+		return
+	}
+	state.lastErr = ""
+	if state.files == nil {
+		state.files = files
+		return
+	}
+	changed := diffWatchedFiles(state.files, files)
+	state.files = files
+	if task.Status == domainschedule.StatusRunning {
+		clear(state.changes)
+		return
+	}
+
+	now := w.now()
+	if len(changed) > 0 {
+		mergeFileChanges(state.changes, changed)
+		state.lastChange = now
+	}
+	if len(state.changes) == 0 || now.Sub(state.lastChange) < task.Trigger.DebounceDuration() {
+		return
+	}
+	source := fmt.Sprintf("%d 个文件变化", len(state.changes))
+	event := domainschedule.NewTriggerEvent(domainschedule.TriggerFile, source, formatFileChanges(state.changes), now)
+	clear(state.changes)
+	if err := w.service.scheduler.FireTrigger(ctx, task.ID, event); err != nil {
+		log.Printf("[scheduler] file trigger failed: taskID=%s, err=%v", task.ID, err)
+	}
+}
+
+// scanWatchedFiles 返回根目录下匹配模式的普通文件，不跟随符号链接。
+func scanWatchedFiles(root, pattern string) (map[string]fileStamp, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+	files := make(map[string]fileStamp)
+	err = doublestar.GlobWalk(os.DirFS(root), pattern, func(path string, entry fs.DirEntry) error {
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		if len(files) >= maxWatchedFiles {
+			return errTooManyWatchedFiles
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// 扫描期间被删除的文件按不存在处理。
+			return nil
+		}
+		files[path] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	}, doublestar.WithFilesOnly(), doublestar.WithNoFollow())
+	if errors.Is(err, errTooManyWatchedFiles) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scan %s: %w", pattern, err)
+	}
+	return files, nil
+}
+
+func diffWatchedFiles(before, after map[string]fileStamp) map[string]string {
+	changed := make(map[string]string)
+	for path, stamp := range after {
+		previous, ok := before[path]
+		switch {
+		case !ok:
+			changed[path] = fileCreated
+		case previous.size != stamp.size || !previous.modTime.Equal(stamp.modTime):
+			changed[path] = fileModified
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed[path] = fileDeleted
+		}
+	}
+	return changed
+}
+
+// mergeFileChanges 合并防抖期间的变化：新建后修改仍算新建，新建后删除视为未变化。
+func mergeFileChanges(pending, changed map[string]string) {
+	for path, kind := range changed {
+		switch previous := pending[path]; {
+		case previous == fileCreated && kind == fileModified:
+		case previous == fileCreated && kind == fileDeleted:
+			delete(pending, path)
+		default:
+			pending[path] = kind
+		}
+	}
+}
+
+func formatFileChanges(changes map[string]string) string {
+	paths := make([]string, 0, len(changes))
+	for path := range changes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	var b strings.Builder
+	for i, path := range paths {
+		if i == maxReportedChanges {
+			fmt.Fprintf(&b, "... 另有 %d 个文件变化\n", len(paths)-maxReportedChanges)
+			break
+		}
+		fmt.Fprintf(&b, "%s: %s\n", changes[path], path)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
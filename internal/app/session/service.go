@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"fkteams/internal/app/project"
+	domainaccount "fkteams/internal/domain/account"
 	"fkteams/internal/domain/apperror"
 	domainsession "fkteams/internal/domain/session"
 	storageport "fkteams/internal/ports/storage"
@@ -21,6 +23,7 @@ type Service struct {
 type CreateRequest struct {
 	SessionID string
 	Title     string
+	Project   string
 }
 
 type UpdateRequest struct {
@@ -29,6 +32,21 @@ type UpdateRequest struct {
 	Favorite     *bool
 	Mode         *string
 	CurrentAgent *string
+	Project      *string
+}
+
+// ForkRequest 描述从某条记录事件分叉出新会话。
+type ForkRequest struct {
+	SessionID    string
+	EventID      string
+	NewSessionID string
+	Title        string
+}
+
+// RewindRequest 描述将会话回退到某条用户消息之前。
+type RewindRequest struct {
+	SessionID string
+	EventID   string
 }
 
 func NewService(repository storageport.SessionRepository) *Service {
@@ -42,12 +60,89 @@ func (s *Service) requireRepository() (storageport.SessionRepository, error) {
 	return s.repository, nil
 }
 
+// List 返回 context 中的账号可以访问的会话。
 func (s *Service) List(ctx context.Context) ([]domainsession.Record, error) {
 	repository, err := s.requireRepository()
 	if err != nil {
 		return nil, err
 	}
-	return repository.ListSessions(ctx)
+	records, err := repository.ListSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	visible := records[:0]
+	for _, record := range records {
+		if domainaccount.CanAccess(ctx, record.Metadata.Owner) {
+			visible = append(visible, record)
+		}
+	}
+	return visible, nil
+}
+
+// Authorize 校验 context 中的账号可以访问会话。会话尚不存在时放行，由首次对话创建并记录归属；
+// 无权访问的会话按不存在处理，避免泄露其他账号的会话 ID。
+func (s *Service) Authorize(ctx context.Context, sessionID string) error {
+	if _, ok := domainaccount.PrincipalFromContext(ctx); !ok {
+		return nil
+	}
+	repository, err := s.requireRepository()
+	if err != nil {
+		return err
+	}
+	metadata, err := repository.LoadSession(ctx, sessionID)
+	if apperror.IsCode(err, apperror.CodeNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !domainaccount.CanAccess(ctx, metadata.Owner) {
+		return sessionNotFound()
+	}
+	return nil
+}
+
+// AssignOwner 将没有归属的会话归属到 owner，返回修改的会话数。
+func (s *Service) AssignOwner(ctx context.Context, owner string) (int, error) {
+	repository, err := s.requireRepository()
+	if err != nil {
+		return 0, err
+	}
+	records, err := repository.ListSessions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	assigned := 0
+	for _, record := range records {
+		if record.Metadata.Owner != "" {
+			continue
+		}
+		if _, err := repository.UpdateSession(ctx, record.Metadata.ID, func(metadata *domainsession.Metadata) error {
+			if metadata.Owner == "" {
+				metadata.Owner = owner
+			}
+			return nil
+		}); err != nil {
+			return assigned, err
+		}
+		assigned++
+	}
+	return assigned, nil
+}
+
+func (s *Service) load(ctx context.Context, repository storageport.SessionRepository, sessionID string) (domainsession.Metadata, error) {
+	metadata, err := repository.LoadSession(ctx, sessionID)
+	if err != nil {
+		return metadata, err
+	}
+	if !domainaccount.CanAccess(ctx, metadata.Owner) {
+		return domainsession.Metadata{}, sessionNotFound()
+	}
+	return metadata, nil
+}
+
+func sessionNotFound() error {
+	return apperror.New(apperror.CodeNotFound, "session not found")
 }
 
 func (s *Service) Create(ctx context.Context, req CreateRequest) (domainsession.Metadata, bool, error) {
@@ -61,14 +156,26 @@ func (s *Service) Create(ctx context.Context, req CreateRequest) (domainsession.
 	if !domainsession.ValidID(req.SessionID) {
 		return domainsession.Metadata{}, false, apperror.New(apperror.CodeInvalidArgument, "invalid session ID")
 	}
+	projectName, err := normalizeProject(req.Project)
+	if err != nil {
+		return domainsession.Metadata{}, false, err
+	}
 	now := s.now()
 	metadata := domainsession.Metadata{
 		ID:        req.SessionID,
 		Title:     NormalizeTitle(req.Title),
 		Status:    domainsession.StatusIdle,
+		Project:   projectName,
+		Owner:     domainaccount.OwnerFromContext(ctx),
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
+	if err := s.Authorize(ctx, req.SessionID); err != nil {
+		if apperror.IsCode(err, apperror.CodeNotFound) {
+			return domainsession.Metadata{}, false, apperror.New(apperror.CodeConflict, "session already exists")
+		}
+		return domainsession.Metadata{}, false, err
+	}
 	return repository.CreateSession(ctx, metadata)
 }
 
@@ -77,7 +184,7 @@ func (s *Service) Get(ctx context.Context, sessionID string) (domainsession.Meta
 	if err != nil {
 		return domainsession.Metadata{}, err
 	}
-	return repository.LoadSession(ctx, sessionID)
+	return s.load(ctx, repository, sessionID)
 }
 
 func (s *Service) Update(ctx context.Context, req UpdateRequest) (domainsession.Metadata, error) {
@@ -85,7 +192,7 @@ func (s *Service) Update(ctx context.Context, req UpdateRequest) (domainsession.
 	if err != nil {
 		return domainsession.Metadata{}, err
 	}
-	if req.Title == nil && req.Favorite == nil && req.Mode == nil && req.CurrentAgent == nil {
+	if req.Title == nil && req.Favorite == nil && req.Mode == nil && req.CurrentAgent == nil && req.Project == nil {
 		return domainsession.Metadata{}, apperror.New(apperror.CodeInvalidArgument, "at least one session field is required")
 	}
 	if req.Title != nil {
@@ -93,6 +200,15 @@ func (s *Service) Update(ctx context.Context, req UpdateRequest) (domainsession.
 			return domainsession.Metadata{}, apperror.New(apperror.CodeInvalidArgument, "session title is required")
 		}
 	}
+	var projectName string
+	if req.Project != nil {
+		if projectName, err = normalizeProject(*req.Project); err != nil {
+			return domainsession.Metadata{}, err
+		}
+	}
+	if _, err := s.load(ctx, repository, req.SessionID); err != nil {
+		return domainsession.Metadata{}, err
+	}
 	return repository.UpdateSession(ctx, req.SessionID, func(metadata *domainsession.Metadata) error {
 		if req.Title != nil {
 			metadata.Title = NormalizeTitle(*req.Title)
@@ -106,6 +222,9 @@ func (s *Service) Update(ctx context.Context, req UpdateRequest) (domainsession.
 		if req.CurrentAgent != nil {
 			metadata.CurrentAgent = strings.TrimSpace(*req.CurrentAgent)
 		}
+		if req.Project != nil {
+			metadata.Project = projectName
+		}
 		metadata.UpdatedAt = s.now()
 		return nil
 	})
@@ -116,9 +235,89 @@ func (s *Service) Delete(ctx context.Context, sessionID string) error {
 	if err != nil {
 		return err
 	}
+	if _, err := s.load(ctx, repository, sessionID); err != nil {
+		return err
+	}
 	return repository.DeleteSession(ctx, sessionID)
 }
 
+// Fork 复制源会话截至 EventID（含）的记录、附件和待办状态到新会话，并沿用源会话的模式、智能体和项目。
+func (s *Service) Fork(ctx context.Context, req ForkRequest) (domainsession.Metadata, error) {
+	repository, err := s.requireRepository()
+	if err != nil {
+		return domainsession.Metadata{}, err
+	}
+	req.EventID = strings.TrimSpace(req.EventID)
+	if req.EventID == "" {
+		return domainsession.Metadata{}, apperror.New(apperror.CodeInvalidArgument, "event_id is required")
+	}
+	if req.NewSessionID == "" {
+		req.NewSessionID = domainsession.NewID()
+	}
+	if !domainsession.ValidID(req.NewSessionID) {
+		return domainsession.Metadata{}, apperror.New(apperror.CodeInvalidArgument, "invalid session ID")
+	}
+	source, err := s.load(ctx, repository, req.SessionID)
+	if err != nil {
+		return domainsession.Metadata{}, err
+	}
+	title := req.Title
+	if strings.TrimSpace(title) == "" {
+		title = source.Title + " (分支)"
+	}
+	now := s.now()
+	metadata := domainsession.Metadata{
+		ID:           req.NewSessionID,
+		Title:        NormalizeTitle(title),
+		Status:       domainsession.StatusIdle,
+		Mode:         source.Mode,
+		CurrentAgent: source.CurrentAgent,
+		Project:      source.Project,
+		Owner:        forkOwner(ctx, source),
+		ForkedFrom:   source.ID,
+		ForkedAt:     req.EventID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	return repository.ForkSession(ctx, req.SessionID, req.EventID, metadata)
+}
+
+// Rewind 丢弃 EventID 指向的用户消息及其后的记录，返回该消息以便编辑后重新发送。
+func (s *Service) Rewind(ctx context.Context, req RewindRequest) (domainsession.RewindResult, error) {
+	repository, err := s.requireRepository()
+	if err != nil {
+		return domainsession.RewindResult{}, err
+	}
+	req.EventID = strings.TrimSpace(req.EventID)
+	if req.EventID == "" {
+		return domainsession.RewindResult{}, apperror.New(apperror.CodeInvalidArgument, "event_id is required")
+	}
+	if _, err := s.load(ctx, repository, req.SessionID); err != nil {
+		return domainsession.RewindResult{}, err
+	}
+	return repository.RewindSession(ctx, req.SessionID, req.EventID)
+}
+
+// forkOwner 返回分支会话的归属：已登录时归属发起分叉的账号，否则沿用源会话。
+func forkOwner(ctx context.Context, source domainsession.Metadata) string {
+	if owner := domainaccount.OwnerFromContext(ctx); owner != "" {
+		return owner
+	}
+	return source.Owner
+}
+
+// normalizeProject 校验项目存在；为空表示会话尚未绑定项目，首次运行时绑定当前项目。
+func normalizeProject(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil
+	}
+	if _, err := project.Resolve(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
 // NormalizeTitle 统一创建和更新会话时的标题规则。
 func NormalizeTitle(title string) string {
 	title = strings.TrimSpace(title)
@@ -6,8 +6,10 @@ import (
 	"testing"
 	"time"
 
+	"fkteams/internal/app/config"
 	"fkteams/internal/domain/apperror"
 	domainsession "fkteams/internal/domain/session"
+	"fkteams/internal/runtime/env"
 )
 
 type memoryRepository struct {
@@ -58,6 +60,22 @@ func (r *memoryRepository) DeleteSession(_ context.Context, id string) error {
 	return nil
 }
 
+func (r *memoryRepository) ForkSession(_ context.Context, sourceID, _ string, metadata domainsession.Metadata) (domainsession.Metadata, error) {
+	if _, ok := r.items[sourceID]; !ok {
+		return domainsession.Metadata{}, apperror.New(apperror.CodeNotFound, "session not found")
+	}
+	r.items[metadata.ID] = metadata
+	return metadata, nil
+}
+
+func (r *memoryRepository) RewindSession(_ context.Context, id, _ string) (domainsession.RewindResult, error) {
+	metadata, ok := r.items[id]
+	if !ok {
+		return domainsession.RewindResult{}, apperror.New(apperror.CodeNotFound, "session not found")
+	}
+	return domainsession.RewindResult{Metadata: metadata}, nil
+}
+
 func TestCreateAndPatchUseConsistentNormalization(t *testing.T) {
 	repository := &memoryRepository{items: make(map[string]domainsession.Metadata)}
 	service := NewService(repository)
@@ -94,3 +112,54 @@ func TestUpdateRequiresAtLeastOneField(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestCreateAndUpdateValidateProject(t *testing.T) {
+	t.Setenv(env.AppDir, t.TempDir())
+	root := t.TempDir()
+	if err := config.Save(&config.Config{Projects: config.Projects{Items: []config.ProjectConfig{{Name: "api", Root: root}}}}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	repository := &memoryRepository{items: make(map[string]domainsession.Metadata)}
+	service := NewService(repository)
+
+	metadata, _, err := service.Create(context.Background(), CreateRequest{SessionID: "session-1", Project: "api"})
+	if err != nil || metadata.Project != "api" {
+		t.Fatalf("create with project: %#v, %v", metadata, err)
+	}
+	if _, _, err := service.Create(context.Background(), CreateRequest{SessionID: "session-2", Project: "missing"}); !apperror.IsCode(err, apperror.CodeNotFound) {
+		t.Fatalf("create with unknown project error = %v", err)
+	}
+
+	other := "default"
+	updated, err := service.Update(context.Background(), UpdateRequest{SessionID: "session-1", Project: &other})
+	if err != nil || updated.Project != "default" {
+		t.Fatalf("update project: %#v, %v", updated, err)
+	}
+}
+
+func TestForkCopiesSourceSettings(t *testing.T) {
+	repository := &memoryRepository{items: map[string]domainsession.Metadata{
+		"session-1": {ID: "session-1", Title: "调试", Mode: "deep", CurrentAgent: "coder", Project: "api", Favorite: true},
+	}}
+	service := NewService(repository)
+
+	forked, err := service.Fork(context.Background(), ForkRequest{SessionID: "session-1", EventID: " evt-3 "})
+	if err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	if forked.ID == "" || forked.ID == "session-1" || forked.Title != "调试 (分支)" || forked.Favorite {
+		t.Fatalf("forked metadata = %#v", forked)
+	}
+	if forked.Mode != "deep" || forked.CurrentAgent != "coder" || forked.Project != "api" || forked.ForkedFrom != "session-1" || forked.ForkedAt != "evt-3" {
+		t.Fatalf("forked settings = %#v", forked)
+	}
+	if _, err := service.Fork(context.Background(), ForkRequest{SessionID: "session-1"}); !apperror.IsCode(err, apperror.CodeInvalidArgument) {
+		t.Fatalf("Fork without event error = %v", err)
+	}
+	if _, err := service.Fork(context.Background(), ForkRequest{SessionID: "missing", EventID: "evt-1"}); !apperror.IsCode(err, apperror.CodeNotFound) {
+		t.Fatalf("Fork unknown session error = %v", err)
+	}
+	if _, err := service.Rewind(context.Background(), RewindRequest{SessionID: "session-1", EventID: " "}); !apperror.IsCode(err, apperror.CodeInvalidArgument) {
+		t.Fatalf("Rewind without event error = %v", err)
+	}
+}
@@ -13,6 +13,7 @@ type ToolGroupInfo struct {
 	Category      string   `json:"category"`
 	Builtin       bool     `json:"builtin"`
 	IncludedTools []string `json:"included_tools,omitempty"`
+	Sandboxed     bool     `json:"sandboxed,omitempty"` // 工具在配置的执行沙箱中运行子进程
 	Hidden        bool     `json:"-"`
 }
 
@@ -0,0 +1,17 @@
+package tools
+
+import (
+	"fkteams/internal/app/appdata"
+	"fkteams/internal/runtime/approval"
+)
+
+// PolicyFiles 返回工具权限策略文件，项目策略排在全局策略之前，优先匹配。
+// 两者都在应用数据目录中，workspaceDir 为空时使用默认工作区的项目策略。
+func PolicyFiles(workspaceDir string) []string {
+	return []string{appdata.ProjectPolicyFile(workspaceDir), appdata.PolicyFile()}
+}
+
+// LoadPolicy 读取项目和全局策略文件；两者都不存在时返回空策略。
+func LoadPolicy(workspaceDir string) (*approval.Policy, error) {
+	return approval.LoadPolicyFiles(PolicyFiles(workspaceDir)...)
+}
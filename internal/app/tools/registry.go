@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 
+	"fkteams/internal/app/project"
 	runtimeport "fkteams/internal/ports/runtime"
 	storageport "fkteams/internal/ports/storage"
 	toolport "fkteams/internal/ports/tools"
@@ -13,11 +14,14 @@ import (
 
 type ToolResolveContext struct {
 	WorkspaceDir  string
+	Env           []string // 命令类工具的附加环境变量，来自会话所属项目
 	SessionsDir   string
 	RuntimeDir    string
 	Cleaner       *resources.Cleaner
 	Config        any
 	SSH           *SSHConfig
+	Sandbox       string // 命令类工具使用的沙箱配置名，为空时使用全局默认配置
+	ConfinedRoot  string // 项目根目录，非空时工具限定在 WorkspaceDir（成员文件区）中，命令类工具须在隐藏该目录的沙箱中运行
 	HistoryReader storageport.SessionMessageReader
 }
 
@@ -170,9 +174,22 @@ func (r *ToolGroupRegistry) ResolveContextFor(ctx context.Context, cleaner *reso
 	if cleaner != nil {
 		resolveCtx.Cleaner = cleaner
 	}
+	// 会话绑定了项目时，工作区和路径校验根目录都取项目根目录。
+	if p, ok := project.FromContext(ctx); ok && p.Root != "" {
+		resolveCtx.WorkspaceDir = p.Root
+		resolveCtx.Env = p.EnvList()
+	}
+	if p, ok := project.FromContext(ctx); ok && p.Sandbox != "" {
+		resolveCtx.Sandbox = p.Sandbox
+	}
 	if patch, ok := resolveContextPatchFromContext(ctx); ok {
 		resolveCtx = mergeResolveContext(resolveCtx, patch)
 	}
+	// 成员文件区最后应用，不会被智能体级别的补丁放宽。
+	if area, ok := project.UserArea(ctx); ok {
+		resolveCtx.ConfinedRoot = project.RootDir(ctx)
+		resolveCtx.WorkspaceDir = area
+	}
 	return resolveCtx
 }
 
@@ -180,6 +197,9 @@ func mergeResolveContext(base, patch ToolResolveContext) ToolResolveContext {
 	if patch.WorkspaceDir != "" {
 		base.WorkspaceDir = patch.WorkspaceDir
 	}
+	if patch.Env != nil {
+		base.Env = patch.Env
+	}
 	if patch.SessionsDir != "" {
 		base.SessionsDir = patch.SessionsDir
 	}
@@ -195,6 +215,9 @@ func mergeResolveContext(base, patch ToolResolveContext) ToolResolveContext {
 	if patch.SSH != nil {
 		base.SSH = patch.SSH
 	}
+	if patch.Sandbox != "" {
+		base.Sandbox = patch.Sandbox
+	}
 	if patch.HistoryReader != nil {
 		base.HistoryReader = patch.HistoryReader
 	}
@@ -5,6 +5,7 @@ import (
 	"slices"
 	"testing"
 
+	"fkteams/internal/app/project"
 	runtimeport "fkteams/internal/ports/runtime"
 )
 
@@ -113,3 +114,29 @@ func TestToolGroupRegistryResolveUsesContextPatch(t *testing.T) {
 		t.Fatalf("resolve demo = ok:%v tools:%d, want one tool", ok, len(resolved))
 	}
 }
+
+func TestToolGroupRegistryResolveContextUsesProject(t *testing.T) {
+	registry := NewToolGroupRegistry(ToolResolveContext{WorkspaceDir: "/base"})
+	ctx := project.WithProject(context.Background(), project.Project{Name: "api", Root: "/work/api", Env: map[string]string{"GOFLAGS": "-mod=mod"}})
+
+	resolved := registry.ResolveContextFor(ctx, nil)
+	if resolved.WorkspaceDir != "/work/api" || !slices.Equal(resolved.Env, []string{"GOFLAGS=-mod=mod"}) {
+		t.Fatalf("resolve context = %#v, want project workspace and env", resolved)
+	}
+
+	patched := registry.ResolveContextFor(WithResolveContextPatch(ctx, ToolResolveContext{WorkspaceDir: "/override"}), nil)
+	if patched.WorkspaceDir != "/override" {
+		t.Fatalf("patched workspace = %q, want /override", patched.WorkspaceDir)
+	}
+}
+
+func TestToolGroupRegistryResolveContextConfinesUserArea(t *testing.T) {
+	registry := NewToolGroupRegistry(ToolResolveContext{WorkspaceDir: "/base"})
+	ctx := project.WithProject(context.Background(), project.Project{Name: "api", Root: "/work/api"})
+	ctx = project.WithUserArea(ctx, "/work/api/.fkteams/users/alice")
+
+	resolved := registry.ResolveContextFor(WithResolveContextPatch(ctx, ToolResolveContext{WorkspaceDir: "/override"}), nil)
+	if resolved.WorkspaceDir != "/work/api/.fkteams/users/alice" || resolved.ConfinedRoot != "/work/api" {
+		t.Fatalf("resolve context = %#v, want member area confined to project root", resolved)
+	}
+}
@@ -0,0 +1,138 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"fkteams/internal/app/appdata"
+	"fkteams/internal/app/config"
+	"fkteams/internal/app/project"
+	"fkteams/internal/domain/event"
+	"fkteams/internal/runtime/sandbox"
+)
+
+// SandboxExecutor 返回沙箱配置名对应的执行后端；名称为空时使用全局默认配置，都未配置时直接执行。
+// 配置名不存在时拒绝执行，不会悄悄改为直接执行。
+func SandboxExecutor(name string) sandbox.Executor {
+	profile, ok, err := sandboxProfile(name)
+	if err != nil {
+		return sandbox.Refuse(name, err.Error())
+	}
+	if !ok {
+		return sandbox.Direct()
+	}
+	return sandbox.New(profile)
+}
+
+// ConfinedSandboxExecutor 返回成员会话使用的执行后端：必须在 Linux 沙箱中运行，并隐藏项目根目录 root，
+// 只有成员文件区（命令工作目录）重新挂载进来；没有可用的沙箱时拒绝执行。
+func ConfinedSandboxExecutor(name, root string) sandbox.Executor {
+	profile, ok, err := sandboxProfile(name)
+	if err != nil {
+		return sandbox.Refuse(name, err.Error())
+	}
+	if !ok || profile.Backend != sandbox.BackendLinux {
+		return sandbox.Refuse(profile.Name, "non-admin accounts can only run commands in a sandbox")
+	}
+	profile.Hidden = append(profile.Hidden, root)
+	profile.Required = true
+	return sandbox.New(profile)
+}
+
+func sandboxProfile(name string) (sandbox.Profile, bool, error) {
+	cfg := config.Get()
+	if name == "" {
+		name = cfg.Sandbox.Default
+	}
+	if name == "" {
+		return sandbox.Profile{}, false, nil
+	}
+	if name == config.SandboxDirect {
+		return sandbox.Profile{Name: name, Backend: sandbox.BackendDirect}, true, nil
+	}
+	item := cfg.ResolveSandboxProfile(name)
+	if item == nil {
+		return sandbox.Profile{}, false, fmt.Errorf("sandbox profile %q not found", name)
+	}
+	// 家目录和应用数据目录中有配置、密钥库、审计日志和 SSH 密钥，沙箱中以空目录遮住
+	hidden := []string{appdata.Dir()}
+	if home, err := os.UserHomeDir(); err == nil {
+		hidden = append(hidden, home)
+	}
+	return sandbox.Profile{
+		Name:         item.Name,
+		Backend:      item.Backend,
+		Network:      item.Network,
+		Writable:     append([]string(nil), item.Writable...),
+		Hidden:       hidden,
+		ReadOnly:     append([]string(nil), item.ReadOnly...),
+		PassEnv:      append([]string(nil), item.PassEnv...),
+		CPUSeconds:   item.CPUSeconds,
+		MemoryMB:     item.MemoryMB,
+		MaxProcesses: item.MaxProcesses,
+		Required:     item.Required,
+	}, true, nil
+}
+
+// agentSandboxName 返回智能体在当前项目中使用的沙箱配置名：智能体配置优先，其次是项目配置。
+func agentSandboxName(ctx context.Context, agentName string) string {
+	for _, item := range config.Get().Agents.Items {
+		if item.ID == agentName && item.Sandbox != "" {
+			return item.Sandbox
+		}
+	}
+	if p, ok := project.FromContext(ctx); ok {
+		return p.Sandbox
+	}
+	return ""
+}
+
+// SandboxEventSink 为沙箱化工具的 tool_call_started 事件补充实际生效的沙箱配置，
+// 未配置沙箱时事件保持不变。
+func SandboxEventSink(ctx context.Context, sink func(event.Event) error) func(event.Event) error {
+	if sink == nil {
+		return nil
+	}
+	registry, ok := RegistryFromContext(ctx)
+	if !ok {
+		return sink
+	}
+	sandboxed := make(map[string]struct{})
+	for _, info := range registry.Infos() {
+		if info.Sandboxed {
+			for _, name := range info.IncludedTools {
+				sandboxed[name] = struct{}{}
+			}
+		}
+	}
+	if len(sandboxed) == 0 {
+		return sink
+	}
+	// 并行成员可能并发上报事件，按智能体缓存解析结果时需要加锁。
+	var mu sync.Mutex
+	byAgent := make(map[string]*event.SandboxPayload)
+	payloadFor := func(agentName string) *event.SandboxPayload {
+		mu.Lock()
+		defer mu.Unlock()
+		payload, seen := byAgent[agentName]
+		if !seen {
+			name := agentSandboxName(ctx, agentName)
+			if _, ok, err := sandboxProfile(name); ok || err != nil {
+				info := SandboxExecutor(name).Info()
+				payload = &event.SandboxPayload{Profile: info.Profile, Backend: info.Backend, Network: info.Network, Fallback: info.Fallback}
+			}
+			byAgent[agentName] = payload
+		}
+		return payload
+	}
+	return func(e event.Event) error {
+		if e.Type == event.TypeToolCallStarted && e.Sandbox == nil {
+			if _, ok := sandboxed[e.ToolName]; ok {
+				e.Sandbox = payloadFor(e.AgentName)
+			}
+		}
+		return sink(e)
+	}
+}
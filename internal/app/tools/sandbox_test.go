@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"fkteams/internal/app/config"
+	"fkteams/internal/app/project"
+	"fkteams/internal/domain/event"
+	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/env"
+	"fkteams/internal/runtime/sandbox"
+)
+
+func TestResolveContextSandboxPrefersAgentOverProject(t *testing.T) {
+	registry := NewToolGroupRegistry()
+	ctx := project.WithProject(context.Background(), project.Project{Name: "api", Root: "/work/api", Sandbox: "project"})
+	if got := registry.ResolveContextFor(ctx, nil).Sandbox; got != "project" {
+		t.Fatalf("project sandbox = %q", got)
+	}
+	ctx = WithResolveContextPatch(ctx, ToolResolveContext{Sandbox: "agent"})
+	if got := registry.ResolveContextFor(ctx, nil).Sandbox; got != "agent" {
+		t.Fatalf("agent sandbox = %q", got)
+	}
+}
+
+func TestSandboxEventSinkAnnotatesSandboxedTools(t *testing.T) {
+	t.Setenv(env.AppDir, t.TempDir())
+	if err := config.Save(&config.Config{
+		Agents: config.Agents{Items: []config.AgentConfig{{ID: "coder", Sandbox: "open"}}},
+		Sandbox: config.Sandbox{Default: "strict", Profiles: []config.SandboxProfile{
+			{Name: "strict", Backend: sandbox.BackendDirect},
+			{Name: "open", Backend: sandbox.BackendDirect, Network: true},
+		}},
+	}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	registry := NewToolGroupRegistry()
+	for _, info := range []ToolGroupInfo{
+		{Name: "command", DisplayName: "Command", Description: "Command", Category: "Test", IncludedTools: []string{"execute"}, Sandboxed: true},
+		{Name: "file", DisplayName: "File", Description: "File", Category: "Test", IncludedTools: []string{"file_read"}},
+	} {
+		if err := registry.Register(ToolGroupRegistration{Info: info, Factory: func(ToolResolveContext) ([]runtimeport.Tool, error) { return nil, nil }}); err != nil {
+			t.Fatalf("register %s: %v", info.Name, err)
+		}
+	}
+
+	var got []event.Event
+	sink := SandboxEventSink(WithRegistry(context.Background(), registry), func(e event.Event) error {
+		got = append(got, e)
+		return nil
+	})
+	for _, e := range []event.Event{
+		{Type: event.TypeToolCallStarted, AgentName: "coordinator", ToolName: "execute"},
+		{Type: event.TypeToolCallStarted, AgentName: "coder", ToolName: "execute"},
+		{Type: event.TypeToolCallStarted, AgentName: "coder", ToolName: "file_read"},
+		{Type: event.TypeToolCallCompleted, AgentName: "coder", ToolName: "execute"},
+	} {
+		if err := sink(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got[0].Sandbox == nil || got[0].Sandbox.Profile != "strict" {
+		t.Fatalf("default sandbox = %#v", got[0].Sandbox)
+	}
+	if got[1].Sandbox == nil || got[1].Sandbox.Profile != "open" || !got[1].Sandbox.Network {
+		t.Fatalf("agent sandbox = %#v", got[1].Sandbox)
+	}
+	if got[2].Sandbox != nil || got[3].Sandbox != nil {
+		t.Fatalf("unexpected sandbox on %#v / %#v", got[2], got[3])
+	}
+}
+
+func TestSandboxExecutorRejectsUnknownProfile(t *testing.T) {
+	t.Setenv(env.AppDir, t.TempDir())
+	if err := config.Save(&config.Config{}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	if info := SandboxExecutor("missing").Info(); info.Profile != "missing" || !strings.Contains(info.Fallback, "not found") {
+		t.Fatalf("unknown sandbox = %#v", info)
+	}
+	if info := SandboxExecutor("").Info(); info.Backend != sandbox.BackendDirect || info.Fallback != "" {
+		t.Fatalf("unconfigured sandbox = %#v", info)
+	}
+}
+
+func TestConfinedSandboxExecutorRequiresLinuxSandbox(t *testing.T) {
+	t.Setenv(env.AppDir, t.TempDir())
+	if err := config.Save(&config.Config{
+		Sandbox: config.Sandbox{Profiles: []config.SandboxProfile{
+			{Name: "host", Backend: sandbox.BackendDirect},
+			{Name: "strict", Backend: sandbox.BackendLinux},
+		}},
+	}); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	for _, name := range []string{"", "host", config.SandboxDirect} {
+		if info := ConfinedSandboxExecutor(name, "/work/api").Info(); !strings.Contains(info.Fallback, "non-admin") {
+			t.Fatalf("confined sandbox %q = %#v, want refusal", name, info)
+		}
+	}
+	if info := ConfinedSandboxExecutor("strict", "/work/api").Info(); info.Profile != "strict" || strings.Contains(info.Fallback, "non-admin") {
+		t.Fatalf("confined linux sandbox = %#v", info)
+	}
+}
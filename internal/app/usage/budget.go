@@ -0,0 +1,158 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+
+	domainusage "fkteams/internal/domain/usage"
+)
+
+// Budget 是单个预算范围的软/硬上限，0 表示不限制。
+type Budget struct {
+	SoftTokens int64   `json:"soft_tokens,omitempty"`
+	HardTokens int64   `json:"hard_tokens,omitempty"`
+	SoftCost   float64 `json:"soft_cost,omitempty"`
+	HardCost   float64 `json:"hard_cost,omitempty"`
+}
+
+// Budgets 按会话、定时任务单次执行和自然日划分的预算。
+type Budgets struct {
+	Session Budget
+	Task    Budget
+	Day     Budget
+}
+
+// BudgetScope 表示预算适用的范围。
+type BudgetScope string
+
+const (
+	BudgetSession BudgetScope = "session"
+	BudgetTask    BudgetScope = "task"
+	BudgetDay     BudgetScope = "day"
+)
+
+// Violation 描述一次预算超限。
+type Violation struct {
+	Scope BudgetScope
+	Key   string
+	Hard  bool
+	Used  domainusage.Totals
+	Limit string
+}
+
+// Message 返回面向用户的超限说明。
+func (v Violation) Message() string {
+	kind := "soft"
+	if v.Hard {
+		kind = "hard"
+	}
+	used := fmt.Sprintf("%d tokens, cost %.4f", v.Used.TotalTokens, v.Used.Cost)
+	if v.Key != "" {
+		return fmt.Sprintf("%s budget exceeded for %s: used %s, %s limit %s", v.Scope, v.Key, used, kind, v.Limit)
+	}
+	return fmt.Sprintf("%s budget exceeded: used %s, %s limit %s", v.Scope, used, kind, v.Limit)
+}
+
+// check 返回累计值超出的上限；硬上限优先于软上限。
+func (b Budget) check(used domainusage.Totals) (limit string, hard bool, exceeded bool) {
+	switch {
+	case b.HardTokens > 0 && used.TotalTokens >= b.HardTokens:
+		return fmt.Sprintf("%d tokens", b.HardTokens), true, true
+	case b.HardCost > 0 && used.Cost >= b.HardCost:
+		return fmt.Sprintf("cost %.4f", b.HardCost), true, true
+	case b.SoftTokens > 0 && used.TotalTokens >= b.SoftTokens:
+		return fmt.Sprintf("%d tokens", b.SoftTokens), false, true
+	case b.SoftCost > 0 && used.Cost >= b.SoftCost:
+		return fmt.Sprintf("cost %.4f", b.SoftCost), false, true
+	default:
+		return "", false, false
+	}
+}
+
+// CheckBudget 检查会话、定时任务执行和当日用量是否超出预算，硬上限违规排在最前。
+func (l *Ledger) CheckBudget(ctx context.Context, sessionID string, scope Scope) ([]Violation, error) {
+	if l == nil {
+		return nil, nil
+	}
+	budgets := l.settings().Budgets
+	if budgets == (Budgets{}) {
+		return nil, nil
+	}
+	if _, err := l.requireStore(); err != nil {
+		return nil, err
+	}
+	if err := l.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	l.rollover()
+	type candidate struct {
+		scope  BudgetScope
+		key    string
+		budget Budget
+		used   domainusage.Totals
+	}
+	candidates := []candidate{{scope: BudgetDay, budget: budgets.Day, used: l.today}}
+	if sessionID != "" {
+		candidates = append(candidates, candidate{scope: BudgetSession, key: sessionID, budget: budgets.Session, used: l.sessions[sessionID]})
+	}
+	if scope.TaskRunID != "" {
+		candidates = append(candidates, candidate{scope: BudgetTask, key: scope.TaskID, budget: budgets.Task, used: l.taskRuns[scope.TaskRunID]})
+	}
+	l.mu.Unlock()
+
+	var hard, soft []Violation
+	for _, c := range candidates {
+		limit, isHard, exceeded := c.budget.check(c.used)
+		if !exceeded {
+			continue
+		}
+		v := Violation{Scope: c.scope, Key: c.key, Hard: isHard, Used: c.used, Limit: limit}
+		if isHard {
+			hard = append(hard, v)
+		} else {
+			soft = append(soft, v)
+		}
+	}
+	return append(hard, soft...), nil
+}
+
+// BudgetStatus 是某个预算范围的当前用量与配置。
+type BudgetStatus struct {
+	Scope  BudgetScope        `json:"scope"`
+	Key    string             `json:"key,omitempty"`
+	Used   domainusage.Totals `json:"used"`
+	Budget Budget             `json:"budget"`
+}
+
+// Status 返回当日预算状态，可选附带指定会话的状态。
+func (l *Ledger) Status(ctx context.Context, sessionID string) ([]BudgetStatus, error) {
+	if _, err := l.requireStore(); err != nil {
+		return nil, err
+	}
+	if err := l.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+	budgets := l.settings().Budgets
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rollover()
+	statuses := []BudgetStatus{{Scope: BudgetDay, Key: l.day, Used: l.today, Budget: budgets.Day}}
+	if sessionID != "" {
+		statuses = append(statuses, BudgetStatus{Scope: BudgetSession, Key: sessionID, Used: l.sessions[sessionID], Budget: budgets.Session})
+	}
+	return statuses, nil
+}
+
+// markNotified 记录软上限已提示，返回是否为首次提示。
+func (l *Ledger) markNotified(v Violation) bool {
+	key := string(v.Scope) + ":" + v.Key + ":" + v.Limit
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.notified[key] {
+		return false
+	}
+	l.notified[key] = true
+	return true
+}
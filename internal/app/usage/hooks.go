@@ -0,0 +1,105 @@
+package usage
+
+import (
+	"context"
+
+	"fkteams/internal/domain/session"
+	domainusage "fkteams/internal/domain/usage"
+	"fkteams/internal/runtime/events"
+	"fkteams/internal/runtime/hooks"
+	"fkteams/internal/runtime/log"
+)
+
+const (
+	recordHookName = "usage.record"
+	budgetHookName = "usage.budget"
+	// NoticeBudget 是软上限提示的 system_notice code。
+	NoticeBudget = "usage_budget"
+)
+
+// Install 在 hook 总线上注册用量记录（on_event）和预算检查（before_model_request），返回注销函数。
+func (l *Ledger) Install(bus *hooks.Bus) func() {
+	if l == nil || bus == nil {
+		return func() {}
+	}
+	unregisterRecord := bus.RegisterFunc(recordHookName, []hooks.HookPoint{hooks.HookOnEvent}, l.handleEvent, hooks.Options{})
+	unregisterBudget := bus.RegisterFunc(budgetHookName, []hooks.HookPoint{hooks.HookBeforeModelRequest}, l.handleModelRequest, hooks.Options{})
+	return func() {
+		unregisterRecord()
+		unregisterBudget()
+	}
+}
+
+func (l *Ledger) handleEvent(ctx context.Context, inv hooks.Invocation) (hooks.Result, error) {
+	payload, ok := inv.Payload.(hooks.EventPayload)
+	if !ok {
+		return hooks.Result{}, nil
+	}
+	record, ok := recordFromEvent(payload.Event)
+	if !ok {
+		return hooks.Result{}, nil
+	}
+	record.SessionID = inv.SessionID
+	if record.SessionID == "" {
+		record.SessionID, _ = session.IDFromContext(ctx)
+	}
+	scope := ScopeFromContext(ctx)
+	record.Channel = scope.Channel
+	record.TaskID = scope.TaskID
+	_, err := l.Record(ctx, record)
+	return hooks.Result{}, err
+}
+
+// recordFromEvent 从携带用量的事件构造记录：流式用量附着在 assistant_completed 上，
+// 其余情况单独发出 usage_reported，二者不会重复。
+func recordFromEvent(event events.Event) (domainusage.Record, bool) {
+	switch event.Type {
+	case events.EventUsageReported:
+	case events.EventAssistantCompleted:
+		if event.Usage == nil {
+			return domainusage.Record{}, false
+		}
+	default:
+		return domainusage.Record{}, false
+	}
+	record := domainusage.Record{
+		Agent:            event.AgentName,
+		PromptTokens:     event.PromptTokens,
+		CompletionTokens: event.CompletionTokens,
+		TotalTokens:      event.TotalTokens,
+	}
+	if event.Usage != nil {
+		record.Model = event.Usage.Model
+		record.PromptTokens = event.Usage.PromptTokens
+		record.CompletionTokens = event.Usage.CompletionTokens
+		record.TotalTokens = event.Usage.TotalTokens
+	}
+	if record.PromptTokens == 0 && record.CompletionTokens == 0 && record.TotalTokens == 0 {
+		return domainusage.Record{}, false
+	}
+	return record, true
+}
+
+// handleModelRequest 在模型请求前检查预算：超出硬上限时终止回合，超出软上限时提示一次。
+func (l *Ledger) handleModelRequest(ctx context.Context, inv hooks.Invocation) (hooks.Result, error) {
+	sessionID := inv.SessionID
+	if sessionID == "" {
+		sessionID, _ = session.IDFromContext(ctx)
+	}
+	violations, err := l.CheckBudget(ctx, sessionID, ScopeFromContext(ctx))
+	if err != nil {
+		return hooks.Result{}, err
+	}
+	for _, v := range violations {
+		if v.Hard {
+			return hooks.Result{}, &hooks.AbortError{Reason: v.Message()}
+		}
+		if !l.markNotified(v) {
+			continue
+		}
+		if err := events.DispatchEvent(ctx, events.SystemNotice("", "", NoticeBudget, v.Message())); err != nil {
+			log.Printf("[usage] dispatch budget notice failed: %v", err)
+		}
+	}
+	return hooks.Result{}, nil
+}
@@ -0,0 +1,262 @@
+// Package usage 提供模型用量账本：记录每次模型调用的 token 与费用，按维度汇总并执行预算。
+package usage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"fkteams/internal/app/config"
+	"fkteams/internal/domain/apperror"
+	domainusage "fkteams/internal/domain/usage"
+	storageport "fkteams/internal/ports/storage"
+)
+
+// Price 是模型单价，单位为每百万 token 的费用。
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// Cost 计算一次调用的费用。
+func (p Price) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*p.Input + float64(completionTokens)*p.Output) / 1_000_000
+}
+
+// Settings 是账本在记录和检查预算时读取的配置快照。
+type Settings struct {
+	Prices  map[string]Price
+	Budgets Budgets
+}
+
+// SettingsFromConfig 从当前全局配置读取价格表和预算，配置热更新后立即生效。
+func SettingsFromConfig() Settings {
+	cfg := config.Get()
+	settings := Settings{Prices: make(map[string]Price)}
+	for _, m := range cfg.Models {
+		if m.Price != nil {
+			settings.Prices[m.ID] = Price{Input: m.Price.Input, Output: m.Price.Output}
+		}
+	}
+	budgets := cfg.Usage.Budgets
+	settings.Budgets = Budgets{
+		Session: budgetFromConfig(budgets.Session),
+		Task:    budgetFromConfig(budgets.Task),
+		Day:     budgetFromConfig(budgets.Day),
+	}
+	return settings
+}
+
+func budgetFromConfig(b config.UsageBudget) Budget {
+	return Budget{SoftTokens: b.SoftTokens, HardTokens: b.HardTokens, SoftCost: b.SoftCost, HardCost: b.HardCost}
+}
+
+// Ledger 是用量账本。持久化记录是汇总查询的唯一来源；
+// 预算检查使用内存中的累计值，首次使用时从存储加载。
+type Ledger struct {
+	store    storageport.UsageStore
+	settings func() Settings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	loaded   bool
+	day      string
+	today    domainusage.Totals
+	sessions map[string]domainusage.Totals
+	taskRuns map[string]domainusage.Totals
+	notified map[string]bool
+}
+
+type ledgerContextKey struct{}
+
+// NewLedger 创建用量账本；settings 为 nil 时不计费也不限制预算。
+func NewLedger(store storageport.UsageStore, settings func() Settings) *Ledger {
+	if settings == nil {
+		settings = func() Settings { return Settings{} }
+	}
+	return &Ledger{
+		store:    store,
+		settings: settings,
+		now:      time.Now,
+		sessions: make(map[string]domainusage.Totals),
+		taskRuns: make(map[string]domainusage.Totals),
+		notified: make(map[string]bool),
+	}
+}
+
+// WithLedger 将用量账本注入上下文。
+func WithLedger(ctx context.Context, ledger *Ledger) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if ledger == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, ledgerContextKey{}, ledger)
+}
+
+// FromContext 从上下文读取用量账本。
+func FromContext(ctx context.Context) *Ledger {
+	if ctx == nil {
+		return nil
+	}
+	ledger, _ := ctx.Value(ledgerContextKey{}).(*Ledger)
+	return ledger
+}
+
+// LedgerNotReadyError 表示用量账本尚未完成组合根初始化。
+type LedgerNotReadyError struct{}
+
+func (LedgerNotReadyError) Error() string {
+	return "usage ledger is not initialized"
+}
+
+func (l *Ledger) requireStore() (storageport.UsageStore, error) {
+	if l == nil || l.store == nil {
+		return nil, apperror.Wrap(apperror.CodeUnavailable, "usage ledger is not initialized", LedgerNotReadyError{})
+	}
+	return l.store, nil
+}
+
+// Record 计算费用后持久化一条用量记录，并更新预算累计值。
+func (l *Ledger) Record(ctx context.Context, record domainusage.Record) (domainusage.Record, error) {
+	store, err := l.requireStore()
+	if err != nil {
+		return record, err
+	}
+	if record.At.IsZero() {
+		record.At = l.now()
+	}
+	if record.TotalTokens == 0 {
+		record.TotalTokens = record.PromptTokens + record.CompletionTokens
+	}
+	if price, ok := l.settings().Prices[record.Model]; ok && record.Cost == 0 {
+		record.Cost = price.Cost(record.PromptTokens, record.CompletionTokens)
+	}
+	if err := l.ensureLoaded(ctx); err != nil {
+		return record, err
+	}
+	if err := store.AppendUsage(ctx, record); err != nil {
+		return record, err
+	}
+	l.mu.Lock()
+	l.accumulate(record, ScopeFromContext(ctx).TaskRunID)
+	l.mu.Unlock()
+	return record, nil
+}
+
+// Summary 按维度汇总用量。
+func (l *Ledger) Summary(ctx context.Context, filter domainusage.Filter, by domainusage.Dimension) (domainusage.Summary, error) {
+	if !domainusage.ValidDimension(by) {
+		return domainusage.Summary{}, apperror.New(apperror.CodeInvalidArgument, "unsupported usage dimension: "+string(by))
+	}
+	store, err := l.requireStore()
+	if err != nil {
+		return domainusage.Summary{}, err
+	}
+	records, err := store.ListUsage(ctx, filter.From, filter.To)
+	if err != nil {
+		return domainusage.Summary{}, err
+	}
+	return domainusage.Summarize(records, filter, by), nil
+}
+
+// Records 按时间倒序返回满足条件的明细记录，limit <= 0 表示不限。
+func (l *Ledger) Records(ctx context.Context, filter domainusage.Filter, limit int) ([]domainusage.Record, error) {
+	store, err := l.requireStore()
+	if err != nil {
+		return nil, err
+	}
+	records, err := store.ListUsage(ctx, filter.From, filter.To)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domainusage.Record, 0, len(records))
+	for _, record := range records {
+		if filter.Match(record) {
+			result = append(result, record)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].At.After(result[j].At)
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// ensureLoaded 从存储加载历史记录以恢复会话和当日累计值。
+func (l *Ledger) ensureLoaded(ctx context.Context) error {
+	l.mu.Lock()
+	loaded := l.loaded
+	l.mu.Unlock()
+	if loaded {
+		return nil
+	}
+	records, err := l.store.ListUsage(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.loaded {
+		return nil
+	}
+	for _, record := range records {
+		l.accumulate(record, "")
+	}
+	l.loaded = true
+	return nil
+}
+
+// accumulate 更新内存累计值，调用方需持有 l.mu。
+func (l *Ledger) accumulate(record domainusage.Record, taskRunID string) {
+	l.rollover()
+	if record.SessionID != "" {
+		totals := l.sessions[record.SessionID]
+		totals.Add(record)
+		l.sessions[record.SessionID] = totals
+	}
+	if taskRunID != "" {
+		totals := l.taskRuns[taskRunID]
+		totals.Add(record)
+		l.taskRuns[taskRunID] = totals
+	}
+	if record.Day() == l.day {
+		l.today.Add(record)
+	}
+}
+
+// rollover 在跨天时重置当日累计值、任务执行累计值和提示状态，调用方需持有 l.mu。
+func (l *Ledger) rollover() {
+	day := l.now().Local().Format(domainusage.DayLayout)
+	if day == l.day {
+		return
+	}
+	l.day = day
+	l.today = domainusage.Totals{}
+	l.taskRuns = make(map[string]domainusage.Totals)
+	l.notified = make(map[string]bool)
+}
+
+// ParseDayRange 将 YYYY-MM-DD 格式的起止日期解析为左闭右开的时间范围，结束日期包含当天。
+func ParseDayRange(from, to string) (time.Time, time.Time, error) {
+	var start, end time.Time
+	if from != "" {
+		day, err := time.ParseInLocation(domainusage.DayLayout, from, time.Local)
+		if err != nil {
+			return start, end, apperror.New(apperror.CodeInvalidArgument, "invalid from date, expected YYYY-MM-DD: "+from)
+		}
+		start = day
+	}
+	if to != "" {
+		day, err := time.ParseInLocation(domainusage.DayLayout, to, time.Local)
+		if err != nil {
+			return start, end, apperror.New(apperror.CodeInvalidArgument, "invalid to date, expected YYYY-MM-DD: "+to)
+		}
+		end = day.AddDate(0, 0, 1)
+	}
+	return start, end, nil
+}
@@ -0,0 +1,187 @@
+package usage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	domainsession "fkteams/internal/domain/session"
+	domainusage "fkteams/internal/domain/usage"
+	"fkteams/internal/runtime/events"
+	"fkteams/internal/runtime/hooks"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	records []domainusage.Record
+}
+
+func (s *memoryStore) AppendUsage(_ context.Context, record domainusage.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records = append(s.records, record)
+	return nil
+}
+
+func (s *memoryStore) ListUsage(_ context.Context, from, to time.Time) ([]domainusage.Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	filter := domainusage.Filter{From: from, To: to}
+	var result []domainusage.Record
+	for _, record := range s.records {
+		if filter.Match(record) {
+			result = append(result, record)
+		}
+	}
+	return result, nil
+}
+
+func fixedSettings(settings Settings) func() Settings {
+	return func() Settings { return settings }
+}
+
+func TestLedgerRecordsCostAndSummarizes(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{}
+	ledger := NewLedger(store, fixedSettings(Settings{Prices: map[string]Price{"gpt": {Input: 2, Output: 8}}}))
+
+	for _, record := range []domainusage.Record{
+		{SessionID: "s1", Agent: "leader", Model: "gpt", PromptTokens: 1_000_000, CompletionTokens: 500_000},
+		{SessionID: "s1", Agent: "coder", Model: "local", PromptTokens: 10, CompletionTokens: 5},
+		{SessionID: "s2", Agent: "leader", Model: "gpt", PromptTokens: 100, CompletionTokens: 0},
+	} {
+		if _, err := ledger.Record(ctx, record); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	if got := store.records[0]; got.Cost != 6 || got.TotalTokens != 1_500_000 {
+		t.Fatalf("first record = %#v", got)
+	}
+
+	summary, err := ledger.Summary(ctx, domainusage.Filter{}, domainusage.ByModel)
+	if err != nil {
+		t.Fatalf("Summary: %v", err)
+	}
+	if summary.Totals.Requests != 3 || len(summary.Groups) != 2 || summary.Groups[0].Key != "gpt" || summary.Groups[0].Requests != 2 {
+		t.Fatalf("summary = %#v", summary)
+	}
+
+	filtered, err := ledger.Summary(ctx, domainusage.Filter{SessionID: "s1"}, domainusage.ByAgent)
+	if err != nil {
+		t.Fatalf("Summary filtered: %v", err)
+	}
+	if filtered.Totals.Requests != 2 || len(filtered.Groups) != 2 {
+		t.Fatalf("filtered summary = %#v", filtered)
+	}
+	if _, err := ledger.Summary(ctx, domainusage.Filter{}, "color"); err == nil {
+		t.Fatal("expected unsupported dimension error")
+	}
+}
+
+func TestLedgerHookRecordsUsageEventsWithScope(t *testing.T) {
+	store := &memoryStore{}
+	ledger := NewLedger(store, nil)
+	bus := hooks.NewBus()
+	ledger.Install(bus)
+
+	ctx := hooks.WithBus(context.Background(), bus)
+	ctx = domainsession.WithID(ctx, "session-1")
+	ctx = WithScope(ctx, Scope{Channel: ChannelScheduler, TaskID: "task-1"})
+
+	usageEvent := events.Usage("leader", "leader", 3, 2, 5)
+	usageEvent.Usage.Model = "gpt"
+	completed := events.Event{Type: events.EventAssistantCompleted, AgentName: "coder"}
+	for _, event := range []events.Event{usageEvent, completed, {Type: events.EventAssistantText, Content: "hi"}} {
+		if err := events.DispatchEvent(ctx, event); err != nil {
+			t.Fatalf("DispatchEvent: %v", err)
+		}
+	}
+
+	if len(store.records) != 1 {
+		t.Fatalf("records = %#v", store.records)
+	}
+	got := store.records[0]
+	if got.SessionID != "session-1" || got.Channel != ChannelScheduler || got.TaskID != "task-1" || got.Model != "gpt" || got.TotalTokens != 5 {
+		t.Fatalf("record = %#v", got)
+	}
+}
+
+func TestLedgerBudgetHookAbortsOnHardLimitAndNotifiesOnSoftLimit(t *testing.T) {
+	store := &memoryStore{}
+	settings := Settings{Budgets: Budgets{
+		Session: Budget{SoftTokens: 10, HardTokens: 100},
+		Task:    Budget{HardTokens: 50},
+	}}
+	ledger := NewLedger(store, fixedSettings(settings))
+	bus := hooks.NewBus()
+	ledger.Install(bus)
+
+	var notices []events.Event
+	ctx := hooks.WithBus(context.Background(), bus)
+	ctx = domainsession.WithID(ctx, "session-1")
+	ctx = events.WithCallback(ctx, func(event events.Event) error {
+		if event.Type == events.EventSystemNotice {
+			notices = append(notices, event)
+		}
+		return nil
+	})
+
+	if _, err := ledger.Record(ctx, domainusage.Record{SessionID: "session-1", TotalTokens: 20}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	for range 2 {
+		if _, err := bus.InvokeBeforeModelRequest(ctx, nil); err != nil {
+			t.Fatalf("soft limit should not reject: %v", err)
+		}
+	}
+	if len(notices) != 1 || notices[0].Notice.Code != NoticeBudget {
+		t.Fatalf("notices = %#v", notices)
+	}
+
+	if _, err := ledger.Record(ctx, domainusage.Record{SessionID: "session-1", TotalTokens: 80}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	_, err := bus.InvokeBeforeModelRequest(ctx, nil)
+	var abort *hooks.AbortError
+	if !errors.As(err, &abort) || abort.Reason == "" {
+		t.Fatalf("hard limit error = %v", err)
+	}
+
+	// 任务预算按单次执行计算：新的执行不受上一次执行用量影响。
+	taskCtx := WithScope(domainsession.WithID(context.Background(), "task-session"), Scope{TaskID: "t1", TaskRunID: "run-1"})
+	if _, err := ledger.Record(taskCtx, domainusage.Record{SessionID: "task-session", TotalTokens: 60}); err != nil {
+		t.Fatalf("Record task: %v", err)
+	}
+	if violations, _ := ledger.CheckBudget(taskCtx, "task-session", ScopeFromContext(taskCtx)); len(violations) == 0 || violations[0].Scope != BudgetTask || !violations[0].Hard {
+		t.Fatalf("task violations = %#v", violations)
+	}
+	if violations, _ := ledger.CheckBudget(taskCtx, "", Scope{TaskID: "t1", TaskRunID: "run-2"}); len(violations) != 0 {
+		t.Fatalf("new task run violations = %#v", violations)
+	}
+}
+
+func TestLedgerRestoresTotalsFromStore(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryStore{records: []domainusage.Record{
+		{At: time.Now(), SessionID: "s1", TotalTokens: 40},
+		{At: time.Now().Add(-48 * time.Hour), SessionID: "s1", TotalTokens: 70},
+	}}
+	ledger := NewLedger(store, fixedSettings(Settings{Budgets: Budgets{
+		Session: Budget{HardTokens: 100},
+		Day:     Budget{HardTokens: 50},
+	}}))
+
+	violations, err := ledger.CheckBudget(ctx, "s1", Scope{})
+	if err != nil {
+		t.Fatalf("CheckBudget: %v", err)
+	}
+	if len(violations) != 1 || violations[0].Scope != BudgetSession || !violations[0].Hard {
+		t.Fatalf("violations = %#v", violations)
+	}
+	statuses, err := ledger.Status(ctx, "s1")
+	if err != nil || len(statuses) != 2 || statuses[0].Used.TotalTokens != 40 || statuses[1].Used.TotalTokens != 110 {
+		t.Fatalf("statuses = %#v, %v", statuses, err)
+	}
+}
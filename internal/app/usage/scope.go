@@ -0,0 +1,38 @@
+package usage
+
+import "context"
+
+// 常用的用量来源通道；IM 通道直接使用通道名（如 qq、discord）。
+const (
+	ChannelWeb       = "web"
+	ChannelAPI       = "api"
+	ChannelCLI       = "cli"
+	ChannelScheduler = "scheduler"
+)
+
+// Scope 描述一次执行的用量归属，由各入口在调用 RunTurn 前注入。
+type Scope struct {
+	Channel string
+	TaskID  string
+	// TaskRunID 标识定时任务的单次执行，任务预算按单次执行计算。
+	TaskRunID string
+}
+
+type scopeContextKey struct{}
+
+// WithScope 将用量归属注入上下文。
+func WithScope(ctx context.Context, scope Scope) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, scopeContextKey{}, scope)
+}
+
+// ScopeFromContext 读取用量归属，未设置时返回零值。
+func ScopeFromContext(ctx context.Context) Scope {
+	if ctx == nil {
+		return Scope{}
+	}
+	scope, _ := ctx.Value(scopeContextKey{}).(Scope)
+	return scope
+}
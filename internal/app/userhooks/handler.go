@@ -0,0 +1,100 @@
+package userhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+
+	"fkteams/internal/app/config"
+	"fkteams/internal/domain/session"
+	"fkteams/internal/runtime/hooks"
+)
+
+// handler 将一条 [[hooks]] 配置适配为 HookBus 处理器。
+type handler struct {
+	name    string
+	points  []hooks.HookPoint
+	matcher *regexp.Regexp
+	runner  Runner
+}
+
+func newHandler(cfg config.HookConfig, newRunner RunnerFactory) (*handler, error) {
+	h := &handler{name: cfg.Name}
+	for _, point := range cfg.Points {
+		h.points = append(h.points, hooks.HookPoint(point))
+	}
+	if cfg.Matcher != "" {
+		matcher, err := regexp.Compile(cfg.Matcher)
+		if err != nil {
+			return nil, err
+		}
+		h.matcher = matcher
+	}
+	runner, err := newRunner(cfg)
+	if err != nil {
+		return nil, err
+	}
+	h.runner = runner
+	return h, nil
+}
+
+func (h *handler) Name() string {
+	return handlerPrefix + h.name
+}
+
+func (h *handler) Points() []hooks.HookPoint {
+	return append([]hooks.HookPoint(nil), h.points...)
+}
+
+func (h *handler) Handle(ctx context.Context, inv hooks.Invocation) (hooks.Result, error) {
+	if h.matcher != nil {
+		toolName, ok := toolNameOf(inv.Payload)
+		if !ok || !h.matcher.MatchString(toolName) {
+			return hooks.Result{}, nil
+		}
+	}
+	sessionID := inv.SessionID
+	if sessionID == "" {
+		sessionID, _ = session.IDFromContext(ctx)
+	}
+	body, err := json.Marshal(request{
+		Hook:      h.name,
+		HookPoint: inv.HookPoint,
+		SessionID: sessionID,
+		RunID:     inv.RunID,
+		TurnID:    inv.TurnID,
+		Payload:   encodePayload(inv.Payload),
+	})
+	if err != nil {
+		return hooks.Result{}, err
+	}
+	env := []string{
+		"FKTEAMS_HOOK_NAME=" + h.name,
+		"FKTEAMS_HOOK_POINT=" + string(inv.HookPoint),
+		"FKTEAMS_SESSION_ID=" + sessionID,
+	}
+	output, err := h.runner.Run(ctx, env, body)
+	var rejected *RejectError
+	if errors.As(err, &rejected) {
+		return hooks.Result{Action: hooks.ActionReject, Message: rejected.Message}, nil
+	}
+	if err != nil {
+		return hooks.Result{}, err
+	}
+	return parseResponse(inv.Payload, output)
+}
+
+// toolNameOf 返回工具相关载荷中的工具名，用于 matcher 过滤。
+func toolNameOf(payload hooks.Payload) (string, bool) {
+	switch p := payload.(type) {
+	case hooks.BeforeToolCallPayload:
+		return p.ToolName, true
+	case hooks.AfterToolCallPayload:
+		return p.ToolName, true
+	case hooks.EventPayload:
+		return p.Event.ToolName, p.Event.ToolName != ""
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,134 @@
+// Package userhooks 将 [[hooks]] 配置中的本地命令和 HTTP 端点注册到运行时 HookBus。
+package userhooks
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
+	"fkteams/internal/app/config"
+	"fkteams/internal/runtime/hooks"
+)
+
+const (
+	handlerPrefix = "user."
+	// defaultTimeout 是未配置 timeout 时的默认超时，外部进程和网络调用比进程内 hook 慢。
+	defaultTimeout = 10 * time.Second
+)
+
+// Manager 维护用户 hook 在 HookBus 上的注册，配置变化后通过 Reload 整体替换。
+type Manager struct {
+	bus       *hooks.Bus
+	settings  func() []config.HookConfig
+	newRunner RunnerFactory
+
+	mu         sync.Mutex
+	loaded     bool
+	applied    []config.HookConfig
+	unregister []func()
+}
+
+type managerContextKey struct{}
+
+// NewManager 创建用户 hook 管理器；settings 为 nil 时读取全局配置。
+func NewManager(bus *hooks.Bus, settings func() []config.HookConfig, newRunner RunnerFactory) *Manager {
+	if settings == nil {
+		settings = SettingsFromConfig
+	}
+	return &Manager{bus: bus, settings: settings, newRunner: newRunner}
+}
+
+// SettingsFromConfig 读取当前全局配置中的用户 hook。
+func SettingsFromConfig() []config.HookConfig {
+	return config.Get().Hooks
+}
+
+// WithManager 将用户 hook 管理器注入上下文。
+func WithManager(ctx context.Context, manager *Manager) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if manager == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, managerContextKey{}, manager)
+}
+
+// FromContext 从上下文读取用户 hook 管理器。
+func FromContext(ctx context.Context) *Manager {
+	if ctx == nil {
+		return nil
+	}
+	manager, _ := ctx.Value(managerContextKey{}).(*Manager)
+	return manager
+}
+
+// Reload 按当前配置重新注册用户 hook；配置未变化时不做任何操作。
+// 配置无效时保留已注册的 hook 并返回错误。
+func (m *Manager) Reload() error {
+	if m == nil || m.bus == nil || m.newRunner == nil {
+		return nil
+	}
+	entries := m.settings()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.loaded && reflect.DeepEqual(entries, m.applied) {
+		return nil
+	}
+	if err := (&config.Config{Hooks: entries}).ValidateHooks(); err != nil {
+		return err
+	}
+	type registration struct {
+		handler *handler
+		options hooks.Options
+	}
+	registrations := make([]registration, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Disabled {
+			continue
+		}
+		h, err := newHandler(entry, m.newRunner)
+		if err != nil {
+			return err
+		}
+		timeout := entry.TimeoutDuration()
+		if timeout == 0 {
+			timeout = defaultTimeout
+		}
+		registrations = append(registrations, registration{handler: h, options: hooks.Options{
+			Timeout:     timeout,
+			ErrorPolicy: hooks.ErrorPolicy(entry.ErrorPolicy),
+			Priority:    entry.Priority,
+		}})
+	}
+
+	m.unregisterLocked()
+	for _, r := range registrations {
+		m.unregister = append(m.unregister, m.bus.Register(r.handler, r.options))
+	}
+	// 全局配置快照不可变，可直接保存用于比较。
+	m.applied = entries
+	m.loaded = true
+	return nil
+}
+
+// Close 注销全部用户 hook。
+func (m *Manager) Close() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.unregisterLocked()
+	m.applied = nil
+	m.loaded = false
+}
+
+func (m *Manager) unregisterLocked() {
+	for _, unregister := range m.unregister {
+		unregister()
+	}
+	m.unregister = nil
+}
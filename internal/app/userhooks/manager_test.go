@@ -0,0 +1,127 @@
+package userhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"fkteams/internal/app/config"
+	"fkteams/internal/domain/message"
+	"fkteams/internal/domain/session"
+	"fkteams/internal/runtime/hooks"
+)
+
+type runnerFunc func(ctx context.Context, env []string, body []byte) ([]byte, error)
+
+func (f runnerFunc) Run(ctx context.Context, env []string, body []byte) ([]byte, error) {
+	return f(ctx, env, body)
+}
+
+func runnerFactory(runners map[string]runnerFunc) RunnerFactory {
+	return func(cfg config.HookConfig) (Runner, error) {
+		return runners[cfg.Name], nil
+	}
+}
+
+func staticSettings(entries *[]config.HookConfig) func() []config.HookConfig {
+	return func() []config.HookConfig { return *entries }
+}
+
+func TestHandlerEncodesRequestAndAppliesRewrittenPayload(t *testing.T) {
+	var got request
+	var gotEnv []string
+	runners := map[string]runnerFunc{
+		"redact": func(_ context.Context, env []string, body []byte) ([]byte, error) {
+			gotEnv = env
+			if err := json.Unmarshal(body, &got); err != nil {
+				return nil, err
+			}
+			return []byte(`{"payload":{"messages":[{"role":"user","content":"[redacted]"}]}}`), nil
+		},
+	}
+	entries := []config.HookConfig{{Name: "redact", Points: []string{"before_model_request"}, Command: "redact"}}
+	bus := hooks.NewBus()
+	if err := NewManager(bus, staticSettings(&entries), runnerFactory(runners)).Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+
+	ctx := session.WithID(context.Background(), "session-1")
+	messages, err := bus.InvokeBeforeModelRequest(ctx, []message.Message{{Role: message.RoleUser, Content: "password=123"}})
+	if err != nil {
+		t.Fatalf("InvokeBeforeModelRequest: %v", err)
+	}
+	if len(messages) != 1 || messages[0].Content != "[redacted]" {
+		t.Fatalf("messages = %#v", messages)
+	}
+	if got.Hook != "redact" || got.HookPoint != hooks.HookBeforeModelRequest || got.SessionID != "session-1" {
+		t.Fatalf("request = %#v", got)
+	}
+	if len(gotEnv) != 3 || gotEnv[1] != "FKTEAMS_HOOK_POINT=before_model_request" {
+		t.Fatalf("env = %#v", gotEnv)
+	}
+}
+
+func TestHandlerMatcherAndRejectError(t *testing.T) {
+	calls := 0
+	runners := map[string]runnerFunc{
+		"guard": func(context.Context, []string, []byte) ([]byte, error) {
+			calls++
+			return nil, &RejectError{Message: "not allowed"}
+		},
+	}
+	entries := []config.HookConfig{{Name: "guard", Points: []string{"before_tool_call"}, Matcher: "^execute$", Command: "guard"}}
+	bus := hooks.NewBus()
+	if err := NewManager(bus, staticSettings(&entries), runnerFactory(runners)).Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+
+	if _, err := bus.InvokeBeforeToolCall(context.Background(), hooks.BeforeToolCallPayload{ToolName: "read_file"}); err != nil || calls != 0 {
+		t.Fatalf("unmatched tool err = %v, calls = %d", err, calls)
+	}
+	_, err := bus.InvokeBeforeToolCall(context.Background(), hooks.BeforeToolCallPayload{ToolName: "execute"})
+	if err == nil || calls != 1 || err.Error() != "tool call rejected by hook: not allowed" {
+		t.Fatalf("matched tool err = %v, calls = %d", err, calls)
+	}
+}
+
+func TestManagerReloadReplacesHooksAndAppliesErrorPolicy(t *testing.T) {
+	failing := runnerFunc(func(context.Context, []string, []byte) ([]byte, error) {
+		return nil, errors.New("boom")
+	})
+	runners := map[string]runnerFunc{"broken": failing, "invalid": failing}
+	bus := hooks.NewBus()
+	entries := []config.HookConfig{{Name: "broken", Points: []string{"before_model_request"}, Command: "broken"}}
+	manager := NewManager(bus, staticSettings(&entries), runnerFactory(runners))
+	if err := manager.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if _, err := bus.InvokeBeforeModelRequest(context.Background(), nil); err == nil {
+		t.Fatal("before_model_request should fail by default")
+	}
+
+	entries = []config.HookConfig{{Name: "broken", Points: []string{"before_model_request"}, Command: "broken", ErrorPolicy: "ignore"}}
+	if err := manager.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if _, err := bus.InvokeBeforeModelRequest(context.Background(), nil); err != nil {
+		t.Fatalf("ignored hook error should not fail: %v", err)
+	}
+
+	entries = []config.HookConfig{{Name: "invalid", Points: []string{"before_model_request"}}}
+	if err := manager.Reload(); err == nil {
+		t.Fatal("invalid config should be rejected")
+	}
+	if _, err := bus.InvokeBeforeModelRequest(context.Background(), nil); err != nil {
+		t.Fatalf("previous hooks should stay registered: %v", err)
+	}
+
+	entries = []config.HookConfig{{Name: "broken", Points: []string{"before_model_request"}, Command: "broken", Disabled: true}}
+	if err := manager.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if _, err := bus.InvokeBeforeModelRequest(context.Background(), nil); err != nil {
+		t.Fatalf("disabled hook should not run: %v", err)
+	}
+	manager.Close()
+}
@@ -0,0 +1,25 @@
+package userhooks
+
+import (
+	"context"
+
+	"fkteams/internal/app/config"
+)
+
+// Runner 执行一次外部 hook 调用：body 为请求 JSON，返回 hook 输出的 JSON。
+// env 是本次调用的附加环境变量，仅对本地命令有意义。
+type Runner interface {
+	Run(ctx context.Context, env []string, body []byte) ([]byte, error)
+}
+
+// RunnerFactory 按配置创建外部 hook 执行器，由组合根注入具体实现。
+type RunnerFactory func(cfg config.HookConfig) (Runner, error)
+
+// RejectError 表示外部 hook 以约定方式拒绝当前调用，例如命令以退出码 2 结束。
+type RejectError struct {
+	Message string
+}
+
+func (e *RejectError) Error() string {
+	return "rejected by hook: " + e.Message
+}
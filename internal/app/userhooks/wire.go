@@ -0,0 +1,166 @@
+package userhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"fkteams/internal/domain/event"
+	"fkteams/internal/domain/message"
+	"fkteams/internal/runtime/hooks"
+)
+
+// request 是发送给命令 stdin 或 HTTP 请求体的 JSON 结构。
+type request struct {
+	Hook      string          `json:"hook"`
+	HookPoint hooks.HookPoint `json:"hook_point"`
+	SessionID string          `json:"session_id,omitempty"`
+	RunID     string          `json:"run_id,omitempty"`
+	TurnID    string          `json:"turn_id,omitempty"`
+	Payload   any             `json:"payload"`
+}
+
+// response 是命令 stdout 或 HTTP 响应体的 JSON 结构，空输出等同于 continue。
+type response struct {
+	Action  hooks.Action    `json:"action,omitempty"`
+	Message string          `json:"message,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type beforeRunWire struct {
+	Context []message.Message `json:"context,omitempty"`
+	Message message.Message   `json:"message"`
+}
+
+type afterRunWire struct {
+	Context   []message.Message `json:"context,omitempty"`
+	Message   message.Message   `json:"message"`
+	LastEvent *event.Event      `json:"last_event,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+type eventWire struct {
+	Event event.Event `json:"event"`
+}
+
+type beforeToolCallWire struct {
+	ToolName string         `json:"tool_name"`
+	Args     string         `json:"args"`
+	Meta     map[string]any `json:"meta,omitempty"`
+}
+
+type afterToolCallWire struct {
+	ToolName string         `json:"tool_name"`
+	Args     string         `json:"args"`
+	Result   string         `json:"result"`
+	Error    string         `json:"error,omitempty"`
+	Meta     map[string]any `json:"meta,omitempty"`
+}
+
+type beforeModelRequestWire struct {
+	Messages []message.Message `json:"messages"`
+	Meta     map[string]any    `json:"meta,omitempty"`
+}
+
+type afterModelResponseWire struct {
+	Message message.Message `json:"message"`
+	Usage   *event.Event    `json:"usage,omitempty"`
+	Error   string          `json:"error,omitempty"`
+	Meta    map[string]any  `json:"meta,omitempty"`
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// encodePayload 将 hook 载荷转换为稳定的 JSON 结构，错误统一转为字符串。
+func encodePayload(payload hooks.Payload) any {
+	switch p := payload.(type) {
+	case hooks.BeforeRunPayload:
+		return beforeRunWire{Context: p.Input.Context, Message: p.Input.Message}
+	case hooks.AfterRunPayload:
+		wire := afterRunWire{Context: p.Input.Context, Message: p.Input.Message, Error: errorText(p.Error)}
+		if p.Result != nil {
+			last := p.Result.LastEvent
+			wire.LastEvent = &last
+		}
+		return wire
+	case hooks.EventPayload:
+		return eventWire{Event: p.Event}
+	case hooks.BeforeToolCallPayload:
+		return beforeToolCallWire{ToolName: p.ToolName, Args: p.Args, Meta: p.Meta}
+	case hooks.AfterToolCallPayload:
+		return afterToolCallWire{ToolName: p.ToolName, Args: p.Args, Result: p.Result, Error: errorText(p.Error), Meta: p.Meta}
+	case hooks.BeforeModelRequestPayload:
+		return beforeModelRequestWire{Messages: p.Messages, Meta: p.Meta}
+	case hooks.AfterModelResponsePayload:
+		return afterModelResponseWire{Message: p.Message, Usage: p.Usage, Error: errorText(p.Error), Meta: p.Meta}
+	default:
+		return payload
+	}
+}
+
+// decodePayload 将 hook 返回的改写载荷合并回原载荷。
+// 只有运行时会采用改写结果的扩展点支持改写，其余扩展点返回 nil。
+func decodePayload(original hooks.Payload, raw json.RawMessage) (hooks.Payload, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	switch p := original.(type) {
+	case hooks.BeforeRunPayload:
+		wire := beforeRunWire{Context: p.Input.Context, Message: p.Input.Message}
+		if err := json.Unmarshal(raw, &wire); err != nil {
+			return nil, fmt.Errorf("decode before_run payload: %w", err)
+		}
+		p.Input = message.TurnInput{Context: wire.Context, Message: wire.Message}
+		return p, nil
+	case hooks.EventPayload:
+		wire := eventWire{Event: p.Event}
+		if err := json.Unmarshal(raw, &wire); err != nil {
+			return nil, fmt.Errorf("decode on_event payload: %w", err)
+		}
+		p.Event = wire.Event
+		return p, nil
+	case hooks.BeforeToolCallPayload:
+		wire := beforeToolCallWire{ToolName: p.ToolName, Args: p.Args, Meta: p.Meta}
+		if err := json.Unmarshal(raw, &wire); err != nil {
+			return nil, fmt.Errorf("decode before_tool_call payload: %w", err)
+		}
+		// 工具名由运行时决定，hook 只能改写参数。
+		p.Args = wire.Args
+		return p, nil
+	case hooks.BeforeModelRequestPayload:
+		wire := beforeModelRequestWire{Messages: p.Messages, Meta: p.Meta}
+		if err := json.Unmarshal(raw, &wire); err != nil {
+			return nil, fmt.Errorf("decode before_model_request payload: %w", err)
+		}
+		p.Messages = wire.Messages
+		return p, nil
+	default:
+		return nil, nil
+	}
+}
+
+// parseResponse 解析 hook 输出并转换为 HookBus 结果。
+func parseResponse(original hooks.Payload, output []byte) (hooks.Result, error) {
+	if len(bytes.TrimSpace(output)) == 0 {
+		return hooks.Result{Action: hooks.ActionContinue}, nil
+	}
+	var resp response
+	if err := json.Unmarshal(output, &resp); err != nil {
+		return hooks.Result{}, fmt.Errorf("decode hook response: %w", err)
+	}
+	switch resp.Action {
+	case "", hooks.ActionContinue, hooks.ActionSkip, hooks.ActionReject:
+	default:
+		return hooks.Result{}, fmt.Errorf("unsupported hook action %q", resp.Action)
+	}
+	payload, err := decodePayload(original, resp.Payload)
+	if err != nil {
+		return hooks.Result{}, err
+	}
+	return hooks.Result{Payload: payload, Action: resp.Action, Message: resp.Message}, nil
+}
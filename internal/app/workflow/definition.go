@@ -0,0 +1,394 @@
+// Package workflow 加载声明式工作流定义，并按有向无环图编排多个智能体执行。
+package workflow
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"fkteams/internal/app/appdata"
+	domainsession "fkteams/internal/domain/session"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// NodeType 是工作流节点类型。
+type NodeType string
+
+const (
+	// NodeAgent 调用一个智能体，输出为智能体的最终回答。
+	NodeAgent NodeType = "agent"
+	// NodeApproval 是人工审批闸门，拒绝后依赖它的节点不会执行。
+	NodeApproval NodeType = "approval"
+)
+
+// Join 决定节点在多个上游节点之间如何汇合。
+type Join string
+
+const (
+	// JoinAll 要求所有上游节点都已完成。
+	JoinAll Join = "all"
+	// JoinAny 只要求任一上游节点已完成，用于汇合互斥的条件分支。
+	JoinAny Join = "any"
+)
+
+// InputRef 是模板中引用用户输入的占位符名称。
+const InputRef = "input"
+
+// Definition 是一个工作流定义文件的内容。
+type Definition struct {
+	Name        string `toml:"name" yaml:"name"`
+	Description string `toml:"description" yaml:"description"`
+	// Output 指定最终回答取自哪个节点，为空时取最后完成的末端节点。
+	Output string `toml:"output" yaml:"output"`
+	Nodes  []Node `toml:"nodes" yaml:"nodes"`
+
+	// Path 是定义文件路径。
+	Path string `toml:"-" yaml:"-"`
+}
+
+// Node 是工作流中的一个步骤。
+type Node struct {
+	ID    string   `toml:"id" yaml:"id"`
+	Type  NodeType `toml:"type" yaml:"type"`
+	Agent string   `toml:"agent" yaml:"agent"`
+	// Prompt 是发给智能体的消息模板，支持 {{input}} 和 {{<节点 ID>}} 占位符；
+	// 为空时发送用户输入并附上所有上游节点的输出。
+	Prompt string `toml:"prompt" yaml:"prompt"`
+	// Message 是审批闸门展示给用户的说明，同样支持占位符。
+	Message string     `toml:"message" yaml:"message"`
+	Depends []string   `toml:"depends" yaml:"depends"`
+	Join    Join       `toml:"join" yaml:"join"`
+	When    *Condition `toml:"when" yaml:"when"`
+}
+
+// Condition 按某个上游节点的输出决定节点是否执行，多个条件同时满足才执行。
+type Condition struct {
+	Node        string   `toml:"node" yaml:"node"`
+	Contains    []string `toml:"contains" yaml:"contains"`
+	NotContains []string `toml:"not_contains" yaml:"not_contains"`
+	Matches     string   `toml:"matches" yaml:"matches"`
+
+	pattern *regexp.Regexp
+}
+
+var (
+	nodeIDPattern   = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+	placeholderExpr = regexp.MustCompile(`\{\{\s*([A-Za-z][A-Za-z0-9_-]*)\s*\}\}`)
+	definitionExts  = []string{".toml", ".yaml", ".yml"}
+)
+
+// Parse 按文件扩展名解析 TOML 或 YAML 格式的工作流定义并校验。
+func Parse(data []byte, path string) (*Definition, error) {
+	var def Definition
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		decoder := toml.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&def); err != nil {
+			return nil, fmt.Errorf("parse workflow %s: %w", path, err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.UnmarshalWithOptions(data, &def, yaml.DisallowUnknownField()); err != nil {
+			return nil, fmt.Errorf("parse workflow %s: %s", path, yaml.FormatError(err, false, true))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported workflow file %s (.toml|.yaml|.yml)", path)
+	}
+	def.Path = path
+	if def.Name == "" {
+		def.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := def.Validate(); err != nil {
+		return nil, fmt.Errorf("workflow %s: %w", path, err)
+	}
+	return &def, nil
+}
+
+// LoadFile 读取并校验单个工作流定义文件。
+func LoadFile(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, path)
+}
+
+// Load 按名称从工作流目录加载定义，名称即文件名（不含扩展名）。
+func Load(name string) (*Definition, error) {
+	if !domainsession.ValidID(name) {
+		return nil, fmt.Errorf("invalid workflow name %q", name)
+	}
+	dir := appdata.WorkflowsDir()
+	for _, ext := range definitionExts {
+		path := filepath.Join(dir, name+ext)
+		def, err := LoadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return def, err
+	}
+	return nil, fmt.Errorf("workflow %q not found in %s", name, dir)
+}
+
+// Entry 是工作流目录中的一个定义文件，解析失败时 Err 非空。
+type Entry struct {
+	Name       string
+	Path       string
+	Definition *Definition
+	Err        error
+}
+
+// List 返回工作流目录中的全部定义，按名称排序；目录不存在时返回空列表。
+func List() ([]Entry, error) {
+	dir := appdata.WorkflowsDir()
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, file := range files {
+		ext := strings.ToLower(filepath.Ext(file.Name()))
+		if file.IsDir() || !isDefinitionExt(ext) {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		def, err := LoadFile(path)
+		entries = append(entries, Entry{
+			Name:       strings.TrimSuffix(file.Name(), filepath.Ext(file.Name())),
+			Path:       path,
+			Definition: def,
+			Err:        err,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+func isDefinitionExt(ext string) bool {
+	for _, candidate := range definitionExts {
+		if ext == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate 补齐默认值并检查节点 ID、依赖关系、占位符和条件。
+func (d *Definition) Validate() error {
+	if len(d.Nodes) == 0 {
+		return fmt.Errorf("no nodes defined")
+	}
+	index := make(map[string]int, len(d.Nodes))
+	for i := range d.Nodes {
+		node := &d.Nodes[i]
+		if !nodeIDPattern.MatchString(node.ID) {
+			return fmt.Errorf("node #%d: invalid id %q (letters, digits, '_' and '-', starting with a letter)", i+1, node.ID)
+		}
+		if node.ID == InputRef {
+			return fmt.Errorf("node id %q is reserved", InputRef)
+		}
+		if _, ok := index[node.ID]; ok {
+			return fmt.Errorf("duplicate node %q", node.ID)
+		}
+		index[node.ID] = i
+		if node.Type == "" {
+			node.Type = NodeAgent
+		}
+		if node.Join == "" {
+			node.Join = JoinAll
+		}
+		switch node.Type {
+		case NodeAgent:
+			if strings.TrimSpace(node.Agent) == "" {
+				return fmt.Errorf("node %s: agent is required", node.ID)
+			}
+		case NodeApproval:
+			if node.Agent != "" {
+				return fmt.Errorf("node %s: approval nodes cannot bind an agent", node.ID)
+			}
+		default:
+			return fmt.Errorf("node %s: unknown type %q (agent|approval)", node.ID, node.Type)
+		}
+		if node.Join != JoinAll && node.Join != JoinAny {
+			return fmt.Errorf("node %s: unknown join %q (all|any)", node.ID, node.Join)
+		}
+	}
+	for _, node := range d.Nodes {
+		for _, dep := range node.Depends {
+			if _, ok := index[dep]; !ok {
+				return fmt.Errorf("node %s: depends on unknown node %q", node.ID, dep)
+			}
+			if dep == node.ID {
+				return fmt.Errorf("node %s: depends on itself", node.ID)
+			}
+		}
+	}
+	if cycle := d.findCycle(index); cycle != "" {
+		return fmt.Errorf("dependency cycle: %s", cycle)
+	}
+
+	for i := range d.Nodes {
+		node := &d.Nodes[i]
+		ancestors := d.ancestors(node.ID, index)
+		for _, template := range []string{node.Prompt, node.Message} {
+			for _, ref := range placeholders(template) {
+				if ref != InputRef && !ancestors[ref] {
+					return fmt.Errorf("node %s: placeholder {{%s}} must reference %s or an upstream node", node.ID, ref, InputRef)
+				}
+			}
+		}
+		if node.When != nil {
+			if !ancestors[node.When.Node] {
+				return fmt.Errorf("node %s: when.node %q must be an upstream node", node.ID, node.When.Node)
+			}
+			if node.When.Matches != "" {
+				pattern, err := regexp.Compile(node.When.Matches)
+				if err != nil {
+					return fmt.Errorf("node %s: when.matches: %w", node.ID, err)
+				}
+				node.When.pattern = pattern
+			}
+		}
+	}
+	if d.Output != "" {
+		if _, ok := index[d.Output]; !ok {
+			return fmt.Errorf("output references unknown node %q", d.Output)
+		}
+	}
+	return nil
+}
+
+// Node 按 ID 返回节点。
+func (d *Definition) Node(id string) (Node, bool) {
+	for _, node := range d.Nodes {
+		if node.ID == id {
+			return node, true
+		}
+	}
+	return Node{}, false
+}
+
+// Agents 返回定义中引用的全部智能体名称，按首次出现顺序去重。
+func (d *Definition) Agents() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, node := range d.Nodes {
+		if node.Type == NodeAgent && !seen[node.Agent] {
+			seen[node.Agent] = true
+			names = append(names, node.Agent)
+		}
+	}
+	return names
+}
+
+// findCycle 用深度优先搜索检测依赖环，返回环路描述。
+func (d *Definition) findCycle(index map[string]int) string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(d.Nodes))
+	var stack []string
+	var visit func(i int) string
+	visit = func(i int) string {
+		state[i] = visiting
+		stack = append(stack, d.Nodes[i].ID)
+		for _, dep := range d.Nodes[i].Depends {
+			j := index[dep]
+			switch state[j] {
+			case visiting:
+				start := 0
+				for k, id := range stack {
+					if id == dep {
+						start = k
+					}
+				}
+				return strings.Join(append(append([]string(nil), stack[start:]...), dep), " -> ")
+			case unvisited:
+				if cycle := visit(j); cycle != "" {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[i] = visited
+		return ""
+	}
+	for i := range d.Nodes {
+		if state[i] == unvisited {
+			if cycle := visit(i); cycle != "" {
+				return cycle
+			}
+		}
+	}
+	return ""
+}
+
+// ancestors 返回节点的全部（传递）上游节点。
+func (d *Definition) ancestors(id string, index map[string]int) map[string]bool {
+	result := make(map[string]bool)
+	pending := append([]string(nil), d.Nodes[index[id]].Depends...)
+	for len(pending) > 0 {
+		dep := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if result[dep] {
+			continue
+		}
+		result[dep] = true
+		pending = append(pending, d.Nodes[index[dep]].Depends...)
+	}
+	return result
+}
+
+func placeholders(template string) []string {
+	var refs []string
+	for _, match := range placeholderExpr.FindAllStringSubmatch(template, -1) {
+		refs = append(refs, match[1])
+	}
+	return refs
+}
+
+// render 替换模板中的占位符，未完成节点的占位符替换为空字符串。
+func render(template, input string, outputs map[string]string) string {
+	return placeholderExpr.ReplaceAllStringFunc(template, func(match string) string {
+		ref := placeholderExpr.FindStringSubmatch(match)[1]
+		if ref == InputRef {
+			return input
+		}
+		return outputs[ref]
+	})
+}
+
+// matches 判断条件是否满足；引用节点没有输出时视为不满足。
+func (c *Condition) matches(outputs map[string]string) bool {
+	output, ok := outputs[c.Node]
+	if !ok {
+		return false
+	}
+	for _, want := range c.Contains {
+		if !strings.Contains(output, want) {
+			return false
+		}
+	}
+	for _, unwanted := range c.NotContains {
+		if strings.Contains(output, unwanted) {
+			return false
+		}
+	}
+	if c.pattern != nil && !c.pattern.MatchString(output) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,501 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"fkteams/internal/domain/message"
+	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/events"
+)
+
+// Status 是节点在一次运行中的最终状态。
+type Status string
+
+const (
+	StatusCompleted Status = "completed"
+	StatusSkipped   Status = "skipped"
+	StatusApproved  Status = "approved"
+	StatusRejected  Status = "rejected"
+	StatusFailed    Status = "failed"
+)
+
+// passed 报告节点是否放行下游节点。
+func (s Status) passed() bool {
+	return s == StatusCompleted || s == StatusApproved
+}
+
+// Runner 按依赖关系执行工作流节点：上游全部结束后节点立即启动，无依赖关系的节点并行执行。
+// 每个智能体节点使用独立的 Runner，其事件以成员事件的形式转发，最终回答取自输出节点。
+type Runner struct {
+	def     *Definition
+	runners map[string]runtimeport.Runner
+}
+
+// NewRunner 创建工作流 Runner，runners 以节点 ID 为键提供每个智能体节点的 Runner。
+func NewRunner(def *Definition, runners map[string]runtimeport.Runner) (*Runner, error) {
+	if def == nil {
+		return nil, fmt.Errorf("workflow definition is nil")
+	}
+	for _, node := range def.Nodes {
+		if node.Type == NodeAgent && runners[node.ID] == nil {
+			return nil, fmt.Errorf("workflow %s: node %s has no runner", def.Name, node.ID)
+		}
+	}
+	return &Runner{def: def, runners: runners}, nil
+}
+
+// Definition 返回工作流定义。
+func (r *Runner) Definition() *Definition {
+	return r.def
+}
+
+// GateInfo 是审批闸门中断携带的信息。
+type GateInfo struct {
+	Workflow string
+	Node     string
+	Message  string
+}
+
+// String 返回展示给审批人的说明。
+func (g GateInfo) String() string {
+	return fmt.Sprintf("工作流 %s 在节点 %s 等待审批：\n%s", g.Workflow, g.Node, g.Message)
+}
+
+// Run 执行一次工作流。审批闸门通过 opts.InterruptHandler 请求决策，未配置处理器时视为拒绝。
+func (r *Runner) Run(ctx context.Context, input message.TurnInput, opts runtimeport.RunOptions) (*runtimeport.RunResult, error) {
+	if r == nil || r.def == nil {
+		return nil, fmt.Errorf("runner is nil")
+	}
+	opts = opts.WithDefaults(defaultRunID(r.def.Name))
+	runID := opts.RunID
+	turnID := events.TurnID(runID, 1)
+	run := &workflowRun{
+		def:     r.def,
+		runners: r.runners,
+		opts:    opts,
+		input:   input,
+		runID:   runID,
+		turnID:  turnID,
+		emitter: events.NewEmitter(runID, turnID, opts.Sink),
+		results: make(map[string]Status, len(r.def.Nodes)),
+		outputs: make(map[string]string, len(r.def.Nodes)),
+	}
+
+	if err := run.emit(events.AgentStart(runID)); err != nil {
+		return nil, err
+	}
+	if err := run.emit(events.TurnStart(runID, turnID)); err != nil {
+		return nil, err
+	}
+	// 节点 Runner 的 checkpoint 不随工作流进度保存，进程重启后无法从中断处继续。
+	if len(opts.Resume) > 0 {
+		err := fmt.Errorf("workflow %s cannot resume an interrupted run, please send the request again", r.def.Name)
+		_ = run.emit(events.AgentError(runID, err))
+		return &runtimeport.RunResult{LastEvent: run.lastEvent()}, err
+	}
+	if !input.Message.IsEmpty() && input.Message.Role == message.RoleUser {
+		if err := run.emit(events.UserMessage(runID, turnID, fmt.Sprintf("%s:user", turnID), input.Message)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := run.execute(ctx); err != nil {
+		_ = run.emit(events.AgentError(runID, err))
+		return &runtimeport.RunResult{LastEvent: run.lastEvent()}, err
+	}
+	if err := run.emitAnswer(); err != nil {
+		return nil, err
+	}
+	if err := run.emit(events.TurnEnd(runID, turnID)); err != nil {
+		return nil, err
+	}
+	if err := run.emit(events.AgentEnd(runID)); err != nil {
+		return nil, err
+	}
+	return &runtimeport.RunResult{LastEvent: run.lastEvent()}, nil
+}
+
+// workflowRun 保存一次运行的节点结果；并行节点共享同一事件出口，所有发送都经过 mu 串行化。
+type workflowRun struct {
+	def     *Definition
+	runners map[string]runtimeport.Runner
+	opts    runtimeport.RunOptions
+	input   message.TurnInput
+	runID   string
+	turnID  string
+
+	mu       sync.Mutex
+	emitter  *events.Emitter
+	results  map[string]Status
+	outputs  map[string]string
+	finished []string
+
+	// gateMu 保证同一时刻只有一个审批闸门在等待决策。
+	gateMu sync.Mutex
+}
+
+func (w *workflowRun) emit(event events.Event) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.emitter.Emit(event)
+}
+
+func (w *workflowRun) lastEvent() events.Event {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.emitter.LastEvent()
+}
+
+// execute 为每个节点启动一个 goroutine，节点等待上游全部结束后决定执行或跳过；任一节点出错时取消其余节点。
+func (w *workflowRun) execute(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(map[string]chan struct{}, len(w.def.Nodes))
+	for _, node := range w.def.Nodes {
+		done[node.ID] = make(chan struct{})
+	}
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for order, node := range w.def.Nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[node.ID])
+			for _, dep := range node.Depends {
+				select {
+				case <-done[dep]:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if err := w.runNode(ctx, node, order+1); err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+func (w *workflowRun) runNode(ctx context.Context, node Node, order int) error {
+	if reason := w.skipReason(node); reason != "" {
+		w.record(node.ID, StatusSkipped, "")
+		return w.emitMember(events.EventMemberCompleted, node, order, StatusSkipped, fmt.Sprintf("已跳过：%s", reason))
+	}
+	if err := w.emitMember(events.EventMemberStarted, node, order, "", ""); err != nil {
+		return err
+	}
+
+	var (
+		status Status
+		output string
+		err    error
+	)
+	switch node.Type {
+	case NodeApproval:
+		status, err = w.runGate(ctx, node)
+	default:
+		output, err = w.runAgent(ctx, node, order)
+		status = StatusCompleted
+	}
+	if err != nil {
+		_ = w.emitMember(events.EventMemberCompleted, node, order, StatusFailed, err.Error())
+		return fmt.Errorf("workflow %s: node %s: %w", w.def.Name, node.ID, err)
+	}
+	w.record(node.ID, status, output)
+	content := ""
+	if status == StatusRejected {
+		content = "审批被拒绝，下游节点将跳过"
+	}
+	return w.emitMember(events.EventMemberCompleted, node, order, status, content)
+}
+
+// skipReason 按汇合方式和条件判断节点是否跳过，返回空字符串表示执行。
+func (w *workflowRun) skipReason(node Node) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(node.Depends) > 0 {
+		passed := 0
+		for _, dep := range node.Depends {
+			if w.results[dep].passed() {
+				passed++
+			}
+		}
+		switch {
+		case node.Join == JoinAny && passed == 0:
+			return "上游节点均未完成"
+		case node.Join != JoinAny && passed < len(node.Depends):
+			return "存在未完成的上游节点"
+		}
+	}
+	if node.When != nil && !node.When.matches(w.outputs) {
+		return fmt.Sprintf("节点 %s 的输出不满足条件", node.When.Node)
+	}
+	return ""
+}
+
+func (w *workflowRun) record(id string, status Status, output string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.results[id] = status
+	if status.passed() {
+		w.outputs[id] = output
+		w.finished = append(w.finished, id)
+	}
+}
+
+// prompt 渲染节点模板；未配置模板时发送用户输入并附上游节点输出。
+func (w *workflowRun) prompt(node Node) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	input := w.input.Message.DisplayText()
+	if node.Prompt != "" {
+		return render(node.Prompt, input, w.outputs)
+	}
+	var b strings.Builder
+	b.WriteString(input)
+	for _, dep := range node.Depends {
+		output, ok := w.outputs[dep]
+		if !ok || strings.TrimSpace(output) == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "\n\n## %s 的输出\n\n%s", dep, output)
+	}
+	return b.String()
+}
+
+func (w *workflowRun) runAgent(ctx context.Context, node Node, order int) (string, error) {
+	var (
+		forwardErr error
+		answer     strings.Builder
+		final      string
+	)
+	sink := func(e events.Event) error {
+		switch e.Type {
+		case events.EventAgentStarted, events.EventAgentCompleted,
+			events.EventTurnStarted, events.EventTurnCompleted, events.EventTurnFailed,
+			events.EventUserMessage:
+			return nil
+		}
+		// 节点自身的回答是节点输出；嵌套成员（如团队智能体的成员）保留原有成员身份。
+		if e.MemberCallID == "" {
+			switch e.Type {
+			case events.EventAssistantStarted:
+				answer.Reset()
+			case events.EventAssistantText:
+				if e.DeltaKind == "" || e.DeltaKind == events.DeltaOutput {
+					answer.WriteString(e.Content)
+				}
+			case events.EventAssistantCompleted:
+				text := answer.String()
+				if text == "" {
+					text = e.Content
+				}
+				if strings.TrimSpace(text) != "" && len(e.ToolCalls) == 0 {
+					final = text
+				}
+			}
+			w.applyMember(&e, node, order)
+		}
+		e.RunID, e.TurnID = w.runID, w.turnID
+		if err := w.emit(e); err != nil {
+			forwardErr = err
+			return err
+		}
+		return nil
+	}
+
+	checkpointID := w.opts.CheckpointID
+	if checkpointID != "" {
+		checkpointID += ":" + node.ID
+	}
+	_, err := w.runners[node.ID].Run(ctx, message.TurnInput{
+		Context: w.input.Context,
+		Message: message.Message{Role: message.RoleUser, Content: w.prompt(node)},
+	}, runtimeport.RunOptions{
+		RunID:            fmt.Sprintf("%s:%s", w.runID, node.ID),
+		CheckpointID:     checkpointID,
+		Sink:             sink,
+		InterruptHandler: w.nodeInterruptHandler(node, order),
+	})
+	if err != nil {
+		return "", err
+	}
+	if forwardErr != nil {
+		return "", forwardErr
+	}
+	return final, nil
+}
+
+// nodeInterruptHandler 为节点内工具审批补齐成员身份，并与审批闸门共用串行化的处理器。
+func (w *workflowRun) nodeInterruptHandler(node Node, order int) runtimeport.InterruptHandler {
+	if w.opts.InterruptHandler == nil {
+		return nil
+	}
+	return func(ctx context.Context, interrupts []runtimeport.Interrupt) (runtimeport.InterruptDecisions, error) {
+		for i := range interrupts {
+			if interrupts[i].MemberCallID == "" {
+				interrupts[i].MemberCallID = w.memberCallID(node)
+				interrupts[i].MemberName = memberName(node)
+				interrupts[i].MemberOrder = &order
+			}
+		}
+		w.gateMu.Lock()
+		defer w.gateMu.Unlock()
+		return w.opts.InterruptHandler(ctx, interrupts)
+	}
+}
+
+func (w *workflowRun) runGate(ctx context.Context, node Node) (Status, error) {
+	if w.opts.InterruptHandler == nil {
+		return StatusRejected, nil
+	}
+	w.mu.Lock()
+	text := render(node.Message, w.input.Message.DisplayText(), w.outputs)
+	w.mu.Unlock()
+	if strings.TrimSpace(text) == "" {
+		text = "是否继续执行后续节点？"
+	}
+	id := w.memberCallID(node)
+
+	w.gateMu.Lock()
+	defer w.gateMu.Unlock()
+	decisions, err := w.opts.InterruptHandler(ctx, []runtimeport.Interrupt{{
+		ID:          id,
+		IsRootCause: true,
+		Info:        GateInfo{Workflow: w.def.Name, Node: node.ID, Message: text},
+	}})
+	if err != nil {
+		return "", err
+	}
+	if approved(decisions[id]) {
+		return StatusApproved, nil
+	}
+	return StatusRejected, nil
+}
+
+// approved 解释审批决策：大于 0 的审批级别或 true 视为通过。
+func approved(decision any) bool {
+	switch v := decision.(type) {
+	case bool:
+		return v
+	case int:
+		return v > 0
+	case int64:
+		return v > 0
+	case float64:
+		return v > 0
+	default:
+		return false
+	}
+}
+
+// emitAnswer 以工作流名义输出最终回答。
+func (w *workflowRun) emitAnswer() error {
+	w.mu.Lock()
+	outputNode := w.def.Output
+	if outputNode == "" {
+		outputNode = w.lastSink()
+	}
+	text, ok := w.outputs[outputNode]
+	w.mu.Unlock()
+	if !ok || strings.TrimSpace(text) == "" {
+		return w.emit(events.SystemNotice(w.def.Name, "", "workflow_no_output", fmt.Sprintf("工作流 %s 没有产生输出", w.def.Name)))
+	}
+
+	meta := events.MessageEvent{
+		MessageID: fmt.Sprintf("%s:workflow", w.turnID),
+		Role:      message.RoleAssistant,
+		AgentName: w.def.Name,
+	}
+	if err := w.emit(events.AssistantStarted(meta)); err != nil {
+		return err
+	}
+	meta.DeltaKind = events.DeltaOutput
+	if err := w.emit(events.AssistantDelta(meta, text)); err != nil {
+		return err
+	}
+	meta.Content = text
+	meta.Message = &message.Message{Role: message.RoleAssistant, Content: text}
+	return w.emit(events.AssistantCompleted(meta))
+}
+
+// lastSink 返回最后完成的、没有下游节点的智能体节点，调用方需持有 mu。
+func (w *workflowRun) lastSink() string {
+	hasDownstream := make(map[string]bool)
+	for _, node := range w.def.Nodes {
+		for _, dep := range node.Depends {
+			hasDownstream[dep] = true
+		}
+	}
+	for i := len(w.finished) - 1; i >= 0; i-- {
+		id := w.finished[i]
+		node, _ := w.def.Node(id)
+		if node.Type == NodeAgent && !hasDownstream[id] {
+			return id
+		}
+	}
+	for i := len(w.finished) - 1; i >= 0; i-- {
+		if node, _ := w.def.Node(w.finished[i]); node.Type == NodeAgent {
+			return node.ID
+		}
+	}
+	return ""
+}
+
+func defaultRunID(name string) string {
+	return "workflow_" + name
+}
+
+func (w *workflowRun) memberCallID(node Node) string {
+	return fmt.Sprintf("workflow:%s:%s", w.def.Name, node.ID)
+}
+
+func memberName(node Node) string {
+	if node.Type == NodeAgent {
+		return fmt.Sprintf("%s（%s）", node.ID, node.Agent)
+	}
+	return node.ID
+}
+
+func (w *workflowRun) applyMember(e *events.Event, node Node, order int) {
+	e.MemberCallID = w.memberCallID(node)
+	e.MemberName = memberName(node)
+	e.MemberOrder = &order
+	e.ParentToolCallID = e.MemberCallID
+}
+
+func (w *workflowRun) emitMember(eventType events.EventType, node Node, order int, status Status, content string) error {
+	detail, _ := json.Marshal(map[string]any{
+		"workflow": w.def.Name,
+		"node":     node.ID,
+		"type":     node.Type,
+		"agent":    node.Agent,
+		"status":   status,
+	})
+	e := events.Event{
+		Type:      eventType,
+		AgentName: node.Agent,
+		Content:   content,
+		Detail:    string(detail),
+	}
+	w.applyMember(&e, node, order)
+	return w.emit(e)
+}
@@ -0,0 +1,276 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"fkteams/internal/domain/message"
+	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/env"
+	"fkteams/internal/runtime/events"
+)
+
+const pipelineTOML = `
+description = "调研后分析并写脚本"
+output = "review"
+
+[[nodes]]
+id = "research"
+agent = "researcher"
+
+[[nodes]]
+id = "analyze"
+agent = "analyst"
+depends = ["research"]
+prompt = "整理成表格：{{research}}"
+
+[[nodes]]
+id = "script"
+agent = "coder"
+depends = ["research"]
+when = { node = "research", contains = ["数据"] }
+
+[[nodes]]
+id = "slides"
+agent = "designer"
+depends = ["research"]
+when = { node = "research", matches = "^幻灯片" }
+
+[[nodes]]
+id = "confirm"
+type = "approval"
+depends = ["analyze", "script"]
+message = "脚本如下：{{script}}"
+
+[[nodes]]
+id = "review"
+agent = "reviewer"
+depends = ["confirm", "slides"]
+join = "any"
+prompt = "审查 {{input}}：{{analyze}} / {{script}}"
+`
+
+func TestLoadParsesTOMLAndYAML(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "config", "workflows")
+	t.Setenv(env.AppDir, filepath.Dir(filepath.Dir(dir)))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"pipeline.toml": pipelineTOML,
+		"simple.yaml":   "nodes:\n  - id: a\n    agent: coder\n  - id: b\n    agent: reviewer\n    depends: [a]\n",
+		"broken.yml":    "nodes:\n  - id: a\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	def, err := Load("pipeline")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if def.Name != "pipeline" || len(def.Nodes) != 6 || def.Nodes[0].Type != NodeAgent || def.Nodes[0].Join != JoinAll {
+		t.Fatalf("definition = %#v", def)
+	}
+	if got := strings.Join(def.Agents(), ","); got != "researcher,analyst,coder,designer,reviewer" {
+		t.Fatalf("agents = %s", got)
+	}
+	if _, err := Load("missing"); err == nil {
+		t.Fatal("expected missing workflow error")
+	}
+
+	entries, err := List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[0].Name != "broken" || entries[0].Err == nil || entries[2].Definition == nil {
+		t.Fatalf("entries = %#v", entries)
+	}
+}
+
+func TestValidateRejectsInvalidGraphs(t *testing.T) {
+	for name, content := range map[string]string{
+		"empty":              "description = \"x\"\n",
+		"unknown field":      "[[nodes]]\nid = \"a\"\nagent = \"coder\"\nretries = 2\n",
+		"missing agent":      "[[nodes]]\nid = \"a\"\n",
+		"duplicate":          "[[nodes]]\nid = \"a\"\nagent = \"x\"\n[[nodes]]\nid = \"a\"\nagent = \"y\"\n",
+		"unknown dependency": "[[nodes]]\nid = \"a\"\nagent = \"x\"\ndepends = [\"b\"]\n",
+		"cycle":              "[[nodes]]\nid = \"a\"\nagent = \"x\"\ndepends = [\"b\"]\n[[nodes]]\nid = \"b\"\nagent = \"y\"\ndepends = [\"a\"]\n",
+		"downstream ref":     "[[nodes]]\nid = \"a\"\nagent = \"x\"\nprompt = \"{{b}}\"\n[[nodes]]\nid = \"b\"\nagent = \"y\"\ndepends = [\"a\"]\n",
+		"bad regexp":         "[[nodes]]\nid = \"a\"\nagent = \"x\"\n[[nodes]]\nid = \"b\"\nagent = \"y\"\ndepends = [\"a\"]\nwhen = { node = \"a\", matches = \"(\" }\n",
+		"reserved id":        "[[nodes]]\nid = \"input\"\nagent = \"x\"\n",
+		"unknown output":     "output = \"z\"\n[[nodes]]\nid = \"a\"\nagent = \"x\"\n",
+	} {
+		if _, err := Parse([]byte(content), name+".toml"); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+	if _, err := Parse([]byte(pipelineTOML), "pipeline.json"); err == nil {
+		t.Error("expected unsupported extension error")
+	}
+}
+
+// echoRunner 记录收到的提示词，并以固定回答模拟一次智能体运行。
+type echoRunner struct {
+	mu      sync.Mutex
+	reply   string
+	prompts []string
+}
+
+func (r *echoRunner) Run(_ context.Context, input message.TurnInput, opts runtimeport.RunOptions) (*runtimeport.RunResult, error) {
+	r.mu.Lock()
+	r.prompts = append(r.prompts, input.Message.Content)
+	r.mu.Unlock()
+	meta := events.MessageEvent{MessageID: opts.RunID + ":msg", Role: message.RoleAssistant, AgentName: "node"}
+	for _, e := range []events.Event{
+		events.AgentStart(opts.RunID),
+		events.AssistantStarted(meta),
+		events.AssistantDelta(meta, r.reply),
+		events.AssistantCompleted(meta),
+		events.AgentEnd(opts.RunID),
+	} {
+		if err := opts.Sink(e); err != nil {
+			return nil, err
+		}
+	}
+	return &runtimeport.RunResult{}, nil
+}
+
+func (r *echoRunner) lastPrompt() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.prompts) == 0 {
+		return ""
+	}
+	return r.prompts[len(r.prompts)-1]
+}
+
+func newPipeline(t *testing.T) (*Runner, map[string]*echoRunner) {
+	t.Helper()
+	def, err := Parse([]byte(pipelineTOML), "pipeline.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakes := map[string]*echoRunner{
+		"research": {reply: "数据：A=1"},
+		"analyze":  {reply: "| A | 1 |"},
+		"script":   {reply: "print(1)"},
+		"slides":   {reply: "不应执行"},
+		"review":   {reply: "审查通过"},
+	}
+	runners := make(map[string]runtimeport.Runner, len(fakes))
+	for id, fake := range fakes {
+		runners[id] = fake
+	}
+	runner, err := NewRunner(def, runners)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return runner, fakes
+}
+
+func TestRunnerExecutesDAGWithBranchesAndApproval(t *testing.T) {
+	runner, fakes := newPipeline(t)
+
+	var (
+		mu        sync.Mutex
+		collected []events.Event
+		gates     []runtimeport.Interrupt
+	)
+	sink := func(e events.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		collected = append(collected, e)
+		return nil
+	}
+	handler := func(_ context.Context, interrupts []runtimeport.Interrupt) (runtimeport.InterruptDecisions, error) {
+		gates = append(gates, interrupts...)
+		return runtimeport.InterruptDecisions{interrupts[0].ID: 1}, nil
+	}
+	_, err := runner.Run(context.Background(), message.TurnInput{Message: message.Message{Role: message.RoleUser, Content: "销量"}}, runtimeport.RunOptions{
+		CheckpointID:     "session",
+		Sink:             sink,
+		InterruptHandler: handler,
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if got := fakes["analyze"].lastPrompt(); got != "整理成表格：数据：A=1" {
+		t.Fatalf("analyze prompt = %q", got)
+	}
+	if got := fakes["script"].lastPrompt(); !strings.HasPrefix(got, "销量") || !strings.Contains(got, "## research 的输出\n\n数据：A=1") {
+		t.Fatalf("script default prompt = %q", got)
+	}
+	if len(fakes["slides"].prompts) != 0 {
+		t.Fatal("slides branch should be skipped")
+	}
+	if got := fakes["review"].lastPrompt(); got != "审查 销量：| A | 1 | / print(1)" {
+		t.Fatalf("review prompt = %q", got)
+	}
+	if len(gates) != 1 || gates[0].ID != "workflow:pipeline:confirm" || !strings.Contains(fmt.Sprint(gates[0].Info), "脚本如下：print(1)") {
+		t.Fatalf("gates = %#v", gates)
+	}
+
+	statuses := map[string]string{}
+	var answer string
+	for _, e := range collected {
+		if err := events.ValidateEventContract(e); err != nil {
+			t.Fatalf("invalid event %#v: %v", e, err)
+		}
+		switch {
+		case e.Type == events.EventMemberCompleted:
+			statuses[strings.TrimPrefix(e.MemberCallID, "workflow:pipeline:")] = e.Detail
+		case e.Type == events.EventAssistantCompleted && e.MemberCallID == "":
+			answer = e.Content
+		case e.Type == events.EventAssistantText && e.MemberCallID != "" && e.MemberOrder == nil:
+			t.Fatalf("member event without order: %#v", e)
+		case e.Type == events.EventAgentStarted && e.RunID != "session":
+			t.Fatalf("node agent_started leaked: %#v", e)
+		}
+	}
+	if answer != "审查通过" {
+		t.Fatalf("final answer = %q", answer)
+	}
+	for node, status := range map[string]string{"research": "completed", "slides": "skipped", "confirm": "approved", "review": "completed"} {
+		if !strings.Contains(statuses[node], `"status":"`+status+`"`) {
+			t.Fatalf("%s status detail = %s", node, statuses[node])
+		}
+	}
+}
+
+func TestRunnerRejectedGateSkipsDownstream(t *testing.T) {
+	runner, fakes := newPipeline(t)
+	var answer, notice string
+	_, err := runner.Run(context.Background(), message.TurnInput{Message: message.Message{Role: message.RoleUser, Content: "销量"}}, runtimeport.RunOptions{
+		Sink: func(e events.Event) error {
+			switch {
+			case e.Type == events.EventAssistantCompleted && e.MemberCallID == "":
+				answer = e.Content
+			case e.Type == events.EventSystemNotice:
+				notice = e.Content
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(fakes["review"].prompts) != 0 {
+		t.Fatal("review should be skipped after rejected gate")
+	}
+	if answer != "" || !strings.Contains(notice, "没有产生输出") {
+		t.Fatalf("answer = %q, notice = %q", answer, notice)
+	}
+
+	if _, err := runner.Run(context.Background(), message.TurnInput{}, runtimeport.RunOptions{Resume: runtimeport.InterruptDecisions{"x": 1}}); err == nil {
+		t.Fatal("expected resume error")
+	}
+}
@@ -63,7 +63,7 @@ func (b *bunInitializer) install() error {
 		name = "sh"
 		args = []string{"-c", "curl -fsSL https://bun.sh/install | bash"}
 	}
-	if err := runCommand(appendProxyEnv(os.Environ()), name, args...); err != nil {
+	if err := runCommand(appendProxyEnv(env.ChildEnviron()), name, args...); err != nil {
 		return fmt.Errorf("install command failed: %w", err)
 	}
 	return nil
@@ -63,7 +63,7 @@ func (u *uvInitializer) install() error {
 		name = "sh"
 		args = []string{"-c", "curl -LsSf https://astral.sh/uv/install.sh | sh"}
 	}
-	if err := runCommand(appendProxyEnv(os.Environ()), name, args...); err != nil {
+	if err := runCommand(appendProxyEnv(env.ChildEnviron()), name, args...); err != nil {
 		return fmt.Errorf("install command failed: %w", err)
 	}
 	return nil
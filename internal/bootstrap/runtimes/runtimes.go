@@ -7,6 +7,7 @@ import (
 	einoproviders "fkteams/internal/adapters/runtime/eino/providers/register"
 	toolmcp "fkteams/internal/adapters/tools/mcp"
 	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/hooks"
 	modelregistry "fkteams/internal/runtime/model"
 	runtimeregistry "fkteams/internal/runtime/registry"
 )
@@ -18,6 +19,7 @@ type Defaults struct {
 	Interrupt             runtimeport.InterruptRuntime
 	ModelRegistry         *modelregistry.Registry
 	ModelProviderRegistry *modelproviders.Registry
+	HookBus               *hooks.Bus
 }
 
 // Options 描述 runtime 组合根的显式外部依赖。
@@ -55,5 +57,6 @@ func NewDefaults(options ...Options) (*Defaults, error) {
 		Interrupt:             einoruntime.NewInterruptRuntime(),
 		ModelRegistry:         modelRegistry,
 		ModelProviderRegistry: providerRegistry,
+		HookBus:               hooks.NewBus(),
 	}, nil
 }
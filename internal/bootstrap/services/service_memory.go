@@ -5,6 +5,7 @@ import (
 	memorymodel "fkteams/internal/adapters/model/memory"
 	"fkteams/internal/app/agent/catalog/common"
 	"fkteams/internal/app/appstate"
+	"fkteams/internal/app/config"
 	"fkteams/internal/app/memory"
 	"fkteams/internal/runtime/log"
 	"fmt"
@@ -39,7 +40,11 @@ func (s *MemoryService) Start(ctx context.Context) error {
 		log.Printf("[memory] 适配模型失败，记忆服务未启动: %v", err)
 		return nil
 	}
-	s.state.SetMemory(memory.NewManager(s.workspaceDir, llmClient, nil))
+	embedder, err := memorymodel.NewEmbedder(config.Get().Memory.Embedding)
+	if err != nil {
+		log.Printf("[memory] 创建向量模型失败，仅使用 BM25 检索: %v", err)
+	}
+	s.state.SetMemory(memory.NewManager(s.workspaceDir, llmClient, &memory.Config{Embedder: embedder, MinSimilarity: config.Get().Memory.Embedding.MinSimilarity}))
 	return nil
 }
 
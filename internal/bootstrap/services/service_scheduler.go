@@ -6,12 +6,19 @@ import (
 	"path/filepath"
 	"sync"
 
+	"fkteams/internal/adapters/scheduler/delivery"
 	"fkteams/internal/adapters/scheduler/filecron"
+	"fkteams/internal/adapters/transport/channel"
 	appagent "fkteams/internal/app/agent"
 	agents "fkteams/internal/app/agent/catalog"
+	"fkteams/internal/app/config"
 	appschedule "fkteams/internal/app/schedule"
 	apptools "fkteams/internal/app/tools"
+	appusage "fkteams/internal/app/usage"
+	"fkteams/internal/app/userhooks"
+	domainschedule "fkteams/internal/domain/schedule"
 	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/hooks"
 	modelregistry "fkteams/internal/runtime/model"
 )
 
@@ -21,15 +28,23 @@ type SchedulerService struct {
 	mu           sync.Mutex
 	scheduler    *filecron.Scheduler
 	service      *appschedule.Service
+	watcher      *appschedule.FileWatcher
+	channels     *delivery.ChannelSender
 }
 
 // NewSchedulerService 创建调度服务
 func NewSchedulerService(schedulerDir string) *SchedulerService {
 	return &SchedulerService{
 		schedulerDir: schedulerDir,
+		channels:     delivery.NewChannelSender(),
 	}
 }
 
+// SetChannelManager 绑定消息通道管理器，供任务结果投递到通道会话。
+func (s *SchedulerService) SetChannelManager(manager *channel.Manager) {
+	s.channels.SetManager(manager)
+}
+
 // Name 返回服务名称
 func (s *SchedulerService) Name() string { return "scheduler" }
 
@@ -59,22 +74,36 @@ func (s *SchedulerService) Start(ctx context.Context) error {
 	agentRegistry, _ := agents.RegistryFromContext(ctx)
 	models, _ := modelregistry.RegistryFromContext(ctx)
 	tools, _ := apptools.RegistryFromContext(ctx)
-	executor, err := appschedule.NewBackgroundExecutor(appagent.CreateBackgroundTaskRunner, filepath.Join(s.schedulerDir, "tasks"))
+	hookBus := hooks.FromContext(ctx)
+	ledger := appusage.FromContext(ctx)
+	userHooks := userhooks.FromContext(ctx)
+	executor, err := appschedule.NewBackgroundExecutor(appagent.CreateScheduledTaskRunner, filepath.Join(s.schedulerDir, "tasks"))
 	if err != nil {
 		return fmt.Errorf("initialize scheduler executor: %w", err)
 	}
+	executor.WithSenders(map[domainschedule.DeliveryType]appschedule.Sender{
+		domainschedule.DeliveryChannel: s.channels,
+		domainschedule.DeliveryWebhook: delivery.NewWebhookSender(func() string { return config.Get().Scheduler.WebhookSecret }),
+		domainschedule.DeliveryEmail:   delivery.NewEmailSender(func() config.SMTP { return config.Get().Scheduler.SMTP }),
+	})
 	executor.WithContextHook(func(ctx context.Context) context.Context {
 		ctx = runtimeport.WithRuntime(ctx, runtime)
 		ctx = runtimeport.WithInterruptRuntime(ctx, interrupt)
 		ctx = agents.WithRegistry(ctx, agentRegistry)
 		ctx = modelregistry.WithRegistry(ctx, models)
 		ctx = apptools.WithRegistry(ctx, tools)
+		ctx = hooks.WithBus(ctx, hookBus)
+		ctx = appusage.WithLedger(ctx, ledger)
+		ctx = userhooks.WithManager(ctx, userHooks)
 		return appschedule.WithService(ctx, appService)
 	})
 	sched.SetExecutor(executor)
 	sched.Start()
+	watcher := appschedule.NewFileWatcher(appService, appschedule.DefaultFileWatchInterval)
+	watcher.Start()
 	s.scheduler = sched
 	s.service = appService
+	s.watcher = watcher
 	return nil
 }
 
@@ -82,6 +111,10 @@ func (s *SchedulerService) Start(ctx context.Context) error {
 func (s *SchedulerService) Stop(ctx context.Context) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.watcher != nil {
+		s.watcher.Stop()
+		s.watcher = nil
+	}
 	if s.scheduler != nil {
 		if err := s.scheduler.Stop(ctx); err != nil {
 			return err
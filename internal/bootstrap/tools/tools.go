@@ -11,6 +11,7 @@ import (
 	fetchtool "fkteams/internal/adapters/tools/builtin/fetch"
 	filetool "fkteams/internal/adapters/tools/builtin/file"
 	gittool "fkteams/internal/adapters/tools/builtin/git"
+	memorytool "fkteams/internal/adapters/tools/builtin/memory"
 	schedulertool "fkteams/internal/adapters/tools/builtin/scheduler"
 	buntool "fkteams/internal/adapters/tools/builtin/script/bun"
 	uvtool "fkteams/internal/adapters/tools/builtin/script/uv"
@@ -24,6 +25,7 @@ import (
 	"fkteams/internal/app/tools/ask"
 	runtimeport "fkteams/internal/ports/runtime"
 	toolport "fkteams/internal/ports/tools"
+	"fkteams/internal/runtime/sandbox"
 )
 
 func runtimeDir() string {
@@ -58,7 +60,11 @@ func RegisterDefaults(mcpProvider toolport.MCPProvider) (*apptools.ToolGroupRegi
 				IncludedTools: []string{"file_read", "file_write", "file_search", "file_list"},
 			},
 			Factory: func(ctx apptools.ToolResolveContext) ([]runtimeport.Tool, error) {
-				fileTools, err := filetool.NewFileTools(ctx.WorkspaceDir)
+				var opts []filetool.Option
+				if ctx.ConfinedRoot != "" {
+					opts = append(opts, filetool.WithConfined())
+				}
+				fileTools, err := filetool.NewFileTools(ctx.WorkspaceDir, opts...)
 				if err != nil {
 					return nil, fmt.Errorf("初始化文件工具失败: %w", err)
 				}
@@ -95,6 +101,19 @@ func RegisterDefaults(mcpProvider toolport.MCPProvider) (*apptools.ToolGroupRegi
 				return ask.GetTools()
 			},
 		},
+		{
+			Info: apptools.ToolGroupInfo{
+				Name:          "memory",
+				DisplayName:   "长期记忆",
+				Description:   "主动保存、检索、修改和删除长期记忆，适合记录已确定的方案、避坑经验和用户要求记住的内容。删除记忆需要审批。",
+				Category:      "协作",
+				Builtin:       true,
+				IncludedTools: []string{"memory_save", "memory_search", "memory_update", "memory_forget"},
+			},
+			Factory: func(apptools.ToolResolveContext) ([]runtimeport.Tool, error) {
+				return memorytool.NewTools(nil).GetTools()
+			},
+		},
 		{
 			Info: apptools.ToolGroupInfo{
 				Name:          "command",
@@ -103,6 +122,7 @@ func RegisterDefaults(mcpProvider toolport.MCPProvider) (*apptools.ToolGroupRegi
 				Category:      "开发",
 				Builtin:       true,
 				IncludedTools: []string{"execute"},
+				Sandboxed:     true,
 			},
 			Factory: commandToolGroup(commandtool.ApprovalModeHITL),
 		},
@@ -114,6 +134,7 @@ func RegisterDefaults(mcpProvider toolport.MCPProvider) (*apptools.ToolGroupRegi
 				Category:      "内部",
 				Builtin:       true,
 				IncludedTools: []string{"execute"},
+				Sandboxed:     true,
 				Hidden:        true,
 			},
 			Factory: commandToolGroup(commandtool.ApprovalModeReject),
@@ -126,9 +147,10 @@ func RegisterDefaults(mcpProvider toolport.MCPProvider) (*apptools.ToolGroupRegi
 				Category:      "开发",
 				Builtin:       true,
 				IncludedTools: []string{"uv_python"},
+				Sandboxed:     true,
 			},
 			Factory: func(ctx apptools.ToolResolveContext) ([]runtimeport.Tool, error) {
-				uvTools, err := uvtool.NewUVTools(ctx.RuntimeDir, ctx.WorkspaceDir)
+				uvTools, err := uvtool.NewUVTools(ctx.RuntimeDir, ctx.WorkspaceDir, uvtool.WithSandbox(toolSandbox(ctx)))
 				if err != nil {
 					return nil, fmt.Errorf("初始化 uv 工具失败: %w", err)
 				}
@@ -143,9 +165,10 @@ func RegisterDefaults(mcpProvider toolport.MCPProvider) (*apptools.ToolGroupRegi
 				Category:      "开发",
 				Builtin:       true,
 				IncludedTools: []string{"bun_javascript"},
+				Sandboxed:     true,
 			},
 			Factory: func(ctx apptools.ToolResolveContext) ([]runtimeport.Tool, error) {
-				bunTools, err := buntool.NewBunTools(ctx.RuntimeDir, ctx.WorkspaceDir)
+				bunTools, err := buntool.NewBunTools(ctx.RuntimeDir, ctx.WorkspaceDir, buntool.WithSandbox(toolSandbox(ctx)))
 				if err != nil {
 					return nil, fmt.Errorf("初始化 bun 工具失败: %w", err)
 				}
@@ -204,7 +227,10 @@ func RegisterDefaults(mcpProvider toolport.MCPProvider) (*apptools.ToolGroupRegi
 				Builtin:       true,
 				IncludedTools: []string{"doc_info", "doc_smart_read", "doc_read_pages", "doc_read_lines"},
 			},
-			Factory: func(apptools.ToolResolveContext) ([]runtimeport.Tool, error) {
+			Factory: func(ctx apptools.ToolResolveContext) ([]runtimeport.Tool, error) {
+				if ctx.ConfinedRoot != "" {
+					return doctool.GetTools(doctool.WithBaseDir(ctx.WorkspaceDir))
+				}
 				return doctool.GetTools()
 			},
 		},
@@ -288,6 +314,14 @@ func RegisterDefaults(mcpProvider toolport.MCPProvider) (*apptools.ToolGroupRegi
 	return registry, nil
 }
 
+// toolSandbox 返回命令类工具的执行后端，限定在成员文件区中的会话必须在沙箱中运行
+func toolSandbox(ctx apptools.ToolResolveContext) sandbox.Executor {
+	if ctx.ConfinedRoot != "" {
+		return apptools.ConfinedSandboxExecutor(ctx.Sandbox, ctx.ConfinedRoot)
+	}
+	return apptools.SandboxExecutor(ctx.Sandbox)
+}
+
 func commandToolGroup(mode commandtool.ApprovalMode) apptools.ToolGroupFactory {
 	return func(ctx apptools.ToolResolveContext) ([]runtimeport.Tool, error) {
 		if ctx.Cleaner != nil {
@@ -297,6 +331,10 @@ func commandToolGroup(mode commandtool.ApprovalMode) apptools.ToolGroupFactory {
 				return nil
 			})
 		}
-		return commandtool.NewCommandTools(ctx.WorkspaceDir, commandtool.WithApprovalMode(mode)).GetTools()
+		return commandtool.NewCommandTools(ctx.WorkspaceDir,
+			commandtool.WithApprovalMode(mode),
+			commandtool.WithEnv(ctx.Env),
+			commandtool.WithSandbox(toolSandbox(ctx)),
+		).GetTools()
 	}
 }
@@ -58,6 +58,31 @@ func TestBootstrapRegistersGitToolGroup(t *testing.T) {
 	}
 }
 
+func TestBootstrapRegistersMemoryToolGroup(t *testing.T) {
+	registry, err := RegisterDefaults(mcpadapter.NewProvider())
+	if err != nil {
+		t.Fatalf("RegisterDefaults should be idempotent: %v", err)
+	}
+	ctx := apptools.WithRegistry(context.Background(), registry)
+	resolved, err := apptools.GetToolsByName(ctx, "memory")
+	if err != nil {
+		t.Fatalf("GetToolsByName returned error: %v", err)
+	}
+	var names []string
+	for _, tool := range resolved {
+		info, err := tool.Info(context.Background())
+		if err != nil {
+			t.Fatalf("tool info: %v", err)
+		}
+		names = append(names, info.Name)
+	}
+	for _, want := range []string{"memory_save", "memory_search", "memory_update", "memory_forget"} {
+		if !contains(names, want) {
+			t.Fatalf("memory tools = %v, missing %s", names, want)
+		}
+	}
+}
+
 func TestBootstrapRegistersSSHToolGroup(t *testing.T) {
 	registry, err := RegisterDefaults(mcpadapter.NewProvider())
 	if err != nil {
@@ -0,0 +1,125 @@
+// Package account 定义 Web/API 服务的用户账号、角色和请求身份。
+package account
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Role 是账号角色，决定可执行的操作范围。
+type Role string
+
+const (
+	// RoleAdmin 可以访问所有用户的数据，并管理账号和服务配置。
+	RoleAdmin Role = "admin"
+	// RoleMember 可以对话、管理自己的会话、定时任务和分享链接。
+	RoleMember Role = "member"
+	// RoleViewer 只能查看自己的数据，不能发起修改。
+	RoleViewer Role = "viewer"
+)
+
+// Valid 判断角色是否受支持。
+func (r Role) Valid() bool {
+	return r == RoleAdmin || r == RoleMember || r == RoleViewer
+}
+
+// CanWrite 判断角色是否可以发起修改类请求。
+func (r Role) CanWrite() bool {
+	return r == RoleAdmin || r == RoleMember
+}
+
+// User 是持久化的账号，PasswordHash 为 bcrypt 哈希。
+// 通过单点登录创建的账号没有密码，Provider 和 Subject 记录身份提供方和用户标识。
+type User struct {
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"password_hash,omitempty"`
+	Role         Role      `json:"role"`
+	Disabled     bool      `json:"disabled,omitempty"`
+	Provider     string    `json:"provider,omitempty"`
+	Subject      string    `json:"subject,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// External 判断账号是否由单点登录创建。
+func (u User) External() bool {
+	return u.Provider != ""
+}
+
+// TokenCredential 返回混入登录令牌签名的账号凭据。修改密码，或删除后以同名重建账号，
+// 都会改变凭据，使旧令牌失效。
+func (u User) TokenCredential() string {
+	return u.PasswordHash + "\x00" + u.Provider + "\x00" + u.Subject + "\x00" + u.CreatedAt.UTC().Format(time.RFC3339Nano)
+}
+
+// Directory 是账号存储的结构化快照。
+type Directory struct {
+	Users []User `json:"users"`
+	// LegacyOwner 记录已完成迁移的单用户账号，迁移时无归属的数据会归属该账号。
+	LegacyOwner string `json:"legacy_owner,omitempty"`
+}
+
+// Principal 是已通过认证的请求身份。
+type Principal struct {
+	Username string `json:"username"`
+	Role     Role   `json:"role"`
+	// Builtin 表示配置文件中 server.auth 定义的管理员，不在账号存储中。
+	Builtin bool `json:"builtin,omitempty"`
+	// APIKey 是请求使用的 API 密钥 ID，使用登录令牌时为空。
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// IsAdmin 判断身份是否为管理员。
+func (p Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin
+}
+
+// Owns 判断身份是否可以访问归属于 owner 的数据。
+// 管理员可以访问所有数据；没有归属的数据只有管理员可以访问。
+func (p Principal) Owns(owner string) bool {
+	return p.IsAdmin() || owner != "" && owner == p.Username
+}
+
+// ValidateUsername 校验用户名，用户名会出现在记忆作用域和令牌中，只允许常见的账号字符。
+func ValidateUsername(username string) error {
+	if username == "" || len(username) > 64 {
+		return fmt.Errorf("username must be 1-64 characters")
+	}
+	for _, r := range username {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("._-@", r)) {
+			return fmt.Errorf("username may only contain letters, digits, '.', '_', '-' and '@'")
+		}
+	}
+	return nil
+}
+
+type principalKey struct{}
+
+// WithPrincipal 将请求身份注入 context。
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext 从 context 中提取请求身份，未启用认证时返回 false。
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	if ctx == nil {
+		return Principal{}, false
+	}
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// CanAccess 判断 context 中的身份是否可以访问归属于 owner 的数据；未启用认证时不做限制。
+func CanAccess(ctx context.Context, owner string) bool {
+	principal, ok := PrincipalFromContext(ctx)
+	return !ok || principal.Owns(owner)
+}
+
+// OwnerFromContext 返回新建数据应记录的归属账号，未启用认证时为空。
+func OwnerFromContext(ctx context.Context) string {
+	principal, _ := PrincipalFromContext(ctx)
+	return principal.Username
+}
@@ -0,0 +1,98 @@
+// Package apikey 定义具名、可过期、按范围授权的 API 密钥。
+package apikey
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Prefix 是 API 密钥明文的固定前缀，用于与登录令牌区分。
+const Prefix = "sk-fkteams-"
+
+// Scope 是密钥可访问的接口范围。
+type Scope string
+
+const (
+	// ScopeOpenAI 允许调用 /v1 OpenAI/Anthropic 兼容接口。
+	ScopeOpenAI Scope = "openai"
+	// ScopeChat 允许通过 /api/fkteams/chat 和流式任务接口对话。
+	ScopeChat Scope = "chat"
+	// ScopeSessionsRead 允许只读访问会话列表和历史。
+	ScopeSessionsRead Scope = "sessions:read"
+	// ScopeSchedules 允许管理定时任务。
+	ScopeSchedules Scope = "schedules"
+)
+
+// Scopes 返回所有受支持的范围。
+func Scopes() []Scope {
+	return []Scope{ScopeOpenAI, ScopeChat, ScopeSessionsRead, ScopeSchedules}
+}
+
+// Valid 判断范围是否受支持。
+func (s Scope) Valid() bool {
+	return slices.Contains(Scopes(), s)
+}
+
+// Key 是持久化的 API 密钥，只保存明文的 SHA-256 哈希。
+type Key struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Hint 是明文开头的几个字符，用于在列表中辨认密钥
+	Hint   string  `json:"hint"`
+	Hash   string  `json:"hash"`
+	Scopes []Scope `json:"scopes"`
+	// Models 允许代理的模型 ID，为空时不限制
+	Models []string `json:"models,omitempty"`
+	// Agents 允许调用的智能体或模式（team、deep、roundtable），为空时不限制
+	Agents []string `json:"agents,omitempty"`
+	// RateLimit 每分钟允许的请求数，0 表示不限制
+	RateLimit int `json:"rate_limit,omitempty"`
+	// Owner 是通过 /api/fkteams 调用时代表的账号
+	Owner      string     `json:"owner,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+}
+
+// HasScope 判断密钥是否包含范围。
+func (k Key) HasScope(scope Scope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// Expired 判断密钥在 now 时是否已过期。
+func (k Key) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// AllowsModel 判断密钥是否可以使用模型。
+func (k Key) AllowsModel(modelID string) bool {
+	return len(k.Models) == 0 || slices.Contains(k.Models, modelID)
+}
+
+// AllowsAgent 判断密钥是否可以调用智能体或模式。
+func (k Key) AllowsAgent(name string) bool {
+	return len(k.Agents) == 0 || slices.Contains(k.Agents, name)
+}
+
+// Store 是密钥存储的结构化快照。
+type Store struct {
+	Keys []Key `json:"keys"`
+}
+
+type keyContext struct{}
+
+// WithKey 将请求使用的 API 密钥注入 context。
+func WithKey(ctx context.Context, key Key) context.Context {
+	return context.WithValue(ctx, keyContext{}, key)
+}
+
+// FromContext 返回请求使用的 API 密钥，未使用受管密钥时返回 false。
+func FromContext(ctx context.Context) (Key, bool) {
+	if ctx == nil {
+		return Key{}, false
+	}
+	key, ok := ctx.Value(keyContext{}).(Key)
+	return key, ok
+}
@@ -0,0 +1,226 @@
+// Package audit 定义工具执行审计记录。记录只追加，每条记录包含上一条的哈希，
+// 形成哈希链：修改、删除或插入任意一条记录都会使之后的校验失败。
+// 哈希是以审计密钥计算的 HMAC，不持有密钥时无法在修改记录后重新计算哈希链。
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Status 是工具调用的结束状态。
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusError    Status = "error"
+	StatusRejected Status = "rejected"
+	StatusDenied   Status = "denied"
+)
+
+// Approval 是工具调用过程中的一次审批检查。
+type Approval struct {
+	// Store 是审批类别，如 command、file、policy
+	Store   string `json:"store"`
+	Outcome string `json:"outcome"`
+	// Source 是裁决来源：user、auto_approve 或 policy
+	Source string `json:"source"`
+	Scope  string `json:"scope,omitempty"`
+	Rule   string `json:"rule,omitempty"`
+}
+
+// Record 是一次工具调用的审计记录。Seq、PrevHash 和 Hash 由存储在追加时填写。
+type Record struct {
+	Seq       int64     `json:"seq"`
+	At        time.Time `json:"at"`
+	User      string    `json:"user,omitempty"`
+	Channel   string    `json:"channel,omitempty"`
+	SessionID string    `json:"session_id,omitempty"`
+	TaskID    string    `json:"task_id,omitempty"`
+	Agent     string    `json:"agent,omitempty"`
+	Tool      string    `json:"tool"`
+	CallID    string    `json:"call_id,omitempty"`
+	// Arguments 可能被截断，ArgumentsSHA256 始终是完整参数的哈希
+	Arguments          string     `json:"arguments,omitempty"`
+	ArgumentsSHA256    string     `json:"arguments_sha256"`
+	ArgumentsTruncated bool       `json:"arguments_truncated,omitempty"`
+	Approvals          []Approval `json:"approvals,omitempty"`
+	// Decision 和 DecisionSource 汇总 Approvals，没有审批检查时为空
+	Decision       string   `json:"decision,omitempty"`
+	DecisionSource string   `json:"decision_source,omitempty"`
+	Status         Status   `json:"status"`
+	ExitCode       *int     `json:"exit_code,omitempty"`
+	Error          string   `json:"error,omitempty"`
+	Paths          []string `json:"paths,omitempty"`
+	PrevHash       string   `json:"prev_hash"`
+	Hash           string   `json:"hash"`
+}
+
+// Summarize 根据审批检查填写 Decision 和 DecisionSource：
+// 任一检查被拒绝时取该检查，否则取最后一次批准。
+func (r *Record) Summarize() {
+	r.Decision, r.DecisionSource = "", ""
+	for _, approval := range r.Approvals {
+		if approval.Outcome != "approved" {
+			r.Decision, r.DecisionSource = approval.Outcome, approval.Source
+			return
+		}
+	}
+	if n := len(r.Approvals); n > 0 {
+		r.Decision, r.DecisionSource = r.Approvals[n-1].Outcome, r.Approvals[n-1].Source
+	}
+}
+
+// ComputeHash 计算记录的哈希：Hash 置空后 JSON 编码的 HMAC-SHA256，PrevHash 参与计算。
+func (r Record) ComputeHash(key []byte) string {
+	r.Hash = ""
+	data, err := json.Marshal(r)
+	if err != nil {
+		return ""
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Chain 将记录链接到 prev 之后并以 key 计算哈希，prev 为 nil 表示第一条记录。
+func (r Record) Chain(prev *Record, key []byte) Record {
+	r.Seq, r.PrevHash = 1, ""
+	if prev != nil {
+		r.Seq, r.PrevHash = prev.Seq+1, prev.Hash
+	}
+	r.Hash = r.ComputeHash(key)
+	return r
+}
+
+// Verification 是哈希链校验结果。
+type Verification struct {
+	Valid   bool  `json:"valid"`
+	Records int64 `json:"records"`
+	// Head 是最后一条有效记录的哈希。保存它可以在之后发现日志末尾被截断
+	Head string `json:"head,omitempty"`
+	// Line 和 Reason 描述第一处损坏，行号从 1 开始
+	Line   int    `json:"line,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Verifier 按写入顺序逐条校验记录。
+type Verifier struct {
+	key    []byte
+	prev   *Record
+	result Verification
+	failed bool
+}
+
+// NewVerifier 创建以 key 校验哈希链的校验器。
+func NewVerifier(key []byte) *Verifier {
+	return &Verifier{key: key}
+}
+
+// Add 校验第 line 行的记录，返回 false 表示哈希链已损坏，之后的记录不再校验。
+func (v *Verifier) Add(line int, record Record) bool {
+	if v.failed {
+		return false
+	}
+	wantSeq, wantPrev := int64(1), ""
+	if v.prev != nil {
+		wantSeq, wantPrev = v.prev.Seq+1, v.prev.Hash
+	}
+	switch {
+	case record.Seq != wantSeq:
+		return v.Fail(line, fmt.Sprintf("sequence %d, expected %d", record.Seq, wantSeq))
+	case record.PrevHash != wantPrev:
+		return v.Fail(line, "prev_hash does not match the previous record")
+	case !hmac.Equal([]byte(record.Hash), []byte(record.ComputeHash(v.key))):
+		return v.Fail(line, "hash does not match the record content")
+	}
+	v.prev = &record
+	v.result.Records++
+	v.result.Head = record.Hash
+	return true
+}
+
+// Fail 记录第 line 行的损坏原因。
+func (v *Verifier) Fail(line int, reason string) bool {
+	if !v.failed {
+		v.failed = true
+		v.result.Line = line
+		v.result.Reason = reason
+	}
+	return false
+}
+
+// Result 返回校验结果。
+func (v *Verifier) Result() Verification {
+	result := v.result
+	result.Valid = !v.failed
+	return result
+}
+
+// Filter 是审计记录的查询条件，空字段不参与过滤。
+type Filter struct {
+	From      time.Time
+	To        time.Time
+	User      string
+	SessionID string
+	Agent     string
+	Tool      string
+	Decision  string
+	// Source 匹配任一审批检查的来源
+	Source string
+	Status Status
+	// Path 匹配受影响路径等于该路径或位于其下
+	Path string
+}
+
+// Match 判断记录是否满足过滤条件。
+func (f Filter) Match(r Record) bool {
+	if !f.From.IsZero() && r.At.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !r.At.Before(f.To) {
+		return false
+	}
+	if f.Status != "" && f.Status != r.Status {
+		return false
+	}
+	if f.Source != "" && !r.hasSource(f.Source) {
+		return false
+	}
+	if f.Path != "" && !r.touches(f.Path) {
+		return false
+	}
+	return matchField(f.User, r.User) &&
+		matchField(f.SessionID, r.SessionID) &&
+		matchField(f.Agent, r.Agent) &&
+		matchField(f.Tool, r.Tool) &&
+		matchField(f.Decision, r.Decision)
+}
+
+func (r Record) hasSource(source string) bool {
+	for _, approval := range r.Approvals {
+		if approval.Source == source {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Record) touches(path string) bool {
+	path = strings.TrimSuffix(path, "/")
+	for _, p := range r.Paths {
+		if p == path || strings.HasPrefix(p, path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func matchField(want, got string) bool {
+	return want == "" || want == got
+}
@@ -104,6 +104,7 @@ type Event struct {
 	Approval         *ApprovalPayload   `json:"approval,omitempty"`
 	Usage            *UsagePayload      `json:"usage,omitempty"`
 	Notice           *NoticePayload     `json:"notice,omitempty"`
+	Sandbox          *SandboxPayload    `json:"sandbox,omitempty"`
 }
 
 type AskPayload struct {
@@ -116,15 +117,36 @@ type AskPayload struct {
 }
 
 type ApprovalPayload struct {
-	ID       string `json:"id,omitempty"`
-	Message  string `json:"message,omitempty"`
-	Decision string `json:"decision,omitempty"`
+	ID       string            `json:"id,omitempty"`
+	Message  string            `json:"message,omitempty"`
+	Decision string            `json:"decision,omitempty"`
+	Findings []ApprovalFinding `json:"findings,omitempty"`
+}
+
+// ApprovalFinding 是审批请求附带的单条风险发现，供界面逐条展示。
+type ApprovalFinding struct {
+	Level   string `json:"level"`
+	Program string `json:"program,omitempty"`
+	Target  string `json:"target,omitempty"`
+	// Scope 是 Target 相对于工作区的位置，如 workspace、outside、system、dynamic
+	Scope  string `json:"scope,omitempty"`
+	Reason string `json:"reason"`
+	Risk   string `json:"risk,omitempty"`
 }
 
 type UsagePayload struct {
-	PromptTokens     int `json:"prompt_tokens,omitempty"`
-	CompletionTokens int `json:"completion_tokens,omitempty"`
-	TotalTokens      int `json:"total_tokens,omitempty"`
+	Model            string `json:"model,omitempty"`
+	PromptTokens     int    `json:"prompt_tokens,omitempty"`
+	CompletionTokens int    `json:"completion_tokens,omitempty"`
+	TotalTokens      int    `json:"total_tokens,omitempty"`
+}
+
+// SandboxPayload 描述执行工具调用的沙箱，仅在配置了沙箱时出现在 tool_call_started 事件中。
+type SandboxPayload struct {
+	Profile  string `json:"profile"`
+	Backend  string `json:"backend"` // 实际生效的后端：linux 或 direct
+	Network  bool   `json:"network"`
+	Fallback string `json:"fallback,omitempty"` // 要求隔离但回退为直接执行的原因
 }
 
 type NoticePayload struct {
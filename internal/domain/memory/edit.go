@@ -0,0 +1,60 @@
+package memory
+
+import "strings"
+
+// SaveAction 主动保存记忆的结果。
+type SaveAction string
+
+const (
+	// SaveAdded 新增了一条记忆。
+	SaveAdded SaveAction = "added"
+	// SaveUpdated 合并到了同类型、同作用域的相近记忆。
+	SaveUpdated SaveAction = "updated"
+	// SaveSkipped 已有摘要完全相同的记忆，未做修改。
+	SaveSkipped SaveAction = "skipped"
+)
+
+// SearchFilter 主动检索记忆时的过滤条件，空字段不参与过滤。
+type SearchFilter struct {
+	// Types 命中任一类型即可。
+	Types []MemoryType
+	// Tags 命中任一标签即可，不区分大小写。
+	Tags []string
+}
+
+// Match 判断记忆条目是否命中过滤条件。
+func (f SearchFilter) Match(entry MemoryEntry) bool {
+	if len(f.Types) > 0 {
+		matched := false
+		for _, memType := range f.Types {
+			if entry.Type == memType {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(f.Tags) == 0 {
+		return true
+	}
+	for _, want := range f.Tags {
+		for _, tag := range entry.Tags {
+			if strings.EqualFold(strings.TrimSpace(want), tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// EntryPatch 修改记忆条目时要替换的字段，零值字段保持不变。
+type EntryPatch struct {
+	Type    MemoryType
+	Summary string
+	// Detail 为 nil 时不修改，指向空字符串时清空详情。
+	Detail *string
+	// Tags 为 nil 时不修改，空切片清空标签。
+	Tags []string
+}
@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScopeKind 记忆作用域类型。
+type ScopeKind string
+
+const (
+	// ScopeGlobal 在所有工作区、智能体和通道用户间共享。
+	ScopeGlobal ScopeKind = "global"
+	// ScopeWorkspace 只在同一项目（工作区）内注入。
+	ScopeWorkspace ScopeKind = "workspace"
+	// ScopeAgent 只在同一智能体或工作模式下注入。
+	ScopeAgent ScopeKind = "agent"
+	// ScopeUser 只对同一通道用户注入，Key 形如 "qq:123456"。
+	ScopeUser ScopeKind = "user"
+)
+
+// AllScopeKinds 所有合法作用域类型。
+var AllScopeKinds = map[ScopeKind]bool{
+	ScopeGlobal:    true,
+	ScopeWorkspace: true,
+	ScopeAgent:     true,
+	ScopeUser:      true,
+}
+
+const maxScopeKeyBytes = 256
+
+// Scope 记忆作用域，零值等同于全局作用域。
+type Scope struct {
+	Kind ScopeKind `json:"kind"`
+	Key  string    `json:"key,omitempty"`
+}
+
+// GlobalScope 返回全局作用域。
+func GlobalScope() Scope {
+	return Scope{Kind: ScopeGlobal}
+}
+
+// ParseScope 解析 "global" 或 "kind:key" 格式的作用域，key 本身可以包含冒号。
+func ParseScope(value string) (Scope, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == string(ScopeGlobal) {
+		return GlobalScope(), nil
+	}
+	kind, key, ok := strings.Cut(value, ":")
+	if !ok {
+		return Scope{}, fmt.Errorf("invalid memory scope %q, expected global or kind:key", value)
+	}
+	scope := Scope{Kind: ScopeKind(strings.TrimSpace(kind)), Key: strings.TrimSpace(key)}
+	if err := scope.Validate(); err != nil {
+		return Scope{}, err
+	}
+	return scope, nil
+}
+
+// Normalize 将零值作用域归一为全局作用域，并去除 key 首尾空白。
+func (s Scope) Normalize() Scope {
+	if s.IsGlobal() {
+		return GlobalScope()
+	}
+	s.Key = strings.TrimSpace(s.Key)
+	return s
+}
+
+// IsGlobal 判断是否为全局作用域。
+func (s Scope) IsGlobal() bool {
+	return s.Kind == "" || s.Kind == ScopeGlobal
+}
+
+// Validate 校验作用域；全局作用域不能带 key，其余作用域必须带 key。
+func (s Scope) Validate() error {
+	if !AllScopeKinds[s.Kind] && s.Kind != "" {
+		return fmt.Errorf("invalid memory scope kind %q", s.Kind)
+	}
+	if s.IsGlobal() {
+		if s.Key != "" {
+			return fmt.Errorf("global memory scope does not take a key")
+		}
+		return nil
+	}
+	if s.Key == "" {
+		return fmt.Errorf("memory scope %s requires a key", s.Kind)
+	}
+	if len(s.Key) > maxScopeKeyBytes || strings.ContainsAny(s.Key, "\r\n") {
+		return fmt.Errorf("invalid memory scope key %q", s.Key)
+	}
+	return nil
+}
+
+// String 返回 "global" 或 "kind:key" 格式的作用域。
+func (s Scope) String() string {
+	if s.IsGlobal() {
+		return string(ScopeGlobal)
+	}
+	return string(s.Kind) + ":" + s.Key
+}
+
+// ScopeContext 描述一次对话所处的作用域，为空的维度不参与检索和提取。
+type ScopeContext struct {
+	// Workspace 是当前项目名。
+	Workspace string
+	// Agent 是当前智能体或工作模式名。
+	Agent string
+	// User 是通道用户标识，形如 "qq:123456"。
+	User string
+}
+
+// ScopeFor 返回上下文中指定类型的作用域，对应维度为空时返回 false。
+func (c ScopeContext) ScopeFor(kind ScopeKind) (Scope, bool) {
+	var key string
+	switch kind {
+	case ScopeGlobal, "":
+		return GlobalScope(), true
+	case ScopeWorkspace:
+		key = c.Workspace
+	case ScopeAgent:
+		key = c.Agent
+	case ScopeUser:
+		key = c.User
+	default:
+		return Scope{}, false
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return Scope{}, false
+	}
+	return Scope{Kind: kind, Key: key}, true
+}
+
+// Matches 判断作用域下的记忆是否适用于当前上下文，全局记忆始终适用。
+func (c ScopeContext) Matches(scope Scope) bool {
+	if scope.IsGlobal() {
+		return true
+	}
+	current, ok := c.ScopeFor(scope.Kind)
+	return ok && current.Key == scope.Key
+}
+
+// ScopeFilter 是列出记忆时的作用域过滤条件，Key 为空表示匹配该类型下的所有作用域。
+type ScopeFilter struct {
+	Kind ScopeKind
+	Key  string
+}
+
+// ParseScopeFilter 解析 "kind" 或 "kind:key" 格式的过滤条件，空字符串匹配所有记忆。
+func ParseScopeFilter(value string) (ScopeFilter, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ScopeFilter{}, nil
+	}
+	kind, key, _ := strings.Cut(value, ":")
+	filter := ScopeFilter{Kind: ScopeKind(strings.TrimSpace(kind)), Key: strings.TrimSpace(key)}
+	if !AllScopeKinds[filter.Kind] {
+		return ScopeFilter{}, fmt.Errorf("invalid memory scope kind %q", filter.Kind)
+	}
+	if filter.Kind == ScopeGlobal && filter.Key != "" {
+		return ScopeFilter{}, fmt.Errorf("global memory scope does not take a key")
+	}
+	return filter, nil
+}
+
+// Match 判断作用域是否命中过滤条件。
+func (f ScopeFilter) Match(scope Scope) bool {
+	if f.Kind == "" {
+		return true
+	}
+	scope = scope.Normalize()
+	return scope.Kind == f.Kind && (f.Key == "" || scope.Key == f.Key)
+}
@@ -49,6 +49,7 @@ func TypeOrder() []TypeMeta {
 type MemoryEntry struct {
 	ID        string     `json:"id"`
 	Type      MemoryType `json:"type"`
+	Scope     Scope      `json:"scope"`
 	Summary   string     `json:"summary"`
 	Detail    string     `json:"detail"`
 	Tags      []string   `json:"tags"`
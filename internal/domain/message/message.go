@@ -52,6 +52,19 @@ type Message struct {
 	ToolName         string        `json:"tool_name,omitempty"`
 	ContentParts     []ContentPart `json:"content_parts,omitempty"`
 	Name             string        `json:"name,omitempty"`
+	ResponseMeta     *ResponseMeta `json:"response_meta,omitempty"`
+}
+
+// ResponseMeta 记录模型响应的元信息：实际应答的模型和 token 用量。
+type ResponseMeta struct {
+	Model string      `json:"model,omitempty"`
+	Usage *TokenUsage `json:"usage,omitempty"`
+}
+
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens,omitempty"`
+	CompletionTokens int `json:"completion_tokens,omitempty"`
+	TotalTokens      int `json:"total_tokens,omitempty"`
 }
 
 type TurnInput struct {
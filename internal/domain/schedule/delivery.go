@@ -0,0 +1,182 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	maxDeliveries          = 8
+	maxDeliveryRecipients  = 20
+	maxDeliveryTemplateLen = 8 << 10
+)
+
+// DeliveryType 表示执行结果的投递方式。
+type DeliveryType string
+
+const (
+	DeliveryChannel DeliveryType = "channel"
+	DeliveryWebhook DeliveryType = "webhook"
+	DeliveryEmail   DeliveryType = "email"
+)
+
+// DeliveryCondition 决定哪些执行结果需要投递。
+type DeliveryCondition string
+
+const (
+	DeliverAlways    DeliveryCondition = "always"
+	DeliverOnSuccess DeliveryCondition = "success"
+	DeliverOnFailure DeliveryCondition = "failure"
+)
+
+// Delivery 描述一个结果投递目标。
+type Delivery struct {
+	Type DeliveryType `json:"type"`
+	// On 为空时等同于 always。
+	On DeliveryCondition `json:"on,omitempty"`
+	// Channel 和 ChatID 用于 channel 投递，Channel 为 discord、qq 或 weixin。
+	Channel string `json:"channel,omitempty"`
+	ChatID  string `json:"chat_id,omitempty"`
+	// URL 用于 webhook 投递。
+	URL string `json:"url,omitempty"`
+	// To 是 email 投递的收件人。
+	To []string `json:"to,omitempty"`
+	// Subject 是邮件主题模板，Template 是正文模板，均为 text/template 语法，为空时使用默认模板。
+	Subject  string `json:"subject,omitempty"`
+	Template string `json:"template,omitempty"`
+}
+
+// Normalize 去除各字段首尾空白和空收件人。
+func (d Delivery) Normalize() Delivery {
+	d.Type = DeliveryType(strings.TrimSpace(string(d.Type)))
+	d.On = DeliveryCondition(strings.TrimSpace(string(d.On)))
+	d.Channel = strings.TrimSpace(d.Channel)
+	d.ChatID = strings.TrimSpace(d.ChatID)
+	d.URL = strings.TrimSpace(d.URL)
+	var to []string
+	for _, addr := range d.To {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	d.To = to
+	return d
+}
+
+// Validate 校验投递目标的格式；通道是否启用、SMTP 是否配置由投递方判断。
+func (d Delivery) Validate() error {
+	switch d.On {
+	case "", DeliverAlways, DeliverOnSuccess, DeliverOnFailure:
+	default:
+		return fmt.Errorf("invalid delivery condition %q", d.On)
+	}
+	switch d.Type {
+	case DeliveryChannel:
+		if d.Channel == "" || d.ChatID == "" {
+			return errors.New("channel delivery requires channel and chat_id")
+		}
+	case DeliveryWebhook:
+		u, err := url.Parse(d.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("webhook delivery requires an http or https url")
+		}
+	case DeliveryEmail:
+		if len(d.To) == 0 {
+			return errors.New("email delivery requires at least one recipient")
+		}
+		if len(d.To) > maxDeliveryRecipients {
+			return fmt.Errorf("email recipients exceed %d entries", maxDeliveryRecipients)
+		}
+		for _, addr := range d.To {
+			if _, err := mail.ParseAddress(addr); err != nil {
+				return fmt.Errorf("invalid email recipient %q", addr)
+			}
+		}
+	default:
+		return fmt.Errorf("invalid delivery type %q", d.Type)
+	}
+	for _, field := range [...]struct{ name, text string }{{"subject", d.Subject}, {"template", d.Template}} {
+		if len(field.text) > maxDeliveryTemplateLen {
+			return fmt.Errorf("%s exceeds %d bytes", field.name, maxDeliveryTemplateLen)
+		}
+		if _, err := template.New(field.name).Parse(field.text); err != nil {
+			return fmt.Errorf("invalid %s: %w", field.name, err)
+		}
+	}
+	return nil
+}
+
+// Matches 判断本次执行结果是否需要投递到该目标。
+func (d Delivery) Matches(success bool) bool {
+	switch d.On {
+	case DeliverOnSuccess:
+		return success
+	case DeliverOnFailure:
+		return !success
+	default:
+		return true
+	}
+}
+
+// Describe 返回用于展示和记录投递状态的目标描述，不含模板内容。
+func (d Delivery) Describe() string {
+	switch d.Type {
+	case DeliveryChannel:
+		return d.Channel + ":" + d.ChatID
+	case DeliveryWebhook:
+		if u, err := url.Parse(d.URL); err == nil {
+			u.User = nil
+			u.RawQuery = ""
+			u.Fragment = ""
+			return u.String()
+		}
+		return d.URL
+	case DeliveryEmail:
+		return strings.Join(d.To, ",")
+	default:
+		return string(d.Type)
+	}
+}
+
+// NormalizeDeliveries 规范化并校验任务的投递目标列表。
+func NormalizeDeliveries(deliveries []Delivery) ([]Delivery, error) {
+	if len(deliveries) > maxDeliveries {
+		return nil, fmt.Errorf("deliveries exceed %d entries", maxDeliveries)
+	}
+	var normalized []Delivery
+	for i, delivery := range deliveries {
+		delivery = delivery.Normalize()
+		if err := delivery.Validate(); err != nil {
+			return nil, fmt.Errorf("delivery %d: %w", i+1, err)
+		}
+		normalized = append(normalized, delivery)
+	}
+	return normalized, nil
+}
+
+// DeliveryStatus 表示一次投递的结果。
+type DeliveryStatus string
+
+const (
+	DeliverySent   DeliveryStatus = "sent"
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// DeliveryResult 记录一次执行结果向某个目标的投递状态。
+type DeliveryResult struct {
+	Type   DeliveryType   `json:"type"`
+	Target string         `json:"target"`
+	Status DeliveryStatus `json:"status"`
+	Error  string         `json:"error,omitempty"`
+	Time   time.Time      `json:"time"`
+}
+
+// DeliveryRecordName 返回历史结果文件对应的投递状态文件名，两者保存在同一目录。
+func DeliveryRecordName(historyFilename string) string {
+	return strings.TrimSuffix(historyFilename, ".md") + ".delivery.json"
+}
@@ -0,0 +1,163 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// MisfireGrace 是到期后仍视为准时执行的宽限时间，超过即视为错过执行。
+	MisfireGrace = time.Minute
+	// DefaultRetryBackoff 是首次重试前的默认等待时间，之后每次翻倍。
+	DefaultRetryBackoff = time.Minute
+	// DefaultMaxRetryBackoff 是重试等待时间的默认上限。
+	DefaultMaxRetryBackoff = time.Hour
+	// MaxJitter 是允许配置的随机延迟上限。
+	MaxJitter = time.Hour
+
+	maxRetryAttempts = 10
+	maxRetryBackoff  = 24 * time.Hour
+)
+
+// MisfirePolicy 决定错过的执行（如进程停止期间到期）如何处理。
+type MisfirePolicy string
+
+const (
+	// MisfireSkip 跳过错过的执行，等待下一次。
+	MisfireSkip MisfirePolicy = "skip"
+	// MisfireRunOnce 立即补执行一次，其余错过的执行被合并，为默认策略。
+	MisfireRunOnce MisfirePolicy = "run_once"
+	// MisfireRunAll 依次补执行每一次错过的执行。
+	MisfireRunAll MisfirePolicy = "run_all"
+)
+
+// ConcurrencyPolicy 决定重复任务上一次执行尚未结束时如何处理新的到期执行。
+type ConcurrencyPolicy string
+
+const (
+	// ConcurrencyForbid 跳过执行期间到期的执行，为默认策略。
+	ConcurrencyForbid ConcurrencyPolicy = "forbid"
+	// ConcurrencyAllow 允许多次执行并行。
+	ConcurrencyAllow ConcurrencyPolicy = "allow"
+	// ConcurrencyReplace 取消正在进行的执行，改为开始新的执行。
+	ConcurrencyReplace ConcurrencyPolicy = "replace"
+)
+
+// RetryPolicy 描述执行失败后的重试方式，零值表示不重试。
+type RetryPolicy struct {
+	// MaxAttempts 是失败后的最大重试次数。
+	MaxAttempts int `json:"max_attempts,omitempty"`
+	// Backoff 是首次重试前的等待时间，之后每次翻倍，默认 1m。
+	Backoff string `json:"backoff,omitempty"`
+	// MaxBackoff 是单次等待时间上限，默认 1h。
+	MaxBackoff string `json:"max_backoff,omitempty"`
+}
+
+// Delay 返回第 attempt 次重试（从 1 开始）前的等待时间。
+func (r RetryPolicy) Delay(attempt int) time.Duration {
+	backoff := parseDurationOr(r.Backoff, DefaultRetryBackoff)
+	limit := parseDurationOr(r.MaxBackoff, DefaultMaxRetryBackoff)
+	delay := backoff
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// Policy 描述任务的重试、错过执行、并发和随机延迟策略，零值保持默认行为。
+type Policy struct {
+	Retry       RetryPolicy       `json:"retry,omitzero"`
+	Misfire     MisfirePolicy     `json:"misfire,omitempty"`
+	Concurrency ConcurrencyPolicy `json:"concurrency,omitempty"`
+	// Jitter 是每次执行时间上附加的随机延迟上限，用于错开同一时刻到期的任务。
+	Jitter string `json:"jitter,omitempty"`
+}
+
+// Normalize 去除各字段首尾空白。
+func (p Policy) Normalize() Policy {
+	p.Retry.Backoff = strings.TrimSpace(p.Retry.Backoff)
+	p.Retry.MaxBackoff = strings.TrimSpace(p.Retry.MaxBackoff)
+	p.Misfire = MisfirePolicy(strings.TrimSpace(string(p.Misfire)))
+	p.Concurrency = ConcurrencyPolicy(strings.TrimSpace(string(p.Concurrency)))
+	p.Jitter = strings.TrimSpace(p.Jitter)
+	return p
+}
+
+// Validate 校验策略取值。
+func (p Policy) Validate() error {
+	if p.Retry.MaxAttempts < 0 || p.Retry.MaxAttempts > maxRetryAttempts {
+		return fmt.Errorf("retry.max_attempts must be between 0 and %d", maxRetryAttempts)
+	}
+	if err := validateDuration("retry.backoff", p.Retry.Backoff, maxRetryBackoff); err != nil {
+		return err
+	}
+	if err := validateDuration("retry.max_backoff", p.Retry.MaxBackoff, maxRetryBackoff); err != nil {
+		return err
+	}
+	switch p.Misfire {
+	case "", MisfireSkip, MisfireRunOnce, MisfireRunAll:
+	default:
+		return fmt.Errorf("invalid misfire policy %q", p.Misfire)
+	}
+	switch p.Concurrency {
+	case "", ConcurrencyForbid, ConcurrencyAllow, ConcurrencyReplace:
+	default:
+		return fmt.Errorf("invalid concurrency policy %q", p.Concurrency)
+	}
+	return validateDuration("jitter", p.Jitter, MaxJitter)
+}
+
+// MisfireOrDefault 返回错过执行策略，未设置时为 run_once。
+func (p Policy) MisfireOrDefault() MisfirePolicy {
+	if p.Misfire == "" {
+		return MisfireRunOnce
+	}
+	return p.Misfire
+}
+
+// ConcurrencyOrDefault 返回并发策略，未设置时为 forbid。
+func (p Policy) ConcurrencyOrDefault() ConcurrencyPolicy {
+	if p.Concurrency == "" {
+		return ConcurrencyForbid
+	}
+	return p.Concurrency
+}
+
+// JitterDuration 返回随机延迟上限，未设置时为 0。
+func (p Policy) JitterDuration() time.Duration {
+	return parseDurationOr(p.Jitter, 0)
+}
+
+// LoadTimeZone 加载 IANA 时区名，空串表示服务器本地时区。
+func LoadTimeZone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time_zone %q", name)
+	}
+	return loc, nil
+}
+
+func validateDuration(name, value string, limit time.Duration) error {
+	if value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if d <= 0 || d > limit {
+		return fmt.Errorf("%s must be positive and at most %s", name, limit)
+	}
+	return nil
+}
+
+func parseDurationOr(value string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d
+	}
+	return fallback
+}
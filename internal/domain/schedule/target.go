@@ -0,0 +1,86 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout 是未指定超时时单次执行的时长上限。
+	DefaultTimeout = 30 * time.Minute
+	// MaxTimeout 是允许配置的单次执行时长上限。
+	MaxTimeout = 24 * time.Hour
+
+	maxTargetTools         = 64
+	maxTargetMaxIterations = 1_000
+)
+
+// Target 描述任务的执行目标和运行限制，零值表示由后台任务官使用默认模型执行。
+type Target struct {
+	// Agent 和 Mode 二选一：Agent 为内置或自定义智能体名，Mode 为 team、deep、roundtable 或 workflow:<名称>。
+	Agent         string   `json:"agent,omitempty"`
+	Mode          string   `json:"mode,omitempty"`
+	Model         string   `json:"model,omitempty"`
+	WorkDir       string   `json:"work_dir,omitempty"`
+	Tools         []string `json:"tools,omitempty"`
+	MaxIterations int      `json:"max_iterations,omitempty"`
+	Timeout       string   `json:"timeout,omitempty"`
+}
+
+// Normalize 去除各字段首尾空白和重复的工具名。
+func (t Target) Normalize() Target {
+	t.Agent = strings.TrimSpace(t.Agent)
+	t.Mode = strings.TrimSpace(t.Mode)
+	t.Model = strings.TrimSpace(t.Model)
+	t.WorkDir = strings.TrimSpace(t.WorkDir)
+	t.Timeout = strings.TrimSpace(t.Timeout)
+	var tools []string
+	seen := make(map[string]bool, len(t.Tools))
+	for _, name := range t.Tools {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		tools = append(tools, name)
+	}
+	t.Tools = tools
+	return t
+}
+
+// Validate 校验执行目标的格式；智能体、模型是否存在由执行方判断。
+func (t Target) Validate() error {
+	if t.Agent != "" && t.Mode != "" {
+		return errors.New("agent and mode are mutually exclusive")
+	}
+	if t.WorkDir != "" && !filepath.IsAbs(t.WorkDir) {
+		return errors.New("work_dir must be an absolute path")
+	}
+	if len(t.Tools) > maxTargetTools {
+		return fmt.Errorf("tools exceeds %d entries", maxTargetTools)
+	}
+	if t.MaxIterations < 0 || t.MaxIterations > maxTargetMaxIterations {
+		return fmt.Errorf("max_iterations must be between 0 and %d", maxTargetMaxIterations)
+	}
+	if t.Timeout != "" {
+		timeout, err := time.ParseDuration(t.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+		if timeout <= 0 || timeout > MaxTimeout {
+			return fmt.Errorf("timeout must be positive and at most %s", MaxTimeout)
+		}
+	}
+	return nil
+}
+
+// ExecutionTimeout 返回单次执行的超时时间，未设置或无效时返回 DefaultTimeout。
+func (t Target) ExecutionTimeout() time.Duration {
+	if timeout, err := time.ParseDuration(t.Timeout); err == nil && timeout > 0 {
+		return timeout
+	}
+	return DefaultTimeout
+}
@@ -36,10 +36,26 @@ type Task struct {
 	Task      string     `json:"task"`
 	CronExpr  string     `json:"cron_expr,omitempty"`
 	OneTime   bool       `json:"one_time"`
-	NextRunAt time.Time  `json:"next_run_at"`
+	NextRunAt time.Time  `json:"next_run_at,omitzero"`
 	Status    Status     `json:"status"`
 	CreatedAt time.Time  `json:"created_at"`
 	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	Target    Target     `json:"target,omitzero"`
+	// Deliveries 是执行结束后接收结果的目标。
+	Deliveries []Delivery `json:"deliveries,omitempty"`
+	// TimeZone 是计算 cron 表达式使用的 IANA 时区，为空时使用服务器本地时区。
+	TimeZone string `json:"time_zone,omitempty"`
+	Policy   Policy `json:"policy,omitzero"`
+	// Attempt 是当前连续失败后已安排的重试次数，成功或放弃重试后归零。
+	Attempt int `json:"attempt,omitempty"`
+	// LastError 是最近一次失败或被跳过的原因，成功后清空。
+	LastError string `json:"last_error,omitempty"`
+	// Trigger 是事件触发条件，设置后任务不按时间调度，NextRunAt 为零值。
+	Trigger *Trigger `json:"trigger,omitempty"`
+	// Owner 是创建任务的账号，未启用认证时为空。
+	Owner string `json:"owner,omitempty"`
+	// Event 是触发本次执行的事件，只出现在执行快照中，不会持久化。
+	Event *TriggerEvent `json:"event,omitempty"`
 }
 
 // TaskList 是文件存储的结构化快照。
@@ -51,4 +67,6 @@ type TaskList struct {
 type HistoryEntry struct {
 	Filename string `json:"filename"`
 	Time     string `json:"time"`
+	// Deliveries 是该次结果的投递状态，未配置投递目标时为空。
+	Deliveries []DeliveryResult `json:"deliveries,omitempty"`
 }
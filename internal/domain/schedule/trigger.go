@@ -0,0 +1,234 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+const (
+	// DefaultTriggerDebounce 是文件触发器默认的防抖时间。
+	DefaultTriggerDebounce = 2 * time.Second
+	// MaxTriggerPayloadBytes 是注入任务提示词的触发内容上限，超出部分截断。
+	MaxTriggerPayloadBytes = 64 << 10
+	// MinTriggerSecretBytes 是 webhook 触发器密钥的最小长度。
+	MinTriggerSecretBytes = 16
+
+	maxTriggerDebounce     = time.Hour
+	maxTriggerGlobBytes    = 512
+	maxTriggerKeywords     = 20
+	maxTriggerKeywordBytes = 200
+	maxTriggerSecretBytes  = 256
+	truncatedPayloadMarker = "\n...[truncated]"
+)
+
+// TriggerType 表示事件触发任务的触发来源。
+type TriggerType string
+
+const (
+	// TriggerFile 在工作区内匹配的文件变化后触发。
+	TriggerFile TriggerType = "file"
+	// TriggerWebhook 在收到携带密钥的 HTTP 请求后触发。
+	TriggerWebhook TriggerType = "webhook"
+	// TriggerKeyword 在消息通道收到包含关键词的消息后触发。
+	TriggerKeyword TriggerType = "keyword"
+)
+
+// Trigger 描述事件触发任务的触发条件。设置触发器的任务不按时间调度，
+// 每次触发开始一次执行，执行结束后回到 pending 等待下一次触发。
+type Trigger struct {
+	Type TriggerType `json:"type"`
+	// Glob 是 file 触发器监视的文件模式，支持 **，相对任务工作目录，未设置工作目录时相对默认工作区。
+	Glob string `json:"glob,omitempty"`
+	// Debounce 是 file 触发器最后一次文件变化后的等待时间，期间的变化合并为一次触发，默认 2s。
+	Debounce string `json:"debounce,omitempty"`
+	// Secret 是 webhook 触发器的调用密钥，创建时为空则自动生成。
+	Secret string `json:"secret,omitempty"`
+	// Channel 和 ChatID 限定 keyword 触发器监听的通道和会话，为空表示不限。
+	Channel string `json:"channel,omitempty"`
+	ChatID  string `json:"chat_id,omitempty"`
+	// Keywords 是 keyword 触发器的关键词，消息包含任一关键词（不区分大小写）即触发。
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// Normalize 去除首尾空白、空关键词和重复关键词，并清除与触发类型无关的字段。
+func (t Trigger) Normalize() Trigger {
+	normalized := Trigger{Type: TriggerType(strings.TrimSpace(string(t.Type)))}
+	switch normalized.Type {
+	case TriggerFile:
+		normalized.Glob = strings.TrimSpace(t.Glob)
+		normalized.Debounce = strings.TrimSpace(t.Debounce)
+	case TriggerWebhook:
+		normalized.Secret = strings.TrimSpace(t.Secret)
+	case TriggerKeyword:
+		normalized.Channel = strings.TrimSpace(t.Channel)
+		normalized.ChatID = strings.TrimSpace(t.ChatID)
+		seen := make(map[string]bool, len(t.Keywords))
+		for _, keyword := range t.Keywords {
+			keyword = strings.TrimSpace(keyword)
+			key := strings.ToLower(keyword)
+			if keyword == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			normalized.Keywords = append(normalized.Keywords, keyword)
+		}
+	default:
+		return t
+	}
+	return normalized
+}
+
+// Validate 校验触发条件；webhook 密钥允许为空，由调度器在创建时生成。
+func (t Trigger) Validate() error {
+	switch t.Type {
+	case TriggerFile:
+		if t.Glob == "" {
+			return errors.New("file trigger requires glob")
+		}
+		if len(t.Glob) > maxTriggerGlobBytes {
+			return fmt.Errorf("glob exceeds %d bytes", maxTriggerGlobBytes)
+		}
+		if strings.Contains(t.Glob, `\`) || path.IsAbs(t.Glob) || !doublestar.ValidatePattern(t.Glob) {
+			return fmt.Errorf("invalid glob %q", t.Glob)
+		}
+		for _, segment := range strings.Split(t.Glob, "/") {
+			if segment == ".." {
+				return fmt.Errorf("glob %q must stay inside the workspace", t.Glob)
+			}
+		}
+		return validateDuration("debounce", t.Debounce, maxTriggerDebounce)
+	case TriggerWebhook:
+		if t.Secret != "" && (len(t.Secret) < MinTriggerSecretBytes || len(t.Secret) > maxTriggerSecretBytes) {
+			return fmt.Errorf("webhook secret must be %d to %d bytes", MinTriggerSecretBytes, maxTriggerSecretBytes)
+		}
+		return nil
+	case TriggerKeyword:
+		if len(t.Keywords) == 0 {
+			return errors.New("keyword trigger requires at least one keyword")
+		}
+		if len(t.Keywords) > maxTriggerKeywords {
+			return fmt.Errorf("keywords exceed %d entries", maxTriggerKeywords)
+		}
+		for _, keyword := range t.Keywords {
+			if len(keyword) > maxTriggerKeywordBytes {
+				return fmt.Errorf("keyword exceeds %d bytes", maxTriggerKeywordBytes)
+			}
+		}
+		if t.ChatID != "" && t.Channel == "" {
+			return errors.New("keyword trigger chat_id requires channel")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid trigger type %q", t.Type)
+	}
+}
+
+// DebounceDuration 返回文件触发器的防抖时间。
+func (t Trigger) DebounceDuration() time.Duration {
+	return parseDurationOr(t.Debounce, DefaultTriggerDebounce)
+}
+
+// MatchesMessage 判断通道消息是否命中 keyword 触发器。
+func (t Trigger) MatchesMessage(channel, chatID, text string) bool {
+	if t.Type != TriggerKeyword {
+		return false
+	}
+	if (t.Channel != "" && t.Channel != channel) || (t.ChatID != "" && t.ChatID != chatID) {
+		return false
+	}
+	lowered := strings.ToLower(text)
+	for _, keyword := range t.Keywords {
+		if strings.Contains(lowered, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Describe 返回用于展示的触发条件摘要，不含密钥。
+func (t Trigger) Describe() string {
+	switch t.Type {
+	case TriggerFile:
+		if t.Debounce != "" {
+			return t.Glob + " (debounce " + t.Debounce + ")"
+		}
+		return t.Glob
+	case TriggerKeyword:
+		scope := "any channel"
+		if t.Channel != "" {
+			scope = t.Channel
+			if t.ChatID != "" {
+				scope += ":" + t.ChatID
+			}
+		}
+		return strings.Join(t.Keywords, ",") + " @ " + scope
+	default:
+		return ""
+	}
+}
+
+// TriggerEvent 是触发一次执行的事件，Payload 会注入本次执行的任务提示词。
+type TriggerEvent struct {
+	Type TriggerType `json:"type"`
+	// Source 描述事件来源，如消息发送者或请求地址。
+	Source  string    `json:"source,omitempty"`
+	Payload string    `json:"payload,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// NewTriggerEvent 创建触发事件，非法 UTF-8 被替换，超长内容被截断。
+func NewTriggerEvent(triggerType TriggerType, source, payload string, at time.Time) TriggerEvent {
+	payload = strings.ToValidUTF8(payload, "�")
+	if len(payload) > MaxTriggerPayloadBytes {
+		cut := MaxTriggerPayloadBytes - len(truncatedPayloadMarker)
+		for cut > 0 && !utf8.RuneStart(payload[cut]) {
+			cut--
+		}
+		payload = payload[:cut] + truncatedPayloadMarker
+	}
+	return TriggerEvent{Type: triggerType, Source: source, Payload: payload, Time: at}
+}
+
+// Triggered 判断任务是否由事件触发，而非按时间调度。
+func (t Task) Triggered() bool {
+	return t.Trigger != nil
+}
+
+// Prompt 返回本次执行的任务提示词，由事件触发时附加触发内容。
+func (t Task) Prompt() string {
+	if t.Event == nil {
+		return t.Task
+	}
+	var b strings.Builder
+	b.WriteString(t.Task)
+	b.WriteString("\n\n---\n")
+	fmt.Fprintf(&b, "本次执行由%s触发", triggerLabel(t.Event.Type))
+	if t.Event.Source != "" {
+		fmt.Fprintf(&b, "（%s）", t.Event.Source)
+	}
+	fmt.Fprintf(&b, "，触发时间 %s。", t.Event.Time.Format("2006-01-02 15:04:05"))
+	if t.Event.Payload != "" {
+		b.WriteString("触发内容如下：\n\n")
+		b.WriteString(t.Event.Payload)
+	}
+	return b.String()
+}
+
+func triggerLabel(triggerType TriggerType) string {
+	switch triggerType {
+	case TriggerFile:
+		return "文件变化"
+	case TriggerWebhook:
+		return " Webhook 请求"
+	case TriggerKeyword:
+		return "通道消息关键词"
+	default:
+		return "事件"
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 	"unicode"
 
+	"fkteams/internal/domain/message"
+
 	"github.com/google/uuid"
 )
 
@@ -31,7 +33,11 @@ type Metadata struct {
 	Status       Status    `json:"status"`
 	Mode         string    `json:"mode,omitempty"`
 	CurrentAgent string    `json:"current_agent,omitempty"`
+	Project      string    `json:"project,omitempty"` // 会话绑定的项目，为空表示尚未绑定
+	Owner        string    `json:"owner,omitempty"`   // 创建会话的账号，未启用认证时为空
 	Favorite     bool      `json:"favorite,omitempty"`
+	ForkedFrom   string    `json:"forked_from,omitempty"`     // 分叉来源会话
+	ForkedAt     string    `json:"forked_at_event,omitempty"` // 分叉点的记录事件 ID
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -43,6 +49,14 @@ type Record struct {
 	ModTime  time.Time
 }
 
+// RewindResult 描述一次回退：被丢弃的记录数和回退目标用户消息，供编辑后重新发送。
+type RewindResult struct {
+	Metadata     Metadata              `json:"metadata"`
+	Removed      int                   `json:"removed"`
+	Message      string                `json:"message"`
+	ContentParts []message.ContentPart `json:"content_parts,omitempty"`
+}
+
 type contextKey struct{}
 
 // NewID 生成新的会话 ID。
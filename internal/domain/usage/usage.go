@@ -0,0 +1,161 @@
+// Package usage 定义模型用量记录及其汇总规则。
+package usage
+
+import (
+	"sort"
+	"time"
+)
+
+// DayLayout 是按天汇总和存储用量时使用的日期格式。
+const DayLayout = "2006-01-02"
+
+// Record 表示一次模型调用的用量，Cost 按记录时的价格表计算。
+type Record struct {
+	At               time.Time `json:"at"`
+	SessionID        string    `json:"session_id,omitempty"`
+	TaskID           string    `json:"task_id,omitempty"`
+	Channel          string    `json:"channel,omitempty"`
+	Agent            string    `json:"agent,omitempty"`
+	Model            string    `json:"model,omitempty"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	Cost             float64   `json:"cost"`
+}
+
+// Day 返回记录所在的本地日期。
+func (r Record) Day() string {
+	return r.At.Local().Format(DayLayout)
+}
+
+// Totals 是一组用量记录的累计值。
+type Totals struct {
+	Requests         int     `json:"requests"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// Add 将一条记录计入累计值。
+func (t *Totals) Add(r Record) {
+	t.Requests++
+	t.PromptTokens += int64(r.PromptTokens)
+	t.CompletionTokens += int64(r.CompletionTokens)
+	t.TotalTokens += int64(r.TotalTokens)
+	t.Cost += r.Cost
+}
+
+// Dimension 表示汇总维度。
+type Dimension string
+
+const (
+	BySession Dimension = "session"
+	ByTask    Dimension = "task"
+	ByAgent   Dimension = "agent"
+	ByModel   Dimension = "model"
+	ByDay     Dimension = "day"
+	ByChannel Dimension = "channel"
+)
+
+// ValidDimension 判断维度是否受支持。
+func ValidDimension(d Dimension) bool {
+	switch d {
+	case BySession, ByTask, ByAgent, ByModel, ByDay, ByChannel:
+		return true
+	default:
+		return false
+	}
+}
+
+// Key 返回记录在该维度下的分组键。
+func (d Dimension) Key(r Record) string {
+	switch d {
+	case BySession:
+		return r.SessionID
+	case ByTask:
+		return r.TaskID
+	case ByAgent:
+		return r.Agent
+	case ByModel:
+		return r.Model
+	case ByDay:
+		return r.Day()
+	case ByChannel:
+		return r.Channel
+	default:
+		return ""
+	}
+}
+
+// Filter 描述用量查询条件，零值字段不参与过滤；时间范围为左闭右开。
+type Filter struct {
+	From      time.Time
+	To        time.Time
+	SessionID string
+	TaskID    string
+	Channel   string
+	Agent     string
+	Model     string
+}
+
+// Match 判断记录是否满足过滤条件。
+func (f Filter) Match(r Record) bool {
+	if !f.From.IsZero() && r.At.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !r.At.Before(f.To) {
+		return false
+	}
+	return matchField(f.SessionID, r.SessionID) &&
+		matchField(f.TaskID, r.TaskID) &&
+		matchField(f.Channel, r.Channel) &&
+		matchField(f.Agent, r.Agent) &&
+		matchField(f.Model, r.Model)
+}
+
+func matchField(want, got string) bool {
+	return want == "" || want == got
+}
+
+// Group 是某个维度下一个分组的累计值。
+type Group struct {
+	Key string `json:"key"`
+	Totals
+}
+
+// Summary 是一次汇总查询的结果。
+type Summary struct {
+	By     Dimension `json:"by"`
+	Totals Totals    `json:"totals"`
+	Groups []Group   `json:"groups"`
+}
+
+// Summarize 按维度汇总满足过滤条件的记录。
+// 按天汇总时分组按日期升序，其余维度按 token 总量降序。
+func Summarize(records []Record, filter Filter, by Dimension) Summary {
+	summary := Summary{By: by, Groups: []Group{}}
+	index := make(map[string]int)
+	for _, r := range records {
+		if !filter.Match(r) {
+			continue
+		}
+		summary.Totals.Add(r)
+		key := by.Key(r)
+		i, ok := index[key]
+		if !ok {
+			i = len(summary.Groups)
+			index[key] = i
+			summary.Groups = append(summary.Groups, Group{Key: key})
+		}
+		summary.Groups[i].Add(r)
+	}
+	sort.SliceStable(summary.Groups, func(i, j int) bool {
+		left, right := summary.Groups[i], summary.Groups[j]
+		if by != ByDay && left.TotalTokens != right.TotalTokens {
+			return left.TotalTokens > right.TotalTokens
+		}
+		return left.Key < right.Key
+	})
+	return summary
+}
@@ -37,6 +37,16 @@ const (
 	ErrorFail   ErrorPolicy = "fail"
 )
 
+// AbortError 表示 hook 要求终止整个回合，而不仅是拒绝当前请求；
+// 运行器据此停止执行并发出带原因的 turn_failed 事件。
+type AbortError struct {
+	Reason string
+}
+
+func (e *AbortError) Error() string {
+	return "turn aborted: " + e.Reason
+}
+
 type Invocation struct {
 	HookPoint HookPoint
 	SessionID string
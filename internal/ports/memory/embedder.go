@@ -0,0 +1,11 @@
+package memory
+
+import "context"
+
+// Embedder 把文本转换为向量，供长期记忆的语义检索使用。
+type Embedder interface {
+	// Model 返回向量模型标识，标识变化时已有的向量索引需要重建。
+	Model() string
+	// Embed 按输入顺序返回每段文本的向量。
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
@@ -141,6 +141,7 @@ type DeepAgentConfig struct {
 	Tools              []Tool
 	SubAgents          []Agent
 	Middlewares        []AgentMiddleware
+	ToolMiddlewares    []ToolMiddleware
 	ModelRetryConfig   *ModelRetryConfig
 	MaxIterations      int
 	Planning           DeepPlanningConfig
@@ -299,6 +300,8 @@ type RunOptions struct {
 	CheckpointID     string
 	Sink             EventSink
 	InterruptHandler InterruptHandler
+	// Resume 非空时从 CheckpointID 对应的 checkpoint 恢复中断，忽略本次输入。
+	Resume InterruptDecisions
 }
 
 // WithDefaults 填充 RunOptions 的安全默认值。
@@ -13,6 +13,8 @@ type ToolInfo struct {
 	Desc   string
 	Policy ToolPolicyMetadata
 	Extra  map[string]any
+	// Parameters 是绑定到模型时可见的参数 JSON Schema，仅用于录制等诊断场景。
+	Parameters json.RawMessage
 }
 
 type ToolPolicyMetadata struct {
@@ -32,6 +34,7 @@ type ToolInvocation struct {
 }
 
 type toolRuntimeMetadataKey struct{}
+type toolAgentNameKey struct{}
 
 type ToolRuntimeMetadata struct {
 	CallID string
@@ -53,6 +56,23 @@ func ToolRuntimeMetadataFromContext(ctx context.Context) (ToolRuntimeMetadata, b
 	return metadata, ok
 }
 
+// WithToolAgentName 记录发起工具调用的智能体名，供权限策略等按智能体区分。
+func WithToolAgentName(ctx context.Context, agentName string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, toolAgentNameKey{}, agentName)
+}
+
+// ToolAgentNameFromContext 返回发起当前工具调用的智能体名。
+func ToolAgentNameFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	agentName, _ := ctx.Value(toolAgentNameKey{}).(string)
+	return agentName
+}
+
 type ToolResult struct {
 	Content string
 }
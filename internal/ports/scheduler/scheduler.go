@@ -9,14 +9,22 @@ import (
 
 // AddTaskRequest 描述创建调度任务所需的最小输入。
 type AddTaskRequest struct {
-	Task      string
-	CronExpr  string
-	ExecuteAt string
+	Task       string
+	CronExpr   string
+	ExecuteAt  string
+	Target     domainschedule.Target
+	Deliveries []domainschedule.Delivery
+	TimeZone   string
+	Policy     domainschedule.Policy
+	// Trigger 设置后创建事件触发任务，与 CronExpr、ExecuteAt 互斥。
+	Trigger *domainschedule.Trigger
+	// Owner 是新建任务的归属账号，更新任务时忽略。
+	Owner string
 }
 
-// TaskExecutor 执行已经到期的调度任务。
+// TaskExecutor 执行已经到期的调度任务，任务快照包含执行目标。
 type TaskExecutor interface {
-	Execute(ctx context.Context, taskID string, task string) (string, error)
+	Execute(ctx context.Context, task domainschedule.Task) (string, error)
 }
 
 // TaskService 是应用用例管理任务所需的最小能力。
@@ -29,6 +37,10 @@ type TaskService interface {
 	ReadTaskResult(ctx context.Context, taskID string) (string, error)
 	ListHistoryEntries(ctx context.Context, taskID string) ([]domainschedule.HistoryEntry, error)
 	ReadHistoryFile(ctx context.Context, taskID string, filename string) (string, error)
+	// FireTrigger 以触发事件开始一次事件触发任务的执行，不等待执行结束。
+	FireTrigger(ctx context.Context, taskID string, event domainschedule.TriggerEvent) error
+	// AssignOwner 将没有归属的任务归属到 owner，返回修改的任务数。
+	AssignOwner(ctx context.Context, owner string) (int, error)
 }
 
 // SchedulerLifecycle 管理调度器后台执行生命周期。
@@ -0,0 +1,14 @@
+package storage
+
+import (
+	"context"
+
+	domainaccount "fkteams/internal/domain/account"
+)
+
+// AccountStore 持久化 Web/API 服务的账号目录。
+type AccountStore interface {
+	// LoadAccounts 读取账号目录，存储不存在时返回空目录。
+	LoadAccounts(ctx context.Context) (domainaccount.Directory, error)
+	SaveAccounts(ctx context.Context, directory domainaccount.Directory) error
+}
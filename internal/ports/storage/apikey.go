@@ -0,0 +1,14 @@
+package storage
+
+import (
+	"context"
+
+	domainapikey "fkteams/internal/domain/apikey"
+)
+
+// APIKeyStore 持久化受管 API 密钥。
+type APIKeyStore interface {
+	// LoadAPIKeys 读取密钥存储，存储不存在时返回空集合。
+	LoadAPIKeys(ctx context.Context) (domainapikey.Store, error)
+	SaveAPIKeys(ctx context.Context, store domainapikey.Store) error
+}
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"context"
+
+	domainaudit "fkteams/internal/domain/audit"
+)
+
+// AuditKeyFunc 返回计算审计哈希链的 HMAC 密钥，密钥不存在时生成并保存。
+type AuditKeyFunc func() ([]byte, error)
+
+// AuditStore 持久化只追加的审计哈希链。
+type AuditStore interface {
+	// AppendAudit 将记录链接到最后一条记录之后并写入，返回填写了 Seq 和哈希的记录。
+	// 多个进程同时追加时，实现需保证每条记录都链接到真正的上一条记录。
+	AppendAudit(ctx context.Context, record domainaudit.Record) (domainaudit.Record, error)
+	// ScanAudit 按写入顺序逐行回调，line 从 1 开始；无法解析的行以 err 传入。
+	// fn 返回错误时停止扫描并返回该错误。
+	ScanAudit(ctx context.Context, fn func(line int, record domainaudit.Record, err error) error) error
+}
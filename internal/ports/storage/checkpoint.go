@@ -7,3 +7,9 @@ type CheckpointStore interface {
 	Set(ctx context.Context, key string, value []byte) error
 	Get(ctx context.Context, key string) ([]byte, bool, error)
 }
+
+// CheckpointCleaner 由可以按 checkpoint ID 清理全部命名空间数据的存储实现，
+// 用于不保留会话的一次性回合。
+type CheckpointCleaner interface {
+	DeleteCheckpoints(ctx context.Context, checkpointID string) error
+}
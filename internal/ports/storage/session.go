@@ -13,4 +13,8 @@ type SessionRepository interface {
 	LoadSession(ctx context.Context, sessionID string) (domainsession.Metadata, error)
 	UpdateSession(ctx context.Context, sessionID string, update func(*domainsession.Metadata) error) (domainsession.Metadata, error)
 	DeleteSession(ctx context.Context, sessionID string) error
+	// ForkSession 以 metadata 创建新会话，复制来源会话截至 eventID（含）的记录。
+	ForkSession(ctx context.Context, sourceID, eventID string, metadata domainsession.Metadata) (domainsession.Metadata, error)
+	// RewindSession 将会话回退到 eventID 指向的用户消息之前，丢弃该消息及其后的记录。
+	RewindSession(ctx context.Context, sessionID, eventID string) (domainsession.RewindResult, error)
 }
@@ -0,0 +1,15 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	domainusage "fkteams/internal/domain/usage"
+)
+
+// UsageStore 持久化模型用量记录。
+type UsageStore interface {
+	AppendUsage(ctx context.Context, record domainusage.Record) error
+	// ListUsage 返回 [from, to) 范围内的记录，零值时间表示不限。
+	ListUsage(ctx context.Context, from, to time.Time) ([]domainusage.Record, error)
+}
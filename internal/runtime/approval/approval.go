@@ -2,19 +2,27 @@ package approval
 
 import (
 	"context"
+	"encoding/gob"
 	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 
+	domainevent "fkteams/internal/domain/event"
 	runtimeport "fkteams/internal/ports/runtime"
 )
 
+func init() {
+	gob.Register(&Request{})
+}
+
 const (
 	StoreCommand  = "command"
 	StoreFile     = "file"
 	StoreGit      = "git"
 	StoreDispatch = "dispatch"
+	StoreMemory   = "memory"
 )
 
 const (
@@ -31,14 +39,26 @@ type OperationDetail struct {
 	Value string
 }
 
+// Finding 是审批请求附带的结构化风险发现。
+type Finding = domainevent.ApprovalFinding
+
 type Operation struct {
 	StoreName string
 	Key       string
 	Title     string
 	Target    string
 	Details   []OperationDetail
+	Findings  []Finding
 }
 
+// Request 是带风险发现的审批中断信息；String 返回文本提示，供只展示文本的渠道使用。
+type Request struct {
+	Message  string
+	Findings []Finding
+}
+
+func (r *Request) String() string { return r.Message }
+
 type MatchFunc func(key string, approved map[string]bool) bool
 
 func DirMatchFunc(key string, approved map[string]bool) bool {
@@ -60,6 +80,8 @@ type Store struct {
 	items   map[string]bool
 	all     bool
 	matcher MatchFunc
+	// allSource 记录全部放行的来源：配置的自动批准或用户选择的全部批准
+	allSource Source
 }
 
 func newStore(matcher MatchFunc) *Store {
@@ -77,10 +99,23 @@ func (s *Store) IsApproved(key string) bool {
 }
 
 func (s *Store) approve(key string) { s.items[key] = true }
-func (s *Store) setApproveAll()     { s.all = true }
+
+func (s *Store) setApproveAll(source Source) {
+	s.all = true
+	s.allSource = source
+}
+
+// approvedBy 返回已放行的 key 的审批来源和范围，逐项批准只能由用户做出。
+func (s *Store) approvedBy() (Source, Scope) {
+	if s.all {
+		return s.allSource, ScopeAll
+	}
+	return SourceUser, ScopeItem
+}
 
 type Registry struct {
 	stores map[string]*Store
+	policy atomic.Pointer[Policy]
 }
 
 type StoreConfig struct {
@@ -94,6 +129,8 @@ func DefaultStoreConfigs() []StoreConfig {
 		{Name: StoreFile, Matcher: DirMatchFunc},
 		{Name: StoreGit, Matcher: DirMatchFunc},
 		{Name: StoreDispatch},
+		{Name: StoreMemory},
+		{Name: StorePolicy},
 	}
 }
 
@@ -116,7 +153,7 @@ func NewDefaultRegistry() *Registry {
 func NewAutoApproveRegistry() *Registry {
 	r := NewDefaultRegistry()
 	for _, s := range r.stores {
-		s.setApproveAll()
+		s.setApproveAll(SourceAutoApprove)
 	}
 	return r
 }
@@ -133,12 +170,12 @@ func NewSelectiveRegistry(autoApprove []string, configs ...StoreConfig) *Registr
 	for _, name := range autoApprove {
 		if name == "all" {
 			for _, s := range r.stores {
-				s.setApproveAll()
+				s.setApproveAll(SourceAutoApprove)
 			}
 			return r
 		}
 		if s, ok := r.stores[name]; ok {
-			s.setApproveAll()
+			s.setApproveAll(SourceAutoApprove)
 		}
 	}
 	return r
@@ -150,6 +187,15 @@ func WithRegistry(ctx context.Context, reg *Registry) context.Context {
 	return context.WithValue(ctx, registryCtxKey{}, reg)
 }
 
+// RegistryFromContext 返回上下文中的审批 Registry。
+func RegistryFromContext(ctx context.Context) *Registry {
+	if ctx == nil {
+		return nil
+	}
+	reg, _ := ctx.Value(registryCtxKey{}).(*Registry)
+	return reg
+}
+
 func RegistryContext(reg *Registry) func(context.Context) context.Context {
 	return func(ctx context.Context) context.Context {
 		return WithRegistry(ctx, reg)
@@ -164,9 +210,20 @@ func getStore(ctx context.Context, name string) *Store {
 }
 
 func Require(ctx context.Context, storeName, key, info string) error {
+	return require(ctx, storeName, key, info, "")
+}
+
+// require 发起审批并把结果记入上下文中的 Journal；info 是中断信息，rule 是要求审批的策略规则。
+func require(ctx context.Context, storeName, key string, info any, rule string) error {
+	if isApprovedCall(ctx) {
+		return nil
+	}
 	store := getStore(ctx, storeName)
+	journal := journalFromContext(ctx)
 
 	if store != nil && store.IsApproved(key) {
+		source, scope := store.approvedBy()
+		journal.record(Decision{Store: storeName, Outcome: OutcomeApproved, Source: source, Scope: scope, Rule: rule})
 		return nil
 	}
 
@@ -174,33 +231,47 @@ func Require(ctx context.Context, storeName, key, info string) error {
 	if wasInterrupted {
 		isTarget, hasData, decision := runtimeport.GetResumeContext[int](ctx)
 		if !isTarget {
+			journal.markInterrupted()
 			return runtimeport.RequestInterrupt(ctx, nil)
 		}
 		if hasData {
 			switch decision {
 			case ApproveOnce:
+				journal.record(Decision{Store: storeName, Outcome: OutcomeApproved, Source: SourceUser, Scope: ScopeOnce, Rule: rule})
 				return nil
 			case ApproveItem:
 				if store != nil {
 					store.approve(key)
 				}
+				journal.record(Decision{Store: storeName, Outcome: OutcomeApproved, Source: SourceUser, Scope: ScopeItem, Rule: rule})
 				return nil
 			case ApproveAll:
 				if store != nil {
-					store.setApproveAll()
+					store.setApproveAll(SourceUser)
 				}
+				journal.record(Decision{Store: storeName, Outcome: OutcomeApproved, Source: SourceUser, Scope: ScopeAll, Rule: rule})
 				return nil
 			}
 		}
+		journal.record(Decision{Store: storeName, Outcome: OutcomeRejected, Source: SourceUser, Rule: rule})
 		return ErrRejected
 	}
 
+	journal.markInterrupted()
 	return runtimeport.RequestInterrupt(ctx, info)
 }
 
 // RequireOperation 使用统一格式发起一次人工审批。
 func RequireOperation(ctx context.Context, op Operation) error {
-	return Require(ctx, op.StoreName, op.Key, op.Info())
+	return require(ctx, op.StoreName, op.Key, op.request(), "")
+}
+
+// request 返回操作的中断信息，有风险发现时携带结构化的 Request。
+func (op Operation) request() any {
+	if len(op.Findings) == 0 {
+		return op.Info()
+	}
+	return &Request{Message: op.Info(), Findings: op.Findings}
 }
 
 func (op Operation) Info() string {
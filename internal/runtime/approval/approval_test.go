@@ -31,6 +31,22 @@ func TestOperationInfoUsesDefaultTitle(t *testing.T) {
 	}
 }
 
+func TestOperationRequestCarriesFindings(t *testing.T) {
+	op := Operation{Title: "Dangerous command requires approval", Target: "rm -rf /"}
+	if got, ok := op.request().(string); !ok || got != op.Info() {
+		t.Fatalf("operation without findings should interrupt with text, got %#v", op.request())
+	}
+
+	op.Findings = []Finding{{Level: "危险", Program: "rm", Target: "/", Scope: "critical", Reason: "递归删除关键目录"}}
+	request, ok := op.request().(*Request)
+	if !ok {
+		t.Fatalf("operation with findings should interrupt with *Request, got %#v", op.request())
+	}
+	if request.String() != op.Info() || len(request.Findings) != 1 || request.Findings[0].Scope != "critical" {
+		t.Fatalf("unexpected approval request: %#v", request)
+	}
+}
+
 func TestRejectedMessage(t *testing.T) {
 	got, ok := RejectedMessage(ErrRejected, "custom rejected")
 	if !ok {
@@ -85,3 +101,27 @@ func TestRegistryContextInjectsRegistry(t *testing.T) {
 		t.Fatalf("expected injected registry to approve command: %v", err)
 	}
 }
+
+func TestJournalRecordsDecisionSources(t *testing.T) {
+	reg := NewSelectiveRegistry([]string{StoreFile}, DefaultStoreConfigs()...)
+	reg.get(StoreCommand).approve("make test")
+	ctx, journal := WithJournal(WithRegistry(context.Background(), reg))
+
+	if err := Require(ctx, StoreFile, "/tmp/a.txt", "info"); err != nil {
+		t.Fatalf("auto-approved file: %v", err)
+	}
+	if err := Require(ctx, StoreCommand, "make test", "info"); err != nil {
+		t.Fatalf("remembered command: %v", err)
+	}
+	got := journal.Decisions()
+	want := []Decision{
+		{Store: StoreFile, Outcome: OutcomeApproved, Source: SourceAutoApprove, Scope: ScopeAll},
+		{Store: StoreCommand, Outcome: OutcomeApproved, Source: SourceUser, Scope: ScopeItem},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("decisions = %#v", got)
+	}
+	if journal.Interrupted() {
+		t.Fatal("journal should not be interrupted")
+	}
+}
@@ -0,0 +1,105 @@
+package approval
+
+import (
+	"context"
+	"sync"
+)
+
+// Source 是审批结果的来源。
+type Source string
+
+const (
+	// SourceUser 表示用户在审批提示中做出的选择，包括此前选择的“本项/全部批准”。
+	SourceUser Source = "user"
+	// SourceAutoApprove 表示配置的自动批准类别或无需人工确认的自主执行。
+	SourceAutoApprove Source = "auto_approve"
+	// SourcePolicy 表示权限策略规则的 allow、ask 或 deny。
+	SourcePolicy Source = "policy"
+)
+
+// Outcome 是一次审批检查的结果。
+type Outcome string
+
+const (
+	OutcomeApproved Outcome = "approved"
+	OutcomeRejected Outcome = "rejected"
+	OutcomeDenied   Outcome = "denied"
+)
+
+// Scope 是用户批准的范围。
+type Scope string
+
+const (
+	ScopeOnce Scope = "once"
+	ScopeItem Scope = "item"
+	ScopeAll  Scope = "all"
+)
+
+// Decision 是工具调用过程中的一次审批检查。
+type Decision struct {
+	Store   string  `json:"store"`
+	Outcome Outcome `json:"outcome"`
+	Source  Source  `json:"source"`
+	Scope   Scope   `json:"scope,omitempty"`
+	// Rule 是做出裁决或要求审批的策略规则
+	Rule string `json:"rule,omitempty"`
+}
+
+// Journal 收集一次工具调用中的审批检查，供 hook 和审计日志读取。
+// 调用因等待审批而中断时标记为 interrupted，恢复后会以新的 Journal 重新执行。
+type Journal struct {
+	mu          sync.Mutex
+	decisions   []Decision
+	interrupted bool
+}
+
+type journalCtxKey struct{}
+
+// WithJournal 为一次工具调用创建审批记录。
+func WithJournal(ctx context.Context) (context.Context, *Journal) {
+	journal := &Journal{}
+	return context.WithValue(ctx, journalCtxKey{}, journal), journal
+}
+
+func journalFromContext(ctx context.Context) *Journal {
+	journal, _ := ctx.Value(journalCtxKey{}).(*Journal)
+	return journal
+}
+
+// Decisions 返回已记录的审批检查副本。
+func (j *Journal) Decisions() []Decision {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]Decision(nil), j.decisions...)
+}
+
+// Interrupted 判断调用是否因等待审批而中断。
+func (j *Journal) Interrupted() bool {
+	if j == nil {
+		return false
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.interrupted
+}
+
+func (j *Journal) record(decision Decision) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	j.decisions = append(j.decisions, decision)
+	j.mu.Unlock()
+}
+
+func (j *Journal) markInterrupted() {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	j.interrupted = true
+	j.mu.Unlock()
+}
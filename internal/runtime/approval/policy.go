@@ -0,0 +1,455 @@
+package approval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	runtimeport "fkteams/internal/ports/runtime"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// StorePolicy 记录策略规则要求审批（ask）的工具调用。
+const StorePolicy = "policy"
+
+// Effect 是策略规则对工具调用的裁决。
+type Effect string
+
+const (
+	EffectAllow Effect = "allow"
+	EffectAsk   Effect = "ask"
+	EffectDeny  Effect = "deny"
+)
+
+// ErrDenied 表示工具调用被权限策略拒绝。
+var ErrDenied = errors.New("tool call denied by policy")
+
+// pathArgKeys 是工具参数中表示文件路径的字段。
+var pathArgKeys = []string{"filepath", "file_path", "dirpath", "path", "local_path", "remote_path"}
+
+// hostArgKeys 是工具参数中表示远程主机的字段。
+var hostArgKeys = []string{"server", "host"}
+
+// PolicyRule 是一条 allow/ask/deny 规则。同一字段内多个模式任一匹配即可，
+// 不同字段之间需要同时满足；未填写的字段不参与匹配。
+type PolicyRule struct {
+	Name   string `toml:"name"`
+	Effect Effect `toml:"effect"`
+	// Tools 匹配工具名的 glob，必填；["*"] 表示全部工具。
+	Tools []string `toml:"tools"`
+	// Agents 匹配发起调用的智能体名的 glob。
+	Agents []string `toml:"agents"`
+	// Commands 匹配 command 参数的命令前缀。
+	Commands []string `toml:"commands"`
+	// Paths 匹配路径类参数的 glob，** 可跨目录。
+	Paths []string `toml:"paths"`
+	// Hosts 匹配 server/host 参数的 glob。
+	Hosts []string `toml:"hosts"`
+	// Args 按参数名匹配任意参数值的 glob。
+	Args   map[string]string `toml:"args"`
+	Reason string            `toml:"reason"`
+	// Source 是规则所在的策略文件，由加载器填写。
+	Source string `toml:"-"`
+
+	tools  []*regexp.Regexp
+	agents []*regexp.Regexp
+	paths  []*regexp.Regexp
+	hosts  []*regexp.Regexp
+	args   map[string]*regexp.Regexp
+}
+
+// Policy 是按顺序求值的规则列表，第一条匹配的规则生效。
+type Policy struct {
+	Rules []PolicyRule `toml:"rules"`
+}
+
+// ToolCall 描述一次待评估的工具调用。
+type ToolCall struct {
+	Tool      string
+	Agent     string
+	Arguments string
+	CallID    string
+}
+
+// Verdict 是策略对一次工具调用的评估结果；Rule 为 nil 表示没有规则匹配。
+type Verdict struct {
+	Effect Effect
+	Rule   *PolicyRule
+}
+
+// ParsePolicy 解析 TOML 格式的策略文件内容并编译规则。
+func ParsePolicy(data []byte, source string) (*Policy, error) {
+	var policy Policy
+	decoder := toml.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("parse policy %s: %w", source, err)
+	}
+	for i := range policy.Rules {
+		policy.Rules[i].Source = source
+		if err := policy.Rules[i].compile(); err != nil {
+			return nil, fmt.Errorf("policy %s: rule %s: %w", source, policy.Rules[i].label(i), err)
+		}
+	}
+	return &policy, nil
+}
+
+// LoadPolicyFiles 依次加载策略文件并按顺序合并规则，不存在的文件会被跳过。
+func LoadPolicyFiles(paths ...string) (*Policy, error) {
+	merged := &Policy{}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read policy %s: %w", path, err)
+		}
+		policy, err := ParsePolicy(data, path)
+		if err != nil {
+			return nil, err
+		}
+		merged.Rules = append(merged.Rules, policy.Rules...)
+	}
+	return merged, nil
+}
+
+// Evaluate 返回第一条匹配规则的裁决。
+func (p *Policy) Evaluate(call ToolCall) Verdict {
+	if p == nil || len(p.Rules) == 0 {
+		return Verdict{}
+	}
+	args := decodeArguments(call.Arguments)
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.matches(call, args) {
+			return Verdict{Effect: rule.Effect, Rule: rule}
+		}
+	}
+	return Verdict{}
+}
+
+// Describe 返回规则的可读说明，用于拒绝信息和审批提示。
+func (r *PolicyRule) Describe() string {
+	name := r.Name
+	if name == "" {
+		name = "unnamed rule"
+	}
+	if r.Source != "" {
+		name += " (" + r.Source + ")"
+	}
+	if r.Reason != "" {
+		return name + ": " + r.Reason
+	}
+	return name
+}
+
+func (r *PolicyRule) label(index int) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return "#" + strconv.Itoa(index+1)
+}
+
+func (r *PolicyRule) compile() error {
+	switch r.Effect {
+	case EffectAllow, EffectAsk, EffectDeny:
+	default:
+		return fmt.Errorf("effect must be allow, ask or deny, got %q", r.Effect)
+	}
+	if len(r.Tools) == 0 {
+		return fmt.Errorf("tools is required")
+	}
+	var err error
+	if r.tools, err = compileGlobs(r.Tools); err != nil {
+		return err
+	}
+	if r.agents, err = compileGlobs(r.Agents); err != nil {
+		return err
+	}
+	if r.paths, err = compileGlobs(r.Paths); err != nil {
+		return err
+	}
+	if r.hosts, err = compileGlobs(r.Hosts); err != nil {
+		return err
+	}
+	for _, prefix := range r.Commands {
+		if strings.TrimSpace(prefix) == "" {
+			return fmt.Errorf("commands must not contain empty prefix")
+		}
+	}
+	r.args = make(map[string]*regexp.Regexp, len(r.Args))
+	for key, pattern := range r.Args {
+		re, err := compileGlob(pattern)
+		if err != nil {
+			return err
+		}
+		r.args[key] = re
+	}
+	return nil
+}
+
+func (r *PolicyRule) matches(call ToolCall, args map[string]any) bool {
+	if !matchAny(r.tools, call.Tool) {
+		return false
+	}
+	if len(r.agents) > 0 && !matchAny(r.agents, call.Agent) {
+		return false
+	}
+	if len(r.Commands) > 0 && !r.matchCommand(stringArg(args, "command")) {
+		return false
+	}
+	if len(r.paths) > 0 && !r.matchValues(r.paths, pathValues(args)) {
+		return false
+	}
+	if len(r.hosts) > 0 && !r.matchValues(r.hosts, argValues(args, hostArgKeys)) {
+		return false
+	}
+	for key, re := range r.args {
+		if !re.MatchString(stringArg(args, key)) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchCommand 按命令前缀匹配。命令会按 ;、&&、||、| 等拆分为多段：
+// allow 要求每一段都命中前缀且不含命令替换和重定向，deny/ask 只要任一段命中即可，
+// 避免用 "git status; rm -rf /" 这类拼接绕过规则。
+func (r *PolicyRule) matchCommand(command string) bool {
+	if strings.TrimSpace(command) == "" {
+		return false
+	}
+	segments := splitCommand(command)
+	if r.Effect != EffectAllow {
+		for _, segment := range segments {
+			if r.hasCommandPrefix(segment) {
+				return true
+			}
+		}
+		return false
+	}
+	if strings.ContainsAny(command, "`<>") || strings.Contains(command, "$(") {
+		return false
+	}
+	for _, segment := range segments {
+		if !r.hasCommandPrefix(segment) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *PolicyRule) hasCommandPrefix(segment string) bool {
+	segment = strings.Join(strings.Fields(segment), " ")
+	for _, prefix := range r.Commands {
+		prefix = strings.Join(strings.Fields(prefix), " ")
+		if segment == prefix || strings.HasPrefix(segment, prefix+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchValues 匹配一组参数值：allow 要求全部命中，deny/ask 任一命中即可。
+func (r *PolicyRule) matchValues(patterns []*regexp.Regexp, values []string) bool {
+	if len(values) == 0 {
+		return false
+	}
+	for _, value := range values {
+		matched := matchAny(patterns, value)
+		if r.Effect == EffectAllow && !matched {
+			return false
+		}
+		if r.Effect != EffectAllow && matched {
+			return true
+		}
+	}
+	return r.Effect == EffectAllow
+}
+
+func splitCommand(command string) []string {
+	fields := strings.FieldsFunc(command, func(c rune) bool {
+		return c == ';' || c == '&' || c == '|' || c == '\n'
+	})
+	segments := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			segments = append(segments, field)
+		}
+	}
+	return segments
+}
+
+func compileGlobs(patterns []string) ([]*regexp.Regexp, error) {
+	result := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := compileGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, re)
+	}
+	return result, nil
+}
+
+// compileGlob 将 glob 转为正则：** 匹配任意字符，* 和 ? 不跨越 /。
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				sb.WriteString(".*")
+				i++
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+func matchAny(patterns []*regexp.Regexp, value string) bool {
+	for _, re := range patterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeArguments(arguments string) map[string]any {
+	var args map[string]any
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return nil
+	}
+	return args
+}
+
+func stringArg(args map[string]any, key string) string {
+	switch value := args[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	default:
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+}
+
+func argValues(args map[string]any, keys []string) []string {
+	var values []string
+	for _, key := range keys {
+		if value := stringArg(args, key); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// pathValues 返回规范化后的路径参数，统一使用 / 分隔以便 glob 匹配。
+func pathValues(args map[string]any) []string {
+	values := argValues(args, pathArgKeys)
+	for i, value := range values {
+		values[i] = filepath.ToSlash(filepath.Clean(value))
+	}
+	return values
+}
+
+// ArgumentPaths 返回工具参数中的路径类参数，与策略 paths 匹配使用相同的字段和规范化方式。
+func ArgumentPaths(arguments string) []string {
+	return pathValues(decodeArguments(arguments))
+}
+
+// SetPolicy 设置 Registry 评估工具调用时使用的权限策略。
+func (r *Registry) SetPolicy(policy *Policy) {
+	if r != nil {
+		r.policy.Store(policy)
+	}
+}
+
+// Policy 返回 Registry 当前的权限策略。
+func (r *Registry) Policy() *Policy {
+	if r == nil {
+		return nil
+	}
+	return r.policy.Load()
+}
+
+// PolicyFromContext 返回上下文中 Registry 的权限策略。
+func PolicyFromContext(ctx context.Context) *Policy {
+	return RegistryFromContext(ctx).Policy()
+}
+
+type approvedCallCtxKey struct{}
+
+// CheckToolCall 按上下文中 Registry 的权限策略评估一次工具调用：
+// deny 返回 ErrDenied，ask 通过 policy store 发起人工审批，
+// allow 或审批通过后返回的上下文会跳过本次调用内部的审批。
+func CheckToolCall(ctx context.Context, call ToolCall) (context.Context, error) {
+	verdict := PolicyFromContext(ctx).Evaluate(call)
+	switch verdict.Effect {
+	case EffectDeny:
+		journalFromContext(ctx).record(Decision{Store: StorePolicy, Outcome: OutcomeDenied, Source: SourcePolicy, Rule: verdict.Rule.Describe()})
+		return ctx, fmt.Errorf("%w: %s", ErrDenied, verdict.Rule.Describe())
+	case EffectAsk:
+		op := Operation{
+			StoreName: StorePolicy,
+			Key:       call.Tool + " " + call.Arguments,
+			Title:     "Tool call requires approval by policy",
+			Target:    call.Tool,
+			Details: []OperationDetail{
+				{Name: "Rule", Value: verdict.Rule.Describe()},
+				{Name: "Agent", Value: call.Agent},
+				{Name: "Arguments", Value: call.Arguments},
+			},
+		}
+		if err := require(ctx, op.StoreName, op.Key, op.request(), verdict.Rule.Describe()); err != nil {
+			return ctx, err
+		}
+	case EffectAllow:
+		journalFromContext(ctx).record(Decision{Store: StorePolicy, Outcome: OutcomeApproved, Source: SourcePolicy, Rule: verdict.Rule.Describe()})
+	default:
+		return ctx, nil
+	}
+	if call.CallID == "" {
+		return ctx, nil
+	}
+	return context.WithValue(ctx, approvedCallCtxKey{}, call.CallID), nil
+}
+
+// isApprovedCall 判断当前工具调用是否已由权限策略放行。
+func isApprovedCall(ctx context.Context) bool {
+	callID, _ := ctx.Value(approvedCallCtxKey{}).(string)
+	if callID == "" {
+		return false
+	}
+	metadata, ok := runtimeport.ToolRuntimeMetadataFromContext(ctx)
+	return ok && metadata.CallID == callID
+}
@@ -0,0 +1,212 @@
+package approval
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	runtimeport "fkteams/internal/ports/runtime"
+)
+
+const testPolicy = `
+[[rules]]
+name = "no-rm"
+effect = "deny"
+tools = ["execute"]
+commands = ["rm -rf", "sudo"]
+reason = "dangerous command"
+
+[[rules]]
+name = "git-readonly"
+effect = "allow"
+tools = ["execute"]
+commands = ["git status", "git diff"]
+
+[[rules]]
+name = "src-writes"
+effect = "allow"
+tools = ["file_*"]
+paths = ["src/**"]
+
+[[rules]]
+name = "prod-ssh"
+effect = "ask"
+tools = ["ssh_*"]
+hosts = ["prod-*"]
+
+[[rules]]
+name = "reviewer-readonly"
+effect = "deny"
+tools = ["file_write", "mcp_*"]
+agents = ["reviewer"]
+
+[[rules]]
+name = "internal-fetch"
+effect = "allow"
+tools = ["fetch"]
+args = { url = "https://intranet/**" }
+`
+
+func mustParsePolicy(t *testing.T, data string) *Policy {
+	t.Helper()
+	policy, err := ParsePolicy([]byte(data), "policy.toml")
+	if err != nil {
+		t.Fatalf("ParsePolicy: %v", err)
+	}
+	return policy
+}
+
+func TestPolicyEvaluateFirstMatchingRule(t *testing.T) {
+	policy := mustParsePolicy(t, testPolicy)
+	tests := []struct {
+		name string
+		call ToolCall
+		rule string
+	}{
+		{"deny prefix", ToolCall{Tool: "execute", Arguments: `{"command":"rm -rf /tmp/x"}`}, "no-rm"},
+		{"deny chained segment", ToolCall{Tool: "execute", Arguments: `{"command":"git status; sudo reboot"}`}, "no-rm"},
+		{"allow all segments", ToolCall{Tool: "execute", Arguments: `{"command":"git status && git diff --stat"}`}, "git-readonly"},
+		{"allow needs every segment", ToolCall{Tool: "execute", Arguments: `{"command":"git status && make"}`}, ""},
+		{"allow rejects substitution", ToolCall{Tool: "execute", Arguments: `{"command":"git diff $(cat list)"}`}, ""},
+		{"prefix respects words", ToolCall{Tool: "execute", Arguments: `{"command":"git statusx"}`}, ""},
+		{"path glob", ToolCall{Tool: "file_write", Arguments: `{"filepath":"src/app/main.go"}`}, "src-writes"},
+		{"path escape", ToolCall{Tool: "file_write", Arguments: `{"filepath":"src/../../etc/passwd"}`}, ""},
+		{"host glob", ToolCall{Tool: "ssh_execute", Arguments: `{"server":"prod-db","command":"ls"}`}, "prod-ssh"},
+		{"missing host", ToolCall{Tool: "ssh_execute", Arguments: `{"command":"ls"}`}, ""},
+		{"agent", ToolCall{Tool: "mcp_github_create_issue", Agent: "reviewer"}, "reviewer-readonly"},
+		{"other agent", ToolCall{Tool: "mcp_github_create_issue", Agent: "coder"}, ""},
+		{"args glob", ToolCall{Tool: "fetch", Arguments: `{"url":"https://intranet/wiki/page"}`}, "internal-fetch"},
+		{"invalid json", ToolCall{Tool: "fetch", Arguments: `not-json`}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := policy.Evaluate(tt.call)
+			got := ""
+			if verdict.Rule != nil {
+				got = verdict.Rule.Name
+				if verdict.Effect != verdict.Rule.Effect {
+					t.Fatalf("effect = %q, rule effect = %q", verdict.Effect, verdict.Rule.Effect)
+				}
+			}
+			if got != tt.rule {
+				t.Fatalf("matched rule = %q, want %q", got, tt.rule)
+			}
+		})
+	}
+}
+
+func TestParsePolicyRejectsInvalidRules(t *testing.T) {
+	for _, data := range []string{
+		"[[rules]]\neffect = \"maybe\"\ntools = [\"*\"]",
+		"[[rules]]\neffect = \"allow\"",
+		"[[rules]]\neffect = \"deny\"\ntools = [\"execute\"]\ncommands = [\" \"]",
+		"[[rules]]\neffect = \"deny\"\ntools = [\"execute\"]\ncomands = [\"rm\"]",
+	} {
+		if _, err := ParsePolicy([]byte(data), "policy.toml"); err == nil {
+			t.Fatalf("expected error for policy:\n%s", data)
+		}
+	}
+}
+
+func TestLoadPolicyFilesMergesInOrderAndSkipsMissing(t *testing.T) {
+	dir := t.TempDir()
+	workspace := filepath.Join(dir, "workspace.toml")
+	global := filepath.Join(dir, "global.toml")
+	if err := os.WriteFile(workspace, []byte("[[rules]]\nname = \"ws\"\neffect = \"allow\"\ntools = [\"execute\"]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(global, []byte("[[rules]]\nname = \"global\"\neffect = \"deny\"\ntools = [\"*\"]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := LoadPolicyFiles(filepath.Join(dir, "missing.toml"), workspace, global)
+	if err != nil {
+		t.Fatalf("LoadPolicyFiles: %v", err)
+	}
+	if verdict := policy.Evaluate(ToolCall{Tool: "execute"}); verdict.Rule == nil || verdict.Rule.Name != "ws" || verdict.Rule.Source != workspace {
+		t.Fatalf("execute verdict = %#v", verdict)
+	}
+	if verdict := policy.Evaluate(ToolCall{Tool: "git_add"}); verdict.Effect != EffectDeny {
+		t.Fatalf("git_add verdict = %#v", verdict)
+	}
+}
+
+func TestCheckToolCallAppliesRegistryPolicy(t *testing.T) {
+	reg := NewDefaultRegistry()
+	reg.SetPolicy(mustParsePolicy(t, testPolicy))
+	ctx := WithRegistry(context.Background(), reg)
+
+	_, err := CheckToolCall(ctx, ToolCall{Tool: "execute", Arguments: `{"command":"sudo ls"}`, CallID: "call-1"})
+	if !errors.Is(err, ErrDenied) || !strings.Contains(err.Error(), "dangerous command") {
+		t.Fatalf("deny err = %v", err)
+	}
+
+	allowed, err := CheckToolCall(ctx, ToolCall{Tool: "execute", Arguments: `{"command":"git status"}`, CallID: "call-2"})
+	if err != nil {
+		t.Fatalf("allow err = %v", err)
+	}
+	toolCtx := runtimeport.WithToolRuntimeMetadata(allowed, runtimeport.ToolRuntimeMetadata{CallID: "call-2", Name: "execute"})
+	if err := Require(toolCtx, StoreCommand, "git status", "info"); err != nil {
+		t.Fatalf("allowed call should skip approval: %v", err)
+	}
+	if !isApprovedCall(toolCtx) {
+		t.Fatal("allowed call should be marked approved")
+	}
+	nestedCtx := runtimeport.WithToolRuntimeMetadata(allowed, runtimeport.ToolRuntimeMetadata{CallID: "call-3", Name: "execute"})
+	if isApprovedCall(nestedCtx) {
+		t.Fatal("approval must not leak to other tool calls")
+	}
+
+	unmatched, err := CheckToolCall(ctx, ToolCall{Tool: "git_status", CallID: "call-4"})
+	if err != nil || unmatched != ctx {
+		t.Fatalf("unmatched call should pass through unchanged: %v", err)
+	}
+}
+
+func TestCheckToolCallAskUsesPolicyStore(t *testing.T) {
+	reg := NewSelectiveRegistry([]string{StorePolicy}, DefaultStoreConfigs()...)
+	reg.SetPolicy(mustParsePolicy(t, testPolicy))
+	ctx := WithRegistry(context.Background(), reg)
+
+	if _, err := CheckToolCall(ctx, ToolCall{Tool: "ssh_execute", Arguments: `{"server":"prod-web"}`, CallID: "call-1"}); err != nil {
+		t.Fatalf("auto-approved policy store should pass ask rule: %v", err)
+	}
+	if got := PolicyFromContext(ctx); got != reg.Policy() {
+		t.Fatal("PolicyFromContext should return registry policy")
+	}
+	if PolicyFromContext(context.Background()) != nil {
+		t.Fatal("context without registry should have no policy")
+	}
+}
+
+func TestCheckToolCallRecordsPolicyDecision(t *testing.T) {
+	policy, err := ParsePolicy([]byte("[[rules]]\nname = \"no-secrets\"\neffect = \"deny\"\ntools = [\"file_read\"]\npaths = [\"**/.env\"]\n\n[[rules]]\nname = \"tests\"\neffect = \"allow\"\ntools = [\"execute\"]\ncommands = [\"go test\"]"), "policy.toml")
+	if err != nil {
+		t.Fatalf("parse policy: %v", err)
+	}
+	reg := NewDefaultRegistry()
+	reg.SetPolicy(policy)
+
+	ctx, journal := WithJournal(WithRegistry(context.Background(), reg))
+	if _, err := CheckToolCall(ctx, ToolCall{Tool: "file_read", Arguments: `{"filepath":"app/.env"}`}); !errors.Is(err, ErrDenied) {
+		t.Fatalf("expected deny, got %v", err)
+	}
+	if got := journal.Decisions(); len(got) != 1 || got[0].Outcome != OutcomeDenied || got[0].Source != SourcePolicy || !strings.Contains(got[0].Rule, "no-secrets") {
+		t.Fatalf("deny decisions = %#v", got)
+	}
+
+	ctx, journal = WithJournal(WithRegistry(context.Background(), reg))
+	if _, err := CheckToolCall(ctx, ToolCall{Tool: "execute", Arguments: `{"command":"go test ./..."}`}); err != nil {
+		t.Fatalf("allow: %v", err)
+	}
+	if got := journal.Decisions(); len(got) != 1 || got[0].Outcome != OutcomeApproved || got[0].Source != SourcePolicy {
+		t.Fatalf("allow decisions = %#v", got)
+	}
+
+	if got := ArgumentPaths(`{"filepath":"a/../b.txt","command":"ls"}`); len(got) != 1 || got[0] != "b.txt" {
+		t.Fatalf("argument paths = %#v", got)
+	}
+}
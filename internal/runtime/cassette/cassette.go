@@ -0,0 +1,117 @@
+// Package cassette 将聊天模型的真实交互录制为磁带文件，并按录制内容确定性地回放。
+package cassette
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"fkteams/internal/domain/message"
+	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/atomicfile"
+	modelregistry "fkteams/internal/runtime/model"
+)
+
+// Version 是当前磁带文件格式版本。
+const Version = 1
+
+// Cassette 是一组按请求顺序录制的模型交互。
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction 是一次模型请求及其响应；流式请求按顺序保存每个分片。
+type Interaction struct {
+	Model       string            `json:"model,omitempty"`
+	Stream      bool              `json:"stream,omitempty"`
+	Fingerprint string            `json:"fingerprint"`
+	Request     Request           `json:"request"`
+	Response    *message.Message  `json:"response,omitempty"`
+	Chunks      []message.Message `json:"chunks,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// Request 是发送给模型的消息和绑定的工具。
+type Request struct {
+	Messages []message.Message `json:"messages"`
+	Tools    []Tool            `json:"tools,omitempty"`
+}
+
+// Tool 是模型可见的工具定义。
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty"`
+}
+
+// Load 读取磁带文件。
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parse cassette %s: %w", path, err)
+	}
+	if c.Version != Version {
+		return nil, fmt.Errorf("cassette %s: unsupported version %d", path, c.Version)
+	}
+	return &c, nil
+}
+
+// Save 原子写入磁带文件，父目录不存在时自动创建。
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cassette: %w", err)
+	}
+	return atomicfile.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// ModelName 返回磁带中标识模型的名称：优先使用本地模型 ID，其次是提供者模型名。
+func ModelName(cfg *modelregistry.Config) string {
+	if cfg == nil {
+		return ""
+	}
+	if cfg.ID != "" {
+		return cfg.ID
+	}
+	return cfg.Model
+}
+
+func newRequest(input []message.Message, tools []runtimeport.ToolInfo) Request {
+	req := Request{Messages: append([]message.Message(nil), input...)}
+	for _, t := range tools {
+		req.Tools = append(req.Tools, Tool{Name: t.Name, Description: t.Desc, Parameters: t.Parameters})
+	}
+	return req
+}
+
+// fingerprint 对模型、调用方式、消息和工具名取摘要；工具参数 schema 不参与匹配。
+func fingerprint(model string, stream bool, req Request) string {
+	names := make([]string, 0, len(req.Tools))
+	for _, t := range req.Tools {
+		names = append(names, t.Name)
+	}
+	data, _ := json.Marshal(struct {
+		Model    string            `json:"model"`
+		Stream   bool              `json:"stream"`
+		Messages []message.Message `json:"messages"`
+		Tools    []string          `json:"tools"`
+	}{model, stream, req.Messages, names})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// replayError 还原录制时的模型错误。
+func replayError(text string) error {
+	if text == "" {
+		return nil
+	}
+	return errors.New(text)
+}
@@ -0,0 +1,168 @@
+package cassette
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fkteams/internal/domain/message"
+	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/testmodel"
+)
+
+func drain(t *testing.T, stream runtimeport.MessageStream) ([]message.Message, error) {
+	t.Helper()
+	defer stream.Close()
+	var chunks []message.Message
+	for {
+		msg, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return chunks, nil
+		}
+		if err != nil {
+			return chunks, err
+		}
+		chunks = append(chunks, msg)
+	}
+}
+
+func TestRecordSaveLoadReplay(t *testing.T) {
+	ctx := context.Background()
+	inner := testmodel.New(testmodel.AssistantMessage("planned")).
+		EnqueueStream(testmodel.AssistantMessage("hel"), testmodel.AssistantMessage("lo"))
+	recorder := NewRecorder()
+	model, err := recorder.Wrap(inner, "main").WithTools([]runtimeport.ToolInfo{{
+		Name:       "file_read",
+		Desc:       "read a file",
+		Parameters: json.RawMessage(`{"type":"object"}`),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := []message.Message{testmodel.UserMessage("hi")}
+	if _, err := model.Generate(ctx, input); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	stream, err := model.Stream(ctx, input)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if _, err := drain(t, stream); err != nil {
+		t.Fatalf("drain: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "cassettes", "case.json")
+	if err := recorder.Cassette().Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Interactions) != 2 || loaded.Interactions[0].Request.Tools[0].Name != "file_read" ||
+		!strings.Contains(string(loaded.Interactions[0].Request.Tools[0].Parameters), `"object"`) {
+		t.Fatalf("loaded cassette = %#v", loaded)
+	}
+
+	player := NewPlayer(loaded)
+	replayed, _ := player.Model("main").WithTools([]runtimeport.ToolInfo{{Name: "file_read"}})
+	got, err := replayed.Generate(ctx, input)
+	if err != nil || got.Content != "planned" {
+		t.Fatalf("replayed Generate = %#v, %v", got, err)
+	}
+	replayedStream, err := replayed.Stream(ctx, input)
+	if err != nil {
+		t.Fatalf("replayed Stream: %v", err)
+	}
+	chunks, err := drain(t, replayedStream)
+	if err != nil || len(chunks) != 2 || chunks[0].Content+chunks[1].Content != "hello" {
+		t.Fatalf("replayed chunks = %#v, %v", chunks, err)
+	}
+	if player.Remaining() != 0 {
+		t.Fatalf("remaining = %d", player.Remaining())
+	}
+	if _, err := replayed.Generate(ctx, input); err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+		t.Fatalf("exhausted cassette error = %v", err)
+	}
+}
+
+func TestPlayerPrefersFingerprintThenRecordOrder(t *testing.T) {
+	ctx := context.Background()
+	first := []message.Message{testmodel.UserMessage("first")}
+	second := []message.Message{testmodel.UserMessage("second")}
+	c := &Cassette{Version: Version}
+	for _, item := range []struct {
+		input []message.Message
+		reply string
+	}{{first, "one"}, {second, "two"}} {
+		reply := testmodel.AssistantMessage(item.reply)
+		req := newRequest(item.input, nil)
+		c.Interactions = append(c.Interactions, Interaction{Model: "main", Fingerprint: fingerprint("main", false, req), Request: req, Response: &reply})
+	}
+
+	player := NewPlayer(c)
+	model := player.Model("main")
+	if got, _ := model.Generate(ctx, second); got.Content != "two" {
+		t.Fatalf("fingerprint match = %q, want two", got.Content)
+	}
+	if got, _ := model.Generate(ctx, []message.Message{testmodel.UserMessage("changed")}); got.Content != "one" {
+		t.Fatalf("ordered fallback = %q, want one", got.Content)
+	}
+	if _, err := player.Model("other").Generate(ctx, first); err == nil {
+		t.Fatal("expected no interaction for unknown model")
+	}
+}
+
+func TestStreamErrorIsRecordedAndReplayed(t *testing.T) {
+	ctx := context.Background()
+	streamErr := errors.New("connection reset")
+	inner := brokenStreamModel{chunk: testmodel.AssistantMessage("partial"), err: streamErr}
+	recorder := NewRecorder()
+	stream, err := recorder.Wrap(inner, "main").Stream(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := drain(t, stream); err == nil {
+		t.Fatal("expected stream error")
+	}
+
+	replayed, err := NewPlayer(recorder.Cassette()).Model("main").Stream(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := drain(t, replayed)
+	if len(chunks) != 1 || err == nil || err.Error() != "connection reset" {
+		t.Fatalf("replayed = %#v, %v", chunks, err)
+	}
+}
+
+// brokenStreamModel 先返回一个分片，再以错误中断流。
+type brokenStreamModel struct {
+	runtimeport.ChatModel
+	chunk message.Message
+	err   error
+}
+
+func (m brokenStreamModel) Stream(context.Context, []message.Message) (runtimeport.MessageStream, error) {
+	return &brokenStream{chunk: m.chunk, err: m.err}, nil
+}
+
+type brokenStream struct {
+	chunk message.Message
+	err   error
+	sent  bool
+}
+
+func (s *brokenStream) Recv() (message.Message, error) {
+	if !s.sent {
+		s.sent = true
+		return s.chunk, nil
+	}
+	return message.Message{}, s.err
+}
+
+func (s *brokenStream) Close() {}
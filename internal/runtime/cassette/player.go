@@ -0,0 +1,138 @@
+package cassette
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"fkteams/internal/domain/message"
+	runtimeport "fkteams/internal/ports/runtime"
+	modelregistry "fkteams/internal/runtime/model"
+)
+
+// Player 按磁带内容回放模型交互，每条交互只使用一次。
+//
+// 请求优先按指纹匹配；提示词中含有日期、临时目录等易变内容导致指纹不一致时，
+// 按录制顺序取同一模型、同一调用方式的下一条未使用交互。
+type Player struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewPlayer 创建磁带回放器。
+func NewPlayer(c *Cassette) *Player {
+	var interactions []Interaction
+	if c != nil {
+		interactions = append(interactions, c.Interactions...)
+	}
+	return &Player{interactions: interactions, used: make([]bool, len(interactions))}
+}
+
+// Interceptor 返回模型拦截器：不创建真实模型，直接返回回放模型。
+func (p *Player) Interceptor() modelregistry.Interceptor {
+	return func(_ context.Context, cfg *modelregistry.Config, _ func() (runtimeport.ChatModel, error)) (runtimeport.ChatModel, error) {
+		return p.Model(ModelName(cfg)), nil
+	}
+}
+
+// Model 返回按 name 回放的聊天模型。
+func (p *Player) Model(name string) runtimeport.ChatModel {
+	return &replayModel{player: p, name: name}
+}
+
+// Remaining 返回尚未被请求使用的交互数量。
+func (p *Player) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	remaining := 0
+	for _, used := range p.used {
+		if !used {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+func (p *Player) next(name string, stream bool, req Request) (Interaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	want := fingerprint(name, stream, req)
+	fallback := -1
+	for i, it := range p.interactions {
+		if p.used[i] || it.Model != name || it.Stream != stream {
+			continue
+		}
+		if it.Fingerprint == want {
+			p.used[i] = true
+			return it, nil
+		}
+		if fallback < 0 {
+			fallback = i
+		}
+	}
+	if fallback < 0 {
+		return Interaction{}, fmt.Errorf("cassette: no recorded interaction left for model %q", name)
+	}
+	p.used[fallback] = true
+	return p.interactions[fallback], nil
+}
+
+type replayModel struct {
+	player *Player
+	name   string
+	tools  []runtimeport.ToolInfo
+}
+
+func (m *replayModel) Generate(_ context.Context, input []message.Message) (message.Message, error) {
+	it, err := m.player.next(m.name, false, newRequest(input, m.tools))
+	if err != nil {
+		return message.Message{}, err
+	}
+	if err := replayError(it.Error); err != nil {
+		return message.Message{}, err
+	}
+	if it.Response == nil {
+		return message.Message{}, fmt.Errorf("cassette: recorded interaction for model %q has no response", m.name)
+	}
+	return *it.Response, nil
+}
+
+func (m *replayModel) Stream(_ context.Context, input []message.Message) (runtimeport.MessageStream, error) {
+	it, err := m.player.next(m.name, true, newRequest(input, m.tools))
+	if err != nil {
+		return nil, err
+	}
+	if len(it.Chunks) == 0 {
+		if err := replayError(it.Error); err != nil {
+			return nil, err
+		}
+	}
+	return &replayStream{chunks: it.Chunks, err: replayError(it.Error)}, nil
+}
+
+func (m *replayModel) WithTools(tools []runtimeport.ToolInfo) (runtimeport.ChatModel, error) {
+	return &replayModel{player: m.player, name: m.name, tools: append([]runtimeport.ToolInfo(nil), tools...)}, nil
+}
+
+// replayStream 依次返回录制的分片，录制时流中途出错则在分片之后返回同样的错误。
+type replayStream struct {
+	chunks []message.Message
+	err    error
+	index  int
+}
+
+func (s *replayStream) Recv() (message.Message, error) {
+	if s.index < len(s.chunks) {
+		msg := s.chunks[s.index]
+		s.index++
+		return msg, nil
+	}
+	if s.err != nil {
+		return message.Message{}, s.err
+	}
+	return message.Message{}, io.EOF
+}
+
+func (s *replayStream) Close() {}
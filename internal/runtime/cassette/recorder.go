@@ -0,0 +1,140 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"fkteams/internal/domain/message"
+	runtimeport "fkteams/internal/ports/runtime"
+	modelregistry "fkteams/internal/runtime/model"
+)
+
+// Recorder 包装真实模型，按请求发出的顺序录制每次交互。
+type Recorder struct {
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder 创建空录制器。
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Interceptor 返回模型拦截器：正常创建真实模型并录制其交互。
+func (r *Recorder) Interceptor() modelregistry.Interceptor {
+	return func(_ context.Context, cfg *modelregistry.Config, create func() (runtimeport.ChatModel, error)) (runtimeport.ChatModel, error) {
+		inner, err := create()
+		if err != nil {
+			return nil, err
+		}
+		return r.Wrap(inner, ModelName(cfg)), nil
+	}
+}
+
+// Wrap 包装聊天模型，name 记录在每条交互中用于回放匹配。
+func (r *Recorder) Wrap(inner runtimeport.ChatModel, name string) runtimeport.ChatModel {
+	return &recordingModel{recorder: r, inner: inner, name: name}
+}
+
+// Cassette 返回已录制交互的快照。
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{Version: Version, Interactions: append([]Interaction(nil), r.interactions...)}
+}
+
+// begin 按请求顺序占位，响应在完成后回填，保证并发请求的录制顺序与发出顺序一致。
+func (r *Recorder) begin(name string, stream bool, req Request) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, Interaction{
+		Model:       name,
+		Stream:      stream,
+		Fingerprint: fingerprint(name, stream, req),
+		Request:     req,
+	})
+	return len(r.interactions) - 1
+}
+
+func (r *Recorder) finish(index int, fill func(*Interaction)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fill(&r.interactions[index])
+}
+
+type recordingModel struct {
+	recorder *Recorder
+	inner    runtimeport.ChatModel
+	name     string
+	tools    []runtimeport.ToolInfo
+}
+
+func (m *recordingModel) Generate(ctx context.Context, input []message.Message) (message.Message, error) {
+	index := m.recorder.begin(m.name, false, newRequest(input, m.tools))
+	msg, err := m.inner.Generate(ctx, input)
+	m.recorder.finish(index, func(it *Interaction) {
+		if err != nil {
+			it.Error = err.Error()
+			return
+		}
+		it.Response = &msg
+	})
+	return msg, err
+}
+
+func (m *recordingModel) Stream(ctx context.Context, input []message.Message) (runtimeport.MessageStream, error) {
+	index := m.recorder.begin(m.name, true, newRequest(input, m.tools))
+	stream, err := m.inner.Stream(ctx, input)
+	if err != nil {
+		m.recorder.finish(index, func(it *Interaction) { it.Error = err.Error() })
+		return nil, err
+	}
+	return &recordingStream{recorder: m.recorder, index: index, inner: stream}, nil
+}
+
+func (m *recordingModel) WithTools(tools []runtimeport.ToolInfo) (runtimeport.ChatModel, error) {
+	next, err := m.inner.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingModel{recorder: m.recorder, inner: next, name: m.name, tools: append([]runtimeport.ToolInfo(nil), tools...)}, nil
+}
+
+// recordingStream 边转发边收集分片，在流结束、出错或被关闭时写回交互。
+type recordingStream struct {
+	recorder *Recorder
+	index    int
+	inner    runtimeport.MessageStream
+	chunks   []message.Message
+	once     sync.Once
+}
+
+func (s *recordingStream) Recv() (message.Message, error) {
+	msg, err := s.inner.Recv()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			s.flush("")
+		} else {
+			s.flush(err.Error())
+		}
+		return msg, err
+	}
+	s.chunks = append(s.chunks, msg)
+	return msg, nil
+}
+
+func (s *recordingStream) Close() {
+	s.flush("")
+	s.inner.Close()
+}
+
+func (s *recordingStream) flush(errText string) {
+	s.once.Do(func() {
+		s.recorder.finish(s.index, func(it *Interaction) {
+			it.Chunks = s.chunks
+			it.Error = errText
+		})
+	})
+}
@@ -0,0 +1,35 @@
+package checkpoint
+
+import "context"
+
+type storeContextKey struct{}
+
+// WithStore 将 checkpoint 存储注入当前上下文，供 Runner 创建时使用。
+func WithStore(ctx context.Context, store Store) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if store == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, storeContextKey{}, store)
+}
+
+// StoreFromContext 从上下文读取 checkpoint 存储。
+func StoreFromContext(ctx context.Context) (Store, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	store, ok := ctx.Value(storeContextKey{}).(Store)
+	return store, ok && store != nil
+}
+
+// WithNamespace 将上下文中的 checkpoint 存储替换为带命名空间的视图。
+// 上下文中没有存储时原样返回，由调用方回退到进程内存储。
+func WithNamespace(ctx context.Context, namespace string) context.Context {
+	store, ok := StoreFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	return WithStore(ctx, NewNamespaceStore(namespace, store))
+}
@@ -0,0 +1,36 @@
+package checkpoint
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithNamespaceWrapsContextStore(t *testing.T) {
+	inner := NewMemoryStore()
+	ctx := WithNamespace(WithStore(context.Background(), inner), "team")
+
+	store, ok := StoreFromContext(ctx)
+	if !ok {
+		t.Fatal("expected namespaced store in context")
+	}
+	if err := store.Set(context.Background(), "session-1", []byte("state")); err != nil {
+		t.Fatalf("set value: %v", err)
+	}
+	got, ok, err := inner.Get(context.Background(), "team:session-1")
+	if err != nil {
+		t.Fatalf("get inner value: %v", err)
+	}
+	if !ok || string(got) != "state" {
+		t.Fatalf("inner value = %q, %v; want namespaced state", got, ok)
+	}
+}
+
+func TestWithNamespaceWithoutStoreKeepsContext(t *testing.T) {
+	ctx := context.Background()
+	if got := WithNamespace(ctx, "team"); got != ctx {
+		t.Fatal("WithNamespace should not modify context without store")
+	}
+	if _, ok := StoreFromContext(ctx); ok {
+		t.Fatal("empty context should not report checkpoint store")
+	}
+}
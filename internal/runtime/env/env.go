@@ -1,7 +1,12 @@
 // Package env 集中管理所有 FEIKONG_ 前缀的环境变量。
 package env
 
-import "os"
+import (
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
 
 // 环境变量名称常量
 const (
@@ -11,9 +16,60 @@ const (
 	NoSelfRestart          = "FEIKONG_NO_SELF_RESTART"           // 禁用自动重启（systemd 等场景）
 	MaxTokensBeforeSummary = "FEIKONG_MAX_TOKENS_BEFORE_SUMMARY" // 触发摘要的 token 阈值
 	DebugContext           = "FEIKONG_DEBUG_CONTEXT"             // 开启上下文日志
+	VaultPassphrase        = "FEIKONG_VAULT_PASSPHRASE"          // 加密密钥库口令，未使用系统钥匙串时必需
+)
+
+// secretNames 是含敏感信息的环境变量。它们在读取时从进程环境中移除，
+// 子进程（命令、脚本、MCP 服务、hook）不会继承。
+var secretNames = []string{VaultPassphrase}
+
+var (
+	secretMu     sync.Mutex
+	secretValues = make(map[string]string)
 )
 
 // Get 读取指定环境变量
 func Get(key string) string {
 	return os.Getenv(key)
 }
+
+// TakeSecrets 读取全部敏感环境变量并从进程环境中移除，应在启动时、创建任何子进程之前调用。
+func TakeSecrets() {
+	for _, name := range secretNames {
+		Secret(name)
+	}
+}
+
+// Secret 返回敏感环境变量的值。变量仍在进程环境中时读取后立即移除，之后返回保存的值。
+func Secret(name string) string {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	if value, ok := os.LookupEnv(name); ok {
+		secretValues[name] = value
+		os.Unsetenv(name)
+	}
+	return secretValues[name]
+}
+
+// SecretEnviron 以 KEY=VALUE 形式返回已读取的敏感环境变量，仅供重启自身时传给新进程。
+func SecretEnviron() []string {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	var values []string
+	for _, name := range secretNames {
+		if value := secretValues[name]; value != "" {
+			values = append(values, name+"="+value)
+		}
+	}
+	return values
+}
+
+// ChildEnviron 返回子进程环境：当前进程环境加上 extra，并去掉其中的敏感变量。
+func ChildEnviron(extra ...string) []string {
+	environ := append(os.Environ(), extra...)
+	return slices.DeleteFunc(environ, func(kv string) bool {
+		name, _, _ := strings.Cut(kv, "=")
+		// Windows 的环境变量名不区分大小写
+		return slices.ContainsFunc(secretNames, func(secret string) bool { return strings.EqualFold(secret, name) })
+	})
+}
@@ -1,6 +1,11 @@
 package env
 
-import "testing"
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
 
 func TestGetReadsEnvironmentVariable(t *testing.T) {
 	t.Setenv(AppDir, "/tmp/fkteams-test")
@@ -12,3 +17,23 @@ func TestGetReadsEnvironmentVariable(t *testing.T) {
 		t.Fatalf("Get(unknown) = %q, want empty", got)
 	}
 }
+
+func TestSecretIsRemovedFromProcessAndChildEnvironment(t *testing.T) {
+	t.Setenv(VaultPassphrase, "correct horse")
+	TakeSecrets()
+
+	if _, ok := os.LookupEnv(VaultPassphrase); ok {
+		t.Fatal("passphrase should be removed from the process environment")
+	}
+	if got := Secret(VaultPassphrase); got != "correct horse" {
+		t.Fatalf("Secret = %q, want value read at startup", got)
+	}
+	for _, kv := range ChildEnviron("FOO=bar", VaultPassphrase+"=from-project") {
+		if strings.HasPrefix(kv, VaultPassphrase+"=") {
+			t.Fatalf("child environment contains %q", kv)
+		}
+	}
+	if got := SecretEnviron(); !slices.Equal(got, []string{VaultPassphrase + "=correct horse"}) {
+		t.Fatalf("SecretEnviron = %q", got)
+	}
+}
@@ -67,6 +67,11 @@ func TurnEnd(runID, turnID string) Event {
 	return Event{Type: EventTurnCompleted, RunID: runID, TurnID: turnID}
 }
 
+// TurnFailed 表示回合被主动终止（如超出预算），reason 面向用户展示。
+func TurnFailed(runID, turnID, reason string) Event {
+	return Event{Type: EventTurnFailed, RunID: runID, TurnID: turnID, Content: reason, Error: reason}
+}
+
 type MessageEvent struct {
 	MessageID        string
 	Role             message.Role
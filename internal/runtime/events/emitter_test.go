@@ -134,6 +134,7 @@ func TestEventConstructors(t *testing.T) {
 		SystemNotice("coder", "root/coder", "interrupted", "paused"),
 		Error("coder", "root/coder", errors.New("failed")),
 		Usage("coder", "root/coder", 1, 2, 3),
+		TurnFailed("run_1", "turn_1", "budget exceeded"),
 	}
 
 	wantTypes := []EventType{
@@ -151,6 +152,7 @@ func TestEventConstructors(t *testing.T) {
 		EventSystemNotice,
 		EventError,
 		EventUsageReported,
+		EventTurnFailed,
 	}
 	for i, event := range constructors {
 		if event.Type != wantTypes[i] {
@@ -173,6 +175,9 @@ func TestEventConstructors(t *testing.T) {
 	if constructors[13].PromptTokens != 1 || constructors[13].CompletionTokens != 2 || constructors[13].TotalTokens != 3 {
 		t.Fatalf("Usage tokens = %#v", constructors[13])
 	}
+	if constructors[14].Error != "budget exceeded" || constructors[14].TurnID != "turn_1" {
+		t.Fatalf("TurnFailed = %#v", constructors[14])
+	}
 }
 
 func TestUserMessageTurnIDAndFirstNonEmpty(t *testing.T) {
@@ -63,5 +63,6 @@ const (
 type Event = domainevent.Event
 type AskPayload = domainevent.AskPayload
 type ApprovalPayload = domainevent.ApprovalPayload
+type ApprovalFinding = domainevent.ApprovalFinding
 type UsagePayload = domainevent.UsagePayload
 type NoticePayload = domainevent.NoticePayload
@@ -30,6 +30,7 @@ const (
 	ErrorFail   = hookport.ErrorFail
 )
 
+type AbortError = hookport.AbortError
 type Invocation = hookport.Invocation
 type Result = hookport.Result
 type Payload = hookport.Payload
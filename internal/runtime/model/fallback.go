@@ -0,0 +1,258 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	domainmessage "fkteams/internal/domain/message"
+	runtimeport "fkteams/internal/ports/runtime"
+	"fkteams/internal/runtime/log"
+	"fkteams/internal/runtime/retry"
+)
+
+// Candidate 是故障转移链中的一个模型。
+type Candidate struct {
+	ID       string
+	Model    runtimeport.ChatModel
+	Cooldown time.Duration
+}
+
+// FallbackChatModel 按顺序尝试候选模型：遇到可重试错误（429、5xx、网络错误）时切换到下一个，
+// 并将实际应答的模型 ID 写入响应的 ResponseMeta。
+type FallbackChatModel struct {
+	candidates []Candidate
+	breaker    *Breaker
+}
+
+// NewFallbackChatModel 创建故障转移组合模型；breaker 为 nil 时不熔断。
+func NewFallbackChatModel(candidates []Candidate, breaker *Breaker) (*FallbackChatModel, error) {
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("fallback model requires at least one candidate")
+	}
+	for _, c := range candidates {
+		if c.Model == nil {
+			return nil, fmt.Errorf("fallback candidate %q is nil", c.ID)
+		}
+	}
+	return &FallbackChatModel{candidates: candidates, breaker: breaker}, nil
+}
+
+func (m *FallbackChatModel) Generate(ctx context.Context, input []domainmessage.Message) (domainmessage.Message, error) {
+	var errs []error
+	for i, c := range m.available() {
+		msg, err := c.Model.Generate(ctx, input)
+		if err == nil {
+			m.breaker.Success(c.ID)
+			stampResponseModel(&msg, c.ID)
+			return msg, nil
+		}
+		if !m.failover(ctx, c, err, i) {
+			return domainmessage.Message{}, err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", c.ID, err))
+	}
+	return domainmessage.Message{}, m.exhausted(errs)
+}
+
+// Stream 在首个分片到达前失败时切换模型；首个分片之后的错误原样返回，由上层重试处理。
+func (m *FallbackChatModel) Stream(ctx context.Context, input []domainmessage.Message) (runtimeport.MessageStream, error) {
+	var errs []error
+	for i, c := range m.available() {
+		stream, err := c.Model.Stream(ctx, input)
+		var first domainmessage.Message
+		if err == nil {
+			first, err = stream.Recv()
+			if err != nil && !errors.Is(err, io.EOF) {
+				stream.Close()
+			} else {
+				m.breaker.Success(c.ID)
+				return &fallbackStream{inner: stream, first: first, firstErr: err, model: c.ID}, nil
+			}
+		}
+		if !m.failover(ctx, c, err, i) {
+			return nil, err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", c.ID, err))
+	}
+	return nil, m.exhausted(errs)
+}
+
+func (m *FallbackChatModel) WithTools(tools []runtimeport.ToolInfo) (runtimeport.ChatModel, error) {
+	candidates := make([]Candidate, 0, len(m.candidates))
+	for _, c := range m.candidates {
+		next, err := c.Model.WithTools(tools)
+		if err != nil {
+			return nil, fmt.Errorf("bind tools for %s: %w", c.ID, err)
+		}
+		c.Model = next
+		candidates = append(candidates, c)
+	}
+	return &FallbackChatModel{candidates: candidates, breaker: m.breaker}, nil
+}
+
+// available 返回未熔断的候选模型；全部熔断时仍返回完整列表，避免请求直接失败。
+func (m *FallbackChatModel) available() []Candidate {
+	result := make([]Candidate, 0, len(m.candidates))
+	for _, c := range m.candidates {
+		if !m.breaker.Open(c.ID) {
+			result = append(result, c)
+		}
+	}
+	if len(result) == 0 {
+		return m.candidates
+	}
+	return result
+}
+
+// failover 记录失败并判断是否继续尝试下一个候选模型。
+func (m *FallbackChatModel) failover(ctx context.Context, c Candidate, err error, index int) bool {
+	if !retry.IsRetryable(ctx, err) {
+		return false
+	}
+	m.breaker.Failure(c.ID, c.Cooldown)
+	log.Printf("[model] %s failed, trying next fallback (attempt %d): %v", c.ID, index+1, err)
+	return true
+}
+
+func (m *FallbackChatModel) exhausted(errs []error) error {
+	ids := make([]string, 0, len(m.candidates))
+	for _, c := range m.candidates {
+		ids = append(ids, c.ID)
+	}
+	return fmt.Errorf("all fallback models failed [%s]: %w", strings.Join(ids, ", "), errors.Join(errs...))
+}
+
+type fallbackStream struct {
+	inner    runtimeport.MessageStream
+	first    domainmessage.Message
+	firstErr error
+	started  bool
+	model    string
+}
+
+func (s *fallbackStream) Recv() (domainmessage.Message, error) {
+	if !s.started {
+		s.started = true
+		if s.firstErr != nil {
+			return domainmessage.Message{}, s.firstErr
+		}
+		stampResponseModel(&s.first, s.model)
+		return s.first, nil
+	}
+	return s.inner.Recv()
+}
+
+func (s *fallbackStream) Close() {
+	s.inner.Close()
+}
+
+// labeledChatModel 为单模型响应标记模型 ID，使用量统计与故障转移模型一致。
+type labeledChatModel struct {
+	inner runtimeport.ChatModel
+	id    string
+}
+
+func (m *labeledChatModel) Generate(ctx context.Context, input []domainmessage.Message) (domainmessage.Message, error) {
+	msg, err := m.inner.Generate(ctx, input)
+	if err == nil {
+		stampResponseModel(&msg, m.id)
+	}
+	return msg, err
+}
+
+func (m *labeledChatModel) Stream(ctx context.Context, input []domainmessage.Message) (runtimeport.MessageStream, error) {
+	stream, err := m.inner.Stream(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return &labeledStream{inner: stream, model: m.id}, nil
+}
+
+func (m *labeledChatModel) WithTools(tools []runtimeport.ToolInfo) (runtimeport.ChatModel, error) {
+	next, err := m.inner.WithTools(tools)
+	if err != nil {
+		return nil, err
+	}
+	return &labeledChatModel{inner: next, id: m.id}, nil
+}
+
+type labeledStream struct {
+	inner   runtimeport.MessageStream
+	model   string
+	stamped bool
+}
+
+func (s *labeledStream) Recv() (domainmessage.Message, error) {
+	msg, err := s.inner.Recv()
+	if err == nil && !s.stamped {
+		s.stamped = true
+		stampResponseModel(&msg, s.model)
+	}
+	return msg, err
+}
+
+func (s *labeledStream) Close() {
+	s.inner.Close()
+}
+
+func stampResponseModel(msg *domainmessage.Message, id string) {
+	if msg.ResponseMeta == nil {
+		msg.ResponseMeta = &domainmessage.ResponseMeta{}
+	}
+	msg.ResponseMeta.Model = id
+}
+
+// Breaker 在模型失败后于冷却时间内跳过该模型。零值不可用，使用 NewBreaker 创建；nil 表示不熔断。
+type Breaker struct {
+	mu        sync.Mutex
+	openUntil map[string]time.Time
+	now       func() time.Time
+}
+
+// NewBreaker 创建熔断器。
+func NewBreaker() *Breaker {
+	return &Breaker{openUntil: make(map[string]time.Time), now: time.Now}
+}
+
+// Open 判断模型是否处于熔断冷却中。
+func (b *Breaker) Open(id string) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	until, ok := b.openUntil[id]
+	if !ok {
+		return false
+	}
+	if b.now().After(until) {
+		delete(b.openUntil, id)
+		return false
+	}
+	return true
+}
+
+// Failure 记录一次可重试失败；cooldown 为 0 时不熔断。
+func (b *Breaker) Failure(id string, cooldown time.Duration) {
+	if b == nil || cooldown <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.openUntil[id] = b.now().Add(cooldown)
+}
+
+// Success 清除模型的熔断状态。
+func (b *Breaker) Success(id string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.openUntil, id)
+}
@@ -0,0 +1,131 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	domainmessage "fkteams/internal/domain/message"
+	runtimeport "fkteams/internal/ports/runtime"
+)
+
+type stubChatModel struct {
+	err   error
+	reply string
+	calls int
+}
+
+func (m *stubChatModel) Generate(context.Context, []domainmessage.Message) (domainmessage.Message, error) {
+	m.calls++
+	if m.err != nil {
+		return domainmessage.Message{}, m.err
+	}
+	return domainmessage.Message{Role: domainmessage.RoleAssistant, Content: m.reply}, nil
+}
+
+func (m *stubChatModel) Stream(context.Context, []domainmessage.Message) (runtimeport.MessageStream, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return runtimeport.NewMessageStream([]domainmessage.Message{
+		{Role: domainmessage.RoleAssistant, Content: m.reply},
+		{Role: domainmessage.RoleAssistant, Content: "!"},
+	}), nil
+}
+
+func (m *stubChatModel) WithTools([]runtimeport.ToolInfo) (runtimeport.ChatModel, error) {
+	return m, nil
+}
+
+func TestFallbackChatModelFailsOverOnRetryableError(t *testing.T) {
+	primary := &stubChatModel{err: errors.New("error, status code: 503")}
+	backup := &stubChatModel{reply: "ok"}
+	m, err := NewFallbackChatModel([]Candidate{{ID: "main", Model: primary}, {ID: "backup", Model: backup}}, nil)
+	if err != nil {
+		t.Fatalf("NewFallbackChatModel: %v", err)
+	}
+
+	msg, err := m.Generate(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if msg.Content != "ok" || msg.ResponseMeta == nil || msg.ResponseMeta.Model != "backup" {
+		t.Fatalf("message = %#v", msg)
+	}
+
+	stream, err := m.Stream(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	first, _ := stream.Recv()
+	second, _ := stream.Recv()
+	if first.ResponseMeta == nil || first.ResponseMeta.Model != "backup" || second.Content != "!" {
+		t.Fatalf("stream chunks = %#v, %#v", first, second)
+	}
+}
+
+func TestFallbackChatModelStopsOnNonRetryableError(t *testing.T) {
+	primary := &stubChatModel{err: errors.New("bad request")}
+	backup := &stubChatModel{reply: "ok"}
+	m, _ := NewFallbackChatModel([]Candidate{{ID: "main", Model: primary}, {ID: "backup", Model: backup}}, nil)
+
+	if _, err := m.Generate(context.Background(), nil); err == nil || backup.calls != 0 {
+		t.Fatalf("err = %v, backup calls = %d", err, backup.calls)
+	}
+
+	primary.err = errors.New("status code: 429")
+	backup.err = errors.New("status code: 502")
+	if _, err := m.Generate(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "all fallback models failed") {
+		t.Fatalf("exhausted error = %v", err)
+	}
+}
+
+func TestFallbackChatModelSkipsOpenCircuit(t *testing.T) {
+	now := time.Unix(1000, 0)
+	breaker := NewBreaker()
+	breaker.now = func() time.Time { return now }
+	primary := &stubChatModel{err: errors.New("status code: 500")}
+	backup := &stubChatModel{reply: "ok"}
+	m, _ := NewFallbackChatModel([]Candidate{{ID: "main", Model: primary, Cooldown: time.Minute}, {ID: "backup", Model: backup}}, breaker)
+
+	for range 2 {
+		if _, err := m.Generate(context.Background(), nil); err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+	}
+	if primary.calls != 1 {
+		t.Fatalf("primary calls = %d, want 1 while circuit is open", primary.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	primary.err = nil
+	primary.reply = "back"
+	msg, _ := m.Generate(context.Background(), nil)
+	if msg.ResponseMeta.Model != "main" {
+		t.Fatalf("answered by %q after cooldown, want main", msg.ResponseMeta.Model)
+	}
+}
+
+func TestNewChatModelBuildsFallbackChain(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(OpenAI, func(ctx context.Context, cfg *Config) (runtimeport.ChatModel, error) {
+		if cfg.Model == "down" {
+			return &stubChatModel{err: errors.New("connection refused")}, nil
+		}
+		return &stubChatModel{reply: cfg.Model}, nil
+	})
+	got, err := registry.NewChatModel(context.Background(), &Config{
+		ID: "main", Provider: OpenAI, Model: "down",
+		Fallback: []*Config{{ID: "backup", Provider: OpenAI, Model: "gpt-5"}},
+	})
+	if err != nil {
+		t.Fatalf("NewChatModel: %v", err)
+	}
+	msg, err := got.Generate(context.Background(), nil)
+	if err != nil || msg.Content != "gpt-5" || msg.ResponseMeta.Model != "backup" {
+		t.Fatalf("message = %#v, err = %v", msg, err)
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"context"
+
+	runtimeport "fkteams/internal/ports/runtime"
+)
+
+// Interceptor 包装注册表创建的每个聊天模型。create 创建真实模型，
+// 录制回放等场景可以不调用它而直接返回替身模型。
+type Interceptor func(ctx context.Context, cfg *Config, create func() (runtimeport.ChatModel, error)) (runtimeport.ChatModel, error)
+
+type interceptorContextKey struct{}
+
+// WithInterceptor 为当前上下文中创建的聊天模型设置拦截器。
+func WithInterceptor(ctx context.Context, interceptor Interceptor) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if interceptor == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, interceptorContextKey{}, interceptor)
+}
+
+// InterceptorFromContext 从上下文读取聊天模型拦截器。
+func InterceptorFromContext(ctx context.Context) (Interceptor, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	interceptor, ok := ctx.Value(interceptorContextKey{}).(Interceptor)
+	return interceptor, ok && interceptor != nil
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 )
 
 // Type 模型提供者类型。
@@ -26,11 +27,17 @@ const (
 
 // Config 是创建聊天模型所需的最小配置。
 type Config struct {
+	// ID 是本地模型 ID，用于记录实际应答的模型和熔断状态。
+	ID           string
 	Provider     Type
 	APIKey       string
 	BaseURL      string
 	Model        string
 	ExtraHeaders map[string]string
+	// Fallback 是按顺序尝试的故障转移模型，非空时返回组合模型。
+	Fallback []*Config
+	// Cooldown 是模型失败后的熔断冷却时间，0 表示不熔断。
+	Cooldown time.Duration
 }
 
 // Factory 创建运行时聊天模型。
@@ -42,11 +49,12 @@ type registryContextKey struct{}
 type Registry struct {
 	mu        sync.RWMutex
 	factories map[Type]Factory
+	breaker   *Breaker
 }
 
 // NewRegistry 创建空模型工厂注册表。
 func NewRegistry() *Registry {
-	return &Registry{factories: make(map[Type]Factory)}
+	return &Registry{factories: make(map[Type]Factory), breaker: NewBreaker()}
 }
 
 // Register 注册模型提供者工厂。
@@ -67,6 +75,53 @@ func (r *Registry) NewChatModel(ctx context.Context, cfg *Config) (runtimeport.C
 	if cfg == nil {
 		return nil, fmt.Errorf("model config is nil")
 	}
+	create := func() (runtimeport.ChatModel, error) { return r.newChatModel(ctx, cfg) }
+	if intercept, ok := InterceptorFromContext(ctx); ok {
+		return intercept(ctx, cfg, create)
+	}
+	return create()
+}
+
+func (r *Registry) newChatModel(ctx context.Context, cfg *Config) (runtimeport.ChatModel, error) {
+	if len(cfg.Fallback) > 0 {
+		return r.newFallbackChatModel(ctx, cfg)
+	}
+	chatModel, err := r.newSingleChatModel(ctx, cfg)
+	if err != nil || cfg.ID == "" {
+		return chatModel, err
+	}
+	return &labeledChatModel{inner: chatModel, id: cfg.ID}, nil
+}
+
+// newFallbackChatModel 创建主模型及其故障转移模型；熔断状态在注册表内共享。
+func (r *Registry) newFallbackChatModel(ctx context.Context, cfg *Config) (runtimeport.ChatModel, error) {
+	configs := append([]*Config{cfg}, cfg.Fallback...)
+	candidates := make([]Candidate, 0, len(configs))
+	for i, c := range configs {
+		if c == nil {
+			continue
+		}
+		chatModel, err := r.newSingleChatModel(ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("create model %s: %w", candidateID(c, i), err)
+		}
+		candidates = append(candidates, Candidate{ID: candidateID(c, i), Model: chatModel, Cooldown: c.Cooldown})
+	}
+	return NewFallbackChatModel(candidates, r.breaker)
+}
+
+func candidateID(cfg *Config, index int) string {
+	switch {
+	case cfg.ID != "":
+		return cfg.ID
+	case cfg.Model != "":
+		return cfg.Model
+	default:
+		return fmt.Sprintf("model-%d", index)
+	}
+}
+
+func (r *Registry) newSingleChatModel(ctx context.Context, cfg *Config) (runtimeport.ChatModel, error) {
 	t := cfg.Provider
 	if t == "" {
 		t = Detect(cfg.BaseURL, cfg.Model)
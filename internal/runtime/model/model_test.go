@@ -47,3 +47,30 @@ func TestRegistryContext(t *testing.T) {
 		t.Fatal("registry from context mismatch")
 	}
 }
+
+func TestNewChatModelAppliesContextInterceptor(t *testing.T) {
+	registry := NewRegistry()
+	created := 0
+	registry.Register(OpenAI, func(context.Context, *Config) (runtimeport.ChatModel, error) {
+		created++
+		return fakeChatModel{}, nil
+	})
+	replacement := fakeChatModel{}
+	ctx := WithInterceptor(context.Background(), func(_ context.Context, cfg *Config, create func() (runtimeport.ChatModel, error)) (runtimeport.ChatModel, error) {
+		if cfg.ID == "live" {
+			return create()
+		}
+		return replacement, nil
+	})
+
+	if _, err := registry.NewChatModel(ctx, &Config{ID: "replayed", Provider: OpenAI}); err != nil || created != 0 {
+		t.Fatalf("intercepted model: err=%v created=%d", err, created)
+	}
+	got, err := registry.NewChatModel(ctx, &Config{ID: "live", Provider: OpenAI})
+	if err != nil || created != 1 {
+		t.Fatalf("live model: err=%v created=%d", err, created)
+	}
+	if _, ok := got.(*labeledChatModel); !ok {
+		t.Fatalf("create() = %T, want labeled model", got)
+	}
+}
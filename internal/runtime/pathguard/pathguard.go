@@ -89,6 +89,51 @@ func ResolveWorkspace(baseDir, userPath string) (ResolvedPath, error) {
 	}, nil
 }
 
+// ReservedDir 是工作区中保存应用数据（成员文件区等）的目录，智能体工具只能读取不能写入。
+const ReservedDir = ".fkteams"
+
+// InReservedDir 判断 path 是否位于 baseDir 的保留目录中。同时检查字面路径和解析符号链接后的路径，
+// 目录名按不区分大小写比较，避免在大小写不敏感的文件系统上绕过。
+func InReservedDir(baseDir, path string) bool {
+	baseAbs, err := filepath.Abs(baseDir)
+	if err != nil {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	if reservedRel(baseAbs, absPath) {
+		return true
+	}
+	realBase, err := filepath.EvalSymlinks(baseAbs)
+	if err != nil {
+		return false
+	}
+	// 目标可能尚不存在，解析最近存在的父目录后拼回剩余部分
+	existing, rest := filepath.Clean(absPath), ""
+	for {
+		if real, err := filepath.EvalSymlinks(existing); err == nil {
+			return reservedRel(realBase, filepath.Join(real, rest))
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return false
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = parent
+	}
+}
+
+func reservedRel(base, path string) bool {
+	rel, err := filepath.Rel(base, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	first, _, _ := strings.Cut(rel, string(filepath.Separator))
+	return strings.EqualFold(first, ReservedDir)
+}
+
 func isWithin(path, base string) bool {
 	path = filepath.Clean(path)
 	base = filepath.Clean(base)
@@ -85,3 +85,26 @@ func TestRejectRootSymlinkComponents(t *testing.T) {
 		t.Fatalf("create regular directories: %v", err)
 	}
 }
+
+func TestInReservedDir(t *testing.T) {
+	base := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(base, ReservedDir, "users"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(base, ReservedDir), filepath.Join(base, "alias")); err != nil {
+		t.Skipf("symlink unsupported: %v", err)
+	}
+	for path, want := range map[string]bool{
+		filepath.Join(base, ReservedDir):                     true,
+		filepath.Join(base, ReservedDir, "users", "a.txt"):   true,
+		filepath.Join(base, ".FKTEAMS", "policy.toml"):       true,
+		filepath.Join(base, "alias", "new", "policy.toml"):   true,
+		filepath.Join(base, "src", ReservedDir, "notes.txt"): false,
+		filepath.Join(base, ".fkteams-notes"):                false,
+		base:                                                 false,
+	} {
+		if got := InReservedDir(base, path); got != want {
+			t.Errorf("InReservedDir(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// Package sandbox 为命令类工具提供可替换的子进程执行后端。
+//
+// 工具照常构造 *exec.Cmd，启动前交给 Executor.Wrap 改写：直接执行后端保持原样，
+// Linux 后端通过 bubblewrap 将命令放入只读系统、可写工作区、默认无网络的命名空间，
+// 以空目录遮住家目录和应用数据目录，只传入白名单内的环境变量，
+// 并叠加 seccomp 过滤和 rlimit 资源限制。
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// BackendDirect 在宿主机上直接执行，不做隔离。
+	BackendDirect = "direct"
+	// BackendLinux 使用 Linux 命名空间、seccomp 和 rlimit 隔离执行。
+	BackendLinux = "linux"
+)
+
+// Profile 描述一个沙箱配置。
+type Profile struct {
+	Name         string
+	Backend      string
+	Network      bool     // 是否允许访问网络
+	Writable     []string // 工作目录之外额外可写的目录
+	Hidden       []string // 以空目录遮住的目录，如家目录和应用数据目录；其中的工作目录、可写目录和只读目录会重新绑定
+	ReadOnly     []string // 在被遮住的目录中以只读方式重新绑定的目录，如工具链安装目录
+	PassEnv      []string // 白名单之外传入沙箱的环境变量名
+	CPUSeconds   int      // CPU 时间上限（秒），0 表示不限制
+	MemoryMB     int      // 虚拟内存上限（MiB），0 表示不限制
+	MaxProcesses int      // 当前用户可拥有的进程数上限，0 表示不限制
+	Required     bool     // 后端不可用时拒绝执行，而不是回退为直接执行
+}
+
+// Info 描述实际生效的沙箱，随工具调用事件上报。
+type Info struct {
+	Profile  string `json:"profile"`
+	Backend  string `json:"backend"`
+	Network  bool   `json:"network"`
+	Fallback string `json:"fallback,omitempty"` // 配置要求隔离但未能启用的原因
+}
+
+// WrapOptions 是单次命令的沙箱参数。
+type WrapOptions struct {
+	// Writable 是本次命令除 cmd.Dir 外需要写入的目录，如脚本环境目录。
+	Writable []string
+	// ReadOnly 是本次命令需要读取、可能位于被遮住目录中的目录，如解释器安装目录。
+	ReadOnly []string
+	// Env 是除白名单外需要传入沙箱的变量（KEY=VALUE），如项目配置的环境变量。
+	Env []string
+	// Detached 表示命令会在后台遗留子进程（如 nohup），沙箱不能随启动进程退出而销毁。
+	Detached bool
+}
+
+// Executor 是子进程执行后端。
+type Executor interface {
+	// Info 返回实际生效的沙箱描述。
+	Info() Info
+	// Wrap 在命令启动前改写 cmd，使其在沙箱中运行，须在设置 Path、Args、Dir 之后调用。
+	// 改写失败时错误写入 cmd.Err，由 Start 返回。返回的 release 在命令启动后（或放弃启动时）调用。
+	Wrap(cmd *exec.Cmd, opts WrapOptions) (release func())
+}
+
+// Direct 返回直接在宿主机执行的后端。
+func Direct() Executor {
+	return directExecutor{info: Info{Backend: BackendDirect, Network: true}}
+}
+
+// New 按配置创建执行后端。Linux 后端不可用时回退为直接执行并在 Info 中注明原因；
+// 配置了 Required 时改为拒绝执行。
+func New(profile Profile) Executor {
+	direct := directExecutor{info: Info{Profile: profile.Name, Backend: BackendDirect, Network: true}}
+	if profile.Backend != BackendLinux {
+		return direct
+	}
+	executor, reason := newPlatformExecutor(profile)
+	if executor != nil {
+		return executor
+	}
+	direct.info.Fallback = reason
+	if profile.Required {
+		return refusingExecutor{info: direct.info}
+	}
+	return direct
+}
+
+type directExecutor struct {
+	info Info
+}
+
+func (e directExecutor) Info() Info { return e.info }
+
+func (directExecutor) Wrap(*exec.Cmd, WrapOptions) func() { return func() {} }
+
+// Refuse 返回拒绝启动任何命令的后端，用于沙箱配置无效等不能安全执行的情况。
+func Refuse(profile, reason string) Executor {
+	return refusingExecutor{info: Info{Profile: profile, Backend: BackendDirect, Fallback: reason}}
+}
+
+// refusingExecutor 在必需的沙箱不可用时拒绝启动任何命令。
+type refusingExecutor struct {
+	info Info
+}
+
+func (e refusingExecutor) Info() Info { return e.info }
+
+func (e refusingExecutor) Wrap(cmd *exec.Cmd, _ WrapOptions) func() {
+	if cmd.Err == nil {
+		cmd.Err = fmt.Errorf("sandbox profile %s is unavailable: %s", e.info.Profile, e.info.Fallback)
+	}
+	return func() {}
+}
+
+// ExpandPath 展开 ~ 开头的路径并转换为绝对路径。
+func ExpandPath(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	return filepath.Abs(path)
+}
+
+// limitPrelude 返回设置 rlimit 后 exec 原命令的 bash 片段，没有限制时返回空串。
+func limitPrelude(profile Profile) string {
+	var limits []string
+	if profile.CPUSeconds > 0 {
+		limits = append(limits, fmt.Sprintf("ulimit -t %d", profile.CPUSeconds))
+	}
+	if profile.MemoryMB > 0 {
+		limits = append(limits, fmt.Sprintf("ulimit -v %d", profile.MemoryMB*1024))
+	}
+	if profile.MaxProcesses > 0 {
+		limits = append(limits, fmt.Sprintf("ulimit -u %d", profile.MaxProcesses))
+	}
+	if len(limits) == 0 {
+		return ""
+	}
+	return strings.Join(limits, " && ") + ` && exec "$@"`
+}
@@ -0,0 +1,287 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sandboxArgv0 是 rlimit 前导脚本中 $0 的取值，出现在错误信息里便于定位。
+const sandboxArgv0 = "fkteams-sandbox"
+
+// findBwrap 查找可用的 bubblewrap，测试中可替换。
+var findBwrap = sync.OnceValues(func() (string, error) {
+	path, err := exec.LookPath("bwrap")
+	if err != nil {
+		return "", fmt.Errorf("bwrap not found")
+	}
+	// 未开放非特权用户命名空间的系统上 bwrap 存在但无法运行，先探测一次。
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	probe := exec.CommandContext(ctx, path, "--ro-bind", "/", "/", "--unshare-user-try", "--unshare-net", "--", "true")
+	if output, err := probe.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("bwrap unusable: %v %s", err, output)
+	}
+	return path, nil
+})
+
+func newPlatformExecutor(profile Profile) (Executor, string) {
+	path, err := findBwrap()
+	if err != nil {
+		return nil, err.Error()
+	}
+	return &linuxExecutor{bwrap: path, profile: profile, filter: seccompFilter()}, ""
+}
+
+// linuxExecutor 通过 bubblewrap 在新的命名空间中运行命令。
+type linuxExecutor struct {
+	bwrap   string
+	profile Profile
+	filter  []byte // seccomp BPF 程序，当前架构不支持时为空
+}
+
+func (e *linuxExecutor) Info() Info {
+	return Info{Profile: e.profile.Name, Backend: BackendLinux, Network: e.profile.Network}
+}
+
+func (e *linuxExecutor) Wrap(cmd *exec.Cmd, opts WrapOptions) func() {
+	if cmd.Err != nil {
+		return func() {}
+	}
+	seccompFD := -1
+	var filterFile *os.File
+	if len(e.filter) > 0 {
+		file, err := filterPipe(e.filter)
+		if err != nil {
+			cmd.Err = fmt.Errorf("prepare seccomp filter: %w", err)
+			return func() {}
+		}
+		filterFile = file
+		// ExtraFiles[i] 在子进程中的描述符为 3+i。
+		seccompFD = 3 + len(cmd.ExtraFiles)
+		cmd.ExtraFiles = append(cmd.ExtraFiles, file)
+	}
+	args, err := e.args(cmd, opts, seccompFD)
+	if err != nil {
+		if filterFile != nil {
+			cmd.ExtraFiles = cmd.ExtraFiles[:len(cmd.ExtraFiles)-1]
+			filterFile.Close()
+		}
+		cmd.Err = err
+		return func() {}
+	}
+	environ := cmd.Env
+	if environ == nil {
+		environ = os.Environ()
+	}
+	cmd.Path = e.bwrap
+	cmd.Args = args
+	cmd.Env = e.sandboxEnv(environ, opts.Env)
+	return func() {
+		if filterFile != nil {
+			filterFile.Close()
+		}
+	}
+}
+
+// args 构造 bwrap 命令行：只读绑定根目录，重新挂载 /dev、/proc 和私有 /tmp，
+// 以空目录遮住家目录和应用数据目录，再绑定只读目录、工作目录和允许写入的目录。
+func (e *linuxExecutor) args(cmd *exec.Cmd, opts WrapOptions, seccompFD int) ([]string, error) {
+	args := []string{e.bwrap,
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+	}
+	var mounts []mount
+	add := func(kind mountKind, dirs []string) error {
+		for _, dir := range dirs {
+			abs, err := ExpandPath(dir)
+			if err != nil {
+				return fmt.Errorf("resolve sandbox path %s: %w", dir, err)
+			}
+			// 不存在的目录无法绑定，跳过而不是让整条命令失败。
+			if _, err := os.Stat(abs); err != nil {
+				continue
+			}
+			mounts = append(mounts, mount{kind: kind, path: abs})
+		}
+		return nil
+	}
+	writable := append([]string(nil), opts.Writable...)
+	if cmd.Dir != "" {
+		writable = append(writable, cmd.Dir)
+	}
+	writable = append(writable, e.profile.Writable...)
+	readOnly := append(append([]string(nil), opts.ReadOnly...), e.profile.ReadOnly...)
+	if root := executableRoot(cmd.Path); root != "" && e.hides(root) {
+		readOnly = append(readOnly, root)
+	}
+	if err := add(mountHidden, e.profile.Hidden); err != nil {
+		return nil, err
+	}
+	if err := add(mountReadOnly, readOnly); err != nil {
+		return nil, err
+	}
+	if err := add(mountWritable, writable); err != nil {
+		return nil, err
+	}
+	for _, m := range orderMounts(mounts) {
+		switch m.kind {
+		case mountHidden:
+			args = append(args, "--tmpfs", m.path)
+		case mountReadOnly:
+			args = append(args, "--ro-bind", m.path, m.path)
+		case mountWritable:
+			args = append(args, "--bind", m.path, m.path)
+		}
+	}
+	args = append(args, "--unshare-user-try", "--unshare-ipc", "--unshare-uts", "--unshare-cgroup-try")
+	if !e.profile.Network {
+		args = append(args, "--unshare-net")
+	}
+	if !opts.Detached {
+		// 后台命令需要在启动进程退出后继续运行，不能放进随 bwrap 销毁的 PID 命名空间。
+		args = append(args, "--unshare-pid", "--die-with-parent")
+	}
+	args = append(args, "--new-session")
+	if cmd.Dir != "" {
+		args = append(args, "--chdir", cmd.Dir)
+	}
+	if seccompFD >= 0 {
+		args = append(args, "--seccomp", strconv.Itoa(seccompFD))
+	}
+	args = append(args, "--")
+	if prelude := limitPrelude(e.profile); prelude != "" {
+		args = append(args, "/bin/bash", "-c", prelude, sandboxArgv0)
+	}
+	args = append(args, cmd.Path)
+	if len(cmd.Args) > 1 {
+		args = append(args, cmd.Args[1:]...)
+	}
+	return args, nil
+}
+
+type mountKind int
+
+const (
+	mountHidden mountKind = iota
+	mountReadOnly
+	mountWritable
+)
+
+type mount struct {
+	kind mountKind
+	path string
+}
+
+// orderMounts 去重后按路径层级排序，较深的挂载后执行并覆盖较浅的挂载；
+// 同一路径上遮住、只读、可写依次执行。工作区位于家目录中时先遮住家目录再绑定工作区，
+// 工作区就是家目录时，其中的应用数据目录在绑定之后仍会被遮住。
+func orderMounts(mounts []mount) []mount {
+	seen := make(map[mount]bool, len(mounts))
+	ordered := make([]mount, 0, len(mounts))
+	for _, m := range mounts {
+		if !seen[m] {
+			seen[m] = true
+			ordered = append(ordered, m)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		di, dj := pathDepth(ordered[i].path), pathDepth(ordered[j].path)
+		if di != dj {
+			return di < dj
+		}
+		return ordered[i].kind < ordered[j].kind
+	})
+	return ordered
+}
+
+func pathDepth(path string) int {
+	return strings.Count(filepath.Clean(path), string(filepath.Separator))
+}
+
+// executableRoot 返回需要只读绑定的可执行文件所在目录（解析符号链接后）。
+// 位于 bin 目录且不在家目录一级子目录下时返回 bin 的上级目录，
+// 使 uv 管理的 Python、nvm 管理的 Node 等解释器能读取同一安装目录中的标准库。
+func executableRoot(path string) string {
+	if !filepath.IsAbs(path) {
+		return ""
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return ""
+	}
+	dir := filepath.Dir(resolved)
+	if filepath.Base(dir) != "bin" {
+		return dir
+	}
+	prefix := filepath.Dir(dir)
+	// ~/.local/bin、~/.cargo/bin 的上级目录中还有其他数据，只绑定 bin 本身
+	if home, err := os.UserHomeDir(); err == nil && filepath.Dir(prefix) == filepath.Clean(home) {
+		return dir
+	}
+	return prefix
+}
+
+// hides 判断目录是否位于被遮住的目录中。
+func (e *linuxExecutor) hides(dir string) bool {
+	for _, hidden := range e.profile.Hidden {
+		abs, err := ExpandPath(hidden)
+		if err == nil && (dir == abs || strings.HasPrefix(dir, abs+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
+
+// envAllowlist 是默认传入沙箱的环境变量，另外包括 LC_ 开头的区域设置。
+var envAllowlist = []string{"PATH", "HOME", "USER", "LOGNAME", "SHELL", "LANG", "LANGUAGE", "TERM", "TZ"}
+
+// proxyEnv 是允许联网时传入沙箱的代理变量。
+var proxyEnv = []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "ALL_PROXY", "http_proxy", "https_proxy", "no_proxy", "all_proxy"}
+
+// sandboxEnv 返回沙箱中命令的环境：只保留白名单、配置允许的变量和本次命令的附加变量。
+// 变量通过 bwrap 进程的环境传入子进程，而不是 --setenv 参数，避免在进程列表中暴露取值。
+func (e *linuxExecutor) sandboxEnv(environ []string, extra []string) []string {
+	allowed := func(name string) bool {
+		return slices.Contains(envAllowlist, name) || strings.HasPrefix(name, "LC_") ||
+			slices.Contains(e.profile.PassEnv, name) || (e.profile.Network && slices.Contains(proxyEnv, name))
+	}
+	result := make([]string, 0, len(envAllowlist)+len(extra))
+	for _, kv := range environ {
+		if name, _, _ := strings.Cut(kv, "="); allowed(name) {
+			result = append(result, kv)
+		}
+	}
+	return append(result, extra...)
+}
+
+// filterPipe 将 seccomp 程序写入管道并返回读端，由 bwrap 从继承的描述符读取。
+func filterPipe(filter []byte) (*os.File, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	// 程序只有几百字节，远小于管道缓冲区，写入不会阻塞。
+	if _, err := w.Write(filter); err != nil {
+		r.Close()
+		w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		r.Close()
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,130 @@
+//go:build linux
+
+package sandbox
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewFallsBackWhenBwrapUnavailable(t *testing.T) {
+	original := findBwrap
+	findBwrap = func() (string, error) { return "", errors.New("bwrap not found") }
+	t.Cleanup(func() { findBwrap = original })
+
+	info := New(Profile{Name: "strict", Backend: BackendLinux}).Info()
+	if info.Backend != BackendDirect || info.Fallback != "bwrap not found" {
+		t.Fatalf("fallback info = %#v", info)
+	}
+	cmd := exec.Command("true")
+	New(Profile{Name: "strict", Backend: BackendLinux, Required: true}).Wrap(cmd, WrapOptions{})()
+	if cmd.Err == nil {
+		t.Fatal("required profile started without sandbox")
+	}
+}
+
+func TestLinuxExecutorWrapsCommand(t *testing.T) {
+	workDir := t.TempDir()
+	envDir := t.TempDir()
+	executor := &linuxExecutor{
+		bwrap:   "/usr/bin/bwrap",
+		profile: Profile{Name: "strict", Backend: BackendLinux, CPUSeconds: 10, Writable: []string{"/nonexistent-sandbox-dir"}},
+		filter:  seccompFilter(),
+	}
+	cmd := exec.Command("/bin/echo", "hello")
+	cmd.Dir = workDir
+	release := executor.Wrap(cmd, WrapOptions{Writable: []string{envDir}})
+	defer release()
+
+	if cmd.Err != nil {
+		t.Fatalf("Wrap: %v", cmd.Err)
+	}
+	args := strings.Join(cmd.Args, " ")
+	for _, want := range []string{
+		"--ro-bind / /",
+		"--bind " + workDir + " " + workDir,
+		"--bind " + envDir + " " + envDir,
+		"--unshare-net",
+		"--unshare-pid --die-with-parent",
+		"--chdir " + workDir,
+		"--seccomp 3",
+		`-- /bin/bash -c ulimit -t 10 && exec "$@" fkteams-sandbox /bin/echo hello`,
+	} {
+		if !strings.Contains(args, want) {
+			t.Fatalf("bwrap args missing %q:\n%s", want, args)
+		}
+	}
+	if strings.Contains(args, "nonexistent-sandbox-dir") {
+		t.Fatalf("missing writable dir was bound:\n%s", args)
+	}
+	if cmd.Path != "/usr/bin/bwrap" || len(cmd.ExtraFiles) != 1 {
+		t.Fatalf("cmd path = %s, extra files = %d", cmd.Path, len(cmd.ExtraFiles))
+	}
+}
+
+func TestLinuxExecutorDetachedAndNetwork(t *testing.T) {
+	executor := &linuxExecutor{bwrap: "/usr/bin/bwrap", profile: Profile{Name: "net", Backend: BackendLinux, Network: true}}
+	cmd := exec.Command("/bin/true")
+	executor.Wrap(cmd, WrapOptions{Detached: true})()
+	if cmd.Err != nil {
+		t.Fatalf("Wrap: %v", cmd.Err)
+	}
+	for _, flag := range []string{"--unshare-net", "--unshare-pid", "--die-with-parent", "--seccomp"} {
+		if slices.Contains(cmd.Args, flag) {
+			t.Fatalf("unexpected %s in %v", flag, cmd.Args)
+		}
+	}
+	if got := cmd.Args[len(cmd.Args)-1]; got != "/bin/true" {
+		t.Fatalf("sandboxed command = %q", got)
+	}
+}
+
+func TestLinuxExecutorHidesHomeAndBindsWorkspaceBack(t *testing.T) {
+	home := t.TempDir()
+	workDir := filepath.Join(home, "project")
+	toolchain := filepath.Join(home, ".rustup")
+	for _, dir := range []string{workDir, toolchain} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	executor := &linuxExecutor{
+		bwrap:   "/usr/bin/bwrap",
+		profile: Profile{Name: "strict", Backend: BackendLinux, Network: true, Hidden: []string{home}, ReadOnly: []string{toolchain}},
+	}
+	cmd := exec.Command("/bin/true")
+	cmd.Dir = workDir
+	executor.Wrap(cmd, WrapOptions{})()
+	if cmd.Err != nil {
+		t.Fatalf("Wrap: %v", cmd.Err)
+	}
+	args := strings.Join(cmd.Args, " ")
+	hidden := strings.Index(args, "--tmpfs "+home+" ")
+	readOnly := strings.Index(args, "--ro-bind "+toolchain+" "+toolchain)
+	writable := strings.Index(args, "--bind "+workDir+" "+workDir)
+	if hidden < 0 || readOnly < hidden || writable < hidden {
+		t.Fatalf("home must be hidden before binding back subdirectories:\n%s", args)
+	}
+}
+
+func TestLinuxExecutorPassesOnlyAllowlistedEnv(t *testing.T) {
+	executor := &linuxExecutor{
+		bwrap:   "/usr/bin/bwrap",
+		profile: Profile{Name: "strict", Backend: BackendLinux, PassEnv: []string{"GOFLAGS"}},
+	}
+	cmd := exec.Command("/bin/true")
+	cmd.Env = []string{"PATH=/usr/bin", "LC_ALL=C", "GOFLAGS=-mod=mod", "OPENAI_API_KEY=sk-secret", "HTTPS_PROXY=http://proxy"}
+	executor.Wrap(cmd, WrapOptions{Env: []string{"PROJECT_MODE=dev"}})()
+	if cmd.Err != nil {
+		t.Fatalf("Wrap: %v", cmd.Err)
+	}
+	want := []string{"PATH=/usr/bin", "LC_ALL=C", "GOFLAGS=-mod=mod", "PROJECT_MODE=dev"}
+	if !slices.Equal(cmd.Env, want) {
+		t.Fatalf("sandbox env = %v, want %v", cmd.Env, want)
+	}
+}
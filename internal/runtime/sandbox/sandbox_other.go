@@ -0,0 +1,7 @@
+//go:build !linux
+
+package sandbox
+
+func newPlatformExecutor(Profile) (Executor, string) {
+	return nil, "linux sandbox is not supported on this platform"
+}
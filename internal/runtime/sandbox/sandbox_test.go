@@ -0,0 +1,49 @@
+package sandbox
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestNewDirectProfileKeepsCommand(t *testing.T) {
+	executor := New(Profile{Name: "open", Backend: BackendDirect})
+	info := executor.Info()
+	if info.Profile != "open" || info.Backend != BackendDirect || !info.Network || info.Fallback != "" {
+		t.Fatalf("info = %#v", info)
+	}
+	cmd := exec.Command("echo", "hi")
+	path, args := cmd.Path, append([]string(nil), cmd.Args...)
+	executor.Wrap(cmd, WrapOptions{})()
+	if cmd.Path != path || strings.Join(cmd.Args, " ") != strings.Join(args, " ") || cmd.Err != nil {
+		t.Fatalf("direct executor changed command: %s %v %v", cmd.Path, cmd.Args, cmd.Err)
+	}
+}
+
+func TestRefusingExecutorFailsStart(t *testing.T) {
+	executor := refusingExecutor{info: Info{Profile: "strict", Backend: BackendDirect, Fallback: "bwrap not found"}}
+	cmd := exec.Command("true")
+	executor.Wrap(cmd, WrapOptions{})()
+	if err := cmd.Start(); err == nil || !strings.Contains(err.Error(), "bwrap not found") {
+		t.Fatalf("Start error = %v", err)
+	}
+}
+
+func TestLimitPrelude(t *testing.T) {
+	if got := limitPrelude(Profile{}); got != "" {
+		t.Fatalf("empty profile prelude = %q", got)
+	}
+	got := limitPrelude(Profile{CPUSeconds: 30, MemoryMB: 512, MaxProcesses: 64})
+	want := `ulimit -t 30 && ulimit -v 524288 && ulimit -u 64 && exec "$@"`
+	if got != want {
+		t.Fatalf("prelude = %q, want %q", got, want)
+	}
+}
+
+func TestRefuseFailsStart(t *testing.T) {
+	cmd := exec.Command("true")
+	Refuse("missing", `sandbox profile "missing" not found`).Wrap(cmd, WrapOptions{})()
+	if err := cmd.Start(); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("Start error = %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+//go:build linux && (amd64 || arm64)
+
+package sandbox
+
+import (
+	"encoding/binary"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// deniedSyscalls 是沙箱内返回 EPERM 的系统调用：挂载与命名空间操作、调试其他进程、
+// 内核模块与 kexec、eBPF、内核密钥环和修改系统时间等。
+var deniedSyscalls = []uint32{
+	unix.SYS_MOUNT, unix.SYS_UMOUNT2, unix.SYS_PIVOT_ROOT, unix.SYS_CHROOT,
+	unix.SYS_FSOPEN, unix.SYS_FSCONFIG, unix.SYS_FSMOUNT, unix.SYS_MOVE_MOUNT, unix.SYS_OPEN_TREE, unix.SYS_MOUNT_SETATTR,
+	unix.SYS_UNSHARE, unix.SYS_SETNS,
+	unix.SYS_PTRACE, unix.SYS_PROCESS_VM_READV, unix.SYS_PROCESS_VM_WRITEV,
+	unix.SYS_KEXEC_LOAD, unix.SYS_KEXEC_FILE_LOAD, unix.SYS_INIT_MODULE, unix.SYS_FINIT_MODULE, unix.SYS_DELETE_MODULE,
+	unix.SYS_REBOOT, unix.SYS_SWAPON, unix.SYS_SWAPOFF, unix.SYS_ACCT, unix.SYS_SYSLOG,
+	unix.SYS_BPF, unix.SYS_PERF_EVENT_OPEN, unix.SYS_USERFAULTFD, unix.SYS_OPEN_BY_HANDLE_AT,
+	unix.SYS_KEYCTL, unix.SYS_ADD_KEY, unix.SYS_REQUEST_KEY,
+	unix.SYS_SETTIMEOFDAY, unix.SYS_CLOCK_SETTIME,
+}
+
+// x32SyscallBit 是 x86_64 上 x32 ABI 系统调用号的标志位，这类调用一律拒绝，避免绕过按号过滤。
+const x32SyscallBit = 0x40000000
+
+// seccompFilter 返回 bwrap --seccomp 读取的 sock_filter 数组（本机字节序）。
+// 架构不匹配的调用直接终止进程，列入 deniedSyscalls 的调用返回 EPERM，其余放行。
+func seccompFilter() []byte {
+	arch := uint32(unix.AUDIT_ARCH_X86_64)
+	if runtime.GOARCH == "arm64" {
+		arch = unix.AUDIT_ARCH_AARCH64
+	}
+	// seccomp_data: nr 位于偏移 0，arch 位于偏移 4。
+	prog := []unix.SockFilter{
+		bpfStmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, 4),
+		bpfJump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, arch, 1, 0),
+		bpfStmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_KILL_PROCESS),
+		bpfStmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, 0),
+	}
+	var jumps []int
+	if runtime.GOARCH == "amd64" {
+		jumps = append(jumps, len(prog))
+		prog = append(prog, bpfJump(unix.BPF_JMP|unix.BPF_JGE|unix.BPF_K, x32SyscallBit, 0, 0))
+	}
+	for _, nr := range deniedSyscalls {
+		jumps = append(jumps, len(prog))
+		prog = append(prog, bpfJump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, nr, 0, 0))
+	}
+	prog = append(prog, bpfStmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_ALLOW))
+	deny := len(prog)
+	prog = append(prog, bpfStmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_ERRNO|uint32(unix.EPERM)))
+	for _, i := range jumps {
+		prog[i].Jt = uint8(deny - i - 1)
+	}
+
+	data := make([]byte, 0, len(prog)*8)
+	for _, ins := range prog {
+		data = binary.NativeEndian.AppendUint16(data, ins.Code)
+		data = append(data, ins.Jt, ins.Jf)
+		data = binary.NativeEndian.AppendUint32(data, ins.K)
+	}
+	return data
+}
+
+func bpfStmt(code uint16, k uint32) unix.SockFilter {
+	return unix.SockFilter{Code: code, K: k}
+}
+
+func bpfJump(code uint16, k uint32, jt, jf uint8) unix.SockFilter {
+	return unix.SockFilter{Code: code, Jt: jt, Jf: jf, K: k}
+}
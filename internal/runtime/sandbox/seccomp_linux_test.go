@@ -0,0 +1,47 @@
+//go:build linux && (amd64 || arm64)
+
+package sandbox
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestSeccompFilterDeniesListedSyscalls(t *testing.T) {
+	data := seccompFilter()
+	if len(data)%8 != 0 {
+		t.Fatalf("filter size %d is not a multiple of sock_filter", len(data))
+	}
+	prog := make([]unix.SockFilter, len(data)/8)
+	for i := range prog {
+		chunk := data[i*8:]
+		prog[i] = unix.SockFilter{
+			Code: binary.NativeEndian.Uint16(chunk),
+			Jt:   chunk[2],
+			Jf:   chunk[3],
+			K:    binary.NativeEndian.Uint32(chunk[4:]),
+		}
+	}
+	last := prog[len(prog)-1]
+	if last.K != unix.SECCOMP_RET_ERRNO|uint32(unix.EPERM) {
+		t.Fatalf("last instruction = %#v, want EPERM return", last)
+	}
+	if prog[len(prog)-2].K != unix.SECCOMP_RET_ALLOW {
+		t.Fatalf("default action = %#v, want allow", prog[len(prog)-2])
+	}
+	denied := 0
+	for i, ins := range prog {
+		if ins.Code != unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K || i == 1 {
+			continue
+		}
+		if i+1+int(ins.Jt) != len(prog)-1 {
+			t.Fatalf("instruction %d jumps to %d, want deny", i, i+1+int(ins.Jt))
+		}
+		denied++
+	}
+	if denied != len(deniedSyscalls) {
+		t.Fatalf("denied %d syscalls, want %d", denied, len(deniedSyscalls))
+	}
+}
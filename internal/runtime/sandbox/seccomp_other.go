@@ -0,0 +1,6 @@
+//go:build linux && !amd64 && !arm64
+
+package sandbox
+
+// seccompFilter 在未适配系统调用表的架构上不生成过滤程序，仅依赖命名空间隔离。
+func seccompFilter() []byte { return nil }
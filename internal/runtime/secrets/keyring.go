@@ -0,0 +1,21 @@
+package secrets
+
+import "errors"
+
+// keyringService 是主密钥在系统钥匙串中的服务名。
+const keyringService = "fkteams"
+
+// ErrKeyringUnavailable 表示当前系统没有可用的钥匙串。
+var ErrKeyringUnavailable = errors.New("system keyring is not available")
+
+// Keyring 保存和读取主密钥，account 区分不同的密钥库。
+type Keyring interface {
+	Get(account string) (string, error)
+	Set(account, secret string) error
+}
+
+// SystemKeyring 返回当前系统的钥匙串：macOS 使用 Keychain，Linux 使用 Secret Service（secret-tool）。
+// 其他系统返回 nil。
+func SystemKeyring() Keyring {
+	return systemKeyring()
+}
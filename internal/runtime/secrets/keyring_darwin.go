@@ -0,0 +1,31 @@
+package secrets
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+type keychain struct{}
+
+func systemKeyring() Keyring {
+	if _, err := exec.LookPath("security"); err != nil {
+		return nil
+	}
+	return keychain{}
+}
+
+func (keychain) Get(account string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", keyringService, "-a", account, "-w").Output()
+	if err != nil {
+		return "", fmt.Errorf("security find-generic-password: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (keychain) Set(account, secret string) error {
+	if out, err := exec.Command("security", "add-generic-password", "-U", "-s", keyringService, "-a", account, "-w", secret).CombinedOutput(); err != nil {
+		return fmt.Errorf("security add-generic-password: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// secretService 通过 libsecret 的 secret-tool 访问 GNOME Keyring、KWallet 等 Secret Service 实现。
+type secretService struct{}
+
+func systemKeyring() Keyring {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return nil
+	}
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return nil
+	}
+	return secretService{}
+}
+
+func (secretService) Get(account string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", keyringService, "account", account).Output()
+	if err != nil {
+		return "", fmt.Errorf("secret-tool lookup: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (secretService) Set(account, secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label=fkteams secret vault", "service", keyringService, "account", account)
+	// 通过标准输入传递密钥，避免出现在进程参数中
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool store: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,7 @@
+//go:build !darwin && !linux
+
+package secrets
+
+func systemKeyring() Keyring {
+	return nil
+}
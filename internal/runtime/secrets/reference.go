@@ -0,0 +1,59 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// RefPrefix 是密钥库引用的前缀，整个字段值形如 secret://name。
+const RefPrefix = "secret://"
+
+// envRefPattern 匹配环境变量引用 ${env:VAR}，可以出现在字段值的任意位置。
+var envRefPattern = regexp.MustCompile(`\$\{env:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// IsReference 判断字段值是否为密钥库引用或包含环境变量引用。
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, RefPrefix) || envRefPattern.MatchString(value)
+}
+
+// Resolver 解析字段值中的引用。密钥库只在遇到 secret:// 引用时才解锁。
+type Resolver struct {
+	Vault *Vault
+	// LookupEnv 为空时使用 os.LookupEnv
+	LookupEnv func(string) (string, bool)
+}
+
+// Resolve 返回引用替换后的值，不含引用的值原样返回。
+func (r *Resolver) Resolve(value string) (string, error) {
+	if name, ok := strings.CutPrefix(value, RefPrefix); ok {
+		if err := ValidateName(name); err != nil {
+			return "", err
+		}
+		if r == nil || r.Vault == nil {
+			return "", fmt.Errorf("%w: no secret vault configured for %s%s", ErrLocked, RefPrefix, name)
+		}
+		return r.Vault.Get(name)
+	}
+	var missing string
+	resolved := envRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envRefPattern.FindStringSubmatch(ref)[1]
+		value, ok := r.lookupEnv(name)
+		if !ok && missing == "" {
+			missing = name
+		}
+		return value
+	})
+	if missing != "" {
+		return "", fmt.Errorf("environment variable %s is not set", missing)
+	}
+	return resolved, nil
+}
+
+func (r *Resolver) lookupEnv(name string) (string, bool) {
+	if r != nil && r.LookupEnv != nil {
+		return r.LookupEnv(name)
+	}
+	return os.LookupEnv(name)
+}
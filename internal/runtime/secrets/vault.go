@@ -0,0 +1,415 @@
+// Package secrets 提供本地加密密钥库和配置中的密钥引用解析。
+//
+// 密钥库是单个 JSON 文件，每个条目使用 AES-256-GCM 独立加密，条目名作为附加数据防止条目互换。
+// 主密钥来自系统钥匙串中随机生成的密钥，或由口令经 scrypt 派生。
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fkteams/internal/runtime/atomicfile"
+	"fkteams/internal/runtime/env"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	vaultVersion      = 1
+	keySize           = 32
+	saltSize          = 16
+	maxVaultFileBytes = 4 << 20
+	maxSecretBytes    = 64 << 10
+	// checkPlaintext 用于在解密任何条目之前确认主密钥正确
+	checkPlaintext = "fkteams-vault"
+
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// KeySource 是主密钥的来源。
+type KeySource string
+
+const (
+	// SourceKeyring 表示主密钥保存在系统钥匙串中。
+	SourceKeyring KeySource = "keyring"
+	// SourcePassphrase 表示主密钥由口令派生。
+	SourcePassphrase KeySource = "passphrase"
+)
+
+var (
+	// ErrNotFound 表示密钥库中没有该条目。
+	ErrNotFound = errors.New("secret not found")
+	// ErrLocked 表示无法获取主密钥，例如口令未提供或钥匙串不可用。
+	ErrLocked = errors.New("secret vault is locked")
+	// ErrWrongKey 表示主密钥与密钥库不匹配，通常是口令错误。
+	ErrWrongKey = errors.New("secret vault key is incorrect")
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._/-]{0,127}$`)
+
+// ValidateName 校验条目名：1-128 个字母、数字或 `.`、`_`、`-`、`/`，以字母或数字开头。
+func ValidateName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q: use 1-128 letters, digits, '.', '_', '-' or '/'", name)
+	}
+	return nil
+}
+
+// Entry 是条目的元数据，不包含明文。
+type Entry struct {
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Options 控制主密钥的获取方式。
+type Options struct {
+	// Keyring 为空时不使用系统钥匙串
+	Keyring Keyring
+	// Passphrase 在需要口令时调用，create 表示正在创建新的密钥库，调用方应要求确认输入
+	Passphrase func(create bool) (string, error)
+}
+
+type vaultFile struct {
+	Version int               `json:"version"`
+	Source  KeySource         `json:"source"`
+	Salt    string            `json:"salt,omitempty"`
+	Check   string            `json:"check"`
+	Secrets map[string]record `json:"secrets"`
+}
+
+type record struct {
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Vault 是文件支持的加密密钥库。主密钥在首次使用时获取并缓存在内存中。
+type Vault struct {
+	path string
+	opts Options
+
+	mu  sync.Mutex
+	key []byte
+}
+
+// Open 返回 path 处的密钥库，文件不存在时在首次写入时创建。
+func Open(path string, opts Options) *Vault {
+	return &Vault{path: path, opts: opts}
+}
+
+// Path 返回密钥库文件路径。
+func (v *Vault) Path() string {
+	return v.path
+}
+
+// Exists 判断密钥库文件是否存在。
+func (v *Vault) Exists() bool {
+	_, err := os.Stat(v.path)
+	return err == nil
+}
+
+// Source 返回现有密钥库的主密钥来源，文件不存在时返回空。
+func (v *Vault) Source() (KeySource, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	file, err := v.read()
+	if err != nil || file == nil {
+		return "", err
+	}
+	return file.Source, nil
+}
+
+// List 返回按名称排序的条目，不需要主密钥。
+func (v *Vault) List() ([]Entry, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	file, err := v.read()
+	if err != nil || file == nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(file.Secrets))
+	for name, rec := range file.Secrets {
+		entries = append(entries, Entry{Name: name, UpdatedAt: rec.UpdatedAt})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries, nil
+}
+
+// Get 解密并返回条目的明文。
+func (v *Vault) Get(name string) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	file, err := v.read()
+	if err != nil {
+		return "", err
+	}
+	if file == nil {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	rec, ok := file.Secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	key, err := v.unlock(file)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(key, rec.Value, name)
+	if err != nil {
+		return "", fmt.Errorf("decrypt secret %s: %w", name, err)
+	}
+	return string(plaintext), nil
+}
+
+// Set 加密保存条目，密钥库不存在时创建：设置了口令时使用口令，否则优先使用系统钥匙串。
+func (v *Vault) Set(name, value string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if len(value) > maxSecretBytes {
+		return fmt.Errorf("secret value exceeds %d bytes", maxSecretBytes)
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	file, err := v.read()
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		if file, err = v.create(); err != nil {
+			return err
+		}
+	}
+	key, err := v.unlock(file)
+	if err != nil {
+		return err
+	}
+	sealed, err := seal(key, []byte(value), name)
+	if err != nil {
+		return err
+	}
+	file.Secrets[name] = record{Value: sealed, UpdatedAt: time.Now().UTC()}
+	return v.write(file)
+}
+
+// Delete 删除条目，条目不存在时返回 ErrNotFound。删除不需要主密钥。
+func (v *Vault) Delete(name string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	file, err := v.read()
+	if err != nil {
+		return err
+	}
+	if file == nil {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if _, ok := file.Secrets[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	delete(file.Secrets, name)
+	return v.write(file)
+}
+
+func (v *Vault) read() (*vaultFile, error) {
+	info, err := os.Stat(v.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxVaultFileBytes {
+		return nil, fmt.Errorf("secret vault exceeds %d bytes", maxVaultFileBytes)
+	}
+	data, err := os.ReadFile(v.path)
+	if err != nil {
+		return nil, err
+	}
+	var file vaultFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decode secret vault: %w", err)
+	}
+	if file.Version != vaultVersion {
+		return nil, fmt.Errorf("unsupported secret vault version %d", file.Version)
+	}
+	if !slices.Contains([]KeySource{SourceKeyring, SourcePassphrase}, file.Source) {
+		return nil, fmt.Errorf("unsupported secret vault key source %q", file.Source)
+	}
+	if file.Secrets == nil {
+		file.Secrets = make(map[string]record)
+	}
+	return &file, nil
+}
+
+func (v *Vault) write(file *vaultFile) error {
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode secret vault: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(v.path), 0700); err != nil {
+		return fmt.Errorf("create secret vault directory: %w", err)
+	}
+	return atomicfile.WriteFile(v.path, data, 0600)
+}
+
+// create 初始化新的密钥库并缓存主密钥。
+func (v *Vault) create() (*vaultFile, error) {
+	file := &vaultFile{Version: vaultVersion, Secrets: make(map[string]record)}
+	passphrase := env.Secret(env.VaultPassphrase)
+	if passphrase == "" && v.opts.Keyring != nil {
+		key := make([]byte, keySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		err := v.opts.Keyring.Set(v.keyringAccount(), base64.StdEncoding.EncodeToString(key))
+		if err == nil {
+			file.Source = SourceKeyring
+			return v.initialize(file, key)
+		}
+		if v.opts.Passphrase == nil {
+			return nil, fmt.Errorf("%w: store key in system keyring: %v; set %s to use a passphrase", ErrLocked, err, env.VaultPassphrase)
+		}
+		// 钥匙串不可用时回退到交互式口令
+	}
+	if passphrase == "" {
+		var err error
+		if passphrase, err = v.askPassphrase(true); err != nil {
+			return nil, err
+		}
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	file.Source = SourcePassphrase
+	file.Salt = base64.StdEncoding.EncodeToString(salt)
+	return v.initialize(file, key)
+}
+
+func (v *Vault) initialize(file *vaultFile, key []byte) (*vaultFile, error) {
+	check, err := seal(key, []byte(checkPlaintext), "")
+	if err != nil {
+		return nil, err
+	}
+	file.Check = check
+	v.key = key
+	return file, nil
+}
+
+// unlock 返回主密钥，并用校验块确认密钥正确。
+func (v *Vault) unlock(file *vaultFile) ([]byte, error) {
+	if v.key != nil {
+		return v.key, nil
+	}
+	var key []byte
+	switch file.Source {
+	case SourceKeyring:
+		if v.opts.Keyring == nil {
+			return nil, fmt.Errorf("%w: system keyring is not available", ErrLocked)
+		}
+		encoded, err := v.opts.Keyring.Get(v.keyringAccount())
+		if err != nil {
+			return nil, fmt.Errorf("%w: read key from system keyring: %v", ErrLocked, err)
+		}
+		if key, err = base64.StdEncoding.DecodeString(strings.TrimSpace(encoded)); err != nil || len(key) != keySize {
+			return nil, fmt.Errorf("%w: system keyring entry is malformed", ErrWrongKey)
+		}
+	case SourcePassphrase:
+		passphrase := env.Secret(env.VaultPassphrase)
+		if passphrase == "" {
+			var err error
+			if passphrase, err = v.askPassphrase(false); err != nil {
+				return nil, err
+			}
+		}
+		salt, err := base64.StdEncoding.DecodeString(file.Salt)
+		if err != nil || len(salt) != saltSize {
+			return nil, fmt.Errorf("secret vault salt is malformed")
+		}
+		if key, err = deriveKey(passphrase, salt); err != nil {
+			return nil, err
+		}
+	}
+	if check, err := open(key, file.Check, ""); err != nil || string(check) != checkPlaintext {
+		return nil, ErrWrongKey
+	}
+	v.key = key
+	return key, nil
+}
+
+func (v *Vault) askPassphrase(create bool) (string, error) {
+	if v.opts.Passphrase == nil {
+		return "", fmt.Errorf("%w: set %s", ErrLocked, env.VaultPassphrase)
+	}
+	passphrase, err := v.opts.Passphrase(create)
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("%w: passphrase is empty", ErrLocked)
+	}
+	return passphrase, nil
+}
+
+// keyringAccount 以密钥库路径区分钥匙串条目，不同数据目录的密钥库互不影响。
+func (v *Vault) keyringAccount() string {
+	if abs, err := filepath.Abs(v.path); err == nil {
+		return abs
+	}
+	return v.path
+}
+
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keySize)
+}
+
+func seal(key, plaintext []byte, name string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(name))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func open(key []byte, encoded, name string) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("malformed ciphertext")
+	}
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(name))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,173 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"fkteams/internal/runtime/env"
+)
+
+type memoryKeyring struct {
+	mu      sync.Mutex
+	entries map[string]string
+}
+
+func (k *memoryKeyring) Get(account string) (string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	secret, ok := k.entries[account]
+	if !ok {
+		return "", errors.New("keyring entry not found")
+	}
+	return secret, nil
+}
+
+func (k *memoryKeyring) Set(account, secret string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.entries == nil {
+		k.entries = make(map[string]string)
+	}
+	k.entries[account] = secret
+	return nil
+}
+
+func TestVaultKeyringRoundTripEncryptsAtRest(t *testing.T) {
+	t.Setenv(env.VaultPassphrase, "")
+	path := filepath.Join(t.TempDir(), "config", "secrets.json")
+	keyring := &memoryKeyring{}
+	vault := Open(path, Options{Keyring: keyring})
+
+	if err := vault.Set("openai", "sk-live-very-secret"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read vault: %v", err)
+	}
+	if strings.Contains(string(data), "sk-live-very-secret") {
+		t.Fatal("vault file contains plaintext secret")
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Fatalf("vault mode = %v, want 0600", info.Mode().Perm())
+	}
+	if source, _ := vault.Source(); source != SourceKeyring {
+		t.Fatalf("source = %q, want keyring", source)
+	}
+
+	// 新实例从钥匙串读取主密钥
+	reopened := Open(path, Options{Keyring: keyring})
+	if got, err := reopened.Get("openai"); err != nil || got != "sk-live-very-secret" {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+	if _, err := reopened.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing err = %v, want ErrNotFound", err)
+	}
+	if _, err := Open(path, Options{}).Get("openai"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("no keyring err = %v, want ErrLocked", err)
+	}
+
+	entries, err := Open(path, Options{}).List()
+	if err != nil || len(entries) != 1 || entries[0].Name != "openai" {
+		t.Fatalf("List without key = %#v, %v", entries, err)
+	}
+	if err := reopened.Delete("openai"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := reopened.Delete("openai"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second Delete err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestVaultPassphraseRejectsWrongKeyAndSwappedEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	t.Setenv(env.VaultPassphrase, "correct horse")
+	vault := Open(path, Options{Keyring: &memoryKeyring{}})
+	if err := vault.Set("a", "value-a"); err != nil {
+		t.Fatalf("Set a: %v", err)
+	}
+	if err := vault.Set("b", "value-b"); err != nil {
+		t.Fatalf("Set b: %v", err)
+	}
+	if source, _ := vault.Source(); source != SourcePassphrase {
+		t.Fatalf("source = %q, want passphrase when %s is set", source, env.VaultPassphrase)
+	}
+
+	t.Setenv(env.VaultPassphrase, "")
+	prompted := Open(path, Options{Passphrase: func(create bool) (string, error) { return "correct horse", nil }})
+	if got, err := prompted.Get("b"); err != nil || got != "value-b" {
+		t.Fatalf("Get with prompted passphrase = %q, %v", got, err)
+	}
+	if _, err := Open(path, Options{}).Get("a"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("missing passphrase err = %v, want ErrLocked", err)
+	}
+	t.Setenv(env.VaultPassphrase, "wrong")
+	if _, err := Open(path, Options{}).Get("a"); !errors.Is(err, ErrWrongKey) {
+		t.Fatalf("wrong passphrase err = %v, want ErrWrongKey", err)
+	}
+
+	// 互换条目密文后解密失败，条目名参与认证
+	data, _ := os.ReadFile(path)
+	var swapped = strings.NewReplacer(`"a": {`, `"tmp": {`, `"b": {`, `"a": {`).Replace(string(data))
+	swapped = strings.Replace(swapped, `"tmp": {`, `"b": {`, 1)
+	if err := os.WriteFile(path, []byte(swapped), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(env.VaultPassphrase, "correct horse")
+	if _, err := Open(path, Options{}).Get("a"); err == nil {
+		t.Fatal("expected swapped entry to fail authentication")
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"openai", "prod/db.password", "A_1-b"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "-lead", "has space", "a$b", strings.Repeat("a", 129)} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("ValidateName(%q) accepted invalid name", name)
+		}
+	}
+}
+
+func TestResolverResolvesReferences(t *testing.T) {
+	t.Setenv(env.VaultPassphrase, "pass")
+	vault := Open(filepath.Join(t.TempDir(), "secrets.json"), Options{})
+	if err := vault.Set("token", "t-123"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	resolver := &Resolver{Vault: vault, LookupEnv: func(name string) (string, bool) {
+		if name == "USER_TOKEN" {
+			return "u-456", true
+		}
+		return "", false
+	}}
+	cases := map[string]string{
+		"plain":                       "plain",
+		"secret://token":              "t-123",
+		"${env:USER_TOKEN}":           "u-456",
+		"Bearer ${env:USER_TOKEN}!":   "Bearer u-456!",
+		"prefix secret://token":       "prefix secret://token",
+		"$env:USER_TOKEN {not a ref}": "$env:USER_TOKEN {not a ref}",
+	}
+	for input, want := range cases {
+		if got, err := resolver.Resolve(input); err != nil || got != want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := resolver.Resolve("${env:MISSING}"); err == nil || !strings.Contains(err.Error(), "MISSING") {
+		t.Fatalf("missing env err = %v", err)
+	}
+	if _, err := resolver.Resolve("secret://missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing secret err = %v", err)
+	}
+	if !IsReference("secret://x") || !IsReference("a${env:B}") || IsReference("plain") {
+		t.Fatal("IsReference misclassified values")
+	}
+}
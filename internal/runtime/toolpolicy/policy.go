@@ -80,6 +80,12 @@ var toolPolicies = map[string]ToolPolicy{
 	"schedule_cancel": destructivePolicy("", false),
 	"schedule_delete": destructivePolicy("", false),
 
+	// 长期记忆
+	"memory_search": readOnlyPolicy("", false),
+	"memory_save":   destructivePolicy("", false),
+	"memory_update": destructivePolicy("", false),
+	"memory_forget": destructivePolicy(approval.StoreMemory, false),
+
 	// TODO 工具
 	"todo_list":         readOnlyPolicy("", false),
 	"todo_add":          destructivePolicy("", false),
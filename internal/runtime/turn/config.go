@@ -44,6 +44,9 @@ type Request struct {
 	// OnInterrupt HITL 中断处理。nil 时默认使用固定拒绝决策
 	OnInterrupt InterruptHandler
 
+	// Resume 非空时从会话 checkpoint 恢复先前的中断，而不是以 Input 开始新回合
+	Resume runtimeport.InterruptDecisions
+
 	// NonInteractive 标记非交互模式（WebSocket / 通道），不输出终端动画
 	NonInteractive bool
 
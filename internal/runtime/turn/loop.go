@@ -8,7 +8,7 @@ import (
 )
 
 // runLoop 装配引擎级选项后执行一次 Runner 调用。
-func (e *core) runLoop(ctx context.Context, input message.TurnInput, runID string, handler InterruptHandler) (*runtimeport.RunResult, error) {
+func (e *core) runLoop(ctx context.Context, input message.TurnInput, runID string, handler InterruptHandler, resume runtimeport.InterruptDecisions) (*runtimeport.RunResult, error) {
 	if runID == "" {
 		runID = e.checkpointID
 	}
@@ -17,5 +17,6 @@ func (e *core) runLoop(ctx context.Context, input message.TurnInput, runID strin
 		CheckpointID:     e.checkpointID,
 		Sink:             events.Dispatch(ctx),
 		InterruptHandler: runtimeport.InterruptHandler(handler),
+		Resume:           resume,
 	})
 }
@@ -24,7 +24,7 @@ func (e *core) run(ctx context.Context, cfg Request) (*runtimeport.RunResult, er
 		cfg.OnStart(ctx)
 	}
 
-	result, err := e.runLoop(ctx, input, cfg.RunID, cfg.interruptHandler())
+	result, err := e.runLoop(ctx, input, cfg.RunID, cfg.interruptHandler(), cfg.Resume)
 
 	if hookErr := cfg.invokeAfterRun(ctx, input, result, err); hookErr != nil && err == nil {
 		err = hookErr
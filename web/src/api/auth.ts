@@ -1,9 +1,26 @@
-import { post } from "./client";
+import { get, post } from "./client";
 
 export interface LoginResponse {
   authenticated: boolean;
 }
 
+export interface LoginOptions {
+  auth_enabled: boolean;
+  password: boolean;
+  oidc: {
+    enabled: boolean;
+    display_name: string;
+  };
+}
+
 export function login(username: string, password: string) {
 	return post<LoginResponse>("/api/fkteams/login", { username, password, cookie_only: true }, { authFailure: "ignore" });
 }
+
+export function getLoginOptions() {
+  return get<LoginOptions>("/api/fkteams/login/options", { authFailure: "ignore" });
+}
+
+export function oidcLoginURL(next: string) {
+  return `/api/fkteams/oidc/login?next=${encodeURIComponent(next)}`;
+}
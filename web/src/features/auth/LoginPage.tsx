@@ -1,9 +1,30 @@
+import { useEffect, useState } from "react";
+import { KeyRound } from "lucide-react";
+import { getLoginOptions, oidcLoginURL, type LoginOptions } from "@/api/auth";
+import { Button } from "@/components/ui/button";
 import { Panel, PanelBody, PanelHeader } from "@/components/ui/panel";
 import { LoginForm } from "@/features/auth/LoginForm";
 import { restoreAuthentication } from "@/lib/auth-session";
 import { loginReturnPath } from "@/lib/navigation";
 
+const ssoErrorMessages: Record<string, string> = {
+  sso_unavailable: "暂时无法连接单点登录服务",
+  sso_denied: "单点登录已取消",
+  sso_expired: "登录请求已过期，请重试",
+  sso_forbidden: "该账号没有访问权限",
+  sso_failed: "单点登录失败，请重试",
+};
+
 export function LoginPage() {
+  const [options, setOptions] = useState<LoginOptions | null>(null);
+  const ssoError = ssoErrorMessages[new URLSearchParams(location.search).get("error") || ""] || "";
+
+  useEffect(() => {
+    getLoginOptions()
+      .then(setOptions)
+      .catch(() => setOptions(null));
+  }, []);
+
   function authenticated() {
     restoreAuthentication();
     location.replace(loginReturnPath(location.search));
@@ -21,8 +42,20 @@ export function LoginPage() {
             </div>
           </div>
         </PanelHeader>
-        <PanelBody className="px-6 py-6 sm:px-7">
+        <PanelBody className="space-y-4 px-6 py-6 sm:px-7">
+          {ssoError ? <div className="text-sm text-destructive">{ssoError}</div> : null}
           <LoginForm onAuthenticated={authenticated} />
+          {options?.oidc.enabled ? (
+            <Button
+              className="h-12 w-full text-base"
+              type="button"
+              variant="outline"
+              onClick={() => location.assign(oidcLoginURL(loginReturnPath(location.search)))}
+            >
+              <KeyRound className="h-5 w-5" />
+              {options.oidc.display_name}
+            </Button>
+          ) : null}
         </PanelBody>
       </Panel>
     </div>
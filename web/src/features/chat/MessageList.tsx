@@ -13,7 +13,7 @@ import { formatTime } from "@/lib/format";
 import { ToolCallCard } from "./ToolCallCard";
 import { chatMessageElementID } from "./dom";
 import { useDisclosureState } from "./disclosureState";
-import type { ChatEvent, ContentPartDTO, ToolCallDTO } from "@/types/events";
+import type { ApprovalFindingDTO, ChatEvent, ContentPartDTO, ToolCallDTO } from "@/types/events";
 import type { ChatViewMessage } from "@/types/chat";
 import type { AgentInfo } from "@/types/api";
 
@@ -51,6 +51,7 @@ interface ApprovalActivity {
   id: string;
   order: number;
   message: string;
+  findings: ApprovalFindingDTO[];
   answered: boolean;
   decision?: string;
 }
@@ -1245,6 +1246,7 @@ function ApprovalPanel({
         <div className="min-w-0 flex-1">
           <div className="text-xs font-semibold text-amber-700">需要权限审批</div>
           <div className="mt-1 whitespace-pre-wrap text-sm leading-7 text-foreground">{approval.message}</div>
+          <ApprovalFindings findings={approval.findings} />
           <div className="mt-3 flex flex-wrap items-center justify-between gap-3">
             <div className="text-sm text-muted-foreground">{error || disabledReason}</div>
             <div className="flex flex-wrap gap-2">
@@ -1273,6 +1275,7 @@ function ApprovalRecord({ approval }: { approval: ApprovalActivity }) {
         <div className="min-w-0 flex-1">
           <div className="text-xs font-semibold text-amber-700">权限审批</div>
           <div className="mt-1 whitespace-pre-wrap text-sm leading-7 text-muted-foreground">{approval.message}</div>
+          <ApprovalFindings findings={approval.findings} />
           <div className="mt-3 rounded-md border border-border bg-background/55 px-3 py-2 text-sm">
             <span className="text-muted-foreground">审批结果：</span>
             <span className="text-foreground">{approval.decision || "已处理"}</span>
@@ -1283,6 +1286,39 @@ function ApprovalRecord({ approval }: { approval: ApprovalActivity }) {
   );
 }
 
+const approvalScopeLabels: Record<string, string> = {
+  workspace: "工作区",
+  null: "空设备",
+  temp: "临时目录",
+  outside: "工作区外",
+  system: "系统目录",
+  critical: "关键目录",
+  device: "设备文件",
+  dynamic: "运行时决定",
+};
+
+function ApprovalFindings({ findings }: { findings: ApprovalFindingDTO[] }) {
+  if (findings.length === 0) return null;
+  return (
+    <ul className="mt-3 space-y-1.5">
+      {findings.map((finding, index) => (
+        <li key={`${index}-${finding.program}-${finding.target}`} className="rounded-md border border-border bg-background/55 px-3 py-2 text-sm">
+          <div className="flex flex-wrap items-center gap-x-2 gap-y-1">
+            <span className={cn("rounded px-1.5 text-xs font-semibold", finding.level === "危险" ? "bg-red-100 text-red-700" : "bg-amber-100 text-amber-700")}>
+              {finding.level}
+            </span>
+            {finding.program ? <code className="text-xs text-foreground">{finding.program}</code> : null}
+            {finding.target ? <code className="break-all text-xs text-muted-foreground">{finding.target}</code> : null}
+            {finding.scope ? <span className="text-xs text-muted-foreground">{approvalScopeLabels[finding.scope] || finding.scope}</span> : null}
+          </div>
+          <div className="mt-1 text-foreground">{finding.reason}</div>
+          {finding.risk ? <div className="text-xs text-muted-foreground">{finding.risk}</div> : null}
+        </li>
+      ))}
+    </ul>
+  );
+}
+
 function askTitle(ask: AskActivity) {
   if (ask.memberName) return `${ask.memberName} · ask_questions`;
   return "ask_questions";
@@ -1606,6 +1642,8 @@ function collectApprovalActivities(
       const existing = byID.get(id);
       if (existing) {
         existing.message = approvalRequestMessage(event) || existing.message;
+        const findings = approvalRequestFindings(event);
+        if (findings.length > 0) existing.findings = findings;
         existing.answered = existing.answered || submittedApprovalIDs.has(id);
         continue;
       }
@@ -1613,6 +1651,7 @@ function collectApprovalActivities(
         id,
         order: eventOrders.get(eventDisplayKey(event)) ?? Number.MAX_SAFE_INTEGER,
         message: approvalRequestMessage(event) || "需要审批",
+        findings: approvalRequestFindings(event),
         answered: submittedApprovalIDs.has(id),
       };
       approvals.push(activity);
@@ -1647,6 +1686,25 @@ function approvalRequestMessage(event: ChatEvent) {
   return stringValue(approval.message) || stringValue(event.content || event.message);
 }
 
+function approvalRequestFindings(event: ChatEvent): ApprovalFindingDTO[] {
+  const findings = approvalPayload(event).findings;
+  if (!Array.isArray(findings)) return [];
+  return findings.flatMap((value) => {
+    if (!value || typeof value !== "object") return [];
+    const finding = value as Record<string, unknown>;
+    const reason = stringValue(finding.reason);
+    if (!reason) return [];
+    return [{
+      level: stringValue(finding.level),
+      program: stringValue(finding.program),
+      target: stringValue(finding.target),
+      scope: stringValue(finding.scope),
+      reason,
+      risk: stringValue(finding.risk),
+    }];
+  });
+}
+
 function approvalDecisionFromEvent(event: ChatEvent) {
   const approval = approvalPayload(event);
   return stringValue(approval.decision) || stringValue(event.content || event.message) || "已处理";
@@ -44,8 +44,10 @@ import type {
   ChannelWeixinConfig,
   DeepConfig,
   MCPServerConfig,
+  MemoryEmbeddingConfig,
   ModelConfig,
   ServerAuthConfig,
+  ServerOIDCConfig,
   TeamMemberConfig,
   ToolInfo,
 } from "@/types/config";
@@ -507,6 +509,7 @@ function ModelNameField({
 function ServerTab({ draft, updateDraft }: EditorProps) {
   const server = draft.server || {};
   const auth = server.auth || {};
+  const oidc = auth.oidc || {};
   const openaiAPI = draft.openai_api || {};
   return (
     <div className="grid gap-4 xl:grid-cols-[1fr_420px]">
@@ -548,6 +551,17 @@ function ServerTab({ draft, updateDraft }: EditorProps) {
             <TextField label="用户名" value={auth.username} onChange={(value) => updateDraft((next) => setAuth(next, { username: value }))} />
             <TextField label="密码" type="password" value={auth.password} onChange={(value) => updateDraft((next) => setAuth(next, { password: value }))} />
             <TextField label="JWT Secret" type="password" value={auth.secret} onChange={(value) => updateDraft((next) => setAuth(next, { secret: value }))} />
+            <ToggleField label="启用 OIDC 单点登录" checked={Boolean(oidc.enabled)} onChange={(value) => updateDraft((next) => setOIDC(next, { enabled: value }))} />
+            {oidc.enabled ? (
+              <>
+                <TextField label="登录按钮名称" value={oidc.display_name} placeholder="单点登录" onChange={(value) => updateDraft((next) => setOIDC(next, { display_name: value }))} />
+                <TextField label="Issuer" value={oidc.issuer} placeholder="https://id.example.com/realms/team" onChange={(value) => updateDraft((next) => setOIDC(next, { issuer: value }))} />
+                <TextField label="Client ID" value={oidc.client_id} onChange={(value) => updateDraft((next) => setOIDC(next, { client_id: value }))} />
+                <TextField label="Client Secret" type="password" value={oidc.client_secret} onChange={(value) => updateDraft((next) => setOIDC(next, { client_secret: value }))} />
+                <TextField label="回调地址" value={oidc.redirect_url} placeholder="留空时按访问地址推导" onChange={(value) => updateDraft((next) => setOIDC(next, { redirect_url: value }))} />
+                <TextField label="默认角色" value={oidc.default_role} placeholder="留空时未匹配用户组的用户不能登录" onChange={(value) => updateDraft((next) => setOIDC(next, { default_role: value }))} />
+              </>
+            ) : null}
           </PanelBody>
         </Panel>
         <Panel>
@@ -908,6 +922,11 @@ function DeepTab({ draft, updateDraft }: EditorProps) {
 }
 
 function MemoryTab({ draft, updateDraft }: EditorProps) {
+  const embedding = draft.memory?.embedding || {};
+  const update = (patch: Partial<MemoryEmbeddingConfig>) =>
+    updateDraft((next) => {
+      next.memory = { ...(next.memory || {}), embedding: { ...(next.memory?.embedding || {}), ...patch } };
+    });
   return (
     <Panel>
       <PanelHeader>
@@ -923,6 +942,17 @@ function MemoryTab({ draft, updateDraft }: EditorProps) {
             })
           }
         />
+        <SelectField
+          label="语义检索向量模型"
+          value={embedding.provider || "hash"}
+          options={["hash", "openai", "ollama", "none"]}
+          onChange={(value) => update({ provider: value })}
+        />
+        <TextField label="模型名称" value={embedding.model} placeholder="text-embedding-3-small" onChange={(value) => update({ model: value })} />
+        <TextField label="Base URL" value={embedding.base_url} onChange={(value) => update({ base_url: value })} />
+        <TextField label="API Key" type="password" value={embedding.api_key} onChange={(value) => update({ api_key: value })} />
+        <NumberField label="向量维度" value={embedding.dimensions} min={0} onChange={(value) => update({ dimensions: value })} />
+        <NumberField label="最低相似度" value={embedding.min_similarity} min={0} step={0.05} onChange={(value) => update({ min_similarity: value })} />
       </PanelBody>
     </Panel>
   );
@@ -967,6 +997,8 @@ const approvalStoreOptions = [
   { value: "file", label: "外部文件" },
   { value: "git", label: "Git 操作" },
   { value: "dispatch", label: "任务分发" },
+  { value: "memory", label: "删除记忆" },
+  { value: "policy", label: "策略规则" },
 ];
 
 function PermissionsTab({ draft, updateDraft, autoSaveDraft, saving }: EditorProps) {
@@ -2342,6 +2374,10 @@ function setAuth(config: AppConfig, patch: Partial<ServerAuthConfig>) {
   config.server = { ...(config.server || {}), auth: { ...(config.server?.auth || {}), ...patch } };
 }
 
+function setOIDC(config: AppConfig, patch: Partial<ServerOIDCConfig>) {
+  setAuth(config, { oidc: { ...(config.server?.auth?.oidc || {}), ...patch } });
+}
+
 function setAgents(config: AppConfig, patch: AppConfig["agents"]) {
   config.agents = { ...(config.agents || {}), ...patch };
 }
@@ -27,7 +27,15 @@ import { Panel, PanelBody, PanelHeader } from "@/components/ui/panel";
 import { MarkdownContent } from "@/components/markdown/MarkdownContent";
 import { cn } from "@/lib/cn";
 import { formatTime, shortID } from "@/lib/format";
-import type { ScheduleHistoryEntry, ScheduleTask, ScheduleTaskPayload } from "@/types/schedules";
+import type {
+  ScheduleDelivery,
+  ScheduleHistoryEntry,
+  SchedulePolicy,
+  ScheduleTarget,
+  ScheduleTask,
+  ScheduleTaskPayload,
+  ScheduleTrigger,
+} from "@/types/schedules";
 
 type ScheduleFilter = "all" | "active" | "completed" | "cancelled" | "failed";
 type ScheduleFormMode = "once" | "cron";
@@ -38,6 +46,12 @@ interface ScheduleFormState {
   mode: ScheduleFormMode;
   cronExpr: string;
   executeAt: string;
+  // 编辑时原样保留执行目标、投递目标、时区、执行策略和触发器，表单暂不提供修改入口。
+  target?: ScheduleTarget;
+  deliveries?: ScheduleDelivery[];
+  timeZone?: string;
+  policy?: SchedulePolicy;
+  trigger?: ScheduleTrigger;
 }
 
 export function SchedulePanel() {
@@ -185,6 +199,11 @@ export function SchedulePanel() {
       mode: task.cron_expr ? "cron" : "once",
       cronExpr: task.cron_expr || "0 9 * * *",
       executeAt: toLocalDateTimeInput(task.next_run_at || new Date(Date.now() + 60 * 60 * 1000).toISOString()),
+      target: task.target,
+      deliveries: task.deliveries,
+      timeZone: task.time_zone,
+      policy: task.policy,
+      trigger: task.trigger,
     });
   }
 
@@ -310,7 +329,7 @@ function ScheduleEditor({
   onCancel: () => void;
   onSave: () => void;
 }) {
-  const canSave = Boolean(form.task.trim() && (form.mode === "cron" ? form.cronExpr.trim() : form.executeAt));
+  const canSave = Boolean(form.task.trim() && (form.trigger || (form.mode === "cron" ? form.cronExpr.trim() : form.executeAt)));
   return (
     <Panel>
       <PanelHeader className="flex flex-col gap-4 xl:flex-row xl:items-center xl:justify-between">
@@ -338,41 +357,48 @@ function ScheduleEditor({
             placeholder="例如：每天早上汇总昨日项目进展并生成报告"
           />
         </label>
-        <div className="space-y-4">
-          <div className="grid grid-cols-2 gap-2">
-            <button
-              className={cn(
-                "h-10 rounded-lg border text-sm transition-colors",
-                form.mode === "once" ? "border-primary/50 bg-primary/10 text-primary" : "border-border bg-card/70 hover:bg-accent/60",
-              )}
-              onClick={() => onChange({ ...form, mode: "once" })}
-              type="button"
-            >
-              一次执行
-            </button>
-            <button
-              className={cn(
-                "h-10 rounded-lg border text-sm transition-colors",
-                form.mode === "cron" ? "border-primary/50 bg-primary/10 text-primary" : "border-border bg-card/70 hover:bg-accent/60",
-              )}
-              onClick={() => onChange({ ...form, mode: "cron" })}
-              type="button"
-            >
-              循环执行
-            </button>
+        {form.trigger ? (
+          <div className="space-y-1.5">
+            <span className="text-sm text-muted-foreground">触发方式</span>
+            <div className="rounded-lg border border-border bg-card/70 px-3 py-2 text-sm">{triggerLabel(form.trigger)}</div>
           </div>
-          {form.mode === "once" ? (
-            <label className="block space-y-1.5">
-              <span className="text-sm text-muted-foreground">执行时间</span>
-              <Input type="datetime-local" value={form.executeAt} onChange={(event) => onChange({ ...form, executeAt: event.target.value })} />
-            </label>
-          ) : (
-            <label className="block space-y-1.5">
-              <span className="text-sm text-muted-foreground">Cron 表达式</span>
-              <Input value={form.cronExpr} onChange={(event) => onChange({ ...form, cronExpr: event.target.value })} placeholder="0 9 * * *" />
-            </label>
-          )}
-        </div>
+        ) : (
+          <div className="space-y-4">
+            <div className="grid grid-cols-2 gap-2">
+              <button
+                className={cn(
+                  "h-10 rounded-lg border text-sm transition-colors",
+                  form.mode === "once" ? "border-primary/50 bg-primary/10 text-primary" : "border-border bg-card/70 hover:bg-accent/60",
+                )}
+                onClick={() => onChange({ ...form, mode: "once" })}
+                type="button"
+              >
+                一次执行
+              </button>
+              <button
+                className={cn(
+                  "h-10 rounded-lg border text-sm transition-colors",
+                  form.mode === "cron" ? "border-primary/50 bg-primary/10 text-primary" : "border-border bg-card/70 hover:bg-accent/60",
+                )}
+                onClick={() => onChange({ ...form, mode: "cron" })}
+                type="button"
+              >
+                循环执行
+              </button>
+            </div>
+            {form.mode === "once" ? (
+              <label className="block space-y-1.5">
+                <span className="text-sm text-muted-foreground">执行时间</span>
+                <Input type="datetime-local" value={form.executeAt} onChange={(event) => onChange({ ...form, executeAt: event.target.value })} />
+              </label>
+            ) : (
+              <label className="block space-y-1.5">
+                <span className="text-sm text-muted-foreground">Cron 表达式</span>
+                <Input value={form.cronExpr} onChange={(event) => onChange({ ...form, cronExpr: event.target.value })} placeholder="0 9 * * *" />
+              </label>
+            )}
+          </div>
+        )}
       </PanelBody>
     </Panel>
   );
@@ -426,7 +452,7 @@ function TaskGrid({
                   <StatusDot status={task.status} />
                   <span className="truncate font-semibold">{shortID(task.id)}</span>
                 </div>
-                <div className="mt-1 text-xs text-muted-foreground">{task.cron_expr || "once"}</div>
+                <div className="mt-1 text-xs text-muted-foreground">{scheduleLabel(task)}</div>
               </div>
               <Badge>{statusLabel(task.status)}</Badge>
             </div>
@@ -523,14 +549,27 @@ function TaskDetail({
           <div className="flex flex-wrap items-center gap-2">
             <h3 className="text-lg font-semibold">任务 {shortID(task.id)}</h3>
             <Badge>{statusLabel(task.status)}</Badge>
-            <Badge>{task.cron_expr || "once"}</Badge>
+            <Badge>{scheduleLabel(task)}</Badge>
           </div>
           <div className="mt-2 max-w-4xl text-sm leading-6 text-muted-foreground">{task.task || "未命名任务"}</div>
           <div className="mt-3 flex flex-wrap gap-3 text-xs text-muted-foreground">
             <span>ID {task.id}</span>
             <span>创建 {formatTime(task.created_at)}</span>
             {task.next_run_at ? <span>下次 {formatTime(task.next_run_at)}</span> : null}
+            {task.time_zone ? <span>时区 {task.time_zone}</span> : null}
+            {task.trigger ? <span>触发 {triggerLabel(task.trigger)}</span> : null}
+            {task.attempt ? <span>第 {task.attempt} 次重试</span> : null}
           </div>
+          {task.trigger?.type === "webhook" ? (
+            <div className="mt-2 max-w-4xl break-all font-mono text-xs text-muted-foreground">
+              POST /api/fkteams/triggers/{task.id} · X-Fkteams-Trigger-Secret: {task.trigger.secret}
+            </div>
+          ) : null}
+          {task.last_error ? (
+            <div className="mt-2 max-w-4xl truncate text-xs text-destructive" title={task.last_error}>
+              最近错误：{task.last_error}
+            </div>
+          ) : null}
         </div>
         <div className="flex shrink-0 flex-wrap gap-2">
           <Button className="min-w-20 whitespace-nowrap" variant="outline" onClick={() => onEdit(task)}>
@@ -599,6 +638,16 @@ function HistoryEntryCard({ entry }: { entry: ScheduleHistoryEntry }) {
       </div>
       <div className="mt-1 text-xs text-muted-foreground">{formatTime(entry.created_at || entry.time)}</div>
       {entry.content ? <div className="mt-2 line-clamp-4 text-sm leading-6 text-muted-foreground">{entry.content}</div> : null}
+      {entry.deliveries?.length ? (
+        <div className="mt-2 space-y-1">
+          {entry.deliveries.map((delivery, index) => (
+            <div key={`${delivery.type}-${index}`} className="truncate text-xs text-muted-foreground" title={delivery.error || delivery.target}>
+              {delivery.status === "sent" ? "已投递" : "投递失败"} · {delivery.type} · {delivery.target}
+              {delivery.error ? ` · ${delivery.error}` : ""}
+            </div>
+          ))}
+        </div>
+      ) : null}
     </div>
   );
 }
@@ -698,9 +747,35 @@ function taskTime(task: ScheduleTask) {
   return Date.parse(task.next_run_at || task.last_run_at || task.created_at || "") || 0;
 }
 
+function scheduleLabel(task: ScheduleTask) {
+  if (task.trigger) return `trigger:${task.trigger.type}`;
+  return task.cron_expr || "once";
+}
+
+function triggerLabel(trigger: ScheduleTrigger) {
+  switch (trigger.type) {
+    case "file":
+      return `文件变化 ${trigger.glob || ""}${trigger.debounce ? `（防抖 ${trigger.debounce}）` : ""}`;
+    case "webhook":
+      return "Webhook 调用";
+    case "keyword":
+      return `通道消息包含 ${(trigger.keywords || []).join("、")}${trigger.channel ? ` @ ${trigger.channel}${trigger.chat_id ? `:${trigger.chat_id}` : ""}` : ""}`;
+    default:
+      return trigger.type;
+  }
+}
+
 function formToPayload(form: ScheduleFormState): ScheduleTaskPayload {
-  const payload: ScheduleTaskPayload = { task: form.task.trim() };
-  if (form.mode === "cron") {
+  const payload: ScheduleTaskPayload = {
+    task: form.task.trim(),
+    target: form.target,
+    deliveries: form.deliveries,
+    time_zone: form.timeZone,
+    policy: form.policy,
+  };
+  if (form.trigger) {
+    payload.trigger = form.trigger;
+  } else if (form.mode === "cron") {
     payload.cron_expr = form.cronExpr.trim();
   } else {
     payload.execute_at = localDateTimeToISO(form.executeAt);
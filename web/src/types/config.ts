@@ -11,8 +11,32 @@ export interface ModelConfig {
   original_id?: string;
 }
 
+export interface MemoryEmbeddingConfig {
+  provider?: string;
+  model?: string;
+  base_url?: string;
+  api_key?: string;
+  dimensions?: number;
+  min_similarity?: number;
+}
+
 export interface MemoryConfig {
   enabled?: boolean;
+  embedding?: MemoryEmbeddingConfig;
+}
+
+export interface ServerOIDCConfig {
+  enabled?: boolean;
+  display_name?: string;
+  issuer?: string;
+  client_id?: string;
+  client_secret?: string;
+  redirect_url?: string;
+  scopes?: string[];
+  username_claim?: string;
+  groups_claim?: string;
+  group_roles?: Record<string, string>;
+  default_role?: string;
 }
 
 export interface ServerAuthConfig {
@@ -20,6 +44,7 @@ export interface ServerAuthConfig {
   username?: string;
   password?: string;
   secret?: string;
+  oidc?: ServerOIDCConfig;
 }
 
 export interface ServerConfig {
@@ -62,6 +62,15 @@ export interface ContentPartDTO {
   detail?: string;
 }
 
+export interface ApprovalFindingDTO {
+  level: string;
+  program?: string;
+  target?: string;
+  scope?: string;
+  reason: string;
+  risk?: string;
+}
+
 export interface ChatEvent {
   type: ChatEventType;
   session_id?: string;
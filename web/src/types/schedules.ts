@@ -6,12 +6,74 @@ export interface ScheduleTask {
   next_run_at?: string;
   last_run_at?: string;
   created_at?: string;
+  target?: ScheduleTarget;
+  deliveries?: ScheduleDelivery[];
+  time_zone?: string;
+  policy?: SchedulePolicy;
+  attempt?: number;
+  last_error?: string;
+  trigger?: ScheduleTrigger;
+}
+
+export interface ScheduleTrigger {
+  type: "file" | "webhook" | "keyword";
+  glob?: string;
+  debounce?: string;
+  secret?: string;
+  channel?: string;
+  chat_id?: string;
+  keywords?: string[];
+}
+
+export interface SchedulePolicy {
+  retry?: {
+    max_attempts?: number;
+    backoff?: string;
+    max_backoff?: string;
+  };
+  misfire?: "skip" | "run_once" | "run_all";
+  concurrency?: "forbid" | "allow" | "replace";
+  jitter?: string;
+}
+
+export interface ScheduleTarget {
+  agent?: string;
+  mode?: string;
+  model?: string;
+  work_dir?: string;
+  tools?: string[];
+  max_iterations?: number;
+  timeout?: string;
+}
+
+export interface ScheduleDelivery {
+  type: "channel" | "webhook" | "email";
+  on?: "always" | "success" | "failure";
+  channel?: string;
+  chat_id?: string;
+  url?: string;
+  to?: string[];
+  subject?: string;
+  template?: string;
+}
+
+export interface ScheduleDeliveryResult {
+  type: string;
+  target: string;
+  status: "sent" | "failed";
+  error?: string;
+  time?: string;
 }
 
 export interface ScheduleTaskPayload {
   task: string;
   cron_expr?: string;
   execute_at?: string;
+  target?: ScheduleTarget;
+  deliveries?: ScheduleDelivery[];
+  time_zone?: string;
+  policy?: SchedulePolicy;
+  trigger?: ScheduleTrigger;
 }
 
 export interface ScheduleHistoryEntry {
@@ -20,4 +82,5 @@ export interface ScheduleHistoryEntry {
 	created_at?: string;
 	status?: string;
 	content?: string;
+	deliveries?: ScheduleDeliveryResult[];
 }